@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/backup"
+	"github.com/TheLazyLemur/switchboard/internal/config"
+	"github.com/TheLazyLemur/switchboard/internal/skills"
+	"github.com/pkg/errors"
+)
+
+const commandUsage = "usage: switchboard [backup <archive.tar.gz> | restore <archive.tar.gz> | replay <recording.jsonl>]"
+
+// runCommand runs a maintenance subcommand instead of the bot.
+func runCommand(name string, args []string) error {
+	if len(args) != 1 {
+		return errors.New(commandUsage)
+	}
+	switch name {
+	case "backup":
+		return runBackup(args[0])
+	case "restore":
+		return runRestore(args[0])
+	case "replay":
+		return runReplay(args[0])
+	default:
+		return errors.Errorf("unknown command %q; %s", name, commandUsage)
+	}
+}
+
+// stateItems lists the state a backup carries, at the paths the current
+// config uses. Sessions live in memory and tools never ask for
+// permission, so there is no history or grant store to include.
+func stateItems(cfg *config.Config) ([]backup.Item, error) {
+	skillsDir, err := skills.DefaultSkillsDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting skills dir")
+	}
+	items := []backup.Item{
+		{Name: "skills", Path: skillsDir},
+		{Name: "memory", Path: cfg.MemoryDir},
+		{Name: "metrics", Path: cfg.MetricsDB},
+		{Name: "guild-settings", Path: cfg.GuildSettingsDB},
+	}
+	if len(cfg.WhatsAppAllowedSenders) > 0 {
+		items = append(items, backup.Item{Name: "whatsapp", Path: cfg.WhatsAppDBPath})
+	}
+	if cfg.PersonasDir != "" {
+		items = append(items, backup.Item{Name: "personas", Path: cfg.PersonasDir})
+	}
+	return items, nil
+}
+
+// runBackup writes the state to path. Stop the bot first so the databases
+// are not written to mid-copy.
+func runBackup(path string) error {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	items, err := stateItems(cfg)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "creating archive")
+	}
+	skipped, err := backup.Create(f, items)
+	if closeErr := f.Close(); err == nil {
+		err = errors.Wrap(closeErr, "writing archive")
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	fmt.Printf("backed up to %s", path)
+	if len(skipped) > 0 {
+		fmt.Printf(" (not found: %s)", strings.Join(skipped, ", "))
+	}
+	fmt.Println()
+	return nil
+}
+
+// runRestore unpacks the archive at path onto the paths this host's config
+// uses, overwriting existing state. Stop the bot first.
+func runRestore(path string) error {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	items, err := stateItems(cfg)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening archive")
+	}
+	defer f.Close()
+	restored, err := backup.Restore(f, items)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %s from %s\n", strings.Join(restored, ", "), path)
+	return nil
+}
@@ -3,42 +3,79 @@ package main
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/TheLazyLemur/switchboard/internal/channels/discord"
 	"github.com/TheLazyLemur/switchboard/internal/config"
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/stt"
 	"github.com/pkg/errors"
 )
 
 // startDiscord opens the Discord session, constructs the plugin, starts it,
 // and returns a cleanup func.
-func startDiscord(cfg *config.Config, bot *core.Bot) (func(), error) {
+func startDiscord(ctx context.Context, cfg *config.Config, bot *core.Bot) (func(), error) {
 	dg, err := discord.Connect(cfg.DiscordToken)
 	if err != nil {
 		return nil, errors.Wrap(err, "connecting discord")
 	}
 
 	plugin := discord.New(discord.Config{
-		Token:        cfg.DiscordToken,
-		BotID:        dg.State.User.ID,
-		AllowedUsers: cfg.AllowedUsers,
-		MediaDir:     cfg.DiscordMediaDir,
+		Token:          cfg.DiscordToken,
+		BotID:          dg.State.User.ID,
+		AllowedUsers:   cfg.AllowedUsers,
+		MediaDir:       cfg.DiscordMediaDir,
+		MaxResponseLen: cfg.DiscordMaxResponseLen,
+		Faults:         cfg.Faults,
+		ArchiveAfter:   time.Duration(cfg.DiscordArchiveMinutes) * time.Minute,
+		Prune:          pruneAges(cfg.DiscordPruneDays),
+		Ignores:        bot.Ignores,
 	}, discord.WrapSession(dg))
 
-	if err := plugin.Start(context.Background(), func(in core.Inbound) {
-		if err := bot.HandleInbound(in); err != nil {
+	if err := plugin.Start(ctx, func(in core.Inbound) {
+		if err := bot.HandleInboundContext(ctx, in); err != nil {
 			slog.Error("handling discord inbound", "error", err)
 		}
 	}); err != nil {
 		return nil, errors.Wrap(err, "starting discord plugin")
 	}
 
+	bot.AddChannel(plugin.ID(), plugin)
 	slog.Info("discord plugin started")
 
+	leaveVoice := func() {}
+	if cfg.VoiceChannel != "" {
+		leaveVoice, err = plugin.StartVoice(ctx, discord.VoiceConfig{
+			GuildID:       cfg.VoiceGuild,
+			ChannelID:     cfg.VoiceChannel,
+			TextChannelID: cfg.VoiceThread,
+			WakeWord:      cfg.VoiceWakeWord,
+			STT:           stt.NewOpenAI(cfg.STTBaseURL, cfg.STTAPIKey, cfg.STTModel),
+		})
+		if err != nil {
+			_ = plugin.Stop()
+			return nil, errors.Wrap(err, "starting discord voice")
+		}
+		slog.Info("discord voice mode listening", "channel", cfg.VoiceChannel)
+	}
+
 	cleanup := func() {
+		leaveVoice()
 		if err := plugin.Stop(); err != nil {
 			slog.Warn("discord plugin stop", "error", err)
 		}
 	}
 	return cleanup, nil
 }
+
+// pruneAges converts DISCORD_PRUNE_DAYS to the plugin's retention ages.
+func pruneAges(days map[string]int) map[string]time.Duration {
+	if len(days) == 0 {
+		return nil
+	}
+	ages := make(map[string]time.Duration, len(days))
+	for channel, n := range days {
+		ages[channel] = time.Duration(n) * 24 * time.Hour
+	}
+	return ages
+}
@@ -1,37 +1,81 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
+	// /timezone must work on images without a zoneinfo database.
+	_ "time/tzdata"
 
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	_ "modernc.org/sqlite"
 
 	"github.com/TheLazyLemur/switchboard/internal/api"
+	"github.com/TheLazyLemur/switchboard/internal/codesearch"
 	"github.com/TheLazyLemur/switchboard/internal/config"
 	"github.com/TheLazyLemur/switchboard/internal/core"
 	"github.com/TheLazyLemur/switchboard/internal/dashboard"
+	"github.com/TheLazyLemur/switchboard/internal/guilds"
+	"github.com/TheLazyLemur/switchboard/internal/hooks"
+	"github.com/TheLazyLemur/switchboard/internal/imagegen"
+	"github.com/TheLazyLemur/switchboard/internal/memory"
+	"github.com/TheLazyLemur/switchboard/internal/metrics"
 	"github.com/TheLazyLemur/switchboard/internal/permission"
+	"github.com/TheLazyLemur/switchboard/internal/persona"
+	"github.com/TheLazyLemur/switchboard/internal/policy"
+	"github.com/TheLazyLemur/switchboard/internal/redact"
 	"github.com/TheLazyLemur/switchboard/internal/skills"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/TheLazyLemur/switchboard/internal/tts"
+	"github.com/TheLazyLemur/switchboard/internal/version"
+	"github.com/TheLazyLemur/switchboard/internal/webhook"
+	"github.com/TheLazyLemur/switchboard/internal/workspace"
 	"github.com/pkg/errors"
 )
 
 func main() {
-	if err := run(); err != nil {
+	var err error
+	if len(os.Args) > 1 {
+		err = runCommand(os.Args[1], os.Args[2:])
+	} else {
+		err = run()
+	}
+	if err != nil {
 		slog.Error("fatal", "error", err)
 		os.Exit(1)
 	}
 }
 
 func run() error {
+	restored, err := restoreSecrets()
+	if err != nil {
+		return err
+	}
 	cfg, err := config.LoadFromEnv()
 	if err != nil {
 		return err
 	}
+	toolEnv := tools.EnvPolicy{Allow: cfg.ToolEnvAllowlist, Deny: cfg.ToolEnvDenylist}
+	if !restored {
+		if err := hideSecrets(toolEnv); err != nil {
+			slog.Warn("secrets stay visible to tool processes in /proc", "error", err)
+		}
+	}
 	if err := cfg.EnsureDirs(); err != nil {
 		return err
 	}
+	var tenants []config.Tenant
+	if cfg.TenantsFile != "" {
+		if tenants, err = config.LoadTenants(cfg.TenantsFile); err != nil {
+			return err
+		}
+	}
 
 	// Export the resolved MEMORY_DIR so the memory skill scripts pick it up
 	// when the model invokes them via the Bash tool.
@@ -44,12 +88,29 @@ func run() error {
 	}
 
 	hub := dashboard.NewHub()
+	hub.SetDropRate(cfg.Faults.WSDropRate)
 	go hub.Run()
 
+	// Redaction wraps the broadcast handler so secrets are scrubbed from both
+	// stdout and the dashboard log stream.
+	secrets := cfg.Secrets()
+	for _, t := range tenants {
+		secrets = append(secrets, t.APIKey)
+	}
+	redactor := redact.New(secrets)
+	// The logs also scrub the values users set with /env, which may be
+	// too common, e.g. "production", to scrub from chat replies.
+	logRedactor := redact.New(secrets)
 	baseHandler := slog.NewTextHandler(os.Stdout, nil)
-	slog.SetDefault(slog.New(dashboard.NewBroadcastHandler(hub, baseHandler)))
+	slog.SetDefault(slog.New(redact.NewHandler(dashboard.NewBroadcastHandler(hub, baseHandler), logRedactor)))
 
-	slog.Info("starting")
+	build := version.Get()
+	slog.Info("starting", "version", build.Version, "commit", build.Commit, "built", build.Date)
+	if cfg.Faults.Enabled() {
+		slog.Warn("fault injection is on; never run this in production",
+			"api_529", cfg.Faults.API529Rate, "discord_send_delay", cfg.Faults.DiscordSendDelay,
+			"discord_429", cfg.Faults.Discord429Rate, "ws_drop", cfg.Faults.WSDropRate)
+	}
 
 	skillsDir, err := skills.DefaultSkillsDir()
 	if err != nil {
@@ -62,21 +123,81 @@ func run() error {
 	skillList, _ := skillStore.List()
 	slog.Info("skills loaded", "count", len(skillList))
 
+	var notes *memory.Notes
+	if cfg.MemoryDir != "" {
+		notes = memory.NewNotes(cfg.MemoryDir)
+	}
+	egress, err := tools.NewEgress(tools.EgressConfig{
+		Proxy:            cfg.EgressProxy,
+		AllowedDomains:   cfg.EgressAllowedDomains,
+		DeniedDomains:    cfg.EgressDeniedDomains,
+		InternalNetworks: cfg.FetchAllowedNetworks,
+	})
+	if err != nil {
+		return err
+	}
+	registry, closeTools, err := loadToolRegistry(cfg, toolEnv, notes, egress)
+	if err != nil {
+		return err
+	}
+	defer closeTools()
+
+	usage, err := metrics.Open(cfg.MetricsDB)
+	if err != nil {
+		return err
+	}
+	defer usage.Close()
+
+	guildSettings, err := guilds.Open(cfg.GuildSettingsDB)
+	if err != nil {
+		return err
+	}
+	defer guildSettings.Close()
+
 	base := api.BackendFactory{
-		APIKey:               cfg.APIKey,
-		BaseURL:              cfg.BaseURL,
-		Model:                cfg.Model,
-		DefaultWorkDir:       cfg.AgentCWD,
-		SkillStore:           skillStore,
-		WebSearchAPIKey:      cfg.WebSearchAPIKey,
-		ThinkingBudgetTokens: cfg.ThinkingBudgetTokens,
+		APIKey:                 cfg.APIKey,
+		BaseURL:                cfg.BaseURL,
+		Model:                  cfg.Model,
+		DefaultWorkDir:         cfg.AgentCWD,
+		AllowedDirs:            cfg.AllowedDirs,
+		SkillStore:             skillStore,
+		WebSearchAPIKey:        cfg.WebSearchAPIKey,
+		ThinkingBudgetTokens:   cfg.ThinkingBudgetTokens,
+		ToolEnv:                toolEnv,
+		Registry:               registry,
+		Notes:                  notes,
+		Usage:                  usage,
+		ToolHooks:              tools.ToolHooks{Pre: cfg.PreToolHook, Post: cfg.PostToolHook},
+		SessionBranches:        cfg.SessionBranches,
+		VerifyTrustedDirs:      cfg.VerifyTrustedDirs,
+		ToolOutputBudgetTokens: cfg.ToolOutputBudgetTokens,
+		RecordDir:              cfg.APIRecordDir,
+		API529Rate:             cfg.Faults.API529Rate,
+		Egress:                 egress,
+	}
+	var webCache *tools.WebCache
+	if cfg.WebCacheDir != "" {
+		if webCache, err = tools.NewWebCache(cfg.WebCacheDir, time.Duration(cfg.WebCacheTTLMinutes)*time.Minute); err != nil {
+			return err
+		}
+		base.WebCache = webCache
 	}
 	baseFactory := core.BackendFactory(&base)
 
 	defaultPerms := core.PermissionChecker(permission.NewAutoApprovePermissionChecker(cfg.AllowedDirs))
+	readOnlyPerms := core.PermissionChecker(permission.NewReadOnlyPermissionChecker(cfg.AllowedDirs))
+	var scriptHooks *hooks.Hooks
+	if cfg.HooksFile != "" {
+		if scriptHooks, err = hooks.Load(cfg.HooksFile); err != nil {
+			return err
+		}
+		defaultPerms = scriptHooks.Checker(defaultPerms, true)
+		readOnlyPerms = scriptHooks.Checker(readOnlyPerms, false)
+		slog.Info("hooks loaded", "file", cfg.HooksFile)
+	}
 
-	// Memory flush runs one final agent turn before each session reset (triggered
-	// by a SessionKey change), so the model can persist durable facts. Disable
+	// Memory flush runs one final agent turn before a session is reset or
+	// evicted, so the model can persist durable facts. Disable
 	// with MEMORY_FLUSH_DISABLED=1.
 	var flushFn core.FlushFunc
 	if os.Getenv("MEMORY_FLUSH_DISABLED") != "1" {
@@ -85,34 +206,250 @@ func run() error {
 
 	baseSessionMgr := core.NewSessionManager(baseFactory, flushFn)
 	defer baseSessionMgr.Close()
+	contentPolicy, err := loadContentPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
 	bot := core.NewBot(baseSessionMgr, defaultPerms)
+	bot.SetMaxConcurrentSessions(cfg.MaxConcurrentSessions)
+	bot.SetTurnTokenLimit(int64(cfg.TurnTokenLimit))
+	bot.SetTurnCache(time.Duration(cfg.TurnCacheMinutes) * time.Minute)
+	bot.SetFeedback(usage, cfg.FeedbackReactions)
+	bot.SetAdmins(cfg.AdminUsers)
+	bot.SetLogScrubber(logRedactor)
+	bot.SetReadOnlyChecker(readOnlyPerms)
+	bot.SetSkillScaffolder(skillStore)
+	if notes != nil {
+		bot.SetPinner(notes)
+		bot.SetAnswerIndex(notes)
+	}
+	if cfg.PersonasDir != "" {
+		bot.SetPersonas(persona.NewStore(cfg.PersonasDir))
+	}
+	if webCache != nil {
+		bot.SetWebCache(webCache)
+	}
+	bot.SetWorkspaces(workspaces(cfg.Workspaces))
+	bot.SetGuildStore(guildSettings)
+	closeTenants := addTenants(bot, tenants, base, scriptHooks, flushFn != nil)
+	defer closeTenants()
+	if cfg.CloneRoot != "" {
+		bot.SetCloner(&workspace.Cloner{
+			Root:     cfg.CloneRoot,
+			Hosts:    cfg.CloneAllowedHosts,
+			MaxBytes: int64(cfg.CloneMaxMB) << 20,
+			Env:      toolEnv.Filter(os.Environ()),
+		})
+	}
+	bot.SetLanguage(core.Lang(cfg.Language))
+	bot.SetScratch(filepath.Join(os.TempDir(), "switchboard-scratch"), time.Duration(cfg.ScratchTTLMinutes)*time.Minute)
+	if scriptHooks != nil {
+		bot.SetHooks(scriptHooks)
+	}
+	if cfg.TTSProvider != "" {
+		bot.SetSpeaker(tts.NewOpenAI(cfg.TTSBaseURL, cfg.TTSAPIKey, cfg.TTSModel, cfg.TTSVoice))
+	}
+	var shares *dashboard.ShareStore
+	if cfg.ShareBaseURL != "" {
+		shares = dashboard.NewShareStore(cfg.ShareBaseURL, time.Duration(cfg.ShareTTLHours)*time.Hour)
+		bot.SetSharer(shares)
+	}
+	releases := version.NewChecker(version.DefaultReleasesURL, cfg.UpdateCheck)
+	bot.SetReleases(releases)
+	// Policy runs first so sensitive-file fingerprints see the raw text.
+	bot.AddOutboundFilter(contentPolicy.Filter)
+	bot.AddOutboundFilter(redactor.Redact)
+
+	// Cancelled on SIGINT/SIGTERM, which cancels in-flight turns too.
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	go releases.Run(ctx, 24*time.Hour)
+	if cfg.WebhookOutURL != "" {
+		mirror := webhook.New(cfg.WebhookOutURL, cfg.WebhookOutSecret)
+		bot.SetMirror(mirror)
+		go mirror.Run(ctx)
+	}
+	if cfg.BatchEnabled {
+		batches := base.Batches()
+		bot.SetBatcher(batches)
+		go batches.Run(ctx, time.Minute)
+	}
 
 	if cfg.DiscordEnabled() {
-		stop, err := startDiscord(cfg, bot)
+		stop, err := startDiscord(ctx, cfg, bot)
 		if err != nil {
 			return err
 		}
 		defer stop()
 	}
 
+	var pairing dashboard.WhatsAppPairing
 	if cfg.WhatsAppEnabled() {
-		stop, err := startWhatsApp(cfg, hub, bot)
+		stop, p, err := startWhatsApp(ctx, cfg, hub, bot)
 		if err != nil {
 			return err
 		}
 		defer stop()
+		pairing = p
 	}
 
-	stopServer, err := startHTTPServer(cfg, hub, bot, baseSessionMgr, defaultPerms, skillStore, skillsDir)
+	stopPlugins, err := startPlatformPlugins(ctx, bot)
+	if err != nil {
+		return err
+	}
+	defer stopPlugins()
+	// Status updates are rate limited; a few seconds of lag is fine.
+	go bot.RunPresence(ctx, 15*time.Second)
+
+	stopServer, err := startHTTPServer(ctx, cfg, hub, bot, baseSessionMgr, defaultPerms, skillStore, skillsDir, shares, usage, releases, pairing)
 	if err != nil {
 		return errors.Wrap(err, "start HTTP server")
 	}
 	defer stopServer()
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
-	<-sig
+	<-ctx.Done()
 
 	slog.Info("shutting down")
 	return nil
 }
+
+// loadContentPolicy compiles CONTENT_POLICY_FILE, or the built-in default
+// when it is unset.
+func loadContentPolicy(cfg *config.Config) (*policy.Policy, error) {
+	if cfg.ContentPolicyPath != "" {
+		return policy.Load(cfg.ContentPolicyPath)
+	}
+	return policy.Compile(policy.DefaultFile(cfg.AllowedDirs))
+}
+
+// addTenants gives each tenant its own sessions, billed to its API key,
+// recorded under its name and confined to its directories. The returned
+// func closes them.
+func addTenants(bot *core.Bot, tenants []config.Tenant, base api.BackendFactory, scriptHooks *hooks.Hooks, flush bool) func() {
+	var managers []*core.SessionManager
+	for _, t := range tenants {
+		f := base
+		f.APIKey = t.APIKey
+		f.AllowedDirs = t.AllowedDirs
+		f.DefaultWorkDir = t.Workspaces[0].Path
+		f.Tenant = t.Name
+		perms := core.PermissionChecker(permission.NewAutoApprovePermissionChecker(t.AllowedDirs))
+		readOnly := core.PermissionChecker(permission.NewReadOnlyPermissionChecker(t.AllowedDirs))
+		if scriptHooks != nil {
+			perms = scriptHooks.Checker(perms, true)
+			readOnly = scriptHooks.Checker(readOnly, false)
+		}
+		var flushFn core.FlushFunc
+		if flush {
+			flushFn = core.NewMemoryFlusher(perms)
+		}
+		mgr := core.NewSessionManager(&f, flushFn)
+		managers = append(managers, mgr)
+		bot.AddTenant(core.Tenant{
+			Name:          t.Name,
+			GuildIDs:      t.Guilds,
+			Sessions:      mgr,
+			Perms:         perms,
+			ReadOnlyPerms: readOnly,
+			Workspaces:    workspaces(t.Workspaces),
+		})
+		slog.Info("tenant added", "tenant", t.Name, "guilds", len(t.Guilds))
+	}
+	return func() {
+		for _, m := range managers {
+			m.Close()
+		}
+	}
+}
+
+func workspaces(ws []config.Workspace) []core.Workspace {
+	out := make([]core.Workspace, len(ws))
+	for i, w := range ws {
+		out[i] = core.Workspace{Label: w.Label, Path: w.Path, Default: w.Default, ReadOnly: w.ReadOnly}
+	}
+	return out
+}
+
+// loadToolRegistry registers the optional tools enabled by config: script
+// tools, remember/recall, kube_*, generate_image, browse, repo_map,
+// sql_query and code_search. The returned func closes the databases.
+func loadToolRegistry(cfg *config.Config, env tools.EnvPolicy, notes *memory.Notes, egress *tools.Egress) (*core.ToolRegistry, func(), error) {
+	registry := core.NewToolRegistry()
+	var extra []core.RegisteredTool
+
+	if cfg.ScriptToolsDir != "" {
+		scripts, err := tools.LoadScriptTools(cfg.ScriptToolsDir, env)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "loading script tools")
+		}
+		slog.Info("script tools loaded", "count", len(scripts))
+		extra = append(extra, scripts...)
+	}
+
+	if notes != nil {
+		extra = append(extra, tools.NoteTools(notes)...)
+	}
+
+	if len(cfg.KubeContexts) > 0 {
+		extra = append(extra, tools.KubeReadTools(cfg.KubeContexts, env)...)
+		if cfg.KubeAllowWrites {
+			extra = append(extra, tools.KubeWriteTools(cfg.KubeContexts, env)...)
+		}
+	}
+
+	switch cfg.ImageProvider {
+	case "openai":
+		extra = append(extra, tools.GenerateImageTool(imagegen.NewOpenAI(cfg.ImageBaseURL, cfg.ImageAPIKey, cfg.ImageModel), cfg.ImageDailyLimit))
+	case "sdwebui":
+		extra = append(extra, tools.GenerateImageTool(imagegen.NewSDWebUI(cfg.ImageBaseURL), cfg.ImageDailyLimit))
+	}
+
+	if cfg.BrowseEnabled {
+		extra = append(extra, tools.BrowseTool(cfg.BrowseAllowedHosts, egress))
+	}
+
+	if cfg.RepoMapEnabled {
+		extra = append(extra, tools.RepoMapTool())
+	}
+
+	dbs := make(map[string]*sql.DB)
+	var codeIndex *codesearch.Index
+	closeDBs := func() {
+		for _, db := range dbs {
+			_ = db.Close()
+		}
+		if codeIndex != nil {
+			_ = codeIndex.Close()
+		}
+	}
+	for _, d := range cfg.SQLDatabases {
+		db, err := sql.Open(d.Driver, tools.ReadOnlyDSN(d.Driver, d.DSN))
+		if err != nil {
+			closeDBs()
+			return nil, nil, errors.Wrapf(err, "opening database %s", d.Name)
+		}
+		dbs[d.Name] = db
+	}
+	if len(dbs) > 0 {
+		extra = append(extra, tools.SQLQueryTool(dbs))
+	}
+
+	if cfg.EmbeddingProvider == "openai" {
+		idx, err := codesearch.Open(cfg.CodeSearchIndex, codesearch.NewOpenAI(cfg.EmbeddingBaseURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel))
+		if err != nil {
+			closeDBs()
+			return nil, nil, err
+		}
+		codeIndex = idx
+		extra = append(extra, tools.CodeSearchTool(idx))
+	}
+
+	for _, t := range extra {
+		if err := registry.Register(t); err != nil {
+			closeDBs()
+			return nil, nil, errors.Wrap(err, "registering tools")
+		}
+	}
+	return registry, closeDBs, nil
+}
@@ -0,0 +1,7 @@
+//go:build console
+
+package main
+
+// Out-of-tree platforms are linked in the same way: a file like this one,
+// guarded by its own build tag, that blank-imports the plugin package.
+import _ "github.com/TheLazyLemur/switchboard/pkg/platform/console"
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/pkg/platform"
+	"github.com/pkg/errors"
+)
+
+// startPlatformPlugins initialises and starts every plugin registered with
+// package platform (see plugin_*.go for the build-tagged imports) and
+// returns a cleanup func that stops them in reverse order.
+func startPlatformPlugins(ctx context.Context, bot *core.Bot) (func(), error) {
+	var started []platform.Plugin
+	stop := func() {
+		for i := len(started) - 1; i >= 0; i-- {
+			if err := started[i].Stop(); err != nil {
+				slog.Warn("platform plugin stop", "plugin", started[i].ID(), "error", err)
+			}
+		}
+	}
+
+	for _, p := range platform.Plugins() {
+		id := p.ID()
+		if err := p.Init(platform.Settings(id, nil)); err != nil {
+			stop()
+			return nil, errors.Wrapf(err, "initialising %s plugin", id)
+		}
+		if err := p.Start(ctx, func(in core.Inbound) {
+			if err := bot.HandleInboundContext(ctx, in); err != nil {
+				slog.Error("handling "+id+" inbound", "error", err)
+			}
+		}); err != nil {
+			stop()
+			return nil, errors.Wrapf(err, "starting %s plugin", id)
+		}
+		started = append(started, p)
+		slog.Info("platform plugin started", "plugin", id)
+	}
+	return stop, nil
+}
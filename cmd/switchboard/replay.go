@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/TheLazyLemur/switchboard/internal/replay"
+	"github.com/pkg/errors"
+)
+
+// runReplay plays a session recorded under API_RECORD_DIR back to stdout.
+// It needs no configuration: the recording answers every API call.
+func runReplay(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening recording")
+	}
+	defer f.Close()
+	entries, err := replay.Read(f)
+	if err != nil {
+		return err
+	}
+	return replay.Run(context.Background(), entries, os.Stdout)
+}
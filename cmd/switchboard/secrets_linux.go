@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/pkg/errors"
+)
+
+// secretsFDEnv tells a re-executed bot which descriptor holds the
+// variables hideSecrets took out of its environment.
+const secretsFDEnv = "SWITCHBOARD_SECRETS_FD"
+
+// maxHiddenEnv keeps the hand-over within a pipe's default buffer, so the
+// write never waits for a reader that only exists after exec.
+const maxHiddenEnv = 64 << 10
+
+// hideSecrets re-executes the bot without the variables policy keeps from
+// tool processes, handing them over through a pipe. Tools run as the bot's
+// user, so they could otherwise read them from /proc/<bot pid>/environ,
+// which shows the environment the process started with whatever
+// os.Unsetenv does later. It only returns on error.
+func hideSecrets(policy tools.EnvPolicy) error {
+	environ := os.Environ()
+	kept := policy.Filter(environ)
+	if len(kept) == len(environ) {
+		return nil
+	}
+	keep := make(map[string]bool, len(kept))
+	for _, kv := range kept {
+		keep[kv] = true
+	}
+	var hidden bytes.Buffer
+	for _, kv := range environ {
+		if !keep[kv] {
+			hidden.WriteString(kv)
+			hidden.WriteByte(0)
+		}
+	}
+	if hidden.Len() > maxHiddenEnv {
+		return errors.Errorf("%d bytes of secrets is too many to hand over", hidden.Len())
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "finding executable")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return errors.Wrap(err, "creating pipe")
+	}
+	defer r.Close()
+	_, err = w.Write(hidden.Bytes())
+	w.Close()
+	if err != nil {
+		return errors.Wrap(err, "writing secrets")
+	}
+	// Exec keeps only descriptors without close-on-exec.
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, r.Fd(), syscall.F_SETFD, 0); errno != 0 {
+		return errors.Wrap(errno, "clearing close-on-exec")
+	}
+	env := append(kept, secretsFDEnv+"="+strconv.Itoa(int(r.Fd())))
+	return errors.Wrap(syscall.Exec(exe, os.Args, env), "re-executing")
+}
+
+// restoreSecrets reads back what hideSecrets handed over, reporting
+// whether the bot was re-executed. os.Setenv only changes the process's
+// own copy, so /proc still shows the clean environment.
+func restoreSecrets() (bool, error) {
+	fd := os.Getenv(secretsFDEnv)
+	if fd == "" {
+		return false, nil
+	}
+	_ = os.Unsetenv(secretsFDEnv)
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return true, errors.Errorf("invalid %s %q", secretsFDEnv, fd)
+	}
+	f := os.NewFile(uintptr(n), "secrets")
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return true, errors.Wrap(err, "reading secrets")
+	}
+	for _, kv := range strings.Split(string(data), "\x00") {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			if err := os.Setenv(name, value); err != nil {
+				return true, errors.Wrapf(err, "restoring %s", name)
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package main
+
+import "github.com/TheLazyLemur/switchboard/internal/tools"
+
+// hideSecrets is Linux-only: elsewhere tool processes running as the bot's
+// user can still read its environment, e.g. with ps -E on macOS.
+func hideSecrets(tools.EnvPolicy) error {
+	return nil
+}
+
+func restoreSecrets() (bool, error) {
+	return false, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"time"
@@ -11,14 +12,18 @@ import (
 	"github.com/TheLazyLemur/switchboard/internal/core"
 	dash "github.com/TheLazyLemur/switchboard/internal/dashboard"
 	"github.com/TheLazyLemur/switchboard/internal/handler"
+	"github.com/TheLazyLemur/switchboard/internal/mcp"
+	"github.com/TheLazyLemur/switchboard/internal/metrics"
 	"github.com/TheLazyLemur/switchboard/internal/skills"
+	"github.com/TheLazyLemur/switchboard/internal/version"
 	"github.com/pkg/errors"
 )
 
-// startHTTPServer mounts the webhook handler and the dashboard on a single
-// http.Server and starts listening. Returns a cleanup that performs a graceful
+// startHTTPServer mounts /healthz, the webhook handler, /share pages, the /mcp endpoint
+// and the dashboard on a single http.Server and starts listening. Returns a cleanup that performs a graceful
 // shutdown.
 func startHTTPServer(
+	ctx context.Context,
 	cfg *config.Config,
 	hub *dash.Hub,
 	bot *core.Bot,
@@ -26,12 +31,24 @@ func startHTTPServer(
 	perms core.PermissionChecker,
 	skillStore skills.SkillStore,
 	skillsDir string,
+	shares *dash.ShareStore,
+	usage *metrics.Store,
+	releases core.Releases,
+	pairing dash.WhatsAppPairing,
 ) (func(), error) {
 	dashboardServer := dash.NewServer(hub, sessionMgr, perms, skillStore, skillsDir, cfg.AgentCWD, cfg.AgentsDefaultPath, cfg.MemoryDir, cfg.DashboardPassword, nil)
+	dashboardServer.SetMetrics(usage)
+	dashboardServer.SetViewerPassword(cfg.ViewerPassword)
+	dashboardServer.SetAPIToken(cfg.DashboardToken)
+	dashboardServer.SetReleases(releases)
+	dashboardServer.SetBroadcaster(bot)
+	if pairing != nil {
+		dashboardServer.SetWhatsApp(pairing)
+	}
 
 	plug := dashboard.New(dashboard.Config{Hub: hub, Server: dashboardServer})
-	if err := plug.Start(context.Background(), func(in core.Inbound) {
-		if err := bot.HandleInbound(in); err != nil {
+	if err := plug.Start(ctx, func(in core.Inbound) {
+		if err := bot.HandleInboundContext(ctx, in); err != nil {
 			slog.Error("dashboard inbound", "error", err)
 		}
 	}); err != nil {
@@ -39,7 +56,14 @@ func startHTTPServer(
 	}
 
 	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
 	mux.Handle("/webhook", handler.NewWebhookHandler())
+	if shares != nil {
+		mux.Handle("/share/", shares)
+	}
+	if cfg.MCPServerToken != "" {
+		mux.Handle("/mcp", mcp.NewServer(bot, cfg.MCPServerToken, cfg.MCPAllowedChats))
+	}
 	mux.Handle("/", dashboardServer.Handler())
 	srv := &http.Server{Addr: ":" + cfg.WebhookPort, Handler: mux}
 
@@ -56,3 +80,13 @@ func startHTTPServer(
 		srv.Shutdown(ctx)
 	}, nil
 }
+
+// handleHealthz answers load balancer health checks with the running build.
+// It needs no login.
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		Status string `json:"status"`
+		version.Info
+	}{"ok", version.Get()})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/TheLazyLemur/switchboard/internal/channels/whatsapp"
 	"github.com/TheLazyLemur/switchboard/internal/config"
@@ -14,18 +15,20 @@ import (
 	"github.com/pkg/errors"
 	waow "go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
 )
 
 // startWhatsApp connects to WhatsApp, wires the plugin against bot, and
-// returns a cleanup func that disconnects and stops the plugin.
-func startWhatsApp(cfg *config.Config, hub *dashboard.Hub, bot *core.Bot) (func(), error) {
+// returns a cleanup func that disconnects and stops the plugin, plus the
+// pairing the dashboard's WhatsApp panel manages.
+func startWhatsApp(ctx context.Context, cfg *config.Config, hub *dashboard.Hub, bot *core.Bot) (func(), *whatsAppPairing, error) {
 	container, err := sqlstore.New(context.Background(), "sqlite", "file:"+cfg.WhatsAppDBPath+"?_pragma=foreign_keys(1)", nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "creating whatsapp store")
+		return nil, nil, errors.Wrap(err, "creating whatsapp store")
 	}
 	device, err := container.GetFirstDevice(context.Background())
 	if err != nil {
-		return nil, errors.Wrap(err, "getting whatsapp device")
+		return nil, nil, errors.Wrap(err, "getting whatsapp device")
 	}
 	waClient := waow.NewClient(device, nil)
 	waWrapper := whatsapp.NewClientWrapper(waClient)
@@ -35,46 +38,30 @@ func startWhatsApp(cfg *config.Config, hub *dashboard.Hub, bot *core.Bot) (func(
 		Downloader:     waWrapper,
 		AllowedSenders: cfg.WhatsAppAllowedSenders,
 		MediaDir:       cfg.WhatsAppMediaDir,
+		MaxResponseLen: cfg.WhatsAppMaxResponseLen,
+		Presence:       cfg.WhatsAppPresence,
 	})
 
-	if err := plugin.Start(context.Background(), func(in core.Inbound) {
-		if err := bot.HandleInbound(in); err != nil {
+	if err := plugin.Start(ctx, func(in core.Inbound) {
+		if err := bot.HandleInboundContext(ctx, in); err != nil {
 			slog.Error("handling whatsapp inbound", "error", err)
 		}
 	}); err != nil {
-		return nil, errors.Wrap(err, "starting whatsapp plugin")
+		return nil, nil, errors.Wrap(err, "starting whatsapp plugin")
 	}
 
 	waClient.AddEventHandler(plugin.HandleEvent)
+	bot.AddChannel(plugin.ID(), plugin)
 
+	pairing := &whatsAppPairing{client: waClient, hub: hub}
 	if waClient.Store.ID == nil {
-		qrChan, err := waClient.GetQRChannel(context.Background())
-		if err != nil {
-			_ = plugin.Stop()
-			return nil, errors.Wrap(err, "getting whatsapp QR channel")
-		}
-		if err := waClient.Connect(); err != nil {
-			_ = plugin.Stop()
-			return nil, errors.Wrap(err, "connecting whatsapp")
-		}
-		go func() {
-			for evt := range qrChan {
-				if evt.Event == "code" {
-					fmt.Println("Scan this QR code in WhatsApp > Linked Devices:")
-					qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
-					hub.BroadcastSticky(dashboard.Message{Type: "whatsapp_qr", Content: evt.Code})
-				} else {
-					slog.Info("whatsapp qr event", "event", evt.Event)
-					hub.ClearSticky()
-					hub.Broadcast(dashboard.Message{Type: "whatsapp_qr", Content: evt.Event})
-				}
-			}
-		}()
+		err = pairing.pair()
 	} else {
-		if err := waClient.Connect(); err != nil {
-			_ = plugin.Stop()
-			return nil, errors.Wrap(err, "connecting whatsapp")
-		}
+		err = errors.Wrap(waClient.Connect(), "connecting whatsapp")
+	}
+	if err != nil {
+		_ = plugin.Stop()
+		return nil, nil, err
 	}
 	slog.Info("whatsapp connected")
 
@@ -82,5 +69,96 @@ func startWhatsApp(cfg *config.Config, hub *dashboard.Hub, bot *core.Bot) (func(
 		waClient.Disconnect()
 		_ = plugin.Stop()
 	}
-	return cleanup, nil
+	return cleanup, pairing, nil
+}
+
+// whatsAppPairing implements dashboard.WhatsAppPairing over the whatsmeow
+// client.
+type whatsAppPairing struct {
+	client *waow.Client
+	hub    *dashboard.Hub
+
+	mu sync.Mutex
+	// gen counts pairings, so the QR loop of one abandoned by Repair
+	// stops touching the dashboard.
+	gen int
+}
+
+// pair connects without a stored device and shows each QR code on the
+// terminal and, as a sticky event, on the dashboard until one is scanned.
+func (p *whatsAppPairing) pair() error {
+	p.mu.Lock()
+	p.gen++
+	gen := p.gen
+	p.mu.Unlock()
+
+	// The channel lives as long as the pairing, not the caller's request.
+	qrChan, err := p.client.GetQRChannel(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "getting whatsapp QR channel")
+	}
+	if err := p.client.Connect(); err != nil {
+		return errors.Wrap(err, "connecting whatsapp")
+	}
+	go func() {
+		for evt := range qrChan {
+			p.mu.Lock()
+			stale := gen != p.gen
+			p.mu.Unlock()
+			if stale {
+				continue
+			}
+			if evt.Event == "code" {
+				fmt.Println("Scan this QR code in WhatsApp > Linked Devices:")
+				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
+				p.hub.BroadcastSticky(dashboard.WhatsAppQREvent{Content: evt.Code})
+			} else {
+				slog.Info("whatsapp qr event", "event", evt.Event)
+				p.hub.ClearSticky()
+				p.hub.Broadcast(dashboard.WhatsAppQREvent{Content: evt.Event})
+			}
+		}
+	}()
+	return nil
+}
+
+// Status reports the stored device and, when connected, every device
+// linked to its account.
+func (p *whatsAppPairing) Status(ctx context.Context) (dashboard.WhatsAppStatus, error) {
+	st := dashboard.WhatsAppStatus{Connected: p.client.IsConnected()}
+	id := p.client.Store.ID
+	if id == nil {
+		return st, nil
+	}
+	st.Paired = true
+	st.JID = id.ToNonAD().String()
+	st.PushName = p.client.Store.PushName
+	st.Platform = p.client.Store.Platform
+	if !st.Connected {
+		return st, nil
+	}
+	devices, err := p.client.GetUserDevices(ctx, []types.JID{id.ToNonAD()})
+	if err != nil {
+		return st, errors.Wrap(err, "listing whatsapp devices")
+	}
+	for _, d := range devices {
+		st.Devices = append(st.Devices, dashboard.WhatsAppDevice{
+			JID:     d.String(),
+			Primary: d.Device == 0,
+			This:    d.Device == id.Device,
+		})
+	}
+	return st, nil
+}
+
+// Repair unlinks the bot from the account, when it is linked, and starts a
+// fresh pairing.
+func (p *whatsAppPairing) Repair(ctx context.Context) error {
+	if p.client.Store.ID != nil {
+		if err := p.client.Logout(ctx); err != nil {
+			return errors.Wrap(err, "logging out of whatsapp")
+		}
+	}
+	p.client.Disconnect()
+	return p.pair()
 }
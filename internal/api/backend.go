@@ -2,15 +2,22 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"log/slog"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/TheLazyLemur/switchboard/internal/config"
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/faults"
+	"github.com/TheLazyLemur/switchboard/internal/media"
+	"github.com/TheLazyLemur/switchboard/internal/memory"
 	"github.com/TheLazyLemur/switchboard/internal/skills"
 	"github.com/TheLazyLemur/switchboard/internal/tools"
 	"github.com/anthropics/anthropic-sdk-go"
@@ -28,9 +35,37 @@ type Backend struct {
 	tools          []anthropic.ToolUnionParam
 	systemPrompt   string
 	workDir        string
-	skillStore     skills.SkillStore
-	webSearchAPIKey  string
+	toolDeps       tools.Deps
 	thinkingBudget int
+	// projectContext holds workDir's CLAUDE.md and README.md as read when
+	// the session was created.
+	projectContext string
+	// notes, when set, seeds memoryBlock from the first turn's identity
+	// and channel's pinned context.
+	notes       *memory.Notes
+	memoryBlock string
+	// usage, when set, is told about every finished turn.
+	usage  core.UsageRecorder
+	tenant string
+	// recorder, when set, records the session for replay.
+	recorder *recorder
+	// verify is workDir's verify config as read when the session was
+	// created.
+	verify tools.VerifyConfig
+	// branch is the session branch edits are committed to, if any.
+	// Guarded by mu.
+	branch *tools.SessionBranch
+	// persona overrides the system prompt, model and temperature. Set
+	// under mu while no turn is running.
+	persona core.Persona
+	// sources collects the current turn's WebSearch and Fetch pages.
+	sources *tools.Sources
+	// toolOutputBudget caps the tool output one turn returns, in tokens;
+	// 0 disables it. budgetLeft is the current turn's remainder in bytes.
+	toolOutputBudget int
+	budgetLeft       int
+	// wait replaces the retry sleep in tests.
+	wait func(ctx context.Context, d time.Duration) error
 
 	mu      sync.Mutex
 	running bool
@@ -39,8 +74,10 @@ type Backend struct {
 
 // NewBackend creates an API backend. workDir is checked for an AGENTS.md
 // file on every API call; its contents are appended to the system prompt.
-// thinkingBudget > 0 enables extended thinking with that token budget.
-func NewBackend(client anthropic.Client, model, systemPrompt, workDir string, tools []anthropic.ToolUnionParam, skillStore skills.SkillStore, webSearchAPIKey string, thinkingBudget int) *Backend {
+// toolDeps carries the per-session tool dependencies; its Outbound is
+// replaced with the caller's on every Converse. thinkingBudget > 0 enables
+// extended thinking with that token budget.
+func NewBackend(client anthropic.Client, model, systemPrompt, workDir string, tools []anthropic.ToolUnionParam, toolDeps tools.Deps, thinkingBudget int) *Backend {
 	if model == "" {
 		model = config.DefaultModel
 	}
@@ -52,16 +89,28 @@ func NewBackend(client anthropic.Client, model, systemPrompt, workDir string, to
 		tools:          tools,
 		systemPrompt:   systemPrompt,
 		workDir:        workDir,
-		skillStore:     skillStore,
-		webSearchAPIKey:  webSearchAPIKey,
+		toolDeps:       toolDeps,
 		thinkingBudget: thinkingBudget,
 	}
 }
 
 // effectiveSystemPrompt re-reads AGENTS.md from workDir on each call so live
 // edits to the file land in the next turn without restarting the session.
+// Project files and the memory block are fixed when the session starts.
 func (b *Backend) effectiveSystemPrompt() string {
-	return core.AppendAgentsContext(b.systemPrompt, core.LoadAgentsContext(b.workDir))
+	sys := b.systemPrompt
+	if b.persona.Prompt != "" {
+		sys = strings.TrimSpace(b.persona.Prompt + "\n\n" + sys)
+	}
+	sys = core.AppendAgentsContext(sys, b.projectContext)
+	sys = core.AppendAgentsContext(sys, core.LoadAgentsContext(b.workDir))
+	if b.memoryBlock == "" {
+		return sys
+	}
+	if sys == "" {
+		return b.memoryBlock
+	}
+	return sys + "\n\n" + b.memoryBlock
 }
 
 func (b *Backend) SessionID() string {
@@ -69,19 +118,36 @@ func (b *Backend) SessionID() string {
 }
 
 func (b *Backend) Close() error {
-	return nil
+	return b.leaveBranch()
 }
 
 const maxMailbox = 64
 
 func (b *Backend) Converse(ctx context.Context, in core.Inbound, out core.Outbound, perms core.PermissionChecker) (string, error) {
+	if b.recorder != nil {
+		b.recorder.inbound(in.Text)
+	}
 	if !b.claim(in) {
 		return "", nil
 	}
 
-	resp, err := b.runConversationLoop(ctx, out, perms)
+	ctx = core.WithIdentity(ctx, in)
+	stats := core.TurnStats{At: time.Now(), Tenant: b.tenant, UserID: in.UserID, ChannelID: in.ChannelID, Model: b.currentModel()}
+	since := b.snapshot(ctx, in.Settings)
+	b.sources = &tools.Sources{}
+	b.budgetLeft = b.toolOutputBudget * bytesPerToken
+	resp, err := b.runConversationLoop(ctx, out, perms, in.Settings, &stats)
 	if err != nil {
 		b.release()
+	} else {
+		resp = appendSources(resp, b.sources)
+		resp = b.appendChanges(ctx, in, out, since, resp)
+		b.commitTurn(ctx, in)
+	}
+	if b.usage != nil {
+		stats.Latency = time.Since(stats.At)
+		stats.Failed = err != nil
+		b.usage.RecordTurn(stats)
 	}
 	return resp, err
 }
@@ -101,23 +167,59 @@ func (b *Backend) claim(in core.Inbound) bool {
 	}
 
 	b.running = true
+	if len(b.history) == 0 && b.notes != nil {
+		pinned := b.notes.PinnedBlock(in.ChannelID)
+		b.memoryBlock = strings.TrimSpace(pinned + "\n\n" + b.notes.Block(in.UserID, in.ChannelID))
+	}
 	userText := renderUserMessage(in)
-	blocks := append([]anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(userText)}, steeringBlocks(b.mailbox)...)
+	blocks := append([]anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(userText)}, imageBlocks(in.Attachments)...)
+	blocks = append(blocks, steeringBlocks(b.mailbox)...)
 	b.mailbox = nil
 	b.history = append(b.history, anthropic.NewUserMessage(blocks...))
 	return true
 }
 
+// maxImagesPerMessage bounds the image blocks attached to one user turn;
+// the API rejects requests with too many images.
+const maxImagesPerMessage = 20
+
+// imageBlocks turns image attachments into vision content blocks so the
+// model sees them directly instead of having to Read the saved file.
+// Images that cannot be prepared are left to the <attachment> tag.
+func imageBlocks(attachments []core.AttachmentRef) []anthropic.ContentBlockParamUnion {
+	var blocks []anthropic.ContentBlockParamUnion
+	for _, a := range attachments {
+		if len(blocks) >= maxImagesPerMessage {
+			break
+		}
+		if !media.IsVisionImage(a.MIME) {
+			continue
+		}
+		data, mime, err := media.PrepareVisionImage(a.Path, a.MIME)
+		if err != nil {
+			slog.Warn("skipping image attachment", "path", a.Path, "error", err)
+			continue
+		}
+		blocks = append(blocks, anthropic.NewImageBlockBase64(mime, base64.StdEncoding.EncodeToString(data)))
+	}
+	return blocks
+}
+
 // renderUserMessage builds the text content for the user turn. When the
 // inbound carries attachments, <attachment> tags are appended after the
 // message text — one tag per attachment, matching the format used by
-// RenderWhatsAppBatch so skill matchers see a consistent format.
+// RenderWhatsAppBatch so skill matchers see a consistent format. Text
+// attachments (logs, patches, source) are also pasted inline under a
+// filename header.
 func renderUserMessage(in core.Inbound) string {
 	if len(in.Attachments) == 0 {
 		return in.Text
 	}
 	var b strings.Builder
 	b.WriteString(in.Text)
+	for _, a := range in.Attachments {
+		writeInlineText(&b, a)
+	}
 	for _, a := range in.Attachments {
 		b.WriteByte('\n')
 		b.WriteString(`<attachment path="`)
@@ -133,6 +235,31 @@ func renderUserMessage(in core.Inbound) string {
 	return b.String()
 }
 
+func writeInlineText(b *strings.Builder, a core.AttachmentRef) {
+	name := a.OriginalName
+	if name == "" {
+		name = filepath.Base(a.Path)
+	}
+	if !media.IsInlineText(name, a.MIME) {
+		return
+	}
+	text, truncated, err := media.ReadInlineText(a.Path)
+	if err != nil {
+		slog.Warn("inlining text attachment", "path", a.Path, "error", err)
+		return
+	}
+	b.WriteString("\n\n=== ")
+	b.WriteString(name)
+	b.WriteString(" ===\n")
+	b.WriteString(text)
+	if truncated {
+		fmt.Fprintf(b, "\n… (truncated at %d bytes; Read %s for the rest)", media.MaxInlineTextBytes, a.Path)
+	}
+	b.WriteString("\n=== end of ")
+	b.WriteString(name)
+	b.WriteString(" ===")
+}
+
 func escapeXMLAttr(s string) string {
 	var sb strings.Builder
 	xml.EscapeText(&sb, []byte(s))
@@ -172,6 +299,69 @@ func (b *Backend) release() {
 	b.running = false
 }
 
+// maxTranscriptToolText caps tool inputs and results in a transcript; the
+// reader needs to see what ran, not every line of output.
+const maxTranscriptToolText = 1000
+
+var _ core.Transcriber = (*Backend)(nil)
+
+// Transcript returns the conversation so far for /share. Tool calls and
+// results become "tool" entries; images are noted but not included.
+func (b *Backend) Transcript() ([]core.TranscriptEntry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return nil, false
+	}
+
+	var out []core.TranscriptEntry
+	for _, msg := range b.history {
+		role := string(msg.Role)
+		var text []string
+		flush := func() {
+			if len(text) > 0 {
+				out = append(out, core.TranscriptEntry{Role: role, Text: strings.Join(text, "\n\n")})
+				text = nil
+			}
+		}
+		for _, block := range msg.Content {
+			switch {
+			case block.OfText != nil:
+				text = append(text, block.OfText.Text)
+			case block.OfImage != nil:
+				text = append(text, "[image]")
+			case block.OfToolUse != nil:
+				flush()
+				input, _ := json.Marshal(block.OfToolUse.Input)
+				out = append(out, core.TranscriptEntry{Role: "tool", Text: block.OfToolUse.Name + " " + clipText(string(input), maxTranscriptToolText)})
+			case block.OfToolResult != nil:
+				flush()
+				var parts []string
+				for _, c := range block.OfToolResult.Content {
+					if c.OfText != nil {
+						parts = append(parts, c.OfText.Text)
+					} else if c.OfImage != nil {
+						parts = append(parts, "[image]")
+					}
+				}
+				out = append(out, core.TranscriptEntry{Role: "tool", Text: "→ " + clipText(strings.Join(parts, "\n"), maxTranscriptToolText)})
+			}
+		}
+		flush()
+	}
+	return out, true
+}
+
+func clipText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
 func steeringText(msg string) string {
 	return "<user_steering>" + msg + "</user_steering>"
 }
@@ -184,14 +374,38 @@ func steeringBlocks(msgs []string) []anthropic.ContentBlockParamUnion {
 	return blocks
 }
 
-func (b *Backend) runConversationLoop(ctx context.Context, out core.Outbound, perms core.PermissionChecker) (string, error) {
+func (b *Backend) runConversationLoop(ctx context.Context, out core.Outbound, perms core.PermissionChecker, settings core.Settings, stats *core.TurnStats) (string, error) {
 	var finalResponse string
+	var trimmed bool
 
 	for {
-		resp, err := b.client.Messages.New(ctx, b.buildParams())
+		resp, err := b.callAPI(ctx, out, settings.Generation)
+		if err != nil && contextTooLong(err) {
+			if !trimmed {
+				if n := b.trimHistory(); n > 0 {
+					trimmed = true
+					slog.Info("context window exceeded, trimmed history", "session", b.sessionID, "turns", n)
+					if out != nil {
+						_ = out.SendUpdate(trimmedNotice(n))
+					}
+					continue
+				}
+			}
+			slog.Warn("context window exceeded, dropping turn", "session", b.sessionID, "error", err)
+			b.dropCurrentTurn()
+			b.release()
+			return strings.TrimSpace(finalResponse + "\n" + tooLongReply), nil
+		}
 		if err != nil {
 			return finalResponse, errors.Wrap(err, "API call failed")
 		}
+		stats.InputTokens += resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens
+		stats.OutputTokens += resp.Usage.OutputTokens
+		thought, redacted, thinkingTokens := splitThinking(resp)
+		stats.ThinkingTokens += thinkingTokens
+		if settings.MirrorThinking() && out != nil && (thought != "" || redacted) {
+			_ = out.SendUpdate(thinkingUpdate(thought))
+		}
 
 		text, toolUses := splitContent(resp)
 		if text != "" {
@@ -212,7 +426,10 @@ func (b *Backend) runConversationLoop(ctx context.Context, out core.Outbound, pe
 			continue
 		}
 
-		toolResults, err := b.executeTools(ctx, toolUses, out, perms)
+		for _, tu := range toolUses {
+			stats.ToolCalls = append(stats.ToolCalls, tu.Name)
+		}
+		toolResults, err := b.executeTools(ctx, toolUses, out, perms, settings)
 		if err != nil {
 			return finalResponse, errors.Wrap(err, "tool execution failed")
 		}
@@ -221,13 +438,26 @@ func (b *Backend) runConversationLoop(ctx context.Context, out core.Outbound, pe
 	}
 }
 
-func (b *Backend) buildParams() anthropic.MessageNewParams {
+// buildParams builds the next request. gen's overrides win over the
+// persona's, which win over the session's defaults.
+func (b *Backend) buildParams(gen core.Generation) anthropic.MessageNewParams {
 	maxTokens := int64(8192)
-	if b.thinkingBudget > 0 && int64(b.thinkingBudget) >= maxTokens {
-		maxTokens = int64(b.thinkingBudget) + 4096
+	if gen.MaxTokens > 0 {
+		maxTokens = int64(gen.MaxTokens)
+	}
+	budget := b.thinkingBudget
+	switch {
+	case gen.ThinkingBudget > 0:
+		budget = gen.ThinkingBudget
+	case gen.ThinkingBudget < 0:
+		budget = 0
+	}
+	// The API needs room for the answer after the thinking budget.
+	if budget > 0 && int64(budget) >= maxTokens {
+		maxTokens = int64(budget) + 4096
 	}
 	params := anthropic.MessageNewParams{
-		Model:     anthropic.Model(b.model),
+		Model:     anthropic.Model(b.currentModel()),
 		MaxTokens: maxTokens,
 		Messages:  b.history,
 	}
@@ -242,8 +472,15 @@ func (b *Backend) buildParams() anthropic.MessageNewParams {
 		params.Tools = b.tools
 	}
 
-	if b.thinkingBudget > 0 {
-		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(int64(b.thinkingBudget))
+	temperature := b.persona.Temperature
+	if gen.Temperature != nil {
+		temperature = gen.Temperature
+	}
+	if budget > 0 {
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(int64(budget))
+	} else if temperature != nil {
+		// The API rejects a temperature alongside extended thinking.
+		params.Temperature = anthropic.Float(*temperature)
 	}
 
 	return params
@@ -263,14 +500,18 @@ func splitContent(resp *anthropic.Message) (text string, tools []anthropic.ToolU
 	return
 }
 
-func (b *Backend) executeTools(ctx context.Context, toolUses []anthropic.ToolUseBlock, out core.Outbound, perms core.PermissionChecker) ([]anthropic.ContentBlockParamUnion, error) {
+func (b *Backend) executeTools(ctx context.Context, toolUses []anthropic.ToolUseBlock, out core.Outbound, perms core.PermissionChecker, settings core.Settings) ([]anthropic.ContentBlockParamUnion, error) {
 	var results []anthropic.ContentBlockParamUnion
+	var edited bool
 
 	for _, tu := range toolUses {
 		slog.Info("executing tool", "name", tu.Name, "id", tu.ID)
 
+		// Registered tools define their own schema, so their input may not
+		// fit ToolInput; the permission check then sees only what decoded.
+		registered, isRegistered := b.toolDeps.Registry.Lookup(tu.Name)
 		var input core.ToolInput
-		if err := json.Unmarshal(tu.Input, &input); err != nil {
+		if err := json.Unmarshal(tu.Input, &input); err != nil && !isRegistered {
 			results = append(results, anthropic.NewToolResultBlock(tu.ID, "Invalid input: "+err.Error(), true))
 			continue
 		}
@@ -281,11 +522,35 @@ func (b *Backend) executeTools(ctx context.Context, toolUses []anthropic.ToolUse
 			continue
 		}
 
-		deps := tools.Deps{Outbound: out, SkillStore: b.skillStore, WebSearchAPIKey: b.webSearchAPIKey}
-		result, isError := tools.Execute(tu.Name, input, deps)
+		deps := b.toolDeps
+		deps.Outbound = out
+		deps.Sources = b.sources
+		deps.SessionEnv = settings.Env
+		call := tools.ToolCall{Tool: tu.Name, Input: tu.Input, SessionID: b.sessionID, WorkDir: deps.WorkDir}
+		if ok, reason := tools.RunPreToolHook(ctx, deps, call); !ok {
+			results = append(results, anthropic.NewToolResultBlock(tu.ID, "Blocked by hook: "+reason, true))
+			continue
+		}
+		start := time.Now()
+		var result string
+		var isError bool
+		if isRegistered {
+			result, isError = registered.Execute(ctx, tu.Input, out)
+		} else {
+			result, isError = tools.Execute(ctx, tu.Name, input, deps)
+		}
+		result = tools.RunPostToolHook(ctx, deps, call, result, isError)
+		result = b.fitToolOutput(result)
+		edited = edited || b.verify.Triggered(tu.Name, input)
+		if settings.MirrorTools() && out != nil && core.MirrorsToolActivity(tu.Name) {
+			_ = out.SendUpdate(core.FormatToolActivity(tu.Name, input, time.Since(start), isError))
+		}
 		results = append(results, buildToolResultBlock(tu.ID, result, isError))
 	}
 
+	if edited && !settings.ReadOnly {
+		results = append(results, b.runVerify(ctx, out, settings.Env))
+	}
 	return results, nil
 }
 
@@ -319,14 +584,48 @@ func buildToolResultBlock(id, result string, isError bool) anthropic.ContentBloc
 
 // BackendFactory creates API backends
 type BackendFactory struct {
-	APIKey               string
-	BaseURL              string
-	Model                string
-	DefaultWorkDir       string
-	SkillStore           skills.SkillStore
-	WebSearchAPIKey      string
+	APIKey         string
+	BaseURL        string
+	Model          string
+	DefaultWorkDir string
+	// AllowedDirs are enforced again by the Read executor.
+	AllowedDirs     []string
+	SkillStore      skills.SkillStore
+	WebSearchAPIKey string
+	// WebCache, when set, is shared by every backend's Fetch and WebSearch.
+	WebCache *tools.WebCache
+	// Egress, when set, limits and proxies Fetch and WebSearch.
+	Egress *tools.Egress
 	// ThinkingBudgetTokens > 0 enables extended thinking on every API call.
 	ThinkingBudgetTokens int
+	// ToolEnv filters the environment inherited by Bash tool processes.
+	ToolEnv tools.EnvPolicy
+	// Registry adds startup-registered tools to every backend.
+	Registry *core.ToolRegistry
+	// Notes, when set, puts the channel's pinned context and the user's and
+	// channel's remembered notes in each new session's system prompt.
+	Notes *memory.Notes
+	// Usage, when set, records tokens, tool calls and latency per turn.
+	Usage core.UsageRecorder
+	// Tenant is recorded with every turn so each tenant can be billed for
+	// its own usage. Empty outside multi-tenant mode.
+	Tenant string
+	// ToolHooks run operator commands around every tool call.
+	ToolHooks tools.ToolHooks
+	// SessionBranches puts each session in a git work tree on its own
+	// branch and commits every turn's edits to it.
+	SessionBranches bool
+	// ToolOutputBudgetTokens caps the tool output each turn returns.
+	ToolOutputBudgetTokens int
+	// VerifyTrustedDirs are the directories whose .switchboard.yaml verify
+	// commands may run; sessions elsewhere never run them.
+	VerifyTrustedDirs []string
+	// RecordDir, when set, gets one JSONL recording per session of its
+	// messages and API responses, for `switchboard replay`.
+	RecordDir string
+	// API529Rate fails that share of API calls with a synthetic 529
+	// (FAULT_INJECTION api_529), to exercise the retry path.
+	API529Rate float64
 }
 
 var _ core.BackendFactory = (*BackendFactory)(nil)
@@ -342,6 +641,14 @@ func (f *BackendFactory) Create(workDir string, caps core.Capabilities) (core.Ba
 	if f.BaseURL != "" {
 		opts = append(opts, option.WithBaseURL(f.BaseURL))
 	}
+	var rec *recorder
+	if f.RecordDir != "" {
+		rec = &recorder{}
+		opts = append(opts, option.WithMiddleware(rec.middleware))
+	}
+	if f.API529Rate > 0 {
+		opts = append(opts, option.WithMiddleware(faults.APIMiddleware(f.API529Rate)))
+	}
 
 	client := anthropic.NewClient(opts...)
 
@@ -352,11 +659,42 @@ func (f *BackendFactory) Create(workDir string, caps core.Capabilities) (core.Ba
 	if caps.Media {
 		parts = append(parts, core.WhatsAppMediaSystemPromptAddendum)
 	}
+	if caps.Files {
+		parts = append(parts, "Use write_artifact for patches, scripts and configs longer than a few lines instead of inline code blocks.")
+	}
 	base := strings.Join(parts, "\n")
-	apiTools := buildChatTools(caps)
+	apiTools := append(buildChatTools(caps), buildToolParams(f.Registry.Defs())...)
 	systemPrompt := core.BuildSystemPrompt(base, f.SkillStore)
 
-	return NewBackend(client, f.Model, systemPrompt, workDir, apiTools, f.SkillStore, f.WebSearchAPIKey, f.ThinkingBudgetTokens), nil
+	deps := tools.Deps{SkillStore: f.SkillStore, WebSearchAPIKey: f.WebSearchAPIKey, WebCache: f.WebCache, Egress: f.Egress, Env: f.ToolEnv, WorkDir: workDir, AllowedDirs: f.AllowedDirs, Registry: f.Registry, Hooks: f.ToolHooks}
+	backend := NewBackend(client, f.Model, systemPrompt, workDir, apiTools, deps, f.ThinkingBudgetTokens)
+	backend.projectContext = core.LoadProjectContext(workDir)
+	if tools.VerifyTrusted(workDir, f.VerifyTrustedDirs) {
+		if project, err := tools.LoadProjectConfig(workDir); err != nil {
+			slog.Warn("ignoring project config", "dir", workDir, "error", err)
+		} else {
+			backend.verify = project.Verify
+		}
+	}
+	backend.notes = f.Notes
+	backend.toolOutputBudget = f.ToolOutputBudgetTokens
+	backend.usage = f.Usage
+	backend.tenant = f.Tenant
+	if rec != nil {
+		if err := rec.open(f.RecordDir, backend.sessionID); err != nil {
+			slog.Warn("not recording session", "session", backend.sessionID, "error", err)
+		} else {
+			backend.recorder = rec
+		}
+	}
+	if f.SessionBranches {
+		branch, err := tools.StartBranch(context.Background(), deps, tools.SessionBranchPrefix+backend.sessionID)
+		if err != nil {
+			slog.Warn("starting session branch", "dir", workDir, "error", err)
+		}
+		backend.branch = branch
+	}
+	return backend, nil
 }
 
 func buildToolParams(defs []core.ToolDef) []anthropic.ToolUnionParam {
@@ -380,6 +718,9 @@ func buildChatTools(caps core.Capabilities) []anthropic.ToolUnionParam {
 	if caps.Reactions {
 		allTools = append(allTools, core.ReactEmojiTool())
 	}
+	if caps.Files {
+		allTools = append(allTools, core.WriteArtifactTool())
+	}
 	allTools = append(allTools, core.FileTools()...)
 	allTools = append(allTools, core.SkillTools()...)
 	return buildToolParams(allTools)
@@ -1,8 +1,12 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"image"
+	"image/png"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +18,7 @@ import (
 	"time"
 
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/memory"
 	"github.com/TheLazyLemur/switchboard/internal/tools"
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
@@ -58,6 +63,24 @@ func TestBuildChatTools_IncludesReactEmojiWhenReactionsTrue(t *testing.T) {
 	assert.True(t, found, "expected react_emoji in tool list")
 }
 
+func TestBuildChatTools_WriteArtifactFollowsFilesCapability(t *testing.T) {
+	// given
+	// ... a helper that reports whether write_artifact was registered
+	has := func(caps core.Capabilities) bool {
+		for _, tool := range buildChatTools(caps) {
+			if tool.OfTool != nil && tool.OfTool.Name == "write_artifact" {
+				return true
+			}
+		}
+		return false
+	}
+
+	// when / then
+	// ... it is only offered to channels that can send files
+	assert.True(t, has(core.Capabilities{Files: true}))
+	assert.False(t, has(core.Capabilities{}))
+}
+
 func TestBuildChatTools_IncludesSendUpdateWhenUpdatesTrue(t *testing.T) {
 	// given
 	// ... updates enabled
@@ -332,6 +355,81 @@ func TestEffectiveSystemPrompt_AgentsFileRefreshedPerCall(t *testing.T) {
 	}
 }
 
+func TestEffectiveSystemPrompt_MemoryBlockFixedAtFirstTurn(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a backend with a saved user note
+	notes := memory.NewNotes(t.TempDir())
+	r.NoError(notes.Set(memory.UserScope("discord:1"), "style", "terse"))
+	b := &Backend{systemPrompt: "BASE", workDir: t.TempDir(), notes: notes}
+
+	// when
+	// ... the first turn is claimed, then the note changes
+	r.True(b.claim(core.Inbound{Text: "hi", UserID: "discord:1"}))
+	r.NoError(notes.Set(memory.UserScope("discord:1"), "style", "verbose"))
+	b.release()
+	r.True(b.claim(core.Inbound{Text: "again", UserID: "discord:1"}))
+
+	// then
+	// ... the prompt keeps the block from the session start
+	got := b.effectiveSystemPrompt()
+	a.Contains(got, "BASE\n\n<memory>")
+	a.Contains(got, "- style: terse")
+	a.NotContains(got, "verbose")
+}
+
+func TestEffectiveSystemPrompt_PinnedContextBeforeNotes(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a channel with pinned context and a user note
+	notes := memory.NewNotes(t.TempDir())
+	r.NoError(notes.PinContext("discord:9", "We use Go 1.22 and tabs."))
+	r.NoError(notes.Set(memory.UserScope("discord:1"), "style", "terse"))
+	b := &Backend{systemPrompt: "BASE", workDir: t.TempDir(), notes: notes}
+
+	// when
+	r.True(b.claim(core.Inbound{Text: "hi", UserID: "discord:1", ChannelID: "discord:9"}))
+
+	// then
+	got := b.effectiveSystemPrompt()
+	a.Contains(got, "BASE\n\n<channel_instructions>")
+	a.Contains(got, "We use Go 1.22 and tabs.\n</channel_instructions>\n\n<memory>")
+}
+
+func TestTranscript_RendersTurnsAndTools(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a history with a user turn, a tool round trip and a reply
+	b := &Backend{history: []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("list files")),
+		anthropic.NewAssistantMessage(anthropic.NewTextBlock("Checking."), anthropic.NewToolUseBlock("t1", map[string]any{"command": "ls"}, "Bash")),
+		anthropic.NewUserMessage(anthropic.NewToolResultBlock("t1", "a.go", false)),
+		anthropic.NewAssistantMessage(anthropic.NewTextBlock("One file: a.go")),
+	}}
+
+	// when
+	entries, ok := b.Transcript()
+	b.running = true
+	_, busyOK := b.Transcript()
+
+	// then
+	r.True(ok)
+	a.False(busyOK)
+	a.Equal([]core.TranscriptEntry{
+		{Role: "user", Text: "list files"},
+		{Role: "assistant", Text: "Checking."},
+		{Role: "tool", Text: `Bash {"command":"ls"}`},
+		{Role: "tool", Text: "→ a.go"},
+		{Role: "assistant", Text: "One file: a.go"},
+	}, entries)
+}
+
 func TestBuildParams_NoThinkingByDefault(t *testing.T) {
 	// given
 	// ... a backend with thinkingBudget unset
@@ -339,7 +437,7 @@ func TestBuildParams_NoThinkingByDefault(t *testing.T) {
 
 	// when
 	// ... params are built
-	params := b.buildParams()
+	params := b.buildParams(core.Generation{})
 
 	// then
 	// ... no thinking config is attached
@@ -355,7 +453,7 @@ func TestBuildParams_ThinkingEnabledWhenBudgetSet(t *testing.T) {
 
 	// when
 	// ... params are built
-	params := b.buildParams()
+	params := b.buildParams(core.Generation{})
 
 	// then
 	// ... params.Thinking carries the enabled config with that budget
@@ -365,6 +463,69 @@ func TestBuildParams_ThinkingEnabledWhenBudgetSet(t *testing.T) {
 	a.Equal(int64(4096), params.Thinking.OfEnabled.BudgetTokens)
 }
 
+func TestBuildParams_PersonaOverrides(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a backend switched to a persona with its own model and temperature
+	temp := 0.2
+	b := &Backend{model: "kimi-for-coding", systemPrompt: "BASE", workDir: t.TempDir()}
+	r.NoError(b.SetPersona(core.Persona{Name: "reviewer", Prompt: "Be terse.", Model: "claude-haiku-4-5", Temperature: &temp}))
+
+	// when
+	params := b.buildParams(core.Generation{})
+	b.thinkingBudget = 4096
+	thinking := b.buildParams(core.Generation{})
+	r.NoError(b.SetPersona(core.Persona{}))
+	reset := b.buildParams(core.Generation{})
+
+	// then
+	// ... the temperature is dropped when thinking is on, and a zero persona restores the defaults
+	a.Equal("claude-haiku-4-5", string(params.Model))
+	r.Len(params.System, 1)
+	a.Equal("Be terse.\n\nBASE", params.System[0].Text)
+	a.Equal(0.2, params.Temperature.Value)
+	a.False(thinking.Temperature.Valid())
+	a.Equal("kimi-for-coding", string(reset.Model))
+	a.Equal("BASE", reset.System[0].Text)
+}
+
+func TestBuildParams_GenerationOverrides(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a backend with a thinking budget and a persona temperature
+	persona, session := 0.2, 0.9
+	b := &Backend{model: "kimi-for-coding", thinkingBudget: 4096, workDir: t.TempDir()}
+	r.NoError(b.SetPersona(core.Persona{Name: "reviewer", Temperature: &persona}))
+
+	// when
+	off := b.buildParams(core.Generation{MaxTokens: 1000, Temperature: &session, ThinkingBudget: -1})
+	bigger := b.buildParams(core.Generation{MaxTokens: 2000, ThinkingBudget: 16000})
+
+	// then
+	// ... the session's temperature wins once thinking is off
+	a.Equal(int64(1000), off.MaxTokens)
+	a.Nil(off.Thinking.OfEnabled)
+	a.Equal(0.9, off.Temperature.Value)
+	// ... and max_tokens grows to fit a larger thinking budget
+	r.NotNil(bigger.Thinking.OfEnabled)
+	a.Equal(int64(16000), bigger.Thinking.OfEnabled.BudgetTokens)
+	a.Equal(int64(20096), bigger.MaxTokens)
+	a.False(bigger.Temperature.Valid())
+}
+
+func TestSetPersona_RefusedWhileRunning(t *testing.T) {
+	b := &Backend{running: true}
+
+	err := b.SetPersona(core.Persona{Name: "reviewer", Prompt: "x"})
+
+	assert.Error(t, err)
+	assert.Empty(t, b.CurrentPersona().Name)
+}
+
 func TestBackendFactory_Create_PropagatesThinkingBudget(t *testing.T) {
 	// given
 	// ... a factory configured with a thinking budget
@@ -732,6 +893,34 @@ func TestConverse_AppendsAttachmentTags(t *testing.T) {
 	a.Contains(got, `<attachment path="/tmp/doc.pdf" mime="application/pdf" original_name="doc.pdf" />`)
 }
 
+func TestConverse_InlinesTextAttachments(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a saved log file and an image
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "20260101-build.log")
+	r.NoError(os.WriteFile(logPath, []byte("FAIL: TestX\nexit 1"), 0o644))
+	in := core.Inbound{
+		Text: "why did this fail?",
+		Attachments: []core.AttachmentRef{
+			{Path: logPath, MIME: "application/octet-stream", OriginalName: "build.log"},
+			{Path: "/tmp/photo.png", MIME: "image/png", OriginalName: "photo.png"},
+		},
+	}
+
+	// when
+	got := renderUserMessage(in)
+
+	// then
+	// ... the log is pasted under its original name and both tags remain
+	a.Contains(got, "=== build.log ===\nFAIL: TestX\nexit 1\n=== end of build.log ===")
+	a.NotContains(got, "=== photo.png ===")
+	a.Contains(got, `original_name="build.log" />`)
+	a.Contains(got, `original_name="photo.png" />`)
+}
+
 func TestConverse_NoAttachments_NoTags(t *testing.T) {
 	a := assert.New(t)
 
@@ -749,6 +938,36 @@ func TestConverse_NoAttachments_NoTags(t *testing.T) {
 	a.NotContains(got, "<attachment")
 }
 
+func TestImageBlocks_AttachesImagesOnly(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a saved PNG, a PDF and a missing image
+	dir := t.TempDir()
+	pngPath := filepath.Join(dir, "photo.png")
+	var buf bytes.Buffer
+	r.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	r.NoError(os.WriteFile(pngPath, buf.Bytes(), 0o644))
+	attachments := []core.AttachmentRef{
+		{Path: pngPath, MIME: "image/png"},
+		{Path: filepath.Join(dir, "doc.pdf"), MIME: "application/pdf"},
+		{Path: filepath.Join(dir, "gone.jpg"), MIME: "image/jpeg"},
+	}
+
+	// when
+	blocks := imageBlocks(attachments)
+
+	// then
+	// ... only the readable image becomes a base64 image block
+	r.Len(blocks, 1)
+	r.NotNil(blocks[0].OfImage)
+	src := blocks[0].OfImage.Source.OfBase64
+	r.NotNil(src)
+	a.Equal(anthropic.Base64ImageSourceMediaType("image/png"), src.MediaType)
+	a.Equal(base64.StdEncoding.EncodeToString(buf.Bytes()), src.Data)
+}
+
 func writeMessageJSON(w http.ResponseWriter, id, text, stopReason string) {
 	payload := map[string]any{
 		"id":   id,
@@ -767,3 +986,185 @@ func writeMessageJSON(w http.ResponseWriter, id, text, stopReason string) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(payload)
 }
+
+type recordingResponder struct {
+	stubResponder
+	updates []string
+}
+
+func (r *recordingResponder) SendUpdate(msg string) error {
+	r.updates = append(r.updates, msg)
+	return nil
+}
+
+func TestExecuteTools_MirrorsToolActivityWhenVerbose(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a Read call for a missing file and a send_update call
+	b := &Backend{sessionID: "test"}
+	uses := []anthropic.ToolUseBlock{
+		{ID: "t1", Name: "Read", Input: json.RawMessage(`{"file_path":"/nonexistent/missing.txt"}`)},
+		{ID: "t2", Name: "send_update", Input: json.RawMessage(`{"message":"working"}`)},
+	}
+	out := &recordingResponder{}
+
+	// when
+	// ... tools run with tool verbosity
+	_, err := b.executeTools(context.Background(), uses, out, allowAllPerms{}, core.Settings{Verbosity: core.VerbosityTools})
+
+	// then
+	// ... the Read call is mirrored; send_update is delivered but not mirrored
+	r.NoError(err)
+	r.Len(out.updates, 2)
+	a.Equal("working", out.updates[1])
+	a.True(strings.HasPrefix(out.updates[0], "🔧 Read: /nonexistent/missing.txt ("), out.updates[0])
+	a.True(strings.HasSuffix(out.updates[0], ", error)"), out.updates[0])
+}
+
+func TestExecuteTools_QuietDoesNotMirror(t *testing.T) {
+	// given
+	b := &Backend{sessionID: "test"}
+	uses := []anthropic.ToolUseBlock{
+		{ID: "t1", Name: "Read", Input: json.RawMessage(`{"file_path":"/nonexistent/missing.txt"}`)},
+	}
+	out := &recordingResponder{}
+
+	// when
+	_, err := b.executeTools(context.Background(), uses, out, allowAllPerms{}, core.Settings{})
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, out.updates)
+}
+
+func TestExecuteTools_RunsRegisteredTool(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a registered tool whose input doesn't fit ToolInput
+	reg := core.NewToolRegistry()
+	var got string
+	r.NoError(reg.Register(core.RegisteredTool{
+		Def: core.ToolDef{Name: "deploy"},
+		Execute: func(_ context.Context, input json.RawMessage, _ core.Outbound) (string, bool) {
+			got = string(input)
+			return "deployed", false
+		},
+	}))
+	b := &Backend{sessionID: "test", toolDeps: tools.Deps{Registry: reg}}
+	uses := []anthropic.ToolUseBlock{
+		{ID: "t1", Name: "deploy", Input: json.RawMessage(`{"save":"prod"}`)},
+	}
+
+	// when
+	results, err := b.executeTools(context.Background(), uses, &recordingResponder{}, allowAllPerms{}, core.Settings{})
+
+	// then
+	r.NoError(err)
+	r.Len(results, 1)
+	a.Equal(`{"save":"prod"}`, got)
+	a.False(results[0].OfToolResult.IsError.Value)
+}
+
+func TestBackendFactory_Create_IncludesRegisteredTools(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	reg := core.NewToolRegistry()
+	r.NoError(reg.Register(core.RegisteredTool{
+		Def:     core.ToolDef{Name: "deploy", InputSchema: map[string]any{"type": "object"}},
+		Execute: func(context.Context, json.RawMessage, core.Outbound) (string, bool) { return "", false },
+	}))
+	factory := &BackendFactory{APIKey: "test", DefaultWorkDir: t.TempDir(), Registry: reg}
+
+	// when
+	backend, err := factory.Create("", core.Capabilities{})
+	r.NoError(err)
+
+	// then
+	var names []string
+	for _, tool := range backend.(*Backend).tools {
+		names = append(names, tool.OfTool.Name)
+	}
+	a.Contains(names, "deploy")
+}
+
+type usageRecorder struct {
+	turns []core.TurnStats
+}
+
+func (u *usageRecorder) RecordTurn(t core.TurnStats) { u.turns = append(u.turns, t) }
+
+func TestConverse_RecordsUsage(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... an API that replies once, then rejects the next request
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls > 1 {
+			http.Error(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`, http.StatusBadRequest)
+			return
+		}
+		writeMessageJSON(w, "msg_1", "hi", "end_turn")
+	}))
+	defer server.Close()
+	usage := &usageRecorder{}
+	b := &Backend{
+		client:    anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL)),
+		model:     "test-model",
+		sessionID: "test",
+		history:   []anthropic.MessageParam{},
+		usage:     usage,
+		tenant:    "team-a",
+	}
+	in := core.Inbound{Text: "hello", UserID: "discord:1", ChannelID: "discord:c"}
+
+	// when
+	_, err := b.Converse(context.Background(), in, stubResponder{}, allowAllPerms{})
+	r.NoError(err)
+	_, err = b.Converse(context.Background(), in, stubResponder{}, allowAllPerms{})
+	r.Error(err)
+
+	// then
+	// ... both turns are recorded with identity, tokens and outcome
+	r.Len(usage.turns, 2)
+	first := usage.turns[0]
+	a.Equal("team-a", first.Tenant)
+	a.Equal("discord:1", first.UserID)
+	a.Equal("discord:c", first.ChannelID)
+	a.Equal("test-model", first.Model)
+	a.Equal(int64(1), first.InputTokens)
+	a.Equal(int64(1), first.OutputTokens)
+	a.False(first.Failed)
+	a.True(usage.turns[1].Failed)
+	a.Zero(usage.turns[1].InputTokens)
+}
+
+func TestEstimateInputTokens_CountsImagesFlat(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... history holding 4000 bytes of text and a large base64 image
+	big := strings.Repeat("x", 4000)
+	b := &Backend{history: []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(big), anthropic.NewImageBlockBase64("image/png", strings.Repeat("A", 1<<20))),
+	}}
+	in := core.Inbound{Text: "hi", Attachments: []core.AttachmentRef{{Path: "/tmp/a.png", MIME: "image/png"}}}
+
+	// when
+	idle := b.EstimateInputTokens(in)
+	b.running = true
+	busy := b.EstimateInputTokens(in)
+
+	// then
+	// ... text counts at four bytes per token, each image at a flat rate
+	a.InDelta(1000+2*imageTokens, idle, 100)
+	a.Zero(busy, "a message that would steer a running turn is not estimated")
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/pkg/errors"
+)
+
+var _ core.Batcher = (*Batches)(nil)
+
+// batchSystemPrompt replaces the chat system prompt: a batch job gets one
+// reply and no tools.
+const batchSystemPrompt = "You are answering a single message submitted as a batch job. You have no tools and cannot ask follow-up questions, so answer completely in one reply."
+
+// batchMaxTokens caps a batch job's answer.
+const batchMaxTokens = 8192
+
+// Batches submits /batch prompts to the Message Batches API and delivers
+// each answer once Run sees its batch end. Jobs are held in memory, so an
+// answer pending at a restart is never delivered.
+type Batches struct {
+	client anthropic.Client
+	model  string
+	usage  core.UsageRecorder
+	tenant string
+
+	mu      sync.Mutex
+	pending map[string]batchPending
+}
+
+type batchPending struct {
+	submitted time.Time
+	deliver   func(answer string, err error)
+}
+
+// Batches returns a Batcher on f's API key, base URL and model that records
+// usage like f's sessions do.
+func (f *BackendFactory) Batches() *Batches {
+	opts := []option.RequestOption{option.WithAPIKey(f.APIKey)}
+	if f.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(f.BaseURL))
+	}
+	return newBatches(anthropic.NewClient(opts...), f.Model, f.Usage, f.Tenant)
+}
+
+func newBatches(client anthropic.Client, model string, usage core.UsageRecorder, tenant string) *Batches {
+	return &Batches{client: client, model: model, usage: usage, tenant: tenant, pending: make(map[string]batchPending)}
+}
+
+// Submit sends prompt as a one-request batch.
+func (b *Batches) Submit(ctx context.Context, prompt string, deliver func(answer string, err error)) (string, error) {
+	batch, err := b.client.Messages.Batches.New(ctx, anthropic.MessageBatchNewParams{
+		Requests: []anthropic.MessageBatchNewParamsRequest{{
+			CustomID: "prompt",
+			Params: anthropic.MessageBatchNewParamsRequestParams{
+				Model:     anthropic.Model(b.model),
+				MaxTokens: batchMaxTokens,
+				System:    []anthropic.TextBlockParam{{Text: batchSystemPrompt}},
+				Messages:  []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock(prompt))},
+			},
+		}},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "creating message batch")
+	}
+	b.mu.Lock()
+	b.pending[batch.ID] = batchPending{submitted: time.Now(), deliver: deliver}
+	b.mu.Unlock()
+	slog.Info("submitted batch job", "batch", batch.ID)
+	return batch.ID, nil
+}
+
+// Run checks pending batches every interval until ctx is done.
+func (b *Batches) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			b.poll(ctx)
+		}
+	}
+}
+
+// poll delivers every pending batch that has ended. A batch that can't be
+// checked is tried again on the next poll.
+func (b *Batches) poll(ctx context.Context) {
+	b.mu.Lock()
+	ids := make([]string, 0, len(b.pending))
+	for id := range b.pending {
+		ids = append(ids, id)
+	}
+	b.mu.Unlock()
+
+	for _, id := range ids {
+		batch, err := b.client.Messages.Batches.Get(ctx, id)
+		if err != nil {
+			slog.Warn("checking batch job", "batch", id, "error", err)
+			continue
+		}
+		if batch.ProcessingStatus != anthropic.MessageBatchProcessingStatusEnded {
+			continue
+		}
+		answer, msg, err := b.result(ctx, id)
+		if err != nil && ctx.Err() != nil {
+			// Shutting down, so there is nobody left to deliver to.
+			return
+		}
+		b.mu.Lock()
+		p, ok := b.pending[id]
+		delete(b.pending, id)
+		b.mu.Unlock()
+		if !ok {
+			continue
+		}
+		b.record(p, msg, err)
+		p.deliver(answer, err)
+	}
+}
+
+// result reads the answer of an ended batch's only request.
+func (b *Batches) result(ctx context.Context, id string) (string, *anthropic.Message, error) {
+	stream := b.client.Messages.Batches.ResultsStreaming(ctx, id)
+	defer stream.Close()
+	for stream.Next() {
+		r := stream.Current().Result
+		switch r.Type {
+		case "succeeded":
+			msg := r.Message
+			text, _ := splitContent(&msg)
+			return text, &msg, nil
+		case "errored":
+			return "", nil, core.MarkError(core.ErrUnavailable, errors.Errorf("batch request errored: %s", r.Error.Error.Message))
+		case "expired":
+			return "", nil, core.MarkError(core.ErrTimeout, errors.New("batch request expired"))
+		default:
+			return "", nil, errors.Errorf("batch request %s", r.Type)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return "", nil, errors.Wrap(err, "reading batch results")
+	}
+	return "", nil, errors.New("batch has no results")
+}
+
+func (b *Batches) record(p batchPending, msg *anthropic.Message, err error) {
+	if b.usage == nil {
+		return
+	}
+	stats := core.TurnStats{
+		At:      p.submitted,
+		Tenant:  b.tenant,
+		Model:   b.model,
+		Latency: time.Since(p.submitted),
+		Failed:  err != nil,
+	}
+	if msg != nil {
+		stats.InputTokens = msg.Usage.InputTokens
+		stats.OutputTokens = msg.Usage.OutputTokens
+	}
+	b.usage.RecordTurn(stats)
+}
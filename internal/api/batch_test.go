@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchServer serves one batch, in progress until ended is set, whose
+// only result is result.
+func batchServer(t *testing.T, ended *bool, result string) (*httptest.Server, *[]string) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/v1/messages/batches":
+			var body struct {
+				Requests []struct {
+					Params struct {
+						Messages []struct {
+							Content []struct {
+								Text string `json:"text"`
+							} `json:"content"`
+						} `json:"messages"`
+					} `json:"params"`
+				} `json:"requests"`
+			}
+			_ = json.NewDecoder(req.Body).Decode(&body)
+			prompts = append(prompts, body.Requests[0].Params.Messages[0].Content[0].Text)
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress"}`)
+		case req.URL.Path == "/v1/messages/batches/msgbatch_1":
+			status := "in_progress"
+			if *ended {
+				status = "ended"
+			}
+			fmt.Fprintf(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":%q}`, status)
+		case req.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			w.Header().Set("Content-Type", "application/x-jsonl")
+			fmt.Fprintln(w, `{"custom_id":"prompt","result":`+result+`}`)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &prompts
+}
+
+type answer struct {
+	text string
+	err  error
+}
+
+func TestBatches_DeliversAnswerOnceEnded(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	ended := false
+	server, prompts := batchServer(t, &ended, `{"type":"succeeded","message":{"id":"msg_1","type":"message","role":"assistant","model":"test-model","content":[{"type":"text","text":"All quiet."}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}}`)
+	usage := &usageRecorder{}
+	b := newBatches(anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL)), "test-model", usage, "")
+	var got []answer
+
+	// when
+	id, err := b.Submit(context.Background(), "summarise", func(text string, err error) { got = append(got, answer{text, err}) })
+	r.NoError(err)
+	b.poll(context.Background())
+	// ... still running, so nothing yet
+	a.Empty(got)
+	ended = true
+	b.poll(context.Background())
+	b.poll(context.Background())
+
+	// then
+	a.Equal("msgbatch_1", id)
+	a.Equal([]string{"summarise"}, *prompts)
+	r.Len(got, 1)
+	r.NoError(got[0].err)
+	a.Equal("All quiet.", got[0].text)
+	r.Len(usage.turns, 1)
+	a.Equal(int64(12), usage.turns[0].InputTokens)
+	a.Equal(int64(3), usage.turns[0].OutputTokens)
+}
+
+func TestBatches_ExpiredRequestIsATimeout(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	ended := true
+	server, _ := batchServer(t, &ended, `{"type":"expired"}`)
+	b := newBatches(anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL)), "test-model", nil, "")
+	var got []answer
+	_, err := b.Submit(context.Background(), "summarise", func(text string, err error) { got = append(got, answer{text, err}) })
+	r.NoError(err)
+
+	// when
+	b.poll(context.Background())
+
+	// then
+	r.Len(got, 1)
+	a.Equal(core.ErrTimeout, core.ErrorKindOf(got[0].err))
+}
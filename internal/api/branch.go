@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/pkg/errors"
+)
+
+var _ core.Brancher = (*Backend)(nil)
+
+// commitTurn commits what a turn changed to the session branch, if any.
+func (b *Backend) commitTurn(ctx context.Context, in core.Inbound) {
+	b.mu.Lock()
+	branch := b.branch
+	b.mu.Unlock()
+	if branch == nil || in.Settings.ReadOnly {
+		return
+	}
+	if err := branch.Commit(ctx, b.toolDeps, tools.CommitMessage(in.Text)); err != nil {
+		slog.Warn("committing to session branch", "session", b.sessionID, "branch", branch.Name, "error", err)
+	}
+}
+
+// leaveBranch checks the base branch out again when the session ends, so
+// the next session doesn't start from this one's branch.
+func (b *Backend) leaveBranch() error {
+	b.mu.Lock()
+	branch := b.branch
+	b.branch = nil
+	b.mu.Unlock()
+	if branch == nil {
+		return nil
+	}
+	if err := branch.Leave(context.Background(), b.toolDeps); err != nil {
+		return errors.Wrapf(err, "leaving session branch %s", branch.Name)
+	}
+	return nil
+}
+
+func (b *Backend) ApplyBranch(ctx context.Context) (string, string, error) {
+	return b.finishBranch(ctx, (*tools.SessionBranch).Apply)
+}
+
+func (b *Backend) DiscardBranch(ctx context.Context) (string, string, error) {
+	return b.finishBranch(ctx, (*tools.SessionBranch).Discard)
+}
+
+// finishBranch runs finish on the session branch while no turn can start,
+// and forgets the branch once it succeeds.
+func (b *Backend) finishBranch(ctx context.Context, finish func(*tools.SessionBranch, context.Context, tools.Deps) error) (string, string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.branch == nil {
+		return "", "", core.ErrNoBranch
+	}
+	if b.running {
+		return "", "", errors.New("a reply is still in progress")
+	}
+	branch := b.branch
+	if err := finish(branch, ctx, b.toolDeps); err != nil {
+		return "", "", err
+	}
+	b.branch = nil
+	return branch.Name, branch.Base, nil
+}
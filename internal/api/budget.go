@@ -0,0 +1,26 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+)
+
+// minToolResult is what a tool result may keep however much of the turn's
+// budget is spent, so late calls still show their errors.
+const minToolResult = 4000
+
+// fitToolOutput compacts result to what is left of the turn's tool output
+// budget and charges it. Images are priced separately and pass through.
+func (b *Backend) fitToolOutput(result string) string {
+	if b.toolOutputBudget <= 0 || strings.HasPrefix(result, tools.ImageSentinel+"\t") {
+		return result
+	}
+	allowed := max(b.budgetLeft, minToolResult)
+	if len(result) > allowed {
+		result = tools.CompactOutput(result, allowed) +
+			"\n[this turn's tool output budget is nearly spent; prefer commands with narrower output]"
+	}
+	b.budgetLeft -= len(result)
+	return result
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteTools_BudgetsToolOutput(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a tool printing 30KB and a turn budget of 10k tokens (40KB)
+	reg := core.NewToolRegistry()
+	big := strings.Repeat("line of output\n", 2000)
+	r.NoError(reg.Register(core.RegisteredTool{
+		Def:     core.ToolDef{Name: "dump"},
+		Execute: func(context.Context, json.RawMessage, core.Outbound) (string, bool) { return big, false },
+	}))
+	b := &Backend{
+		sessionID:        "test",
+		toolDeps:         tools.Deps{Registry: reg, WorkDir: t.TempDir()},
+		toolOutputBudget: 10000,
+		budgetLeft:       10000 * bytesPerToken,
+	}
+	uses := []anthropic.ToolUseBlock{
+		{ID: "t1", Name: "dump", Input: json.RawMessage(`{}`)},
+		{ID: "t2", Name: "dump", Input: json.RawMessage(`{}`)},
+	}
+
+	// when
+	results, err := b.executeTools(context.Background(), uses, &recordingResponder{}, allowAllPerms{}, core.Settings{})
+
+	// then
+	// ... the first result fits whole and the second is compacted to what is left
+	r.NoError(err)
+	r.Len(results, 2)
+	first := results[0].OfToolResult.Content[0].OfText.Text
+	second := results[1].OfToolResult.Content[0].OfText.Text
+	a.Equal(big, first)
+	a.Less(len(second), 10200)
+	a.Contains(second, "[output compacted:")
+	a.Contains(second, "tool output budget is nearly spent")
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+)
+
+// snapshot records workDir before a turn so appendChanges can report what
+// the turn did. Read-only sessions change nothing and skip it.
+func (b *Backend) snapshot(ctx context.Context, settings core.Settings) string {
+	if settings.ReadOnly {
+		return ""
+	}
+	tree, err := tools.Snapshot(ctx, b.toolDeps)
+	if err != nil {
+		slog.Warn("snapshotting work tree", "session", b.sessionID, "error", err)
+	}
+	return tree
+}
+
+// appendChanges adds a change summary footer to resp when the turn changed
+// files since the snapshot. Channels that take files also get the full
+// diff as changes.diff.
+func (b *Backend) appendChanges(ctx context.Context, in core.Inbound, out core.Outbound, since, resp string) string {
+	if since == "" {
+		return resp
+	}
+	changes, err := tools.Changes(ctx, b.toolDeps, since)
+	if err != nil {
+		slog.Warn("summarizing changes", "session", b.sessionID, "error", err)
+		return resp
+	}
+	if changes.Files == 0 {
+		return resp
+	}
+	if fs, ok := out.(core.FileSender); ok && in.Capabilities.Files {
+		if err := fs.SendFile("changes.diff", []byte(changes.Diff)); err != nil {
+			slog.Warn("sending changes diff", "session", b.sessionID, "error", err)
+		}
+	}
+	if resp == "" {
+		return changes.Footer()
+	}
+	return resp + "\n\n" + changes.Footer()
+}
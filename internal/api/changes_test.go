@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fileResponder struct {
+	stubResponder
+	files map[string]string
+}
+
+func (f *fileResponder) SendFile(name string, content []byte) error {
+	f.files[name] = string(content)
+	return nil
+}
+
+func TestAppendChanges(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	r.NoError(exec.Command("git", "init", "-q", dir).Run())
+	b := &Backend{sessionID: "test", toolDeps: tools.Deps{WorkDir: dir}}
+	in := core.Inbound{Capabilities: core.Capabilities{Files: true}}
+	since := b.snapshot(context.Background(), core.Settings{})
+	r.NotEmpty(since)
+	r.NoError(os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("one\ntwo\n"), 0o644))
+	out := &fileResponder{files: map[string]string{}}
+
+	// when
+	resp := b.appendChanges(context.Background(), in, out, since, "Done.")
+	unchanged := b.appendChanges(context.Background(), in, out, b.snapshot(context.Background(), core.Settings{}), "Done.")
+
+	// then
+	a.Equal("Done.\n\n📝 Changed: 1 file (+2/−0)", resp)
+	a.Contains(out.files["changes.diff"], "+++ b/notes.txt")
+	a.Equal("Done.", unchanged)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/media"
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+var _ core.TokenEstimator = (*Backend)(nil)
+
+const (
+	// bytesPerToken is a deliberately rough average for English and code.
+	bytesPerToken = 4
+	// imageTokens is what one image costs at most once resized to the
+	// API's 1568px edge limit, whatever its encoded size.
+	imageTokens = 1600
+)
+
+// EstimateInputTokens approximates the input of the first API call in's
+// turn would make: system prompt, tool schemas, history and the new user
+// message. It returns 0 while a turn is running, since in would then steer
+// that turn rather than start one.
+func (b *Backend) EstimateInputTokens(in core.Inbound) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return 0
+	}
+
+	bytes := len(b.effectiveSystemPrompt()) + len(renderUserMessage(in))
+	if raw, err := json.Marshal(b.tools); err == nil {
+		bytes += len(raw)
+	}
+	var images int
+	for _, a := range in.Attachments {
+		if media.IsVisionImage(a.MIME) && images < maxImagesPerMessage {
+			images++
+		}
+	}
+	for _, m := range b.history {
+		for _, block := range m.Content {
+			n, imgs := blockSize(block)
+			bytes += n
+			images += imgs
+		}
+	}
+	return int64(bytes/bytesPerToken + images*imageTokens)
+}
+
+// blockSize returns a block's size in bytes, with images counted
+// separately so their base64 payload doesn't inflate the estimate.
+func blockSize(block anthropic.ContentBlockParamUnion) (bytes, images int) {
+	switch {
+	case block.OfImage != nil:
+		return 0, 1
+	case block.OfText != nil:
+		return len(block.OfText.Text), 0
+	case block.OfToolResult != nil:
+		for _, c := range block.OfToolResult.Content {
+			switch {
+			case c.OfImage != nil:
+				images++
+			case c.OfText != nil:
+				bytes += len(c.OfText.Text)
+			}
+		}
+		return bytes, images
+	}
+	raw, _ := json.Marshal(block)
+	return len(raw), 0
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+var _ core.Personalizer = (*Backend)(nil)
+
+// SetPersona applies p from the next turn on. It refuses while a turn is
+// running, since that turn reads the persona without holding mu.
+func (b *Backend) SetPersona(p core.Persona) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return errors.New("a reply is still in progress")
+	}
+	b.persona = p
+	return nil
+}
+
+func (b *Backend) CurrentPersona() core.Persona {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.persona
+}
+
+// currentModel is the persona's model, or the session's.
+func (b *Backend) currentModel() string {
+	if b.persona.Model != "" {
+		return b.persona.Model
+	}
+	return b.model
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/pkg/errors"
+)
+
+// RecordEntry is one line of a session recording: a message the session
+// was given, or a Messages API response it got back. Requests are left out
+// because replaying the messages against the responses rebuilds them.
+type RecordEntry struct {
+	Time time.Time `json:"time"`
+	// Kind is "inbound" or "response".
+	Kind string `json:"kind"`
+	Text string `json:"text,omitempty"`
+	// Status and Body are a response's HTTP status and raw body.
+	Status int    `json:"status,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// recorder appends a session's RecordEntries to its own JSONL file in
+// RecordDir, for `switchboard replay`. Its middleware is installed on the
+// client before the session has an ID, so nothing is written until open.
+type recorder struct {
+	mu   sync.Mutex
+	path string
+}
+
+// open creates the session's recording file in dir.
+func (r *recorder) open(dir, sessionID string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return errors.Wrap(err, "creating record dir")
+	}
+	f, err := os.CreateTemp(dir, sessionID+"-*.jsonl")
+	if err != nil {
+		return errors.Wrap(err, "creating recording")
+	}
+	f.Close()
+	r.mu.Lock()
+	r.path = f.Name()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recorder) inbound(text string) {
+	r.write(RecordEntry{Time: time.Now(), Kind: "inbound", Text: text})
+}
+
+// middleware records every Messages API response, errors included, and
+// hands the caller an unread copy of the body.
+func (r *recorder) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	resp, err := next(req)
+	if err != nil || !strings.HasSuffix(req.URL.Path, "/v1/messages") {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	r.write(RecordEntry{Time: time.Now(), Kind: "response", Status: resp.StatusCode, Body: string(body)})
+	return resp, nil
+}
+
+// write appends e. A failed write is logged and dropped: recording must
+// never break the turn it records.
+func (r *recorder) write(e RecordEntry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		slog.Warn("recording session", "error", err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path == "" {
+		return
+	}
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		slog.Warn("recording session", "path", r.path, "error", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		slog.Warn("recording session", "path", r.path, "error", err)
+	}
+}
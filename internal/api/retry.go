@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/pkg/errors"
+)
+
+const (
+	maxAPIRetries  = 4
+	baseRetryDelay = 2 * time.Second
+	// maxRetryDelay caps the backoff; a retry-after longer than this fails
+	// the turn instead of leaving the user waiting.
+	maxRetryDelay = time.Minute
+)
+
+// callAPI sends one Messages request. Rate limits (429), overload (529),
+// other 5xx responses and network errors are retried up to maxAPIRetries
+// times with jittered exponential backoff or the server's retry-after,
+// telling the user through out before each wait. gen is the session's
+// /settings overrides.
+func (b *Backend) callAPI(ctx context.Context, out core.Outbound, gen core.Generation) (*anthropic.Message, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := b.client.Messages.New(ctx, b.buildParams(gen), option.WithMaxRetries(0))
+		if err == nil {
+			return resp, nil
+		}
+		delay, ok := retryDelay(ctx, err, attempt)
+		if !ok || attempt == maxAPIRetries {
+			return nil, markAPIError(ctx, err)
+		}
+		slog.Warn("API call failed, retrying", "session", b.sessionID, "attempt", attempt+1, "delay", delay, "error", err)
+		if out != nil {
+			_ = out.SendUpdate(retryNotice(err, delay))
+		}
+		if err := b.pause(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// pause waits for d or until ctx is done. Tests replace it via b.wait.
+func (b *Backend) pause(ctx context.Context, d time.Duration) error {
+	if b.wait != nil {
+		return b.wait(ctx, d)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryDelay reports whether err is worth retrying and how long to wait
+// first.
+func retryDelay(ctx context.Context, err error, attempt int) (time.Duration, bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		// No response at all: a dropped connection or DNS hiccup.
+		return backoff(attempt), true
+	}
+	if !retryableStatus(apiErr.StatusCode) {
+		return 0, false
+	}
+	if apiErr.Response != nil {
+		if d, ok := retryAfter(apiErr.Response.Header); ok {
+			return d, d <= maxRetryDelay
+		}
+	}
+	return backoff(attempt), true
+}
+
+// markAPIError tags err with the core.ErrorKind users are shown.
+// Authentication failures count as unavailable: the user can't fix them.
+func markAPIError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return core.MarkError(core.ErrUnavailable, err)
+	}
+	switch code := apiErr.StatusCode; {
+	case code == http.StatusTooManyRequests:
+		return core.MarkError(core.ErrRateLimited, err)
+	case code == http.StatusUnauthorized, code == http.StatusForbidden, retryableStatus(code):
+		return core.MarkError(core.ErrUnavailable, err)
+	}
+	return err
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// backoff doubles baseRetryDelay per attempt up to maxRetryDelay and picks
+// a random delay in its upper half so concurrent sessions spread out.
+func backoff(attempt int) time.Duration {
+	d := min(baseRetryDelay<<attempt, maxRetryDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter reads retry-after-ms or retry-after (seconds or HTTP date).
+func retryAfter(h http.Header) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	v := h.Get("retry-after")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.ParseFloat(v, 64); err == nil && s >= 0 {
+		return time.Duration(s * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func retryNotice(err error, delay time.Duration) string {
+	wait := delay.Round(time.Second)
+	if wait == 0 {
+		wait = time.Second
+	}
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Sprintf("Couldn't reach the model API, retrying in %s", wait)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		return fmt.Sprintf("Rate limited, retrying in %s", wait)
+	case 529:
+		return fmt.Sprintf("The model API is overloaded, retrying in %s", wait)
+	}
+	return fmt.Sprintf("The model API returned %d, retrying in %s", apiErr.StatusCode, wait)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+// retryBackend returns a backend talking to a server that answers with
+// statuses in order, then succeeds. Waits are recorded instead of slept.
+func retryBackend(t *testing.T, header http.Header, statuses ...int) (*Backend, *int, *[]time.Duration) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls <= len(statuses) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			http.Error(w, `{"type":"error","error":{"type":"api_error","message":"nope"}}`, statuses[calls-1])
+			return
+		}
+		writeMessageJSON(w, "msg_1", "done", "end_turn")
+	}))
+	t.Cleanup(server.Close)
+	var waits []time.Duration
+	b := &Backend{
+		client:    anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL)),
+		model:     "test-model",
+		sessionID: "test",
+		history:   []anthropic.MessageParam{},
+		wait: func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+	return b, &calls, &waits
+}
+
+func TestConverse_RetriesRateLimitUsingRetryAfter(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a 429 with retry-after: 20, then success
+	b, calls, waits := retryBackend(t, http.Header{"Retry-After": {"20"}}, http.StatusTooManyRequests)
+	out := &recordingResponder{}
+
+	// when
+	resp, err := b.Converse(context.Background(), core.Inbound{Text: "hi"}, out, allowAllPerms{})
+
+	// then
+	// ... the turn succeeds after one announced wait of the server's delay
+	r.NoError(err)
+	a.Equal("done", resp)
+	a.Equal(2, *calls)
+	a.Equal([]time.Duration{20 * time.Second}, *waits)
+	a.Equal([]string{"Rate limited, retrying in 20s"}, out.updates)
+}
+
+func TestConverse_GivesUpAfterMaxRetries(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... an API that stays overloaded
+	statuses := make([]int, maxAPIRetries+1)
+	for i := range statuses {
+		statuses[i] = 529
+	}
+	b, calls, waits := retryBackend(t, nil, statuses...)
+	out := &recordingResponder{}
+
+	// when
+	_, err := b.Converse(context.Background(), core.Inbound{Text: "hi"}, out, allowAllPerms{})
+
+	// then
+	// ... backoff grows between attempts and the last error is returned
+	a.Error(err)
+	a.Equal(maxAPIRetries+1, *calls)
+	a.Len(*waits, maxAPIRetries)
+	for i, d := range *waits {
+		full := baseRetryDelay << i
+		a.GreaterOrEqual(d, full/2)
+		a.LessOrEqual(d, full)
+	}
+	a.Contains(out.updates[0], "overloaded")
+	a.Equal(core.ErrUnavailable, core.ErrorKindOf(err))
+}
+
+func TestConverse_DoesNotRetryClientErrors(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	b, calls, waits := retryBackend(t, nil, http.StatusBadRequest)
+
+	// when
+	_, err := b.Converse(context.Background(), core.Inbound{Text: "hi"}, &recordingResponder{}, allowAllPerms{})
+
+	// then
+	a.Error(err)
+	a.Equal(1, *calls)
+	a.Empty(*waits)
+	a.Equal(core.ErrInternal, core.ErrorKindOf(err))
+}
+
+func TestConverse_RetryAfterBeyondCapFails(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a rate limit asking for ten minutes
+	b, calls, _ := retryBackend(t, http.Header{"Retry-After": {"600"}}, http.StatusTooManyRequests)
+
+	// when
+	_, err := b.Converse(context.Background(), core.Inbound{Text: "hi"}, &recordingResponder{}, allowAllPerms{})
+
+	// then
+	a.Error(err)
+	a.Equal(1, *calls)
+	a.Equal(core.ErrRateLimited, core.ErrorKindOf(err))
+}
+
+func TestRetryAfter_ParsesHeaderForms(t *testing.T) {
+	a := assert.New(t)
+
+	d, ok := retryAfter(http.Header{"Retry-After-Ms": {"1500"}})
+	a.True(ok)
+	a.Equal(1500*time.Millisecond, d)
+
+	d, ok = retryAfter(http.Header{"Retry-After": {"7"}})
+	a.True(ok)
+	a.Equal(7*time.Second, d)
+
+	d, ok = retryAfter(http.Header{"Retry-After": {time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)}})
+	a.True(ok)
+	a.InDelta(30*time.Second, d, float64(2*time.Second))
+
+	_, ok = retryAfter(http.Header{})
+	a.False(ok)
+}
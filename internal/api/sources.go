@@ -0,0 +1,13 @@
+package api
+
+import "github.com/TheLazyLemur/switchboard/internal/tools"
+
+// appendSources lists the pages the turn's WebSearch and Fetch calls
+// returned under resp, leaving out any the model already linked.
+func appendSources(resp string, sources *tools.Sources) string {
+	footer := sources.Footer(resp)
+	if resp == "" || footer == "" {
+		return resp
+	}
+	return resp + "\n\n" + footer
+}
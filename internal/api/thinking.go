@@ -0,0 +1,41 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// maxThinkingUpdate caps the thinking summary posted with /verbosity
+// thinking.
+const maxThinkingUpdate = 300
+
+// splitThinking collects resp's thinking blocks. The API bills thinking as
+// output tokens without breaking it out, so tokens is an estimate from the
+// block sizes; redacted blocks only have their encrypted data to go on.
+func splitThinking(resp *anthropic.Message) (text string, redacted bool, tokens int64) {
+	var parts []string
+	var bytes int
+	for _, block := range resp.Content {
+		switch v := block.AsAny().(type) {
+		case anthropic.ThinkingBlock:
+			parts = append(parts, v.Thinking)
+			bytes += len(v.Thinking)
+		case anthropic.RedactedThinkingBlock:
+			redacted = true
+			bytes += len(v.Data)
+		}
+	}
+	return strings.Join(parts, "\n\n"), redacted, min(int64(bytes/bytesPerToken), resp.Usage.OutputTokens)
+}
+
+// thinkingUpdate summarises thinking for the updates channel: its first
+// paragraph, clipped.
+func thinkingUpdate(text string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	first = strings.Join(strings.Fields(first), " ")
+	if first == "" {
+		return "💭 (redacted)"
+	}
+	return "💭 " + clipText(first, maxThinkingUpdate)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverse_SummarisesThinking(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// given
+	// ... a reply that thinks first, in a session with /verbosity thinking
+	thought := strings.Repeat("x", 400) + "\n\nSecond paragraph."
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":   "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"content": []map[string]any{
+				{"type": "thinking", "thinking": thought, "signature": "sig"},
+				{"type": "text", "text": "done"},
+			},
+			"model":       "test-model",
+			"stop_reason": "end_turn",
+			"usage":       map[string]any{"input_tokens": 10, "output_tokens": 500},
+		})
+	}))
+	defer server.Close()
+	usage := &usageRecorder{}
+	b := &Backend{
+		client:    anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL)),
+		model:     "test-model",
+		sessionID: "test",
+		history:   []anthropic.MessageParam{},
+		usage:     usage,
+	}
+	out := &recordingResponder{}
+	in := core.Inbound{Text: "hello", Settings: core.Settings{Verbosity: core.VerbosityThinking}}
+
+	// when
+	resp, err := b.Converse(context.Background(), in, out, allowAllPerms{})
+
+	// then
+	// ... the first paragraph is posted clipped, and its tokens are estimated
+	r.NoError(err)
+	a.Equal("done", resp)
+	r.Len(out.updates, 1)
+	a.Equal("💭 "+strings.Repeat("x", maxThinkingUpdate)+"…", out.updates[0])
+	r.Len(usage.turns, 1)
+	a.Equal(int64(len(thought)/bytesPerToken), usage.turns[0].ThinkingTokens)
+	a.Equal(int64(500), usage.turns[0].OutputTokens)
+}
+
+func TestThinkingUpdate_Redacted(t *testing.T) {
+	assert.Equal(t, "💭 (redacted)", thinkingUpdate(""))
+}
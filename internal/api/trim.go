@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/pkg/errors"
+)
+
+// contextErrorMarkers are lowercase fragments of the errors Anthropic and
+// compatible providers return when a request exceeds the context window.
+var contextErrorMarkers = []string{
+	"prompt is too long",
+	"context_length_exceeded",
+	"maximum context length",
+	"context window",
+	"request_too_large",
+}
+
+const trimmedNote = "[Earlier messages in this conversation were removed to fit the context window.]"
+
+const tooLongReply = "That doesn't fit in the model's context window, even after dropping earlier messages. Try sending less at once, such as just the relevant part of a log."
+
+// contextTooLong reports whether err is the API refusing a request for
+// being larger than the model's context window.
+func contextTooLong(err error) bool {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusRequestEntityTooLarge {
+		return false
+	}
+	body := strings.ToLower(apiErr.RawJSON())
+	for _, m := range contextErrorMarkers {
+		if strings.Contains(body, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// turnStarts returns the indices of history's user messages that begin a
+// turn, as opposed to those carrying tool results.
+func turnStarts(history []anthropic.MessageParam) []int {
+	var starts []int
+	for i, m := range history {
+		if m.Role != anthropic.MessageParamRoleUser {
+			continue
+		}
+		start := true
+		for _, block := range m.Content {
+			if block.OfToolResult != nil {
+				start = false
+				break
+			}
+		}
+		if start {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func messageSize(m anthropic.MessageParam) int {
+	var n int
+	for _, block := range m.Content {
+		bytes, images := blockSize(block)
+		n += bytes + images*imageTokens*bytesPerToken
+	}
+	return n
+}
+
+// trimHistory drops the oldest whole turns until about half of the
+// history's size is gone, always keeping the current turn, and marks the
+// cut with a note. It returns how many turns were dropped.
+func (b *Backend) trimHistory() int {
+	starts := turnStarts(b.history)
+	if len(starts) < 2 {
+		return 0
+	}
+	var total int
+	for _, m := range b.history {
+		total += messageSize(m)
+	}
+
+	dropped, cut, removed := 0, 0, 0
+	for dropped < len(starts)-1 && removed < total/2 {
+		next := starts[dropped+1]
+		for _, m := range b.history[cut:next] {
+			removed += messageSize(m)
+		}
+		cut = next
+		dropped++
+	}
+
+	kept := b.history[cut:]
+	first := kept[0]
+	first.Content = append([]anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(trimmedNote)}, first.Content...)
+	b.history = append([]anthropic.MessageParam{first}, kept[1:]...)
+	return dropped
+}
+
+// dropCurrentTurn removes the turn in progress so a request that can never
+// fit doesn't fail every later turn too.
+func (b *Backend) dropCurrentTurn() {
+	if starts := turnStarts(b.history); len(starts) > 0 {
+		b.history = b.history[:starts[len(starts)-1]]
+	}
+}
+
+func trimmedNotice(turns int) string {
+	if turns == 1 {
+		return "The conversation got too long for the model, so I dropped the oldest exchange and retried."
+	}
+	return fmt.Sprintf("The conversation got too long for the model, so I dropped the %d oldest exchanges and retried.", turns)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+func writeContextError(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`))
+}
+
+// contextBackend talks to a server that rejects the first tooLong requests
+// as over the context window and records the message count of each.
+func contextBackend(t *testing.T, tooLong int) (*Backend, *[]int) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Messages []json.RawMessage `json:"messages"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		sizes = append(sizes, len(body.Messages))
+		if len(sizes) <= tooLong {
+			writeContextError(w)
+			return
+		}
+		writeMessageJSON(w, "msg", "answer", "end_turn")
+	}))
+	t.Cleanup(server.Close)
+	return &Backend{
+		client:    anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL)),
+		model:     "test-model",
+		sessionID: "test",
+		history:   []anthropic.MessageParam{},
+	}, &sizes
+}
+
+func pastTurn(text string) []anthropic.MessageParam {
+	return []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(text)),
+		anthropic.NewAssistantMessage(anthropic.NewTextBlock("ok")),
+	}
+}
+
+func TestConverse_ContextTooLongTrimsOldestTurnsAndRetries(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... three earlier turns, the first two large
+	b, sizes := contextBackend(t, 1)
+	b.history = append(b.history, pastTurn(strings.Repeat("a", 5000))...)
+	b.history = append(b.history, pastTurn(strings.Repeat("b", 5000))...)
+	b.history = append(b.history, pastTurn("small")...)
+	out := &recordingResponder{}
+
+	// when
+	resp, err := b.Converse(context.Background(), core.Inbound{Text: "now"}, out, allowAllPerms{})
+
+	// then
+	// ... the two large turns are dropped, the user is told, and the retry succeeds
+	r.NoError(err)
+	a.Equal("answer", resp)
+	a.Equal([]int{7, 3}, *sizes)
+	a.Equal([]string{"The conversation got too long for the model, so I dropped the 2 oldest exchanges and retried."}, out.updates)
+	r.Len(b.history, 4)
+	a.Equal(trimmedNote, b.history[0].Content[0].OfText.Text)
+	a.Equal("small", b.history[0].Content[1].OfText.Text)
+}
+
+func TestConverse_ContextTooLongWithNothingToTrimDropsTurn(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a fresh session whose only message is too long
+	b, sizes := contextBackend(t, 10)
+
+	// when
+	resp, err := b.Converse(context.Background(), core.Inbound{Text: strings.Repeat("log ", 1000)}, &recordingResponder{}, allowAllPerms{})
+
+	// then
+	// ... the user gets an explanation and the session stays usable
+	r.NoError(err)
+	a.Equal(tooLongReply, resp)
+	a.Len(*sizes, 1)
+	a.Empty(b.history)
+	a.False(b.running)
+}
+
+func TestTurnStarts_SkipsToolResults(t *testing.T) {
+	// given
+	history := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("q")),
+		anthropic.NewAssistantMessage(anthropic.NewToolUseBlock("t1", map[string]any{}, "Bash")),
+		anthropic.NewUserMessage(anthropic.NewToolResultBlock("t1", "out", false)),
+		anthropic.NewAssistantMessage(anthropic.NewTextBlock("a")),
+		anthropic.NewUserMessage(anthropic.NewTextBlock("q2")),
+	}
+
+	// when
+	starts := turnStarts(history)
+
+	// then
+	assert.Equal(t, []int{0, 4}, starts)
+}
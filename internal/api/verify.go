@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// runVerify runs the session's verify commands, with its /env variables,
+// after a batch of tool calls edited files. The model gets every command's
+// output in the same user turn as the tool results; the user gets a
+// one-line summary.
+func (b *Backend) runVerify(ctx context.Context, out core.Outbound, env map[string]string) anthropic.ContentBlockParamUnion {
+	deps := b.toolDeps
+	deps.Outbound = out
+	deps.SessionEnv = env
+	results := tools.RunVerify(ctx, deps, b.verify.Commands)
+
+	var body strings.Builder
+	body.WriteString("<verification>\nThe project's verify commands ran after your edits:\n")
+	summary := make([]string, len(results))
+	for i, r := range results {
+		status := "passed"
+		if r.Failed {
+			status = "failed"
+		}
+		summary[i] = r.Command + " " + status
+		body.WriteString("\n$ " + r.Command + " (" + status + ")\n")
+		if output := strings.TrimSpace(r.Output); output != "" {
+			body.WriteString(output + "\n")
+		}
+	}
+	body.WriteString("</verification>")
+
+	slog.Info("verify commands ran", "session", b.sessionID, "summary", strings.Join(summary, ", "))
+	if out != nil {
+		_ = out.SendUpdate("Verify: " + strings.Join(summary, ", "))
+	}
+	return anthropic.NewTextBlock(body.String())
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/tools"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func verifyBackend(t *testing.T) *Backend {
+	t.Helper()
+	reg := core.NewToolRegistry()
+	require.NoError(t, reg.Register(core.RegisteredTool{
+		Def:     core.ToolDef{Name: "deploy"},
+		Execute: func(context.Context, json.RawMessage, core.Outbound) (string, bool) { return "done", false },
+	}))
+	return &Backend{
+		sessionID: "test",
+		toolDeps:  tools.Deps{Registry: reg, WorkDir: t.TempDir()},
+		verify:    tools.VerifyConfig{Commands: []string{"echo built", "echo FAIL: TestX; exit 1"}, After: []string{"deploy"}},
+	}
+}
+
+func TestExecuteTools_RunsVerifyAfterEdits(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	b := verifyBackend(t)
+	out := &recordingResponder{}
+	uses := []anthropic.ToolUseBlock{{ID: "t1", Name: "deploy", Input: json.RawMessage(`{}`)}}
+
+	// when
+	results, err := b.executeTools(context.Background(), uses, out, allowAllPerms{}, core.Settings{})
+
+	// then
+	// ... the model gets every command's output after the tool result and the user a summary
+	r.NoError(err)
+	r.Len(results, 2)
+	r.NotNil(results[1].OfText)
+	text := results[1].OfText.Text
+	a.Contains(text, "$ echo built (passed)\nbuilt")
+	a.Contains(text, "(failed)\nFAIL: TestX")
+	a.Equal([]string{"Verify: echo built passed, echo FAIL: TestX; exit 1 failed"}, out.updates)
+}
+
+func TestExecuteTools_SkipsVerifyWithoutEdits(t *testing.T) {
+	// given
+	// ... a batch that only reads
+	b := verifyBackend(t)
+	out := &recordingResponder{}
+	uses := []anthropic.ToolUseBlock{{ID: "t1", Name: "send_update", Input: json.RawMessage(`{"message":"hi"}`)}}
+
+	// when
+	results, err := b.executeTools(context.Background(), uses, out, allowAllPerms{}, core.Settings{})
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, []string{"hi"}, out.updates)
+}
+
+func TestExecuteTools_SkipsVerifyWhenReadOnly(t *testing.T) {
+	// given
+	b := verifyBackend(t)
+	out := &recordingResponder{}
+	uses := []anthropic.ToolUseBlock{{ID: "t1", Name: "deploy", Input: json.RawMessage(`{}`)}}
+
+	// when
+	results, err := b.executeTools(context.Background(), uses, out, allowAllPerms{}, core.Settings{ReadOnly: true})
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Empty(t, out.updates)
+}
@@ -0,0 +1,203 @@
+// Package backup bundles the bot's on-disk state into one gzipped tar so
+// it can be moved to another host, and unpacks it there.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Item is one file or directory of state, stored in the archive under
+// Name so it can be restored to a different Path.
+type Item struct {
+	Name string
+	Path string
+}
+
+// sqliteSidecars are the files SQLite keeps next to a database in WAL
+// mode. They travel with it, and stale ones are removed on restore.
+var sqliteSidecars = []string{"-wal", "-shm", "-journal"}
+
+// Create writes items to w. Missing items are skipped and reported, so a
+// host without WhatsApp still backs up the rest. Only regular files are
+// stored.
+func Create(w io.Writer, items []Item) (skipped []string, err error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, it := range items {
+		info, err := os.Stat(it.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			skipped = append(skipped, it.Name)
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "backing up %s", it.Name)
+		}
+		if info.IsDir() {
+			err = addDir(tw, it)
+		} else {
+			err = addFiles(tw, it)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "backing up %s", it.Name)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "writing archive")
+	}
+	return skipped, errors.Wrap(gz.Close(), "writing archive")
+}
+
+func addDir(tw *tar.Writer, it Item) error {
+	return filepath.WalkDir(it.Path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(it.Path, p)
+		if err != nil {
+			return err
+		}
+		return addFile(tw, p, it.Name+"/"+filepath.ToSlash(rel))
+	})
+}
+
+// addFiles stores a single file and any SQLite sidecars next to it.
+func addFiles(tw *tar.Writer, it Item) error {
+	if err := addFile(tw, it.Path, it.Name); err != nil {
+		return err
+	}
+	for _, suffix := range sqliteSidecars {
+		err := addFile(tw, it.Path+suffix, it.Name+suffix)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func addFile(tw *tar.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// Restore unpacks an archive made by Create, writing each item's files
+// under its Path and overwriting what is there. Entries for unknown items
+// or outside their item are refused. It returns the names restored.
+func Restore(r io.Reader, items []Item) ([]string, error) {
+	byName := make(map[string]Item, len(items))
+	for _, it := range items {
+		byName[it.Name] = it
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading archive")
+	}
+	defer gz.Close()
+
+	var restored []string
+	seen := make(map[string]bool)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return restored, nil
+		}
+		if err != nil {
+			return restored, errors.Wrap(err, "reading archive")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, dest, err := target(hdr.Name, byName)
+		if err != nil {
+			return restored, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			restored = append(restored, name)
+		}
+		if hdr.Name == name {
+			// A database comes before its sidecars.
+			if err := clearSidecars(byName[name]); err != nil {
+				return restored, err
+			}
+		}
+		if err := writeFile(dest, tr, fs.FileMode(hdr.Mode).Perm()); err != nil {
+			return restored, errors.Wrapf(err, "restoring %s", hdr.Name)
+		}
+	}
+}
+
+// target maps an archive entry to the item it belongs to and where it is
+// written.
+func target(entry string, items map[string]Item) (string, string, error) {
+	if it, ok := items[entry]; ok {
+		return entry, it.Path, nil
+	}
+	for _, suffix := range sqliteSidecars {
+		if it, ok := items[strings.TrimSuffix(entry, suffix)]; ok && strings.HasSuffix(entry, suffix) {
+			return it.Name, it.Path + suffix, nil
+		}
+	}
+	name, rel, ok := strings.Cut(entry, "/")
+	it, known := items[name]
+	if !ok || !known {
+		return "", "", errors.Errorf("archive entry %q belongs to no known item", entry)
+	}
+	if !filepath.IsLocal(rel) || path.Clean(rel) != rel {
+		return "", "", errors.Errorf("archive entry %q leaves its item", entry)
+	}
+	return name, filepath.Join(it.Path, filepath.FromSlash(rel)), nil
+}
+
+// clearSidecars removes SQLite sidecars that would otherwise be replayed
+// over a restored database.
+func clearSidecars(it Item) error {
+	for _, suffix := range sqliteSidecars {
+		if err := os.Remove(it.Path + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return errors.Wrapf(err, "clearing %s", it.Name)
+		}
+	}
+	return nil
+}
+
+func writeFile(dest string, r io.Reader, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
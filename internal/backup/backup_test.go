@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRestore_RoundTrip(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a skills directory and a database with a WAL, but no WhatsApp DB
+	src := t.TempDir()
+	r.NoError(os.MkdirAll(filepath.Join(src, "skills", "deploy", "scripts"), 0o755))
+	r.NoError(os.WriteFile(filepath.Join(src, "skills", "deploy", "SKILL.md"), []byte("skill"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(src, "skills", "deploy", "scripts", "run.sh"), []byte("#!/bin/sh"), 0o755))
+	r.NoError(os.WriteFile(filepath.Join(src, "guilds.db"), []byte("db"), 0o600))
+	r.NoError(os.WriteFile(filepath.Join(src, "guilds.db-wal"), []byte("wal"), 0o600))
+	items := func(root string) []Item {
+		return []Item{
+			{Name: "skills", Path: filepath.Join(root, "skills")},
+			{Name: "guild-settings", Path: filepath.Join(root, "guilds.db")},
+			{Name: "whatsapp", Path: filepath.Join(root, "whatsapp.db")},
+		}
+	}
+	var buf bytes.Buffer
+	skipped, err := Create(&buf, items(src))
+	r.NoError(err)
+
+	// when
+	// ... it is restored over a host with a stale database sidecar
+	dst := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dst, "guilds.db-shm"), []byte("stale"), 0o600))
+	restored, err := Restore(&buf, items(dst))
+
+	// then
+	r.NoError(err)
+	a.Equal([]string{"whatsapp"}, skipped)
+	a.Equal([]string{"skills", "guild-settings"}, restored)
+	got, err := os.ReadFile(filepath.Join(dst, "skills", "deploy", "scripts", "run.sh"))
+	r.NoError(err)
+	a.Equal("#!/bin/sh", string(got))
+	info, err := os.Stat(filepath.Join(dst, "skills", "deploy", "scripts", "run.sh"))
+	r.NoError(err)
+	a.Equal(os.FileMode(0o755), info.Mode().Perm())
+	got, err = os.ReadFile(filepath.Join(dst, "guilds.db-wal"))
+	r.NoError(err)
+	a.Equal("wal", string(got))
+	a.NoFileExists(filepath.Join(dst, "guilds.db-shm"))
+}
+
+func TestRestore_RefusesForeignEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+		want  string
+	}{
+		{"unknown item", "secrets/id_rsa", "belongs to no known item"},
+		{"escape", "skills/../../etc/passwd", "leaves its item"},
+		{"absolute", "skills//etc/passwd", "leaves its item"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: tt.entry, Mode: 0o600, Size: 1}))
+			_, err := tw.Write([]byte("x"))
+			require.NoError(t, err)
+			require.NoError(t, tw.Close())
+			require.NoError(t, gz.Close())
+			dst := t.TempDir()
+
+			// when
+			_, err = Restore(&buf, []Item{{Name: "skills", Path: filepath.Join(dst, "skills")}})
+
+			// then
+			assert.ErrorContains(t, err, tt.want)
+			assert.NoDirExists(t, filepath.Join(dst, "skills"))
+		})
+	}
+}
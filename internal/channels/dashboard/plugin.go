@@ -33,7 +33,7 @@ func New(cfg Config) *Plugin { return &Plugin{cfg: cfg} }
 func (p *Plugin) ID() string { return "dashboard" }
 
 func (p *Plugin) Capabilities() core.Capabilities {
-	return core.Capabilities{Reactions: false, Updates: true}
+	return dash.ChatCapabilities
 }
 
 func (p *Plugin) Start(_ context.Context, deliver func(core.Inbound)) error {
@@ -71,6 +71,8 @@ func (p *Plugin) HandleChat(sessionID, text string) {
 	d(core.Inbound{
 		SessionKey:   SessionKey(),
 		Text:         text,
+		UserID:       "dashboard",
+		ChannelID:    "dashboard",
 		Reply:        out,
 		Capabilities: p.Capabilities(),
 	})
@@ -79,5 +81,5 @@ func (p *Plugin) HandleChat(sessionID, text string) {
 // SessionKey returns the stable dashboard session key. The dashboard is
 // single-user/single-channel so one constant key is correct.
 func SessionKey() core.SessionKey {
-	return core.SessionKey("dashboard")
+	return dash.ChatSessionKey
 }
@@ -0,0 +1,106 @@
+package discord
+
+import (
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// pinEmoji on one of the bot's answers pins it and adds it to the
+// channel's answers index, like /pin-answer.
+const pinEmoji = "📌"
+
+// messagePinner is the slice of a session pinning answers needs.
+type messagePinner interface {
+	// ChannelMessagesBefore is as in channelPruner.
+	ChannelMessagesBefore(channelID, beforeID string) ([]*discordgo.Message, error)
+	ChannelMessageAuthor(channelID, messageID string) (string, error)
+	ChannelMessagePin(channelID, messageID string) error
+}
+
+// pinLatest pins the bot's newest message in threadID. A response split
+// into several messages is pinned by its last part.
+func (p *Plugin) pinLatest(threadID string) error {
+	s, ok := p.session.(messagePinner)
+	if !ok {
+		return errors.New("pinning is not supported by this session")
+	}
+	id, err := p.latestBotMessage(s, threadID)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(withRetry(func() error { return s.ChannelMessagePin(threadID, id) }), "pinning message")
+}
+
+// latestBotMessage returns the ID of the bot's newest message in
+// channelID.
+func (p *Plugin) latestBotMessage(s messagePinner, channelID string) (string, error) {
+	msgs, err := s.ChannelMessagesBefore(channelID, "")
+	if err != nil {
+		return "", errors.Wrap(err, "reading recent messages")
+	}
+	for _, m := range msgs {
+		if m.Author != nil && m.Author.ID == p.cfg.BotID {
+			return m.ID, nil
+		}
+	}
+	return "", errors.New("no bot message in the channel")
+}
+
+// translateReactionAdd converts a discordgo MessageReactionAdd into a
+// messageEvent from the reacting user and the emoji they added.
+func translateReactionAdd(r *discordgo.MessageReactionAdd, botID string, lookupChannel func(string) (*discordgo.Channel, error)) (messageEvent, string, bool) {
+	if r.MessageReaction == nil || r.UserID == botID {
+		return messageEvent{}, "", false
+	}
+	ev := messageEvent{
+		AuthorID:  r.UserID,
+		ChannelID: r.ChannelID,
+		GuildID:   r.GuildID,
+		MessageID: r.MessageID,
+		IsDM:      r.GuildID == "",
+	}
+	if lookupChannel != nil {
+		if ch, err := lookupChannel(r.ChannelID); err == nil && ch.IsThread() {
+			ev.IsThread = true
+			ev.ParentID = ch.ParentID
+		}
+	}
+	return ev, r.Emoji.Name, true
+}
+
+// handlePinReaction pins the bot's answer an allowed user reacted to in
+// one of the bot's threads or a DM, and hands the bot "/pin-answer <link>"
+// so it lands in the answers index too.
+func (p *Plugin) handlePinReaction(ev messageEvent) {
+	if !ev.IsDM && !p.threads.owns(ev.ChannelID) {
+		return
+	}
+	s, ok := p.session.(messagePinner)
+	if !ok {
+		return
+	}
+	author, err := s.ChannelMessageAuthor(ev.ChannelID, ev.MessageID)
+	if err != nil {
+		slog.Warn("discord read reacted message failed", "channel", ev.ChannelID, "message", ev.MessageID, "error", err)
+		return
+	}
+	if author != p.cfg.BotID {
+		return
+	}
+	if err := withRetry(func() error { return s.ChannelMessagePin(ev.ChannelID, ev.MessageID) }); err != nil {
+		slog.Warn("discord pin message failed", "channel", ev.ChannelID, "message", ev.MessageID, "error", err)
+	}
+
+	p.deliverReaction(ev, "/pin-answer "+messageURL(ev))
+}
+
+// messageURL links to ev's message in the form FetchMessage reads.
+func messageURL(ev messageEvent) string {
+	guild := ev.GuildID
+	if guild == "" {
+		guild = "@me"
+	}
+	return "https://discord.com/channels/" + guild + "/" + ev.ChannelID + "/" + ev.MessageID
+}
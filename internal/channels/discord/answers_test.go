@@ -0,0 +1,130 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pinSession serves recent messages and authors from memory and records
+// pins.
+type pinSession struct {
+	sessionFull
+	messages []*discordgo.Message
+	authors  map[string]string
+	pinned   []string
+}
+
+func (s *pinSession) ChannelMessagesBefore(string, string) ([]*discordgo.Message, error) {
+	return s.messages, nil
+}
+
+func (s *pinSession) ChannelMessageAuthor(_, messageID string) (string, error) {
+	return s.authors[messageID], nil
+}
+
+func (s *pinSession) ChannelMessagePin(_, messageID string) error {
+	s.pinned = append(s.pinned, messageID)
+	return nil
+}
+
+func TestOutbound_PinLatestPinsNewestBotMessage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a thread whose newest message is the user's
+	s := &pinSession{messages: []*discordgo.Message{
+		{ID: "m-3", Author: &discordgo.User{ID: "user-1"}},
+		{ID: "m-2", Author: &discordgo.User{ID: "bot-id"}},
+		{ID: "m-1", Author: &discordgo.User{ID: "bot-id"}},
+	}}
+	p := New(Config{BotID: "bot-id"}, s)
+
+	// when
+	err := p.outbound("thread-1", "msg-1").PinLatest()
+
+	// then
+	r.NoError(err)
+	a.Equal([]string{"m-2"}, s.pinned)
+}
+
+func TestPlugin_PinReactionOnBotAnswer(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot answer and a user message in an owned thread
+	s := &pinSession{authors: map[string]string{"answer-1": "bot-id", "question-1": "user-1"}}
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+	p.threads.markOwned("thread-1")
+	ev := messageEvent{AuthorID: "user-1", ChannelID: "thread-1", GuildID: "guild-1", ParentID: "channel-1", IsThread: true}
+
+	// when
+	// ... the user reacts with 📌 to both, and with 👍 to the answer
+	ev.MessageID = "answer-1"
+	p.handleReaction(ev, pinEmoji)
+	p.handleReaction(ev, "👍")
+	ev.MessageID = "question-1"
+	p.handleReaction(ev, pinEmoji)
+
+	// then
+	// ... only the answer is pinned and handed over as /pin-answer
+	a.Equal([]string{"answer-1"}, s.pinned)
+	r.Len(got, 1)
+	a.Equal("/pin-answer https://discord.com/channels/guild-1/thread-1/answer-1", got[0].Text)
+	a.Equal(core.SessionKey("discord:thread:thread-1"), got[0].SessionKey)
+	a.Equal("discord:channel-1", got[0].ChannelID)
+}
+
+func TestPlugin_PinReactionIgnoredOutsideBotThreads(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s := &pinSession{authors: map[string]string{"answer-1": "bot-id"}}
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+
+	// when
+	// ... reactions arrive in a foreign channel and from a stranger in a DM
+	p.handleReaction(messageEvent{AuthorID: "user-1", ChannelID: "channel-9", GuildID: "guild-1", MessageID: "answer-1"}, pinEmoji)
+	p.handleReaction(messageEvent{AuthorID: "user-2", ChannelID: "dm-1", MessageID: "answer-1", IsDM: true}, pinEmoji)
+
+	// then
+	a.Empty(s.pinned)
+	a.Empty(got)
+}
+
+func TestPlugin_RetryReactionOnLastResponse(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an owned thread with two bot responses
+	s := &pinSession{messages: []*discordgo.Message{
+		{ID: "answer-2", Author: &discordgo.User{ID: "bot-id"}},
+		{ID: "question-2", Author: &discordgo.User{ID: "user-1"}},
+		{ID: "answer-1", Author: &discordgo.User{ID: "bot-id"}},
+	}}
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+	p.threads.markOwned("thread-1")
+	ev := messageEvent{AuthorID: "user-1", ChannelID: "thread-1", GuildID: "guild-1", ParentID: "channel-1", IsThread: true}
+
+	// when
+	// ... the user reacts with 🔁 to the older response, then the newest
+	ev.MessageID = "answer-1"
+	p.handleReaction(ev, retryEmoji)
+	ev.MessageID = "answer-2"
+	p.handleReaction(ev, retryEmoji)
+
+	// then
+	// ... only the newest one is handed over as /retry
+	r.Len(got, 1)
+	a.Equal("/retry", got[0].Text)
+	a.Equal(core.SessionKey("discord:thread:thread-1"), got[0].SessionKey)
+}
@@ -0,0 +1,165 @@
+package discord
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pruneInterval is how often the prune loop sweeps its channels.
+	pruneInterval = time.Hour
+	// maxPrunePages bounds how far back one sweep reads a channel's
+	// history, 100 messages a page.
+	maxPrunePages = 50
+)
+
+// threadArchiver is implemented by sessions that can archive a thread.
+// Archiving is skipped for sessions that can't.
+type threadArchiver interface {
+	ArchiveThread(threadID string) error
+}
+
+// channelPruner is the slice of a session pruning needs.
+type channelPruner interface {
+	// ChannelMessagesBefore returns up to 100 messages older than beforeID,
+	// newest first; an empty beforeID starts at the newest message.
+	ChannelMessagesBefore(channelID, beforeID string) ([]*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string) error
+	// ChannelThreads returns the active and archived public threads of a
+	// channel.
+	ChannelThreads(channelID string) ([]*discordgo.Channel, error)
+	ChannelDelete(channelID string) error
+}
+
+// archiveTimers archives each thread the bot owns ArchiveAfter after its
+// last response. A new message in the thread cancels the timer; the next
+// response starts it again.
+type archiveTimers struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newArchiveTimers() *archiveTimers {
+	return &archiveTimers{timers: make(map[string]*time.Timer)}
+}
+
+// responded is called by threadID's outbound after each response.
+func (p *Plugin) responded(threadID string) {
+	a, ok := p.session.(threadArchiver)
+	if p.cfg.ArchiveAfter <= 0 || !ok || !p.threads.owns(threadID) {
+		return
+	}
+	p.archives.mu.Lock()
+	defer p.archives.mu.Unlock()
+	if t := p.archives.timers[threadID]; t != nil {
+		t.Stop()
+	}
+	p.archives.timers[threadID] = time.AfterFunc(p.cfg.ArchiveAfter, func() {
+		p.archives.mu.Lock()
+		delete(p.archives.timers, threadID)
+		p.archives.mu.Unlock()
+		if err := withRetry(func() error { return a.ArchiveThread(threadID) }); err != nil {
+			slog.Warn("discord archive thread failed", "thread", threadID, "error", err)
+			return
+		}
+		slog.Info("archived discord thread", "thread", threadID)
+	})
+}
+
+// active cancels threadID's pending archive while a new message is handled.
+func (p *Plugin) active(threadID string) {
+	p.archives.mu.Lock()
+	defer p.archives.mu.Unlock()
+	if t := p.archives.timers[threadID]; t != nil {
+		t.Stop()
+		delete(p.archives.timers, threadID)
+	}
+}
+
+// prune sweeps every channel in cfg.Prune now and then every
+// pruneInterval until ctx is done.
+func (p *Plugin) prune(ctx context.Context, s channelPruner) {
+	t := time.NewTicker(pruneInterval)
+	defer t.Stop()
+	for {
+		for channelID, maxAge := range p.cfg.Prune {
+			if err := p.pruneChannel(ctx, s, channelID, time.Now().Add(-maxAge)); err != nil {
+				slog.Warn("discord prune failed", "channel", channelID, "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// pruneChannel deletes the bot's messages in channelID sent before cutoff,
+// and the threads the bot opened there whose last message is older than
+// cutoff, with everything in them.
+func (p *Plugin) pruneChannel(ctx context.Context, s channelPruner, channelID string, cutoff time.Time) error {
+	deleted := 0
+	before := ""
+	for page := 0; page < maxPrunePages && ctx.Err() == nil; page++ {
+		msgs, err := s.ChannelMessagesBefore(channelID, before)
+		if err != nil {
+			return errors.Wrap(err, "reading channel history")
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		for _, m := range msgs {
+			if m.Author == nil || m.Author.ID != p.cfg.BotID || !m.Timestamp.Before(cutoff) {
+				continue
+			}
+			if err := withRetry(func() error { return s.ChannelMessageDelete(channelID, m.ID) }); err != nil {
+				return errors.Wrap(err, "deleting message")
+			}
+			deleted++
+		}
+		before = msgs[len(msgs)-1].ID
+	}
+
+	threads, err := s.ChannelThreads(channelID)
+	if err != nil {
+		return errors.Wrap(err, "listing threads")
+	}
+	removed := 0
+	for _, th := range threads {
+		if ctx.Err() != nil {
+			break
+		}
+		if th.OwnerID != p.cfg.BotID || !lastActivity(th).Before(cutoff) {
+			continue
+		}
+		if err := withRetry(func() error { return s.ChannelDelete(th.ID) }); err != nil {
+			return errors.Wrap(err, "deleting thread")
+		}
+		removed++
+	}
+	if deleted > 0 || removed > 0 {
+		slog.Info("pruned discord channel", "channel", channelID, "messages", deleted, "threads", removed)
+	}
+	return nil
+}
+
+// lastActivity is when th last had a message, or was created if it has
+// none.
+func lastActivity(th *discordgo.Channel) time.Time {
+	id := th.LastMessageID
+	if id == "" {
+		id = th.ID
+	}
+	t, err := discordgo.SnowflakeTimestamp(id)
+	if err != nil {
+		// Unknown age: keep the thread.
+		return time.Now()
+	}
+	return t
+}
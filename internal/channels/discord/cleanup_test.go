@@ -0,0 +1,142 @@
+package discord
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// janitorSession records archives and deletions.
+type janitorSession struct {
+	sessionFull
+
+	mu              sync.Mutex
+	archived        []string
+	messages        []*discordgo.Message
+	threads         []*discordgo.Channel
+	deletedMessages []string
+	deletedThreads  []string
+}
+
+func (s *janitorSession) ArchiveThread(threadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archived = append(s.archived, threadID)
+	return nil
+}
+
+func (s *janitorSession) archivedThreads() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.archived...)
+}
+
+func (s *janitorSession) ChannelMessagesBefore(_, beforeID string) ([]*discordgo.Message, error) {
+	if beforeID != "" {
+		return nil, nil
+	}
+	return s.messages, nil
+}
+
+func (s *janitorSession) ChannelMessageDelete(_, messageID string) error {
+	s.deletedMessages = append(s.deletedMessages, messageID)
+	return nil
+}
+
+func (s *janitorSession) ChannelThreads(string) ([]*discordgo.Channel, error) {
+	return s.threads, nil
+}
+
+func (s *janitorSession) ChannelDelete(channelID string) error {
+	s.deletedThreads = append(s.deletedThreads, channelID)
+	return nil
+}
+
+// snowflake returns a Discord ID minted at t.
+func snowflake(t time.Time) string {
+	return strconv.FormatInt((t.UnixMilli()-1420070400000)<<22, 10)
+}
+
+func TestPlugin_ArchivesOwnedThreadAfterResponse(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a plugin archiving its threads 10ms after the last response
+	s := &janitorSession{}
+	s.On("ChannelMessageSend", mock.Anything, mock.Anything).Return(nil)
+	p := New(Config{BotID: "bot-id", ArchiveAfter: 10 * time.Millisecond}, s)
+	p.threads.markOwned("thread-1")
+
+	// when
+	// ... responses are posted in the owned thread and a DM
+	r.NoError(p.outbound("thread-1", "msg-1").PostResponse("done"))
+	r.NoError(p.outbound("dm-1", "msg-2").PostResponse("done"))
+
+	// then
+	// ... only the thread is archived
+	r.Eventually(func() bool { return len(s.archivedThreads()) > 0 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	a.Equal([]string{"thread-1"}, s.archivedThreads())
+}
+
+func TestPlugin_NewMessageCancelsArchive(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a pending archive for an owned thread
+	s := &janitorSession{}
+	s.On("ChannelMessageSend", mock.Anything, mock.Anything).Return(nil)
+	p := New(Config{BotID: "bot-id", ArchiveAfter: 20 * time.Millisecond}, s)
+	p.threads.markOwned("thread-1")
+	r.NoError(p.outbound("thread-1", "msg-1").PostResponse("done"))
+
+	// when
+	// ... the thread becomes active again before the timer fires
+	p.active("thread-1")
+	time.Sleep(40 * time.Millisecond)
+
+	// then
+	a.Empty(s.archivedThreads())
+}
+
+func TestPlugin_PruneChannelDeletesOldBotMessagesAndThreads(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... old and recent messages and threads from the bot and a user
+	now := time.Now()
+	old, recent := now.Add(-10*24*time.Hour), now.Add(-time.Hour)
+	bot, user := &discordgo.User{ID: "bot-id"}, &discordgo.User{ID: "user-1"}
+	s := &janitorSession{
+		messages: []*discordgo.Message{
+			{ID: "m-recent", Author: bot, Timestamp: recent},
+			{ID: "m-user", Author: user, Timestamp: old},
+			{ID: "m-old", Author: bot, Timestamp: old},
+		},
+		threads: []*discordgo.Channel{
+			{ID: snowflake(old), OwnerID: "bot-id", LastMessageID: snowflake(recent)},
+			{ID: snowflake(old.Add(time.Second)), OwnerID: "bot-id", LastMessageID: snowflake(old)},
+			{ID: snowflake(old.Add(2 * time.Second)), OwnerID: "user-1"},
+		},
+	}
+	p := New(Config{BotID: "bot-id"}, s)
+
+	// when
+	err := p.pruneChannel(context.Background(), s, "channel-1", now.Add(-7*24*time.Hour))
+
+	// then
+	// ... only the bot's old message and its idle thread are gone
+	r.NoError(err)
+	a.Equal([]string{"m-old"}, s.deletedMessages)
+	a.Equal([]string{snowflake(old.Add(time.Second))}, s.deletedThreads)
+}
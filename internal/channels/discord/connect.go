@@ -12,7 +12,13 @@ func Connect(token string) (*discordgo.Session, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "creating discord session")
 	}
-	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentMessageContent | discordgo.IntentDirectMessages
+	// Voice states are only needed to join a channel in voice mode, and
+	// reactions for pinning answers with 📌.
+	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentMessageContent | discordgo.IntentDirectMessages | discordgo.IntentsGuildVoiceStates | discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessageReactions
+	// discordgo still waits out the rate-limit buckets from response
+	// headers, but a 429 comes back as RateLimitError so outbound can retry
+	// it a bounded number of times instead of retrying without limit.
+	dg.ShouldRetryOnRateLimit = false
 	if err := dg.Open(); err != nil {
 		return nil, errors.Wrap(err, "opening discord session")
 	}
@@ -0,0 +1,71 @@
+package discord
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+// editRerunWindow is how long after dispatch an edited prompt is re-run.
+// Older edits are treated as tidy-ups and ignored.
+const editRerunWindow = 2 * time.Minute
+
+// editRerunEmoji marks a prompt whose edit was picked up.
+const editRerunEmoji = "🔁"
+
+// promptRecord remembers where a dispatched prompt was routed so an edit
+// can be sent to the same thread and session.
+type promptRecord struct {
+	threadID string
+	key      core.SessionKey
+	content  string
+	at       time.Time
+}
+
+// promptTracker holds recently dispatched prompts keyed by message ID.
+// Entries older than editRerunWindow are pruned on every record.
+type promptTracker struct {
+	mu      sync.Mutex
+	prompts map[string]promptRecord
+	now     func() time.Time
+}
+
+func newPromptTracker() *promptTracker {
+	return &promptTracker{prompts: make(map[string]promptRecord), now: time.Now}
+}
+
+func (t *promptTracker) record(messageID string, rec promptRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	for id, r := range t.prompts {
+		if now.Sub(r.at) > editRerunWindow {
+			delete(t.prompts, id)
+		}
+	}
+	rec.at = now
+	t.prompts[messageID] = rec
+}
+
+// edited returns the record for messageID if it is still inside the window
+// and content differs from what was last dispatched, updating the stored
+// content so duplicate update events don't re-run twice.
+func (t *promptTracker) edited(messageID, content string) (promptRecord, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec, ok := t.prompts[messageID]
+	if !ok || t.now().Sub(rec.at) > editRerunWindow || rec.content == content {
+		return promptRecord{}, false
+	}
+	rec.content = content
+	t.prompts[messageID] = rec
+	return rec, true
+}
+
+// editedPromptText frames a re-run so the model treats it as a replacement
+// for the original prompt, whether it arrives as a fresh turn or as
+// steering for one still in flight.
+func editedPromptText(text string) string {
+	return "[The user edited their previous message. This version replaces it:]\n" + text
+}
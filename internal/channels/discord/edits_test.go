@@ -0,0 +1,80 @@
+package discord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptTracker_EditedOnlyWithinWindowAndOnChange(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a tracker with a controllable clock and one recorded prompt
+	now := time.Unix(1000, 0)
+	tr := newPromptTracker()
+	tr.now = func() time.Time { return now }
+	tr.record("m1", promptRecord{threadID: "t1", key: "discord:thread:t1", content: "helo"})
+
+	// when / then
+	// ... identical content (e.g. an embed unfurl) is not an edit
+	_, ok := tr.edited("m1", "helo")
+	a.False(ok)
+
+	// ... a changed prompt inside the window is, exactly once
+	rec, ok := tr.edited("m1", "hello")
+	a.True(ok)
+	a.Equal("t1", rec.threadID)
+	_, ok = tr.edited("m1", "hello")
+	a.False(ok)
+
+	// ... and nothing is re-run once the window has passed
+	now = now.Add(editRerunWindow + time.Second)
+	_, ok = tr.edited("m1", "hello again")
+	a.False(ok)
+}
+
+func TestPlugin_EditedPrompt_RerunsInOriginalThread(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a prompt that opened thread-new
+	s := &sessionFull{}
+	s.On("MessageThreadStartComplex", "channel-1", "msg-1", mock.Anything).Return("thread-new", nil).Once()
+	s.On("MessageReactionAdd", "channel-1", "msg-1", editRerunEmoji).Return(nil).Once()
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+	ev := messageEvent{AuthorID: "user-1", ChannelID: "channel-1", MessageID: "msg-1", Content: "<@bot-id> fix the tpyo"}
+	p.handleMessage(ev)
+
+	// when
+	// ... the user edits the prompt
+	ev.Content = "<@bot-id> fix the typo"
+	p.handleEdit(ev)
+
+	// then
+	// ... the edit is re-delivered to the same session without opening a new thread
+	r.Len(got, 2)
+	a.Equal(got[0].SessionKey, got[1].SessionKey)
+	a.Equal(editedPromptText("fix the typo"), got[1].Text)
+	s.AssertExpectations(t)
+}
+
+func TestPlugin_EditOfUnknownMessage_Ignored(t *testing.T) {
+	// given
+	s := &sessionFull{}
+	delivered := false
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(core.Inbound) { delivered = true })
+
+	// when
+	p.handleEdit(messageEvent{AuthorID: "user-1", ChannelID: "channel-1", MessageID: "old", Content: "<@bot-id> hi"})
+
+	// then
+	assert.False(t, delivered)
+	s.AssertExpectations(t)
+}
@@ -0,0 +1,86 @@
+package discord
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// The reactions the bot offers under each answer when feedback prompts are
+// on. An allowed user adding one rates the answer.
+const (
+	feedbackUp   = "👍"
+	feedbackDown = "👎"
+)
+
+// ratedAnswersMax bounds how many answers stay open for rating. When
+// exceeded, the oldest is forgotten.
+const ratedAnswersMax = 1000
+
+// ratedAnswers maps the bot's answer messages to the turns that produced
+// them.
+type ratedAnswers struct {
+	mu    sync.Mutex
+	turns map[string]string
+	order []string
+}
+
+func newRatedAnswers() *ratedAnswers {
+	return &ratedAnswers{turns: make(map[string]string)}
+}
+
+func (r *ratedAnswers) add(messageID, turnID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.turns[messageID]; !exists {
+		r.order = append(r.order, messageID)
+		if len(r.order) > ratedAnswersMax {
+			delete(r.turns, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+	r.turns[messageID] = turnID
+}
+
+func (r *ratedAnswers) turn(messageID string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.turns[messageID]
+	return t, ok
+}
+
+// promptFeedback reacts 👍 and 👎 to the bot's newest message in threadID
+// and remembers it as turnID's answer.
+func (p *Plugin) promptFeedback(threadID, turnID string) error {
+	s, ok := p.session.(messagePinner)
+	if !ok {
+		return errors.New("feedback is not supported by this session")
+	}
+	id, err := p.latestBotMessage(s, threadID)
+	if err != nil {
+		return err
+	}
+	p.rated.add(id, turnID)
+	for _, emoji := range []string{feedbackUp, feedbackDown} {
+		if err := withRetry(func() error { return p.session.MessageReactionAdd(threadID, id, emoji) }); err != nil {
+			return errors.Wrap(err, "adding feedback reaction")
+		}
+	}
+	return nil
+}
+
+// handleFeedbackReaction hands the bot "/feedback up|down <turn>" for a
+// rating of one of its recent answers.
+func (p *Plugin) handleFeedbackReaction(ev messageEvent, emoji string) {
+	turnID, ok := p.rated.turn(ev.MessageID)
+	if !ok {
+		return
+	}
+	verdict := "up"
+	if emoji == feedbackDown {
+		verdict = "down"
+	}
+	slog.Info("discord answer rated", "channel", ev.ChannelID, "message", ev.MessageID, "turn", turnID, "rating", verdict)
+	p.deliverReaction(ev, "/feedback "+verdict+" "+turnID)
+}
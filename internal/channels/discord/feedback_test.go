@@ -0,0 +1,58 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugin_FeedbackReactionsRateTurn(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an answer that was offered for rating
+	s := &pinSession{messages: []*discordgo.Message{{ID: "answer-1", Author: &discordgo.User{ID: "bot-id"}}}}
+	s.On("MessageReactionAdd", "thread-1", "answer-1", feedbackUp).Return(nil).Once()
+	s.On("MessageReactionAdd", "thread-1", "answer-1", feedbackDown).Return(nil).Once()
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+	p.threads.markOwned("thread-1")
+	r.NoError(p.outbound("thread-1", "msg-1").PromptFeedback("turn-1"))
+
+	// when
+	// ... the user clicks 👎 on it, and 👍 on a message never offered
+	ev := messageEvent{AuthorID: "user-1", ChannelID: "thread-1", GuildID: "guild-1", ParentID: "channel-1", IsThread: true}
+	ev.MessageID = "answer-1"
+	p.handleReaction(ev, feedbackDown)
+	ev.MessageID = "answer-0"
+	p.handleReaction(ev, feedbackUp)
+
+	// then
+	s.AssertExpectations(t)
+	r.Len(got, 1)
+	a.Equal("/feedback down turn-1", got[0].Text)
+	a.Equal(core.SessionKey("discord:thread:thread-1"), got[0].SessionKey)
+	a.Equal("discord:user-1", got[0].UserID)
+}
+
+func TestRatedAnswers_EvictsOldest(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	r := newRatedAnswers()
+
+	// when
+	for i := 0; i <= ratedAnswersMax; i++ {
+		r.add(fmtThread(i), "turn")
+	}
+
+	// then
+	_, first := r.turn(fmtThread(0))
+	_, last := r.turn(fmtThread(ratedAnswersMax))
+	a.False(first)
+	a.True(last)
+}
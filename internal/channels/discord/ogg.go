@@ -0,0 +1,106 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// opusFrameSamples is the length of one Discord voice frame: 20 ms at
+// 48 kHz.
+const opusFrameSamples = 960
+
+// oggCRC is the Ogg page checksum table: CRC-32 with polynomial 0x04c11db7,
+// unreflected, no final xor.
+var oggCRC = func() (t [256]uint32) {
+	for i := range t {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+// oggOpus wraps raw Opus frames, as Discord sends them, in an Ogg Opus
+// file a transcription endpoint can read, without decoding them.
+func oggOpus(frames [][]byte) []byte {
+	var w oggWriter
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1 // version
+	head[9] = 2 // channels
+	binary.LittleEndian.PutUint16(head[10:], 312)
+	binary.LittleEndian.PutUint32(head[12:], 48000)
+	w.page([][]byte{head}, 0, 0x02)
+
+	vendor := "switchboard"
+	tags := make([]byte, 8+4+len(vendor)+4)
+	copy(tags, "OpusTags")
+	binary.LittleEndian.PutUint32(tags[8:], uint32(len(vendor)))
+	copy(tags[12:], vendor)
+	w.page([][]byte{tags}, 0, 0)
+
+	var granule int64
+	for len(frames) > 0 {
+		// A page holds at most 255 lacing values.
+		n, segs := 0, 0
+		for n < len(frames) && segs+len(frames[n])/255+1 <= 255 {
+			segs += len(frames[n])/255 + 1
+			n++
+		}
+		granule += int64(n * opusFrameSamples)
+		flags := byte(0)
+		if n == len(frames) {
+			flags = 0x04
+		}
+		w.page(frames[:n], granule, flags)
+		frames = frames[n:]
+	}
+	return w.buf.Bytes()
+}
+
+type oggWriter struct {
+	buf bytes.Buffer
+	seq uint32
+}
+
+// page appends one page holding packets.
+func (w *oggWriter) page(packets [][]byte, granule int64, flags byte) {
+	var lacing []byte
+	var body []byte
+	for _, p := range packets {
+		for n := len(p); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		body = append(body, p...)
+	}
+
+	hdr := make([]byte, 27, 27+len(lacing))
+	copy(hdr, "OggS")
+	hdr[5] = flags
+	binary.LittleEndian.PutUint64(hdr[6:], uint64(granule))
+	binary.LittleEndian.PutUint32(hdr[14:], 1) // stream serial
+	binary.LittleEndian.PutUint32(hdr[18:], w.seq)
+	hdr[26] = byte(len(lacing))
+	hdr = append(hdr, lacing...)
+	w.seq++
+
+	var crc uint32
+	for _, b := range [][]byte{hdr, body} {
+		for _, c := range b {
+			crc = crc<<8 ^ oggCRC[byte(crc>>24)^c]
+		}
+	}
+	binary.LittleEndian.PutUint32(hdr[22:], crc)
+	w.buf.Write(hdr)
+	w.buf.Write(body)
+}
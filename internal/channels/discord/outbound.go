@@ -1,23 +1,63 @@
 package discord
 
 import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/faults"
+	"github.com/bwmarrin/discordgo"
 	"github.com/pkg/errors"
 )
 
+// updateWindow is how long SendUpdate waits for more updates before posting
+// them as one message, so a burst of tool activity doesn't trip Discord's
+// per-channel rate limit.
+var updateWindow = time.Second
+
+// messageLink matches a Discord message link, capturing the channel and
+// message IDs.
+var messageLink = regexp.MustCompile(`^https://(?:(?:ptb|canary)\.)?discord(?:app)?\.com/channels/(?:\d+|@me)/(\d+)/(\d+)$`)
+
+// maxSendRetries bounds how often a rate-limited send is retried.
+const maxSendRetries = 3
+
 // discordSession is the slice of *discordgo.Session that outbound needs.
 // Defined as an interface so tests can mock it.
 type discordSession interface {
 	ChannelMessageSend(channelID, content string) error
 	ChannelTyping(channelID string) error
 	MessageReactionAdd(channelID, messageID, emoji string) error
+	ChannelFileSend(channelID, name string, content []byte) error
+	ChannelMessage(channelID, messageID string) (string, error)
 }
 
+// outbound sends everything for one inbound through a single path: updates
+// are batched, sends keep their order, and 429s are retried after the
+// delay Discord asks for.
 type outbound struct {
 	s         discordSession
 	threadID  string
 	messageID string
 	maxLen    int
+
+	// sendMu serialises sends so a flushed batch of updates never lands
+	// after the response that followed it.
+	sendMu  sync.Mutex
+	mu      sync.Mutex
+	pending []string
+	timer   *time.Timer
+
+	faults faults.Config
+	// responded, when set, is called after each response is posted.
+	responded func()
+	// pinLatest pins the bot's newest message in the thread.
+	pinLatest func() error
+	// promptFeedback offers 👍/👎 on the bot's newest message.
+	promptFeedback func(turnID string) error
 }
 
 func newOutbound(s discordSession, threadID, messageID string, maxLen int) *outbound {
@@ -29,10 +69,17 @@ func (o *outbound) SendTyping() error {
 }
 
 func (o *outbound) PostResponse(content string) error {
-	for _, chunk := range core.ChunkMessage(content, o.maxLen) {
-		if err := o.s.ChannelMessageSend(o.threadID, chunk); err != nil {
-			return errors.Wrap(err, "discord send")
-		}
+	if err := o.flushUpdates(); err != nil {
+		slog.Warn("discord update", "thread", o.threadID, "error", err)
+	}
+	o.sendMu.Lock()
+	err := o.sendText(content)
+	o.sendMu.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "discord send")
+	}
+	if o.responded != nil {
+		o.responded()
 	}
 	return nil
 }
@@ -41,11 +88,128 @@ func (o *outbound) AddReaction(emoji string) error {
 	return errors.Wrap(o.s.MessageReactionAdd(o.threadID, o.messageID, emoji), "discord react")
 }
 
+// SendUpdate queues message and posts it, with any others that arrive
+// within updateWindow, as one message.
 func (o *outbound) SendUpdate(message string) error {
-	for _, chunk := range core.ChunkMessage(message, o.maxLen) {
-		if err := o.s.ChannelMessageSend(o.threadID, chunk); err != nil {
-			return errors.Wrap(err, "discord update")
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pending = append(o.pending, message)
+	if o.timer == nil {
+		o.timer = time.AfterFunc(updateWindow, func() {
+			if err := o.flushUpdates(); err != nil {
+				slog.Warn("discord update", "thread", o.threadID, "error", err)
+			}
+		})
+	}
+	return nil
+}
+
+// flushUpdates posts the queued updates now.
+func (o *outbound) flushUpdates() error {
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+
+	o.mu.Lock()
+	updates := o.pending
+	o.pending = nil
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	o.mu.Unlock()
+
+	if len(updates) == 0 {
+		return nil
+	}
+	return errors.Wrap(o.sendText(strings.Join(updates, "\n")), "discord update")
+}
+
+// sendText posts text in chunks. sendMu must be held.
+func (o *outbound) sendText(text string) error {
+	for _, chunk := range core.ChunkMessage(text, o.maxLen) {
+		err := o.send(func() error { return o.s.ChannelMessageSend(o.threadID, chunk) })
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+func (o *outbound) SendFile(name string, content []byte) error {
+	if err := o.flushUpdates(); err != nil {
+		slog.Warn("discord update", "thread", o.threadID, "error", err)
+	}
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+	err := o.send(func() error { return o.s.ChannelFileSend(o.threadID, name, content) })
+	return errors.Wrap(err, "discord file send")
+}
+
+// FetchMessage reads the message a Discord message link points to.
+func (o *outbound) FetchMessage(link string) (string, bool, error) {
+	m := messageLink.FindStringSubmatch(link)
+	if m == nil {
+		return "", false, nil
+	}
+	text, err := o.s.ChannelMessage(m[1], m[2])
+	return text, true, errors.Wrap(err, "discord fetch message")
+}
+
+// PinLatest pins the bot's newest message in the thread, for /pin-answer.
+func (o *outbound) PinLatest() error {
+	if o.pinLatest == nil {
+		return errors.New("discord pinning is not available")
+	}
+	return o.pinLatest()
+}
+
+// PromptFeedback reacts 👍 and 👎 to the response just posted; a user's
+// click on either rates turnID.
+func (o *outbound) PromptFeedback(turnID string) error {
+	if o.promptFeedback == nil {
+		return errors.New("discord feedback is not available")
+	}
+	return o.promptFeedback(turnID)
+}
+
+// SendVoice posts audio as an attachment; Discord clients play it inline.
+func (o *outbound) SendVoice(audio []byte, mimeType string) error {
+	name := "response.ogg"
+	if strings.HasPrefix(mimeType, "audio/mpeg") {
+		name = "response.mp3"
+	}
+	return o.SendFile(name, audio)
+}
+
+// send makes a Discord request with withRetry. Each attempt first waits
+// out any injected delay and may fail with an injected rate limit.
+func (o *outbound) send(request func() error) error {
+	return withRetry(func() error {
+		if o.faults.DiscordSendDelay > 0 {
+			time.Sleep(o.faults.DiscordSendDelay)
+		}
+		if faults.Hit(o.faults.Discord429Rate) {
+			return discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+				TooManyRequests: &discordgo.TooManyRequests{Message: "injected fault", RetryAfter: time.Second},
+			}}
+		}
+		return request()
+	})
+}
+
+// withRetry runs send, retrying up to maxSendRetries times while Discord
+// answers 429.
+func withRetry(send func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := send()
+		var limited discordgo.RateLimitError
+		if attempt == maxSendRetries || !errors.As(err, &limited) {
+			return err
+		}
+		wait := time.Second
+		if limited.RateLimit != nil && limited.TooManyRequests != nil && limited.RetryAfter > 0 {
+			wait = limited.RetryAfter
+		}
+		time.Sleep(wait)
+	}
+}
@@ -3,7 +3,9 @@ package discord
 import (
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,6 +21,13 @@ func (m *discordSessionMock) ChannelTyping(channelID string) error {
 func (m *discordSessionMock) MessageReactionAdd(channelID, messageID, emoji string) error {
 	return m.Called(channelID, messageID, emoji).Error(0)
 }
+func (m *discordSessionMock) ChannelFileSend(channelID, name string, content []byte) error {
+	return m.Called(channelID, name, content).Error(0)
+}
+func (m *discordSessionMock) ChannelMessage(channelID, messageID string) (string, error) {
+	args := m.Called(channelID, messageID)
+	return args.String(0), args.Error(1)
+}
 
 const maxLen = 2000
 
@@ -70,32 +79,141 @@ func TestOutbound_SendUpdate_ChunksLongMessages(t *testing.T) {
 	s.On("ChannelMessageSend", "thread-1", mock.Anything).Return(nil).Twice()
 
 	// when
-	// ... SendUpdate is called with the long payload
+	// ... SendUpdate is called with the long payload and the batch is flushed
 	err := o.SendUpdate(long)
+	flushErr := o.flushUpdates()
 
 	// then
 	// ... the payload was sent in 2 chunks to the thread
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if err != nil || flushErr != nil {
+		t.Fatalf("unexpected error: %v, %v", err, flushErr)
 	}
 	s.AssertNumberOfCalls(t, "ChannelMessageSend", 2)
 }
 
 func TestOutbound_SendUpdate_PostsInSameThread(t *testing.T) {
 	// given
-	// ... an outbound bound to a thread
+	// ... an outbound bound to a thread and a short update window
+	defer func(w time.Duration) { updateWindow = w }(updateWindow)
+	updateWindow = 10 * time.Millisecond
 	s := &discordSessionMock{}
 	o := newOutbound(s, "thread-1", "msg-1", maxLen)
-	s.On("ChannelMessageSend", "thread-1", "doing the thing").Return(nil).Once()
+	posted := make(chan struct{})
+	s.On("ChannelMessageSend", "thread-1", "doing the thing").Run(func(mock.Arguments) { close(posted) }).Return(nil).Once()
 
 	// when
 	// ... SendUpdate is called
 	err := o.SendUpdate("doing the thing")
 
 	// then
-	// ... the update was posted to the thread
+	// ... the update was posted to the thread once the window passed
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-posted:
+	case <-time.After(time.Second):
+		t.Fatal("update was never posted")
+	}
+	s.AssertExpectations(t)
+}
+
+func TestOutbound_SendUpdate_BatchesBeforeResponse(t *testing.T) {
+	// given
+	// ... three quick updates and then the final response
+	s := &discordSessionMock{}
+	o := newOutbound(s, "thread-1", "msg-1", maxLen)
+	var sent []string
+	s.On("ChannelMessageSend", "thread-1", mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.String(1))
+	}).Return(nil)
+
+	// when
+	o.SendUpdate("Read a.go")
+	o.SendUpdate("Read b.go")
+	o.SendUpdate("Bash go test")
+	err := o.PostResponse("All tests pass.")
+
+	// then
+	// ... the updates went out as one message, ahead of the response
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	want := []string{"Read a.go\nRead b.go\nBash go test", "All tests pass."}
+	if strings.Join(sent, "|") != strings.Join(want, "|") {
+		t.Fatalf("sent %q, want %q", sent, want)
+	}
+}
+
+func TestOutbound_PostResponse_RetriesRateLimit(t *testing.T) {
+	// given
+	// ... Discord answers 429 once
+	s := &discordSessionMock{}
+	o := newOutbound(s, "thread-1", "msg-1", maxLen)
+	limited := discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+		TooManyRequests: &discordgo.TooManyRequests{RetryAfter: time.Millisecond},
+	}}
+	s.On("ChannelMessageSend", "thread-1", "done").Return(limited).Once()
+	s.On("ChannelMessageSend", "thread-1", "done").Return(nil).Once()
+
+	// when
+	err := o.PostResponse("done")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.AssertNumberOfCalls(t, "ChannelMessageSend", 2)
+}
+
+func TestOutbound_SendFile_UploadsToThread(t *testing.T) {
+	// given
+	s := &discordSessionMock{}
+	s.On("ChannelFileSend", "thread-1", "fix.patch", []byte("diff")).Return(nil).Once()
+	o := newOutbound(s, "thread-1", "msg-1", maxLen)
+
+	// when
+	err := o.SendFile("fix.patch", []byte("diff"))
+
+	// then
+	if err != nil {
+		t.Fatalf("SendFile: %v", err)
+	}
+	s.AssertExpectations(t)
+}
+
+func TestOutbound_SendVoice_AttachesAudio(t *testing.T) {
+	// given
+	s := &discordSessionMock{}
+	s.On("ChannelFileSend", "thread-1", "response.ogg", []byte("ogg")).Return(nil).Once()
+	o := newOutbound(s, "thread-1", "msg-1", maxLen)
+
+	// when
+	err := o.SendVoice([]byte("ogg"), "audio/ogg; codecs=opus")
+
+	// then
+	if err != nil {
+		t.Fatalf("SendVoice: %v", err)
+	}
+	s.AssertExpectations(t)
+}
+
+func TestOutbound_FetchMessage(t *testing.T) {
+	// given
+	s := &discordSessionMock{}
+	s.On("ChannelMessage", "222", "333").Return("we use tabs", nil).Once()
+	o := newOutbound(s, "thread-1", "msg-1", maxLen)
+
+	// when
+	text, ok, err := o.FetchMessage("https://discord.com/channels/111/222/333")
+	_, notLink, _ := o.FetchMessage("https://example.com/channels/111/222/333")
+
+	// then
+	if err != nil || !ok || text != "we use tabs" {
+		t.Fatalf("FetchMessage = %q, %v, %v", text, ok, err)
+	}
+	if notLink {
+		t.Fatal("non-Discord URL treated as a message link")
+	}
 	s.AssertExpectations(t)
 }
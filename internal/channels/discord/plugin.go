@@ -1,6 +1,7 @@
 package discord
 
 import (
+	"bytes"
 	"context"
 	"log/slog"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/faults"
 	"github.com/bwmarrin/discordgo"
 	"github.com/pkg/errors"
 )
@@ -21,6 +23,7 @@ const maxDiscordMessageLen = 2000
 type messageEvent struct {
 	AuthorID    string
 	ChannelID   string
+	GuildID     string // empty in DMs
 	ParentID    string // populated when IsThread is true
 	MessageID   string
 	Content     string
@@ -41,13 +44,33 @@ type Config struct {
 	// Downloader fetches raw bytes from Discord CDN URLs. When nil and MediaDir
 	// is set, New installs an HTTPDownloader with a 30 s timeout.
 	Downloader Downloader
+	// MaxResponseLen caps a final response before the bot condenses it.
+	// Zero disables the cap.
+	MaxResponseLen int
+	// Faults delays or fails sends on purpose (FAULT_INJECTION).
+	Faults faults.Config
+	// ArchiveAfter archives a thread the bot opened this long after its
+	// last response. Zero leaves it to Discord's hour of inactivity.
+	ArchiveAfter time.Duration
+	// Prune maps channel IDs to how long the bot's messages and threads
+	// there are kept. Channels not listed are never pruned.
+	Prune map[string]time.Duration
+	// Ignores reports whether the bot would drop a message to a paused
+	// channel (core.Bot.Ignores); such messages are dropped before any
+	// download or new thread. Nil checks nothing.
+	Ignores func(channelID, text string) bool
 }
 
 // Plugin implements core.ChannelPlugin for Discord.
 type Plugin struct {
-	cfg     Config
-	session sessionForPlugin
-	threads *threadRegistry
+	cfg      Config
+	session  sessionForPlugin
+	threads  *threadRegistry
+	prompts  *promptTracker
+	archives *archiveTimers
+	rated    *ratedAnswers
+	// seen drops MESSAGE_CREATEs the gateway redelivers after a reconnect.
+	seen    *core.Dedup
 	mu      sync.Mutex
 	deliver func(core.Inbound)
 }
@@ -59,6 +82,14 @@ type sessionForPlugin interface {
 	MessageThreadStartComplex(channelID, messageID, name string) (string, error)
 }
 
+// gateway is the event side of a session: the real discordgo session, or
+// testkit.Discord in end-to-end tests. AddHandler takes discordgo handler
+// funcs; ChannelState looks a channel up in the gateway's cache.
+type gateway interface {
+	AddHandler(handler interface{}) func()
+	ChannelState(channelID string) (*discordgo.Channel, error)
+}
+
 // New constructs a Plugin with a caller-owned session. The production caller
 // opens a real discordgo session via Connect(token), wraps it with WrapSession,
 // and sets BotID from dg.State.User.ID before calling Start. Tests pass a mock
@@ -70,13 +101,29 @@ func New(cfg Config, s sessionForPlugin) *Plugin {
 			Client: &http.Client{Timeout: 30 * time.Second},
 		}
 	}
-	return &Plugin{cfg: cfg, session: s, threads: newThreadRegistry()}
+	return &Plugin{cfg: cfg, session: s, threads: newThreadRegistry(), prompts: newPromptTracker(), archives: newArchiveTimers(), rated: newRatedAnswers(), seen: core.NewDedup(core.DefaultDedupSize)}
 }
 
 func (p *Plugin) ID() string { return "discord" }
 
 func (p *Plugin) Capabilities() core.Capabilities {
-	return core.Capabilities{Reactions: true, Media: p.cfg.MediaDir != "", Updates: true}
+	return core.Capabilities{Reactions: true, Media: p.cfg.MediaDir != "", Updates: true, Files: true, Voice: true}
+}
+
+// OpenChat returns an Outbound for a channel or thread, for posts the bot
+// makes without an inbound.
+func (p *Plugin) OpenChat(chatID, messageID string) core.Outbound {
+	return p.outbound(chatID, messageID)
+}
+
+// outbound returns the Outbound for a reply in threadID.
+func (p *Plugin) outbound(threadID, messageID string) *outbound {
+	o := newOutbound(p.session, threadID, messageID, maxDiscordMessageLen)
+	o.faults = p.cfg.Faults
+	o.responded = func() { p.responded(threadID) }
+	o.pinLatest = func() error { return p.pinLatest(threadID) }
+	o.promptFeedback = func(turnID string) error { return p.promptFeedback(threadID, turnID) }
+	return o
 }
 
 func (p *Plugin) Start(ctx context.Context, deliver func(core.Inbound)) error {
@@ -87,23 +134,60 @@ func (p *Plugin) Start(ctx context.Context, deliver func(core.Inbound)) error {
 	if p.session == nil {
 		return errors.New("discord plugin started without a session")
 	}
-	dg, ok := p.session.(sessionAdapter)
+	if pr, ok := p.session.(channelPruner); ok && len(p.cfg.Prune) > 0 {
+		go p.prune(ctx, pr)
+	}
+	gw, ok := p.session.(gateway)
 	if !ok {
-		// Test session injected — register nothing on the real discordgo session.
+		// Mock session injected — there are no events to listen for.
 		return nil
 	}
 
-	dg.AddHandler(func(_ *discordgo.Session, m *discordgo.MessageCreate) {
-		ev, ok := translateMessageCreate(m, p.cfg.BotID, dg.State.Channel)
+	gw.AddHandler(func(_ *discordgo.Session, m *discordgo.MessageCreate) {
+		ev, ok := translateMessageCreate(m, p.cfg.BotID, gw.ChannelState)
 		if !ok {
 			return
 		}
+		if !p.seen.First(ev.MessageID) {
+			slog.Info("dropping redelivered discord message", "channel", ev.ChannelID, "message", ev.MessageID)
+			return
+		}
+		defer core.Recover("discord message", p.notifyPanic(ev))
 		p.handleMessage(ev)
 	})
+	gw.AddHandler(func(_ *discordgo.Session, m *discordgo.MessageUpdate) {
+		if m.Message == nil {
+			return
+		}
+		ev, ok := translateMessageCreate(&discordgo.MessageCreate{Message: m.Message}, p.cfg.BotID, gw.ChannelState)
+		if !ok {
+			return
+		}
+		defer core.Recover("discord edit", p.notifyPanic(ev))
+		p.handleEdit(ev)
+	})
+	gw.AddHandler(func(_ *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		ev, emoji, ok := translateReactionAdd(r, p.cfg.BotID, gw.ChannelState)
+		if !ok {
+			return
+		}
+		defer core.Recover("discord reaction", p.notifyPanic(ev))
+		p.handleReaction(ev, emoji)
+	})
 
 	return nil
 }
 
+// notifyPanic replies to ev's message when handling it panicked, in the
+// channel it was sent in since the thread may not exist yet.
+func (p *Plugin) notifyPanic(ev messageEvent) func(string) {
+	return func(text string) {
+		if err := p.outbound(ev.ChannelID, ev.MessageID).PostResponse(text); err != nil {
+			slog.Warn("discord panic notice failed", "channel", ev.ChannelID, "error", err)
+		}
+	}
+}
+
 // translateMessageCreate converts a discordgo MessageCreate into the
 // platform-agnostic messageEvent the plugin's handleMessage consumes.
 // lookupChannel allows the State lookup to be stubbed in tests.
@@ -114,6 +198,7 @@ func translateMessageCreate(m *discordgo.MessageCreate, botID string, lookupChan
 	ev := messageEvent{
 		AuthorID:    m.Author.ID,
 		ChannelID:   m.ChannelID,
+		GuildID:     m.GuildID,
 		MessageID:   m.ID,
 		Content:     m.Content,
 		Attachments: m.Attachments,
@@ -147,6 +232,9 @@ func (p *Plugin) handleMessage(ev messageEvent) {
 	if !ok {
 		return
 	}
+	if p.cfg.Ignores != nil && p.cfg.Ignores(memoryChannelID(ev), cleaned) {
+		return
+	}
 
 	// Extract attachments when media processing is configured.
 	var refs []core.AttachmentRef
@@ -175,6 +263,7 @@ func (p *Plugin) handleMessage(ev messageEvent) {
 		}
 		return
 	}
+	p.active(threadID)
 
 	p.mu.Lock()
 	d := p.deliver
@@ -183,12 +272,96 @@ func (p *Plugin) handleMessage(ev messageEvent) {
 		return
 	}
 
+	key := sessionKey(ev, threadID)
+	p.prompts.record(ev.MessageID, promptRecord{threadID: threadID, key: key, content: cleaned})
+
 	d(core.Inbound{
-		SessionKey:   sessionKey(ev, threadID),
-		Text:         cleaned,
-		Attachments:  refs,
-		Reply:        newOutbound(p.session, threadID, ev.MessageID, maxDiscordMessageLen),
-		Capabilities: p.Capabilities(),
+		SessionKey:     key,
+		Text:           cleaned,
+		UserID:         "discord:" + ev.AuthorID,
+		ChannelID:      memoryChannelID(ev),
+		GuildID:        guildID(ev.GuildID),
+		Attachments:    refs,
+		Reply:          p.outbound(threadID, ev.MessageID),
+		Capabilities:   p.Capabilities(),
+		MaxResponseLen: p.cfg.MaxResponseLen,
+	})
+}
+
+// handleEdit re-runs a recently dispatched prompt whose text was edited.
+// The edit goes to the original thread and session: if the first turn is
+// still running it lands as steering, otherwise it starts a new turn.
+// Attachments are not re-processed.
+func (p *Plugin) handleEdit(ev messageEvent) {
+	if !p.userAllowed(ev.AuthorID) {
+		return
+	}
+	cleaned, ok := stripMention(ev.Content, p.cfg.BotID)
+	if !ok {
+		return
+	}
+	rec, ok := p.prompts.edited(ev.MessageID, cleaned)
+	if !ok {
+		return
+	}
+	p.active(rec.threadID)
+
+	p.mu.Lock()
+	d := p.deliver
+	p.mu.Unlock()
+	if d == nil {
+		return
+	}
+
+	if err := p.session.MessageReactionAdd(ev.ChannelID, ev.MessageID, editRerunEmoji); err != nil {
+		slog.Warn("discord reaction add failed", "channel", ev.ChannelID, "message", ev.MessageID, "error", err)
+	}
+	d(core.Inbound{
+		SessionKey:     rec.key,
+		Text:           editedPromptText(cleaned),
+		UserID:         "discord:" + ev.AuthorID,
+		ChannelID:      memoryChannelID(ev),
+		GuildID:        guildID(ev.GuildID),
+		Reply:          p.outbound(rec.threadID, ev.MessageID),
+		Capabilities:   p.Capabilities(),
+		MaxResponseLen: p.cfg.MaxResponseLen,
+	})
+}
+
+// handleReaction routes an allowed user's reaction: pinEmoji pins an
+// answer, feedbackUp and feedbackDown rate one.
+func (p *Plugin) handleReaction(ev messageEvent, emoji string) {
+	if !p.userAllowed(ev.AuthorID) {
+		return
+	}
+	switch emoji {
+	case pinEmoji:
+		p.handlePinReaction(ev)
+	case feedbackUp, feedbackDown:
+		p.handleFeedbackReaction(ev, emoji)
+	case retryEmoji:
+		p.handleRetryReaction(ev)
+	}
+}
+
+// deliverReaction hands the bot text as if the reacting user had sent it
+// in the reacted message's chat.
+func (p *Plugin) deliverReaction(ev messageEvent, text string) {
+	p.mu.Lock()
+	d := p.deliver
+	p.mu.Unlock()
+	if d == nil {
+		return
+	}
+	d(core.Inbound{
+		SessionKey:     sessionKey(ev, ev.ChannelID),
+		Text:           text,
+		UserID:         "discord:" + ev.AuthorID,
+		ChannelID:      memoryChannelID(ev),
+		GuildID:        guildID(ev.GuildID),
+		Reply:          p.outbound(ev.ChannelID, ev.MessageID),
+		Capabilities:   p.Capabilities(),
+		MaxResponseLen: p.cfg.MaxResponseLen,
 	})
 }
 
@@ -219,6 +392,23 @@ func sessionKey(ev messageEvent, threadID string) core.SessionKey {
 	return core.SessionKey("discord:thread:" + threadID)
 }
 
+// memoryChannelID names the channel notes are kept for. Every mention opens
+// its own thread, so threads share their parent channel's notes.
+func memoryChannelID(ev messageEvent) string {
+	if ev.IsThread && ev.ParentID != "" {
+		return "discord:" + ev.ParentID
+	}
+	return "discord:" + ev.ChannelID
+}
+
+// guildID namespaces a Discord server ID for core.Inbound.
+func guildID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "discord:" + id
+}
+
 func (p *Plugin) userAllowed(userID string) bool {
 	for _, u := range p.cfg.AllowedUsers {
 		if u == userID {
@@ -264,6 +454,23 @@ func (s sessionAdapter) ChannelMessageSend(channelID, content string) error {
 	return err
 }
 
+func (s sessionAdapter) ChannelFileSend(channelID, name string, content []byte) error {
+	_, err := s.Session.ChannelFileSend(channelID, name, bytes.NewReader(content))
+	return err
+}
+
+func (s sessionAdapter) ChannelMessage(channelID, messageID string) (string, error) {
+	m, err := s.Session.ChannelMessage(channelID, messageID)
+	if err != nil {
+		return "", err
+	}
+	return m.Content, nil
+}
+
+func (s sessionAdapter) ChannelState(channelID string) (*discordgo.Channel, error) {
+	return s.State.Channel(channelID)
+}
+
 func (s sessionAdapter) ChannelTyping(channelID string) error {
 	return s.Session.ChannelTyping(channelID)
 }
@@ -282,3 +489,74 @@ func (s sessionAdapter) MessageThreadStartComplex(channelID, messageID, name str
 	}
 	return t.ID, nil
 }
+
+func (s sessionAdapter) ArchiveThread(threadID string) error {
+	archived := true
+	_, err := s.Session.ChannelEdit(threadID, &discordgo.ChannelEdit{Archived: &archived})
+	return err
+}
+
+func (s sessionAdapter) ChannelMessagesBefore(channelID, beforeID string) ([]*discordgo.Message, error) {
+	return s.Session.ChannelMessages(channelID, 100, beforeID, "", "")
+}
+
+func (s sessionAdapter) ChannelMessageDelete(channelID, messageID string) error {
+	return s.Session.ChannelMessageDelete(channelID, messageID)
+}
+
+// ChannelThreads reads active threads from the guild's list, which the API
+// doesn't offer per channel, and pages through the archived ones.
+func (s sessionAdapter) ChannelThreads(channelID string) ([]*discordgo.Channel, error) {
+	ch, err := s.State.Channel(channelID)
+	if err != nil {
+		if ch, err = s.Session.Channel(channelID); err != nil {
+			return nil, err
+		}
+	}
+	var threads []*discordgo.Channel
+	active, err := s.Session.GuildThreadsActive(ch.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	for _, th := range active.Threads {
+		if th.ParentID == channelID {
+			threads = append(threads, th)
+		}
+	}
+	var before *time.Time
+	for {
+		archived, err := s.Session.ThreadsArchived(channelID, before, 100)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, archived.Threads...)
+		if !archived.HasMore || len(archived.Threads) == 0 {
+			return threads, nil
+		}
+		last := archived.Threads[len(archived.Threads)-1].ThreadMetadata
+		if last == nil {
+			return threads, nil
+		}
+		before = &last.ArchiveTimestamp
+	}
+}
+
+func (s sessionAdapter) ChannelDelete(channelID string) error {
+	_, err := s.Session.ChannelDelete(channelID)
+	return err
+}
+
+func (s sessionAdapter) ChannelMessageAuthor(channelID, messageID string) (string, error) {
+	m, err := s.Session.ChannelMessage(channelID, messageID)
+	if err != nil {
+		return "", err
+	}
+	if m.Author == nil {
+		return "", nil
+	}
+	return m.Author.ID, nil
+}
+
+func (s sessionAdapter) ChannelMessagePin(channelID, messageID string) error {
+	return s.Session.ChannelMessagePin(channelID, messageID)
+}
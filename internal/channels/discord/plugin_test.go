@@ -166,6 +166,31 @@ func TestPlugin_DM_UsesDMSessionKey(t *testing.T) {
 	}
 }
 
+func TestPlugin_Inbound_NamespacesGuildID(t *testing.T) {
+	// given
+	// ... a plugin and a server message
+	s := &sessionFull{}
+	s.On("MessageThreadStartComplex", "channel-1", "msg-1", mock.Anything).Return("thread-new", nil).Once()
+	var got core.Inbound
+	p := newTestPlugin(s, "bot-id", []string{"user-1"}, func(in core.Inbound) { got = in })
+
+	// when
+	// ... the message arrives
+	p.handleMessage(messageEvent{
+		AuthorID:  "user-1",
+		ChannelID: "channel-1",
+		GuildID:   "guild-1",
+		MessageID: "msg-1",
+		Content:   "<@bot-id> hello",
+	})
+
+	// then
+	// ... the inbound names the server so its /config settings apply
+	if got.GuildID != "discord:guild-1" {
+		t.Fatalf("guild id: %q", got.GuildID)
+	}
+}
+
 func TestPlugin_DisallowedUser_Ignored(t *testing.T) {
 	// given
 	// ... a plugin where user-2 is not allowed
@@ -214,6 +239,29 @@ func TestPlugin_Inbound_CapabilitiesMatchPluginCapabilities(t *testing.T) {
 	}
 }
 
+func TestPlugin_Inbound_CarriesMaxResponseLen(t *testing.T) {
+	// given
+	// ... a plugin configured with a response cap
+	s := &sessionFull{}
+	s.On("MessageThreadStartComplex", "channel-1", "msg-1", mock.Anything).Return("thread-new", nil).Once()
+	var got core.Inbound
+	p := New(Config{BotID: "bot-id", AllowedUsers: []string{"user-1"}, MaxResponseLen: 6000}, s)
+	_ = p.Start(context.Background(), func(in core.Inbound) { got = in })
+
+	// when
+	// ... a message arrives
+	p.handleMessage(messageEvent{
+		AuthorID:  "user-1",
+		ChannelID: "channel-1",
+		MessageID: "msg-1",
+		Content:   "<@bot-id> do the thing",
+	})
+
+	// then
+	// ... the inbound carries the configured cap
+	assert.Equal(t, 6000, got.MaxResponseLen)
+}
+
 func TestPlugin_Capabilities_UpdatesTrue(t *testing.T) {
 	// given
 	// ... a plugin with no media dir
@@ -498,3 +546,28 @@ func TestTranslate_AttachmentsPopulated(t *testing.T) {
 	a.Len(ev.Attachments, 1)
 	a.Equal("image.png", ev.Attachments[0].Filename)
 }
+
+func TestPlugin_MentionInPausedChannel_Dropped(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... channel-1 is paused for everything but /resume
+	s := &sessionFull{}
+	s.On("MessageThreadStartComplex", "channel-1", "msg-2", mock.Anything).Return("thread-new", nil).Once()
+	var got []core.Inbound
+	p := New(Config{BotID: "bot-id", AllowedUsers: []string{"user-1"}, Ignores: func(channelID, text string) bool {
+		return channelID == "discord:channel-1" && text != "/resume"
+	}}, s)
+	_ = p.Start(context.Background(), func(in core.Inbound) { got = append(got, in) })
+
+	// when
+	p.handleMessage(messageEvent{AuthorID: "user-1", ChannelID: "channel-1", MessageID: "msg-1", Content: "<@bot-id> hello"})
+	p.handleMessage(messageEvent{AuthorID: "user-1", ChannelID: "channel-1", MessageID: "msg-2", Content: "<@bot-id> /resume"})
+
+	// then
+	// ... only /resume opens a thread and reaches the bot
+	s.AssertExpectations(t)
+	r.Len(got, 1)
+	a.Equal("/resume", got[0].Text)
+}
@@ -0,0 +1,21 @@
+package discord
+
+import (
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// statusUpdater is the slice of a session that sets the bot's status.
+type statusUpdater interface {
+	UpdateCustomStatus(state string) error
+}
+
+// SetPresence shows pr as the bot's custom status, e.g. "Working on 2
+// tasks". Sessions that can't set a status ignore it.
+func (p *Plugin) SetPresence(pr core.Presence) error {
+	s, ok := p.session.(statusUpdater)
+	if !ok {
+		return nil
+	}
+	return errors.Wrap(s.UpdateCustomStatus(pr.String()), "updating discord status")
+}
@@ -0,0 +1,36 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusSession records custom statuses.
+type statusSession struct {
+	sessionFull
+	statuses []string
+}
+
+func (s *statusSession) UpdateCustomStatus(state string) error {
+	s.statuses = append(s.statuses, state)
+	return nil
+}
+
+func TestPlugin_SetPresenceUpdatesCustomStatus(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s := &statusSession{}
+	p := New(Config{BotID: "bot-id"}, s)
+
+	// when
+	r.NoError(p.SetPresence(core.Presence{Working: 2}))
+	r.NoError(p.SetPresence(core.Presence{}))
+
+	// then
+	a.Equal([]string{"Working on 2 tasks", "Idle"}, s.statuses)
+}
@@ -0,0 +1,28 @@
+package discord
+
+import "log/slog"
+
+// retryEmoji on the bot's last response hands the bot /retry.
+const retryEmoji = "🔁"
+
+// handleRetryReaction hands the bot "/retry" when an allowed user reacts
+// to the bot's newest message in one of its threads or a DM. Older
+// responses are ignored: /retry only knows the last prompt.
+func (p *Plugin) handleRetryReaction(ev messageEvent) {
+	if !ev.IsDM && !p.threads.owns(ev.ChannelID) {
+		return
+	}
+	s, ok := p.session.(messagePinner)
+	if !ok {
+		return
+	}
+	latest, err := p.latestBotMessage(s, ev.ChannelID)
+	if err != nil {
+		slog.Warn("discord read recent messages failed", "channel", ev.ChannelID, "error", err)
+		return
+	}
+	if latest != ev.MessageID {
+		return
+	}
+	p.deliverReaction(ev, "/retry")
+}
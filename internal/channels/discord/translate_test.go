@@ -121,6 +121,9 @@ func TestTranslate_ThreadLookupSetsParentID(t *testing.T) {
 	if ev.ParentID != "parent-ch" {
 		t.Fatalf("ParentID: got %q, want %q", ev.ParentID, "parent-ch")
 	}
+	if ev.GuildID != "guild-1" {
+		t.Fatalf("GuildID: got %q, want %q", ev.GuildID, "guild-1")
+	}
 }
 
 func TestTranslate_NonThreadChannel_NoParent(t *testing.T) {
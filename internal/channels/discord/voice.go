@@ -0,0 +1,217 @@
+package discord
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// utteranceGap is how long a speaker must be quiet before what they said
+// is transcribed. Discord stops sending packets when someone stops talking
+// or lets go of push-to-talk.
+var utteranceGap = 800 * time.Millisecond
+
+// maxUtteranceFrames cuts one utterance at 30 seconds.
+const maxUtteranceFrames = 1500
+
+// SpeechToText transcribes one utterance for voice mode.
+type SpeechToText interface {
+	Transcribe(ctx context.Context, audio []byte, name string) (string, error)
+}
+
+// VoiceConfig turns on voice mode: the bot sits muted in ChannelID,
+// transcribes what allowed users say and treats utterances starting with
+// WakeWord as prompts, replying in TextChannelID.
+type VoiceConfig struct {
+	GuildID       string
+	ChannelID     string
+	TextChannelID string
+	WakeWord      string
+	STT           SpeechToText
+}
+
+// StartVoice joins the voice channel and listens until ctx is done. The
+// returned func leaves the channel.
+func (p *Plugin) StartVoice(ctx context.Context, cfg VoiceConfig) (func(), error) {
+	dg, ok := p.session.(sessionAdapter)
+	if !ok {
+		return nil, errors.New("voice mode needs a discord session")
+	}
+	vc, err := dg.ChannelVoiceJoin(cfg.GuildID, cfg.ChannelID, true, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "joining voice channel")
+	}
+	l := newVoiceListener(p, cfg)
+	vc.AddHandler(func(_ *discordgo.VoiceConnection, vs *discordgo.VoiceSpeakingUpdate) {
+		l.speaking(uint32(vs.SSRC), vs.UserID)
+	})
+	go l.run(ctx, vc.OpusRecv)
+	return func() {
+		if err := vc.Disconnect(); err != nil {
+			slog.Warn("discord voice disconnect", "error", err)
+		}
+	}, nil
+}
+
+// voiceListener groups voice packets into utterances per speaker and
+// turns the ones addressed to the bot into inbounds.
+type voiceListener struct {
+	p   *Plugin
+	cfg VoiceConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	speakers map[uint32]string // SSRC to user ID
+	pending  map[uint32]*utterance
+}
+
+type utterance struct {
+	userID string
+	frames [][]byte
+	last   time.Time
+}
+
+func newVoiceListener(p *Plugin, cfg VoiceConfig) *voiceListener {
+	return &voiceListener{
+		p:        p,
+		cfg:      cfg,
+		now:      time.Now,
+		speakers: make(map[uint32]string),
+		pending:  make(map[uint32]*utterance),
+	}
+}
+
+func (l *voiceListener) speaking(ssrc uint32, userID string) {
+	l.mu.Lock()
+	l.speakers[ssrc] = userID
+	l.mu.Unlock()
+}
+
+// run collects packets and hands finished utterances to handle.
+func (l *voiceListener) run(ctx context.Context, packets <-chan *discordgo.Packet) {
+	tick := time.NewTicker(utteranceGap / 4)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case pkt, ok := <-packets:
+			if !ok {
+				return
+			}
+			if u := l.add(pkt); u != nil {
+				go l.handle(ctx, u)
+			}
+		case <-tick.C:
+			for _, u := range l.quiet() {
+				go l.handle(ctx, u)
+			}
+		}
+	}
+}
+
+// add appends pkt to its speaker's utterance, returning the utterance
+// when it has reached the length cap.
+func (l *voiceListener) add(pkt *discordgo.Packet) *utterance {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.pending[pkt.SSRC]
+	if u == nil {
+		u = &utterance{}
+		l.pending[pkt.SSRC] = u
+	}
+	if u.userID == "" {
+		u.userID = l.speakers[pkt.SSRC]
+	}
+	u.frames = append(u.frames, pkt.Opus)
+	u.last = l.now()
+	if len(u.frames) < maxUtteranceFrames {
+		return nil
+	}
+	delete(l.pending, pkt.SSRC)
+	return u
+}
+
+// quiet removes and returns utterances whose speaker has gone quiet.
+func (l *voiceListener) quiet() []*utterance {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var done []*utterance
+	for ssrc, u := range l.pending {
+		if l.now().Sub(u.last) >= utteranceGap {
+			if u.userID == "" {
+				// The speaking update may trail the first packets.
+				u.userID = l.speakers[ssrc]
+			}
+			done = append(done, u)
+			delete(l.pending, ssrc)
+		}
+	}
+	return done
+}
+
+// handle transcribes u and delivers it when it starts with the wake word.
+func (l *voiceListener) handle(ctx context.Context, u *utterance) {
+	defer core.Recover("discord voice", nil)
+	if u.userID == "" || !l.p.userAllowed(u.userID) {
+		return
+	}
+	text, err := l.cfg.STT.Transcribe(ctx, oggOpus(u.frames), "utterance.ogg")
+	if err != nil {
+		slog.Warn("discord voice transcription", "user", u.userID, "error", err)
+		return
+	}
+	prompt, ok := wakePrompt(text, l.cfg.WakeWord)
+	if !ok {
+		return
+	}
+
+	p := l.p
+	p.mu.Lock()
+	d := p.deliver
+	p.mu.Unlock()
+	if d == nil {
+		return
+	}
+	out := p.outbound(l.cfg.TextChannelID, "")
+	if err := out.PostResponse("🎙️ <@" + u.userID + ">: " + prompt); err != nil {
+		slog.Warn("discord voice transcript", "channel", l.cfg.TextChannelID, "error", err)
+	}
+	caps := p.Capabilities()
+	// There is no message to react to.
+	caps.Reactions = false
+	d(core.Inbound{
+		SessionKey:     core.SessionKey("discord:voice:" + l.cfg.ChannelID),
+		Text:           prompt,
+		UserID:         "discord:" + u.userID,
+		ChannelID:      "discord:" + l.cfg.TextChannelID,
+		GuildID:        guildID(l.cfg.GuildID),
+		Reply:          out,
+		Capabilities:   caps,
+		MaxResponseLen: p.cfg.MaxResponseLen,
+	})
+}
+
+// wakePrompt returns what follows wakeWord at the start of text, ignoring
+// case and the punctuation transcribers add ("Hey, Switchboard. Run the
+// tests."). ok is false when text doesn't start with it or says nothing
+// more.
+func wakePrompt(text, wakeWord string) (string, bool) {
+	want := strings.Fields(strings.ToLower(wakeWord))
+	words := strings.Fields(text)
+	if len(want) == 0 || len(words) <= len(want) {
+		return "", false
+	}
+	for i, w := range want {
+		if strings.ToLower(strings.Trim(words[i], `.,!?;:"'`)) != w {
+			return "", false
+		}
+	}
+	return strings.Join(words[len(want):], " "), true
+}
@@ -0,0 +1,113 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSTT struct {
+	text  string
+	audio []byte
+}
+
+func (s *stubSTT) Transcribe(_ context.Context, audio []byte, _ string) (string, error) {
+	s.audio = audio
+	return s.text, nil
+}
+
+func TestWakePrompt(t *testing.T) {
+	for _, tc := range []struct{ text, wake, want string }{
+		{"Switchboard, run the tests.", "switchboard", "run the tests."},
+		{"Hey, Switchboard. What broke?", "hey switchboard", "What broke?"},
+		{"switchboard", "switchboard", ""},
+		{"I told switchboard to run tests.", "switchboard", ""},
+	} {
+		got, ok := wakePrompt(tc.text, tc.wake)
+		assert.Equal(t, tc.want != "", ok, tc.text)
+		assert.Equal(t, tc.want, got, tc.text)
+	}
+}
+
+func TestVoiceListener_DeliversWakeWordUtterances(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an allowed user speaking two frames and then going quiet
+	s := &sessionFull{}
+	s.On("ChannelMessageSend", "text-1", "🎙️ <@user-1>: deploy staging").Return(nil).Once()
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+	stt := &stubSTT{text: "Switchboard, deploy staging"}
+	l := newVoiceListener(p, VoiceConfig{ChannelID: "voice-1", TextChannelID: "text-1", WakeWord: "switchboard", STT: stt})
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+	l.speaking(7, "user-1")
+	l.add(&discordgo.Packet{SSRC: 7, Opus: []byte{1, 2}})
+	l.add(&discordgo.Packet{SSRC: 7, Opus: []byte{3}})
+
+	// when
+	early := l.quiet()
+	now = now.Add(utteranceGap)
+	done := l.quiet()
+	r.Len(done, 1)
+	l.handle(context.Background(), done[0])
+
+	// then
+	// ... the transcript is echoed and the words after the wake word become a prompt
+	a.Empty(early)
+	r.Len(got, 1)
+	a.Equal(core.SessionKey("discord:voice:voice-1"), got[0].SessionKey)
+	a.Equal("deploy staging", got[0].Text)
+	a.Equal("discord:user-1", got[0].UserID)
+	a.False(got[0].Capabilities.Reactions)
+	a.True(bytes.HasPrefix(stt.audio, []byte("OggS")))
+	s.AssertExpectations(t)
+}
+
+func TestVoiceListener_IgnoresOthers(t *testing.T) {
+	// given
+	// ... a user who isn't allowed, and an allowed one who doesn't say the wake word
+	s := &sessionFull{}
+	var got []core.Inbound
+	p := newTestPlugin(s, "bot", []string{"user-1"}, func(in core.Inbound) { got = append(got, in) })
+	l := newVoiceListener(p, VoiceConfig{WakeWord: "switchboard", STT: &stubSTT{text: "switchboard, rm -rf"}})
+	chatter := newVoiceListener(p, VoiceConfig{WakeWord: "switchboard", STT: &stubSTT{text: "lunch?"}})
+
+	// when
+	l.handle(context.Background(), &utterance{userID: "stranger", frames: [][]byte{{1}}})
+	chatter.handle(context.Background(), &utterance{userID: "user-1", frames: [][]byte{{1}}})
+
+	// then
+	assert.Empty(t, got)
+	s.AssertNotCalled(t, "ChannelMessageSend", mock.Anything, mock.Anything)
+}
+
+func TestOggOpus(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	frames := [][]byte{bytes.Repeat([]byte{9}, 300), {1, 2, 3}}
+
+	// when
+	out := oggOpus(frames)
+
+	// then
+	// ... three pages: OpusHead, OpusTags and one audio page ending the stream
+	pages := bytes.Split(out, []byte("OggS"))[1:]
+	a.Len(pages, 3)
+	a.True(bytes.HasPrefix(pages[0][23+1:], []byte("OpusHead")))
+	audio := pages[2]
+	a.Equal(byte(0x04), audio[1])
+	a.Equal(uint64(2*opusFrameSamples), binary.LittleEndian.Uint64(audio[2:]))
+	a.Equal([]byte{3, 255, 45, 3}, audio[22:26])
+}
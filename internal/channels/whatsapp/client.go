@@ -35,6 +35,56 @@ func (c *ClientWrapper) SendText(chatJID, text string) error {
 	return errors.Wrap(err, "sending whatsapp message")
 }
 
+func (c *ClientWrapper) SendDocument(chatJID, fileName, mimeType string, data []byte) error {
+	jid, err := types.ParseJID(chatJID)
+	if err != nil {
+		return errors.Wrap(err, "parsing chat JID")
+	}
+	ctx := context.Background()
+	up, err := c.client.Upload(ctx, data, whatsmeow.MediaDocument)
+	if err != nil {
+		return errors.Wrap(err, "uploading whatsapp document")
+	}
+	msg := &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+		URL:           proto.String(up.URL),
+		DirectPath:    proto.String(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    proto.Uint64(up.FileLength),
+		Mimetype:      proto.String(mimeType),
+		FileName:      proto.String(fileName),
+		Title:         proto.String(fileName),
+	}}
+	_, err = c.client.SendMessage(ctx, jid, msg)
+	return errors.Wrap(err, "sending whatsapp document")
+}
+
+// SendVoice uploads Ogg Opus audio and sends it as a push-to-talk voice note.
+func (c *ClientWrapper) SendVoice(chatJID, mimeType string, data []byte) error {
+	jid, err := types.ParseJID(chatJID)
+	if err != nil {
+		return errors.Wrap(err, "parsing chat JID")
+	}
+	ctx := context.Background()
+	up, err := c.client.Upload(ctx, data, whatsmeow.MediaAudio)
+	if err != nil {
+		return errors.Wrap(err, "uploading whatsapp voice note")
+	}
+	msg := &waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+		URL:           proto.String(up.URL),
+		DirectPath:    proto.String(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    proto.Uint64(up.FileLength),
+		Mimetype:      proto.String(mimeType),
+		PTT:           proto.Bool(true),
+	}}
+	_, err = c.client.SendMessage(ctx, jid, msg)
+	return errors.Wrap(err, "sending whatsapp voice note")
+}
+
 func (c *ClientWrapper) SendTyping(chatJID string) error {
 	jid, err := types.ParseJID(chatJID)
 	if err != nil {
@@ -49,3 +99,8 @@ func (c *ClientWrapper) SendTyping(chatJID string) error {
 		"sending chat presence",
 	)
 }
+
+// SetStatusMessage sets the account's about text.
+func (c *ClientWrapper) SetStatusMessage(text string) error {
+	return errors.Wrap(c.client.SetStatusMessage(context.Background(), text), "setting whatsapp about")
+}
@@ -1,6 +1,11 @@
 package whatsapp
 
-import "github.com/TheLazyLemur/switchboard/internal/core"
+import (
+	"mime"
+	"path/filepath"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
 
 const maxMessageLen = 65536
 
@@ -33,3 +38,17 @@ func (r *Outbound) AddReaction(_ string) error { return nil }
 func (r *Outbound) SendUpdate(message string) error {
 	return r.client.SendText(r.chatJID, message)
 }
+
+// SendFile delivers content as a WhatsApp document.
+func (r *Outbound) SendFile(name string, content []byte) error {
+	mimeType := mime.TypeByExtension(filepath.Ext(name))
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	return r.client.SendDocument(r.chatJID, name, mimeType, content)
+}
+
+// SendVoice delivers audio as a WhatsApp voice note.
+func (r *Outbound) SendVoice(audio []byte, mimeType string) error {
+	return r.client.SendVoice(r.chatJID, mimeType, audio)
+}
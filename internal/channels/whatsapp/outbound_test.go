@@ -46,3 +46,31 @@ func TestOutbound_PostResponse_SplitsWhenOverMaxLen(t *testing.T) {
 	msgr.AssertCalled(t, "SendText", "chat-1@g.us", first)
 	msgr.AssertCalled(t, "SendText", "chat-1@g.us", second)
 }
+
+func TestOutbound_SendFile_SendsDocumentWithGuessedMIME(t *testing.T) {
+	// given
+	msgr := &messengerMock{}
+	out := NewOutbound(msgr, "chat-1@g.us")
+	msgr.On("SendDocument", "chat-1@g.us", "notes.unknownext", "text/plain", []byte("hi")).Return(nil).Once()
+
+	// when
+	err := out.SendFile("notes.unknownext", []byte("hi"))
+
+	// then
+	require.NoError(t, err)
+	msgr.AssertExpectations(t)
+}
+
+func TestOutbound_SendVoice_SendsVoiceNote(t *testing.T) {
+	// given
+	msgr := &messengerMock{}
+	out := NewOutbound(msgr, "chat-1@g.us")
+	msgr.On("SendVoice", "chat-1@g.us", "audio/ogg; codecs=opus", []byte("ogg")).Return(nil).Once()
+
+	// when
+	err := out.SendVoice([]byte("ogg"), "audio/ogg; codecs=opus")
+
+	// then
+	require.NoError(t, err)
+	msgr.AssertExpectations(t)
+}
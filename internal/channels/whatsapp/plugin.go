@@ -26,6 +26,11 @@ type Config struct {
 	Downloader     Downloader
 	AllowedSenders []string
 	MediaDir       string
+	// MaxResponseLen caps a final response before the bot condenses it.
+	// Zero disables the cap.
+	MaxResponseLen int
+	// Presence lets SetPresence change the account's about text.
+	Presence bool
 }
 
 // Plugin implements core.ChannelPlugin for WhatsApp.
@@ -34,12 +39,14 @@ type Plugin struct {
 	mu      sync.Mutex
 	deliver func(core.Inbound)
 	buffer  *core.DebouncedBuffer
-	now     func() time.Time
+	// seen drops messages whatsmeow delivers again, e.g. after a resync.
+	seen *core.Dedup
+	now  func() time.Time
 }
 
 // New constructs a Plugin from cfg.
 func New(cfg Config) *Plugin {
-	p := &Plugin{cfg: cfg, now: time.Now}
+	p := &Plugin{cfg: cfg, seen: core.NewDedup(core.DefaultDedupSize), now: time.Now}
 	p.buffer = core.NewDebouncedBuffer(DefaultBurstDelay, p.flush)
 	return p
 }
@@ -47,7 +54,29 @@ func New(cfg Config) *Plugin {
 func (p *Plugin) ID() string { return "whatsapp" }
 
 func (p *Plugin) Capabilities() core.Capabilities {
-	return core.Capabilities{Reactions: false, Media: true, Updates: true}
+	return core.Capabilities{Reactions: false, Media: true, Updates: true, Files: true, Voice: true}
+}
+
+// OpenChat returns an Outbound for a chat JID, for posts the bot makes
+// without an inbound. WhatsApp has no reactions, so messageID is unused.
+func (p *Plugin) OpenChat(chatID, _ string) core.Outbound {
+	return NewOutbound(p.cfg.Messenger, chatID)
+}
+
+// statusSetter is implemented by messengers that can change the account's
+// about text (ClientWrapper).
+type statusSetter interface {
+	SetStatusMessage(text string) error
+}
+
+// SetPresence shows pr as the account's about text when Config.Presence
+// is set. Messengers that can't set it ignore it.
+func (p *Plugin) SetPresence(pr core.Presence) error {
+	s, ok := p.cfg.Messenger.(statusSetter)
+	if !p.cfg.Presence || !ok {
+		return nil
+	}
+	return s.SetStatusMessage(pr.String())
 }
 
 func (p *Plugin) Start(ctx context.Context, deliver func(core.Inbound)) error {
@@ -78,6 +107,12 @@ func (p *Plugin) HandleEvent(evt interface{}) {
 		slog.Info("unauthorized whatsapp sender", "sender", senderJID, "alt", v.Info.SenderAlt.String())
 		return
 	}
+	defer core.Recover("whatsapp message", p.notifyPanic(chatJID))
+	// IDs are only unique per chat.
+	if !p.seen.First(chatJID + "/" + v.Info.ID) {
+		slog.Info("dropping redelivered whatsapp message", "chat", chatJID, "message", v.Info.ID)
+		return
+	}
 
 	caption, att, err := ExtractInbound(context.Background(), v, p.cfg.Downloader)
 	if err != nil {
@@ -101,6 +136,15 @@ func (p *Plugin) HandleEvent(evt interface{}) {
 	})
 }
 
+// notifyPanic tells chatJID that handling its message panicked.
+func (p *Plugin) notifyPanic(chatJID string) func(string) {
+	return func(text string) {
+		if err := NewOutbound(p.cfg.Messenger, chatJID).PostResponse(text); err != nil {
+			slog.Warn("whatsapp panic notice failed", "chat", chatJID, "error", err)
+		}
+	}
+}
+
 func (p *Plugin) isSenderAllowed(sender, senderAlt types.JID) bool {
 	for _, allowed := range p.cfg.AllowedSenders {
 		if sender.String() == allowed || senderAlt.String() == allowed {
@@ -146,11 +190,17 @@ func (p *Plugin) materializeAttachment(chatJID string, att *media.Attachment) ([
 }
 
 func (p *Plugin) flush(chatJID string, msgs []core.BufferedMessage) {
+	// flush runs on the buffer's timer goroutine, outside whatsmeow.
+	defer core.Recover("whatsapp batch", p.notifyPanic(chatJID))
 	if len(msgs) == 0 {
 		return
 	}
 
 	prompt := core.RenderWhatsAppBatch(msgs)
+	// Commands must reach the bot verbatim, not wrapped in <message> tags.
+	if len(msgs) == 1 && core.IsControlMessage(msgs[0].Content) {
+		prompt = msgs[0].Content
+	}
 	if prompt == "" {
 		return
 	}
@@ -172,11 +222,14 @@ func (p *Plugin) flush(chatJID string, msgs []core.BufferedMessage) {
 
 	out := NewOutbound(p.cfg.Messenger, chatJID)
 	d(core.Inbound{
-		SessionKey:   SessionKey(chatJID),
-		Text:         prompt,
-		Attachments:  atts,
-		Reply:        out,
-		Capabilities: p.Capabilities(),
+		SessionKey:     SessionKey(chatJID),
+		Text:           prompt,
+		UserID:         "whatsapp:" + msgs[len(msgs)-1].AuthorID,
+		ChannelID:      "whatsapp:" + chatJID,
+		Attachments:    atts,
+		Reply:          out,
+		Capabilities:   p.Capabilities(),
+		MaxResponseLen: p.cfg.MaxResponseLen,
 	})
 }
 
@@ -2,8 +2,10 @@ package whatsapp
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,6 +33,15 @@ func (m *messengerMock) SendTyping(jid string) error {
 	args := m.Called(jid)
 	return args.Error(0)
 }
+func (m *messengerMock) SendDocument(jid, name, mimeType string, data []byte) error {
+	args := m.Called(jid, name, mimeType, data)
+	return args.Error(0)
+}
+
+func (m *messengerMock) SendVoice(jid, mimeType string, data []byte) error {
+	args := m.Called(jid, mimeType, data)
+	return args.Error(0)
+}
 
 type downloaderMock struct{ mock.Mock }
 
@@ -102,9 +113,18 @@ func makeWAMessage(text string) *waE2E.Message {
 	return &waE2E.Message{Conversation: proto.String(text)}
 }
 
+// messageSeq gives every test event its own ID, as WhatsApp does; the
+// plugin drops an ID it has already seen.
+var messageSeq atomic.Int64
+
+func nextMessageID() string {
+	return "msg-" + strconv.FormatInt(messageSeq.Add(1), 10)
+}
+
 func makeMessageEvent(senderJID, chatJID, text string) *events.Message {
 	return &events.Message{
 		Info: types.MessageInfo{
+			ID: nextMessageID(),
 			MessageSource: types.MessageSource{
 				Sender: parseJID(senderJID),
 				Chat:   parseJID(chatJID),
@@ -117,6 +137,7 @@ func makeMessageEvent(senderJID, chatJID, text string) *events.Message {
 func makeMessageEventWithAlt(senderJID, altJID, chatJID, text string) *events.Message {
 	return &events.Message{
 		Info: types.MessageInfo{
+			ID: nextMessageID(),
 			MessageSource: types.MessageSource{
 				Sender:    parseJID(senderJID),
 				SenderAlt: parseJID(altJID),
@@ -136,6 +157,7 @@ func makeImageMessageEvent(senderJID, chatJID, caption, mime string) *events.Mes
 	}
 	return &events.Message{
 		Info: types.MessageInfo{
+			ID: nextMessageID(),
 			MessageSource: types.MessageSource{
 				Sender: parseJID(senderJID),
 				Chat:   parseJID(chatJID),
@@ -209,6 +231,27 @@ func TestPlugin_ID(t *testing.T) {
 
 // --- HandleEvent tests ---
 
+func TestPlugin_RedeliveredMessage_Dropped(t *testing.T) {
+	r := require.New(t)
+
+	// given
+	// ... a message whatsmeow delivers twice
+	msgr := &messengerMock{}
+	dl := &downloaderMock{}
+	p, sink := newTestPlugin(t, msgr, dl, []string{"sender-1@s.whatsapp.net"})
+	evt := makeMessageEvent("sender-1@s.whatsapp.net", "chat-1@g.us", "hello")
+
+	// when
+	p.HandleEvent(evt)
+	p.HandleEvent(evt)
+	time.Sleep(testBurstDelay + 200*time.Millisecond)
+
+	// then
+	// ... one inbound carries the text once
+	r.Equal(1, sink.count())
+	r.Equal(1, strings.Count(sink.at(0).Text, "hello"))
+}
+
 func TestPlugin_Inbound_CapabilitiesMatchPluginCapabilities(t *testing.T) {
 	r := require.New(t)
 
@@ -255,6 +298,27 @@ func TestPlugin_AllowedSender_DeliversInbound(t *testing.T) {
 	a.Equal(SessionKey("chat-1@g.us"), sink.at(0).SessionKey)
 }
 
+func TestPlugin_ControlMessage_DeliveredVerbatim(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a plugin with one allowed sender
+	msgr := &messengerMock{}
+	dl := &downloaderMock{}
+	p, sink := newTestPlugin(t, msgr, dl, []string{"sender-1@s.whatsapp.net"})
+
+	// when
+	// ... the sender sends a bot command on its own
+	p.HandleEvent(makeMessageEvent("sender-1@s.whatsapp.net", "chat-1@g.us", "!begin"))
+	time.Sleep(testBurstDelay + 200*time.Millisecond)
+
+	// then
+	// ... the command is not wrapped in <message> tags
+	r.Equal(1, sink.count())
+	a.Equal("!begin", sink.at(0).Text)
+}
+
 func TestPlugin_DisallowedSender_Ignored(t *testing.T) {
 	// given
 	// ... a plugin with a different allowed sender
@@ -0,0 +1,102 @@
+// Package codesearch keeps an embedding index of a working directory for
+// the code_search tool.
+package codesearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultBaseURL = "https://api.openai.com"
+	DefaultModel   = "text-embedding-3-small"
+)
+
+// Embedder turns texts into vectors, one per text in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OpenAI calls an OpenAI-compatible /v1/embeddings endpoint, which local
+// servers (Ollama, LM Studio, llama.cpp) also expose.
+type OpenAI struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Client  *http.Client
+}
+
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	return &OpenAI{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+		Client:  &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": o.Model, "input": texts})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding embedding request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+"/v1/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating embedding request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting embeddings")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading embeddings")
+	}
+	if resp.StatusCode >= 400 {
+		msg := string(respBody)
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return nil, errors.Errorf("embedding endpoint returned %d: %s", resp.StatusCode, msg)
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, errors.Wrap(err, "decoding embeddings")
+	}
+	if len(out.Data) != len(texts) {
+		return nil, errors.Errorf("embedding endpoint returned %d vectors for %d inputs", len(out.Data), len(texts))
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, errors.Errorf("embedding index %d out of range", d.Index)
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	return vecs, nil
+}
@@ -0,0 +1,51 @@
+package codesearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAI_EmbedOrdersByIndex(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an endpoint that returns vectors out of order
+	var gotAuth string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotAuth = req.Header.Get("Authorization")
+		_ = json.NewDecoder(req.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer srv.Close()
+
+	// when
+	vecs, err := NewOpenAI(srv.URL, "sk-test", "").Embed(context.Background(), []string{"a", "b"})
+
+	// then
+	r.NoError(err)
+	a.Equal([][]float32{{1, 0}, {0, 1}}, vecs)
+	a.Equal("Bearer sk-test", gotAuth)
+	a.Equal(DefaultModel, gotBody["model"])
+}
+
+func TestOpenAI_EmbedErrorStatus(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "bad key", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	// when
+	_, err := NewOpenAI(srv.URL, "", "").Embed(context.Background(), []string{"a"})
+
+	// then
+	assert.ErrorContains(t, err, "401")
+}
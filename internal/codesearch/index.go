@@ -0,0 +1,313 @@
+package codesearch
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	chunkLines    = 50
+	maxChunkBytes = 4000
+	maxFileBytes  = 256 * 1024
+	maxIndexFiles = 5000
+	embedBatch    = 64
+)
+
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true,
+	"target": true, "__pycache__": true, "venv": true,
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS files (
+	root  TEXT NOT NULL,
+	path  TEXT NOT NULL,
+	mtime INTEGER NOT NULL,
+	size  INTEGER NOT NULL,
+	PRIMARY KEY (root, path)
+);
+CREATE TABLE IF NOT EXISTS chunks (
+	root       TEXT NOT NULL,
+	path       TEXT NOT NULL,
+	start_line INTEGER NOT NULL,
+	end_line   INTEGER NOT NULL,
+	content    TEXT NOT NULL,
+	vector     BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS chunks_file ON chunks (root, path);
+`
+
+// Result is one matching chunk. Path is relative to the searched root.
+type Result struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Score     float64
+	Content   string
+}
+
+// Index stores chunk embeddings for any number of roots in one SQLite
+// database. Files are re-embedded when their size or mtime changes.
+type Index struct {
+	mu  sync.Mutex
+	db  *sql.DB
+	emb Embedder
+}
+
+// Open opens or creates the index database at path. The sqlite driver must
+// be registered by the caller.
+func Open(path string, emb Embedder) (*Index, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening code index")
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "creating code index schema")
+	}
+	return &Index{db: db, emb: emb}, nil
+}
+
+func (x *Index) Close() error {
+	return x.db.Close()
+}
+
+// Search brings root's index up to date and returns the limit chunks most
+// similar to query.
+func (x *Index) Search(ctx context.Context, root, query string, limit int) ([]Result, error) {
+	root = filepath.Clean(root)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.update(ctx, root); err != nil {
+		return nil, err
+	}
+	qv, err := x.emb.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := x.db.QueryContext(ctx, `SELECT path, start_line, end_line, content, vector FROM chunks WHERE root = ?`, root)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading code index")
+	}
+	defer rows.Close()
+	var results []Result
+	for rows.Next() {
+		var r Result
+		var blob []byte
+		if err := rows.Scan(&r.Path, &r.StartLine, &r.EndLine, &r.Content, &blob); err != nil {
+			return nil, errors.Wrap(err, "reading code index")
+		}
+		r.Score = cosine(qv[0], decodeVector(blob))
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading code index")
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+type fileStamp struct {
+	mtime, size int64
+}
+
+// update re-embeds files under root that are new or changed since the last
+// search and drops files that are gone.
+func (x *Index) update(ctx context.Context, root string) error {
+	indexed := map[string]fileStamp{}
+	rows, err := x.db.QueryContext(ctx, `SELECT path, mtime, size FROM files WHERE root = ?`, root)
+	if err != nil {
+		return errors.Wrap(err, "reading code index")
+	}
+	for rows.Next() {
+		var p string
+		var s fileStamp
+		if err := rows.Scan(&p, &s.mtime, &s.size); err != nil {
+			rows.Close()
+			return errors.Wrap(err, "reading code index")
+		}
+		indexed[p] = s
+	}
+	rows.Close()
+
+	seen := map[string]bool{}
+	var changed []string
+	stamps := map[string]fileStamp{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || !d.Type().IsRegular() || len(seen) >= maxIndexFiles {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() == 0 || info.Size() > maxFileBytes {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+		s := fileStamp{mtime: info.ModTime().UnixNano(), size: info.Size()}
+		if indexed[rel] != s {
+			changed = append(changed, rel)
+			stamps[rel] = s
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "walking workdir")
+	}
+
+	for p := range indexed {
+		if !seen[p] {
+			if err := x.forget(ctx, root, p); err != nil {
+				return err
+			}
+		}
+	}
+	for _, p := range changed {
+		if err := x.indexFile(ctx, root, p, stamps[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *Index) forget(ctx context.Context, root, path string) error {
+	if _, err := x.db.ExecContext(ctx, `DELETE FROM chunks WHERE root = ? AND path = ?`, root, path); err != nil {
+		return errors.Wrap(err, "updating code index")
+	}
+	_, err := x.db.ExecContext(ctx, `DELETE FROM files WHERE root = ? AND path = ?`, root, path)
+	return errors.Wrap(err, "updating code index")
+}
+
+type chunk struct {
+	start, end int
+	text       string
+}
+
+func (x *Index) indexFile(ctx context.Context, root, rel string, stamp fileStamp) error {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	var chunks []chunk
+	if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+		chunks = splitChunks(string(data))
+	}
+
+	var vecs [][]float32
+	for i := 0; i < len(chunks); i += embedBatch {
+		batch := chunks[i:min(i+embedBatch, len(chunks))]
+		texts := make([]string, len(batch))
+		for j, c := range batch {
+			texts[j] = rel + "\n" + c.text
+		}
+		v, err := x.emb.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		vecs = append(vecs, v...)
+	}
+
+	tx, err := x.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "updating code index")
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks WHERE root = ? AND path = ?`, root, rel); err != nil {
+		return errors.Wrap(err, "updating code index")
+	}
+	for i, c := range chunks {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO chunks (root, path, start_line, end_line, content, vector) VALUES (?, ?, ?, ?, ?, ?)`,
+			root, rel, c.start, c.end, c.text, encodeVector(vecs[i])); err != nil {
+			return errors.Wrap(err, "updating code index")
+		}
+	}
+	// Binary files are recorded without chunks so they aren't re-read.
+	if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO files (root, path, mtime, size) VALUES (?, ?, ?, ?)`,
+		root, rel, stamp.mtime, stamp.size); err != nil {
+		return errors.Wrap(err, "updating code index")
+	}
+	return errors.Wrap(tx.Commit(), "updating code index")
+}
+
+// splitChunks cuts text into chunkLines-line windows, skipping blank ones
+// and capping each at maxChunkBytes.
+func splitChunks(text string) []chunk {
+	lines := strings.Split(text, "\n")
+	var out []chunk
+	for start := 0; start < len(lines); start += chunkLines {
+		end := min(start+chunkLines, len(lines))
+		body := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+		if len(body) > maxChunkBytes {
+			cut := maxChunkBytes
+			for cut > 0 && !utf8.RuneStart(body[cut]) {
+				cut--
+			}
+			body = body[:cut]
+		}
+		out = append(out, chunk{start: start + 1, end: end, text: body})
+	}
+	return out
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
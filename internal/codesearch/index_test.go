@@ -0,0 +1,99 @@
+package codesearch
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+// wordEmbedder hashes words into a small bag-of-words vector and counts
+// the texts it embeds.
+type wordEmbedder struct {
+	embedded int
+}
+
+func (w *wordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		v := make([]float32, 64)
+		for _, word := range strings.Fields(strings.ToLower(t)) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			v[h.Sum32()%64]++
+		}
+		out[i] = v
+	}
+	w.embedded += len(texts)
+	return out, nil
+}
+
+func TestIndex_SearchRanksAndUpdatesIncrementally(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a root with two files and an index over it
+	root := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(root, "retry.go"), []byte("retry backoff retry attempts"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(root, "auth.go"), []byte("login password token"), 0o644))
+	emb := &wordEmbedder{}
+	idx, err := Open(filepath.Join(t.TempDir(), "index.db"), emb)
+	r.NoError(err)
+	defer idx.Close()
+	ctx := context.Background()
+
+	// when
+	// ... it is searched, then searched again unchanged
+	first, err := idx.Search(ctx, root, "retry backoff", 1)
+	r.NoError(err)
+	afterFirst := emb.embedded
+	_, err = idx.Search(ctx, root, "retry backoff", 1)
+	r.NoError(err)
+
+	// then
+	// ... the best chunk is the retry file and unchanged files aren't re-embedded
+	r.Len(first, 1)
+	a.Equal("retry.go", first[0].Path)
+	a.Equal(1, first[0].StartLine)
+	a.Equal(3, afterFirst, "two chunks and the query")
+	a.Equal(afterFirst+1, emb.embedded, "only the query")
+
+	// when
+	// ... one file changes and the other is deleted
+	later := time.Now().Add(time.Minute)
+	r.NoError(os.WriteFile(filepath.Join(root, "auth.go"), []byte("login password token retry"), 0o644))
+	r.NoError(os.Chtimes(filepath.Join(root, "auth.go"), later, later))
+	r.NoError(os.Remove(filepath.Join(root, "retry.go")))
+	before := emb.embedded
+	results, err := idx.Search(ctx, root, "retry backoff", 5)
+
+	// then
+	// ... only the changed file is re-embedded and the deleted one is gone
+	r.NoError(err)
+	a.Equal(before+2, emb.embedded)
+	r.Len(results, 1)
+	a.Equal("auth.go", results[0].Path)
+}
+
+func TestSplitChunks_SkipsBlankWindows(t *testing.T) {
+	// given
+	// ... a blank first window followed by code
+	text := strings.Repeat("\n", chunkLines) + "func main() {}\n"
+
+	// when
+	chunks := splitChunks(text)
+
+	// then
+	require.Len(t, chunks, 1)
+	assert.Equal(t, chunkLines+1, chunks[0].start)
+	assert.Contains(t, chunks[0].text, "func main")
+}
@@ -1,12 +1,19 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/TheLazyLemur/switchboard/internal/faults"
+	"github.com/TheLazyLemur/switchboard/internal/version"
 	"github.com/pkg/errors"
 )
 
@@ -15,9 +22,21 @@ const (
 	DefaultKimiModel = "Kimi-for-Coding"
 )
 
+// Workspace is a labelled working directory sessions can start in.
+type Workspace struct {
+	Label   string
+	Path    string
+	Default bool
+	// ReadOnly sessions started here begin in read-only mode.
+	ReadOnly bool
+}
+
 type Config struct {
 	DiscordToken string
 	AllowedDirs  []string
+	// Workspaces from WORKSPACES, or one per ALLOWED_DIRS entry. Exactly
+	// one is Default.
+	Workspaces   []Workspace
 	AllowedUsers []string
 	AgentCWD     string
 	WebhookPort  string
@@ -30,6 +49,10 @@ type Config struct {
 	ResendAPIKey string
 	// Optional password for dashboard auth
 	DashboardPassword string
+	// Optional password for read-only dashboard viewers
+	ViewerPassword string
+	// Optional bearer token for the dashboard REST API
+	DashboardToken string
 	// API key for the WebSearch tool (Brave Search API)
 	WebSearchAPIKey string
 
@@ -44,11 +67,27 @@ type Config struct {
 	// is enabled. Must live under one of AllowedDirs.
 	WhatsAppMediaDir string
 
+	// Final responses longer than these are condensed by the backend before
+	// posting. Zero disables the cap for that platform.
+	DiscordMaxResponseLen  int
+	WhatsAppMaxResponseLen int
+
+	// How many sessions may run turns at the same time. Zero uses
+	// core.DefaultMaxConcurrentSessions.
+	MaxConcurrentSessions int
+
 	// Directory Discord attachments are saved to. Defaults to
 	// <first AllowedDirs>/discord-media when Discord is enabled. Must live
 	// under AllowedDirs.
 	DiscordMediaDir string
 
+	// Discord cleanup: threads the bot opened are archived this many minutes
+	// after its last response (0 leaves it to Discord), and the bot's
+	// messages and threads in each listed channel are deleted once older
+	// than its number of days.
+	DiscordArchiveMinutes int
+	DiscordPruneDays      map[string]int
+
 	// Directory the memory skill stores MEMORY.md and daily logs in. Defaults
 	// to <first AllowedDirs>/switchboard-memory. Must live under AllowedDirs.
 	MemoryDir string
@@ -61,10 +100,273 @@ type Config struct {
 	// Anthropic Messages request includes thinking={type:enabled,budget_tokens:N}.
 	// Anthropic requires N >= 1024.
 	ThinkingBudgetTokens int
+
+	// TurnTokenLimit holds a message for /confirm when its first API call
+	// would send more than this many input tokens. 0 disables the guard.
+	TurnTokenLimit int
+
+	// TurnCacheMinutes answers a prompt repeated in the same channel within
+	// this many minutes with the earlier answer. 0 disables the cache.
+	TurnCacheMinutes int
+
+	// ToolOutputBudgetTokens is how much tool output one turn feeds back to
+	// the model before results are compacted harder
+	// (TOOL_OUTPUT_BUDGET_TOKENS, default DefaultToolOutputBudgetTokens).
+	// 0 disables the budget.
+	ToolOutputBudgetTokens int
+
+	// Environment variable names passed through to (allowlist) or stripped
+	// from (denylist) Bash tool processes. An empty allowlist passes
+	// everything not denied.
+	ToolEnvAllowlist []string
+	ToolEnvDenylist  []string
+
+	// Optional YAML file of outbound content rules (mask/block regexes,
+	// sensitive file globs). When empty, policy.DefaultFile applies.
+	ContentPolicyPath string
+
+	// Optional directory of script tools: <name>.json manifests next to
+	// executables called <name>. Empty disables script tools.
+	ScriptToolsDir string
+	// PreToolHook and PostToolHook are shell commands run around every
+	// tool call; a failing PreToolHook vetoes the call.
+	PreToolHook  string
+	PostToolHook string
+
+	// Databases the sql_query tool can read, from SQL_DATABASES.
+	SQLDatabases []SQLDatabase
+
+	// Kubeconfig contexts the kube_* tools may use, each mapped to its
+	// allowed namespaces ("*" for any). From KUBE_CONTEXTS.
+	KubeContexts map[string][]string
+	// KubeAllowWrites registers kube_apply and kube_delete (KUBE_ALLOW_WRITES=1).
+	KubeAllowWrites bool
+
+	// BrowseEnabled registers the headless-Chrome browse tool (BROWSE_ENABLED=1).
+	BrowseEnabled bool
+	// Hosts besides localhost the browse tool may open.
+	BrowseAllowedHosts []string
+
+	// RepoMapEnabled registers the repo_map tool (REPO_MAP_ENABLED=1).
+	RepoMapEnabled bool
+
+	// CloneRoot enables /clone, which clones repositories under it; it is
+	// added to AllowedDirs. Only https URLs on CloneAllowedHosts (default
+	// github.com) up to CloneMaxMB (default 500) are cloned.
+	CloneRoot         string
+	CloneAllowedHosts []string
+	CloneMaxMB        int
+
+	// ScratchTTLMinutes bounds how long a /scratch directory lives; it is
+	// also removed as soon as its session ends.
+	ScratchTTLMinutes int
+
+	// SessionBranches gives each session in a clean git work tree its own
+	// branch for /apply and /discard (SESSION_BRANCHES=1).
+	SessionBranches bool
+
+	// VerifyTrustedDirs are the directories whose .switchboard.yaml verify
+	// commands run after edits (VERIFY_TRUSTED_DIRS). Unset, none do, so a
+	// cloned repository can't run commands on the host.
+	VerifyTrustedDirs []string
+
+	// Language is the reply language for chats where nobody has picked one
+	// with /language: en, es, de or af (LANGUAGE, default en).
+	Language string
+
+	// EgressProxy routes Fetch and WebSearch through an http, https or
+	// socks5 proxy (EGRESS_PROXY). EgressAllowedDomains, when set, are the
+	// only domains they may reach; EgressDeniedDomains are never reachable.
+	// A domain covers its subdomains.
+	EgressProxy          string
+	EgressAllowedDomains []string
+	EgressDeniedDomains  []string
+	// FetchAllowedNetworks are CIDRs Fetch may reach although they are
+	// loopback, private or link-local (FETCH_ALLOWED_NETWORKS); all other
+	// internal addresses are refused.
+	FetchAllowedNetworks []string
+
+	// WebCacheDir keeps Fetch and WebSearch results for WebCacheTTLMinutes
+	// (WEB_CACHE_DIR, WEB_CACHE_TTL_MINUTES, default 60); empty disables
+	// the cache.
+	WebCacheDir        string
+	WebCacheTTLMinutes int
+
+	// APIRecordDir gets a JSONL recording of every session's messages and
+	// API responses for `switchboard replay` (API_RECORD_DIR); empty
+	// disables recording.
+	APIRecordDir string
+
+	// PersonasDir holds <name>.md personas for /persona (PERSONAS_DIR);
+	// empty disables the command.
+	PersonasDir string
+
+	// Text-to-speech for /speak. TTSProvider is "openai" (any
+	// OpenAI-compatible /v1/audio/speech endpoint) or empty to disable.
+	TTSProvider string
+	TTSAPIKey   string
+	TTSBaseURL  string
+	TTSModel    string
+	TTSVoice    string
+
+	// Speech-to-text for Discord voice mode. STTProvider is "openai" (any
+	// OpenAI-compatible /v1/audio/transcriptions endpoint) or empty.
+	STTProvider string
+	STTAPIKey   string
+	STTBaseURL  string
+	STTModel    string
+
+	// Discord voice mode: the bot listens in VoiceChannel of VoiceGuild and
+	// answers prompts starting with VoiceWakeWord in VoiceThread, a text
+	// channel or thread. Empty VoiceChannel disables it.
+	VoiceGuild    string
+	VoiceChannel  string
+	VoiceThread   string
+	VoiceWakeWord string
+
+	// Image generation for generate_image. ImageProvider is "openai",
+	// "sdwebui" or empty to disable. ImageDailyLimit caps generations per
+	// UTC day (0 is unlimited).
+	ImageProvider   string
+	ImageAPIKey     string
+	ImageBaseURL    string
+	ImageModel      string
+	ImageDailyLimit int
+
+	// ShareBaseURL is the public address of the dashboard server; setting it
+	// enables /share. Shared pages expire after ShareTTLHours.
+	ShareBaseURL  string
+	ShareTTLHours int
+
+	// Embeddings for code_search. EmbeddingProvider is "openai" (any
+	// OpenAI-compatible /v1/embeddings endpoint) or empty to disable.
+	// CodeSearchIndex is the SQLite file the index is kept in.
+	EmbeddingProvider string
+	EmbeddingAPIKey   string
+	EmbeddingBaseURL  string
+	EmbeddingModel    string
+	CodeSearchIndex   string
+
+	// MetricsDB is the SQLite file per-turn usage is recorded in for the
+	// dashboard analytics view.
+	MetricsDB string
+
+	// GuildSettingsDB is the SQLite file per-server /config settings are
+	// kept in.
+	GuildSettingsDB string
+
+	// TenantsFile is an optional YAML list of tenants (LoadTenants), each
+	// serving its own servers with its own API key and directories.
+	TenantsFile string
+
+	// HooksFile is an optional Starlark script of message, tool call and
+	// reply hooks.
+	HooksFile string
+
+	// Faults are failures injected on purpose to test retries and
+	// reconnects (FAULT_INJECTION); never set in production.
+	Faults faults.Config
+
+	// UpdateCheck is how far behind the latest release the build must be
+	// before the bot warns (UPDATE_CHECK: off, patch, minor or major).
+	UpdateCheck version.Level
+
+	// MCPServerToken enables the /mcp endpoint other local agents message
+	// chats through; MCPAllowedChats are the "channel:chatID" chats it may
+	// reach.
+	MCPServerToken  string
+	MCPAllowedChats []string
+
+	// AdminUsers are the "platform:userID" users, as the bot sees them
+	// (e.g. discord:123), who may run /broadcast.
+	AdminUsers []string
+
+	// WebhookOutURL receives every reply, progress update, reaction and
+	// refused tool call as a JSON POST, signed with WebhookOutSecret
+	// (HMAC-SHA256) when that is set.
+	WebhookOutURL    string
+	WebhookOutSecret string
+
+	// BatchEnabled turns on /batch, which sends a prompt through the
+	// Message Batches API at half price and posts the answer when ready
+	// (BATCH_ENABLED=1).
+	BatchEnabled bool
+
+	// FeedbackReactions offers 👍/👎 under each final response on Discord
+	// and records clicks as ratings (FEEDBACK_REACTIONS=1). /feedback works
+	// without it.
+	FeedbackReactions bool
+
+	// WhatsAppPresence keeps the WhatsApp account's about text on the
+	// bot's state, e.g. "Working on 2 tasks" (WHATSAPP_PRESENCE=1). Off by
+	// default since the account is often a personal one.
+	WhatsAppPresence bool
+}
+
+// SQLDatabase is one SQL_DATABASES entry: name=driver:dsn.
+type SQLDatabase struct {
+	Name   string
+	Driver string
+	DSN    string
 }
 
+// sqlDrivers are the database/sql driver names registered by main.
+var sqlDrivers = map[string]bool{"postgres": true, "mysql": true, "sqlite": true}
+
 const minThinkingBudgetTokens = 1024
 
+// DefaultVoiceWakeWord starts a prompt in Discord voice mode when
+// VOICE_WAKE_WORD is unset.
+const DefaultVoiceWakeWord = "switchboard"
+
+// DefaultToolOutputBudgetTokens is the per-turn tool output budget when
+// TOOL_OUTPUT_BUDGET_TOKENS is unset: about four full-size results.
+const DefaultToolOutputBudgetTokens = 50000
+
+// DefaultImageDailyLimit caps generate_image when IMAGE_DAILY_LIMIT is unset.
+const DefaultImageDailyLimit = 20
+
+// DefaultShareTTLHours is how long /share pages live when SHARE_TTL_HOURS
+// is unset.
+const DefaultShareTTLHours = 7 * 24
+
+// Default response caps: four Discord messages, a few phone screens on WhatsApp.
+const (
+	DefaultDiscordMaxResponseLen  = 8000
+	DefaultWhatsAppMaxResponseLen = 4000
+)
+
+// DefaultCloneMaxMB caps a /clone checkout when CLONE_MAX_MB is unset.
+const DefaultCloneMaxMB = 500
+
+// DefaultWebCacheTTLMinutes is how long cached Fetch and WebSearch results
+// are reused when WEB_CACHE_TTL_MINUTES is unset.
+const DefaultWebCacheTTLMinutes = 60
+
+// DefaultScratchTTLMinutes is how long /scratch directories live when
+// SCRATCH_TTL_MINUTES is unset.
+const DefaultScratchTTLMinutes = 60
+
+// DefaultToolEnvDenylist is used when TOOL_ENV_DENYLIST is unset. It covers
+// every variable behind Secrets except RESEND_API_KEY, which is
+// deliberately absent because the email skill scripts read it.
+var DefaultToolEnvDenylist = []string{
+	"DISCORD_TOKEN",
+	"SWITCHBOARD_API_KEY",
+	"CLAUDECORD_API_KEY",
+	"DASHBOARD_PASSWORD",
+	"DASHBOARD_VIEWER_PASSWORD",
+	"DASHBOARD_API_TOKEN",
+	"MCP_SERVER_TOKEN",
+	"WEBHOOK_OUT_SECRET",
+	"WEB_SEARCH_API_KEY",
+	"SQL_DATABASES",
+	"TTS_API_KEY",
+	"STT_API_KEY",
+	"IMAGE_API_KEY",
+	"EMBEDDING_API_KEY",
+}
+
 func (c *Config) DiscordEnabled() bool {
 	return c.DiscordToken != ""
 }
@@ -73,6 +375,21 @@ func (c *Config) WhatsAppEnabled() bool {
 	return len(c.WhatsAppAllowedSenders) > 0
 }
 
+// Secrets returns the configured credential values that must never appear in
+// logs or chat output. Unset values are omitted.
+func (c *Config) Secrets() []string {
+	var out []string
+	for _, s := range []string{c.DiscordToken, c.APIKey, c.ResendAPIKey, c.DashboardPassword, c.ViewerPassword, c.DashboardToken, c.MCPServerToken, c.WebhookOutSecret, c.WebSearchAPIKey, c.TTSAPIKey, c.STTAPIKey, c.ImageAPIKey, c.EmbeddingAPIKey} {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	for _, db := range c.SQLDatabases {
+		out = append(out, db.DSN)
+	}
+	return out
+}
+
 // Load reads config from env map. For production use LoadFromEnv.
 func Load(env map[string]string) (*Config, error) {
 	discordToken := env["DISCORD_TOKEN"]
@@ -93,11 +410,57 @@ func Load(env map[string]string) (*Config, error) {
 		return nil, errors.New("at least one platform required: set DISCORD_TOKEN or WHATSAPP_ALLOWED_SENDERS")
 	}
 
-	allowedDirsStr := env["ALLOWED_DIRS"]
-	if allowedDirsStr == "" {
-		return nil, errors.New("ALLOWED_DIRS required")
+	var allowedDirs []string
+	if s := env["ALLOWED_DIRS"]; s != "" {
+		allowedDirs = splitAndTrim(s)
+	}
+	workspaces, err := parseWorkspaces(env["WORKSPACES"])
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		if len(allowedDirs) == 0 {
+			return nil, errors.New("ALLOWED_DIRS required (or set WORKSPACES)")
+		}
+		workspaces = dirWorkspaces(allowedDirs)
+	}
+	for _, w := range workspaces {
+		if !slices.Contains(allowedDirs, w.Path) {
+			allowedDirs = append(allowedDirs, w.Path)
+		}
+	}
+	cloneRoot := env["CLONE_ROOT"]
+	if cloneRoot != "" {
+		if !filepath.IsAbs(cloneRoot) {
+			return nil, errors.Errorf("CLONE_ROOT %q must be an absolute path", cloneRoot)
+		}
+		cloneRoot = filepath.Clean(cloneRoot)
+		if !pathInsideAllowedDirs(cloneRoot, allowedDirs) {
+			allowedDirs = append(allowedDirs, cloneRoot)
+		}
+	}
+	cloneHosts := []string{"github.com"}
+	if s := env["CLONE_ALLOWED_HOSTS"]; s != "" {
+		cloneHosts = splitAndTrim(s)
+	}
+	cloneMaxMB, err := intOrDefault(env, "CLONE_MAX_MB", DefaultCloneMaxMB)
+	if err != nil {
+		return nil, err
+	}
+	scratchTTL, err := intOrDefault(env, "SCRATCH_TTL_MINUTES", DefaultScratchTTLMinutes)
+	if err != nil {
+		return nil, err
+	}
+	if scratchTTL == 0 {
+		return nil, errors.New("SCRATCH_TTL_MINUTES must be positive")
+	}
+	webCacheTTL, err := intOrDefault(env, "WEB_CACHE_TTL_MINUTES", DefaultWebCacheTTLMinutes)
+	if err != nil {
+		return nil, err
+	}
+	if webCacheTTL == 0 {
+		return nil, errors.New("WEB_CACHE_TTL_MINUTES must be positive")
 	}
-	allowedDirs := splitAndTrim(allowedDirsStr)
 
 	// Discord requires ALLOWED_USERS with numeric IDs
 	var allowedUsers []string
@@ -116,7 +479,7 @@ func Load(env map[string]string) (*Config, error) {
 
 	agentCwd := envOrLegacy(env, "AGENT_CWD", "CLAUDE_CWD")
 	if agentCwd == "" {
-		agentCwd = allowedDirs[0]
+		agentCwd = defaultWorkspace(workspaces).Path
 	}
 
 	webhookPort := env["WEBHOOK_PORT"]
@@ -182,6 +545,194 @@ func Load(env map[string]string) (*Config, error) {
 		thinkingBudget = n
 	}
 
+	discordMaxResponseLen, err := intOrDefault(env, "DISCORD_MAX_RESPONSE_LEN", DefaultDiscordMaxResponseLen)
+	if err != nil {
+		return nil, err
+	}
+	discordArchiveMinutes, err := intOrDefault(env, "DISCORD_ARCHIVE_MINUTES", 0)
+	if err != nil {
+		return nil, err
+	}
+	discordPruneDays, err := parseDiscordPruneDays(env["DISCORD_PRUNE_DAYS"])
+	if err != nil {
+		return nil, err
+	}
+	whatsAppMaxResponseLen, err := intOrDefault(env, "WHATSAPP_MAX_RESPONSE_LEN", DefaultWhatsAppMaxResponseLen)
+	if err != nil {
+		return nil, err
+	}
+
+	maxConcurrentSessions, err := intOrDefault(env, "MAX_CONCURRENT_SESSIONS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var toolEnvAllow []string
+	if s := env["TOOL_ENV_ALLOWLIST"]; s != "" {
+		toolEnvAllow = splitAndTrim(s)
+	}
+	toolEnvDeny := DefaultToolEnvDenylist
+	if s := env["TOOL_ENV_DENYLIST"]; s != "" {
+		toolEnvDeny = splitAndTrim(s)
+	}
+
+	sqlDatabases, err := parseSQLDatabases(env["SQL_DATABASES"])
+	if err != nil {
+		return nil, err
+	}
+
+	egressProxy := env["EGRESS_PROXY"]
+	if egressProxy != "" {
+		u, err := url.Parse(egressProxy)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return nil, errors.Errorf("EGRESS_PROXY %q must be an http, https or socks5 URL", egressProxy)
+		}
+	}
+	var egressAllowed, egressDenied []string
+	if s := env["EGRESS_ALLOWED_DOMAINS"]; s != "" {
+		egressAllowed = splitAndTrim(s)
+	}
+	if s := env["EGRESS_DENIED_DOMAINS"]; s != "" {
+		egressDenied = splitAndTrim(s)
+	}
+
+	var fetchNetworks []string
+	if s := env["FETCH_ALLOWED_NETWORKS"]; s != "" {
+		for _, n := range splitAndTrim(s) {
+			if _, _, err := net.ParseCIDR(n); err != nil {
+				return nil, errors.Errorf("FETCH_ALLOWED_NETWORKS entry %q must be a CIDR such as 10.1.0.0/16", n)
+			}
+			fetchNetworks = append(fetchNetworks, n)
+		}
+	}
+
+	var verifyTrusted []string
+	if s := env["VERIFY_TRUSTED_DIRS"]; s != "" {
+		verifyTrusted = splitAndTrim(s)
+	}
+
+	var browseAllowedHosts []string
+	if s := env["BROWSE_ALLOWED_HOSTS"]; s != "" {
+		browseAllowedHosts = splitAndTrim(s)
+	}
+
+	language := strings.ToLower(env["LANGUAGE"])
+	switch language {
+	case "":
+		language = "en"
+	case "en", "es", "de", "af":
+	default:
+		return nil, errors.Errorf("LANGUAGE %q is not supported (en, es, de, af)", env["LANGUAGE"])
+	}
+
+	ttsProvider := env["TTS_PROVIDER"]
+	if ttsProvider != "" && ttsProvider != "openai" {
+		return nil, errors.Errorf("TTS_PROVIDER %q is not supported (openai)", ttsProvider)
+	}
+
+	sttProvider := env["STT_PROVIDER"]
+	if sttProvider != "" && sttProvider != "openai" {
+		return nil, errors.Errorf("STT_PROVIDER %q is not supported (openai)", sttProvider)
+	}
+	if env["DISCORD_VOICE_CHANNEL"] != "" {
+		switch {
+		case discordToken == "":
+			return nil, errors.New("DISCORD_VOICE_CHANNEL needs DISCORD_TOKEN")
+		case env["DISCORD_VOICE_GUILD"] == "" || env["DISCORD_VOICE_THREAD"] == "":
+			return nil, errors.New("DISCORD_VOICE_GUILD and DISCORD_VOICE_THREAD are required with DISCORD_VOICE_CHANNEL")
+		case sttProvider == "":
+			return nil, errors.New("STT_PROVIDER is required with DISCORD_VOICE_CHANNEL")
+		}
+	}
+	voiceWakeWord := env["VOICE_WAKE_WORD"]
+	if voiceWakeWord == "" {
+		voiceWakeWord = DefaultVoiceWakeWord
+	}
+
+	imageProvider := env["IMAGE_PROVIDER"]
+	switch imageProvider {
+	case "", "openai":
+	case "sdwebui":
+		if env["IMAGE_BASE_URL"] == "" {
+			return nil, errors.New("IMAGE_BASE_URL is required when IMAGE_PROVIDER=sdwebui")
+		}
+	default:
+		return nil, errors.Errorf("IMAGE_PROVIDER %q is not supported (openai, sdwebui)", imageProvider)
+	}
+	turnTokenLimit, err := intOrDefault(env, "TURN_TOKEN_LIMIT", 0)
+	if err != nil {
+		return nil, err
+	}
+	turnCacheMinutes, err := intOrDefault(env, "TURN_CACHE_MINUTES", 0)
+	if err != nil {
+		return nil, err
+	}
+	toolOutputBudget, err := intOrDefault(env, "TOOL_OUTPUT_BUDGET_TOKENS", DefaultToolOutputBudgetTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	var adminUsers []string
+	if env["ADMIN_USERS"] != "" {
+		adminUsers = splitAndTrim(env["ADMIN_USERS"])
+		for _, u := range adminUsers {
+			if platform, id, ok := strings.Cut(u, ":"); !ok || platform == "" || id == "" {
+				return nil, errors.Errorf("ADMIN_USERS entry %q must be platform:userID", u)
+			}
+		}
+	}
+
+	var mcpAllowedChats []string
+	if env["MCP_SERVER_TOKEN"] != "" {
+		if env["MCP_ALLOWED_CHATS"] == "" {
+			return nil, errors.New("MCP_ALLOWED_CHATS is required when MCP_SERVER_TOKEN is set")
+		}
+		mcpAllowedChats = splitAndTrim(env["MCP_ALLOWED_CHATS"])
+		for _, c := range mcpAllowedChats {
+			if channel, chat, ok := strings.Cut(c, ":"); !ok || channel == "" || chat == "" {
+				return nil, errors.Errorf("MCP_ALLOWED_CHATS entry %q must be channel:chatID", c)
+			}
+		}
+	}
+
+	var shareTTLHours int
+	if env["SHARE_BASE_URL"] != "" {
+		shareTTLHours, err = intOrDefault(env, "SHARE_TTL_HOURS", DefaultShareTTLHours)
+		if err != nil {
+			return nil, err
+		}
+		if shareTTLHours == 0 {
+			return nil, errors.New("SHARE_TTL_HOURS must be positive")
+		}
+	}
+
+	embeddingProvider := env["EMBEDDING_PROVIDER"]
+	if embeddingProvider != "" && embeddingProvider != "openai" {
+		return nil, errors.Errorf("EMBEDDING_PROVIDER %q is not supported (openai)", embeddingProvider)
+	}
+	codeSearchIndex := env["CODE_SEARCH_INDEX"]
+	if codeSearchIndex == "" {
+		codeSearchIndex = "code-index.db"
+	}
+	metricsDB := env["METRICS_DB"]
+	if metricsDB == "" {
+		metricsDB = "metrics.db"
+	}
+	guildSettingsDB := env["GUILD_SETTINGS_DB"]
+	if guildSettingsDB == "" {
+		guildSettingsDB = "guilds.db"
+	}
+
+	imageDailyLimit, err := intOrDefault(env, "IMAGE_DAILY_LIMIT", DefaultImageDailyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeContexts, err := parseKubeContexts(env["KUBE_CONTEXTS"])
+	if err != nil {
+		return nil, err
+	}
+
 	memoryDir := env["MEMORY_DIR"]
 	if memoryDir == "" {
 		memoryDir = defaultMemoryDir(allowedDirs[0])
@@ -190,9 +741,32 @@ func Load(env map[string]string) (*Config, error) {
 		return nil, errors.Errorf("MEMORY_DIR %q must live under ALLOWED_DIRS", memoryDir)
 	}
 
+	faultCfg, err := faults.Parse(env["FAULT_INJECTION"])
+	if err != nil {
+		return nil, errors.Wrap(err, "FAULT_INJECTION")
+	}
+
+	updateCheck := env["UPDATE_CHECK"]
+	if updateCheck == "" {
+		updateCheck = "minor"
+	}
+	updateLevel, err := version.ParseLevel(updateCheck)
+	if err != nil {
+		return nil, errors.Wrap(err, "UPDATE_CHECK")
+	}
+
+	webhookOutURL := env["WEBHOOK_OUT_URL"]
+	if webhookOutURL != "" {
+		u, err := url.Parse(webhookOutURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, errors.Errorf("WEBHOOK_OUT_URL %q must be an http or https URL", webhookOutURL)
+		}
+	}
+
 	return &Config{
 		DiscordToken:           discordToken,
 		AllowedDirs:            allowedDirs,
+		Workspaces:             workspaces,
 		AllowedUsers:           allowedUsers,
 		AgentCWD:               agentCwd,
 		WebhookPort:            webhookPort,
@@ -200,15 +774,91 @@ func Load(env map[string]string) (*Config, error) {
 		BaseURL:                baseURL,
 		ResendAPIKey:           resendAPIKey,
 		DashboardPassword:      dashboardPassword,
+		ViewerPassword:         env["DASHBOARD_VIEWER_PASSWORD"],
+		DashboardToken:         env["DASHBOARD_API_TOKEN"],
 		WebSearchAPIKey:        webSearchAPIKey,
 		Model:                  model,
 		WhatsAppAllowedSenders: whatsAppSenders,
 		WhatsAppDBPath:         whatsAppDBPath,
 		WhatsAppMediaDir:       mediaDir,
 		DiscordMediaDir:        discordMediaDir,
+		DiscordMaxResponseLen:  discordMaxResponseLen,
+		DiscordArchiveMinutes:  discordArchiveMinutes,
+		DiscordPruneDays:       discordPruneDays,
+		WhatsAppMaxResponseLen: whatsAppMaxResponseLen,
+		MaxConcurrentSessions:  maxConcurrentSessions,
 		MemoryDir:              memoryDir,
 		AgentsDefaultPath:      agentsDefaultPath,
 		ThinkingBudgetTokens:   thinkingBudget,
+		TurnTokenLimit:         turnTokenLimit,
+		TurnCacheMinutes:       turnCacheMinutes,
+		ToolOutputBudgetTokens: toolOutputBudget,
+		ToolEnvAllowlist:       toolEnvAllow,
+		ToolEnvDenylist:        toolEnvDeny,
+		ContentPolicyPath:      env["CONTENT_POLICY_FILE"],
+		ScriptToolsDir:         env["SCRIPT_TOOLS_DIR"],
+		PreToolHook:            env["PRE_TOOL_HOOK"],
+		PostToolHook:           env["POST_TOOL_HOOK"],
+		SQLDatabases:           sqlDatabases,
+		KubeContexts:           kubeContexts,
+		KubeAllowWrites:        env["KUBE_ALLOW_WRITES"] == "1",
+		BrowseEnabled:          env["BROWSE_ENABLED"] == "1",
+		BrowseAllowedHosts:     browseAllowedHosts,
+		RepoMapEnabled:         env["REPO_MAP_ENABLED"] == "1",
+		CloneRoot:              cloneRoot,
+		CloneAllowedHosts:      cloneHosts,
+		CloneMaxMB:             cloneMaxMB,
+		ScratchTTLMinutes:      scratchTTL,
+		SessionBranches:        env["SESSION_BRANCHES"] == "1",
+		VerifyTrustedDirs:      verifyTrusted,
+		Language:               language,
+		PersonasDir:            env["PERSONAS_DIR"],
+		EgressProxy:            egressProxy,
+		EgressAllowedDomains:   egressAllowed,
+		EgressDeniedDomains:    egressDenied,
+		FetchAllowedNetworks:   fetchNetworks,
+		WebCacheDir:            env["WEB_CACHE_DIR"],
+		WebCacheTTLMinutes:     webCacheTTL,
+		APIRecordDir:           env["API_RECORD_DIR"],
+		TTSProvider:            ttsProvider,
+		TTSAPIKey:              env["TTS_API_KEY"],
+		TTSBaseURL:             env["TTS_BASE_URL"],
+		TTSModel:               env["TTS_MODEL"],
+		TTSVoice:               env["TTS_VOICE"],
+		STTProvider:            sttProvider,
+		STTAPIKey:              env["STT_API_KEY"],
+		STTBaseURL:             env["STT_BASE_URL"],
+		STTModel:               env["STT_MODEL"],
+		VoiceGuild:             env["DISCORD_VOICE_GUILD"],
+		VoiceChannel:           env["DISCORD_VOICE_CHANNEL"],
+		VoiceThread:            env["DISCORD_VOICE_THREAD"],
+		VoiceWakeWord:          voiceWakeWord,
+		ImageProvider:          imageProvider,
+		ImageAPIKey:            env["IMAGE_API_KEY"],
+		ImageBaseURL:           env["IMAGE_BASE_URL"],
+		ImageModel:             env["IMAGE_MODEL"],
+		ImageDailyLimit:        imageDailyLimit,
+		ShareBaseURL:           env["SHARE_BASE_URL"],
+		MCPServerToken:         env["MCP_SERVER_TOKEN"],
+		MCPAllowedChats:        mcpAllowedChats,
+		ShareTTLHours:          shareTTLHours,
+		EmbeddingProvider:      embeddingProvider,
+		EmbeddingAPIKey:        env["EMBEDDING_API_KEY"],
+		EmbeddingBaseURL:       env["EMBEDDING_BASE_URL"],
+		EmbeddingModel:         env["EMBEDDING_MODEL"],
+		CodeSearchIndex:        codeSearchIndex,
+		MetricsDB:              metricsDB,
+		GuildSettingsDB:        guildSettingsDB,
+		TenantsFile:            env["TENANTS_FILE"],
+		HooksFile:              env["HOOKS_FILE"],
+		Faults:                 faultCfg,
+		UpdateCheck:            updateLevel,
+		WebhookOutURL:          webhookOutURL,
+		WebhookOutSecret:       env["WEBHOOK_OUT_SECRET"],
+		BatchEnabled:           env["BATCH_ENABLED"] == "1",
+		FeedbackReactions:      env["FEEDBACK_REACTIONS"] == "1",
+		WhatsAppPresence:       env["WHATSAPP_PRESENCE"] == "1",
+		AdminUsers:             adminUsers,
 	}, nil
 }
 
@@ -230,6 +880,11 @@ func (c *Config) EnsureDirs() error {
 			return errors.Wrap(err, "creating MEMORY_DIR")
 		}
 	}
+	if c.CloneRoot != "" {
+		if err := os.MkdirAll(c.CloneRoot, 0o755); err != nil {
+			return errors.Wrap(err, "creating CLONE_ROOT")
+		}
+	}
 	return nil
 }
 
@@ -263,27 +918,104 @@ func defaultMemoryDir(base string) string {
 // LoadFromEnv loads config from os environment variables.
 func LoadFromEnv() (*Config, error) {
 	env := map[string]string{
-		"DISCORD_TOKEN":            os.Getenv("DISCORD_TOKEN"),
-		"ALLOWED_DIRS":             os.Getenv("ALLOWED_DIRS"),
-		"ALLOWED_USERS":            os.Getenv("ALLOWED_USERS"),
-		"AGENT_CWD":                os.Getenv("AGENT_CWD"),
-		"CLAUDE_CWD":               os.Getenv("CLAUDE_CWD"),
-		"WEBHOOK_PORT":             os.Getenv("WEBHOOK_PORT"),
-		"SWITCHBOARD_API_KEY":      os.Getenv("SWITCHBOARD_API_KEY"),
-		"CLAUDECORD_API_KEY":       os.Getenv("CLAUDECORD_API_KEY"),
-		"SWITCHBOARD_BASE_URL":     os.Getenv("SWITCHBOARD_BASE_URL"),
-		"CLAUDECORD_BASE_URL":      os.Getenv("CLAUDECORD_BASE_URL"),
-		"RESEND_API_KEY":           os.Getenv("RESEND_API_KEY"),
-		"DASHBOARD_PASSWORD":       os.Getenv("DASHBOARD_PASSWORD"),
-		"WEB_SEARCH_API_KEY":       os.Getenv("WEB_SEARCH_API_KEY"),
-		"WHATSAPP_ALLOWED_SENDERS": os.Getenv("WHATSAPP_ALLOWED_SENDERS"),
-		"WHATSAPP_DB_PATH":         os.Getenv("WHATSAPP_DB_PATH"),
-		"WHATSAPP_MEDIA_DIR":       os.Getenv("WHATSAPP_MEDIA_DIR"),
-		"DISCORD_MEDIA_DIR":        os.Getenv("DISCORD_MEDIA_DIR"),
-		"MODEL":                    os.Getenv("MODEL"),
-		"MEMORY_DIR":               os.Getenv("MEMORY_DIR"),
-		"AGENTS_DEFAULT_PATH":      os.Getenv("AGENTS_DEFAULT_PATH"),
-		"THINKING_BUDGET_TOKENS":   os.Getenv("THINKING_BUDGET_TOKENS"),
+		"DISCORD_TOKEN":             os.Getenv("DISCORD_TOKEN"),
+		"ALLOWED_DIRS":              os.Getenv("ALLOWED_DIRS"),
+		"WORKSPACES":                os.Getenv("WORKSPACES"),
+		"ALLOWED_USERS":             os.Getenv("ALLOWED_USERS"),
+		"AGENT_CWD":                 os.Getenv("AGENT_CWD"),
+		"CLAUDE_CWD":                os.Getenv("CLAUDE_CWD"),
+		"WEBHOOK_PORT":              os.Getenv("WEBHOOK_PORT"),
+		"SWITCHBOARD_API_KEY":       os.Getenv("SWITCHBOARD_API_KEY"),
+		"CLAUDECORD_API_KEY":        os.Getenv("CLAUDECORD_API_KEY"),
+		"SWITCHBOARD_BASE_URL":      os.Getenv("SWITCHBOARD_BASE_URL"),
+		"CLAUDECORD_BASE_URL":       os.Getenv("CLAUDECORD_BASE_URL"),
+		"RESEND_API_KEY":            os.Getenv("RESEND_API_KEY"),
+		"DASHBOARD_PASSWORD":        os.Getenv("DASHBOARD_PASSWORD"),
+		"DASHBOARD_VIEWER_PASSWORD": os.Getenv("DASHBOARD_VIEWER_PASSWORD"),
+		"DASHBOARD_API_TOKEN":       os.Getenv("DASHBOARD_API_TOKEN"),
+		"MCP_SERVER_TOKEN":          os.Getenv("MCP_SERVER_TOKEN"),
+		"MCP_ALLOWED_CHATS":         os.Getenv("MCP_ALLOWED_CHATS"),
+		"WEB_SEARCH_API_KEY":        os.Getenv("WEB_SEARCH_API_KEY"),
+		"WHATSAPP_ALLOWED_SENDERS":  os.Getenv("WHATSAPP_ALLOWED_SENDERS"),
+		"WHATSAPP_DB_PATH":          os.Getenv("WHATSAPP_DB_PATH"),
+		"WHATSAPP_MEDIA_DIR":        os.Getenv("WHATSAPP_MEDIA_DIR"),
+		"DISCORD_MEDIA_DIR":         os.Getenv("DISCORD_MEDIA_DIR"),
+		"MODEL":                     os.Getenv("MODEL"),
+		"MEMORY_DIR":                os.Getenv("MEMORY_DIR"),
+		"AGENTS_DEFAULT_PATH":       os.Getenv("AGENTS_DEFAULT_PATH"),
+		"THINKING_BUDGET_TOKENS":    os.Getenv("THINKING_BUDGET_TOKENS"),
+		"TOOL_ENV_ALLOWLIST":        os.Getenv("TOOL_ENV_ALLOWLIST"),
+		"TOOL_ENV_DENYLIST":         os.Getenv("TOOL_ENV_DENYLIST"),
+		"CONTENT_POLICY_FILE":       os.Getenv("CONTENT_POLICY_FILE"),
+		"DISCORD_MAX_RESPONSE_LEN":  os.Getenv("DISCORD_MAX_RESPONSE_LEN"),
+		"DISCORD_ARCHIVE_MINUTES":   os.Getenv("DISCORD_ARCHIVE_MINUTES"),
+		"DISCORD_PRUNE_DAYS":        os.Getenv("DISCORD_PRUNE_DAYS"),
+		"WHATSAPP_MAX_RESPONSE_LEN": os.Getenv("WHATSAPP_MAX_RESPONSE_LEN"),
+		"MAX_CONCURRENT_SESSIONS":   os.Getenv("MAX_CONCURRENT_SESSIONS"),
+		"SCRIPT_TOOLS_DIR":          os.Getenv("SCRIPT_TOOLS_DIR"),
+		"PRE_TOOL_HOOK":             os.Getenv("PRE_TOOL_HOOK"),
+		"POST_TOOL_HOOK":            os.Getenv("POST_TOOL_HOOK"),
+		"SQL_DATABASES":             os.Getenv("SQL_DATABASES"),
+		"KUBE_CONTEXTS":             os.Getenv("KUBE_CONTEXTS"),
+		"KUBE_ALLOW_WRITES":         os.Getenv("KUBE_ALLOW_WRITES"),
+		"BROWSE_ENABLED":            os.Getenv("BROWSE_ENABLED"),
+		"BROWSE_ALLOWED_HOSTS":      os.Getenv("BROWSE_ALLOWED_HOSTS"),
+		"CLONE_ROOT":                os.Getenv("CLONE_ROOT"),
+		"CLONE_ALLOWED_HOSTS":       os.Getenv("CLONE_ALLOWED_HOSTS"),
+		"CLONE_MAX_MB":              os.Getenv("CLONE_MAX_MB"),
+		"SCRATCH_TTL_MINUTES":       os.Getenv("SCRATCH_TTL_MINUTES"),
+		"EGRESS_PROXY":              os.Getenv("EGRESS_PROXY"),
+		"EGRESS_ALLOWED_DOMAINS":    os.Getenv("EGRESS_ALLOWED_DOMAINS"),
+		"EGRESS_DENIED_DOMAINS":     os.Getenv("EGRESS_DENIED_DOMAINS"),
+		"FETCH_ALLOWED_NETWORKS":    os.Getenv("FETCH_ALLOWED_NETWORKS"),
+		"WEB_CACHE_DIR":             os.Getenv("WEB_CACHE_DIR"),
+		"WEB_CACHE_TTL_MINUTES":     os.Getenv("WEB_CACHE_TTL_MINUTES"),
+		"API_RECORD_DIR":            os.Getenv("API_RECORD_DIR"),
+		"SESSION_BRANCHES":          os.Getenv("SESSION_BRANCHES"),
+		"VERIFY_TRUSTED_DIRS":       os.Getenv("VERIFY_TRUSTED_DIRS"),
+		"LANGUAGE":                  os.Getenv("LANGUAGE"),
+		"PERSONAS_DIR":              os.Getenv("PERSONAS_DIR"),
+		"REPO_MAP_ENABLED":          os.Getenv("REPO_MAP_ENABLED"),
+		"TTS_PROVIDER":              os.Getenv("TTS_PROVIDER"),
+		"TTS_API_KEY":               os.Getenv("TTS_API_KEY"),
+		"TTS_BASE_URL":              os.Getenv("TTS_BASE_URL"),
+		"TTS_MODEL":                 os.Getenv("TTS_MODEL"),
+		"TTS_VOICE":                 os.Getenv("TTS_VOICE"),
+		"STT_PROVIDER":              os.Getenv("STT_PROVIDER"),
+		"STT_API_KEY":               os.Getenv("STT_API_KEY"),
+		"STT_BASE_URL":              os.Getenv("STT_BASE_URL"),
+		"STT_MODEL":                 os.Getenv("STT_MODEL"),
+		"DISCORD_VOICE_GUILD":       os.Getenv("DISCORD_VOICE_GUILD"),
+		"DISCORD_VOICE_CHANNEL":     os.Getenv("DISCORD_VOICE_CHANNEL"),
+		"DISCORD_VOICE_THREAD":      os.Getenv("DISCORD_VOICE_THREAD"),
+		"VOICE_WAKE_WORD":           os.Getenv("VOICE_WAKE_WORD"),
+		"IMAGE_PROVIDER":            os.Getenv("IMAGE_PROVIDER"),
+		"IMAGE_API_KEY":             os.Getenv("IMAGE_API_KEY"),
+		"IMAGE_BASE_URL":            os.Getenv("IMAGE_BASE_URL"),
+		"IMAGE_MODEL":               os.Getenv("IMAGE_MODEL"),
+		"IMAGE_DAILY_LIMIT":         os.Getenv("IMAGE_DAILY_LIMIT"),
+		"SHARE_BASE_URL":            os.Getenv("SHARE_BASE_URL"),
+		"SHARE_TTL_HOURS":           os.Getenv("SHARE_TTL_HOURS"),
+		"TURN_TOKEN_LIMIT":          os.Getenv("TURN_TOKEN_LIMIT"),
+		"TURN_CACHE_MINUTES":        os.Getenv("TURN_CACHE_MINUTES"),
+		"TOOL_OUTPUT_BUDGET_TOKENS": os.Getenv("TOOL_OUTPUT_BUDGET_TOKENS"),
+		"EMBEDDING_PROVIDER":        os.Getenv("EMBEDDING_PROVIDER"),
+		"EMBEDDING_API_KEY":         os.Getenv("EMBEDDING_API_KEY"),
+		"EMBEDDING_BASE_URL":        os.Getenv("EMBEDDING_BASE_URL"),
+		"EMBEDDING_MODEL":           os.Getenv("EMBEDDING_MODEL"),
+		"CODE_SEARCH_INDEX":         os.Getenv("CODE_SEARCH_INDEX"),
+		"METRICS_DB":                os.Getenv("METRICS_DB"),
+		"GUILD_SETTINGS_DB":         os.Getenv("GUILD_SETTINGS_DB"),
+		"TENANTS_FILE":              os.Getenv("TENANTS_FILE"),
+		"HOOKS_FILE":                os.Getenv("HOOKS_FILE"),
+		"FAULT_INJECTION":           os.Getenv("FAULT_INJECTION"),
+		"UPDATE_CHECK":              os.Getenv("UPDATE_CHECK"),
+		"WEBHOOK_OUT_URL":           os.Getenv("WEBHOOK_OUT_URL"),
+		"WEBHOOK_OUT_SECRET":        os.Getenv("WEBHOOK_OUT_SECRET"),
+		"BATCH_ENABLED":             os.Getenv("BATCH_ENABLED"),
+		"FEEDBACK_REACTIONS":        os.Getenv("FEEDBACK_REACTIONS"),
+		"WHATSAPP_PRESENCE":         os.Getenv("WHATSAPP_PRESENCE"),
+		"ADMIN_USERS":               os.Getenv("ADMIN_USERS"),
 	}
 	return Load(env)
 }
@@ -301,6 +1033,173 @@ func envOrLegacy(env map[string]string, key, legacyKey string) string {
 	return ""
 }
 
+// intOrDefault parses env[key] as a non-negative integer, returning def when
+// the key is unset.
+func intOrDefault(env map[string]string, key string, def int) (int, error) {
+	s := env[key]
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s must be an integer", key)
+	}
+	if n < 0 {
+		return 0, errors.Errorf("%s must not be negative", key)
+	}
+	return n, nil
+}
+
+// parseSQLDatabases parses semicolon-separated name=driver:dsn entries.
+// Semicolons rather than commas because DSNs often contain commas.
+func parseSQLDatabases(s string) ([]SQLDatabase, error) {
+	var dbs []SQLDatabase
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		driver, dsn, ok2 := strings.Cut(rest, ":")
+		name, driver = strings.TrimSpace(name), strings.TrimSpace(driver)
+		if !ok || !ok2 || name == "" || dsn == "" {
+			return nil, errors.Errorf("SQL_DATABASES entry %q must be name=driver:dsn", name)
+		}
+		if !sqlDrivers[driver] {
+			return nil, errors.Errorf("SQL_DATABASES entry %q: unknown driver %q (postgres, mysql, sqlite)", name, driver)
+		}
+		if seen[name] {
+			return nil, errors.Errorf("SQL_DATABASES entry %q is duplicated", name)
+		}
+		seen[name] = true
+		dbs = append(dbs, SQLDatabase{Name: name, Driver: driver, DSN: dsn})
+	}
+	return dbs, nil
+}
+
+// parseKubeContexts parses semicolon-separated context=ns1,ns2 entries.
+func parseKubeContexts(s string) (map[string][]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	contexts := make(map[string][]string)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, namespaces, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.TrimSpace(namespaces) == "" {
+			return nil, errors.Errorf("KUBE_CONTEXTS entry %q must be context=namespace[,namespace...]", entry)
+		}
+		if _, dup := contexts[name]; dup {
+			return nil, errors.Errorf("KUBE_CONTEXTS entry %q is duplicated", name)
+		}
+		contexts[name] = splitAndTrim(namespaces)
+	}
+	return contexts, nil
+}
+
+// parseDiscordPruneDays parses comma-separated channelID=days entries.
+func parseDiscordPruneDays(s string) (map[string]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	days := make(map[string]int)
+	for _, entry := range splitAndTrim(s) {
+		channel, n, ok := strings.Cut(entry, "=")
+		channel = strings.TrimSpace(channel)
+		d, err := strconv.Atoi(strings.TrimSpace(n))
+		if !ok || channel == "" || err != nil || d < 1 {
+			return nil, errors.Errorf("DISCORD_PRUNE_DAYS entry %q must be channelID=days with days >= 1", entry)
+		}
+		if _, dup := days[channel]; dup {
+			return nil, errors.Errorf("DISCORD_PRUNE_DAYS entry %q is duplicated", channel)
+		}
+		days[channel] = d
+	}
+	return days, nil
+}
+
+var workspaceLabel = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// parseWorkspaces reads WORKSPACES: comma-separated label=path entries, each
+// optionally followed by :default and/or :readonly. Without a :default the
+// first entry is the default.
+func parseWorkspaces(s string) ([]Workspace, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var workspaces []Workspace
+	seen := make(map[string]bool)
+	defaults := 0
+	for _, entry := range splitAndTrim(s) {
+		label, rest, ok := strings.Cut(entry, "=")
+		label = strings.ToLower(strings.TrimSpace(label))
+		if !ok || !workspaceLabel.MatchString(label) {
+			return nil, errors.Errorf("invalid WORKSPACES entry %q: want label=path[:default][:readonly]", entry)
+		}
+		if seen[label] {
+			return nil, errors.Errorf("duplicate workspace %q", label)
+		}
+		seen[label] = true
+		parts := strings.Split(rest, ":")
+		w := Workspace{Label: label, Path: filepath.Clean(strings.TrimSpace(parts[0]))}
+		if strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("workspace %q has no path", label)
+		}
+		for _, flag := range parts[1:] {
+			switch strings.TrimSpace(flag) {
+			case "default":
+				w.Default = true
+				defaults++
+			case "readonly":
+				w.ReadOnly = true
+			default:
+				return nil, errors.Errorf("workspace %q: unknown flag %q", label, flag)
+			}
+		}
+		workspaces = append(workspaces, w)
+	}
+	switch {
+	case defaults > 1:
+		return nil, errors.New("WORKSPACES may mark only one workspace :default")
+	case defaults == 0:
+		workspaces[0].Default = true
+	}
+	return workspaces, nil
+}
+
+// dirWorkspaces labels each ALLOWED_DIRS entry by its base name, the first
+// being the default.
+func dirWorkspaces(dirs []string) []Workspace {
+	workspaces := make([]Workspace, 0, len(dirs))
+	seen := make(map[string]int)
+	for i, dir := range dirs {
+		label := strings.ToLower(filepath.Base(dir))
+		if !workspaceLabel.MatchString(label) {
+			label = "dir"
+		}
+		seen[label]++
+		if n := seen[label]; n > 1 {
+			label = fmt.Sprintf("%s-%d", label, n)
+		}
+		workspaces = append(workspaces, Workspace{Label: label, Path: dir, Default: i == 0})
+	}
+	return workspaces
+}
+
+func defaultWorkspace(workspaces []Workspace) Workspace {
+	for _, w := range workspaces {
+		if w.Default {
+			return w
+		}
+	}
+	return workspaces[0]
+}
+
 func splitAndTrim(s string) []string {
 	parts := strings.Split(s, ",")
 	for i := range parts {
@@ -2,8 +2,11 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/TheLazyLemur/switchboard/internal/faults"
+	"github.com/TheLazyLemur/switchboard/internal/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -714,3 +717,657 @@ func TestLoad_NewAgentCWDTakesPrecedenceOverLegacy(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "/new/path", cfg.AgentCWD)
 }
+
+// --- Tool env filtering ---
+
+func TestLoad_ToolEnvDenylistDefaultsToSecrets(t *testing.T) {
+	// given
+	// ... env without TOOL_ENV_DENYLIST
+
+	// when
+	// ... config is loaded
+	cfg, err := Load(validDiscordEnv())
+
+	// then
+	// ... the bot's own secrets are denied and nothing is allowlisted
+	require.NoError(t, err)
+	assert.Contains(t, cfg.ToolEnvDenylist, "DISCORD_TOKEN")
+	assert.Contains(t, cfg.ToolEnvDenylist, "SWITCHBOARD_API_KEY")
+	assert.NotContains(t, cfg.ToolEnvDenylist, "RESEND_API_KEY")
+	assert.Empty(t, cfg.ToolEnvAllowlist)
+}
+
+func TestDefaultToolEnvDenylist_CoversSecrets(t *testing.T) {
+	// given
+	// ... every secret-bearing variable set to a value naming it
+	env := validDiscordEnv()
+	for _, name := range []string{"DISCORD_TOKEN", "SWITCHBOARD_API_KEY", "RESEND_API_KEY", "DASHBOARD_PASSWORD", "DASHBOARD_VIEWER_PASSWORD", "DASHBOARD_API_TOKEN", "MCP_SERVER_TOKEN", "WEBHOOK_OUT_SECRET", "WEB_SEARCH_API_KEY", "TTS_API_KEY", "STT_API_KEY", "IMAGE_API_KEY", "EMBEDDING_API_KEY"} {
+		env[name] = "secret-" + name
+	}
+	env["SQL_DATABASES"] = "db=sqlite:secret-SQL_DATABASES"
+	env["MCP_ALLOWED_CHATS"] = "discord:1"
+
+	// when
+	cfg, err := Load(env)
+
+	// then
+	// ... each secret's variable is denied, except the one the email skill needs
+	require.NoError(t, err)
+	secrets := cfg.Secrets()
+	require.Len(t, secrets, 14)
+	for _, secret := range secrets {
+		name := strings.TrimPrefix(secret, "secret-")
+		if name == "RESEND_API_KEY" {
+			continue
+		}
+		assert.Contains(t, cfg.ToolEnvDenylist, name)
+	}
+}
+
+func TestLoad_ToolEnvListsOverride(t *testing.T) {
+	// given
+	// ... env with explicit allow and deny lists
+	env := validDiscordEnv()
+	env["TOOL_ENV_ALLOWLIST"] = "PATH, HOME"
+	env["TOOL_ENV_DENYLIST"] = "HOME"
+
+	// when
+	// ... config is loaded
+	cfg, err := Load(env)
+
+	// then
+	// ... both lists are parsed and the default denylist is replaced
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PATH", "HOME"}, cfg.ToolEnvAllowlist)
+	assert.Equal(t, []string{"HOME"}, cfg.ToolEnvDenylist)
+}
+
+func TestConfig_SecretsSkipsUnset(t *testing.T) {
+	// given
+	// ... a config with some credentials set
+	cfg := &Config{DiscordToken: "dtok", APIKey: "sk-test", ResendAPIKey: ""}
+
+	// when
+	// ... secrets are collected
+	got := cfg.Secrets()
+
+	// then
+	// ... only non-empty values are returned
+	assert.Equal(t, []string{"dtok", "sk-test"}, got)
+}
+
+// --- Response length caps ---
+
+func TestLoad_MaxResponseLenDefaults(t *testing.T) {
+	cfg, err := Load(validDiscordEnv())
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultDiscordMaxResponseLen, cfg.DiscordMaxResponseLen)
+	assert.Equal(t, DefaultWhatsAppMaxResponseLen, cfg.WhatsAppMaxResponseLen)
+}
+
+func TestLoad_MaxResponseLenOverrideAndDisable(t *testing.T) {
+	env := validDiscordEnv()
+	env["DISCORD_MAX_RESPONSE_LEN"] = "0"
+	env["WHATSAPP_MAX_RESPONSE_LEN"] = "1500"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.DiscordMaxResponseLen)
+	assert.Equal(t, 1500, cfg.WhatsAppMaxResponseLen)
+}
+
+func TestLoad_MaxResponseLenRejectsGarbage(t *testing.T) {
+	env := validDiscordEnv()
+	env["DISCORD_MAX_RESPONSE_LEN"] = "lots"
+
+	_, err := Load(env)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DISCORD_MAX_RESPONSE_LEN")
+}
+
+func TestLoad_MaxConcurrentSessions(t *testing.T) {
+	env := validDiscordEnv()
+	env["MAX_CONCURRENT_SESSIONS"] = "8"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, 8, cfg.MaxConcurrentSessions)
+}
+
+func TestLoad_SQLDatabases(t *testing.T) {
+	env := validDiscordEnv()
+	env["SQL_DATABASES"] = "analytics=postgres:postgres://ro:pw@db/analytics?sslmode=disable; shop=mysql:ro:pw@tcp(db:3306)/shop"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, []SQLDatabase{
+		{Name: "analytics", Driver: "postgres", DSN: "postgres://ro:pw@db/analytics?sslmode=disable"},
+		{Name: "shop", Driver: "mysql", DSN: "ro:pw@tcp(db:3306)/shop"},
+	}, cfg.SQLDatabases)
+	assert.Contains(t, cfg.Secrets(), "ro:pw@tcp(db:3306)/shop")
+}
+
+func TestLoad_SQLDatabasesRejectsBadEntries(t *testing.T) {
+	for _, v := range []string{"analytics", "analytics=oracle:dsn", "a=sqlite:x.db;a=sqlite:y.db"} {
+		env := validDiscordEnv()
+		env["SQL_DATABASES"] = v
+
+		_, err := Load(env)
+
+		assert.ErrorContains(t, err, "SQL_DATABASES", v)
+	}
+}
+
+func TestLoad_KubeContexts(t *testing.T) {
+	env := validDiscordEnv()
+	env["KUBE_CONTEXTS"] = "prod=web, api; staging=*"
+	env["KUBE_ALLOW_WRITES"] = "1"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"prod": {"web", "api"}, "staging": {"*"}}, cfg.KubeContexts)
+	assert.True(t, cfg.KubeAllowWrites)
+}
+
+func TestLoad_KubeContextsRejectsMissingNamespaces(t *testing.T) {
+	env := validDiscordEnv()
+	env["KUBE_CONTEXTS"] = "prod"
+
+	_, err := Load(env)
+
+	assert.ErrorContains(t, err, "KUBE_CONTEXTS")
+}
+
+func TestLoad_DiscordCleanup(t *testing.T) {
+	env := validDiscordEnv()
+	env["DISCORD_ARCHIVE_MINUTES"] = "15"
+	env["DISCORD_PRUNE_DAYS"] = "111=7, 222=30"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, 15, cfg.DiscordArchiveMinutes)
+	assert.Equal(t, map[string]int{"111": 7, "222": 30}, cfg.DiscordPruneDays)
+}
+
+func TestLoad_DiscordPruneDaysRejectsBadEntries(t *testing.T) {
+	for _, v := range []string{"111", "111=0", "111=week", "=7", "111=7,111=3"} {
+		env := validDiscordEnv()
+		env["DISCORD_PRUNE_DAYS"] = v
+
+		_, err := Load(env)
+
+		assert.ErrorContains(t, err, "DISCORD_PRUNE_DAYS", v)
+	}
+}
+
+func TestLoad_Browse(t *testing.T) {
+	env := validDiscordEnv()
+	env["BROWSE_ENABLED"] = "1"
+	env["BROWSE_ALLOWED_HOSTS"] = "staging.example.com, docs.example.com"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.True(t, cfg.BrowseEnabled)
+	assert.Equal(t, []string{"staging.example.com", "docs.example.com"}, cfg.BrowseAllowedHosts)
+}
+
+func TestLoad_Embedding(t *testing.T) {
+	env := validDiscordEnv()
+	env["EMBEDDING_PROVIDER"] = "openai"
+	env["EMBEDDING_API_KEY"] = "sk-embed"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.EmbeddingProvider)
+	assert.Equal(t, "code-index.db", cfg.CodeSearchIndex)
+	assert.Contains(t, cfg.Secrets(), "sk-embed")
+
+	env["EMBEDDING_PROVIDER"] = "cohere"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "EMBEDDING_PROVIDER")
+}
+
+func TestLoad_TurnTokenLimit(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Zero(t, cfg.TurnTokenLimit)
+
+	env["TURN_TOKEN_LIMIT"] = "150000"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, 150000, cfg.TurnTokenLimit)
+
+	env["TURN_TOKEN_LIMIT"] = "lots"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "TURN_TOKEN_LIMIT")
+}
+
+func TestLoad_TurnCacheMinutes(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Zero(t, cfg.TurnCacheMinutes)
+
+	env["TURN_CACHE_MINUTES"] = "5"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.TurnCacheMinutes)
+
+	env["TURN_CACHE_MINUTES"] = "soon"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "TURN_CACHE_MINUTES")
+}
+
+func TestLoad_MetricsDB(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "metrics.db", cfg.MetricsDB)
+
+	env["METRICS_DB"] = "/var/lib/switchboard/metrics.db"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/switchboard/metrics.db", cfg.MetricsDB)
+}
+
+func TestLoad_GuildSettingsDB(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "guilds.db", cfg.GuildSettingsDB)
+
+	env["GUILD_SETTINGS_DB"] = "/var/lib/switchboard/guilds.db"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/switchboard/guilds.db", cfg.GuildSettingsDB)
+}
+
+func TestLoad_ToolHooks(t *testing.T) {
+	env := validDiscordEnv()
+	env["PRE_TOOL_HOOK"] = "./hooks/protect.sh"
+	env["POST_TOOL_HOOK"] = "gofmt -l . >&2"
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "./hooks/protect.sh", cfg.PreToolHook)
+	assert.Equal(t, "gofmt -l . >&2", cfg.PostToolHook)
+}
+
+func TestLoad_TTS(t *testing.T) {
+	env := validDiscordEnv()
+	env["TTS_PROVIDER"] = "openai"
+	env["TTS_API_KEY"] = "sk-tts"
+	env["TTS_VOICE"] = "nova"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.TTSProvider)
+	assert.Equal(t, "nova", cfg.TTSVoice)
+	assert.Contains(t, cfg.Secrets(), "sk-tts")
+
+	env["TTS_PROVIDER"] = "elevenlabs"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "TTS_PROVIDER")
+}
+
+func TestLoad_Language(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "en", cfg.Language)
+
+	env["LANGUAGE"] = "AF"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "af", cfg.Language)
+
+	env["LANGUAGE"] = "fr"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "LANGUAGE")
+}
+
+func TestLoad_Egress(t *testing.T) {
+	env := validDiscordEnv()
+	env["EGRESS_PROXY"] = "http://proxy.corp:3128"
+	env["EGRESS_ALLOWED_DOMAINS"] = "github.com, go.dev"
+	env["EGRESS_DENIED_DOMAINS"] = "gist.github.com"
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.corp:3128", cfg.EgressProxy)
+	assert.Equal(t, []string{"github.com", "go.dev"}, cfg.EgressAllowedDomains)
+	assert.Equal(t, []string{"gist.github.com"}, cfg.EgressDeniedDomains)
+
+	env["EGRESS_PROXY"] = "proxy.corp:3128"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "EGRESS_PROXY")
+}
+
+func TestLoad_FetchAllowedNetworks(t *testing.T) {
+	env := validDiscordEnv()
+	env["FETCH_ALLOWED_NETWORKS"] = "10.1.0.0/16, fd00::/8"
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.1.0.0/16", "fd00::/8"}, cfg.FetchAllowedNetworks)
+
+	env["FETCH_ALLOWED_NETWORKS"] = "10.1.2.3"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "FETCH_ALLOWED_NETWORKS")
+}
+
+func TestLoad_APIRecordDir(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.APIRecordDir)
+
+	env["API_RECORD_DIR"] = "/var/lib/switchboard/recordings"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/switchboard/recordings", cfg.APIRecordDir)
+}
+
+func TestLoad_FaultInjection(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.False(t, cfg.Faults.Enabled())
+
+	env["FAULT_INJECTION"] = "api_529=0.25,ws_drop=0.1"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, faults.Config{API529Rate: 0.25, WSDropRate: 0.1}, cfg.Faults)
+
+	env["FAULT_INJECTION"] = "cli_kill=0.1"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "FAULT_INJECTION")
+}
+
+func TestLoad_UpdateCheck(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, version.LevelMinor, cfg.UpdateCheck)
+
+	env["UPDATE_CHECK"] = "off"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, version.LevelOff, cfg.UpdateCheck)
+
+	env["UPDATE_CHECK"] = "weekly"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "UPDATE_CHECK")
+}
+
+func TestLoad_WebhookOut(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.WebhookOutURL)
+
+	env["WEBHOOK_OUT_URL"] = "https://hooks.example.com/switchboard"
+	env["WEBHOOK_OUT_SECRET"] = "whsec"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/switchboard", cfg.WebhookOutURL)
+	assert.Contains(t, cfg.Secrets(), "whsec")
+
+	env["WEBHOOK_OUT_URL"] = "hooks.example.com"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "WEBHOOK_OUT_URL")
+}
+
+func TestLoad_BatchEnabled(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.False(t, cfg.BatchEnabled)
+
+	env["BATCH_ENABLED"] = "1"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.True(t, cfg.BatchEnabled)
+}
+
+func TestLoad_FeedbackReactions(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.False(t, cfg.FeedbackReactions)
+
+	env["FEEDBACK_REACTIONS"] = "1"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.True(t, cfg.FeedbackReactions)
+}
+
+func TestLoad_WhatsAppPresence(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.False(t, cfg.WhatsAppPresence)
+
+	env["WHATSAPP_PRESENCE"] = "1"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.True(t, cfg.WhatsAppPresence)
+}
+
+func TestLoad_AdminUsers(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.AdminUsers)
+
+	env["ADMIN_USERS"] = "discord:123, whatsapp:27821234567@s.whatsapp.net"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"discord:123", "whatsapp:27821234567@s.whatsapp.net"}, cfg.AdminUsers)
+
+	env["ADMIN_USERS"] = "123"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "must be platform:userID")
+}
+
+func TestLoad_WebCache(t *testing.T) {
+	env := validDiscordEnv()
+
+	cfg, err := Load(env)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.WebCacheDir)
+	assert.Equal(t, DefaultWebCacheTTLMinutes, cfg.WebCacheTTLMinutes)
+
+	env["WEB_CACHE_DIR"] = "/var/cache/switchboard"
+	env["WEB_CACHE_TTL_MINUTES"] = "15"
+	cfg, err = Load(env)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/cache/switchboard", cfg.WebCacheDir)
+	assert.Equal(t, 15, cfg.WebCacheTTLMinutes)
+
+	env["WEB_CACHE_TTL_MINUTES"] = "0"
+	_, err = Load(env)
+	assert.ErrorContains(t, err, "WEB_CACHE_TTL_MINUTES")
+}
+
+func TestLoad_ImageProvider(t *testing.T) {
+	env := validDiscordEnv()
+	env["IMAGE_PROVIDER"] = "openai"
+	env["IMAGE_API_KEY"] = "sk-img"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.ImageProvider)
+	assert.Equal(t, DefaultImageDailyLimit, cfg.ImageDailyLimit)
+	assert.Contains(t, cfg.Secrets(), "sk-img")
+}
+
+func TestLoad_ImageProviderRejectsSDWebUIWithoutURL(t *testing.T) {
+	env := validDiscordEnv()
+	env["IMAGE_PROVIDER"] = "sdwebui"
+
+	_, err := Load(env)
+
+	assert.ErrorContains(t, err, "IMAGE_BASE_URL")
+}
+
+func TestLoad_WorkspacesParsed(t *testing.T) {
+	env := validDiscordEnv()
+	delete(env, "ALLOWED_DIRS")
+	env["WORKSPACES"] = "api=/srv/api, docs=/srv/docs:default:readonly"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Workspace{
+		{Label: "api", Path: "/srv/api"},
+		{Label: "docs", Path: "/srv/docs", Default: true, ReadOnly: true},
+	}, cfg.Workspaces)
+	assert.Equal(t, []string{"/srv/api", "/srv/docs"}, cfg.AllowedDirs)
+	assert.Equal(t, "/srv/docs", cfg.AgentCWD)
+}
+
+func TestLoad_WorkspacesFromAllowedDirs(t *testing.T) {
+	env := validDiscordEnv()
+	env["ALLOWED_DIRS"] = "/home/user, /srv/app, /other/app"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Workspace{
+		{Label: "user", Path: "/home/user", Default: true},
+		{Label: "app", Path: "/srv/app"},
+		{Label: "app-2", Path: "/other/app"},
+	}, cfg.Workspaces)
+	assert.Equal(t, "/home/user", cfg.AgentCWD)
+}
+
+func TestLoad_WorkspacesInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"api",
+		"api=/a,api=/b",
+		"api=/a:default,web=/b:default",
+		"api=/a:fast",
+		"Bad Label=/a",
+	} {
+		env := validDiscordEnv()
+		env["WORKSPACES"] = spec
+
+		_, err := Load(env)
+
+		assert.Error(t, err, spec)
+	}
+}
+
+// --- Sharing ---
+
+func TestLoad_ShareTTLOnlyCheckedWhenSharing(t *testing.T) {
+	// given
+	// ... a bad TTL with and without a share base URL
+	env := validDiscordEnv()
+	env["SHARE_TTL_HOURS"] = "0"
+
+	// when
+	disabled, disabledErr := Load(env)
+	env["SHARE_BASE_URL"] = "https://bot.example.com"
+	_, enabledErr := Load(env)
+
+	// then
+	// ... only the enabled config fails
+	require.NoError(t, disabledErr)
+	assert.Zero(t, disabled.ShareTTLHours)
+	assert.EqualError(t, enabledErr, "SHARE_TTL_HOURS must be positive")
+}
+
+func TestLoad_ShareTTLDefault(t *testing.T) {
+	env := validDiscordEnv()
+	env["SHARE_BASE_URL"] = "https://bot.example.com"
+
+	cfg, err := Load(env)
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultShareTTLHours, cfg.ShareTTLHours)
+}
+
+func TestLoad_ToolOutputBudget(t *testing.T) {
+	// given
+	env := thinkingTestEnv(t)
+
+	// when
+	cfg, err := Load(env)
+	env["TOOL_OUTPUT_BUDGET_TOKENS"] = "0"
+	disabled, disabledErr := Load(env)
+
+	// then
+	// ... it defaults on and an explicit 0 turns it off
+	require.NoError(t, err)
+	require.NoError(t, disabledErr)
+	assert.Equal(t, DefaultToolOutputBudgetTokens, cfg.ToolOutputBudgetTokens)
+	assert.Equal(t, 0, disabled.ToolOutputBudgetTokens)
+}
+
+func TestLoad_MCPServerNeedsAllowedChats(t *testing.T) {
+	// given
+	env := thinkingTestEnv(t)
+	env["MCP_SERVER_TOKEN"] = "tok"
+
+	// when
+	_, missing := Load(env)
+	env["MCP_ALLOWED_CHATS"] = "discord:123, 456"
+	_, malformed := Load(env)
+	env["MCP_ALLOWED_CHATS"] = "discord:123, whatsapp:27@s.whatsapp.net"
+	cfg, err := Load(env)
+
+	// then
+	assert.ErrorContains(t, missing, "MCP_ALLOWED_CHATS is required")
+	assert.ErrorContains(t, malformed, `"456" must be channel:chatID`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"discord:123", "whatsapp:27@s.whatsapp.net"}, cfg.MCPAllowedChats)
+	assert.Contains(t, cfg.Secrets(), "tok")
+}
+
+func TestLoad_DiscordVoice(t *testing.T) {
+	// given
+	env := validDiscordEnv()
+	env["DISCORD_VOICE_CHANNEL"] = "555"
+
+	// when
+	_, incomplete := Load(env)
+	env["DISCORD_VOICE_GUILD"] = "111"
+	env["DISCORD_VOICE_THREAD"] = "222"
+	_, noSTT := Load(env)
+	env["STT_PROVIDER"] = "openai"
+	cfg, err := Load(env)
+
+	// then
+	assert.ErrorContains(t, incomplete, "DISCORD_VOICE_GUILD and DISCORD_VOICE_THREAD are required")
+	assert.ErrorContains(t, noSTT, "STT_PROVIDER is required")
+	require.NoError(t, err)
+	assert.Equal(t, "555", cfg.VoiceChannel)
+	assert.Equal(t, DefaultVoiceWakeWord, cfg.VoiceWakeWord)
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Tenant is one team in TENANTS_FILE: the servers it owns, the API key its
+// sessions bill and the directories they may touch.
+type Tenant struct {
+	Name string `yaml:"name"`
+	// Guilds are "channel:guildID" entries, e.g. "discord:123".
+	Guilds      []string `yaml:"guilds"`
+	APIKey      string   `yaml:"api_key"`
+	AllowedDirs []string `yaml:"allowed_dirs"`
+	// Workspaces are one per AllowedDirs entry, labelled like
+	// ALLOWED_DIRS's; the first is the default.
+	Workspaces []Workspace `yaml:"-"`
+}
+
+// LoadTenants reads and validates TENANTS_FILE, a YAML list of tenants.
+// Each server may belong to one tenant only.
+func LoadTenants(path string) ([]Tenant, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading tenants file")
+	}
+	var tenants []Tenant
+	if err := yaml.Unmarshal(body, &tenants); err != nil {
+		return nil, errors.Wrap(err, "parsing tenants file")
+	}
+	if len(tenants) == 0 {
+		return nil, errors.Errorf("tenants file %s lists no tenants", path)
+	}
+
+	names := make(map[string]bool)
+	owners := make(map[string]string)
+	for i := range tenants {
+		t := &tenants[i]
+		t.Name = strings.TrimSpace(t.Name)
+		if t.Name == "" {
+			return nil, errors.Errorf("tenant %d has no name", i+1)
+		}
+		if names[t.Name] {
+			return nil, errors.Errorf("tenant %q is listed twice", t.Name)
+		}
+		names[t.Name] = true
+		if t.APIKey == "" {
+			return nil, errors.Errorf("tenant %q has no api_key", t.Name)
+		}
+		if len(t.Guilds) == 0 {
+			return nil, errors.Errorf("tenant %q has no guilds", t.Name)
+		}
+		for _, g := range t.Guilds {
+			if channel, id, ok := strings.Cut(g, ":"); !ok || channel == "" || id == "" {
+				return nil, errors.Errorf("tenant %q guild %q must be channel:guildID", t.Name, g)
+			}
+			if owner, ok := owners[g]; ok {
+				return nil, errors.Errorf("guild %s belongs to both %q and %q", g, owner, t.Name)
+			}
+			owners[g] = t.Name
+		}
+		if len(t.AllowedDirs) == 0 {
+			return nil, errors.Errorf("tenant %q has no allowed_dirs", t.Name)
+		}
+		for j, dir := range t.AllowedDirs {
+			if !filepath.IsAbs(dir) {
+				return nil, errors.Errorf("tenant %q allowed dir %q must be an absolute path", t.Name, dir)
+			}
+			t.AllowedDirs[j] = filepath.Clean(dir)
+		}
+		t.Workspaces = dirWorkspaces(t.AllowedDirs)
+	}
+	return tenants, nil
+}
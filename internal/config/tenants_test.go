@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTenants(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestLoadTenants(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	path := writeTenants(t, `
+- name: team-a
+  guilds: [discord:1, discord:2]
+  api_key: key-a
+  allowed_dirs: [/srv/team-a/api/, /srv/team-a/docs]
+- name: team-b
+  guilds: [discord:3]
+  api_key: key-b
+  allowed_dirs: [/srv/team-b]
+`)
+
+	// when
+	tenants, err := LoadTenants(path)
+
+	// then
+	// ... dirs are cleaned and become the tenant's workspaces
+	r.NoError(err)
+	r.Len(tenants, 2)
+	a.Equal("team-a", tenants[0].Name)
+	a.Equal([]string{"discord:1", "discord:2"}, tenants[0].Guilds)
+	a.Equal("key-a", tenants[0].APIKey)
+	a.Equal([]string{"/srv/team-a/api", "/srv/team-a/docs"}, tenants[0].AllowedDirs)
+	a.Equal([]Workspace{
+		{Label: "api", Path: "/srv/team-a/api", Default: true},
+		{Label: "docs", Path: "/srv/team-a/docs"},
+	}, tenants[0].Workspaces)
+	a.Equal([]Workspace{{Label: "team-b", Path: "/srv/team-b", Default: true}}, tenants[1].Workspaces)
+}
+
+func TestLoadTenants_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", `[]`, "no tenants"},
+		{"no name", `[{guilds: [discord:1], api_key: k, allowed_dirs: [/a]}]`, "tenant 1 has no name"},
+		{"duplicate name", `[{name: a, guilds: [discord:1], api_key: k, allowed_dirs: [/a]}, {name: a, guilds: [discord:2], api_key: k, allowed_dirs: [/b]}]`, `tenant "a" is listed twice`},
+		{"no key", `[{name: a, guilds: [discord:1], allowed_dirs: [/a]}]`, "no api_key"},
+		{"no guilds", `[{name: a, api_key: k, allowed_dirs: [/a]}]`, "no guilds"},
+		{"bad guild", `[{name: a, guilds: ["123"], api_key: k, allowed_dirs: [/a]}]`, "must be channel:guildID"},
+		{"shared guild", `[{name: a, guilds: [discord:1], api_key: k, allowed_dirs: [/a]}, {name: b, guilds: [discord:1], api_key: k, allowed_dirs: [/b]}]`, "belongs to both"},
+		{"no dirs", `[{name: a, guilds: [discord:1], api_key: k}]`, "no allowed_dirs"},
+		{"relative dir", `[{name: a, guilds: [discord:1], api_key: k, allowed_dirs: [srv]}]`, "must be an absolute path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTenants(writeTenants(t, tt.body))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
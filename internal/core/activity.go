@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxActivitySummary bounds the argument shown in a tool activity line.
+const maxActivitySummary = 80
+
+// FormatToolActivity renders one tool call for the updates channel, e.g.
+// "🔧 Bash: go test ./... (3.2s, ok)".
+func FormatToolActivity(name string, input ToolInput, elapsed time.Duration, isErr bool) string {
+	status := "ok"
+	if isErr {
+		status = "error"
+	}
+	secs := fmt.Sprintf("%.1fs", elapsed.Seconds())
+	if summary := summarizeToolInput(input); summary != "" {
+		return fmt.Sprintf("🔧 %s: %s (%s, %s)", name, summary, secs, status)
+	}
+	return fmt.Sprintf("🔧 %s (%s, %s)", name, secs, status)
+}
+
+// MirrorsToolActivity reports whether a tool's calls are worth mirroring.
+// send_update and react_emoji are already visible to the user.
+func MirrorsToolActivity(name string) bool {
+	return name != "send_update" && name != "react_emoji"
+}
+
+// summarizeToolInput picks the single most telling argument of a call.
+func summarizeToolInput(in ToolInput) string {
+	var s string
+	switch {
+	case in.Command != "":
+		s = in.Command
+	case in.FilePath != "":
+		s = in.FilePath
+	case in.URL != "":
+		s = in.URL
+		if in.Method != "" && in.Method != "GET" {
+			s = in.Method + " " + s
+		}
+	case in.Query != "":
+		s = in.Query
+	case in.Name != "" && in.Path != "":
+		s = in.Name + "/" + in.Path
+	case in.Name != "":
+		s = in.Name
+	case in.Path != "":
+		s = in.Path
+	case in.Directory != "":
+		s = in.Directory
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxActivitySummary {
+		s = string(r[:maxActivitySummary-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatToolActivity(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("🔧 Bash: go test ./... (3.2s, ok)",
+		FormatToolActivity("Bash", ToolInput{Command: "go test ./..."}, 3200*time.Millisecond, false))
+	a.Equal("🔧 Fetch: POST https://x.test (0.1s, error)",
+		FormatToolActivity("Fetch", ToolInput{URL: "https://x.test", Method: "POST"}, 100*time.Millisecond, true))
+	a.Equal("🔧 Mystery (0.0s, ok)", FormatToolActivity("Mystery", ToolInput{}, 0, false))
+}
+
+func TestFormatToolActivity_CollapsesAndTruncatesArgument(t *testing.T) {
+	a := assert.New(t)
+
+	got := FormatToolActivity("Bash", ToolInput{Command: "echo a\n\necho " + strings.Repeat("b", 200)}, time.Second, false)
+
+	a.True(strings.HasPrefix(got, "🔧 Bash: echo a echo bbb"), got)
+	a.Contains(got, "… (1.0s, ok)")
+	a.NotContains(got, "\n")
+}
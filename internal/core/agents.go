@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 )
@@ -29,6 +30,44 @@ func LoadAgentsContext(workDir string) string {
 	return "<agents_md>\n" + trimmed + "\n</agents_md>"
 }
 
+// ProjectContextFiles are read from a session's workDir when it is created,
+// in order.
+var ProjectContextFiles = []string{"CLAUDE.md", "README.md"}
+
+// MaxProjectFileBytes caps each project context file; longer files are cut
+// and the model is pointed at the rest.
+const MaxProjectFileBytes = 16 * 1024
+
+// LoadProjectContext reads ProjectContextFiles from workDir and returns
+// each non-empty one wrapped in a <project_file name="..."> tag, so API
+// sessions start with the same project context the CLI picks up. Returns
+// empty string if workDir is empty or none of the files exist.
+func LoadProjectContext(workDir string) string {
+	if workDir == "" {
+		return ""
+	}
+	var blocks []string
+	for _, name := range ProjectContextFiles {
+		body, err := os.ReadFile(filepath.Join(workDir, name))
+		if err != nil {
+			continue
+		}
+		text := strings.TrimSpace(string(body))
+		if text == "" {
+			continue
+		}
+		if len(text) > MaxProjectFileBytes {
+			cut := MaxProjectFileBytes
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + "\n[truncated; Read " + name + " for the rest]"
+		}
+		blocks = append(blocks, `<project_file name="`+name+`">`+"\n"+text+"\n</project_file>")
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
 // BootstrapAgentsMd seeds <workDir>/AGENTS.md from defaultPath when the file
 // is missing. Existing files are left untouched. A missing default or empty
 // path is a silent no-op so dev environments without the bundled default
@@ -46,6 +46,36 @@ func TestLoadAgentsContext_EmptyFile(t *testing.T) {
 	}
 }
 
+func TestLoadProjectContext_ReadsInOrderAndSkipsMissing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("  rules\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := LoadProjectContext(dir)
+	want := "<project_file name=\"CLAUDE.md\">\nrules\n</project_file>\n\n<project_file name=\"README.md\">\nreadme\n</project_file>"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got := LoadProjectContext(t.TempDir()); got != "" {
+		t.Fatalf("expected empty for dir without files, got %q", got)
+	}
+}
+
+func TestLoadProjectContext_TruncatesOnRuneBoundary(t *testing.T) {
+	dir := t.TempDir()
+	body := strings.Repeat("a", MaxProjectFileBytes-1) + "€tail"
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := LoadProjectContext(dir)
+	if !strings.Contains(got, strings.Repeat("a", MaxProjectFileBytes-1)+"\n[truncated; Read README.md for the rest]") {
+		t.Fatalf("expected truncation marker after last whole rune, got tail %q", got[len(got)-80:])
+	}
+}
+
 func TestBuildSystemPromptWithAgents_AppendsBoth(t *testing.T) {
 	base := "BASE"
 	agents := "<agents_md>X</agents_md>"
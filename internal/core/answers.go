@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Answer is a final response saved to its channel's answers index with
+// /pin-answer.
+type Answer struct {
+	At time.Time
+	// Question is the prompt the answer replied to, or "" when the answer
+	// was pinned from a message link.
+	Question string
+	Text     string
+	// TurnID names the turn that produced the answer for /feedback. The
+	// answers index doesn't keep it.
+	TurnID string
+}
+
+// latestAnswers remembers each session's last final response for
+// /pin-answer.
+type latestAnswers struct {
+	mu      sync.Mutex
+	answers map[SessionKey]Answer
+}
+
+func (l *latestAnswers) put(key SessionKey, a Answer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.answers == nil {
+		l.answers = make(map[SessionKey]Answer)
+	}
+	l.answers[key] = a
+}
+
+func (l *latestAnswers) get(key SessionKey) (Answer, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a, ok := l.answers[key]
+	return a, ok
+}
+
+// maxQuestionLen bounds the prompt kept with a pinned answer.
+const maxQuestionLen = 200
+
+// questionLine is prompt on one line, cut to maxQuestionLen.
+func questionLine(prompt string) string {
+	return truncateText(strings.Join(strings.Fields(prompt), " "), maxQuestionLen)
+}
+
+// SetAnswerIndex enables /pin-answer and /answers. Without one both report
+// they are unavailable.
+func (b *Bot) SetAnswerIndex(idx AnswerIndex) {
+	b.answers = idx
+}
+
+// cmdPinAnswer saves the session's latest answer, or the message a link
+// points to, to the channel's answers index. Without a link the platform
+// also pins the answer in the chat where it can.
+func (b *Bot) cmdPinAnswer(_ context.Context, in Inbound, args string) (string, error) {
+	if b.answers == nil {
+		return b.tr(in, "The answers index is not available."), nil
+	}
+	if in.ChannelID == "" {
+		return b.tr(in, "This chat cannot pin answers."), nil
+	}
+
+	var a Answer
+	if args == "" {
+		latest, ok := b.latest.get(in.SessionKey)
+		if !ok {
+			return b.tr(in, "There is no answer to pin yet."), nil
+		}
+		a = latest
+	} else {
+		f, ok := outboundAs[MessageFetcher](in.Reply)
+		if !ok {
+			return b.tr(in, "Use /pin-answer without arguments to pin the latest answer."), nil
+		}
+		text, isLink, err := f.FetchMessage(args)
+		if !isLink {
+			return b.tr(in, "Use /pin-answer without arguments to pin the latest answer."), nil
+		}
+		if err != nil {
+			return b.tr(in, "Could not read that message: %s", err), nil
+		}
+		a = Answer{At: time.Now(), Text: text}
+	}
+	if err := b.answers.AddAnswer(in.ChannelID, a); err != nil {
+		return "", err
+	}
+	if p, ok := outboundAs[AnswerPinner](in.Reply); ok && args == "" {
+		if err := p.PinLatest(); err != nil {
+			slog.Warn("pinning answer in chat", "key", string(in.SessionKey), "error", err)
+		}
+	}
+	return b.tr(in, "Answer pinned. Use /answers to list this chat's pinned answers."), nil
+}
+
+// cmdAnswers lists the channel's pinned answers, or shows one in full by
+// its number.
+func (b *Bot) cmdAnswers(_ context.Context, in Inbound, args string) (string, error) {
+	if b.answers == nil {
+		return b.tr(in, "The answers index is not available."), nil
+	}
+	if in.ChannelID == "" {
+		return b.tr(in, "This chat cannot pin answers."), nil
+	}
+	answers, err := b.answers.Answers(in.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	if len(answers) == 0 {
+		return b.tr(in, "No answers are pinned here. Use /pin-answer after a reply worth keeping."), nil
+	}
+
+	if args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n < 1 || n > len(answers) {
+			return b.tr(in, "Use /answers <number> with a number from 1 to %d.", len(answers)), nil
+		}
+		a := answers[n-1]
+		head := b.formatTime(in, a.At)
+		if a.Question != "" {
+			head += " · " + a.Question
+		}
+		return head + "\n\n" + a.Text, nil
+	}
+
+	lines := []string{b.tr(in, "Pinned answers:")}
+	for i, a := range answers {
+		title := a.Question
+		if title == "" {
+			title, _, _ = strings.Cut(a.Text, "\n")
+		}
+		lines = append(lines, strconv.Itoa(i+1)+". "+b.formatTime(in, a.At)+" · "+truncateText(title, 80))
+	}
+	lines = append(lines, b.tr(in, "Use /answers <number> to read one."))
+	return strings.Join(lines, "\n"), nil
+}
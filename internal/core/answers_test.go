@@ -0,0 +1,135 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAnswerIndex struct{ answers map[string][]Answer }
+
+func (s *stubAnswerIndex) AddAnswer(channelID string, a Answer) error {
+	s.answers[channelID] = append(s.answers[channelID], a)
+	return nil
+}
+
+func (s *stubAnswerIndex) Answers(channelID string) ([]Answer, error) {
+	return s.answers[channelID], nil
+}
+
+type pinningResponder struct {
+	fetchingResponder
+	pins int
+}
+
+func (p *pinningResponder) PinLatest() error {
+	p.pins++
+	return nil
+}
+
+func TestHandleInbound_PinAnswerSavesLatestAnswer(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a session that has answered one question
+	be := &stubBackend{id: "b", converseR: "Set GOFLAGS=-mod=mod."}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	idx := &stubAnswerIndex{answers: map[string][]Answer{}}
+	bot.SetAnswerIndex(idx)
+	out := &pinningResponder{}
+	in := Inbound{SessionKey: "k", ChannelID: "c:1", Reply: out}
+	in.Text = "why does the   build\nignore vendor?"
+	r.NoError(bot.HandleInbound(in))
+
+	// when
+	in.Text = "/pin-answer"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/answers"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/answers 1"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the answer is indexed with its question and pinned in the chat
+	r.Len(idx.answers["c:1"], 1)
+	a.Equal("why does the build ignore vendor?", idx.answers["c:1"][0].Question)
+	a.Equal("Set GOFLAGS=-mod=mod.", idx.answers["c:1"][0].Text)
+	a.Equal(1, out.pins)
+	r.Len(out.posted, 4)
+	a.Contains(out.posted[1], "Answer pinned")
+	a.Contains(out.posted[2], "1. ")
+	a.Contains(out.posted[2], "why does the build ignore vendor?")
+	a.Contains(out.posted[3], "Set GOFLAGS=-mod=mod.")
+}
+
+func TestHandleInbound_PinAnswerFromLink(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil), nil)
+	idx := &stubAnswerIndex{answers: map[string][]Answer{}}
+	bot.SetAnswerIndex(idx)
+	out := &pinningResponder{fetchingResponder: fetchingResponder{messages: map[string]string{"https://chat.example/m/1": "Restart the worker."}}}
+
+	// when
+	// ... an older answer is pinned by its link
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c:1", Text: "/pin-answer https://chat.example/m/1", Reply: out}))
+
+	// then
+	// ... it is indexed without a question and the platform pin is left alone
+	r.Len(idx.answers["c:1"], 1)
+	a.Equal("Restart the worker.", idx.answers["c:1"][0].Text)
+	a.Empty(idx.answers["c:1"][0].Question)
+	a.Zero(out.pins)
+}
+
+func TestHandleInbound_PinAnswerThroughFiltersAndMirror(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... the filter and mirror wrappers main always installs
+	be := &stubBackend{id: "b", converseR: "Set GOFLAGS=-mod=mod."}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.AddOutboundFilter(func(s string) string { return s })
+	bot.SetMirror(&recordingMirror{out: &stubResponder{}})
+	idx := &stubAnswerIndex{answers: map[string][]Answer{}}
+	bot.SetAnswerIndex(idx)
+	out := &pinningResponder{fetchingResponder: fetchingResponder{messages: map[string]string{"https://chat.example/m/1": "Restart the worker."}}}
+	in := Inbound{SessionKey: "k", ChannelID: "c:1", Reply: out, Text: "why does the build ignore vendor?"}
+	r.NoError(bot.HandleInbound(in))
+
+	// when
+	in.Text = "/pin-answer"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/pin-answer https://chat.example/m/1"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the latest answer is pinned in the chat and the link resolves
+	a.Equal(1, out.pins)
+	r.Len(idx.answers["c:1"], 2)
+	a.Equal("Restart the worker.", idx.answers["c:1"][1].Text)
+}
+
+func TestHandleInbound_PinAnswerWithoutAnswer(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil), nil)
+	idx := &stubAnswerIndex{answers: map[string][]Answer{}}
+	bot.SetAnswerIndex(idx)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c:1", Text: "/pin-answer", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c:1", Text: "/answers", Reply: out}))
+
+	// then
+	a.Empty(idx.answers)
+	a.Equal([]string{"There is no answer to pin yet.", "No answers are pinned here. Use /pin-answer after a reply worth keeping."}, out.posted)
+}
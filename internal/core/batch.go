@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Batcher runs one-off prompts through a provider's batch API, which
+// answers within hours instead of seconds at about half the price
+// (api.Batches). deliver is called once, from another goroutine and never
+// before Submit returns, with the answer or the reason there is none.
+type Batcher interface {
+	Submit(ctx context.Context, prompt string, deliver func(answer string, err error)) (id string, err error)
+}
+
+// batchJob is a submitted /batch prompt whose answer hasn't arrived.
+type batchJob struct {
+	id        string
+	prompt    string
+	submitted time.Time
+}
+
+// batchJobs tracks pending jobs per session for /batch to list.
+type batchJobs struct {
+	mu   sync.Mutex
+	jobs map[SessionKey][]batchJob
+}
+
+func (j *batchJobs) add(key SessionKey, job batchJob) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.jobs == nil {
+		j.jobs = make(map[SessionKey][]batchJob)
+	}
+	j.jobs[key] = append(j.jobs[key], job)
+}
+
+func (j *batchJobs) remove(key SessionKey, id string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jobs := j.jobs[key]
+	for i, job := range jobs {
+		if job.id == id {
+			jobs = append(jobs[:i:i], jobs[i+1:]...)
+			break
+		}
+	}
+	if len(jobs) == 0 {
+		delete(j.jobs, key)
+		return
+	}
+	j.jobs[key] = jobs
+}
+
+func (j *batchJobs) pending(key SessionKey) []batchJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]batchJob(nil), j.jobs[key]...)
+}
+
+// SetBatcher enables /batch. Without one /batch says batch jobs are
+// unavailable.
+func (b *Bot) SetBatcher(bt Batcher) {
+	b.batcher = bt
+}
+
+// cmdBatch submits args as a batch job whose answer is posted to this chat
+// when it is ready, or lists the chat's pending jobs. The job sees only
+// the prompt: no history, no tools. Tenants' chats can't use it, since the
+// batcher bills the base API key.
+func (b *Bot) cmdBatch(ctx context.Context, in Inbound, args string) (string, error) {
+	if b.batcher == nil || b.tenantFor(in) != b.base {
+		return b.tr(in, "Batch jobs are not available."), nil
+	}
+	if args == "" {
+		jobs := b.batches.pending(in.SessionKey)
+		if len(jobs) == 0 {
+			return b.tr(in, "No batch jobs are pending. Use /batch <prompt> to submit one."), nil
+		}
+		lines := []string{b.tr(in, "Pending batch jobs:")}
+		for _, job := range jobs {
+			lines = append(lines, "- "+job.id+" ("+time.Since(job.submitted).Truncate(time.Minute).String()+"): "+truncateText(job.prompt, 60))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	reply := in.Reply
+	key := in.SessionKey
+	// mu holds deliver back until the job is recorded under its id.
+	var (
+		mu  sync.Mutex
+		id  string
+		err error
+	)
+	mu.Lock()
+	id, err = b.batcher.Submit(ctx, args, func(answer string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		b.batches.remove(key, id)
+		if reply == nil {
+			return
+		}
+		text := b.tr(in, "Batch job %s is done:", id) + "\n\n" + answer
+		if err != nil {
+			msg, errID := b.Lang(in).UserError(err)
+			slog.Error("batch job failed", "key", string(key), "job", id, "error_id", errID, "error", err)
+			text = b.tr(in, "Batch job %s failed:", id) + " " + msg
+		}
+		if err := reply.PostResponse(text); err != nil {
+			slog.Warn("posting batch answer", "key", string(key), "job", id, "error", err)
+		}
+	})
+	if err == nil {
+		b.batches.add(key, batchJob{id: id, prompt: args, submitted: time.Now()})
+	}
+	mu.Unlock()
+	if err != nil {
+		return "", MarkError(ErrUnavailable, err)
+	}
+	return b.tr(in, "Submitted batch job %s. The answer will be posted here when it is ready, usually within an hour.", id), nil
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubBatcher struct {
+	prompts  []string
+	delivers []func(string, error)
+}
+
+func (s *stubBatcher) Submit(_ context.Context, prompt string, deliver func(string, error)) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	s.delivers = append(s.delivers, deliver)
+	return "msgbatch_1", nil
+}
+
+func TestBatch_SubmitsAndPostsAnswerWhenReady(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{} }}, nil), nil)
+	batcher := &stubBatcher{}
+	bot.SetBatcher(batcher)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/batch summarise last week's incidents", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/batch", Reply: out}))
+	batcher.delivers[0]("All quiet.", nil)
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/batch", Reply: out}))
+
+	// then
+	a.Equal([]string{"summarise last week's incidents"}, batcher.prompts)
+	r.Len(out.posted, 4)
+	a.Contains(out.posted[0], "Submitted batch job msgbatch_1.")
+	a.Contains(out.posted[1], "- msgbatch_1 (0s): summarise last week's incidents")
+	a.Equal("Batch job msgbatch_1 is done:\n\nAll quiet.", out.posted[2])
+	// ... the delivered job is no longer pending
+	a.Contains(out.posted[3], "No batch jobs are pending.")
+}
+
+func TestBatch_ReportsFailedJob(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{} }}, nil), nil)
+	batcher := &stubBatcher{}
+	bot.SetBatcher(batcher)
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/batch hello", Reply: out}))
+
+	// when
+	batcher.delivers[0]("", MarkError(ErrUnavailable, errors.New("batch expired")))
+
+	// then
+	r.Len(out.posted, 2)
+	a.Regexp(`^Batch job msgbatch_1 failed: The model backend is unavailable right now\.`, out.posted[1])
+}
+
+func TestBatch_Unavailable(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{} }}, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/batch hello", Reply: out}))
+
+	// then
+	a.Equal([]string{"Batch jobs are not available."}, out.posted)
+}
@@ -2,23 +2,186 @@ package core
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultMaxConcurrentSessions is how many sessions may run turns at once.
+const DefaultMaxConcurrentSessions = 4
+
 type Bot struct {
-	sessions        *SessionManager
-	perms           PermissionChecker
-	mu              sync.RWMutex
-	activeKey       SessionKey
-	activeCaps      Capabilities
+	// base serves chats outside every tenant's servers.
+	base            *tenant
+	tenants         map[string]*tenant // by guild ID
+	speaker         Speaker
+	sharer          Sharer
+	scaffolder      SkillScaffolder
+	pinner          ContextPinner
+	answers         AnswerIndex
+	latest          latestAnswers
+	feedback        FeedbackRecorder
+	askFeedback     bool
+	personas        PersonaStore
+	webCache        ResultCache
+	cloner          Cloner
+	scratchRoot     string
+	scratchTTL      time.Duration
 	converseTimeout time.Duration
+	filters         []TextFilter
+	channels        map[string]ChatOpener
+	composer        composer
+	links           linkCodes
+	turnTokenLimit  int64
+	turns           turnCache
+	prompts         lastPrompts
+	chats           replyChats
+	admins          []string
+	paused          pausedChannels
+	logSecrets      SecretScrubber
+	turnTimes       turnTimer
+	held            heldTurns
+	hooks           Hooks
+	langs           langPrefs
+	guilds          guildPrefs
+	zones           timezones
+	releases        Releases
+	mirror          Mirror
+	batcher         Batcher
+	batches         batchJobs
+	started         time.Time
+
+	// sem bounds how many sessions run turns at once. A session holds one
+	// slot while any of its inbounds are in flight, so steering messages
+	// for a busy session never queue behind the cap.
+	sem    chan struct{}
+	slotMu sync.Mutex
+	slots  map[SessionKey]*sessionSlot
+	// working and queued count sessions holding and waiting for a sem
+	// slot, for Presence.
+	working atomic.Int32
+	queued  atomic.Int32
+}
+
+// sessionSlot tracks a session's in-flight inbounds. refs counts goroutines
+// holding the struct (so it can be dropped from Bot.slots); active counts
+// inbounds that have passed the semaphore.
+type sessionSlot struct {
+	mu     sync.Mutex
+	refs   int
+	active int
 }
 
 // NewBot creates a bot with the given dependencies
 func NewBot(sessions *SessionManager, perms PermissionChecker) *Bot {
 	return &Bot{
-		sessions:        sessions,
-		perms:           perms,
+		base:            &tenant{sessions: sessions, perms: perms},
+		tenants:         make(map[string]*tenant),
 		converseTimeout: 10 * time.Minute,
+		sem:             make(chan struct{}, DefaultMaxConcurrentSessions),
+		slots:           make(map[SessionKey]*sessionSlot),
+		composer:        composer{buffers: make(map[SessionKey]*composeBuffer)},
+		links:           linkCodes{codes: make(map[string]linkCode), now: time.Now},
+		held:            heldTurns{turns: make(map[SessionKey]Inbound)},
+		langs:           langPrefs{users: make(map[string]Lang), channels: make(map[string]Lang)},
+		zones:           timezones{users: make(map[string]*time.Location)},
+		started:         time.Now(),
+	}
+}
+
+// SetMaxConcurrentSessions changes the global concurrency cap. n <= 0 keeps
+// the default. Call before the first inbound is handled.
+func (b *Bot) SetMaxConcurrentSessions(n int) {
+	if n <= 0 {
+		n = DefaultMaxConcurrentSessions
+	}
+	b.sem = make(chan struct{}, n)
+}
+
+// SetReadOnlyChecker sets the checker used by sessions in /readonly mode.
+// Without one, /readonly refuses to turn on. Call before the first inbound
+// is handled.
+func (b *Bot) SetReadOnlyChecker(pc PermissionChecker) {
+	b.base.readOnlyPerms = pc
+}
+
+// SetSpeaker enables /speak. Without one the command reports it is
+// unavailable.
+func (b *Bot) SetSpeaker(s Speaker) {
+	b.speaker = s
+}
+
+// SetSharer enables /share. Without one the command reports it is
+// unavailable.
+func (b *Bot) SetSharer(s Sharer) {
+	b.sharer = s
+}
+
+// SetSkillScaffolder enables /skill new. Without one the command reports it
+// is unavailable.
+func (b *Bot) SetSkillScaffolder(s SkillScaffolder) {
+	b.scaffolder = s
+}
+
+// SetPinner enables /pin-context. Without one the command reports it is
+// unavailable.
+func (b *Bot) SetPinner(p ContextPinner) {
+	b.pinner = p
+}
+
+// SetWebCache enables /cache. Without one the command reports caching is
+// off.
+func (b *Bot) SetWebCache(c ResultCache) {
+	b.webCache = c
+}
+
+// SetPersonas enables /persona. Without a store the command reports it is
+// unavailable.
+func (b *Bot) SetPersonas(s PersonaStore) {
+	b.personas = s
+}
+
+// AddOutboundFilter registers a TextFilter applied to every response and
+// progress update the bot sends. Call before the first inbound is handled.
+func (b *Bot) AddOutboundFilter(f TextFilter) {
+	b.filters = append(b.filters, f)
+}
+
+// acquireSlot blocks until key may run, taking a global slot if key has no
+// other inbound in flight. The returned func releases it.
+func (b *Bot) acquireSlot(key SessionKey) func() {
+	b.slotMu.Lock()
+	s, ok := b.slots[key]
+	if !ok {
+		s = &sessionSlot{}
+		b.slots[key] = s
+	}
+	s.refs++
+	b.slotMu.Unlock()
+
+	s.mu.Lock()
+	if s.active == 0 {
+		b.queued.Add(1)
+		b.sem <- struct{}{}
+		b.queued.Add(-1)
+		b.working.Add(1)
+	}
+	s.active++
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		s.active--
+		if s.active == 0 {
+			<-b.sem
+			b.working.Add(-1)
+		}
+		s.mu.Unlock()
+
+		b.slotMu.Lock()
+		s.refs--
+		if s.refs == 0 {
+			delete(b.slots, key)
+		}
+		b.slotMu.Unlock()
 	}
 }
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedBackend blocks every Converse until release is closed, announcing
+// each entry on entered.
+type gatedBackend struct {
+	id      string
+	entered chan string
+	release chan struct{}
+}
+
+func (g *gatedBackend) Converse(_ context.Context, in Inbound, _ Outbound, _ PermissionChecker) (string, error) {
+	g.entered <- in.Text
+	<-g.release
+	return "", nil
+}
+func (g *gatedBackend) SessionID() string { return g.id }
+func (g *gatedBackend) Close() error      { return nil }
+
+func newGatedBot(maxConcurrent int) (*Bot, chan string, chan struct{}) {
+	entered := make(chan string, 8)
+	release := make(chan struct{})
+	f := &stubFactory{next: func() Backend {
+		return &gatedBackend{id: "g", entered: entered, release: release}
+	}}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.SetMaxConcurrentSessions(maxConcurrent)
+	return bot, entered, release
+}
+
+func waitEntered(t *testing.T, entered chan string) string {
+	t.Helper()
+	select {
+	case text := <-entered:
+		return text
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for Converse")
+		return ""
+	}
+}
+
+func TestHandleInbound_DifferentKeysRunInParallel(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a bot allowing two concurrent sessions
+	bot, entered, release := newGatedBot(2)
+	var wg sync.WaitGroup
+
+	// when
+	// ... two keys send a message at the same time
+	for _, key := range []SessionKey{"kA", "kB"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = bot.HandleInbound(Inbound{SessionKey: key, Text: string(key)})
+		}()
+	}
+
+	// then
+	// ... both turns are in Converse before either finishes
+	got := []string{waitEntered(t, entered), waitEntered(t, entered)}
+	a.ElementsMatch([]string{"kA", "kB"}, got)
+	close(release)
+	wg.Wait()
+}
+
+func TestHandleInbound_ConcurrencyCapQueuesOtherSessions(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a bot allowing one concurrent session, busy with kA
+	bot, entered, release := newGatedBot(1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a1"})
+	}()
+	a.Equal("a1", waitEntered(t, entered))
+
+	// when
+	// ... a steering message for kA and a message for kB arrive
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a2"})
+	}()
+	a.Equal("a2", waitEntered(t, entered))
+	go func() {
+		defer wg.Done()
+		_ = bot.HandleInbound(Inbound{SessionKey: "kB", Text: "b"})
+	}()
+
+	// then
+	// ... kA's second message shares its slot, kB waits until kA drains
+	select {
+	case text := <-entered:
+		t.Fatalf("kB should be held by the cap, but %q entered Converse", text)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	a.Equal("b", waitEntered(t, entered))
+	wg.Wait()
+}
+
+func TestSetMaxConcurrentSessions_NonPositiveUsesDefault(t *testing.T) {
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{}, nil), nil)
+
+	// when
+	bot.SetMaxConcurrentSessions(0)
+
+	// then
+	r.Equal(DefaultMaxConcurrentSessions, cap(bot.sem))
+}
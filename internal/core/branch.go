@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoBranch is returned by a Brancher whose session has no branch.
+var ErrNoBranch = errors.New("session has no branch")
+
+// cmdApply merges the session's branch into the branch it started from.
+func (b *Bot) cmdApply(ctx context.Context, in Inbound, _ string) (string, error) {
+	return b.finishBranch(ctx, in, "apply", Brancher.ApplyBranch, "Merged %s into %s.")
+}
+
+// cmdDiscard deletes the session's branch and every change on it.
+func (b *Bot) cmdDiscard(ctx context.Context, in Inbound, _ string) (string, error) {
+	return b.finishBranch(ctx, in, "discard", Brancher.DiscardBranch, "Deleted %s and its changes; back on %s.")
+}
+
+func (b *Bot) finishBranch(ctx context.Context, in Inbound, verb string, finish func(Brancher, context.Context) (string, string, error), done string) (string, error) {
+	t := b.tenantFor(in)
+	backend, err := t.sessions.GetSession(in.SessionKey)
+	if err != nil {
+		return b.tr(in, "This session has no branch."), nil
+	}
+	br, ok := backend.(Brancher)
+	if !ok {
+		return b.tr(in, "This session has no branch."), nil
+	}
+	if t.sessions.Settings(in.SessionKey).ReadOnly {
+		return b.tr(in, "/%s is not allowed in read-only mode.", verb), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.converseTimeout)
+	defer cancel()
+	branch, base, err := finish(br, ctx)
+	if errors.Is(err, ErrNoBranch) {
+		return b.tr(in, "This session has no branch."), nil
+	}
+	if err != nil {
+		return b.tr(in, "/%s failed: %s", verb, err), nil
+	}
+	return b.tr(in, done, branch, base), nil
+}
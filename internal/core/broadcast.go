@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// replyChat is where a session's replies last went.
+type replyChat struct {
+	out      Outbound
+	sessions *SessionManager
+}
+
+// replyChats remembers each session's chat so Broadcast can reach every
+// chat with a live session.
+type replyChats struct {
+	mu    sync.Mutex
+	chats map[SessionKey]replyChat
+}
+
+func (r *replyChats) put(key SessionKey, c replyChat) {
+	if c.out == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.chats == nil {
+		r.chats = make(map[SessionKey]replyChat)
+	}
+	r.chats[key] = c
+}
+
+// live returns the chats whose session still exists, dropping the rest.
+func (r *replyChats) live() map[SessionKey]Outbound {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	live := make(map[SessionKey]Outbound, len(r.chats))
+	for key, c := range r.chats {
+		if _, ok := c.sessions.Info(key); !ok {
+			delete(r.chats, key)
+			continue
+		}
+		live[key] = c.out
+	}
+	return live
+}
+
+// SetAdmins names the users, e.g. "discord:123", who may run operator
+// commands such as /broadcast.
+func (b *Bot) SetAdmins(userIDs []string) {
+	b.admins = userIDs
+}
+
+func (b *Bot) isAdmin(in Inbound) bool {
+	return in.UserID != "" && slices.Contains(b.admins, in.UserID)
+}
+
+// broadcastPrefix marks an announcement apart from the bot's answers.
+const broadcastPrefix = "📢 "
+
+// Broadcast posts text to every chat with a live session, in every
+// tenant, through the same filters as the bot's replies. It reports how
+// many chats got it and how many posts failed.
+func (b *Bot) Broadcast(text string) (sent, failed int) {
+	for key, out := range b.chats.live() {
+		if err := out.PostResponse(broadcastPrefix + text); err != nil {
+			slog.Warn("broadcasting", "key", string(key), "error", err)
+			failed++
+			continue
+		}
+		sent++
+	}
+	slog.Info("broadcast sent", "sent", sent, "failed", failed)
+	return sent, failed
+}
+
+// cmdBroadcast announces args to every active chat, e.g. before a
+// restart. Only admins may run it.
+func (b *Bot) cmdBroadcast(_ context.Context, in Inbound, args string) (string, error) {
+	if !b.isAdmin(in) {
+		return b.tr(in, "Only operators can broadcast."), nil
+	}
+	if args == "" {
+		return b.tr(in, "Use /broadcast <message> to post an announcement to every active chat."), nil
+	}
+	sent, failed := b.Broadcast(args)
+	if failed > 0 {
+		return b.tr(in, "Broadcast sent to %d chats; %d failed.", sent, failed), nil
+	}
+	return b.tr(in, "Broadcast sent to %d chats.", sent), nil
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_BroadcastReachesActiveChats(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... two chats with sessions and one that has only run a command
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b", converseR: "ok"} }}, nil), nil)
+	bot.SetAdmins([]string{"discord:op"})
+	first, second, idle := &stubResponder{}, &stubResponder{}, &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "discord:op", Text: "hi", Reply: first}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k2", UserID: "whatsapp:u", Text: "hi", Reply: second}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k3", UserID: "discord:u", Text: "/status", Reply: idle}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "discord:op", Text: "/broadcast Restarting at 18:00 UTC.", Reply: first}))
+
+	// then
+	// ... both sessions' chats get the announcement and the operator a count
+	a.Equal([]string{"ok", "📢 Restarting at 18:00 UTC.", "Broadcast sent to 2 chats."}, first.posted)
+	a.Equal([]string{"ok", "📢 Restarting at 18:00 UTC."}, second.posted)
+	a.Len(idle.posted, 1)
+}
+
+func TestHandleInbound_BroadcastNeedsAdmin(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b", converseR: "ok"} }}, nil), nil)
+	bot.SetAdmins([]string{"discord:op"})
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "discord:u", Text: "hi", Reply: out}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "discord:u", Text: "/broadcast down for maintenance", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "discord:op", Text: "/broadcast", Reply: out}))
+
+	// then
+	a.Equal([]string{
+		"ok",
+		"Only operators can broadcast.",
+		"Use /broadcast <message> to post an announcement to every active chat.",
+	}, out.posted)
+}
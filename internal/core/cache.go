@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+// cmdCache reports how many Fetch and WebSearch results are cached, or
+// clears them with "clear".
+func (b *Bot) cmdCache(_ context.Context, in Inbound, args string) (string, error) {
+	if b.webCache == nil {
+		return b.tr(in, "The web cache is off."), nil
+	}
+	switch strings.ToLower(args) {
+	case "":
+		n, err := b.webCache.Len()
+		if err != nil {
+			return "", err
+		}
+		return b.tr(in, "%d cached web results. Use /cache clear to drop them.", n), nil
+	case "clear":
+		n, err := b.webCache.Clear()
+		if err != nil {
+			return "", err
+		}
+		return b.tr(in, "Cleared %d cached web results.", n), nil
+	}
+	return b.tr(in, "Unknown option %q. Use /cache [clear].", args), nil
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCache struct{ n int }
+
+func (c *stubCache) Len() (int, error) { return c.n, nil }
+
+func (c *stubCache) Clear() (int, error) {
+	n := c.n
+	c.n = 0
+	return n, nil
+}
+
+func TestHandleInbound_CacheCommand(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: text, Reply: out}))
+	}
+
+	// when
+	send("/cache")
+	bot.SetWebCache(&stubCache{n: 4})
+	send("/cache")
+	send("/cache clear")
+	send("/cache purge")
+
+	// then
+	a.Equal([]string{
+		"The web cache is off.",
+		"4 cached web results. Use /cache clear to drop them.",
+		"Cleared 4 cached web results.",
+		`Unknown option "purge". Use /cache [clear].`,
+	}, out.posted)
+}
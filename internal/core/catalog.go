@@ -0,0 +1,564 @@
+package core
+
+// catalog maps English text to its translations. Keys are the exact format
+// strings passed to Lang.T; translations keep their verbs in the same order.
+var catalog = map[Lang]map[string]string{
+	LangSpanish: {
+		"Something went wrong while handling that message.":                                       "Algo salió mal al procesar ese mensaje.",
+		"Something went wrong while handling that message, so this conversation will start over.": "Algo salió mal al procesar ese mensaje, así que esta conversación empezará de nuevo.",
+		"The model backend is unavailable right now. Try again in a few minutes.":                 "El modelo no está disponible ahora mismo. Inténtalo de nuevo en unos minutos.",
+		"That isn't permitted.":                                             "Eso no está permitido.",
+		"That took too long and was stopped.":                               "Eso tardó demasiado y se detuvo.",
+		"The model is rate limited right now. Wait a minute and try again.": "El modelo está limitado ahora mismo. Espera un minuto y vuelve a intentarlo.",
+		"%s (error ID %s)":                                                  "%s (ID de error %s)",
+
+		"Verbosity is %s. Use /verbosity quiet|tools|thinking.":      "El detalle es %s. Usa /verbosity quiet|tools|thinking.",
+		"Unknown verbosity %q. Use /verbosity quiet|tools|thinking.": "Nivel de detalle desconocido %q. Usa /verbosity quiet|tools|thinking.",
+		"Verbosity set to %s.": "Detalle establecido en %s.",
+		"Read-only mode is on. Use /readonly off to allow writes.":                                            "El modo de solo lectura está activado. Usa /readonly off para permitir escrituras.",
+		"Read-only mode is off. Use /readonly on to block writes.":                                            "El modo de solo lectura está desactivado. Usa /readonly on para bloquear escrituras.",
+		"Read-only mode is not available.":                                                                    "El modo de solo lectura no está disponible.",
+		"Unknown option %q. Use /readonly on|off.":                                                            "Opción desconocida %q. Usa /readonly on|off.",
+		"Read-only mode on: tools can read and search but not write, run commands or send mutating requests.": "Modo de solo lectura activado: las herramientas pueden leer y buscar, pero no escribir, ejecutar comandos ni enviar peticiones que modifiquen nada.",
+		"Read-only mode off: writes are enabled.":                                                             "Modo de solo lectura desactivado: las escrituras están permitidas.",
+		"This session's workspace is read-only. Use /new-session with another workspace to write.":            "El espacio de trabajo de esta sesión es de solo lectura. Usa /new-session con otro espacio de trabajo para escribir.",
+		"Speech is on. Use /speak off to stop audio replies.":                                                 "La voz está activada. Usa /speak off para dejar de recibir respuestas en audio.",
+		"Speech is off. Use /speak on to also get replies as audio.":                                          "La voz está desactivada. Usa /speak on para recibir también las respuestas en audio.",
+		"Speech is not available here.":                                                                       "La voz no está disponible aquí.",
+		"Unknown option %q. Use /speak on|off.":                                                               "Opción desconocida %q. Usa /speak on|off.",
+		"Speech on: replies will also be sent as audio.":                                                      "Voz activada: las respuestas también se enviarán en audio.",
+		"Speech off.":                    "Voz desactivada.",
+		"Sharing is not available.":      "Compartir no está disponible.",
+		"Nothing to share yet.":          "Todavía no hay nada que compartir.",
+		"This session cannot be shared.": "Esta sesión no se puede compartir.",
+		"A reply is still in progress. Use /share once it finishes.": "Todavía hay una respuesta en curso. Usa /share cuando termine.",
+		"Transcript shared until %s: %s":                             "Transcripción compartida hasta %s: %s",
+
+		"Send /link-session %s from the other chat within %d minutes to continue this conversation there.": "Envía /link-session %s desde el otro chat en menos de %d minutos para continuar allí esta conversación.",
+		"This chat is not linked.":                                                                "Este chat no está vinculado.",
+		"Unlinked: this chat will start a new conversation.":                                      "Desvinculado: este chat empezará una conversación nueva.",
+		"That link code is invalid or has expired.":                                               "Ese código de vínculo no es válido o ha caducado.",
+		"That code is for this chat. Send it from the other one.":                                 "Ese código es de este chat. Envíalo desde el otro.",
+		"That conversation has ended. Start a new link from the other chat.":                      "Esa conversación ha terminado. Inicia un vínculo nuevo desde el otro chat.",
+		"Linked: this chat now continues the same conversation. Use /link-session off to detach.": "Vinculado: este chat continúa ahora la misma conversación. Usa /link-session off para desvincularlo.",
+
+		"This message would send about %s input tokens to the model (limit %s). Send /confirm to go ahead, or anything else to drop it.": "Este mensaje enviaría unos %s tokens de entrada al modelo (límite %s). Envía /confirm para continuar o cualquier otra cosa para descartarlo.",
+		"Nothing is waiting for confirmation.": "No hay nada pendiente de confirmación.",
+
+		skillUsage:                          "Usa /skill new <nombre> [descripción]. Con una descripción, también redacto las instrucciones.",
+		"Creating skills is not available.": "No se pueden crear habilidades.",
+		"Invalid skill name %q: use lowercase letters, digits and single hyphens.": "Nombre de habilidad no válido %q: usa minúsculas, dígitos y guiones simples.",
+		"Could not create skill %s: %s":                                            "No se pudo crear la habilidad %s: %s",
+		"Created skill %s at %s with drafted instructions, plus scripts/example.sh and references/notes.md. Review it before relying on it; new sessions will list it.":   "Habilidad %s creada en %s con instrucciones redactadas, además de scripts/example.sh y references/notes.md. Revísala antes de confiar en ella; las sesiones nuevas la mostrarán.",
+		"Created skill %s at %s from the template, plus scripts/example.sh and references/notes.md. Fill in its description and instructions; new sessions will list it.": "Habilidad %s creada en %s a partir de la plantilla, además de scripts/example.sh y references/notes.md. Completa su descripción e instrucciones; las sesiones nuevas la mostrarán.",
+
+		"%s (default)":            "%s (predeterminado)",
+		"%s (default, read-only)": "%s (predeterminado, solo lectura)",
+		"%s (read-only)":          "%s (solo lectura)",
+		"No workspaces are configured. Use /new-session without a name.": "No hay espacios de trabajo configurados. Usa /new-session sin nombre.",
+		"Started a new session.":                                                                    "Nueva sesión iniciada.",
+		"Unknown workspace %q. Workspaces: %s.":                                                     "Espacio de trabajo desconocido %q. Espacios de trabajo: %s.",
+		"Workspace %s is read-only, but read-only mode is not available.":                           "El espacio de trabajo %s es de solo lectura, pero el modo de solo lectura no está disponible.",
+		"Started a new read-only session in %s (%s).":                                               "Nueva sesión de solo lectura iniciada en %s (%s).",
+		"Started a new session in %s (%s).":                                                         "Nueva sesión iniciada en %s (%s).",
+		"Cloning is not available.":                                                                 "Clonar no está disponible.",
+		"Use /clone <https-git-url>.":                                                               "Usa /clone <url-git-https>.",
+		"Cloning is not allowed in read-only mode.":                                                 "No se permite clonar en modo de solo lectura.",
+		"Could not clone %s: %s":                                                                    "No se pudo clonar %s: %s",
+		"Cloned %s into %s and started a new session there.":                                        "%s clonado en %s; se inició allí una nueva sesión.",
+		"Already cloned %s into %s; started a new session there.":                                   "%s ya estaba clonado en %s; se inició allí una nueva sesión.",
+		"Use /new-session %s to come back to it.":                                                   "Usa /new-session %s para volver a él.",
+		"Scratch sessions are not available.":                                                       "Las sesiones temporales no están disponibles.",
+		"Use /scratch without arguments.":                                                           "Usa /scratch sin argumentos.",
+		"Scratch sessions are not allowed in read-only mode.":                                       "Las sesiones temporales no se permiten en modo de solo lectura.",
+		"Started a scratch session in %s. It is deleted when the session ends.":                     "Sesión temporal iniciada en %s. Se borra cuando termina la sesión.",
+		"Started a scratch session in %s. It is deleted when the session ends or after %d minutes.": "Sesión temporal iniciada en %s. Se borra cuando termina la sesión o tras %d minutos.",
+
+		"This session has no branch.":             "Esta sesión no tiene rama.",
+		"/%s is not allowed in read-only mode.":   "/%s no está permitido en modo de solo lectura.",
+		"/%s failed: %s":                          "/%s falló: %s",
+		"Merged %s into %s.":                      "%s fusionada en %s.",
+		"Deleted %s and its changes; back on %s.": "%s y sus cambios eliminados; de vuelta en %s.",
+
+		"Composing. Send the rest of your prompt, then !end to submit or !cancel to discard.": "Redactando. Envía el resto de tu mensaje y luego !end para enviarlo o !cancel para descartarlo.",
+		"Compose discarded.": "Redacción descartada.",
+		"Nothing to submit.": "No hay nada que enviar.",
+		"Compose buffer is full. Send !end to submit what you have.": "El búfer de redacción está lleno. Envía !end para enviar lo que tienes.",
+
+		"Language is %s. Use /language %s [channel].":                  "El idioma es %s. Usa /language %s [channel].",
+		"Unknown language %q. Use /language %s [channel].":             "Idioma desconocido %q. Usa /language %s [channel].",
+		"Unknown option %q. Use /language %s [channel].":               "Opción desconocida %q. Usa /language %s [channel].",
+		"This chat cannot tell users apart. Use /language %s channel.": "Este chat no distingue entre usuarios. Usa /language %s channel.",
+		"Language set to %s for you.":                                  "Idioma establecido en %s para ti.",
+		"This chat cannot set a channel language.":                     "Este chat no puede fijar un idioma de canal.",
+		"Language set to %s for this chat.":                            "Idioma establecido en %s para este chat.",
+
+		"Your time zone is %s. Use /timezone <zone>, e.g. /timezone Europe/Berlin.":         "Tu zona horaria es %s. Usa /timezone <zona>, p. ej. /timezone Europe/Madrid.",
+		"This chat cannot tell users apart, so times stay in UTC.":                          "Este chat no distingue entre usuarios, así que las horas se quedan en UTC.",
+		"Unknown time zone %q. Use an IANA name such as Europe/Berlin or America/New_York.": "Zona horaria desconocida %q. Usa un nombre IANA como Europe/Madrid o America/Mexico_City.",
+		"Time zone set to %s. It is now %s.":                                                "Zona horaria establecida en %s. Ahora son las %s.",
+
+		"Use /rename-session <name>, or /rename-session - to clear it. Session ID: %s.": "Usa /rename-session <nombre>, o /rename-session - para borrarlo. ID de sesión: %s.",
+		"Session names can be at most %d characters.":                                   "Los nombres de sesión pueden tener como máximo %d caracteres.",
+		"Session name cleared.":  "Nombre de sesión borrado.",
+		"Session renamed to %q.": "Sesión renombrada a %q.",
+		"Invalid tag %q: use up to 32 lowercase letters, digits, - and _.":                       "Etiqueta no válida %q: usa hasta 32 minúsculas, dígitos, - y _.",
+		"Could not tag the session: %s":                                                          "No se pudo etiquetar la sesión: %s",
+		"This session has no tags. Use /tag-session <tag>... to add some, -<tag> to remove one.": "Esta sesión no tiene etiquetas. Usa /tag-session <etiqueta>... para añadir algunas, -<etiqueta> para quitar una.",
+		"Tags: %s": "Etiquetas: %s",
+
+		"Pinned context is not available.":                                 "El contexto fijado no está disponible.",
+		"This chat cannot pin context.":                                    "En este chat no se puede fijar contexto.",
+		"Nothing is pinned here. Use /pin-context <text or message link>.": "No hay nada fijado aquí. Usa /pin-context <texto o enlace a un mensaje>.",
+		"Pinned for this chat:\n%s\nUse /pin-context clear to remove it.":  "Fijado en este chat:\n%s\nUsa /pin-context clear para quitarlo.",
+		"Pinned context cleared.":                                          "Contexto fijado eliminado.",
+		"Could not read that message: %s":                                  "No se pudo leer ese mensaje: %s",
+		"Could not pin context: %s":                                        "No se pudo fijar el contexto: %s",
+		"Pinned. New sessions in this chat will start with it.":            "Fijado. Las sesiones nuevas de este chat empezarán con él.",
+
+		"Personas are not available.":                                             "Las personalidades no están disponibles.",
+		"This session cannot switch personas.":                                    "Esta sesión no puede cambiar de personalidad.",
+		"Persona is %s. Available: %s. Use /persona <name>.":                      "La personalidad es %s. Disponibles: %s. Usa /persona <nombre>.",
+		"Could not switch persona: %s":                                            "No se pudo cambiar de personalidad: %s",
+		"Back to the default persona.":                                            "De vuelta a la personalidad predeterminada.",
+		"Unknown persona %q. Available: %s.":                                      "Personalidad desconocida %q. Disponibles: %s.",
+		"Switched to %s for this session. /new-session goes back to the default.": "Cambiado a %s para esta sesión. /new-session vuelve a la predeterminada.",
+
+		"Generation: %s. " + settingsUsage:                                  "Generación: %s. Usa /settings max_tokens|temperature|thinking <valor|default>.",
+		"Generation: %s.":                                                   "Generación: %s.",
+		"max_tokens must be between 1 and %d, or default.":                  "max_tokens debe estar entre 1 y %d, o ser default.",
+		"temperature must be between 0 and 1, or default.":                  "temperature debe estar entre 0 y 1, o ser default.",
+		"thinking must be a budget of at least %d tokens, off, or default.": "thinking debe ser un presupuesto de al menos %d tokens, off o default.",
+		"Unknown setting %q. " + settingsUsage:                              "Ajuste desconocido %q. Usa /settings max_tokens|temperature|thinking <valor|default>.",
+		"Session %s (name: %s, tags: %s)\nWork dir: %s\nVerbosity %s, read-only %s, speech %s, persona %s\nGeneration: %s": "Sesión %s (nombre: %s, etiquetas: %s)\nDirectorio de trabajo: %s\nDetalle %s, solo lectura %s, voz %s, personalidad %s\nGeneración: %s",
+
+		"The web cache is off.":                                 "La caché web está desactivada.",
+		"%d cached web results. Use /cache clear to drop them.": "%d resultados web en caché. Usa /cache clear para borrarlos.",
+		"Cleared %d cached web results.":                        "Se borraron %d resultados web en caché.",
+		"Unknown option %q. Use /cache [clear].":                "Opción desconocida %q. Usa /cache [clear].",
+
+		"Server settings are not available.":              "La configuración del servidor no está disponible.",
+		"Server settings only apply in a server channel.": "La configuración del servidor solo se aplica en un canal de un servidor.",
+		"Use /config channels <id> ...|all, /config passive on|off, /config workspace <name>|default or /config language <code>|default.": "Usa /config channels <id> ...|all, /config passive on|off, /config workspace <nombre>|default o /config language <código>|default.",
+		"Server settings: channels %s, passive %s, workspace %s, language %s.":                                                            "Configuración del servidor: canales %s, pasivo %s, espacio de trabajo %s, idioma %s.",
+		"Server settings updated: channels %s, passive %s, workspace %s, language %s.":                                                    "Configuración del servidor actualizada: canales %s, pasivo %s, espacio de trabajo %s, idioma %s.",
+		"Unknown language %q. Use /config language %s|default.":                                                                           "Idioma desconocido %q. Usa /config language %s|default.",
+		"unknown": "desconocida",
+		"Switchboard %s, up %s, %d live sessions.":                                 "Switchboard %s, activo desde hace %s, %d sesiones activas.",
+		"Release %s is available.":                                                 "La versión %s está disponible.",
+		"Feedback is not being collected.":                                         "No se está recogiendo valoración.",
+		"Use /feedback up or /feedback down to rate the latest answer.":            "Usa /feedback up o /feedback down para valorar la última respuesta.",
+		"There is no answer to rate yet.":                                          "Todavía no hay ninguna respuesta que valorar.",
+		"Thanks for the feedback.":                                                 "Gracias por tu valoración.",
+		"The answers index is not available.":                                      "El índice de respuestas no está disponible.",
+		"This chat cannot pin answers.":                                            "Este chat no puede fijar respuestas.",
+		"There is no answer to pin yet.":                                           "Todavía no hay ninguna respuesta que fijar.",
+		"Use /pin-answer without arguments to pin the latest answer.":              "Usa /pin-answer sin argumentos para fijar la última respuesta.",
+		"Answer pinned. Use /answers to list this chat's pinned answers.":          "Respuesta fijada. Usa /answers para ver las respuestas fijadas de este chat.",
+		"No answers are pinned here. Use /pin-answer after a reply worth keeping.": "No hay respuestas fijadas aquí. Usa /pin-answer tras una respuesta que valga la pena guardar.",
+		"Use /answers <number> with a number from 1 to %d.":                        "Usa /answers <número> con un número del 1 al %d.",
+		"Pinned answers:":                                               "Respuestas fijadas:",
+		"Use /answers <number> to read one.":                            "Usa /answers <número> para leer una.",
+		"(Cached from %d min ago. Rephrase the question to ask again.)": "(Respuesta guardada de hace %d min. Reformula la pregunta para volver a preguntar.)",
+		"Batch jobs are not available.":                                 "Los trabajos por lotes no están disponibles.",
+		"No batch jobs are pending. Use /batch <prompt> to submit one.": "No hay trabajos por lotes pendientes. Usa /batch <prompt> para enviar uno.",
+		"Pending batch jobs:":                                           "Trabajos por lotes pendientes:",
+		"Batch job %s is done:":                                         "El trabajo por lotes %s ha terminado:",
+		"Batch job %s failed:":                                          "El trabajo por lotes %s ha fallado:",
+		"Submitted batch job %s. The answer will be posted here when it is ready, usually within an hour.": "Trabajo por lotes %s enviado. La respuesta se publicará aquí cuando esté lista, normalmente en menos de una hora.",
+		"There is no message to retry yet.":                                      "No hay ningún mensaje que reintentar todavía.",
+		"Only operators can broadcast.":                                          "Solo los operadores pueden enviar avisos.",
+		"Use /broadcast <message> to post an announcement to every active chat.": "Usa /broadcast <mensaje> para publicar un aviso en todos los chats activos.",
+		"Broadcast sent to %d chats; %d failed.":                                 "Aviso enviado a %d chats; %d fallaron.",
+		"Broadcast sent to %d chats.":                                            "Aviso enviado a %d chats.",
+		"Paused in this channel until /resume.":                                  "En pausa en este canal hasta /resume.",
+		"Paused in this channel until %s.":                                       "En pausa en este canal hasta %s.",
+		"Use /pause, or /pause <duration> such as 30m or 2h (up to 7 days).":     "Usa /pause, o /pause <duración> como 30m o 2h (hasta 7 días).",
+		"Resumed: the pause is over.":                                            "Reanudado: la pausa ha terminado.",
+		"Paused. I'll ignore this channel until /resume.":                        "En pausa. Ignoraré este canal hasta /resume.",
+		"Paused. I'll ignore this channel until %s, or /resume.":                 "En pausa. Ignoraré este canal hasta %s, o /resume.",
+		"The bot isn't paused here.":                                             "El bot no está en pausa aquí.",
+		"Resumed.":                                                               "Reanudado.",
+		"No session variables are set. Use /env set KEY=VALUE to add one.":       "No hay variables de sesión. Usa /env set CLAVE=VALOR para añadir una.",
+		"Session variables: %s":                                                  "Variables de sesión: %s",
+		"Use /env set KEY=VALUE, with a name such as TEST_DATABASE_URL.":         "Usa /env set CLAVE=VALOR, con un nombre como TEST_DATABASE_URL.",
+		"Set %s for this session's tool calls.":                                  "%s definida para las herramientas de esta sesión.",
+		"Use /env unset KEY.":                                                    "Usa /env unset CLAVE.",
+		"%s isn't set.":                                                          "%s no está definida.",
+		"Unset %s.":                                                              "%s eliminada.",
+		"Cleared this session's variables.":                                      "Variables de esta sesión borradas.",
+		"Use /env, /env set KEY=VALUE, /env unset KEY or /env clear.":            "Usa /env, /env set CLAVE=VALOR, /env unset CLAVE o /env clear.",
+	},
+	LangGerman: {
+		"Something went wrong while handling that message.":                                       "Beim Verarbeiten dieser Nachricht ist etwas schiefgegangen.",
+		"Something went wrong while handling that message, so this conversation will start over.": "Beim Verarbeiten dieser Nachricht ist etwas schiefgegangen, daher beginnt diese Unterhaltung von vorn.",
+		"The model backend is unavailable right now. Try again in a few minutes.":                 "Das Modell ist gerade nicht erreichbar. Versuche es in ein paar Minuten erneut.",
+		"That isn't permitted.":                                             "Das ist nicht erlaubt.",
+		"That took too long and was stopped.":                               "Das hat zu lange gedauert und wurde abgebrochen.",
+		"The model is rate limited right now. Wait a minute and try again.": "Das Modell ist gerade ausgelastet. Warte eine Minute und versuche es erneut.",
+		"%s (error ID %s)":                                                  "%s (Fehler-ID %s)",
+
+		"Verbosity is %s. Use /verbosity quiet|tools|thinking.":      "Ausführlichkeit ist %s. Verwende /verbosity quiet|tools|thinking.",
+		"Unknown verbosity %q. Use /verbosity quiet|tools|thinking.": "Unbekannte Ausführlichkeit %q. Verwende /verbosity quiet|tools|thinking.",
+		"Verbosity set to %s.": "Ausführlichkeit auf %s gesetzt.",
+		"Read-only mode is on. Use /readonly off to allow writes.":                                            "Der Nur-Lese-Modus ist an. Verwende /readonly off, um Schreibzugriffe zu erlauben.",
+		"Read-only mode is off. Use /readonly on to block writes.":                                            "Der Nur-Lese-Modus ist aus. Verwende /readonly on, um Schreibzugriffe zu sperren.",
+		"Read-only mode is not available.":                                                                    "Der Nur-Lese-Modus ist nicht verfügbar.",
+		"Unknown option %q. Use /readonly on|off.":                                                            "Unbekannte Option %q. Verwende /readonly on|off.",
+		"Read-only mode on: tools can read and search but not write, run commands or send mutating requests.": "Nur-Lese-Modus an: Werkzeuge können lesen und suchen, aber nicht schreiben, keine Befehle ausführen und keine verändernden Anfragen senden.",
+		"Read-only mode off: writes are enabled.":                                                             "Nur-Lese-Modus aus: Schreibzugriffe sind erlaubt.",
+		"This session's workspace is read-only. Use /new-session with another workspace to write.":            "Der Arbeitsbereich dieser Sitzung ist schreibgeschützt. Verwende /new-session mit einem anderen Arbeitsbereich, um zu schreiben.",
+		"Speech is on. Use /speak off to stop audio replies.":                                                 "Sprachausgabe ist an. Verwende /speak off, um Audioantworten zu beenden.",
+		"Speech is off. Use /speak on to also get replies as audio.":                                          "Sprachausgabe ist aus. Verwende /speak on, um Antworten auch als Audio zu bekommen.",
+		"Speech is not available here.":                                                                       "Sprachausgabe ist hier nicht verfügbar.",
+		"Unknown option %q. Use /speak on|off.":                                                               "Unbekannte Option %q. Verwende /speak on|off.",
+		"Speech on: replies will also be sent as audio.":                                                      "Sprachausgabe an: Antworten werden auch als Audio gesendet.",
+		"Speech off.":                    "Sprachausgabe aus.",
+		"Sharing is not available.":      "Teilen ist nicht verfügbar.",
+		"Nothing to share yet.":          "Noch nichts zum Teilen.",
+		"This session cannot be shared.": "Diese Sitzung kann nicht geteilt werden.",
+		"A reply is still in progress. Use /share once it finishes.": "Eine Antwort läuft noch. Verwende /share, sobald sie fertig ist.",
+		"Transcript shared until %s: %s":                             "Verlauf geteilt bis %s: %s",
+
+		"Send /link-session %s from the other chat within %d minutes to continue this conversation there.": "Sende /link-session %s innerhalb von %d Minuten aus dem anderen Chat, um dieses Gespräch dort fortzusetzen.",
+		"This chat is not linked.":                                                                "Dieser Chat ist nicht verknüpft.",
+		"Unlinked: this chat will start a new conversation.":                                      "Verknüpfung gelöst: Dieser Chat beginnt ein neues Gespräch.",
+		"That link code is invalid or has expired.":                                               "Dieser Verknüpfungscode ist ungültig oder abgelaufen.",
+		"That code is for this chat. Send it from the other one.":                                 "Dieser Code gehört zu diesem Chat. Sende ihn aus dem anderen.",
+		"That conversation has ended. Start a new link from the other chat.":                      "Dieses Gespräch ist beendet. Starte eine neue Verknüpfung aus dem anderen Chat.",
+		"Linked: this chat now continues the same conversation. Use /link-session off to detach.": "Verknüpft: Dieser Chat setzt jetzt dasselbe Gespräch fort. Verwende /link-session off zum Trennen.",
+
+		"This message would send about %s input tokens to the model (limit %s). Send /confirm to go ahead, or anything else to drop it.": "Diese Nachricht würde etwa %s Eingabe-Tokens an das Modell senden (Limit %s). Sende /confirm zum Fortfahren oder etwas anderes zum Verwerfen.",
+		"Nothing is waiting for confirmation.": "Nichts wartet auf Bestätigung.",
+
+		skillUsage:                          "Verwende /skill new <name> [beschreibung]. Mit einer Beschreibung entwerfe ich auch die Anleitung.",
+		"Creating skills is not available.": "Das Erstellen von Skills ist nicht verfügbar.",
+		"Invalid skill name %q: use lowercase letters, digits and single hyphens.": "Ungültiger Skill-Name %q: verwende Kleinbuchstaben, Ziffern und einzelne Bindestriche.",
+		"Could not create skill %s: %s":                                            "Skill %s konnte nicht erstellt werden: %s",
+		"Created skill %s at %s with drafted instructions, plus scripts/example.sh and references/notes.md. Review it before relying on it; new sessions will list it.":   "Skill %s in %s mit entworfener Anleitung erstellt, dazu scripts/example.sh und references/notes.md. Prüfe ihn, bevor du dich darauf verlässt; neue Sitzungen führen ihn auf.",
+		"Created skill %s at %s from the template, plus scripts/example.sh and references/notes.md. Fill in its description and instructions; new sessions will list it.": "Skill %s in %s aus der Vorlage erstellt, dazu scripts/example.sh und references/notes.md. Ergänze Beschreibung und Anleitung; neue Sitzungen führen ihn auf.",
+
+		"%s (default)":            "%s (Standard)",
+		"%s (default, read-only)": "%s (Standard, nur lesen)",
+		"%s (read-only)":          "%s (nur lesen)",
+		"No workspaces are configured. Use /new-session without a name.": "Es sind keine Arbeitsbereiche eingerichtet. Verwende /new-session ohne Namen.",
+		"Started a new session.":                                                                    "Neue Sitzung gestartet.",
+		"Unknown workspace %q. Workspaces: %s.":                                                     "Unbekannter Arbeitsbereich %q. Arbeitsbereiche: %s.",
+		"Workspace %s is read-only, but read-only mode is not available.":                           "Arbeitsbereich %s ist schreibgeschützt, aber der Nur-Lese-Modus ist nicht verfügbar.",
+		"Started a new read-only session in %s (%s).":                                               "Neue Nur-Lese-Sitzung in %s (%s) gestartet.",
+		"Started a new session in %s (%s).":                                                         "Neue Sitzung in %s (%s) gestartet.",
+		"Cloning is not available.":                                                                 "Klonen ist nicht verfügbar.",
+		"Use /clone <https-git-url>.":                                                               "Verwende /clone <https-git-url>.",
+		"Cloning is not allowed in read-only mode.":                                                 "Klonen ist im Nur-Lese-Modus nicht erlaubt.",
+		"Could not clone %s: %s":                                                                    "%s konnte nicht geklont werden: %s",
+		"Cloned %s into %s and started a new session there.":                                        "%s nach %s geklont und dort eine neue Sitzung gestartet.",
+		"Already cloned %s into %s; started a new session there.":                                   "%s war bereits nach %s geklont; dort wurde eine neue Sitzung gestartet.",
+		"Use /new-session %s to come back to it.":                                                   "Verwende /new-session %s, um dorthin zurückzukehren.",
+		"Scratch sessions are not available.":                                                       "Wegwerf-Sitzungen sind nicht verfügbar.",
+		"Use /scratch without arguments.":                                                           "Verwende /scratch ohne Argumente.",
+		"Scratch sessions are not allowed in read-only mode.":                                       "Wegwerf-Sitzungen sind im Nur-Lese-Modus nicht erlaubt.",
+		"Started a scratch session in %s. It is deleted when the session ends.":                     "Wegwerf-Sitzung in %s gestartet. Sie wird gelöscht, wenn die Sitzung endet.",
+		"Started a scratch session in %s. It is deleted when the session ends or after %d minutes.": "Wegwerf-Sitzung in %s gestartet. Sie wird gelöscht, wenn die Sitzung endet oder nach %d Minuten.",
+
+		"This session has no branch.":             "Diese Sitzung hat keinen Branch.",
+		"/%s is not allowed in read-only mode.":   "/%s ist im Nur-Lese-Modus nicht erlaubt.",
+		"/%s failed: %s":                          "/%s fehlgeschlagen: %s",
+		"Merged %s into %s.":                      "%s in %s zusammengeführt.",
+		"Deleted %s and its changes; back on %s.": "%s und seine Änderungen gelöscht; zurück auf %s.",
+
+		"Composing. Send the rest of your prompt, then !end to submit or !cancel to discard.": "Entwurf läuft. Sende den Rest deiner Nachricht, dann !end zum Absenden oder !cancel zum Verwerfen.",
+		"Compose discarded.": "Entwurf verworfen.",
+		"Nothing to submit.": "Nichts zum Absenden.",
+		"Compose buffer is full. Send !end to submit what you have.": "Der Entwurfspuffer ist voll. Sende !end, um das Bisherige abzuschicken.",
+
+		"Language is %s. Use /language %s [channel].":                  "Sprache ist %s. Verwende /language %s [channel].",
+		"Unknown language %q. Use /language %s [channel].":             "Unbekannte Sprache %q. Verwende /language %s [channel].",
+		"Unknown option %q. Use /language %s [channel].":               "Unbekannte Option %q. Verwende /language %s [channel].",
+		"This chat cannot tell users apart. Use /language %s channel.": "Dieser Chat kann Nutzer nicht unterscheiden. Verwende /language %s channel.",
+		"Language set to %s for you.":                                  "Sprache für dich auf %s gesetzt.",
+		"This chat cannot set a channel language.":                     "Dieser Chat kann keine Kanalsprache festlegen.",
+		"Language set to %s for this chat.":                            "Sprache für diesen Chat auf %s gesetzt.",
+
+		"Your time zone is %s. Use /timezone <zone>, e.g. /timezone Europe/Berlin.":         "Deine Zeitzone ist %s. Verwende /timezone <zone>, z. B. /timezone Europe/Berlin.",
+		"This chat cannot tell users apart, so times stay in UTC.":                          "Dieser Chat kann Nutzer nicht unterscheiden, daher bleiben Zeiten in UTC.",
+		"Unknown time zone %q. Use an IANA name such as Europe/Berlin or America/New_York.": "Unbekannte Zeitzone %q. Verwende einen IANA-Namen wie Europe/Berlin oder America/New_York.",
+		"Time zone set to %s. It is now %s.":                                                "Zeitzone auf %s gesetzt. Es ist jetzt %s.",
+
+		"Use /rename-session <name>, or /rename-session - to clear it. Session ID: %s.": "Verwende /rename-session <name> oder /rename-session - zum Entfernen. Sitzungs-ID: %s.",
+		"Session names can be at most %d characters.":                                   "Sitzungsnamen dürfen höchstens %d Zeichen lang sein.",
+		"Session name cleared.":  "Sitzungsname entfernt.",
+		"Session renamed to %q.": "Sitzung in %q umbenannt.",
+		"Invalid tag %q: use up to 32 lowercase letters, digits, - and _.":                       "Ungültiger Tag %q: verwende bis zu 32 Kleinbuchstaben, Ziffern, - und _.",
+		"Could not tag the session: %s":                                                          "Die Sitzung konnte nicht getaggt werden: %s",
+		"This session has no tags. Use /tag-session <tag>... to add some, -<tag> to remove one.": "Diese Sitzung hat keine Tags. Verwende /tag-session <tag>... zum Hinzufügen, -<tag> zum Entfernen.",
+		"Tags: %s": "Tags: %s",
+
+		"Pinned context is not available.":                                 "Angehefteter Kontext ist nicht verfügbar.",
+		"This chat cannot pin context.":                                    "In diesem Chat kann kein Kontext angeheftet werden.",
+		"Nothing is pinned here. Use /pin-context <text or message link>.": "Hier ist nichts angeheftet. Verwende /pin-context <text oder nachrichtenlink>.",
+		"Pinned for this chat:\n%s\nUse /pin-context clear to remove it.":  "Für diesen Chat angeheftet:\n%s\nVerwende /pin-context clear zum Entfernen.",
+		"Pinned context cleared.":                                          "Angehefteter Kontext entfernt.",
+		"Could not read that message: %s":                                  "Diese Nachricht konnte nicht gelesen werden: %s",
+		"Could not pin context: %s":                                        "Kontext konnte nicht angeheftet werden: %s",
+		"Pinned. New sessions in this chat will start with it.":            "Angeheftet. Neue Sitzungen in diesem Chat beginnen damit.",
+
+		"Personas are not available.":                                             "Personas sind nicht verfügbar.",
+		"This session cannot switch personas.":                                    "Diese Sitzung kann die Persona nicht wechseln.",
+		"Persona is %s. Available: %s. Use /persona <name>.":                      "Persona ist %s. Verfügbar: %s. Verwende /persona <name>.",
+		"Could not switch persona: %s":                                            "Persona konnte nicht gewechselt werden: %s",
+		"Back to the default persona.":                                            "Zurück zur Standard-Persona.",
+		"Unknown persona %q. Available: %s.":                                      "Unbekannte Persona %q. Verfügbar: %s.",
+		"Switched to %s for this session. /new-session goes back to the default.": "Für diese Sitzung zu %s gewechselt. /new-session kehrt zum Standard zurück.",
+
+		"Generation: %s. " + settingsUsage:                                  "Generierung: %s. Verwende /settings max_tokens|temperature|thinking <wert|default>.",
+		"Generation: %s.":                                                   "Generierung: %s.",
+		"max_tokens must be between 1 and %d, or default.":                  "max_tokens muss zwischen 1 und %d liegen oder default sein.",
+		"temperature must be between 0 and 1, or default.":                  "temperature muss zwischen 0 und 1 liegen oder default sein.",
+		"thinking must be a budget of at least %d tokens, off, or default.": "thinking muss ein Budget von mindestens %d Tokens, off oder default sein.",
+		"Unknown setting %q. " + settingsUsage:                              "Unbekannte Einstellung %q. Verwende /settings max_tokens|temperature|thinking <wert|default>.",
+		"Session %s (name: %s, tags: %s)\nWork dir: %s\nVerbosity %s, read-only %s, speech %s, persona %s\nGeneration: %s": "Sitzung %s (Name: %s, Tags: %s)\nArbeitsverzeichnis: %s\nAusführlichkeit %s, nur lesen %s, Sprachausgabe %s, Persona %s\nGenerierung: %s",
+
+		"The web cache is off.":                                 "Der Web-Cache ist aus.",
+		"%d cached web results. Use /cache clear to drop them.": "%d Web-Ergebnisse im Cache. Verwende /cache clear, um sie zu löschen.",
+		"Cleared %d cached web results.":                        "%d Web-Ergebnisse aus dem Cache gelöscht.",
+		"Unknown option %q. Use /cache [clear].":                "Unbekannte Option %q. Verwende /cache [clear].",
+
+		"Server settings are not available.":              "Servereinstellungen sind nicht verfügbar.",
+		"Server settings only apply in a server channel.": "Servereinstellungen gelten nur in einem Serverkanal.",
+		"Use /config channels <id> ...|all, /config passive on|off, /config workspace <name>|default or /config language <code>|default.": "Verwende /config channels <id> ...|all, /config passive on|off, /config workspace <name>|default oder /config language <code>|default.",
+		"Server settings: channels %s, passive %s, workspace %s, language %s.":                                                            "Servereinstellungen: Kanäle %s, passiv %s, Arbeitsbereich %s, Sprache %s.",
+		"Server settings updated: channels %s, passive %s, workspace %s, language %s.":                                                    "Servereinstellungen aktualisiert: Kanäle %s, passiv %s, Arbeitsbereich %s, Sprache %s.",
+		"Unknown language %q. Use /config language %s|default.":                                                                           "Unbekannte Sprache %q. Verwende /config language %s|default.",
+		"unknown": "unbekannt",
+		"Switchboard %s, up %s, %d live sessions.":                                 "Switchboard %s, läuft seit %s, %d aktive Sitzungen.",
+		"Release %s is available.":                                                 "Version %s ist verfügbar.",
+		"Feedback is not being collected.":                                         "Es wird kein Feedback gesammelt.",
+		"Use /feedback up or /feedback down to rate the latest answer.":            "Verwende /feedback up oder /feedback down, um die letzte Antwort zu bewerten.",
+		"There is no answer to rate yet.":                                          "Es gibt noch keine Antwort zum Bewerten.",
+		"Thanks for the feedback.":                                                 "Danke für dein Feedback.",
+		"The answers index is not available.":                                      "Der Antwortindex ist nicht verfügbar.",
+		"This chat cannot pin answers.":                                            "In diesem Chat können keine Antworten angeheftet werden.",
+		"There is no answer to pin yet.":                                           "Es gibt noch keine Antwort zum Anheften.",
+		"Use /pin-answer without arguments to pin the latest answer.":              "Verwende /pin-answer ohne Argumente, um die letzte Antwort anzuheften.",
+		"Answer pinned. Use /answers to list this chat's pinned answers.":          "Antwort angeheftet. Mit /answers siehst du die angehefteten Antworten dieses Chats.",
+		"No answers are pinned here. Use /pin-answer after a reply worth keeping.": "Hier sind keine Antworten angeheftet. Verwende /pin-answer nach einer Antwort, die es wert ist, aufbewahrt zu werden.",
+		"Use /answers <number> with a number from 1 to %d.":                        "Verwende /answers <Nummer> mit einer Nummer von 1 bis %d.",
+		"Pinned answers:":                                               "Angeheftete Antworten:",
+		"Use /answers <number> to read one.":                            "Verwende /answers <Nummer>, um eine zu lesen.",
+		"(Cached from %d min ago. Rephrase the question to ask again.)": "(Zwischengespeichert vor %d Min. Formuliere die Frage um, um erneut zu fragen.)",
+		"Batch jobs are not available.":                                 "Batch-Aufträge sind nicht verfügbar.",
+		"No batch jobs are pending. Use /batch <prompt> to submit one.": "Keine Batch-Aufträge ausstehend. Mit /batch <prompt> reichst du einen ein.",
+		"Pending batch jobs:":                                           "Ausstehende Batch-Aufträge:",
+		"Batch job %s is done:":                                         "Batch-Auftrag %s ist fertig:",
+		"Batch job %s failed:":                                          "Batch-Auftrag %s ist fehlgeschlagen:",
+		"Submitted batch job %s. The answer will be posted here when it is ready, usually within an hour.": "Batch-Auftrag %s eingereicht. Die Antwort erscheint hier, sobald sie fertig ist, meist innerhalb einer Stunde.",
+		"There is no message to retry yet.":                                      "Es gibt noch keine Nachricht zum Wiederholen.",
+		"Only operators can broadcast.":                                          "Nur Betreiber können Rundsendungen verschicken.",
+		"Use /broadcast <message> to post an announcement to every active chat.": "Verwende /broadcast <Nachricht>, um eine Ankündigung in allen aktiven Chats zu posten.",
+		"Broadcast sent to %d chats; %d failed.":                                 "Rundsendung an %d Chats gesendet; %d fehlgeschlagen.",
+		"Broadcast sent to %d chats.":                                            "Rundsendung an %d Chats gesendet.",
+		"Paused in this channel until /resume.":                                  "In diesem Kanal pausiert bis /resume.",
+		"Paused in this channel until %s.":                                       "In diesem Kanal pausiert bis %s.",
+		"Use /pause, or /pause <duration> such as 30m or 2h (up to 7 days).":     "Verwende /pause oder /pause <Dauer> wie 30m oder 2h (bis zu 7 Tage).",
+		"Resumed: the pause is over.":                                            "Fortgesetzt: Die Pause ist vorbei.",
+		"Paused. I'll ignore this channel until /resume.":                        "Pausiert. Ich ignoriere diesen Kanal bis /resume.",
+		"Paused. I'll ignore this channel until %s, or /resume.":                 "Pausiert. Ich ignoriere diesen Kanal bis %s oder /resume.",
+		"The bot isn't paused here.":                                             "Der Bot ist hier nicht pausiert.",
+		"Resumed.":                                                               "Fortgesetzt.",
+		"No session variables are set. Use /env set KEY=VALUE to add one.":       "Keine Sitzungsvariablen gesetzt. Verwende /env set SCHLÜSSEL=WERT, um eine hinzuzufügen.",
+		"Session variables: %s":                                                  "Sitzungsvariablen: %s",
+		"Use /env set KEY=VALUE, with a name such as TEST_DATABASE_URL.":         "Verwende /env set SCHLÜSSEL=WERT mit einem Namen wie TEST_DATABASE_URL.",
+		"Set %s for this session's tool calls.":                                  "%s für die Werkzeugaufrufe dieser Sitzung gesetzt.",
+		"Use /env unset KEY.":                                                    "Verwende /env unset SCHLÜSSEL.",
+		"%s isn't set.":                                                          "%s ist nicht gesetzt.",
+		"Unset %s.":                                                              "%s entfernt.",
+		"Cleared this session's variables.":                                      "Variablen dieser Sitzung gelöscht.",
+		"Use /env, /env set KEY=VALUE, /env unset KEY or /env clear.":            "Verwende /env, /env set SCHLÜSSEL=WERT, /env unset SCHLÜSSEL oder /env clear.",
+	},
+	LangAfrikaans: {
+		"Something went wrong while handling that message.":                                       "Iets het verkeerd geloop met die hantering van daardie boodskap.",
+		"Something went wrong while handling that message, so this conversation will start over.": "Iets het verkeerd geloop met die hantering van daardie boodskap, so hierdie gesprek begin van voor af.",
+		"The model backend is unavailable right now. Try again in a few minutes.":                 "Die model is nou nie beskikbaar nie. Probeer weer oor 'n paar minute.",
+		"That isn't permitted.":                                             "Dit word nie toegelaat nie.",
+		"That took too long and was stopped.":                               "Dit het te lank geneem en is gestop.",
+		"The model is rate limited right now. Wait a minute and try again.": "Die model word nou beperk. Wag 'n minuut en probeer weer.",
+		"%s (error ID %s)":                                                  "%s (fout-ID %s)",
+
+		"Verbosity is %s. Use /verbosity quiet|tools|thinking.":      "Breedvoerigheid is %s. Gebruik /verbosity quiet|tools|thinking.",
+		"Unknown verbosity %q. Use /verbosity quiet|tools|thinking.": "Onbekende breedvoerigheid %q. Gebruik /verbosity quiet|tools|thinking.",
+		"Verbosity set to %s.": "Breedvoerigheid gestel op %s.",
+		"Read-only mode is on. Use /readonly off to allow writes.":                                            "Leesalleen-modus is aan. Gebruik /readonly off om skryf toe te laat.",
+		"Read-only mode is off. Use /readonly on to block writes.":                                            "Leesalleen-modus is af. Gebruik /readonly on om skryf te blokkeer.",
+		"Read-only mode is not available.":                                                                    "Leesalleen-modus is nie beskikbaar nie.",
+		"Unknown option %q. Use /readonly on|off.":                                                            "Onbekende opsie %q. Gebruik /readonly on|off.",
+		"Read-only mode on: tools can read and search but not write, run commands or send mutating requests.": "Leesalleen-modus aan: gereedskap kan lees en soek, maar nie skryf, opdragte uitvoer of veranderende versoeke stuur nie.",
+		"Read-only mode off: writes are enabled.":                                                             "Leesalleen-modus af: skryf is toegelaat.",
+		"This session's workspace is read-only. Use /new-session with another workspace to write.":            "Hierdie sessie se werkspasie is leesalleen. Gebruik /new-session met 'n ander werkspasie om te skryf.",
+		"Speech is on. Use /speak off to stop audio replies.":                                                 "Spraak is aan. Gebruik /speak off om oudio-antwoorde te stop.",
+		"Speech is off. Use /speak on to also get replies as audio.":                                          "Spraak is af. Gebruik /speak on om antwoorde ook as oudio te kry.",
+		"Speech is not available here.":                                                                       "Spraak is nie hier beskikbaar nie.",
+		"Unknown option %q. Use /speak on|off.":                                                               "Onbekende opsie %q. Gebruik /speak on|off.",
+		"Speech on: replies will also be sent as audio.":                                                      "Spraak aan: antwoorde sal ook as oudio gestuur word.",
+		"Speech off.":                    "Spraak af.",
+		"Sharing is not available.":      "Deel is nie beskikbaar nie.",
+		"Nothing to share yet.":          "Nog niks om te deel nie.",
+		"This session cannot be shared.": "Hierdie sessie kan nie gedeel word nie.",
+		"A reply is still in progress. Use /share once it finishes.": "'n Antwoord is nog besig. Gebruik /share sodra dit klaar is.",
+		"Transcript shared until %s: %s":                             "Transkripsie gedeel tot %s: %s",
+
+		"Send /link-session %s from the other chat within %d minutes to continue this conversation there.": "Stuur /link-session %s binne %d minute van die ander klets af om hierdie gesprek daar voort te sit.",
+		"This chat is not linked.":                                                                "Hierdie klets is nie gekoppel nie.",
+		"Unlinked: this chat will start a new conversation.":                                      "Ontkoppel: hierdie klets begin 'n nuwe gesprek.",
+		"That link code is invalid or has expired.":                                               "Daardie koppelkode is ongeldig of het verval.",
+		"That code is for this chat. Send it from the other one.":                                 "Daardie kode is vir hierdie klets. Stuur dit van die ander een af.",
+		"That conversation has ended. Start a new link from the other chat.":                      "Daardie gesprek het geëindig. Begin 'n nuwe koppeling van die ander klets af.",
+		"Linked: this chat now continues the same conversation. Use /link-session off to detach.": "Gekoppel: hierdie klets sit nou dieselfde gesprek voort. Gebruik /link-session off om te ontkoppel.",
+
+		"This message would send about %s input tokens to the model (limit %s). Send /confirm to go ahead, or anything else to drop it.": "Hierdie boodskap sal omtrent %s invoer-tokens na die model stuur (limiet %s). Stuur /confirm om voort te gaan, of enigiets anders om dit te laat vaar.",
+		"Nothing is waiting for confirmation.": "Niks wag vir bevestiging nie.",
+
+		skillUsage:                          "Gebruik /skill new <naam> [beskrywing]. Met 'n beskrywing skryf ek ook die instruksies.",
+		"Creating skills is not available.": "Die skep van vaardighede is nie beskikbaar nie.",
+		"Invalid skill name %q: use lowercase letters, digits and single hyphens.": "Ongeldige vaardigheidsnaam %q: gebruik kleinletters, syfers en enkele koppeltekens.",
+		"Could not create skill %s: %s":                                            "Kon nie vaardigheid %s skep nie: %s",
+		"Created skill %s at %s with drafted instructions, plus scripts/example.sh and references/notes.md. Review it before relying on it; new sessions will list it.":   "Vaardigheid %s by %s geskep met konsep-instruksies, plus scripts/example.sh en references/notes.md. Hersien dit voordat jy daarop staatmaak; nuwe sessies sal dit lys.",
+		"Created skill %s at %s from the template, plus scripts/example.sh and references/notes.md. Fill in its description and instructions; new sessions will list it.": "Vaardigheid %s by %s uit die sjabloon geskep, plus scripts/example.sh en references/notes.md. Vul die beskrywing en instruksies in; nuwe sessies sal dit lys.",
+
+		"%s (default)":            "%s (verstek)",
+		"%s (default, read-only)": "%s (verstek, leesalleen)",
+		"%s (read-only)":          "%s (leesalleen)",
+		"No workspaces are configured. Use /new-session without a name.": "Geen werkruimtes is opgestel nie. Gebruik /new-session sonder 'n naam.",
+		"Started a new session.":                                                                    "Nuwe sessie begin.",
+		"Unknown workspace %q. Workspaces: %s.":                                                     "Onbekende werkruimte %q. Werkruimtes: %s.",
+		"Workspace %s is read-only, but read-only mode is not available.":                           "Werkruimte %s is leesalleen, maar leesalleen-modus is nie beskikbaar nie.",
+		"Started a new read-only session in %s (%s).":                                               "Nuwe leesalleen-sessie in %s (%s) begin.",
+		"Started a new session in %s (%s).":                                                         "Nuwe sessie in %s (%s) begin.",
+		"Cloning is not available.":                                                                 "Kloning is nie beskikbaar nie.",
+		"Use /clone <https-git-url>.":                                                               "Gebruik /clone <https-git-url>.",
+		"Cloning is not allowed in read-only mode.":                                                 "Kloning word nie in leesalleen-modus toegelaat nie.",
+		"Could not clone %s: %s":                                                                    "Kon nie %s kloon nie: %s",
+		"Cloned %s into %s and started a new session there.":                                        "%s na %s gekloon en 'n nuwe sessie daar begin.",
+		"Already cloned %s into %s; started a new session there.":                                   "%s is reeds na %s gekloon; 'n nuwe sessie is daar begin.",
+		"Use /new-session %s to come back to it.":                                                   "Gebruik /new-session %s om daarheen terug te keer.",
+		"Scratch sessions are not available.":                                                       "Kladsessies is nie beskikbaar nie.",
+		"Use /scratch without arguments.":                                                           "Gebruik /scratch sonder argumente.",
+		"Scratch sessions are not allowed in read-only mode.":                                       "Kladsessies word nie in leesalleen-modus toegelaat nie.",
+		"Started a scratch session in %s. It is deleted when the session ends.":                     "Kladsessie in %s begin. Dit word uitgevee wanneer die sessie eindig.",
+		"Started a scratch session in %s. It is deleted when the session ends or after %d minutes.": "Kladsessie in %s begin. Dit word uitgevee wanneer die sessie eindig of na %d minute.",
+
+		"This session has no branch.":             "Hierdie sessie het geen tak nie.",
+		"/%s is not allowed in read-only mode.":   "/%s word nie in leesalleen-modus toegelaat nie.",
+		"/%s failed: %s":                          "/%s het misluk: %s",
+		"Merged %s into %s.":                      "%s in %s saamgevoeg.",
+		"Deleted %s and its changes; back on %s.": "%s en sy veranderinge uitgevee; terug op %s.",
+
+		"Composing. Send the rest of your prompt, then !end to submit or !cancel to discard.": "Besig om op te stel. Stuur die res van jou boodskap, dan !end om in te dien of !cancel om weg te gooi.",
+		"Compose discarded.": "Opstel weggegooi.",
+		"Nothing to submit.": "Niks om in te dien nie.",
+		"Compose buffer is full. Send !end to submit what you have.": "Die opstelbuffer is vol. Stuur !end om in te dien wat jy het.",
+
+		"Language is %s. Use /language %s [channel].":                  "Taal is %s. Gebruik /language %s [channel].",
+		"Unknown language %q. Use /language %s [channel].":             "Onbekende taal %q. Gebruik /language %s [channel].",
+		"Unknown option %q. Use /language %s [channel].":               "Onbekende opsie %q. Gebruik /language %s [channel].",
+		"This chat cannot tell users apart. Use /language %s channel.": "Hierdie klets kan nie gebruikers uitmekaar ken nie. Gebruik /language %s channel.",
+		"Language set to %s for you.":                                  "Taal vir jou op %s gestel.",
+		"This chat cannot set a channel language.":                     "Hierdie klets kan nie 'n kanaaltaal stel nie.",
+		"Language set to %s for this chat.":                            "Taal vir hierdie klets op %s gestel.",
+
+		"Your time zone is %s. Use /timezone <zone>, e.g. /timezone Europe/Berlin.":         "Jou tydsone is %s. Gebruik /timezone <sone>, bv. /timezone Africa/Johannesburg.",
+		"This chat cannot tell users apart, so times stay in UTC.":                          "Hierdie klets kan nie gebruikers uitmekaar ken nie, dus bly tye in UTC.",
+		"Unknown time zone %q. Use an IANA name such as Europe/Berlin or America/New_York.": "Onbekende tydsone %q. Gebruik 'n IANA-naam soos Africa/Johannesburg of Europe/London.",
+		"Time zone set to %s. It is now %s.":                                                "Tydsone op %s gestel. Dit is nou %s.",
+
+		"Use /rename-session <name>, or /rename-session - to clear it. Session ID: %s.": "Gebruik /rename-session <naam>, of /rename-session - om dit uit te vee. Sessie-ID: %s.",
+		"Session names can be at most %d characters.":                                   "Sessiename kan hoogstens %d karakters lank wees.",
+		"Session name cleared.":  "Sessienaam uitgevee.",
+		"Session renamed to %q.": "Sessie hernoem na %q.",
+		"Invalid tag %q: use up to 32 lowercase letters, digits, - and _.":                       "Ongeldige etiket %q: gebruik tot 32 kleinletters, syfers, - en _.",
+		"Could not tag the session: %s":                                                          "Kon nie die sessie etiketteer nie: %s",
+		"This session has no tags. Use /tag-session <tag>... to add some, -<tag> to remove one.": "Hierdie sessie het geen etikette nie. Gebruik /tag-session <etiket>... om by te voeg, -<etiket> om een te verwyder.",
+		"Tags: %s": "Etikette: %s",
+
+		"Pinned context is not available.":                                 "Vasgespelde konteks is nie beskikbaar nie.",
+		"This chat cannot pin context.":                                    "Hierdie klets kan nie konteks vasspeld nie.",
+		"Nothing is pinned here. Use /pin-context <text or message link>.": "Niks is hier vasgespeld nie. Gebruik /pin-context <teks of boodskapskakel>.",
+		"Pinned for this chat:\n%s\nUse /pin-context clear to remove it.":  "Vasgespeld vir hierdie klets:\n%s\nGebruik /pin-context clear om dit te verwyder.",
+		"Pinned context cleared.":                                          "Vasgespelde konteks verwyder.",
+		"Could not read that message: %s":                                  "Kon nie daardie boodskap lees nie: %s",
+		"Could not pin context: %s":                                        "Kon nie konteks vasspeld nie: %s",
+		"Pinned. New sessions in this chat will start with it.":            "Vasgespeld. Nuwe sessies in hierdie klets sal daarmee begin.",
+
+		"Personas are not available.":                                             "Personas is nie beskikbaar nie.",
+		"This session cannot switch personas.":                                    "Hierdie sessie kan nie van persona wissel nie.",
+		"Persona is %s. Available: %s. Use /persona <name>.":                      "Persona is %s. Beskikbaar: %s. Gebruik /persona <naam>.",
+		"Could not switch persona: %s":                                            "Kon nie van persona wissel nie: %s",
+		"Back to the default persona.":                                            "Terug na die verstek-persona.",
+		"Unknown persona %q. Available: %s.":                                      "Onbekende persona %q. Beskikbaar: %s.",
+		"Switched to %s for this session. /new-session goes back to the default.": "Na %s gewissel vir hierdie sessie. /new-session gaan terug na die verstek.",
+
+		"Generation: %s. " + settingsUsage:                                  "Generering: %s. Gebruik /settings max_tokens|temperature|thinking <waarde|default>.",
+		"Generation: %s.":                                                   "Generering: %s.",
+		"max_tokens must be between 1 and %d, or default.":                  "max_tokens moet tussen 1 en %d wees, of default.",
+		"temperature must be between 0 and 1, or default.":                  "temperature moet tussen 0 en 1 wees, of default.",
+		"thinking must be a budget of at least %d tokens, off, or default.": "thinking moet 'n begroting van minstens %d tokens, off of default wees.",
+		"Unknown setting %q. " + settingsUsage:                              "Onbekende instelling %q. Gebruik /settings max_tokens|temperature|thinking <waarde|default>.",
+		"Session %s (name: %s, tags: %s)\nWork dir: %s\nVerbosity %s, read-only %s, speech %s, persona %s\nGeneration: %s": "Sessie %s (naam: %s, etikette: %s)\nWerkgids: %s\nBreedvoerigheid %s, leesalleen %s, spraak %s, persona %s\nGenerering: %s",
+
+		"The web cache is off.":                                 "Die webkas is af.",
+		"%d cached web results. Use /cache clear to drop them.": "%d webresultate in die kas. Gebruik /cache clear om hulle uit te vee.",
+		"Cleared %d cached web results.":                        "%d webresultate uit die kas verwyder.",
+		"Unknown option %q. Use /cache [clear].":                "Onbekende opsie %q. Gebruik /cache [clear].",
+
+		"Server settings are not available.":              "Bedienerinstellings is nie beskikbaar nie.",
+		"Server settings only apply in a server channel.": "Bedienerinstellings geld net in 'n bedienerkanaal.",
+		"Use /config channels <id> ...|all, /config passive on|off, /config workspace <name>|default or /config language <code>|default.": "Gebruik /config channels <id> ...|all, /config passive on|off, /config workspace <naam>|default of /config language <kode>|default.",
+		"Server settings: channels %s, passive %s, workspace %s, language %s.":                                                            "Bedienerinstellings: kanale %s, passief %s, werkruimte %s, taal %s.",
+		"Server settings updated: channels %s, passive %s, workspace %s, language %s.":                                                    "Bedienerinstellings bygewerk: kanale %s, passief %s, werkruimte %s, taal %s.",
+		"Unknown language %q. Use /config language %s|default.":                                                                           "Onbekende taal %q. Gebruik /config language %s|default.",
+		"unknown": "onbekend",
+		"Switchboard %s, up %s, %d live sessions.":                                 "Switchboard %s, loop al %s, %d aktiewe sessies.",
+		"Release %s is available.":                                                 "Weergawe %s is beskikbaar.",
+		"Feedback is not being collected.":                                         "Terugvoer word nie ingesamel nie.",
+		"Use /feedback up or /feedback down to rate the latest answer.":            "Gebruik /feedback up of /feedback down om die jongste antwoord te beoordeel.",
+		"There is no answer to rate yet.":                                          "Daar is nog geen antwoord om te beoordeel nie.",
+		"Thanks for the feedback.":                                                 "Dankie vir die terugvoer.",
+		"The answers index is not available.":                                      "Die antwoordindeks is nie beskikbaar nie.",
+		"This chat cannot pin answers.":                                            "Hierdie klets kan nie antwoorde vaspen nie.",
+		"There is no answer to pin yet.":                                           "Daar is nog geen antwoord om vas te pen nie.",
+		"Use /pin-answer without arguments to pin the latest answer.":              "Gebruik /pin-answer sonder argumente om die jongste antwoord vas te pen.",
+		"Answer pinned. Use /answers to list this chat's pinned answers.":          "Antwoord vasgepen. Gebruik /answers om hierdie klets se vasgepende antwoorde te sien.",
+		"No answers are pinned here. Use /pin-answer after a reply worth keeping.": "Geen antwoorde is hier vasgepen nie. Gebruik /pin-answer na 'n antwoord wat die moeite werd is om te hou.",
+		"Use /answers <number> with a number from 1 to %d.":                        "Gebruik /answers <nommer> met 'n nommer van 1 tot %d.",
+		"Pinned answers:":                                               "Vasgepende antwoorde:",
+		"Use /answers <number> to read one.":                            "Gebruik /answers <nommer> om een te lees.",
+		"(Cached from %d min ago. Rephrase the question to ask again.)": "(Gekas van %d min gelede. Herformuleer die vraag om weer te vra.)",
+		"Batch jobs are not available.":                                 "Bondeltake is nie beskikbaar nie.",
+		"No batch jobs are pending. Use /batch <prompt> to submit one.": "Geen bondeltake is hangende nie. Gebruik /batch <prompt> om een in te dien.",
+		"Pending batch jobs:":                                           "Hangende bondeltake:",
+		"Batch job %s is done:":                                         "Bondeltaak %s is klaar:",
+		"Batch job %s failed:":                                          "Bondeltaak %s het misluk:",
+		"Submitted batch job %s. The answer will be posted here when it is ready, usually within an hour.": "Bondeltaak %s ingedien. Die antwoord word hier geplaas sodra dit gereed is, gewoonlik binne 'n uur.",
+		"There is no message to retry yet.":                                      "Daar is nog geen boodskap om weer te probeer nie.",
+		"Only operators can broadcast.":                                          "Slegs operateurs kan aankondigings uitstuur.",
+		"Use /broadcast <message> to post an announcement to every active chat.": "Gebruik /broadcast <boodskap> om 'n aankondiging in elke aktiewe klets te plaas.",
+		"Broadcast sent to %d chats; %d failed.":                                 "Aankondiging na %d kletse gestuur; %d het misluk.",
+		"Broadcast sent to %d chats.":                                            "Aankondiging na %d kletse gestuur.",
+		"Paused in this channel until /resume.":                                  "Gepouseer in hierdie kanaal tot /resume.",
+		"Paused in this channel until %s.":                                       "Gepouseer in hierdie kanaal tot %s.",
+		"Use /pause, or /pause <duration> such as 30m or 2h (up to 7 days).":     "Gebruik /pause, of /pause <duur> soos 30m of 2h (tot 7 dae).",
+		"Resumed: the pause is over.":                                            "Hervat: die pouse is verby.",
+		"Paused. I'll ignore this channel until /resume.":                        "Gepouseer. Ek ignoreer hierdie kanaal tot /resume.",
+		"Paused. I'll ignore this channel until %s, or /resume.":                 "Gepouseer. Ek ignoreer hierdie kanaal tot %s, of /resume.",
+		"The bot isn't paused here.":                                             "Die bot is nie hier gepouseer nie.",
+		"Resumed.":                                                               "Hervat.",
+		"No session variables are set. Use /env set KEY=VALUE to add one.":       "Geen sessieveranderlikes is gestel nie. Gebruik /env set SLEUTEL=WAARDE om een by te voeg.",
+		"Session variables: %s":                                                  "Sessieveranderlikes: %s",
+		"Use /env set KEY=VALUE, with a name such as TEST_DATABASE_URL.":         "Gebruik /env set SLEUTEL=WAARDE, met 'n naam soos TEST_DATABASE_URL.",
+		"Set %s for this session's tool calls.":                                  "%s gestel vir hierdie sessie se gereedskapoproepe.",
+		"Use /env unset KEY.":                                                    "Gebruik /env unset SLEUTEL.",
+		"%s isn't set.":                                                          "%s is nie gestel nie.",
+		"Unset %s.":                                                              "%s verwyder.",
+		"Cleared this session's variables.":                                      "Hierdie sessie se veranderlikes is uitgevee.",
+		"Use /env, /env set KEY=VALUE, /env unset KEY or /env clear.":            "Gebruik /env, /env set SLEUTEL=WAARDE, /env unset SLEUTEL of /env clear.",
+	},
+}
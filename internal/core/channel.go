@@ -7,6 +7,14 @@ type SessionKey string
 type Inbound struct {
 	SessionKey SessionKey
 	Text       string
+	// UserID and ChannelID identify the sender and the chat, namespaced by
+	// channel (e.g. "discord:123"). They scope remember/recall notes and
+	// /language choices; channels that cannot tell leave them empty.
+	UserID    string
+	ChannelID string
+	// GuildID names the server the chat belongs to (e.g. "discord:123"),
+	// whose /config settings apply. Empty outside servers.
+	GuildID string
 	// Attachments carries media refs for the current message.
 	// Populated by channels that translate inbound platform attachments (currently WhatsApp and Discord).
 	Attachments []AttachmentRef
@@ -14,6 +22,13 @@ type Inbound struct {
 	// Capabilities describes what the originating channel plugin supports.
 	// Used to gate per-session tool registration (e.g. react_emoji).
 	Capabilities Capabilities
+	// MaxResponseLen caps the final response in bytes. Longer responses are
+	// condensed by the backend (then truncated if still too long). Zero
+	// disables the cap.
+	MaxResponseLen int
+	// Settings is a snapshot of the session's settings, filled in by Bot
+	// just before dispatch. Channels leave it zero.
+	Settings Settings
 }
 
 type Capabilities struct {
@@ -23,6 +38,12 @@ type Capabilities struct {
 	// updates per turn (streaming-style chat). When false, the send_update
 	// tool is not registered and the system prompt does not mention it.
 	Updates bool
+	// Files indicates the channel's Outbound implements FileSender, so the
+	// write_artifact tool is registered.
+	Files bool
+	// Voice indicates the channel's Outbound implements VoiceSender, so
+	// /speak can be turned on.
+	Voice bool
 }
 
 type ChannelPlugin interface {
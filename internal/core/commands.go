@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// command handles one slash command for the inbound's session. args is the
+// text after the command name. The returned reply is posted back verbatim.
+type command func(b *Bot, ctx context.Context, in Inbound, args string) (string, error)
+
+var commands = map[string]command{
+	"verbosity":       (*Bot).cmdVerbosity,
+	"readonly":        (*Bot).cmdReadOnly,
+	"speak":           (*Bot).cmdSpeak,
+	"share":           (*Bot).cmdShare,
+	"link-session":    (*Bot).cmdLinkSession,
+	"confirm":         (*Bot).cmdConfirm,
+	"skill":           (*Bot).cmdSkill,
+	"new-session":     (*Bot).cmdNewSession,
+	"clone":           (*Bot).cmdClone,
+	"scratch":         (*Bot).cmdScratch,
+	"apply":           (*Bot).cmdApply,
+	"discard":         (*Bot).cmdDiscard,
+	"language":        (*Bot).cmdLanguage,
+	"timezone":        (*Bot).cmdTimezone,
+	"rename-session":  (*Bot).cmdRenameSession,
+	"tag-session":     (*Bot).cmdTagSession,
+	"pin-context":     (*Bot).cmdPinContext,
+	"pin-answer":      (*Bot).cmdPinAnswer,
+	"answers":         (*Bot).cmdAnswers,
+	"feedback":        (*Bot).cmdFeedback,
+	"retry":           (*Bot).cmdRetry,
+	"broadcast":       (*Bot).cmdBroadcast,
+	"pause":           (*Bot).cmdPause,
+	"resume":          (*Bot).cmdResume,
+	"env":             (*Bot).cmdEnv,
+	"persona":         (*Bot).cmdPersona,
+	"cache":           (*Bot).cmdCache,
+	"settings":        (*Bot).cmdSettings,
+	"current-session": (*Bot).cmdCurrentSession,
+	"config":          (*Bot).cmdConfig,
+	"status":          (*Bot).cmdStatus,
+	"batch":           (*Bot).cmdBatch,
+}
+
+// parseCommand splits "/name args" into its parts. Only registered names
+// count, so a message that merely starts with a path ("/etc/hosts is
+// empty?") still reaches the model.
+func parseCommand(text string) (command, string, string, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return nil, "", "", false
+	}
+	name, args, _ := strings.Cut(text[1:], " ")
+	name = strings.ToLower(name)
+	cmd, ok := commands[name]
+	if !ok {
+		return nil, "", "", false
+	}
+	return cmd, name, strings.TrimSpace(args), true
+}
+
+// runCommand executes cmd and posts its reply instead of starting a turn.
+func (b *Bot) runCommand(ctx context.Context, in Inbound, cmd command, name, args string) error {
+	reply, err := cmd(b, ctx, in, args)
+	if err != nil {
+		return errors.Wrapf(err, "/%s", name)
+	}
+	if reply != "" && in.Reply != nil {
+		if err := in.Reply.PostResponse(reply); err != nil {
+			return errors.Wrap(err, "posting command reply")
+		}
+	}
+	return nil
+}
+
+func (b *Bot) cmdVerbosity(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	if args == "" {
+		v := t.sessions.Settings(in.SessionKey).Verbosity
+		if v == "" {
+			v = VerbosityQuiet
+		}
+		return b.tr(in, "Verbosity is %s. Use /verbosity quiet|tools|thinking.", v), nil
+	}
+
+	v := Verbosity(strings.ToLower(args))
+	if v != VerbosityQuiet && v != VerbosityTools && v != VerbosityThinking {
+		return b.tr(in, "Unknown verbosity %q. Use /verbosity quiet|tools|thinking.", args), nil
+	}
+	err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+		s.Verbosity = v
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.tr(in, "Verbosity set to %s.", v), nil
+}
+
+func (b *Bot) cmdReadOnly(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	var on bool
+	switch strings.ToLower(args) {
+	case "":
+		if t.sessions.Settings(in.SessionKey).ReadOnly {
+			return b.tr(in, "Read-only mode is on. Use /readonly off to allow writes."), nil
+		}
+		return b.tr(in, "Read-only mode is off. Use /readonly on to block writes."), nil
+	case "on":
+		if t.readOnlyPerms == nil {
+			return b.tr(in, "Read-only mode is not available."), nil
+		}
+		on = true
+	case "off":
+		if t.sessions.ReadOnlyLocked(in.SessionKey) {
+			return b.tr(in, "This session's workspace is read-only. Use /new-session with another workspace to write."), nil
+		}
+	default:
+		return b.tr(in, "Unknown option %q. Use /readonly on|off.", args), nil
+	}
+
+	err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+		s.ReadOnly = on
+	})
+	if err != nil {
+		return "", err
+	}
+	if on {
+		return b.tr(in, "Read-only mode on: tools can read and search but not write, run commands or send mutating requests."), nil
+	}
+	return b.tr(in, "Read-only mode off: writes are enabled."), nil
+}
+
+func (b *Bot) cmdSpeak(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	var on bool
+	switch strings.ToLower(args) {
+	case "":
+		if t.sessions.Settings(in.SessionKey).Speak {
+			return b.tr(in, "Speech is on. Use /speak off to stop audio replies."), nil
+		}
+		return b.tr(in, "Speech is off. Use /speak on to also get replies as audio."), nil
+	case "on":
+		if b.speaker == nil || !in.Capabilities.Voice {
+			return b.tr(in, "Speech is not available here."), nil
+		}
+		on = true
+	case "off":
+	default:
+		return b.tr(in, "Unknown option %q. Use /speak on|off.", args), nil
+	}
+
+	err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+		s.Speak = on
+	})
+	if err != nil {
+		return "", err
+	}
+	if on {
+		return b.tr(in, "Speech on: replies will also be sent as audio."), nil
+	}
+	return b.tr(in, "Speech off."), nil
+}
+
+func (b *Bot) cmdShare(_ context.Context, in Inbound, _ string) (string, error) {
+	if b.sharer == nil {
+		return b.tr(in, "Sharing is not available."), nil
+	}
+	backend, err := b.tenantFor(in).sessions.GetSession(in.SessionKey)
+	if err != nil {
+		return b.tr(in, "Nothing to share yet."), nil
+	}
+	t, ok := backend.(Transcriber)
+	if !ok {
+		return b.tr(in, "This session cannot be shared."), nil
+	}
+	entries, ok := t.Transcript()
+	if !ok {
+		return b.tr(in, "A reply is still in progress. Use /share once it finishes."), nil
+	}
+	if len(entries) == 0 {
+		return b.tr(in, "Nothing to share yet."), nil
+	}
+
+	// The page is public to anyone with the link, so it gets the same
+	// redaction as anything posted to the chat.
+	filtered := &filteredOutbound{filters: b.filters}
+	for i := range entries {
+		entries[i].Text = filtered.apply(entries[i].Text)
+	}
+	url, expires, err := b.sharer.Share(entries)
+	if err != nil {
+		return "", err
+	}
+	return b.tr(in, "Transcript shared until %s: %s", b.formatTime(in, expires), url), nil
+}
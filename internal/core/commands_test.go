@@ -0,0 +1,330 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	a := assert.New(t)
+
+	_, name, args, ok := parseCommand("  /Verbosity tools ")
+	a.True(ok)
+	a.Equal("verbosity", name)
+	a.Equal("tools", args)
+
+	_, _, _, ok = parseCommand("/etc/hosts looks empty, why?")
+	a.False(ok, "unregistered names are ordinary messages")
+
+	_, _, _, ok = parseCommand("set /verbosity tools")
+	a.False(ok)
+}
+
+func TestHandleInbound_VerbosityCommandSetsSessionSetting(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot with one backend
+	be := &stubBackend{id: "b1", converseR: "ok"}
+	f := &stubFactory{next: func() Backend { return be }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	// ... verbosity is raised, then a normal message follows
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/verbosity tools", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "hi", Reply: out}))
+
+	// then
+	// ... the command never reached the backend and the setting rides on the next inbound
+	a.Equal([]string{"hi"}, be.messages)
+	a.Equal(VerbosityTools, be.lastInbound.Settings.Verbosity)
+	a.Equal([]string{"Verbosity set to tools.", "ok"}, out.posted)
+}
+
+func TestHandleInbound_VerbosityIsPerSession(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot where k1 has tool verbosity
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/verbosity tools"}))
+
+	// when
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k2", Text: "/verbosity", Reply: out}))
+
+	// then
+	a.Equal(VerbosityTools, mgr.Settings("k1").Verbosity)
+	a.Equal([]string{"Verbosity is quiet. Use /verbosity quiet|tools|thinking."}, out.posted)
+}
+
+func TestHandleInbound_VerbosityRejectsUnknownLevel(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/verbosity loud", Reply: out}))
+
+	// then
+	a.Equal(Verbosity(""), mgr.Settings("k1").Verbosity)
+	a.Equal([]string{`Unknown verbosity "loud". Use /verbosity quiet|tools|thinking.`}, out.posted)
+}
+
+func TestSessionManager_NewSession_KeepsSettings(t *testing.T) {
+	r := require.New(t)
+
+	// given
+	factory := &mockBackendFactory{backend: &mockBackend{sessionID: "s"}}
+	mgr := NewSessionManager(factory, nil)
+	r.NoError(mgr.UpdateSettings("k", Capabilities{}, func(s *Settings) { s.Verbosity = VerbosityTools }))
+
+	// when
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
+
+	// then
+	assert.Equal(t, VerbosityTools, mgr.Settings("k").Verbosity)
+}
+
+type namedPerms string
+
+func (namedPerms) Check(string, ToolInput) (bool, string) { return true, "" }
+
+type permsBackend struct {
+	stubBackend
+	perms []PermissionChecker
+}
+
+func (p *permsBackend) Converse(ctx context.Context, in Inbound, out Outbound, perms PermissionChecker) (string, error) {
+	p.perms = append(p.perms, perms)
+	return p.stubBackend.Converse(ctx, in, out, perms)
+}
+
+func TestHandleInbound_ReadOnlySwapsPermissionChecker(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot with a standard and a read-only checker
+	be := &permsBackend{}
+	f := &stubFactory{next: func() Backend { return be }}
+	bot := NewBot(NewSessionManager(f, nil), namedPerms("standard"))
+	bot.SetReadOnlyChecker(namedPerms("readonly"))
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: text}))
+	}
+
+	// when
+	// ... the session toggles read-only around two turns
+	send("/readonly on")
+	send("explore")
+	send("/readonly off")
+	send("now fix it")
+
+	// then
+	a.Equal([]PermissionChecker{namedPerms("readonly"), namedPerms("standard")}, be.perms)
+}
+
+func TestHandleInbound_ReadOnlyUnavailableWithoutChecker(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{} }}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/readonly on", Reply: out}))
+
+	// then
+	a.False(mgr.Settings("k1").ReadOnly)
+	a.Equal([]string{"Read-only mode is not available."}, out.posted)
+}
+
+type stubSpeaker struct{ spoken []string }
+
+func (s *stubSpeaker) Speak(_ context.Context, text string) ([]byte, string, error) {
+	s.spoken = append(s.spoken, text)
+	return []byte("audio:" + text), "audio/ogg", nil
+}
+
+type voiceResponder struct {
+	stubResponder
+	voices []string
+}
+
+func (v *voiceResponder) SendVoice(audio []byte, _ string) error {
+	v.voices = append(v.voices, string(audio))
+	return nil
+}
+
+func TestHandleInbound_SpeakSendsFilteredResponseAsVoice(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot with a speaker and a redacting filter
+	f := &stubFactory{next: func() Backend { return &stubBackend{converseR: "token is hunter2"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	speaker := &stubSpeaker{}
+	bot.SetSpeaker(speaker)
+	bot.AddOutboundFilter(func(s string) string { return strings.ReplaceAll(s, "hunter2", "[REDACTED]") })
+	out := &voiceResponder{}
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: text, Reply: out, Capabilities: Capabilities{Voice: true}}))
+	}
+
+	// when
+	send("what's the token?")
+	send("/speak on")
+	send("again?")
+
+	// then
+	// ... only the turn after /speak on is spoken, and it is filtered
+	a.Equal([]string{"token is [REDACTED]"}, speaker.spoken)
+	a.Equal([]string{"audio:token is [REDACTED]"}, out.voices)
+}
+
+func TestHandleInbound_SpeakUnavailableWithoutVoice(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a speaker, but a channel without voice support
+	f := &stubFactory{next: func() Backend { return &stubBackend{} }}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	bot.SetSpeaker(&stubSpeaker{})
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/speak on", Reply: out}))
+
+	// then
+	a.False(mgr.Settings("k1").Speak)
+	a.Equal([]string{"Speech is not available here."}, out.posted)
+}
+
+type transcriptBackend struct {
+	stubBackend
+	entries []TranscriptEntry
+	busy    bool
+}
+
+func (t *transcriptBackend) Transcript() ([]TranscriptEntry, bool) {
+	return append([]TranscriptEntry(nil), t.entries...), !t.busy
+}
+
+type stubSharer struct{ shared [][]TranscriptEntry }
+
+func (s *stubSharer) Share(entries []TranscriptEntry) (string, time.Time, error) {
+	s.shared = append(s.shared, entries)
+	return "https://bot.example/share/abc", time.Date(2026, 1, 8, 9, 30, 0, 0, time.UTC), nil
+}
+
+func TestHandleInbound_ShareFiltersTranscript(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a session whose transcript mentions a secret, and a redacting filter
+	be := &transcriptBackend{entries: []TranscriptEntry{
+		{Role: "user", Text: "password is hunter2"},
+		{Role: "assistant", Text: "noted"},
+	}}
+	f := &stubFactory{next: func() Backend { return be }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	sharer := &stubSharer{}
+	bot.SetSharer(sharer)
+	bot.AddOutboundFilter(func(s string) string { return strings.ReplaceAll(s, "hunter2", "[REDACTED]") })
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "hi", Reply: out}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/share", Reply: out}))
+
+	// then
+	r.Len(sharer.shared, 1)
+	a.Equal("password is [REDACTED]", sharer.shared[0][0].Text)
+	a.Equal("Transcript shared until 2026-01-08 09:30 UTC: https://bot.example/share/abc", out.posted[len(out.posted)-1])
+}
+
+func TestHandleInbound_ShareRefusals(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &transcriptBackend{busy: true, entries: []TranscriptEntry{{Role: "user", Text: "x"}}}
+	f := &stubFactory{next: func() Backend { return be }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+	share := func() string {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/share", Reply: out}))
+		return out.posted[len(out.posted)-1]
+	}
+
+	// when / then
+	a.Equal("Sharing is not available.", share())
+	bot.SetSharer(&stubSharer{})
+	a.Equal("Nothing to share yet.", share())
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "hi"}))
+	a.Equal("A reply is still in progress. Use /share once it finishes.", share())
+}
+
+type branchBackend struct {
+	stubBackend
+	err      error
+	finished []string
+}
+
+func (b *branchBackend) ApplyBranch(context.Context) (string, string, error) {
+	b.finished = append(b.finished, "apply")
+	return "switchboard/session-1", "main", b.err
+}
+
+func (b *branchBackend) DiscardBranch(context.Context) (string, string, error) {
+	b.finished = append(b.finished, "discard")
+	return "switchboard/session-1", "main", b.err
+}
+
+func TestHandleInbound_ApplyAndDiscard(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	backend := &branchBackend{}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return backend }}, nil), nil)
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "hi", Reply: out}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/apply", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/discard", Reply: out}))
+	backend.err = ErrNoBranch
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/apply", Reply: out}))
+
+	// then
+	a.Equal([]string{"apply", "discard", "apply"}, backend.finished)
+	a.Equal([]string{
+		"Merged switchboard/session-1 into main.",
+		"Deleted switchboard/session-1 and its changes; back on main.",
+		"This session has no branch.",
+	}, out.posted)
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxComposeBytes bounds a compose buffer so a runaway paste can't grow
+// the prompt without limit.
+const maxComposeBytes = 256 << 10
+
+const (
+	composeBegin  = "!begin"
+	composeEnd    = "!end"
+	composeCancel = "!cancel"
+)
+
+// composeBuffer accumulates the messages of one session between !begin and
+// !end so they can be submitted as a single prompt.
+type composeBuffer struct {
+	parts       []string
+	size        int
+	attachments []AttachmentRef
+}
+
+// composer holds the open compose buffers, keyed by session.
+type composer struct {
+	mu      sync.Mutex
+	buffers map[SessionKey]*composeBuffer
+}
+
+// IsControlMessage reports whether text is a bot command (/command or
+// !begin-style compose marker) rather than prompt content. Channels that
+// reshape message text use it to pass such messages through verbatim.
+func IsControlMessage(text string) bool {
+	t := strings.TrimSpace(text)
+	if _, _, _, ok := parseCommand(t); ok {
+		return true
+	}
+	word, _ := splitFirstWord(t)
+	switch word {
+	case composeBegin, composeEnd, composeCancel:
+		return true
+	}
+	return false
+}
+
+// compose runs before anything else in HandleInbound. It returns the
+// inbound to dispatch and true, or false when the message was absorbed
+// into (or ended without) a compose buffer. Text after !begin or !end on
+// the same message is kept as content.
+func (b *Bot) compose(in Inbound) (Inbound, bool) {
+	text := strings.TrimSpace(in.Text)
+	word, rest := splitFirstWord(text)
+
+	b.composer.mu.Lock()
+	defer b.composer.mu.Unlock()
+	buf, open := b.composer.buffers[in.SessionKey]
+
+	switch {
+	case word == composeBegin:
+		buf = &composeBuffer{}
+		b.composer.buffers[in.SessionKey] = buf
+		buf.add(rest, in.Attachments)
+		postReply(in, b.tr(in, "Composing. Send the rest of your prompt, then !end to submit or !cancel to discard."))
+		return in, false
+
+	case !open:
+		return in, true
+
+	case word == composeCancel:
+		delete(b.composer.buffers, in.SessionKey)
+		postReply(in, b.tr(in, "Compose discarded."))
+		return in, false
+
+	case word == composeEnd:
+		delete(b.composer.buffers, in.SessionKey)
+		buf.add(rest, in.Attachments)
+		if len(buf.parts) == 0 && len(buf.attachments) == 0 {
+			postReply(in, b.tr(in, "Nothing to submit."))
+			return in, false
+		}
+		in.Text = strings.Join(buf.parts, "\n")
+		in.Attachments = buf.attachments
+		return in, true
+
+	default:
+		if !buf.add(in.Text, in.Attachments) {
+			postReply(in, b.tr(in, "Compose buffer is full. Send !end to submit what you have."))
+			return in, false
+		}
+		if in.Reply != nil && in.Capabilities.Reactions {
+			_ = in.Reply.AddReaction("📝")
+		}
+		return in, false
+	}
+}
+
+// add appends a part, reporting false if it would overflow the buffer.
+func (c *composeBuffer) add(text string, atts []AttachmentRef) bool {
+	if c.size+len(text) > maxComposeBytes {
+		return false
+	}
+	if text != "" {
+		c.parts = append(c.parts, text)
+		c.size += len(text)
+	}
+	c.attachments = append(c.attachments, atts...)
+	return true
+}
+
+// splitFirstWord returns the lower-cased first word of s and the trimmed
+// remainder. Pasted content often follows a marker on a new line.
+func splitFirstWord(s string) (string, string) {
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return strings.ToLower(s), ""
+	}
+	return strings.ToLower(s[:i]), strings.TrimSpace(s[i:])
+}
+
+func postReply(in Inbound, msg string) {
+	if in.Reply != nil {
+		_ = in.Reply.PostResponse(msg)
+	}
+}
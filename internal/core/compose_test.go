@@ -0,0 +1,105 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newComposeBot() (*Bot, *stubBackend) {
+	be := &stubBackend{id: "b1", converseR: "ok"}
+	f := &stubFactory{next: func() Backend { return be }}
+	return NewBot(NewSessionManager(f, nil), nil), be
+}
+
+func TestHandleInbound_ComposeSubmitsAccumulatedPrompt(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot and a channel that supports reactions
+	bot, be := newComposeBot()
+	out := &stubResponder{}
+	send := func(text string, atts ...AttachmentRef) {
+		r.NoError(bot.HandleInbound(Inbound{
+			SessionKey:   "k1",
+			Text:         text,
+			Attachments:  atts,
+			Reply:        out,
+			Capabilities: Capabilities{Reactions: true},
+		}))
+	}
+
+	// when
+	// ... a prompt is composed over several messages
+	send("!begin\nhere is the log:")
+	send("  line 1\n  line 2")
+	send("line 3", AttachmentRef{Path: "/tmp/a.log"})
+	send("!end what went wrong?")
+
+	// then
+	// ... the backend saw one prompt with every part, in order
+	r.Len(be.messages, 1)
+	a.Equal("here is the log:\n  line 1\n  line 2\nline 3\nwhat went wrong?", be.messages[0])
+	a.Len(be.lastInbound.Attachments, 1)
+	a.Equal([]string{"📝", "📝"}, out.reactions)
+}
+
+func TestHandleInbound_ComposeCancelDiscards(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot, be := newComposeBot()
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "!begin"}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "draft"}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "!cancel"}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "fresh question"}))
+
+	// then
+	// ... only the message after the cancel reached the backend
+	a.Equal([]string{"fresh question"}, be.messages)
+}
+
+func TestHandleInbound_ComposeIsPerSession(t *testing.T) {
+	r := require.New(t)
+
+	// given
+	// ... k1 is composing
+	bot, be := newComposeBot()
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "!begin"}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k2", Text: "unrelated"}))
+
+	// then
+	// ... k2 is dispatched normally
+	assert.Equal(t, []string{"unrelated"}, be.messages)
+}
+
+func TestHandleInbound_EndWithoutBeginIsOrdinaryText(t *testing.T) {
+	r := require.New(t)
+
+	// given
+	bot, be := newComposeBot()
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "!end"}))
+
+	// then
+	assert.Equal(t, []string{"!end"}, be.messages)
+}
+
+func TestIsControlMessage(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(IsControlMessage("/verbosity tools"))
+	a.True(IsControlMessage(" !BEGIN\npasted"))
+	a.True(IsControlMessage("!end"))
+	a.False(IsControlMessage("/etc/hosts"))
+	a.False(IsControlMessage("!beginning"))
+	a.False(IsControlMessage("hello"))
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+)
+
+const condensePrompt = "Your previous response was %d bytes, but this channel only fits %d. " +
+	"Reply again with a condensed version of that response that fits within the limit. " +
+	"Keep the essential answer, drop detail, and do not call any tools."
+
+const truncatedSuffix = "\n… (truncated)"
+
+// condense asks the backend for a shorter version of response when it
+// exceeds max. If the backend fails or still overshoots, the text is
+// truncated so the channel is never flooded.
+func (b *Bot) condense(ctx context.Context, backend Backend, in Inbound, response string, max int) string {
+	slog.Info("response over channel limit, condensing", "key", string(in.SessionKey), "len", len(response), "max", max)
+
+	// The follow-up runs under the turn's settings and checker, so
+	// /readonly, scratch dirs, generation settings and /env still apply.
+	short, err := backend.Converse(ctx, Inbound{
+		SessionKey:   in.SessionKey,
+		Text:         fmt.Sprintf(condensePrompt, len(response), max),
+		Capabilities: in.Capabilities,
+		Settings:     in.Settings,
+	}, in.Reply, reportDenials(b.tenantFor(in).permsFor(in.SessionKey, in.Settings), in.Reply))
+	if err != nil {
+		slog.Warn("condensing response failed, truncating", "error", err)
+		short = ""
+	}
+	if short == "" {
+		short = response
+	}
+	return truncateText(short, max)
+}
+
+// truncateText cuts s to at most max bytes on a rune boundary, marking the
+// cut with truncatedSuffix when there is room for it.
+func truncateText(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	suffix := truncatedSuffix
+	if max < len(suffix) {
+		suffix = ""
+	}
+	cut := max - len(suffix)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + suffix
+}
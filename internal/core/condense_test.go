@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedBackend returns replies in order, recording every inbound text
+// and the settings and checker it ran with.
+type scriptedBackend struct {
+	stubBackend
+	replies  []string
+	errs     []error
+	settings []Settings
+	perms    []PermissionChecker
+}
+
+func (s *scriptedBackend) Converse(_ context.Context, in Inbound, _ Outbound, perms PermissionChecker) (string, error) {
+	s.messages = append(s.messages, in.Text)
+	s.settings = append(s.settings, in.Settings)
+	s.perms = append(s.perms, perms)
+	i := len(s.messages) - 1
+	var reply string
+	var err error
+	if i < len(s.replies) {
+		reply = s.replies[i]
+	}
+	if i < len(s.errs) {
+		err = s.errs[i]
+	}
+	return reply, err
+}
+
+func newScriptedBot(be Backend) *Bot {
+	return NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+}
+
+func TestHandleInbound_UnderLimitPostsAsIs(t *testing.T) {
+	// given
+	be := &scriptedBackend{replies: []string{"short"}}
+	r := &stubResponder{}
+
+	// when
+	err := newScriptedBot(be).HandleInbound(Inbound{SessionKey: "k", Text: "q", Reply: r, MaxResponseLen: 100})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short"}, r.posted)
+	assert.Len(t, be.messages, 1)
+}
+
+func TestHandleInbound_OverLimitAsksBackendToCondense(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	be := &scriptedBackend{replies: []string{strings.Repeat("x", 50), "condensed"}}
+	r := &stubResponder{}
+
+	// when
+	err := newScriptedBot(be).HandleInbound(Inbound{SessionKey: "k", Text: "q", Reply: r, MaxResponseLen: 20})
+
+	// then
+	require.NoError(t, err)
+	a.Equal([]string{"condensed"}, r.posted)
+	require.Len(t, be.messages, 2)
+	a.Contains(be.messages[1], "50 bytes")
+	a.Contains(be.messages[1], "only fits 20")
+}
+
+func TestHandleInbound_CondenseKeepsSessionSettingsAndPermissions(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a read-only session with a variable set
+	be := &scriptedBackend{replies: []string{strings.Repeat("x", 50), "condensed"}}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), namedPerms("standard"))
+	bot.SetReadOnlyChecker(namedPerms("readonly"))
+	in := Inbound{SessionKey: "k", Reply: &stubResponder{}, MaxResponseLen: 20}
+	for _, text := range []string{"/readonly on", "/env set TEST_DATABASE_URL=postgres://db"} {
+		in.Text = text
+		r.NoError(bot.HandleInbound(in))
+	}
+
+	// when
+	in.Text = "q"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the condense follow-up runs like the turn it shortens
+	r.Len(be.perms, 2)
+	a.Equal([]PermissionChecker{namedPerms("readonly"), namedPerms("readonly")}, be.perms)
+	a.True(be.settings[1].ReadOnly)
+	a.Equal(map[string]string{"TEST_DATABASE_URL": "postgres://db"}, be.settings[1].Env)
+}
+
+func TestHandleInbound_CondenseFailureTruncates(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	long := strings.Repeat("y", 100)
+	be := &scriptedBackend{replies: []string{long, ""}, errs: []error{nil, errors.New("boom")}}
+	r := &stubResponder{}
+
+	// when
+	err := newScriptedBot(be).HandleInbound(Inbound{SessionKey: "k", Text: "q", Reply: r, MaxResponseLen: 40})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, r.posted, 1)
+	a.LessOrEqual(len(r.posted[0]), 40)
+	a.True(strings.HasSuffix(r.posted[0], truncatedSuffix))
+}
+
+func TestTruncateText_RespectsRuneBoundaries(t *testing.T) {
+	got := truncateText(strings.Repeat("é", 20), 21)
+
+	assert.LessOrEqual(t, len(got), 21)
+	assert.True(t, strings.HasSuffix(got, truncatedSuffix))
+	assert.True(t, strings.HasPrefix(got, "é"))
+}
+
+func TestTruncateText_MaxBelowSuffix(t *testing.T) {
+	got := truncateText(strings.Repeat("é", 20), 5)
+
+	assert.Equal(t, "éé", got)
+}
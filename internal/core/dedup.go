@@ -0,0 +1,40 @@
+package core
+
+import "sync"
+
+// DefaultDedupSize is how many message IDs a Dedup remembers. Platforms
+// redeliver within seconds of a reconnect, so this only has to cover a
+// short burst of traffic.
+const DefaultDedupSize = 1024
+
+// Dedup remembers the most recently handled message IDs of one platform,
+// so a message the platform delivers twice (Discord after a gateway
+// reconnect, WhatsApp after a resync) runs one turn, not two. The oldest
+// ID is forgotten once size are held.
+type Dedup struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string
+	size  int
+}
+
+// NewDedup returns a Dedup holding up to size IDs.
+func NewDedup(size int) *Dedup {
+	return &Dedup{seen: make(map[string]struct{}), size: size}
+}
+
+// First records id and reports whether it hadn't been seen before.
+func (d *Dedup) First(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+	d.seen[id] = struct{}{}
+	d.order = append(d.order, id)
+	if len(d.order) > d.size {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	return true
+}
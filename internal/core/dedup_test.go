@@ -0,0 +1,23 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedup_First(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	d := NewDedup(2)
+
+	// when / then
+	a.True(d.First("m1"))
+	a.False(d.First("m1"))
+	a.True(d.First("m2"))
+	a.True(d.First("m3"))
+	// ... m1 was the oldest of three, so it has been forgotten
+	a.True(d.First("m1"))
+	a.False(d.First("m3"))
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// envName is a variable name /env accepts: what a shell would.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SetLogScrubber makes every value set with /env redacted from the logs.
+// Without one, values are still never echoed back, but a tool's output
+// that prints one may be logged.
+func (b *Bot) SetLogScrubber(s SecretScrubber) {
+	b.logSecrets = s
+}
+
+// cmdEnv manages the session's variables for tool calls: "/env" lists
+// their names, "/env set KEY=VALUE" and "/env unset KEY" change one and
+// "/env clear" drops them all. Replies name variables but never show
+// their values.
+func (b *Bot) cmdEnv(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	sub, rest, _ := strings.Cut(args, " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(sub) {
+	case "":
+		names := slices.Sorted(maps.Keys(t.sessions.Settings(in.SessionKey).Env))
+		if len(names) == 0 {
+			return b.tr(in, "No session variables are set. Use /env set KEY=VALUE to add one."), nil
+		}
+		return b.tr(in, "Session variables: %s", strings.Join(names, ", ")), nil
+	case "set":
+		name, value, ok := strings.Cut(rest, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envName.MatchString(name) {
+			return b.tr(in, "Use /env set KEY=VALUE, with a name such as TEST_DATABASE_URL."), nil
+		}
+		if b.logSecrets != nil {
+			b.logSecrets.Add(value)
+		}
+		err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+			env := maps.Clone(s.Env)
+			if env == nil {
+				env = make(map[string]string)
+			}
+			env[name] = value
+			s.Env = env
+		})
+		if err != nil {
+			return "", err
+		}
+		return b.tr(in, "Set %s for this session's tool calls.", name), nil
+	case "unset":
+		if !envName.MatchString(rest) {
+			return b.tr(in, "Use /env unset KEY."), nil
+		}
+		var had bool
+		err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+			if _, had = s.Env[rest]; had {
+				env := maps.Clone(s.Env)
+				delete(env, rest)
+				s.Env = env
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+		if !had {
+			return b.tr(in, "%s isn't set.", rest), nil
+		}
+		return b.tr(in, "Unset %s.", rest), nil
+	case "clear":
+		err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+			s.Env = nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return b.tr(in, "Cleared this session's variables."), nil
+	}
+	return b.tr(in, "Use /env, /env set KEY=VALUE, /env unset KEY or /env clear."), nil
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingScrubber records the secrets the bot asks it to redact.
+type recordingScrubber struct {
+	secrets []string
+}
+
+func (s *recordingScrubber) Add(secret string) {
+	s.secrets = append(s.secrets, secret)
+}
+
+func TestHandleInbound_EnvSetsSessionVariables(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	sm := NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil)
+	bot := NewBot(sm, nil)
+	scrubber := &recordingScrubber{}
+	bot.SetLogScrubber(scrubber)
+	out := &stubResponder{}
+	in := Inbound{SessionKey: "discord:thread:1", Reply: out}
+
+	// when
+	in.Text = "/env set TEST_DATABASE_URL=postgres://u:pw@db/test?sslmode=disable"
+	r.NoError(bot.HandleInbound(in))
+	before := sm.Settings(in.SessionKey).Env
+	in.Text = "/env set API_TOKEN=s3cret-token"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/env"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	a.Equal(map[string]string{"TEST_DATABASE_URL": "postgres://u:pw@db/test?sslmode=disable", "API_TOKEN": "s3cret-token"}, sm.Settings(in.SessionKey).Env)
+	a.Equal([]string{"postgres://u:pw@db/test?sslmode=disable", "s3cret-token"}, scrubber.secrets)
+	// ... a turn holding the old map doesn't see later changes
+	a.Len(before, 1)
+	r.Len(out.posted, 3)
+	a.Equal("Set TEST_DATABASE_URL for this session's tool calls.", out.posted[0])
+	a.Equal("Session variables: API_TOKEN, TEST_DATABASE_URL", out.posted[2])
+	for _, reply := range out.posted {
+		a.NotContains(reply, "s3cret")
+		a.NotContains(reply, "postgres://")
+	}
+}
+
+func TestHandleInbound_EnvUnsetAndClear(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	sm := NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil)
+	bot := NewBot(sm, nil)
+	out := &stubResponder{}
+	in := Inbound{SessionKey: "discord:thread:1", Reply: out}
+	for _, text := range []string{"/env set A=1", "/env set B=2"} {
+		in.Text = text
+		r.NoError(bot.HandleInbound(in))
+	}
+
+	// when
+	in.Text = "/env unset A"
+	r.NoError(bot.HandleInbound(in))
+	afterUnset := sm.Settings(in.SessionKey).Env
+	in.Text = "/env unset A"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/env clear"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	a.Equal(map[string]string{"B": "2"}, afterUnset)
+	a.Empty(sm.Settings(in.SessionKey).Env)
+	a.Equal([]string{"Unset A.", "A isn't set.", "Cleared this session's variables."}, out.posted[2:])
+}
+
+func TestHandleInbound_EnvRejectsBadInput(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"set without value", "/env set TOKEN", "Use /env set KEY=VALUE"},
+		{"set with bad name", "/env set 1TOKEN=x", "Use /env set KEY=VALUE"},
+		{"set with spaced name", "/env set MY TOKEN=x", "Use /env set KEY=VALUE"},
+		{"unset with bad name", "/env unset A-B", "Use /env unset KEY."},
+		{"unknown subcommand", "/env show", "Use /env, /env set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := require.New(t)
+
+			// given
+			sm := NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil)
+			bot := NewBot(sm, nil)
+			out := &stubResponder{}
+			in := Inbound{SessionKey: "discord:thread:1", Reply: out, Text: tt.text}
+
+			// when
+			r.NoError(bot.HandleInbound(in))
+
+			// then
+			r.Len(out.posted, 1)
+			a.True(strings.HasPrefix(out.posted[0], tt.want), out.posted[0])
+			a.Empty(sm.Settings(in.SessionKey).Env)
+		})
+	}
+}
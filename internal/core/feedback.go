@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// SetFeedback enables /feedback, recording ratings to r. With ask, each
+// final response also offers 👍/👎 where the Outbound is a
+// FeedbackPrompter.
+func (b *Bot) SetFeedback(r FeedbackRecorder, ask bool) {
+	b.feedback = r
+	b.askFeedback = ask
+}
+
+// newTurnID names a turn for feedback.
+func newTurnID() string {
+	var buf [6]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+// promptFeedback offers ratings for the response just posted to in.
+// Failures are logged only: the answer is already there.
+func (b *Bot) promptFeedback(in Inbound, turnID string) {
+	p, ok := outboundAs[FeedbackPrompter](in.Reply)
+	if b.feedback == nil || !b.askFeedback || !ok {
+		return
+	}
+	if err := p.PromptFeedback(turnID); err != nil {
+		slog.Warn("prompting for feedback", "key", string(in.SessionKey), "turn", turnID, "error", err)
+	}
+}
+
+// cmdFeedback rates the session's latest answer, or the turn named after
+// the rating. A named turn comes from a platform reaction, so it is
+// recorded without a reply.
+func (b *Bot) cmdFeedback(_ context.Context, in Inbound, args string) (string, error) {
+	if b.feedback == nil {
+		return b.tr(in, "Feedback is not being collected."), nil
+	}
+	verdict, turnID, _ := strings.Cut(args, " ")
+	var rating int
+	switch strings.ToLower(verdict) {
+	case "up", "good", "👍":
+		rating = 1
+	case "down", "bad", "👎":
+		rating = -1
+	default:
+		return b.tr(in, "Use /feedback up or /feedback down to rate the latest answer."), nil
+	}
+	turnID = strings.TrimSpace(turnID)
+	silent := turnID != ""
+	if !silent {
+		latest, ok := b.latest.get(in.SessionKey)
+		if !ok {
+			return b.tr(in, "There is no answer to rate yet."), nil
+		}
+		turnID = latest.TurnID
+	}
+	b.feedback.RecordFeedback(Feedback{
+		At:         time.Now(),
+		Tenant:     b.tenantFor(in).name,
+		SessionKey: in.SessionKey,
+		TurnID:     turnID,
+		UserID:     in.UserID,
+		ChannelID:  in.ChannelID,
+		Rating:     rating,
+	})
+	if silent {
+		return "", nil
+	}
+	return b.tr(in, "Thanks for the feedback."), nil
+}
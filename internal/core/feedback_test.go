@@ -0,0 +1,119 @@
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubFeedback struct {
+	mu    sync.Mutex
+	rated []Feedback
+}
+
+func (s *stubFeedback) RecordFeedback(f Feedback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rated = append(s.rated, f)
+}
+
+type feedbackResponder struct {
+	stubResponder
+	prompted []string
+}
+
+func (f *feedbackResponder) PromptFeedback(turnID string) error {
+	f.prompted = append(f.prompted, turnID)
+	return nil
+}
+
+func TestHandleInbound_FeedbackRatesLatestAnswer(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... feedback prompts on and one answered question
+	be := &stubBackend{id: "b", converseR: "42"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	rec := &stubFeedback{}
+	bot.SetFeedback(rec, true)
+	out := &feedbackResponder{}
+	in := Inbound{SessionKey: "k", UserID: "u:1", ChannelID: "c:1", Text: "what is the answer?", Reply: out}
+	r.NoError(bot.HandleInbound(in))
+
+	// when
+	in.Text = "/feedback down"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the answer asked for a rating and the rating names its turn
+	r.Len(out.prompted, 1)
+	r.Len(rec.rated, 1)
+	a.Equal(out.prompted[0], rec.rated[0].TurnID)
+	a.Equal(-1, rec.rated[0].Rating)
+	a.Equal("u:1", rec.rated[0].UserID)
+	a.Equal(SessionKey("k"), rec.rated[0].SessionKey)
+	a.Equal([]string{"42", "Thanks for the feedback."}, out.posted)
+}
+
+func TestHandleInbound_FeedbackPromptsThroughFiltersAndMirror(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... the filter and mirror wrappers main always installs
+	be := &stubBackend{id: "b", converseR: "42"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.AddOutboundFilter(func(s string) string { return s })
+	bot.SetMirror(&recordingMirror{out: &stubResponder{}})
+	bot.SetFeedback(&stubFeedback{}, true)
+	out := &feedbackResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c:1", Text: "what is the answer?", Reply: out}))
+
+	// then
+	a.Len(out.prompted, 1)
+}
+
+func TestHandleInbound_FeedbackForNamedTurnIsSilent(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil), nil)
+	rec := &stubFeedback{}
+	bot.SetFeedback(rec, false)
+	out := &stubResponder{}
+
+	// when
+	// ... a reaction hands over a rating for a named turn
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/feedback up 0a1b2c", Reply: out}))
+
+	// then
+	r.Len(rec.rated, 1)
+	a.Equal("0a1b2c", rec.rated[0].TurnID)
+	a.Equal(1, rec.rated[0].Rating)
+	a.Empty(out.posted)
+}
+
+func TestHandleInbound_FeedbackWithoutAnswer(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil), nil)
+	rec := &stubFeedback{}
+	bot.SetFeedback(rec, false)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/feedback up", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/feedback meh", Reply: out}))
+
+	// then
+	a.Empty(rec.rated)
+	a.Equal([]string{"There is no answer to rate yet.", "Use /feedback up or /feedback down to rate the latest answer."}, out.posted)
+}
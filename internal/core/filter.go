@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ErrFilesUnsupported is returned by SendFile when the wrapped Outbound
+// cannot deliver files.
+var ErrFilesUnsupported = errors.New("channel cannot send files")
+
+// ErrVoiceUnsupported is returned by SendVoice when the wrapped Outbound
+// cannot deliver audio.
+var ErrVoiceUnsupported = errors.New("channel cannot send voice")
+
+// TextFilter rewrites outbound text before it reaches a channel. Filters
+// may mask parts of the text or replace it wholesale.
+type TextFilter func(string) string
+
+// filteredOutbound runs PostResponse and SendUpdate content through a chain
+// of TextFilters before delegating to the wrapped Outbound.
+type filteredOutbound struct {
+	Outbound
+	filters []TextFilter
+}
+
+// FilterOutbound wraps out so every response and progress update passes
+// through filters in order. Returns out unchanged when there is nothing to
+// apply.
+func FilterOutbound(out Outbound, filters ...TextFilter) Outbound {
+	if out == nil || len(filters) == 0 {
+		return out
+	}
+	return &filteredOutbound{Outbound: out, filters: filters}
+}
+
+// wrappedOutbound is implemented by Outbounds that decorate another, such
+// as the filter and mirror wrappers.
+type wrappedOutbound interface {
+	Unwrap() Outbound
+}
+
+// outboundAs finds a T among out and the Outbounds it wraps, for optional
+// interfaces that send no text, such as MessageFetcher. Ones that do, like
+// FileSender, are implemented by the wrappers themselves so the filters
+// still apply.
+func outboundAs[T any](out Outbound) (T, bool) {
+	for out != nil {
+		if t, ok := out.(T); ok {
+			return t, true
+		}
+		w, ok := out.(wrappedOutbound)
+		if !ok {
+			break
+		}
+		out = w.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// Unwrap returns the Outbound f filters.
+func (f *filteredOutbound) Unwrap() Outbound {
+	return f.Outbound
+}
+
+func (f *filteredOutbound) apply(s string) string {
+	for _, fn := range f.filters {
+		s = fn(s)
+	}
+	return s
+}
+
+func (f *filteredOutbound) PostResponse(content string) error {
+	return f.Outbound.PostResponse(f.apply(content))
+}
+
+func (f *filteredOutbound) SendUpdate(message string) error {
+	return f.Outbound.SendUpdate(f.apply(message))
+}
+
+// SendFile filters text files, since artifacts are what the model wrote
+// and can leak secrets the same way a response can. Binary files (images,
+// screenshots) pass through untouched.
+func (f *filteredOutbound) SendFile(name string, content []byte) error {
+	fs, ok := f.Outbound.(FileSender)
+	if !ok {
+		return ErrFilesUnsupported
+	}
+	if utf8.Valid(content) && !bytes.ContainsRune(content, 0) {
+		content = []byte(f.apply(string(content)))
+	}
+	return fs.SendFile(name, content)
+}
+
+// SendVoice passes audio through unfiltered; callers synthesize it from
+// text that has already been through the filters.
+func (f *filteredOutbound) SendVoice(audio []byte, mimeType string) error {
+	vs, ok := f.Outbound.(VoiceSender)
+	if !ok {
+		return ErrVoiceUnsupported
+	}
+	return vs.SendVoice(audio, mimeType)
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterOutbound_AppliesFiltersInOrder(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	r := &stubResponder{}
+	out := FilterOutbound(r,
+		func(s string) string { return strings.ReplaceAll(s, "secret", "[x]") },
+		strings.ToUpper,
+	)
+
+	// when
+	_ = out.PostResponse("the secret")
+	_ = out.SendUpdate("a secret update")
+	_ = out.AddReaction("👍")
+
+	// then
+	a.Equal([]string{"THE [X]"}, r.posted)
+	a.Equal([]string{"A [X] UPDATE"}, r.updates)
+	a.Equal([]string{"👍"}, r.reactions)
+}
+
+func TestFilterOutbound_NoFiltersReturnsOriginal(t *testing.T) {
+	r := &stubResponder{}
+
+	assert.Same(t, r, FilterOutbound(r))
+}
+
+func TestHandleInbound_AppliesOutboundFilters(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	be := &stubBackend{id: "b1", converseR: "key=abc123"}
+	f := &stubFactory{next: func() Backend { return be }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.AddOutboundFilter(func(s string) string { return strings.ReplaceAll(s, "abc123", "[REDACTED]") })
+	r := &stubResponder{}
+
+	// when
+	err := bot.HandleInbound(Inbound{SessionKey: "k", Text: "hi", Reply: r})
+
+	// then
+	a.NoError(err)
+	a.Equal([]string{"key=[REDACTED]"}, r.posted)
+}
+
+type fileStub struct {
+	stubResponder
+	files map[string]string
+}
+
+func (f *fileStub) SendFile(name string, content []byte) error {
+	f.files[name] = string(content)
+	return nil
+}
+
+func TestFilterOutbound_SendFileIsFiltered(t *testing.T) {
+	// given
+	inner := &fileStub{files: map[string]string{}}
+	out := FilterOutbound(inner, func(s string) string { return strings.ReplaceAll(s, "hunter2", "[REDACTED]") })
+
+	// when
+	err := out.(FileSender).SendFile("env.sh", []byte("PASS=hunter2"))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "PASS=[REDACTED]", inner.files["env.sh"])
+}
+
+func TestFilterOutbound_SendFileLeavesBinaryAlone(t *testing.T) {
+	// given
+	inner := &fileStub{files: map[string]string{}}
+	out := FilterOutbound(inner, func(string) string { return "[BLOCKED]" })
+	png := "\x89PNG\r\n\x1a\n\x00\x00hunter2"
+
+	// when
+	err := out.(FileSender).SendFile("image.png", []byte(png))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, png, inner.files["image.png"])
+}
+
+func TestFilterOutbound_SendFileUnsupported(t *testing.T) {
+	// given
+	out := FilterOutbound(&stubResponder{}, func(s string) string { return s })
+
+	// when
+	err := out.(FileSender).SendFile("a.txt", []byte("x"))
+
+	// then
+	assert.ErrorIs(t, err, ErrFilesUnsupported)
+}
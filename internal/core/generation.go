@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Bounds for /settings. The API enforces the real per-model limits.
+const (
+	maxMaxTokens      = 128000
+	minThinkingBudget = 1024
+)
+
+const settingsUsage = "Use /settings max_tokens|temperature|thinking <value|default>."
+
+// String renders the overrides the way /settings takes them.
+func (g Generation) String() string {
+	maxTokens, temperature, thinking := "default", "default", "default"
+	if g.MaxTokens > 0 {
+		maxTokens = strconv.Itoa(g.MaxTokens)
+	}
+	if g.Temperature != nil {
+		temperature = strconv.FormatFloat(*g.Temperature, 'g', -1, 64)
+	}
+	switch {
+	case g.ThinkingBudget > 0:
+		thinking = strconv.Itoa(g.ThinkingBudget)
+	case g.ThinkingBudget < 0:
+		thinking = "off"
+	}
+	return "max_tokens " + maxTokens + ", temperature " + temperature + ", thinking " + thinking
+}
+
+// cmdSettings shows or changes the session's generation parameters.
+func (b *Bot) cmdSettings(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	if args == "" {
+		return b.tr(in, "Generation: %s. "+settingsUsage, t.sessions.Settings(in.SessionKey).Generation), nil
+	}
+	key, value := splitFirstWord(args)
+	value = strings.ToLower(value)
+	reset := value == "default"
+
+	var apply func(*Generation)
+	switch key {
+	case "max_tokens":
+		var n int
+		if !reset {
+			var err error
+			if n, err = strconv.Atoi(value); err != nil || n < 1 || n > maxMaxTokens {
+				return b.tr(in, "max_tokens must be between 1 and %d, or default.", maxMaxTokens), nil
+			}
+		}
+		apply = func(g *Generation) { g.MaxTokens = n }
+	case "temperature":
+		var t *float64
+		if !reset {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v < 0 || v > 1 {
+				return b.tr(in, "temperature must be between 0 and 1, or default."), nil
+			}
+			t = &v
+		}
+		apply = func(g *Generation) { g.Temperature = t }
+	case "thinking":
+		var n int
+		switch {
+		case reset:
+		case value == "off":
+			n = -1
+		default:
+			var err error
+			if n, err = strconv.Atoi(value); err != nil || n < minThinkingBudget {
+				return b.tr(in, "thinking must be a budget of at least %d tokens, off, or default.", minThinkingBudget), nil
+			}
+		}
+		apply = func(g *Generation) { g.ThinkingBudget = n }
+	default:
+		return b.tr(in, "Unknown setting %q. "+settingsUsage, key), nil
+	}
+
+	err := t.sessions.UpdateSettings(in.SessionKey, in.Capabilities, func(s *Settings) {
+		apply(&s.Generation)
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.tr(in, "Generation: %s.", t.sessions.Settings(in.SessionKey).Generation), nil
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_SettingsCommand(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	sm := NewSessionManager(f, nil)
+	bot := NewBot(sm, nil)
+	out := &stubResponder{}
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: text, Reply: out}))
+	}
+
+	// when
+	send("/settings max_tokens 2048")
+	send("/settings temperature 0.3")
+	send("/settings thinking off")
+	send("/settings temperature default")
+	send("/settings temperature 2")
+	send("/settings thinking 10")
+	send("/settings top_p 1")
+	send("/settings")
+
+	// then
+	a.Equal([]string{
+		"Generation: max_tokens 2048, temperature default, thinking default.",
+		"Generation: max_tokens 2048, temperature 0.3, thinking default.",
+		"Generation: max_tokens 2048, temperature 0.3, thinking off.",
+		"Generation: max_tokens 2048, temperature default, thinking off.",
+		"temperature must be between 0 and 1, or default.",
+		"thinking must be a budget of at least 1024 tokens, off, or default.",
+		`Unknown setting "top_p". Use /settings max_tokens|temperature|thinking <value|default>.`,
+		"Generation: max_tokens 2048, temperature default, thinking off. Use /settings max_tokens|temperature|thinking <value|default>.",
+	}, out.posted)
+	a.Equal(Generation{MaxTokens: 2048, ThinkingBudget: -1}, sm.Settings("k1").Generation)
+}
+
+func TestHandleInbound_CurrentSessionCommand(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a named, tagged session with a persona and tuned generation
+	be := &personaBackend{stubBackend: stubBackend{id: "sess-1"}, persona: Persona{Name: "reviewer"}}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out := &stubResponder{}
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: text, Reply: out}))
+	}
+	send("/rename-session api work")
+	send("/tag-session backend")
+	send("/settings max_tokens 4096")
+
+	// when
+	send("/current-session")
+
+	// then
+	r.Len(out.posted, 4)
+	a.Equal("Session sess-1 (name: api work, tags: backend)\n"+
+		"Work dir: default\n"+
+		"Verbosity quiet, read-only off, speech off, persona reviewer\n"+
+		"Generation: max_tokens 4096, temperature default, thinking default", out.posted[3])
+}
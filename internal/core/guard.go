@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// heldTurns keeps, per session, the last message that was stopped by the
+// token guard until /confirm runs it or another message replaces it.
+type heldTurns struct {
+	mu    sync.Mutex
+	turns map[SessionKey]Inbound
+}
+
+func (h *heldTurns) put(in Inbound) {
+	h.mu.Lock()
+	h.turns[in.SessionKey] = in
+	h.mu.Unlock()
+}
+
+func (h *heldTurns) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.turns)
+}
+
+func (h *heldTurns) take(key SessionKey) (Inbound, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	in, ok := h.turns[key]
+	delete(h.turns, key)
+	return in, ok
+}
+
+// SetTurnTokenLimit makes the bot hold any message whose first API call
+// would send more than n input tokens, as estimated by a TokenEstimator
+// backend, until the user sends /confirm. n <= 0 disables the guard. Call
+// before the first inbound is handled.
+func (b *Bot) SetTurnTokenLimit(n int64) {
+	b.turnTokenLimit = n
+}
+
+// overLimit reports the estimate for in when it exceeds the turn token
+// limit.
+func (b *Bot) overLimit(backend Backend, in Inbound) (int64, bool) {
+	est, ok := backend.(TokenEstimator)
+	if b.turnTokenLimit <= 0 || !ok {
+		return 0, false
+	}
+	n := est.EstimateInputTokens(in)
+	return n, n > b.turnTokenLimit
+}
+
+func (b *Bot) holdMessage(in Inbound, estimate int64) string {
+	return b.tr(in, "This message would send about %s input tokens to the model (limit %s). Send /confirm to go ahead, or anything else to drop it.",
+		formatTokens(estimate), formatTokens(b.turnTokenLimit))
+}
+
+// formatTokens renders n as e.g. "1.2M", "350k" or "900".
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%dk", n/1_000)
+	}
+	return fmt.Sprint(n)
+}
+
+func (b *Bot) cmdConfirm(ctx context.Context, in Inbound, _ string) (string, error) {
+	held, ok := b.held.take(in.SessionKey)
+	if !ok {
+		return b.tr(in, "Nothing is waiting for confirmation."), nil
+	}
+	held.Reply = in.Reply
+	return "", b.dispatch(ctx, held, true)
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// estimatingBackend estimates a turn at len(text) tokens.
+type estimatingBackend struct {
+	stubBackend
+}
+
+func (e *estimatingBackend) EstimateInputTokens(in Inbound) int64 {
+	return int64(len(in.Text))
+}
+
+func TestHandleInbound_TokenGuardHoldsUntilConfirmed(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a 10-token limit and a backend estimating one token per byte
+	be := &estimatingBackend{}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.SetTurnTokenLimit(10)
+	first, second := &stubResponder{}, &stubResponder{}
+
+	// when
+	// ... an oversized message arrives, then /confirm from another responder
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "a very long pasted log", Reply: first}))
+	held := append([]string(nil), be.messages...)
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/confirm", Reply: second}))
+
+	// then
+	// ... the message only reaches the backend after confirmation
+	a.Empty(held)
+	r.Len(first.posted, 1)
+	a.Contains(first.posted[0], "about 22 input tokens")
+	a.Contains(first.posted[0], "/confirm")
+	a.Equal([]string{"a very long pasted log"}, be.messages)
+	a.Same(second, be.lastInbound.Reply)
+}
+
+func TestHandleInbound_TokenGuardDropsHeldOnNextMessage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a held oversized message
+	be := &estimatingBackend{}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.SetTurnTokenLimit(10)
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "a very long pasted log", Reply: out}))
+
+	// when
+	// ... a small message follows, then /confirm
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "short", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/confirm", Reply: out}))
+
+	// then
+	// ... only the small message ran and nothing was left to confirm
+	a.Equal([]string{"short"}, be.messages)
+	a.Equal("Nothing is waiting for confirmation.", out.posted[len(out.posted)-1])
+}
+
+func TestFormatTokens(t *testing.T) {
+	assert.Equal(t, "900", formatTokens(900))
+	assert.Equal(t, "350k", formatTokens(350_400))
+	assert.Equal(t, "1.2M", formatTokens(1_234_567))
+}
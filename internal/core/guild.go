@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// GuildSettings are one server's overrides of the bot-wide configuration,
+// changed with /config.
+type GuildSettings struct {
+	// AllowedChannels, when set, are the only channel IDs the bot answers
+	// in. Threads count as their parent channel.
+	AllowedChannels []string
+	// Passive servers get no turns or commands except /config.
+	Passive bool
+	// Workspace is the label chats without a session start in.
+	Workspace string
+	// Language replaces LANGUAGE where nobody has picked one with /language.
+	Language Lang
+}
+
+// GuildStore keeps GuildSettings by guild ID, e.g. "discord:123".
+type GuildStore interface {
+	GuildSettings(guildID string) (GuildSettings, error)
+	SetGuildSettings(guildID string, s GuildSettings) error
+}
+
+// guildPrefs caches a GuildStore, which every inbound consults.
+type guildPrefs struct {
+	mu    sync.Mutex
+	store GuildStore
+	cache map[string]GuildSettings
+}
+
+// get returns guildID's settings, or none when it has no guild or they
+// can't be read.
+func (g *guildPrefs) get(guildID string) GuildSettings {
+	if guildID == "" {
+		return GuildSettings{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s, err := g.loadLocked(guildID)
+	if err != nil {
+		slog.Warn("loading guild settings", "guild", guildID, "error", err)
+	}
+	return s
+}
+
+func (g *guildPrefs) loadLocked(guildID string) (GuildSettings, error) {
+	if g.store == nil {
+		return GuildSettings{}, nil
+	}
+	if s, ok := g.cache[guildID]; ok {
+		return s, nil
+	}
+	s, err := g.store.GuildSettings(guildID)
+	if err != nil {
+		return GuildSettings{}, err
+	}
+	g.cache[guildID] = s
+	return s, nil
+}
+
+// update applies fn to guildID's settings and saves them.
+func (g *guildPrefs) update(guildID string, fn func(*GuildSettings)) (GuildSettings, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s, err := g.loadLocked(guildID)
+	if err != nil {
+		return GuildSettings{}, err
+	}
+	s.AllowedChannels = slices.Clone(s.AllowedChannels)
+	fn(&s)
+	if err := g.store.SetGuildSettings(guildID, s); err != nil {
+		return GuildSettings{}, err
+	}
+	g.cache[guildID] = s
+	return s, nil
+}
+
+// SetGuildStore enables /config, keeping per-server settings in s. Call
+// before the first inbound is handled.
+func (b *Bot) SetGuildStore(s GuildStore) {
+	b.guilds.store = s
+	b.guilds.cache = make(map[string]GuildSettings)
+}
+
+// guildAdmits reports whether in's server lets the bot answer it.
+func (b *Bot) guildAdmits(in Inbound) bool {
+	g := b.guilds.get(in.GuildID)
+	if g.Passive {
+		return false
+	}
+	if len(g.AllowedChannels) == 0 {
+		return true
+	}
+	_, channel, _ := strings.Cut(in.ChannelID, ":")
+	return slices.Contains(g.AllowedChannels, channel)
+}
+
+// startInGuildWorkspace gives a chat without a session one in its
+// server's workspace.
+func (b *Bot) startInGuildWorkspace(in Inbound) error {
+	t := b.tenantFor(in)
+	label := b.guilds.get(in.GuildID).Workspace
+	if label == "" {
+		return nil
+	}
+	if _, ok := t.sessions.Info(in.SessionKey); ok {
+		return nil
+	}
+	w, ok := t.workspace(label)
+	if !ok || (w.ReadOnly && t.readOnlyPerms == nil) {
+		slog.Warn("guild workspace unavailable", "guild", in.GuildID, "workspace", label)
+		return nil
+	}
+	return t.sessions.NewWorkspaceSession(in.SessionKey, w.Path, w.ReadOnly, in.Capabilities)
+}
+
+// cmdConfig shows or changes the server's settings.
+func (b *Bot) cmdConfig(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	if b.guilds.store == nil {
+		return b.tr(in, "Server settings are not available."), nil
+	}
+	if in.GuildID == "" {
+		return b.tr(in, "Server settings only apply in a server channel."), nil
+	}
+	usage := b.tr(in, "Use /config channels <id> ...|all, /config passive on|off, /config workspace <name>|default or /config language <code>|default.")
+
+	key, value := splitFirstWord(args)
+	value = strings.TrimSpace(value)
+	if key == "" {
+		g := b.guilds.get(in.GuildID)
+		return b.tr(in, "Server settings: channels %s, passive %s, workspace %s, language %s.", guildSummary(g)...) + "\n" + usage, nil
+	}
+
+	if value == "" {
+		return usage, nil
+	}
+
+	var apply func(*GuildSettings)
+	switch strings.ToLower(key) {
+	case "channels":
+		ids := strings.Fields(strings.ReplaceAll(value, ",", " "))
+		if len(ids) == 1 && strings.EqualFold(ids[0], "all") {
+			ids = nil
+		}
+		apply = func(g *GuildSettings) { g.AllowedChannels = ids }
+	case "passive":
+		on := strings.EqualFold(value, "on")
+		if !on && !strings.EqualFold(value, "off") {
+			return usage, nil
+		}
+		apply = func(g *GuildSettings) { g.Passive = on }
+	case "workspace":
+		var label string
+		if !strings.EqualFold(value, "default") {
+			w, ok := t.workspace(value)
+			if !ok {
+				return b.tr(in, "Unknown workspace %q. Workspaces: %s.", value, t.workspaceList(b.Lang(in))), nil
+			}
+			label = w.Label
+		}
+		apply = func(g *GuildSettings) { g.Workspace = label }
+	case "language":
+		var l Lang
+		if !strings.EqualFold(value, "default") {
+			var ok bool
+			if l, ok = ParseLang(value); !ok {
+				return b.tr(in, "Unknown language %q. Use /config language %s|default.", value, langCodes()), nil
+			}
+		}
+		apply = func(g *GuildSettings) { g.Language = l }
+	default:
+		return usage, nil
+	}
+
+	g, err := b.guilds.update(in.GuildID, apply)
+	if err != nil {
+		return "", err
+	}
+	return b.tr(in, "Server settings updated: channels %s, passive %s, workspace %s, language %s.", guildSummary(g)...), nil
+}
+
+// guildSummary renders g's values for the /config replies.
+func guildSummary(g GuildSettings) []any {
+	channels, passive, workspace, lang := "all", "off", "default", "default"
+	if len(g.AllowedChannels) > 0 {
+		channels = strings.Join(g.AllowedChannels, " ")
+	}
+	if g.Passive {
+		passive = "on"
+	}
+	if g.Workspace != "" {
+		workspace = g.Workspace
+	}
+	if g.Language != "" {
+		lang = string(g.Language)
+	}
+	return []any{channels, passive, workspace, lang}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memGuildStore map[string]GuildSettings
+
+func (m memGuildStore) GuildSettings(id string) (GuildSettings, error) { return m[id], nil }
+func (m memGuildStore) SetGuildSettings(id string, s GuildSettings) error {
+	m[id] = s
+	return nil
+}
+
+func TestHandleInbound_ConfigPassive(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a server switched to passive
+	b := &stubBackend{id: "b"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return b }}, nil), nil)
+	store := memGuildStore{}
+	bot.SetGuildStore(store)
+	out := &stubResponder{}
+	in := Inbound{SessionKey: "k1", UserID: "discord:u", ChannelID: "discord:1", GuildID: "discord:g", Reply: out}
+	in.Text = "/config passive on"
+	r.NoError(bot.HandleInbound(in))
+
+	// when
+	in.Text = "hello"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/status"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/config passive off"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "hello again"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... only /config is answered until passive mode is off
+	a.Equal([]string{"hello again"}, b.messages)
+	a.Equal([]string{
+		"Server settings updated: channels all, passive on, workspace default, language default.",
+		"Server settings updated: channels all, passive off, workspace default, language default.",
+	}, out.posted[:2])
+	a.False(store["discord:g"].Passive)
+}
+
+func TestHandleInbound_ConfigChannelsAndLanguage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a server that allows one channel and speaks German
+	b := &stubBackend{id: "b"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return b }}, nil), nil)
+	bot.SetGuildStore(memGuildStore{})
+	out := &stubResponder{}
+	allowed := Inbound{SessionKey: "k1", UserID: "discord:u", ChannelID: "discord:1", GuildID: "discord:g", Reply: out}
+	other := Inbound{SessionKey: "k2", UserID: "discord:u", ChannelID: "discord:2", GuildID: "discord:g", Reply: out}
+	allowed.Text = "/config channels 1"
+	r.NoError(bot.HandleInbound(allowed))
+	allowed.Text = "/config language de"
+	r.NoError(bot.HandleInbound(allowed))
+
+	// when
+	other.Text = "hello"
+	r.NoError(bot.HandleInbound(other))
+	allowed.Text = "hello"
+	r.NoError(bot.HandleInbound(allowed))
+	allowed.Text = "/speak"
+	r.NoError(bot.HandleInbound(allowed))
+	allowed.Text = "/language en"
+	r.NoError(bot.HandleInbound(allowed))
+	allowed.Text = "/config language xx"
+	r.NoError(bot.HandleInbound(allowed))
+
+	// then
+	// ... other channels are ignored and a user's own language still wins
+	a.Equal([]string{"hello"}, b.messages)
+	a.Equal("Servereinstellungen aktualisiert: Kanäle 1, passiv off, Arbeitsbereich default, Sprache de.", out.posted[1])
+	a.Equal("Sprachausgabe ist aus. Verwende /speak on, um Antworten auch als Audio zu bekommen.", out.posted[2])
+	a.Equal(`Unknown language "xx". Use /config language en|es|de|af|default.`, out.posted[4])
+}
+
+func TestHandleInbound_ConfigWorkspace(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a server whose chats start in the docs workspace
+	f := &workDirFactory{}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.SetWorkspaces([]Workspace{
+		{Label: "api", Path: "/srv/api", Default: true},
+		{Label: "docs", Path: "/srv/docs"},
+	})
+	bot.SetGuildStore(memGuildStore{})
+	out := &stubResponder{}
+	in := Inbound{SessionKey: "k1", UserID: "discord:u", ChannelID: "discord:1", GuildID: "discord:g", Reply: out}
+	in.Text = "/config workspace nope"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/config workspace docs"
+	r.NoError(bot.HandleInbound(in))
+
+	// when
+	in.Text = "hello"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "again"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the first turn starts there and later turns keep the session
+	a.Equal([]string{"/srv/docs"}, f.workDirs)
+	a.Equal(`Unknown workspace "nope". Workspaces: api (default), docs.`, out.posted[0])
+}
+
+func TestHandleInbound_ConfigUnavailable(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/config", GuildID: "discord:g", Reply: out}))
+	bot.SetGuildStore(memGuildStore{})
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/config", Reply: out}))
+
+	// then
+	a.Equal([]string{
+		"Server settings are not available.",
+		"Server settings only apply in a server channel.",
+	}, out.posted)
+}
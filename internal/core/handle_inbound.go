@@ -3,62 +3,174 @@ package core
 import (
 	"context"
 	"log/slog"
+	"runtime/debug"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-// HandleInbound routes a single inbound message to the active backend, resetting
-// the session if the SessionKey has changed since the last inbound.
+// HandleInbound routes a single inbound message to the backend for its
+// SessionKey, creating one on first use.
 //
-// Concurrency: concurrent inbounds for the SAME session key proceed in parallel
-// under RLock; a key mismatch upgrades to a write lock and rotates the session,
-// blocking until in-flight messages drain (same as NewSession behaviour).
+// Concurrency: inbounds for different keys run in parallel, bounded by the
+// bot's concurrency cap. Inbounds for the SAME key share one slot and reach
+// the backend concurrently (so the second one steers the first); they hold
+// the session's read lock, which NewSession takes for writing before
+// retiring the old backend.
+//
+// A failure is reported to the user by category (see ErrorKind) with a
+// short ID; the returned error carries the same ID for the logs. A panic is
+// reported the same way, as ErrCrashed, and marks the session suspect.
 func (b *Bot) HandleInbound(in Inbound) error {
+	return b.HandleInboundContext(context.Background(), in)
+}
+
+// HandleInboundContext is HandleInbound under ctx. Cancelling ctx, e.g. on
+// shutdown, cancels the turn or command down to the model call and running
+// tools; the bot's converse timeout still applies within it.
+func (b *Bot) HandleInboundContext(ctx context.Context, in Inbound) error {
 	if in.SessionKey == "" {
 		return errors.New("inbound: empty SessionKey")
 	}
 
-	b.mu.RLock()
-	matches := in.SessionKey == b.activeKey
-	b.mu.RUnlock()
+	in.Reply = b.mirrored(in, FilterOutbound(in.Reply, b.filters...))
+	b.chats.put(in.SessionKey, replyChat{out: in.Reply, sessions: b.tenantFor(in).sessions})
+	// A paused channel shouldn't even see the bot typing.
+	if _, name, _, _ := parseCommand(in.Text); in.Reply != nil && b.pauseAdmits(in, name) {
+		_ = in.Reply.SendTyping()
+	}
 
-	if !matches {
-		b.mu.Lock()
-		if in.SessionKey != b.activeKey {
-			if err := b.sessions.NewSession("", in.Capabilities); err != nil {
-				b.mu.Unlock()
-				return errors.Wrap(err, "rotating session on key change")
-			}
-			b.activeKey = in.SessionKey
-			b.activeCaps = in.Capabilities
-		}
-		b.mu.Unlock()
+	err := b.handleRecovered(ctx, in)
+	if err == nil {
+		return nil
+	}
+	text, id := b.Lang(in).UserError(err)
+	if in.Reply != nil {
+		_ = in.Reply.PostResponse(text)
 	}
+	return errors.Wrapf(err, "error ID %s", id)
+}
 
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+// handleRecovered is handle with a panic turned into an ErrCrashed error,
+// so one bad message can't take the process down. It recovers after
+// handle's deferred releases have run, so the session lock is free again.
+func (b *Bot) handleRecovered(ctx context.Context, in Inbound) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		slog.Error("inbound panicked", "key", string(in.SessionKey), "panic", v, "stack", string(debug.Stack()))
+		b.tenantFor(in).sessions.MarkSuspect(in.SessionKey)
+		err = MarkError(ErrCrashed, errors.Errorf("panic: %v", v))
+	}()
+	return b.handle(ctx, in)
+}
 
-	if in.Reply != nil {
-		_ = in.Reply.SendTyping()
+// handle runs compose, commands and the turn itself for HandleInbound.
+func (b *Bot) handle(ctx context.Context, in Inbound) error {
+	in, ok := b.compose(in)
+	if !ok {
+		return nil
+	}
+	cmd, name, args, isCmd := parseCommand(in.Text)
+	// /config stays reachable so a passive server can be turned back on.
+	if name != "config" && !b.guildAdmits(in) {
+		return nil
+	}
+	if !b.pauseAdmits(in, name) {
+		return nil
+	}
+	if isCmd {
+		return b.runCommand(ctx, in, cmd, name, args)
+	}
+	in, ok, err := b.hookMessage(in)
+	if !ok {
+		return err
 	}
+	b.held.take(in.SessionKey)
+	b.prompts.put(in)
+	if served, err := b.serveCached(in); served {
+		slog.Info("served cached turn", "key", string(in.SessionKey))
+		return errors.Wrap(err, "posting cached response")
+	}
+	return b.dispatch(ctx, in, false)
+}
+
+// dispatch runs one turn. Unless confirmed, a turn over the token limit is
+// held for /confirm instead.
+func (b *Bot) dispatch(ctx context.Context, in Inbound, confirmed bool) error {
+	t := b.tenantFor(in)
+	release := b.acquireSlot(in.SessionKey)
+	defer release()
 
-	backend, err := b.sessions.GetOrCreateSession(b.activeCaps)
+	if err := b.startInGuildWorkspace(in); err != nil {
+		return errors.Wrap(err, "starting guild workspace session")
+	}
+	if err := t.sessions.RestartSuspect(in.SessionKey, in.Capabilities); err != nil {
+		return errors.Wrap(err, "restarting suspect session")
+	}
+	backend, unlock, err := t.sessions.Acquire(in.SessionKey, in.Capabilities)
 	if err != nil {
 		return errors.Wrap(err, "getting session")
 	}
+	defer unlock()
+	in.Settings = t.sessions.Settings(in.SessionKey)
+
+	if n, over := b.overLimit(backend, in); over && !confirmed {
+		b.held.put(in)
+		slog.Info("holding expensive turn", "key", string(in.SessionKey), "estimate", n)
+		if in.Reply != nil {
+			return errors.Wrap(in.Reply.PostResponse(b.holdMessage(in, n)), "posting token guard notice")
+		}
+		return nil
+	}
 
 	slog.Info("dispatching inbound", "key", string(in.SessionKey), "session", backend.SessionID())
 
-	ctx, cancel := context.WithTimeout(context.Background(), b.converseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, b.converseTimeout)
 	defer cancel()
-	response, err := backend.Converse(ctx, in, in.Reply, b.perms)
+	started := time.Now()
+	response, err := backend.Converse(ctx, in, in.Reply, reportDenials(t.permsFor(in.SessionKey, in.Settings), in.Reply))
+	b.turnTimes.observe(time.Since(started))
 	if err != nil {
 		return errors.Wrap(err, "converse")
 	}
+	if in.MaxResponseLen > 0 && len(response) > in.MaxResponseLen {
+		response = b.condense(ctx, backend, in, response, in.MaxResponseLen)
+	}
+	response = b.hookResponse(in, response)
 	if response != "" && in.Reply != nil {
 		if err := in.Reply.PostResponse(response); err != nil {
 			return errors.Wrap(err, "posting response")
 		}
+		b.turns.put(in, response)
+		turnID := newTurnID()
+		b.latest.put(in.SessionKey, Answer{At: time.Now(), Question: questionLine(in.Text), Text: response, TurnID: turnID})
+		b.promptFeedback(in, turnID)
+		if in.Settings.Speak {
+			b.speak(ctx, in, response)
+		}
 	}
 	return nil
 }
+
+// speak sends response as audio after the text reply. Failures are logged
+// only: the user already has the text.
+func (b *Bot) speak(ctx context.Context, in Inbound, response string) {
+	vs, ok := in.Reply.(VoiceSender)
+	if b.speaker == nil || !ok {
+		return
+	}
+	for _, f := range b.filters {
+		response = f(response)
+	}
+	audio, mimeType, err := b.speaker.Speak(ctx, response)
+	if err != nil {
+		slog.Warn("synthesizing speech", "key", string(in.SessionKey), "error", err)
+		return
+	}
+	if err := vs.SendVoice(audio, mimeType); err != nil {
+		slog.Warn("sending voice", "key", string(in.SessionKey), "error", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 type stubBackend struct {
@@ -102,7 +103,7 @@ func TestHandleInbound_SameKeyContinues(t *testing.T) {
 	}
 }
 
-func TestHandleInbound_DifferentKeyResets(t *testing.T) {
+func TestHandleInbound_DifferentKeysGetOwnSessions(t *testing.T) {
 	// given
 	// ... two backends, returned in order from the factory
 	be1 := &stubBackend{id: "b1", converseR: "first"}
@@ -114,14 +115,18 @@ func TestHandleInbound_DifferentKeyResets(t *testing.T) {
 	r := &stubResponder{}
 
 	// when
-	// ... two inbounds arrive with different SessionKeys
-	_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a", Reply: r})
+	// ... inbounds alternate between two SessionKeys
+	_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a1", Reply: r})
 	_ = bot.HandleInbound(Inbound{SessionKey: "kB", Text: "b", Reply: r})
+	_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a2", Reply: r})
 
 	// then
-	// ... the first backend was closed, a new backend was created, only the second saw 'b'
-	if !be1.closed {
-		t.Fatalf("expected first backend to be closed on key switch")
+	// ... each key kept its own backend and neither was closed
+	if be1.closed || be2.closed {
+		t.Fatalf("expected no backend to be closed, got b1=%v b2=%v", be1.closed, be2.closed)
+	}
+	if got := strings.Join(be1.messages, ","); got != "a1,a2" {
+		t.Fatalf("first backend messages: %v", be1.messages)
 	}
 	if got := len(be2.messages); got != 1 || be2.messages[0] != "b" {
 		t.Fatalf("second backend messages: %v", be2.messages)
@@ -130,7 +135,7 @@ func TestHandleInbound_DifferentKeyResets(t *testing.T) {
 
 func TestHandleInbound_FlushErrorIsSwallowed(t *testing.T) {
 	// given
-	// ... a flusher that returns an error and a backend that succeeds
+	// ... a flusher that panics and a manager that keeps a single session
 	be1 := &stubBackend{id: "b1", converseR: "x", closeErr: nil}
 	be2 := &stubBackend{id: "b2", converseR: "y"}
 	queue := []Backend{be1, be2}
@@ -141,18 +146,22 @@ func TestHandleInbound_FlushErrorIsSwallowed(t *testing.T) {
 		panic(errors.New("boom"))
 	}
 	mgr := NewSessionManager(f, flush)
+	mgr.maxSessions = 1
 	bot := NewBot(mgr, nil)
 	r := &stubResponder{}
 
 	// when
-	// ... two different keys land in sequence
+	// ... two different keys land in sequence, evicting the first session
 	_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a", Reply: r})
 	err := bot.HandleInbound(Inbound{SessionKey: "kB", Text: "b", Reply: r})
 
 	// then
 	// ... the flush ran, panicked, was swallowed, and the second inbound succeeded
 	if !flushCalled {
-		t.Fatalf("expected flush to run on key switch")
+		t.Fatalf("expected flush to run on eviction")
+	}
+	if !be1.closed {
+		t.Fatalf("expected evicted backend to be closed")
 	}
 	if err != nil {
 		t.Fatalf("HandleInbound returned error after flush panic: %v", err)
@@ -295,3 +304,28 @@ func TestHandleInbound_AttachmentsReachBackend(t *testing.T) {
 		t.Fatalf("unexpected second attachment MIME: %s", be.lastInbound.Attachments[1].MIME)
 	}
 }
+
+// blockingBackend waits for its turn's context to end.
+type blockingBackend struct {
+	stubBackend
+}
+
+func (b *blockingBackend) Converse(ctx context.Context, _ Inbound, _ Outbound, _ PermissionChecker) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestHandleInboundContext_CancelStopsTurn(t *testing.T) {
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &blockingBackend{} }}, nil), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	// when
+	err := bot.HandleInboundContext(ctx, Inbound{SessionKey: "k1", Text: "hi", Reply: &stubResponder{}})
+
+	// then
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"log/slog"
+
+	"github.com/pkg/errors"
+)
+
+// SetHooks runs h on every non-command message before its turn and on
+// every reply before it is posted. Call before the first inbound is
+// handled.
+func (b *Bot) SetHooks(h Hooks) {
+	b.hooks = h
+}
+
+// hookMessage applies on_message to in. ok is false when the hook denied
+// it; the reason, if any, has been posted.
+func (b *Bot) hookMessage(in Inbound) (Inbound, bool, error) {
+	if b.hooks == nil {
+		return in, true, nil
+	}
+	d := b.hooks.OnMessage(in)
+	switch d.Verdict {
+	case HookDeny:
+		slog.Info("message denied by hook", "key", string(in.SessionKey), "reason", d.Reason)
+		if d.Reason != "" && in.Reply != nil {
+			return in, false, errors.Wrap(in.Reply.PostResponse(d.Reason), "posting hook denial")
+		}
+		return in, false, nil
+	case HookModify:
+		in.Text = d.Text
+	case HookRoute:
+		slog.Info("message routed by hook", "from", string(in.SessionKey), "to", string(d.Route))
+		in.SessionKey = d.Route
+	}
+	return in, true, nil
+}
+
+// hookResponse applies post_response to a reply. Denying a reply replaces
+// it with the reason, or drops it when there is none.
+func (b *Bot) hookResponse(in Inbound, response string) string {
+	if b.hooks == nil || response == "" {
+		return response
+	}
+	d := b.hooks.PostResponse(in, response)
+	switch d.Verdict {
+	case HookDeny:
+		slog.Info("reply denied by hook", "key", string(in.SessionKey), "reason", d.Reason)
+		return d.Reason
+	case HookModify:
+		return d.Text
+	}
+	return response
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubHooks returns fixed decisions and records the replies it saw.
+type stubHooks struct {
+	onMessage    HookDecision
+	postResponse HookDecision
+	responses    []string
+}
+
+func (h *stubHooks) OnMessage(Inbound) HookDecision { return h.onMessage }
+func (h *stubHooks) PostResponse(_ Inbound, response string) HookDecision {
+	h.responses = append(h.responses, response)
+	return h.postResponse
+}
+
+func TestHandleInbound_HookDeniesMessage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an on_message hook denying with a reason
+	be := &stubBackend{converseR: "hi"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.SetHooks(&stubHooks{onMessage: HookDecision{Verdict: HookDeny, Reason: "not here"}})
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "hello", Reply: out}))
+
+	// then
+	// ... the backend never sees it and the user gets the reason
+	a.Empty(be.messages)
+	a.Equal([]string{"not here"}, out.posted)
+}
+
+func TestHandleInbound_HookModifiesAndRoutesMessage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	hooks := &stubHooks{onMessage: HookDecision{Verdict: HookModify, Text: "rewritten"}}
+	bot.SetHooks(hooks)
+
+	// when
+	// ... one message is modified, the next routed
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "original"}))
+	hooks.onMessage = HookDecision{Verdict: HookRoute, Route: "shared"}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "second"}))
+
+	// then
+	a.Equal([]string{"rewritten", "second"}, be.messages)
+	a.Equal(SessionKey("shared"), be.lastInbound.SessionKey)
+}
+
+func TestHandleInbound_HookRewritesReply(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a post_response hook that modifies, then denies without a reason
+	be := &stubBackend{converseR: "secret answer"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	hooks := &stubHooks{postResponse: HookDecision{Verdict: HookModify, Text: "[redacted]"}}
+	bot.SetHooks(hooks)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "one", Reply: out}))
+	hooks.postResponse = HookDecision{Verdict: HookDeny}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "two", Reply: out}))
+
+	// then
+	// ... the first reply is replaced and the second dropped
+	a.Equal([]string{"secret answer", "secret answer"}, hooks.responses)
+	a.Equal([]string{"[redacted]"}, out.posted)
+}
+
+func TestHandleInbound_HooksSkipCommands(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a hook denying everything
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{} }}, nil), nil)
+	bot.SetHooks(&stubHooks{onMessage: HookDecision{Verdict: HookDeny, Reason: "no"}})
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/verbosity", Reply: out}))
+
+	// then
+	// ... slash commands still run
+	r.Len(out.posted, 1)
+	a.NotEqual("no", out.posted[0])
+}
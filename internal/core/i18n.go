@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Lang is a language user-facing text can be shown in.
+type Lang string
+
+const (
+	LangEnglish   Lang = "en"
+	LangSpanish   Lang = "es"
+	LangGerman    Lang = "de"
+	LangAfrikaans Lang = "af"
+)
+
+// Languages lists the supported languages. English is the source text.
+var Languages = []Lang{LangEnglish, LangSpanish, LangGerman, LangAfrikaans}
+
+// ParseLang matches a language code case-insensitively.
+func ParseLang(code string) (Lang, bool) {
+	l := Lang(strings.ToLower(strings.TrimSpace(code)))
+	return l, slices.Contains(Languages, l)
+}
+
+// T translates format into l and fills in args like fmt.Sprintf. Text
+// without a translation is shown in English.
+func (l Lang) T(format string, args ...any) string {
+	if t, ok := catalog[l][format]; ok {
+		format = t
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// langCodes lists the supported language codes for usage messages.
+func langCodes() string {
+	codes := make([]string, len(Languages))
+	for i, l := range Languages {
+		codes[i] = string(l)
+	}
+	return strings.Join(codes, "|")
+}
+
+// langPrefs holds the languages chosen with /language. A user's choice wins
+// over their chat's, and both over their server's.
+type langPrefs struct {
+	mu       sync.Mutex
+	fallback Lang
+	users    map[string]Lang
+	channels map[string]Lang
+}
+
+func (p *langPrefs) lang(in Inbound, guild Lang) Lang {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l, ok := p.users[in.UserID]; ok && in.UserID != "" {
+		return l
+	}
+	if l, ok := p.channels[in.ChannelID]; ok && in.ChannelID != "" {
+		return l
+	}
+	if guild != "" {
+		return guild
+	}
+	if p.fallback == "" {
+		return LangEnglish
+	}
+	return p.fallback
+}
+
+// SetLanguage sets the language used where nobody has picked one with
+// /language. Call before the first inbound is handled.
+func (b *Bot) SetLanguage(l Lang) {
+	b.langs.fallback = l
+}
+
+// Lang is the language replies to in are shown in.
+func (b *Bot) Lang(in Inbound) Lang {
+	return b.langs.lang(in, b.guilds.get(in.GuildID).Language)
+}
+
+func (b *Bot) tr(in Inbound, format string, args ...any) string {
+	return b.Lang(in).T(format, args...)
+}
+
+// cmdLanguage shows or sets the reply language for the sender, or for the
+// whole chat with "channel".
+func (b *Bot) cmdLanguage(_ context.Context, in Inbound, args string) (string, error) {
+	supported := langCodes()
+	cur := b.Lang(in)
+
+	code, scope := splitFirstWord(args)
+	if code == "" {
+		return cur.T("Language is %s. Use /language %s [channel].", cur, supported), nil
+	}
+	l, ok := ParseLang(code)
+	if !ok {
+		return cur.T("Unknown language %q. Use /language %s [channel].", code, supported), nil
+	}
+
+	b.langs.mu.Lock()
+	defer b.langs.mu.Unlock()
+	switch strings.ToLower(strings.TrimSpace(scope)) {
+	case "":
+		if in.UserID == "" {
+			return cur.T("This chat cannot tell users apart. Use /language %s channel.", l), nil
+		}
+		b.langs.users[in.UserID] = l
+		return l.T("Language set to %s for you.", l), nil
+	case "channel":
+		if in.ChannelID == "" {
+			return cur.T("This chat cannot set a channel language."), nil
+		}
+		b.langs.channels[in.ChannelID] = l
+		return l.T("Language set to %s for this chat.", l), nil
+	}
+	return cur.T("Unknown option %q. Use /language %s [channel].", scope, supported), nil
+}
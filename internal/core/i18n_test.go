@@ -0,0 +1,105 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog_TranslationsKeepVerbs(t *testing.T) {
+	a := assert.New(t)
+	verbs := regexp.MustCompile(`%[a-z]`)
+
+	for lang, msgs := range catalog {
+		for en, tr := range msgs {
+			a.Equal(verbs.FindAllString(en, -1), verbs.FindAllString(tr, -1), "%s: %q", lang, en)
+		}
+	}
+}
+
+func TestCatalog_CoversEveryLanguage(t *testing.T) {
+	a := assert.New(t)
+
+	for _, lang := range Languages[1:] {
+		a.Len(catalog[lang], len(catalog[LangSpanish]), lang)
+		for en := range catalog[LangSpanish] {
+			a.Contains(catalog[lang], en, lang)
+		}
+	}
+}
+
+func TestLangT_FallsBackToEnglish(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Voz desactivada.", LangSpanish.T("Speech off."))
+	a.Equal("Verbosity set to tools.", LangEnglish.T("Verbosity set to %s.", "tools"))
+	a.Equal("not in the catalog 3", LangGerman.T("not in the catalog %d", 3))
+}
+
+func TestHandleInbound_LanguageCommand(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a chat where one user picks German and the channel picks Afrikaans
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+	alice := Inbound{SessionKey: "k1", UserID: "u:alice", ChannelID: "c:1", Reply: out}
+	bob := Inbound{SessionKey: "k1", UserID: "u:bob", ChannelID: "c:1", Reply: out}
+	alice.Text = "/language DE"
+	r.NoError(bot.HandleInbound(alice))
+	bob.Text = "/language af channel"
+	r.NoError(bot.HandleInbound(bob))
+
+	// when
+	alice.Text = "/speak"
+	r.NoError(bot.HandleInbound(alice))
+	bob.Text = "/speak"
+	r.NoError(bot.HandleInbound(bob))
+	bob.Text = "/language xx"
+	r.NoError(bot.HandleInbound(bob))
+
+	// then
+	// ... the user's choice beats the channel's
+	a.Equal([]string{
+		"Sprache für dich auf de gesetzt.",
+		"Taal vir hierdie klets op af gestel.",
+		"Sprachausgabe ist aus. Verwende /speak on, um Antworten auch als Audio zu bekommen.",
+		"Spraak is af. Gebruik /speak on om antwoorde ook as oudio te kry.",
+		`Onbekende taal "xx". Gebruik /language en|es|de|af [channel].`,
+	}, out.posted)
+}
+
+func TestHandleInbound_DefaultLanguage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.SetLanguage(LangSpanish)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/language", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/language de", Reply: out}))
+
+	// then
+	// ... a chat that cannot tell users apart keeps the default
+	a.Equal([]string{
+		"El idioma es es. Usa /language en|es|de|af [channel].",
+		"Este chat no distingue entre usuarios. Usa /language de channel.",
+	}, out.posted)
+}
+
+func TestLangUserError(t *testing.T) {
+	a := assert.New(t)
+
+	text, id := LangGerman.UserError(errors.New("boom"))
+
+	a.Equal("Beim Verarbeiten dieser Nachricht ist etwas schiefgegangen. (Fehler-ID "+id+")", text)
+}
@@ -0,0 +1,24 @@
+package core
+
+import "context"
+
+// Identity is who a turn is for. Tools that keep per-user or per-channel
+// state read it from the context the backend passes them.
+type Identity struct {
+	UserID    string
+	ChannelID string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns ctx carrying in's UserID and ChannelID.
+func WithIdentity(ctx context.Context, in Inbound) context.Context {
+	return context.WithValue(ctx, identityKey{}, Identity{UserID: in.UserID, ChannelID: in.ChannelID})
+}
+
+// IdentityFromContext returns the Identity set by WithIdentity, or the zero
+// Identity.
+func IdentityFromContext(ctx context.Context) Identity {
+	id, _ := ctx.Value(identityKey{}).(Identity)
+	return id
+}
@@ -1,9 +1,20 @@
 package core
 
+import (
+	"context"
+	"time"
+)
+
 type PermissionChecker interface {
 	Check(toolName string, input ToolInput) (allow bool, reason string)
 }
 
+// DirScoper is implemented by PermissionCheckers that can allow extra
+// directories for one session, such as a /scratch directory.
+type DirScoper interface {
+	WithDirs(dirs ...string) PermissionChecker
+}
+
 // Outbound is the per-message send-side of a channel. It is owned by a single
 // Inbound and bound to the originating chat surface (Discord thread, WhatsApp
 // chat, dashboard WebSocket). Every channel plugin's reply type implements
@@ -15,7 +26,216 @@ type Outbound interface {
 	SendUpdate(message string) error
 }
 
+// FileSender is implemented by Outbounds that can deliver a named file
+// (Discord attachment, WhatsApp document). Check Capabilities.Files before
+// relying on it.
+type FileSender interface {
+	SendFile(name string, content []byte) error
+}
+
+// VoiceSender is implemented by Outbounds that can deliver synthesized
+// speech (WhatsApp voice note, Discord audio attachment). Check
+// Capabilities.Voice before relying on it.
+type VoiceSender interface {
+	SendVoice(audio []byte, mimeType string) error
+}
+
+// MessageFetcher is implemented by Outbounds that can read a message by
+// its link, for /pin-context. ok is false when link isn't one of theirs.
+type MessageFetcher interface {
+	FetchMessage(link string) (text string, ok bool, err error)
+}
+
+// Speaker renders text as speech for /speak.
+type Speaker interface {
+	Speak(ctx context.Context, text string) (audio []byte, mimeType string, err error)
+}
+
+// TranscriptEntry is one message of a conversation as shown to people.
+// Role is "user", "assistant" or "tool".
+type TranscriptEntry struct {
+	Role string
+	Text string
+}
+
+// Transcriber is implemented by Backends that can export their history for
+// /share. ok is false while a turn is running.
+type Transcriber interface {
+	Transcript() (entries []TranscriptEntry, ok bool)
+}
+
+// Brancher is implemented by Backends that commit their edits to a session
+// branch. ApplyBranch merges it into the branch the session started from
+// and DiscardBranch deletes it with its changes; both return the two branch
+// names. Without a branch they return ErrNoBranch.
+type Brancher interface {
+	ApplyBranch(ctx context.Context) (branch, base string, err error)
+	DiscardBranch(ctx context.Context) (branch, base string, err error)
+}
+
+// Sharer publishes a transcript and returns a link to it for /share.
+type Sharer interface {
+	Share(entries []TranscriptEntry) (url string, expires time.Time, err error)
+}
+
+// SecretScrubber learns values to keep out of the logs, such as those set
+// with /env.
+type SecretScrubber interface {
+	Add(secret string)
+}
+
+// SkillScaffolder creates a new skill directory for /skill new and returns
+// its SKILL.md path.
+type SkillScaffolder interface {
+	Scaffold(name, description, instructions string) (path string, err error)
+}
+
+// ContextPinner stores standing instructions per channel for
+// /pin-context. New sessions in the channel start with them.
+type ContextPinner interface {
+	PinnedContext(channelID string) (string, error)
+	PinContext(channelID, text string) error
+}
+
+// AnswerIndex keeps the answers pinned per channel for /answers, oldest
+// first.
+type AnswerIndex interface {
+	AddAnswer(channelID string, a Answer) error
+	Answers(channelID string) ([]Answer, error)
+}
+
+// AnswerPinner is implemented by Outbounds that can pin the bot's latest
+// message in the chat, such as Discord's.
+type AnswerPinner interface {
+	PinLatest() error
+}
+
+// Persona is a named system prompt with optional model and temperature
+// overrides, chosen per session with /persona.
+type Persona struct {
+	Name        string
+	Description string
+	Prompt      string
+	// Model and Temperature replace the backend's defaults when set.
+	Model       string
+	Temperature *float64
+}
+
+// PersonaStore looks personas up by name for /persona.
+type PersonaStore interface {
+	Personas() ([]Persona, error)
+	Persona(name string) (Persona, error)
+}
+
+// Personalizer is implemented by Backends a Persona can be applied to.
+// The zero Persona restores the defaults.
+type Personalizer interface {
+	SetPersona(p Persona) error
+	CurrentPersona() Persona
+}
+
+// Cloner checks out a repository for /clone and returns its directory.
+// fresh is false when an earlier clone was reused.
+type Cloner interface {
+	Clone(ctx context.Context, url string) (dir string, fresh bool, err error)
+}
+
+// HookVerdict is what an operator hook decided about a message or reply.
+type HookVerdict int
+
+const (
+	HookPass HookVerdict = iota
+	HookAllow
+	HookDeny
+	HookModify
+	HookRoute
+)
+
+// HookDecision is a hook's answer. Reason is shown to the user on HookDeny
+// when set; Text replaces the content on HookModify; Route replaces the
+// SessionKey on HookRoute.
+type HookDecision struct {
+	Verdict HookVerdict
+	Reason  string
+	Text    string
+	Route   SessionKey
+}
+
+// Hooks lets operator scripts filter and reroute inbound messages and
+// rewrite or suppress replies. Tool calls are hooked through the
+// PermissionChecker instead.
+type Hooks interface {
+	OnMessage(in Inbound) HookDecision
+	PostResponse(in Inbound, response string) HookDecision
+}
+
+// TokenEstimator is implemented by Backends that can estimate how many
+// input tokens their next API call would send if in started a turn now.
+// Bot uses it to hold unexpectedly expensive turns for /confirm.
+type TokenEstimator interface {
+	EstimateInputTokens(in Inbound) int64
+}
+
+// ResultCache is the shared on-disk cache of Fetch and WebSearch results
+// that /cache inspects and clears.
+type ResultCache interface {
+	Len() (int, error)
+	Clear() (int, error)
+}
+
+// TurnStats describes one Converse call for usage accounting. Tokens are
+// summed over every API call the turn made; ToolCalls holds one name per
+// call. ThinkingTokens estimates the part of OutputTokens spent on extended
+// thinking.
+type TurnStats struct {
+	At             time.Time
+	Tenant         string
+	UserID         string
+	ChannelID      string
+	Model          string
+	InputTokens    int64
+	OutputTokens   int64
+	ThinkingTokens int64
+	ToolCalls      []string
+	Latency        time.Duration
+	Failed         bool
+}
+
+// UsageRecorder receives TurnStats after each turn. Implementations must be
+// safe for concurrent use and must not block for long.
+type UsageRecorder interface {
+	RecordTurn(TurnStats)
+}
+
+// Feedback is one user's rating of a turn's answer.
+type Feedback struct {
+	At         time.Time
+	Tenant     string
+	SessionKey SessionKey
+	TurnID     string
+	UserID     string
+	ChannelID  string
+	// Rating is 1 for 👍 and -1 for 👎.
+	Rating int
+}
+
+// FeedbackRecorder stores ratings. A user rating the same turn again
+// replaces their earlier rating. Like UsageRecorder, implementations log
+// their own failures.
+type FeedbackRecorder interface {
+	RecordFeedback(Feedback)
+}
+
+// FeedbackPrompter is implemented by Outbounds that can offer 👍/👎 on the
+// response just posted, such as Discord's. A rating comes back as
+// "/feedback up|down <turnID>".
+type FeedbackPrompter interface {
+	PromptFeedback(turnID string) error
+}
+
 type WhatsAppMessenger interface {
 	SendText(chatJID, text string) error
 	SendTyping(chatJID string) error
+	SendDocument(chatJID, fileName, mimeType string, data []byte) error
+	SendVoice(chatJID, mimeType string, data []byte) error
 }
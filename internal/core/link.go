@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// linkCodeTTL is how long a /link-session code can be redeemed.
+const linkCodeTTL = 10 * time.Minute
+
+// linkCodeAlphabet avoids characters that are easy to mistype on a phone
+// (0/O, 1/I/L).
+const linkCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+type linkCode struct {
+	key     SessionKey
+	expires time.Time
+}
+
+// linkCodes holds outstanding /link-session codes. Each is single use.
+type linkCodes struct {
+	mu    sync.Mutex
+	codes map[string]linkCode
+	now   func() time.Time
+}
+
+func (l *linkCodes) issue(key SessionKey) (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "generating link code")
+	}
+	for i, c := range buf {
+		buf[i] = linkCodeAlphabet[int(c)%len(linkCodeAlphabet)]
+	}
+	code := string(buf)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for c, lc := range l.codes {
+		if !now.Before(lc.expires) {
+			delete(l.codes, c)
+		}
+	}
+	l.codes[code] = linkCode{key: key, expires: now.Add(linkCodeTTL)}
+	return code, nil
+}
+
+func (l *linkCodes) redeem(code string) (SessionKey, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lc, ok := l.codes[code]
+	delete(l.codes, code)
+	if !ok || !l.now().Before(lc.expires) {
+		return "", false
+	}
+	return lc.key, true
+}
+
+// cmdLinkSession bridges two chats onto one session. Without arguments it
+// issues a code for this chat's session; with a code it joins that
+// session; "off" detaches this chat again.
+func (b *Bot) cmdLinkSession(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	switch arg := strings.ToUpper(strings.TrimSpace(args)); arg {
+	case "":
+		if _, err := t.sessions.GetOrCreateSession(in.SessionKey, in.Capabilities); err != nil {
+			return "", err
+		}
+		code, err := b.links.issue(in.SessionKey)
+		if err != nil {
+			return "", err
+		}
+		return b.tr(in, "Send /link-session %s from the other chat within %d minutes to continue this conversation there.", code, int(linkCodeTTL.Minutes())), nil
+	case "OFF":
+		if !t.sessions.Unlink(in.SessionKey) {
+			return b.tr(in, "This chat is not linked."), nil
+		}
+		return b.tr(in, "Unlinked: this chat will start a new conversation."), nil
+	default:
+		target, ok := b.links.redeem(arg)
+		if !ok {
+			return b.tr(in, "That link code is invalid or has expired."), nil
+		}
+		if target == in.SessionKey {
+			return b.tr(in, "That code is for this chat. Send it from the other one."), nil
+		}
+		if err := t.sessions.Link(in.SessionKey, target); err != nil {
+			return b.tr(in, "That conversation has ended. Start a new link from the other chat."), nil
+		}
+		return b.tr(in, "Linked: this chat now continues the same conversation. Use /link-session off to detach."), nil
+	}
+}
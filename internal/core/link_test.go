@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_LinkSessionSharesConversation(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot creating numbered backends and a Discord conversation
+	var backends []*stubBackend
+	f := &stubFactory{next: func() Backend {
+		be := &stubBackend{id: fmt.Sprintf("b%d", len(backends))}
+		backends = append(backends, be)
+		return be
+	}}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	desk, phone := &stubResponder{}, &stubResponder{}
+	send := func(key SessionKey, out *stubResponder, text string) string {
+		before := len(out.posted)
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: key, Text: text, Reply: out}))
+		if len(out.posted) == before {
+			return ""
+		}
+		return out.posted[len(out.posted)-1]
+	}
+	send("discord:dm:1", desk, "start at the desk")
+	send("whatsapp:1", phone, "unrelated phone chat")
+
+	// when
+	// ... a code from Discord is redeemed on WhatsApp
+	reply := send("discord:dm:1", desk, "/link-session")
+	code := strings.Fields(strings.TrimPrefix(reply, "Send /link-session "))[0]
+	linked := send("whatsapp:1", phone, "/link-session "+strings.ToLower(code))
+	send("whatsapp:1", phone, "continue on the phone")
+
+	// then
+	// ... the phone joins the desk backend and its own backend is retired
+	a.Contains(linked, "Linked")
+	a.Equal([]string{"start at the desk", "continue on the phone"}, backends[0].messages)
+	a.True(backends[1].closed)
+	a.Equal("That link code is invalid or has expired.", send("whatsapp:1", phone, "/link-session "+code), "codes are single use")
+
+	// when
+	// ... the desk resets the session, then the phone detaches
+	r.NoError(mgr.NewSession("discord:dm:1", "", Capabilities{}))
+	send("whatsapp:1", phone, "after reset")
+	unlinked := send("whatsapp:1", phone, "/link-session off")
+	send("whatsapp:1", phone, "alone again")
+
+	// then
+	// ... the reset carried both chats to the new backend; after unlinking the phone gets its own
+	a.Equal([]string{"after reset"}, backends[2].messages)
+	a.Equal("Unlinked: this chat will start a new conversation.", unlinked)
+	a.Equal([]string{"alone again"}, backends[3].messages)
+	a.False(backends[2].closed)
+}
+
+func TestLinkCodes_Expire(t *testing.T) {
+	// given
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := linkCodes{codes: make(map[string]linkCode), now: func() time.Time { return now }}
+	code, err := l.issue("k")
+	require.NoError(t, err)
+
+	// when
+	now = now.Add(linkCodeTTL)
+	_, ok := l.redeem(code)
+
+	// then
+	assert.False(t, ok)
+}
+
+func TestSessionManager_EvictingLinkedKeyKeepsSharedBackend(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a cap of two and two keys sharing one backend
+	var backends []*stubBackend
+	f := &stubFactory{next: func() Backend {
+		be := &stubBackend{}
+		backends = append(backends, be)
+		return be
+	}}
+	mgr := NewSessionManager(f, nil)
+	mgr.maxSessions = 2
+	_, err := mgr.GetOrCreateSession("a", Capabilities{})
+	r.NoError(err)
+	r.NoError(mgr.Link("b", "a"))
+
+	// when
+	// ... a third key pushes one alias out
+	_, err = mgr.GetOrCreateSession("c", Capabilities{})
+	r.NoError(err)
+
+	// then
+	// ... the shared backend stays open under the remaining key
+	a.False(backends[0].closed)
+	r.NoError(mgr.Close())
+	a.True(backends[0].closed)
+}
@@ -0,0 +1,110 @@
+package core
+
+// Mirror receives a copy of every chat's output, for systems that consume
+// the bot without being a chat platform (internal/webhook).
+type Mirror interface {
+	// Outbound returns where copies of in's replies go.
+	Outbound(in Inbound) Outbound
+}
+
+// DenialReporter is implemented by Outbounds that want to hear about tool
+// calls the permission checker refused during a turn.
+type DenialReporter interface {
+	ToolDenied(toolName, reason string)
+}
+
+// SetMirror copies every reply, update and reaction, after the outbound
+// filters, to m.
+func (b *Bot) SetMirror(m Mirror) {
+	b.mirror = m
+}
+
+// mirrored returns out teed to the mirror's Outbound for in.
+func (b *Bot) mirrored(in Inbound, out Outbound) Outbound {
+	if b.mirror == nil || out == nil {
+		return out
+	}
+	return &teeOutbound{Outbound: out, copy: b.mirror.Outbound(in)}
+}
+
+// teeOutbound sends everything to the chat's Outbound and a copy to the
+// mirror's. The chat's result is returned; the mirror handles its own
+// failures.
+type teeOutbound struct {
+	Outbound
+	copy Outbound
+}
+
+func (t *teeOutbound) SendTyping() error {
+	_ = t.copy.SendTyping()
+	return t.Outbound.SendTyping()
+}
+
+func (t *teeOutbound) PostResponse(content string) error {
+	_ = t.copy.PostResponse(content)
+	return t.Outbound.PostResponse(content)
+}
+
+func (t *teeOutbound) AddReaction(emoji string) error {
+	_ = t.copy.AddReaction(emoji)
+	return t.Outbound.AddReaction(emoji)
+}
+
+func (t *teeOutbound) SendUpdate(message string) error {
+	_ = t.copy.SendUpdate(message)
+	return t.Outbound.SendUpdate(message)
+}
+
+// Unwrap returns the chat's Outbound, so outboundAs finds its optional
+// interfaces.
+func (t *teeOutbound) Unwrap() Outbound {
+	return t.Outbound
+}
+
+// SendFile and SendVoice reach the chat only, so the tee keeps the chat's
+// optional interfaces.
+func (t *teeOutbound) SendFile(name string, content []byte) error {
+	fs, ok := t.Outbound.(FileSender)
+	if !ok {
+		return ErrFilesUnsupported
+	}
+	return fs.SendFile(name, content)
+}
+
+func (t *teeOutbound) SendVoice(audio []byte, mimeType string) error {
+	vs, ok := t.Outbound.(VoiceSender)
+	if !ok {
+		return ErrVoiceUnsupported
+	}
+	return vs.SendVoice(audio, mimeType)
+}
+
+func (t *teeOutbound) ToolDenied(toolName, reason string) {
+	if r, ok := t.copy.(DenialReporter); ok {
+		r.ToolDenied(toolName, reason)
+	}
+}
+
+// reportingChecker tells a DenialReporter about every call its checker
+// refuses.
+type reportingChecker struct {
+	PermissionChecker
+	report DenialReporter
+}
+
+// reportDenials wraps pc when out wants to hear about refusals.
+func reportDenials(pc PermissionChecker, out Outbound) PermissionChecker {
+	r, ok := out.(DenialReporter)
+	if !ok || pc == nil {
+		return pc
+	}
+	return &reportingChecker{PermissionChecker: pc, report: r}
+}
+
+func (c *reportingChecker) Check(toolName string, input ToolInput) (bool, string) {
+	allow, reason := c.PermissionChecker.Check(toolName, input)
+	if !allow {
+		c.report.ToolDenied(toolName, reason)
+	}
+	return allow, reason
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type denyAll struct{}
+
+func (denyAll) Check(toolName string, input ToolInput) (bool, string) { return false, "not here" }
+
+// toolTryingBackend sends an update and tries one tool before answering.
+type toolTryingBackend struct{ stubBackend }
+
+func (b *toolTryingBackend) Converse(_ context.Context, _ Inbound, out Outbound, perms PermissionChecker) (string, error) {
+	_ = out.SendUpdate("looking")
+	perms.Check("Bash", ToolInput{Command: "rm -rf /"})
+	return "done", nil
+}
+
+type recordingMirror struct {
+	out    *stubResponder
+	keys   []SessionKey
+	denied []string
+}
+
+func (m *recordingMirror) Outbound(in Inbound) Outbound {
+	m.keys = append(m.keys, in.SessionKey)
+	return &denialRecorder{stubResponder: m.out, m: m}
+}
+
+type denialRecorder struct {
+	*stubResponder
+	m *recordingMirror
+}
+
+func (d *denialRecorder) ToolDenied(toolName, reason string) {
+	d.m.denied = append(d.m.denied, toolName+": "+reason)
+}
+
+func TestSetMirror_CopiesRepliesAndDenials(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &toolTryingBackend{} }}, nil), denyAll{})
+	mirror := &recordingMirror{out: &stubResponder{}}
+	bot.SetMirror(mirror)
+	chat := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "clean up", Reply: chat}))
+
+	// then
+	// ... the chat and the mirror both saw the turn
+	a.Equal([]string{"done"}, chat.posted)
+	a.Equal([]string{"looking"}, chat.updates)
+	a.Equal([]SessionKey{"k"}, mirror.keys)
+	a.Equal([]string{"done"}, mirror.out.posted)
+	a.Equal([]string{"looking"}, mirror.out.updates)
+	a.Equal([]string{"Bash: not here"}, mirror.denied)
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxSessionName = 64
+	maxSessionTags = 10
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Rename names key's session; "" clears the name. Linked chats share it.
+func (m *SessionManager) Rename(key SessionKey, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	if !ok {
+		return errors.New("no active session")
+	}
+	s.name = name
+	return nil
+}
+
+// Tag adds and removes tags on key's session and returns the result,
+// sorted.
+func (m *SessionManager) Tag(key SessionKey, add, remove []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	if !ok {
+		return nil, errors.New("no active session")
+	}
+	tags := slices.DeleteFunc(slices.Clone(s.tags), func(t string) bool {
+		return slices.Contains(remove, t)
+	})
+	for _, t := range add {
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > maxSessionTags {
+		return nil, errors.Errorf("a session can have at most %d tags", maxSessionTags)
+	}
+	slices.Sort(tags)
+	s.tags = tags
+	return slices.Clone(tags), nil
+}
+
+// Matches reports whether the session has tag and lives in workDir. Empty
+// arguments match everything.
+func (i SessionInfo) Matches(tag, workDir string) bool {
+	if tag != "" && !slices.Contains(i.Tags, strings.ToLower(tag)) {
+		return false
+	}
+	return workDir == "" || i.WorkDir == workDir
+}
+
+func (b *Bot) cmdRenameSession(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	backend, err := t.sessions.GetOrCreateSession(in.SessionKey, in.Capabilities)
+	if err != nil {
+		return "", err
+	}
+	if args == "" {
+		return b.tr(in, "Use /rename-session <name>, or /rename-session - to clear it. Session ID: %s.", backend.SessionID()), nil
+	}
+	name := args
+	if name == "-" {
+		name = ""
+	}
+	if len([]rune(name)) > maxSessionName {
+		return b.tr(in, "Session names can be at most %d characters.", maxSessionName), nil
+	}
+	if err := t.sessions.Rename(in.SessionKey, name); err != nil {
+		return "", err
+	}
+	if name == "" {
+		return b.tr(in, "Session name cleared."), nil
+	}
+	return b.tr(in, "Session renamed to %q.", name), nil
+}
+
+// cmdTagSession adds tags to the chat's session; a leading "-" removes
+// one instead.
+func (b *Bot) cmdTagSession(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	var add, remove []string
+	for _, f := range strings.Fields(strings.ToLower(args)) {
+		tag, drop := strings.CutPrefix(f, "-")
+		if !tagPattern.MatchString(tag) {
+			return b.tr(in, "Invalid tag %q: use up to 32 lowercase letters, digits, - and _.", tag), nil
+		}
+		if drop {
+			remove = append(remove, tag)
+		} else {
+			add = append(add, tag)
+		}
+	}
+	if _, err := t.sessions.GetOrCreateSession(in.SessionKey, in.Capabilities); err != nil {
+		return "", err
+	}
+	tags, err := t.sessions.Tag(in.SessionKey, add, remove)
+	if err != nil {
+		return b.tr(in, "Could not tag the session: %s", err), nil
+	}
+	if len(tags) == 0 {
+		return b.tr(in, "This session has no tags. Use /tag-session <tag>... to add some, -<tag> to remove one."), nil
+	}
+	return b.tr(in, "Tags: %s", strings.Join(tags, ", ")), nil
+}
+
+// cmdCurrentSession describes the chat's session and its settings.
+func (b *Bot) cmdCurrentSession(_ context.Context, in Inbound, _ string) (string, error) {
+	t := b.tenantFor(in)
+	backend, err := t.sessions.GetOrCreateSession(in.SessionKey, in.Capabilities)
+	if err != nil {
+		return "", err
+	}
+	info, _ := t.sessions.Info(in.SessionKey)
+	settings := t.sessions.Settings(in.SessionKey)
+
+	name, workDir, tags, persona := "-", "default", "-", defaultPersona
+	if info.Name != "" {
+		name = info.Name
+	}
+	if info.WorkDir != "" {
+		workDir = info.WorkDir
+	}
+	if len(info.Tags) > 0 {
+		tags = strings.Join(info.Tags, ", ")
+	}
+	if p, ok := backend.(Personalizer); ok && p.CurrentPersona().Name != "" {
+		persona = p.CurrentPersona().Name
+	}
+	verbosity := settings.Verbosity
+	if verbosity == "" {
+		verbosity = VerbosityQuiet
+	}
+	return b.tr(in, "Session %s (name: %s, tags: %s)\nWork dir: %s\nVerbosity %s, read-only %s, speech %s, persona %s\nGeneration: %s",
+		info.SessionID, name, tags, workDir, verbosity, onOff(settings.ReadOnly), onOff(settings.Speak), persona, settings.Generation), nil
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
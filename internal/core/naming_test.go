@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_RenameAndTagSession(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b1"} }}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/rename-session billing bug", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/tag-session Billing urgent", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/tag-session -urgent", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/tag-session bad!tag", Reply: out}))
+
+	// then
+	a.Equal([]string{
+		`Session renamed to "billing bug".`,
+		"Tags: billing, urgent",
+		"Tags: billing",
+		`Invalid tag "bad!tag": use up to 32 lowercase letters, digits, - and _.`,
+	}, out.posted)
+	infos := mgr.Sessions()
+	r.Len(infos, 1)
+	a.Equal("billing bug", infos[0].Name)
+	a.Equal([]string{"billing"}, infos[0].Tags)
+}
+
+func TestSessionManager_NamesDoNotSurviveNewSession(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	mgr := NewSessionManager(f, nil)
+	_, err := mgr.GetOrCreateSession("k1", Capabilities{})
+	r.NoError(err)
+	r.NoError(mgr.Rename("k1", "old work"))
+	_, err = mgr.Tag("k1", []string{"old"}, nil)
+	r.NoError(err)
+
+	// when
+	r.NoError(mgr.NewSession("k1", "/srv/repo", Capabilities{}))
+
+	// then
+	info := mgr.Sessions()[0]
+	a.Empty(info.Name)
+	a.Empty(info.Tags)
+	a.Equal("/srv/repo", info.WorkDir)
+	a.Error(mgr.Rename("missing", "x"))
+}
+
+func TestSessionInfo_Matches(t *testing.T) {
+	a := assert.New(t)
+	info := SessionInfo{Tags: []string{"billing"}, WorkDir: "/srv/repo"}
+
+	a.True(info.Matches("", ""))
+	a.True(info.Matches("Billing", "/srv/repo"))
+	a.False(info.Matches("urgent", ""))
+	a.False(info.Matches("", "/srv/other"))
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxPause bounds /pause's timer, so a typo can't silence a channel for
+// years.
+const maxPause = 7 * 24 * time.Hour
+
+// pausedChannel is one channel silenced with /pause. until is zero when
+// only /resume ends it.
+type pausedChannel struct {
+	until time.Time
+	timer *time.Timer
+}
+
+// pausedChannels tracks channels where the bot ignores everything but
+// /pause, /resume and /status. Pauses live in memory, so a restart
+// resumes every channel.
+type pausedChannels struct {
+	mu       sync.Mutex
+	channels map[string]pausedChannel
+}
+
+// pause silences scope, replacing any earlier pause. With d > 0, resumed
+// runs when the timer ends it.
+func (p *pausedChannels) pause(scope string, d time.Duration, resumed func()) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.channels == nil {
+		p.channels = make(map[string]pausedChannel)
+	}
+	p.stopLocked(scope)
+	var c pausedChannel
+	if d > 0 {
+		c.until = time.Now().Add(d)
+		var timer *time.Timer
+		timer = time.AfterFunc(d, func() {
+			p.mu.Lock()
+			current, ok := p.channels[scope]
+			expired := ok && current.timer == timer
+			if expired {
+				delete(p.channels, scope)
+			}
+			p.mu.Unlock()
+			if expired {
+				resumed()
+			}
+		})
+		c.timer = timer
+	}
+	p.channels[scope] = c
+	return c.until
+}
+
+// resume ends scope's pause, reporting whether it had one.
+func (p *pausedChannels) resume(scope string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.channels[scope]
+	p.stopLocked(scope)
+	return ok
+}
+
+func (p *pausedChannels) stopLocked(scope string) {
+	if c, ok := p.channels[scope]; ok {
+		if c.timer != nil {
+			c.timer.Stop()
+		}
+		delete(p.channels, scope)
+	}
+}
+
+// get returns scope's pause, if any.
+func (p *pausedChannels) get(scope string) (pausedChannel, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.channels[scope]
+	return c, ok
+}
+
+// pauseScope is the channel /pause applies to: threads share their parent
+// channel's, and chats without a channel pause only themselves.
+func pauseScope(in Inbound) string {
+	if in.ChannelID != "" {
+		return in.ChannelID
+	}
+	return string(in.SessionKey)
+}
+
+// pauseAdmits reports whether in gets through its channel's pause.
+func (b *Bot) pauseAdmits(in Inbound, command string) bool {
+	switch command {
+	case "pause", "resume", "status":
+		return true
+	}
+	_, paused := b.paused.get(pauseScope(in))
+	return !paused
+}
+
+// Ignores reports whether the bot will drop text sent in channelID
+// because the channel is paused, so plugins can skip visible work such as
+// opening a thread for it.
+func (b *Bot) Ignores(channelID, text string) bool {
+	_, name, _, _ := parseCommand(text)
+	return !b.pauseAdmits(Inbound{ChannelID: channelID}, name)
+}
+
+// pauseStatus describes in's channel's pause for /status, or "" when the
+// bot is answering there.
+func (b *Bot) pauseStatus(in Inbound) string {
+	c, ok := b.paused.get(pauseScope(in))
+	switch {
+	case !ok:
+		return ""
+	case c.until.IsZero():
+		return b.tr(in, "Paused in this channel until /resume.")
+	}
+	return b.tr(in, "Paused in this channel until %s.", b.formatTime(in, c.until))
+}
+
+// cmdPause silences the bot in the channel, for both mentions and
+// follow-ups, until /resume or the optional duration runs out.
+func (b *Bot) cmdPause(_ context.Context, in Inbound, args string) (string, error) {
+	var d time.Duration
+	if args != "" {
+		var err error
+		d, err = time.ParseDuration(args)
+		if err != nil || d <= 0 || d > maxPause {
+			return b.tr(in, "Use /pause, or /pause <duration> such as 30m or 2h (up to 7 days)."), nil
+		}
+	}
+	reply := in.Reply
+	until := b.paused.pause(pauseScope(in), d, func() {
+		if reply != nil {
+			_ = reply.PostResponse(b.tr(in, "Resumed: the pause is over."))
+		}
+	})
+	if until.IsZero() {
+		return b.tr(in, "Paused. I'll ignore this channel until /resume."), nil
+	}
+	return b.tr(in, "Paused. I'll ignore this channel until %s, or /resume.", b.formatTime(in, until)), nil
+}
+
+// cmdResume ends the channel's pause.
+func (b *Bot) cmdResume(_ context.Context, in Inbound, _ string) (string, error) {
+	if !b.paused.resume(pauseScope(in)) {
+		return b.tr(in, "The bot isn't paused here."), nil
+	}
+	return b.tr(in, "Resumed."), nil
+}
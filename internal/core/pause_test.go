@@ -0,0 +1,114 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_PauseSilencesChannelUntilResume(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... two threads of one channel and another channel
+	be := &stubBackend{id: "b", converseR: "ok"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out, other := &stubResponder{}, &stubResponder{}
+	thread := Inbound{SessionKey: "discord:thread:1", ChannelID: "discord:c1", Reply: out}
+	sibling := Inbound{SessionKey: "discord:thread:2", ChannelID: "discord:c1", Reply: out}
+	elsewhere := Inbound{SessionKey: "discord:thread:3", ChannelID: "discord:c2", Reply: other}
+
+	// when
+	thread.Text = "/pause"
+	r.NoError(bot.HandleInbound(thread))
+	sibling.Text = "are you there?"
+	r.NoError(bot.HandleInbound(sibling))
+	sibling.Text = "/help"
+	r.NoError(bot.HandleInbound(sibling))
+	elsewhere.Text = "hello"
+	r.NoError(bot.HandleInbound(elsewhere))
+	thread.Text = "/status"
+	r.NoError(bot.HandleInbound(thread))
+	thread.Text = "/resume"
+	r.NoError(bot.HandleInbound(thread))
+	sibling.Text = "are you there now?"
+	r.NoError(bot.HandleInbound(sibling))
+
+	// then
+	// ... the paused channel's messages and commands are dropped, other channels answer
+	a.Equal([]string{"hello", "are you there now?"}, be.messages)
+	r.Len(out.posted, 4)
+	a.Equal("Paused. I'll ignore this channel until /resume.", out.posted[0])
+	a.Contains(out.posted[1], "Paused in this channel until /resume.")
+	a.Equal("Resumed.", out.posted[2])
+	a.Equal([]string{"ok"}, other.posted)
+}
+
+// lockedResponder is a stubResponder safe to post to from a timer.
+type lockedResponder struct {
+	mu sync.Mutex
+	stubResponder
+}
+
+func (l *lockedResponder) PostResponse(text string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stubResponder.PostResponse(text)
+}
+
+func (l *lockedResponder) postedCopy() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.posted...)
+}
+
+func TestHandleInbound_PauseResumesOnTimer(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{id: "b", converseR: "ok"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out := &lockedResponder{}
+	in := Inbound{SessionKey: "whatsapp:1@s.whatsapp.net", ChannelID: "1@s.whatsapp.net", Reply: out}
+
+	// when
+	in.Text = "/pause 20ms"
+	r.NoError(bot.HandleInbound(in))
+	r.Eventually(func() bool { return len(out.postedCopy()) == 2 }, 2*time.Second, time.Millisecond)
+	in.Text = "back?"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the end of the pause is announced and turns run again
+	posted := out.postedCopy()
+	a.Contains(posted[0], "Paused. I'll ignore this channel until ")
+	a.Equal("Resumed: the pause is over.", posted[1])
+	a.Equal([]string{"back?"}, be.messages)
+}
+
+func TestHandleInbound_PauseRejectsBadDuration(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{id: "b", converseR: "ok"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	for _, args := range []string{"soon", "-5m", "720h"} {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c", Text: "/pause " + args, Reply: out}))
+	}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c", Text: "/resume", Reply: out}))
+
+	// then
+	// ... nothing was paused
+	a.Len(out.posted, 4)
+	a.Equal("Use /pause, or /pause <duration> such as 30m or 2h (up to 7 days).", out.posted[0])
+	a.Equal("The bot isn't paused here.", out.posted[3])
+}
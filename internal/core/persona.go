@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+// defaultPersona is the /persona argument that restores the defaults.
+const defaultPersona = "default"
+
+// cmdPersona switches the chat's session to a persona, or back to the
+// default, for the rest of that session.
+func (b *Bot) cmdPersona(_ context.Context, in Inbound, args string) (string, error) {
+	if b.personas == nil {
+		return b.tr(in, "Personas are not available."), nil
+	}
+	backend, err := b.tenantFor(in).sessions.GetOrCreateSession(in.SessionKey, in.Capabilities)
+	if err != nil {
+		return "", err
+	}
+	p, ok := backend.(Personalizer)
+	if !ok {
+		return b.tr(in, "This session cannot switch personas."), nil
+	}
+	all, err := b.personas.Personas()
+	if err != nil {
+		return "", err
+	}
+	names := []string{defaultPersona}
+	for _, persona := range all {
+		names = append(names, persona.Name)
+	}
+	available := strings.Join(names, ", ")
+
+	name := strings.ToLower(args)
+	switch name {
+	case "":
+		current := p.CurrentPersona().Name
+		if current == "" {
+			current = defaultPersona
+		}
+		return b.tr(in, "Persona is %s. Available: %s. Use /persona <name>.", current, available), nil
+	case defaultPersona:
+		if err := p.SetPersona(Persona{}); err != nil {
+			return b.tr(in, "Could not switch persona: %s", err), nil
+		}
+		return b.tr(in, "Back to the default persona."), nil
+	}
+
+	persona, err := b.personas.Persona(name)
+	if err != nil {
+		return b.tr(in, "Unknown persona %q. Available: %s.", args, available), nil
+	}
+	if err := p.SetPersona(persona); err != nil {
+		return b.tr(in, "Could not switch persona: %s", err), nil
+	}
+	return b.tr(in, "Switched to %s for this session. /new-session goes back to the default.", persona.Name), nil
+}
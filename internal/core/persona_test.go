@@ -0,0 +1,79 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type personaBackend struct {
+	stubBackend
+	persona Persona
+}
+
+func (p *personaBackend) SetPersona(persona Persona) error { p.persona = persona; return nil }
+func (p *personaBackend) CurrentPersona() Persona          { return p.persona }
+
+type stubPersonas []Persona
+
+func (s stubPersonas) Personas() ([]Persona, error) { return s, nil }
+
+func (s stubPersonas) Persona(name string) (Persona, error) {
+	for _, p := range s {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Persona{}, errors.New("not found")
+}
+
+func TestHandleInbound_PersonaCommand(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &personaBackend{stubBackend: stubBackend{id: "b"}}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.SetPersonas(stubPersonas{{Name: "reviewer", Prompt: "Be terse."}})
+	out := &stubResponder{}
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: text, Reply: out}))
+	}
+
+	// when
+	send("/persona Reviewer")
+	applied := be.persona
+	send("/persona")
+	send("/persona pirate")
+	send("/persona default")
+
+	// then
+	a.Equal("Be terse.", applied.Prompt)
+	a.Empty(be.persona.Name)
+	a.Equal([]string{
+		"Switched to reviewer for this session. /new-session goes back to the default.",
+		"Persona is reviewer. Available: default, reviewer. Use /persona <name>.",
+		`Unknown persona "pirate". Available: default, reviewer.`,
+		"Back to the default persona.",
+	}, out.posted)
+}
+
+func TestHandleInbound_PersonaUnavailable(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a store but a backend that can't take personas
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil), nil)
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/persona reviewer", Reply: out}))
+	bot.SetPersonas(stubPersonas{})
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/persona reviewer", Reply: out}))
+
+	// then
+	a.Equal([]string{"Personas are not available.", "This session cannot switch personas."}, out.posted)
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+// cmdPinContext shows, sets or clears the chat's pinned context. A lone
+// message link pins that message's text.
+func (b *Bot) cmdPinContext(_ context.Context, in Inbound, args string) (string, error) {
+	if b.pinner == nil {
+		return b.tr(in, "Pinned context is not available."), nil
+	}
+	if in.ChannelID == "" {
+		return b.tr(in, "This chat cannot pin context."), nil
+	}
+
+	switch {
+	case args == "":
+		pinned, err := b.pinner.PinnedContext(in.ChannelID)
+		if err != nil {
+			return "", err
+		}
+		if pinned == "" {
+			return b.tr(in, "Nothing is pinned here. Use /pin-context <text or message link>."), nil
+		}
+		return b.tr(in, "Pinned for this chat:\n%s\nUse /pin-context clear to remove it.", pinned), nil
+	case strings.EqualFold(args, "clear"):
+		if err := b.pinner.PinContext(in.ChannelID, ""); err != nil {
+			return "", err
+		}
+		return b.tr(in, "Pinned context cleared."), nil
+	}
+
+	text := args
+	if f, ok := outboundAs[MessageFetcher](in.Reply); ok && !strings.ContainsAny(args, " \n") {
+		fetched, isLink, err := f.FetchMessage(args)
+		if err != nil {
+			return b.tr(in, "Could not read that message: %s", err), nil
+		}
+		if isLink {
+			text = fetched
+		}
+	}
+	if err := b.pinner.PinContext(in.ChannelID, text); err != nil {
+		return b.tr(in, "Could not pin context: %s", err), nil
+	}
+	return b.tr(in, "Pinned. New sessions in this chat will start with it."), nil
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPinner struct{ pins map[string]string }
+
+func (p *stubPinner) PinnedContext(channelID string) (string, error) {
+	return p.pins[channelID], nil
+}
+
+func (p *stubPinner) PinContext(channelID, text string) error {
+	p.pins[channelID] = text
+	return nil
+}
+
+type fetchingResponder struct {
+	stubResponder
+	messages map[string]string
+}
+
+func (f *fetchingResponder) FetchMessage(link string) (string, bool, error) {
+	if !strings.HasPrefix(link, "https://chat.example/") {
+		return "", false, nil
+	}
+	return f.messages[link], true, nil
+}
+
+func TestHandleInbound_PinContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	pinner := &stubPinner{pins: map[string]string{}}
+	bot.SetPinner(pinner)
+	out := &fetchingResponder{messages: map[string]string{"https://chat.example/m/1": "Go 1.22, tabs"}}
+	in := Inbound{SessionKey: "k1", ChannelID: "c:1", Reply: out}
+
+	// when
+	in.Text = "/pin-context use table-driven tests"
+	r.NoError(bot.HandleInbound(in))
+	typed := pinner.pins["c:1"]
+	in.Text = "/pin-context https://chat.example/m/1"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/pin-context"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/pin-context clear"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	a.Equal("use table-driven tests", typed)
+	a.Equal("", pinner.pins["c:1"])
+	a.Equal([]string{
+		"Pinned. New sessions in this chat will start with it.",
+		"Pinned. New sessions in this chat will start with it.",
+		"Pinned for this chat:\nGo 1.22, tabs\nUse /pin-context clear to remove it.",
+		"Pinned context cleared.",
+	}, out.posted)
+}
+
+func TestHandleInbound_PinContextFetchesThroughFiltersAndMirror(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... the filter and mirror wrappers main always installs
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.AddOutboundFilter(func(s string) string { return s })
+	bot.SetMirror(&recordingMirror{out: &stubResponder{}})
+	pinner := &stubPinner{pins: map[string]string{}}
+	bot.SetPinner(pinner)
+	out := &fetchingResponder{messages: map[string]string{"https://chat.example/m/1": "Go 1.22, tabs"}}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", ChannelID: "c:1", Reply: out, Text: "/pin-context https://chat.example/m/1"}))
+
+	// then
+	a.Equal("Go 1.22, tabs", pinner.pins["c:1"])
+}
+
+func TestHandleInbound_PinContextUnavailable(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", ChannelID: "c:1", Text: "/pin-context x", Reply: out}))
+	bot.SetPinner(&stubPinner{pins: map[string]string{}})
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/pin-context x", Reply: out}))
+
+	// then
+	a.Equal([]string{"Pinned context is not available.", "This chat cannot pin context."}, out.posted)
+}
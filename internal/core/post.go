@@ -0,0 +1,50 @@
+package core
+
+import "github.com/pkg/errors"
+
+// ChatOpener is implemented by channel plugins that can reach any chat they
+// serve without an inbound, so the bot can post on another agent's behalf.
+// messageID is the message AddReaction targets and may be empty.
+type ChatOpener interface {
+	Capabilities() Capabilities
+	OpenChat(chatID, messageID string) Outbound
+}
+
+// ErrUnknownChannel is returned by Post and React for a channel no plugin
+// was added for.
+var ErrUnknownChannel = errors.New("unknown channel")
+
+// ErrReactionsUnsupported is returned by React on channels without
+// reactions.
+var ErrReactionsUnsupported = errors.New("channel cannot react")
+
+// AddChannel makes a plugin's chats reachable through Post and React under
+// id. Call before anything posts.
+func (b *Bot) AddChannel(id string, c ChatOpener) {
+	if b.channels == nil {
+		b.channels = make(map[string]ChatOpener)
+	}
+	b.channels[id] = c
+}
+
+// Post sends text to chatID on channel through the same outbound filters
+// as the bot's own replies.
+func (b *Bot) Post(channel, chatID, text string) error {
+	c, ok := b.channels[channel]
+	if !ok {
+		return errors.Wrap(ErrUnknownChannel, channel)
+	}
+	return FilterOutbound(c.OpenChat(chatID, ""), b.filters...).PostResponse(text)
+}
+
+// React adds emoji to messageID in chatID on channel.
+func (b *Bot) React(channel, chatID, messageID, emoji string) error {
+	c, ok := b.channels[channel]
+	if !ok {
+		return errors.Wrap(ErrUnknownChannel, channel)
+	}
+	if !c.Capabilities().Reactions {
+		return errors.Wrap(ErrReactionsUnsupported, channel)
+	}
+	return c.OpenChat(chatID, messageID).AddReaction(emoji)
+}
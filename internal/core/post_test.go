@@ -0,0 +1,63 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubChats opens the same stubResponder for every chat.
+type stubChats struct {
+	caps   Capabilities
+	out    *stubResponder
+	opened []string
+}
+
+func (c *stubChats) Capabilities() Capabilities { return c.caps }
+
+func (c *stubChats) OpenChat(chatID, messageID string) Outbound {
+	c.opened = append(c.opened, chatID+"/"+messageID)
+	return c.out
+}
+
+func TestBot_Post_AppliesOutboundFilters(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{}, nil), nil)
+	bot.AddOutboundFilter(func(s string) string { return strings.ReplaceAll(s, "hunter2", "****") })
+	chats := &stubChats{out: &stubResponder{}}
+	bot.AddChannel("discord", chats)
+
+	// when
+	err := bot.Post("discord", "123", "the password is hunter2")
+
+	// then
+	require.NoError(t, err)
+	a.Equal([]string{"123/"}, chats.opened)
+	a.Equal([]string{"the password is ****"}, chats.out.posted)
+}
+
+func TestBot_React(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{}, nil), nil)
+	discord := &stubChats{caps: Capabilities{Reactions: true}, out: &stubResponder{}}
+	bot.AddChannel("discord", discord)
+	bot.AddChannel("whatsapp", &stubChats{out: &stubResponder{}})
+
+	// when
+	err := bot.React("discord", "123", "m1", "👍")
+	noReactions := bot.React("whatsapp", "27@s.whatsapp.net", "m1", "👍")
+	unknown := bot.Post("slack", "C1", "hi")
+
+	// then
+	a.NoError(err)
+	a.Equal([]string{"123/m1"}, discord.opened)
+	a.Equal([]string{"👍"}, discord.out.reactions)
+	a.ErrorIs(noReactions, ErrReactionsUnsupported)
+	a.ErrorIs(unknown, ErrUnknownChannel)
+}
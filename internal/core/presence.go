@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Presence summarises what the bot is doing, for a platform's status line.
+type Presence struct {
+	// Working is how many sessions are running a turn.
+	Working int
+	// Queued is how many sessions wait for a slot under the concurrency
+	// cap.
+	Queued int
+	// Awaiting is how many turns the token guard holds for /confirm.
+	Awaiting int
+	// ETA is roughly how long the last queued session waits, from recent
+	// turn durations. Zero when nothing is queued or no turn has finished.
+	ETA time.Duration
+}
+
+// String renders p as e.g. "Working on 2 tasks", "Working on 4 tasks, 3
+// queued (~5 min)", "Awaiting approval" or "Idle".
+func (p Presence) String() string {
+	switch {
+	case p.Working == 0 && p.Awaiting > 0:
+		return "Awaiting approval"
+	case p.Working == 0:
+		return "Idle"
+	}
+	s := "Working on 1 task"
+	if p.Working > 1 {
+		s = fmt.Sprintf("Working on %d tasks", p.Working)
+	}
+	if p.Queued == 0 {
+		return s
+	}
+	s += fmt.Sprintf(", %d queued", p.Queued)
+	if p.ETA > 0 {
+		s += fmt.Sprintf(" (~%d min)", max(1, int((p.ETA+time.Minute-1)/time.Minute)))
+	}
+	return s
+}
+
+// PresenceSetter is implemented by channel plugins that can show the bot's
+// state in their status, e.g. Discord's custom status.
+type PresenceSetter interface {
+	SetPresence(p Presence) error
+}
+
+// turnTimer keeps a moving average of how long turns take, for Presence's
+// ETA.
+type turnTimer struct {
+	mu  sync.Mutex
+	avg time.Duration
+}
+
+func (t *turnTimer) observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.avg == 0 {
+		t.avg = d
+		return
+	}
+	t.avg = (4*t.avg + d) / 5
+}
+
+func (t *turnTimer) average() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.avg
+}
+
+// presence reports what the bot is doing right now. The ETA assumes queued
+// sessions go through the concurrency cap in waves of average turns.
+func (b *Bot) presence() Presence {
+	p := Presence{
+		Working:  int(b.working.Load()),
+		Queued:   int(b.queued.Load()),
+		Awaiting: b.held.len(),
+	}
+	if p.Queued > 0 {
+		waves := (p.Queued + cap(b.sem) - 1) / cap(b.sem)
+		p.ETA = time.Duration(waves) * b.turnTimes.average()
+	}
+	return p
+}
+
+// RunPresence shows the bot's state on every added channel that is a
+// PresenceSetter until ctx is done. It checks every interval and only
+// updates a status when the text changes, which keeps within the
+// platforms' rate limits.
+func (b *Bot) RunPresence(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	var last string
+	for {
+		if p := b.presence(); p.String() != last && b.publishPresence(p) {
+			last = p.String()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// publishPresence sets p on every PresenceSetter channel and reports
+// whether all of them took it, so a failed update is tried again.
+func (b *Bot) publishPresence(p Presence) bool {
+	ok := true
+	for id, c := range b.channels {
+		s, isSetter := c.(PresenceSetter)
+		if !isSetter {
+			continue
+		}
+		if err := s.SetPresence(p); err != nil {
+			slog.Warn("setting presence", "channel", id, "error", err)
+			ok = false
+		}
+	}
+	return ok
+}
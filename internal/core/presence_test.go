@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresence_String(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Presence
+		want string
+	}{
+		{name: "idle", p: Presence{}, want: "Idle"},
+		{name: "one task", p: Presence{Working: 1}, want: "Working on 1 task"},
+		{name: "queued without history", p: Presence{Working: 2, Queued: 1}, want: "Working on 2 tasks, 1 queued"},
+		{name: "queued with eta", p: Presence{Working: 4, Queued: 3, ETA: 90 * time.Second}, want: "Working on 4 tasks, 3 queued (~2 min)"},
+		{name: "awaiting approval", p: Presence{Awaiting: 1}, want: "Awaiting approval"},
+		{name: "working wins over awaiting", p: Presence{Working: 1, Awaiting: 1}, want: "Working on 1 task"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.p.String())
+		})
+	}
+}
+
+func TestBot_PresenceCountsWorkingAndQueued(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a bot allowing one concurrent session, busy with kA
+	bot, entered, release := newGatedBot(1)
+	bot.turnTimes.observe(2 * time.Minute)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = bot.HandleInbound(Inbound{SessionKey: "kA", Text: "a"})
+	}()
+	a.Equal("a", waitEntered(t, entered))
+
+	// when
+	// ... kB arrives and waits for the slot
+	go func() {
+		defer wg.Done()
+		_ = bot.HandleInbound(Inbound{SessionKey: "kB", Text: "b"})
+	}()
+	r.Eventually(func() bool { return bot.presence().Queued == 1 }, 2*time.Second, time.Millisecond)
+
+	// then
+	a.Equal(Presence{Working: 1, Queued: 1, ETA: 2 * time.Minute}, bot.presence())
+	close(release)
+	a.Equal("b", waitEntered(t, entered))
+	wg.Wait()
+	a.Equal(Presence{}, bot.presence())
+}
+
+// presenceChats records every presence set on it.
+type presenceChats struct {
+	stubChats
+	mu  sync.Mutex
+	set []string
+}
+
+func (c *presenceChats) SetPresence(p Presence) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set = append(c.set, p.String())
+	return nil
+}
+
+func (c *presenceChats) statuses() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.set...)
+}
+
+func TestBot_RunPresencePublishesChanges(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{}, nil), nil)
+	chats := &presenceChats{}
+	bot.AddChannel("chat", chats)
+	bot.AddChannel("plain", &stubChats{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bot.RunPresence(ctx, time.Millisecond)
+	}()
+	r.Eventually(func() bool { return len(chats.statuses()) == 1 }, 2*time.Second, time.Millisecond)
+
+	// when
+	// ... a turn is held for /confirm
+	bot.held.put(Inbound{SessionKey: "k"})
+	r.Eventually(func() bool { return len(chats.statuses()) == 2 }, 2*time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+
+	// then
+	// ... each state is published once
+	a.Equal([]string{"Idle", "Awaiting approval"}, chats.statuses())
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+)
+
+// Recover keeps a panic in a platform event handler from crashing the
+// process. Defer it directly at the top of the handler:
+//
+//	defer core.Recover("discord message", notify)
+//
+// The panic is logged with its stack and an error ID, and notify, if
+// non-nil, gets the matching user-facing text to post in the chat.
+// Turns recover on their own inside HandleInbound.
+func Recover(where string, notify func(text string)) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	text, id := UserError(errors.Errorf("panic: %v", v))
+	slog.Error("handler panicked", "in", where, "panic", v, "error_id", id, "stack", string(debug.Stack()))
+	if notify != nil {
+		notify(text)
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panickingBackend panics in its first turn.
+type panickingBackend struct {
+	stubBackend
+	turns int
+}
+
+func (b *panickingBackend) Converse(ctx context.Context, in Inbound, out Outbound, perms PermissionChecker) (string, error) {
+	b.turns++
+	if b.turns == 1 {
+		panic("nil map")
+	}
+	return b.stubBackend.Converse(ctx, in, out, perms)
+}
+
+func TestHandleInbound_PanicMarksSessionSuspect(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	crashed := &panickingBackend{stubBackend: stubBackend{id: "b1"}}
+	fresh := &stubBackend{id: "b2", converseR: "hello again"}
+	queue := []Backend{crashed, fresh}
+	f := &stubFactory{next: func() Backend { b := queue[0]; queue = queue[1:]; return b }}
+	var flushed []string
+	mgr := NewSessionManager(f, func(_ context.Context, b Backend) { flushed = append(flushed, b.SessionID()) })
+	bot := NewBot(mgr, nil)
+	out := &stubResponder{}
+
+	// when
+	err := bot.HandleInbound(Inbound{SessionKey: "k", Text: "hi", Reply: out})
+
+	// then
+	// ... the panic becomes an error the user is told about
+	r.Error(err)
+	a.Equal(ErrCrashed, ErrorKindOf(err))
+	r.Len(out.posted, 1)
+	a.Contains(out.posted[0], "this conversation will start over")
+	info, ok := mgr.Info("k")
+	r.True(ok)
+	a.True(info.Suspect)
+
+	// when
+	err = bot.HandleInbound(Inbound{SessionKey: "k", Text: "again", Reply: out})
+
+	// then
+	// ... the next turn runs on a fresh session; the crashed one is closed unflushed
+	r.NoError(err)
+	a.Equal([]string{"again"}, fresh.messages)
+	a.True(crashed.closed)
+	a.Empty(flushed)
+	info, _ = mgr.Info("k")
+	a.Equal("b2", info.SessionID)
+	a.False(info.Suspect)
+}
+
+func TestRecover(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	var notified []string
+	notify := func(text string) { notified = append(notified, text) }
+	handler := func(fail bool) {
+		defer Recover("test", notify)
+		if fail {
+			panic("boom")
+		}
+	}
+
+	// when
+	handler(false)
+	handler(true)
+
+	// then
+	// ... only the panic is reported, with an error ID
+	if a.Len(notified, 1) {
+		a.Contains(notified[0], "Something went wrong while handling that message. (error ID ")
+	}
+}
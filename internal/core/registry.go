@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ToolExecutor runs a registered tool. input is the raw JSON object the
+// model sent, so tools are free to define their own schema.
+type ToolExecutor func(ctx context.Context, input json.RawMessage, out Outbound) (result string, isError bool)
+
+// RegisteredTool is a tool added at startup on top of the built-in set.
+type RegisteredTool struct {
+	Def     ToolDef
+	Execute ToolExecutor
+}
+
+// ToolRegistry holds the extra tools exposed to every backend. A nil
+// registry is empty.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]RegisteredTool
+	order []string
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]RegisteredTool)}
+}
+
+// Register adds t. Names must be unique and may not shadow a built-in tool.
+func (r *ToolRegistry) Register(t RegisteredTool) error {
+	name := t.Def.Name
+	if name == "" {
+		return errors.New("tool name is empty")
+	}
+	if t.Execute == nil {
+		return errors.Errorf("tool %s has no executor", name)
+	}
+	if isBuiltinTool(name) {
+		return errors.Errorf("tool %s shadows a built-in tool", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[name]; ok {
+		return errors.Errorf("tool %s already registered", name)
+	}
+	r.tools[name] = t
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Lookup returns the registered tool called name.
+func (r *ToolRegistry) Lookup(name string) (RegisteredTool, bool) {
+	if r == nil {
+		return RegisteredTool{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[name]
+	return t, ok
+}
+
+// Defs returns the definitions of all registered tools in registration order.
+func (r *ToolRegistry) Defs() []ToolDef {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]ToolDef, 0, len(r.order))
+	for _, name := range r.order {
+		defs = append(defs, r.tools[name].Def)
+	}
+	return defs
+}
+
+func isBuiltinTool(name string) bool {
+	builtins := []ToolDef{ReactEmojiTool(), SendUpdateTool(), WriteArtifactTool()}
+	builtins = append(builtins, FileTools()...)
+	builtins = append(builtins, SkillTools()...)
+	for _, t := range builtins {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
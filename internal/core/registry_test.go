@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopExecutor(context.Context, json.RawMessage, Outbound) (string, bool) { return "", false }
+
+func TestToolRegistry_RegisterAndLookup(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	reg := NewToolRegistry()
+
+	// when
+	r.NoError(reg.Register(RegisteredTool{Def: ToolDef{Name: "deploy"}, Execute: noopExecutor}))
+	r.NoError(reg.Register(RegisteredTool{Def: ToolDef{Name: "audit"}, Execute: noopExecutor}))
+
+	// then
+	_, ok := reg.Lookup("deploy")
+	a.True(ok)
+	_, ok = reg.Lookup("missing")
+	a.False(ok)
+	a.Equal([]ToolDef{{Name: "deploy"}, {Name: "audit"}}, reg.Defs())
+}
+
+func TestToolRegistry_RejectsInvalidTools(t *testing.T) {
+	a := assert.New(t)
+	reg := NewToolRegistry()
+	a.NoError(reg.Register(RegisteredTool{Def: ToolDef{Name: "deploy"}, Execute: noopExecutor}))
+
+	a.Error(reg.Register(RegisteredTool{Def: ToolDef{Name: "deploy"}, Execute: noopExecutor}), "duplicate")
+	a.Error(reg.Register(RegisteredTool{Def: ToolDef{Name: "Bash"}, Execute: noopExecutor}), "built-in")
+	a.Error(reg.Register(RegisteredTool{Def: ToolDef{Name: ""}, Execute: noopExecutor}), "empty name")
+	a.Error(reg.Register(RegisteredTool{Def: ToolDef{Name: "other"}}), "no executor")
+}
+
+func TestToolRegistry_NilIsEmpty(t *testing.T) {
+	var reg *ToolRegistry
+
+	_, ok := reg.Lookup("deploy")
+	assert.False(t, ok)
+	assert.Empty(t, reg.Defs())
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// lastPrompts remembers each session's last prompt for /retry, as sent to
+// the model: after composing and hooks, with its attachments.
+type lastPrompts struct {
+	mu      sync.Mutex
+	prompts map[SessionKey]Inbound
+}
+
+func (l *lastPrompts) put(in Inbound) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.prompts == nil {
+		l.prompts = make(map[SessionKey]Inbound)
+	}
+	l.prompts[in.SessionKey] = in
+}
+
+func (l *lastPrompts) get(key SessionKey) (Inbound, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	in, ok := l.prompts[key]
+	return in, ok
+}
+
+// differentApproach is what /retry different adds to the prompt.
+const differentApproach = "Try a different approach than last time."
+
+// cmdRetry sends the session's last prompt again. "/retry different" asks
+// for a different approach; any other argument is added as a note. The
+// retry skips the turn cache and leaves the remembered prompt as it was,
+// so retrying twice doesn't stack notes.
+func (b *Bot) cmdRetry(ctx context.Context, in Inbound, args string) (string, error) {
+	prev, ok := b.prompts.get(in.SessionKey)
+	if !ok {
+		return b.tr(in, "There is no message to retry yet."), nil
+	}
+	note := args
+	if strings.EqualFold(note, "different") {
+		note = differentApproach
+	}
+	if note != "" {
+		prev.Text = strings.TrimSpace(prev.Text + "\n\n" + note)
+	}
+	prev.Reply = in.Reply
+	return "", b.dispatch(ctx, prev, false)
+}
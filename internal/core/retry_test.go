@@ -0,0 +1,57 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_RetryResendsLastPrompt(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a turn with an attachment that failed
+	be := &stubBackend{id: "b", converseErr: errors.New("overloaded")}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out := &stubResponder{}
+	in := Inbound{SessionKey: "k", Text: "summarise the log", Attachments: []AttachmentRef{{Path: "/tmp/app.log"}}, Reply: out}
+	r.Error(bot.HandleInbound(in))
+	be.converseErr = nil
+	be.converseR = "ok"
+
+	// when
+	in.Attachments = nil
+	in.Text = "/retry"
+	r.NoError(bot.HandleInbound(in))
+	in.Text = "/retry different"
+	r.NoError(bot.HandleInbound(in))
+
+	// then
+	// ... the prompt goes out again with its attachment, without stacking notes
+	a.Equal([]string{
+		"summarise the log",
+		"summarise the log",
+		"summarise the log\n\n" + differentApproach,
+	}, be.messages)
+	a.Len(be.lastInbound.Attachments, 1)
+}
+
+func TestHandleInbound_RetryWithoutPrompt(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{id: "b"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/retry", Reply: out}))
+
+	// then
+	a.Empty(be.messages)
+	a.Equal([]string{"There is no message to retry yet."}, out.posted)
+}
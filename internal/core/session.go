@@ -3,103 +3,481 @@ package core
 import (
 	"context"
 	"log/slog"
+	"os"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-// FlushFunc is invoked against the outgoing backend just before it is
-// closed during NewSession, so the agent can persist any unsaved memory
-// state. Errors and panics are swallowed; flushes never block the reset.
+// FlushFunc is invoked against an outgoing backend just before it is
+// closed (NewSession or eviction), so the agent can persist any unsaved
+// memory state. Errors and panics are swallowed; flushes never block the reset.
 type FlushFunc func(ctx context.Context, current Backend)
 
-// SessionManager manages the single active backend session
+// DefaultMaxSessions caps how many idle sessions are kept alive. Discord
+// opens a new thread (and so a new key) per mention, so without a cap the
+// map would grow for the lifetime of the process.
+const DefaultMaxSessions = 16
+
+// session is one keyed backend. mu is held for reading by every in-flight
+// turn and for writing while the backend is flushed and closed, so a reset
+// waits for that session's turns to drain without touching other sessions.
+type session struct {
+	mu       sync.RWMutex
+	backend  Backend
+	settings Settings
+	lastUsed time.Time
+	// scratch is a throwaway work directory removed with the session.
+	scratch string
+	// workDir is what the backend was created with; "" is the default.
+	workDir string
+	// name and tags are set with /rename-session and /tag-session.
+	name string
+	tags []string
+	// readOnlyLocked is set for sessions in a read-only workspace, whose
+	// ReadOnly setting /readonly off may not lift.
+	readOnlyLocked bool
+	// suspect is set when a panic hit a turn or command on the session;
+	// its backend may be stuck mid-turn, so the next turn replaces it.
+	suspect atomic.Bool
+}
+
+// pendingSession is a backend Acquire is creating; done is closed once
+// it is in the map or err is set.
+type pendingSession struct {
+	done chan struct{}
+	err  error
+}
+
+// SessionManager owns one backend per SessionKey.
 type SessionManager struct {
-	mu      sync.RWMutex
-	current Backend
-	factory BackendFactory
-	flush   FlushFunc
+	mu          sync.Mutex
+	sessions    map[SessionKey]*session
+	pending     map[SessionKey]*pendingSession
+	factory     BackendFactory
+	flush       FlushFunc
+	maxSessions int
 }
 
 // NewSessionManager creates a session manager. If flush is non-nil it runs
-// against the outgoing backend before it is closed on each NewSession.
+// against each outgoing backend before it is closed.
 func NewSessionManager(factory BackendFactory, flush FlushFunc) *SessionManager {
-	return &SessionManager{factory: factory, flush: flush}
+	return &SessionManager{
+		sessions:    make(map[SessionKey]*session),
+		pending:     make(map[SessionKey]*pendingSession),
+		factory:     factory,
+		flush:       flush,
+		maxSessions: DefaultMaxSessions,
+	}
+}
+
+// NewSession starts a fresh session for key. The old backend is closed only
+// after the new one is created successfully, so a factory error leaves the
+// current session intact. Turns already running against the old backend
+// finish before it is flushed and closed.
+func (m *SessionManager) NewSession(key SessionKey, workDir string, caps Capabilities) error {
+	return m.newSession(key, &session{workDir: workDir}, caps, false)
 }
 
-// NewSession starts a fresh session. The old backend is closed only after
-// the new one is created successfully, so a factory error leaves the current
-// session intact.
-func (m *SessionManager) NewSession(workDir string, caps Capabilities) error {
+// NewScratchSession is NewSession in the throwaway directory dir, which is
+// removed once the session is retired. Until then ScratchDir reports it.
+func (m *SessionManager) NewScratchSession(key SessionKey, dir string, caps Capabilities) error {
+	return m.newSession(key, &session{workDir: dir, scratch: dir}, caps, false)
+}
+
+// NewWorkspaceSession is NewSession in a workspace whose read-only policy
+// replaces the session's ReadOnly setting. It is set before the session
+// becomes visible, so no turn runs with the old setting, and a read-only
+// workspace's sessions can't leave read-only mode.
+func (m *SessionManager) NewWorkspaceSession(key SessionKey, workDir string, readOnly bool, caps Capabilities) error {
+	return m.newSession(key, &session{workDir: workDir, readOnlyLocked: readOnly}, caps, true)
+}
+
+// ReadOnlyLocked reports whether key's session is in a read-only workspace.
+func (m *SessionManager) ReadOnlyLocked(key SessionKey) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	return ok && s.readOnlyLocked
+}
 
-	backend, err := m.factory.Create(workDir, caps)
+// newSession fills in fresh, which carries the work and scratch directories,
+// and swaps it in for key's current session. With workspace set, the
+// workspace's read-only policy replaces the carried-over ReadOnly setting.
+func (m *SessionManager) newSession(key SessionKey, fresh *session, caps Capabilities, workspace bool) error {
+	backend, err := m.factory.Create(fresh.workDir, caps)
 	if err != nil {
 		return errors.Wrap(err, "creating new session")
 	}
+	fresh.backend = backend
+	fresh.lastUsed = time.Now()
 
-	if m.current != nil {
-		m.runFlush(m.current)
-		m.current.Close()
+	m.mu.Lock()
+	old := m.sessions[key]
+	if old != nil {
+		fresh.settings = old.settings
+		// Linked chats move to the new session together.
+		for k, s := range m.sessions {
+			if s == old {
+				m.sessions[k] = fresh
+			}
+		}
+	}
+	if workspace {
+		fresh.settings.ReadOnly = fresh.readOnlyLocked
 	}
+	m.sessions[key] = fresh
+	evicted := m.evictLocked(key)
+	m.mu.Unlock()
 
-	m.current = backend
+	if old != nil {
+		evicted = append(evicted, old)
+	}
+	for _, s := range evicted {
+		m.retire(s)
+	}
 	return nil
 }
 
-func (m *SessionManager) runFlush(current Backend) {
-	if m.flush == nil {
-		return
+// Acquire returns the backend for key, creating it with caps if none exists,
+// and holds that session's read lock until release is called. Callers must
+// call release exactly once. The backend is created outside m.mu, since
+// that can be slow (git for session branches); concurrent Acquires of the
+// same key wait for the one creating it.
+func (m *SessionManager) Acquire(key SessionKey, caps Capabilities) (Backend, func(), error) {
+	for {
+		m.mu.Lock()
+		if s, ok := m.sessions[key]; ok {
+			return m.acquireLocked(s, nil)
+		}
+		if p, ok := m.pending[key]; ok {
+			m.mu.Unlock()
+			<-p.done
+			if p.err != nil {
+				return nil, nil, errors.Wrap(p.err, "creating session")
+			}
+			continue
+		}
+		p := &pendingSession{done: make(chan struct{})}
+		m.pending[key] = p
+		m.mu.Unlock()
+
+		backend, err := m.factory.Create("", caps)
+
+		m.mu.Lock()
+		delete(m.pending, key)
+		p.err = err
+		close(p.done)
+		if err != nil {
+			m.mu.Unlock()
+			return nil, nil, errors.Wrap(err, "creating session")
+		}
+		if _, ok := m.sessions[key]; ok {
+			// NewSession or Link gave key a session in the meantime.
+			m.mu.Unlock()
+			backend.Close()
+			continue
+		}
+		s := &session{backend: backend}
+		m.sessions[key] = s
+		return m.acquireLocked(s, m.evictLocked(key))
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			slog.Warn("memory flush panicked", "panic", r)
+}
+
+// acquireLocked read-locks s, releases m.mu and retires evicted. m.mu must
+// be held.
+func (m *SessionManager) acquireLocked(s *session, evicted []*session) (Backend, func(), error) {
+	s.lastUsed = time.Now()
+	// Taken under m.mu so a concurrent NewSession can't retire s between
+	// the lookup and the lock.
+	s.mu.RLock()
+	m.mu.Unlock()
+
+	for _, e := range evicted {
+		m.retire(e)
+	}
+	return s.backend, s.mu.RUnlock, nil
+}
+
+// GetOrCreateSession returns the session for key, creating one if none
+// exists. caps is forwarded to the factory when a new session must be created.
+func (m *SessionManager) GetOrCreateSession(key SessionKey, caps Capabilities) (Backend, error) {
+	backend, release, err := m.Acquire(key, caps)
+	if err != nil {
+		return nil, err
+	}
+	release()
+	return backend, nil
+}
+
+// GetSession returns the session for key or an error if none exists.
+func (m *SessionManager) GetSession(key SessionKey) (Backend, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[key]
+	if !ok {
+		return nil, errors.New("no active session")
+	}
+	return s.backend, nil
+}
+
+// SessionInfo describes one live session key.
+type SessionInfo struct {
+	Key       SessionKey `json:"key"`
+	SessionID string     `json:"sessionID"`
+	LastUsed  time.Time  `json:"lastUsed"`
+	Name      string     `json:"name,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	// WorkDir is empty for sessions in the default directory.
+	WorkDir string `json:"workDir,omitempty"`
+	// Suspect is set after a panic; see MarkSuspect.
+	Suspect bool `json:"suspect,omitempty"`
+}
+
+// Sessions lists every live key, most recently used first. Linked keys are
+// listed separately with the same SessionID.
+func (m *SessionManager) Sessions() []SessionInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]SessionInfo, 0, len(m.sessions))
+	for k, s := range m.sessions {
+		infos = append(infos, s.info(k))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].LastUsed.Equal(infos[j].LastUsed) {
+			return infos[i].LastUsed.After(infos[j].LastUsed)
 		}
-	}()
-	m.flush(context.Background(), current)
+		return infos[i].Key < infos[j].Key
+	})
+	return infos
 }
 
-// GetOrCreateSession returns current session or creates one if none exists.
-// caps is forwarded to the factory when a new session must be created.
-func (m *SessionManager) GetOrCreateSession(caps Capabilities) (Backend, error) {
+// Info describes key's session, reporting false if it has none.
+func (m *SessionManager) Info(key SessionKey) (SessionInfo, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	if !ok {
+		return SessionInfo{}, false
+	}
+	return s.info(key), true
+}
 
-	if m.current != nil {
-		return m.current, nil
+func (s *session) info(key SessionKey) SessionInfo {
+	return SessionInfo{
+		Key:       key,
+		SessionID: s.backend.SessionID(),
+		LastUsed:  s.lastUsed,
+		Name:      s.name,
+		Tags:      slices.Clone(s.tags),
+		WorkDir:   s.workDir,
+		Suspect:   s.suspect.Load(),
 	}
+}
 
-	backend, err := m.factory.Create("", caps)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating session")
+// MarkSuspect flags key's session after a panic. RestartSuspect replaces
+// it before its next turn, and it is closed without a memory flush, since
+// its backend may have been left mid-turn.
+func (m *SessionManager) MarkSuspect(key SessionKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[key]; ok {
+		s.suspect.Store(true)
 	}
+}
 
-	m.current = backend
-	return m.current, nil
+// RestartSuspect replaces key's session with a fresh one in the same work
+// directory if MarkSuspect flagged it. A scratch session starts over in
+// the default directory, since its scratch directory goes with it.
+func (m *SessionManager) RestartSuspect(key SessionKey, caps Capabilities) error {
+	m.mu.Lock()
+	s, ok := m.sessions[key]
+	if !ok || !s.suspect.Load() {
+		m.mu.Unlock()
+		return nil
+	}
+	fresh := &session{workDir: s.workDir, readOnlyLocked: s.readOnlyLocked}
+	if s.scratch != "" {
+		fresh.workDir = ""
+	}
+	m.mu.Unlock()
+
+	slog.Info("restarting suspect session", "key", string(key))
+	return m.newSession(key, fresh, caps, false)
 }
 
-// GetSession returns the current session or error if none
-func (m *SessionManager) GetSession() (Backend, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// Link makes key share target's session, so a conversation started in one
+// chat continues in another. key's own session, if any, is retired unless
+// another key still uses it.
+func (m *SessionManager) Link(key, target SessionKey) error {
+	m.mu.Lock()
+	t, ok := m.sessions[target]
+	if !ok {
+		m.mu.Unlock()
+		return errors.New("no active session")
+	}
+	old := m.sessions[key]
+	m.sessions[key] = t
+	retired := old != nil && old != t && !m.sharedLocked(old, "")
+	m.mu.Unlock()
 
-	if m.current == nil {
-		return nil, errors.New("no active session")
+	if retired {
+		m.retire(old)
 	}
-	return m.current, nil
+	return nil
 }
 
-// Close shuts down the current session
-func (m *SessionManager) Close() error {
+// Unlink detaches key from a session it shares with another key; its next
+// message starts a fresh session. Reports false if key wasn't linked.
+func (m *SessionManager) Unlink(key SessionKey) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	if !ok || !m.sharedLocked(s, key) {
+		return false
+	}
+	delete(m.sessions, key)
+	return true
+}
+
+// sharedLocked reports whether a key other than except maps to s. m.mu
+// must be held.
+func (m *SessionManager) sharedLocked(s *session, except SessionKey) bool {
+	for k, other := range m.sessions {
+		if other == s && k != except {
+			return true
+		}
+	}
+	return false
+}
 
-	if m.current == nil {
+// evictLocked drops least-recently-used sessions over the cap, never
+// touching keep (or chats linked to it) or a session with turns in flight.
+// The returned sessions must be retired after m.mu is released. m.mu must
+// be held.
+func (m *SessionManager) evictLocked(keep SessionKey) []*session {
+	if m.maxSessions <= 0 || len(m.sessions) <= m.maxSessions {
 		return nil
 	}
 
-	err := m.current.Close()
-	m.current = nil
-	return err
+	keys := make([]SessionKey, 0, len(m.sessions))
+	for k, s := range m.sessions {
+		if k != keep && s != m.sessions[keep] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return m.sessions[keys[i]].lastUsed.Before(m.sessions[keys[j]].lastUsed)
+	})
+
+	var evicted []*session
+	for _, k := range keys {
+		if len(m.sessions) <= m.maxSessions {
+			break
+		}
+		s := m.sessions[k]
+		if !s.mu.TryLock() {
+			continue
+		}
+		s.mu.Unlock()
+		// Linked chats share one session; it is retired with its last key.
+		delete(m.sessions, k)
+		if !m.sharedLocked(s, "") {
+			evicted = append(evicted, s)
+		}
+	}
+	return evicted
+}
+
+// ScratchDir returns the scratch directory of key's session, or "".
+func (m *SessionManager) ScratchDir(key SessionKey) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[key]; ok {
+		return s.scratch
+	}
+	return ""
+}
+
+// ExpireScratch retires the session working in scratch directory dir, if
+// it is still live, and reports whether there was one.
+func (m *SessionManager) ExpireScratch(dir string) bool {
+	m.mu.Lock()
+	var expired *session
+	for k, s := range m.sessions {
+		if s.scratch == dir {
+			expired = s
+			delete(m.sessions, k)
+		}
+	}
+	m.mu.Unlock()
+
+	if expired == nil {
+		return false
+	}
+	m.retire(expired)
+	return true
+}
+
+// retire waits for in-flight turns on s, then flushes and closes its backend
+// and removes its scratch directory. Suspect sessions aren't flushed.
+func (m *SessionManager) retire(s *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.suspect.Load() {
+		m.runFlush(s.backend)
+	}
+	s.backend.Close()
+	removeScratch(s)
+}
+
+func removeScratch(s *session) {
+	if s.scratch == "" {
+		return
+	}
+	if err := os.RemoveAll(s.scratch); err != nil {
+		slog.Warn("removing scratch dir", "dir", s.scratch, "error", err)
+	}
+}
+
+func (m *SessionManager) runFlush(current Backend) {
+	if m.flush == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Warn("memory flush panicked", "panic", r)
+		}
+	}()
+	m.flush(context.Background(), current)
+}
+
+// Close shuts down every session without flushing or waiting for
+// in-flight turns, removing scratch directories; it is meant for process
+// shutdown.
+func (m *SessionManager) Close() error {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[SessionKey]*session)
+	m.mu.Unlock()
+
+	var firstErr error
+	closed := make(map[*session]bool)
+	for _, s := range sessions {
+		if closed[s] {
+			continue
+		}
+		closed[s] = true
+		if err := s.backend.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		removeScratch(s)
+	}
+	return firstErr
 }
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -38,12 +40,12 @@ func TestSessionManager_NewSession_CreatesSession(t *testing.T) {
 	mgr := NewSessionManager(factory, nil)
 
 	// when
-	err := mgr.NewSession("", Capabilities{})
+	err := mgr.NewSession("k", "", Capabilities{})
 
 	// then
 	r.NoError(err)
 	a.True(factory.createCalled)
-	sess, _ := mgr.GetSession()
+	sess, _ := mgr.GetSession("k")
 	a.NotNil(sess)
 	a.Equal("session-1", sess.SessionID())
 }
@@ -59,7 +61,7 @@ func TestSessionManager_NewSession_PassesWorkDirToFactory(t *testing.T) {
 	mgr := NewSessionManager(factory, nil)
 
 	// when
-	err := mgr.NewSession("/custom/work/dir", Capabilities{})
+	err := mgr.NewSession("k", "/custom/work/dir", Capabilities{})
 
 	// then
 	r.NoError(err)
@@ -75,20 +77,20 @@ func TestSessionManager_NewSession_ClosesOldAndCreatesNewWithDifferentWorkDir(t
 	secondBackend := &mockBackend{sessionID: "session-2"}
 	factory := &mockBackendFactory{backend: firstBackend}
 	mgr := NewSessionManager(factory, nil)
-	r.NoError(mgr.NewSession("/first/dir", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "/first/dir", Capabilities{}))
 	a.Equal("/first/dir", factory.lastWorkDir)
 
 	// prepare second backend
 	factory.backend = secondBackend
 
 	// when - new session with different dir
-	err := mgr.NewSession("/second/dir", Capabilities{})
+	err := mgr.NewSession("k", "/second/dir", Capabilities{})
 
 	// then
 	r.NoError(err)
 	a.True(firstBackend.closed, "old session should be closed")
 	a.Equal("/second/dir", factory.lastWorkDir, "new session should use new workDir")
-	sess, _ := mgr.GetSession()
+	sess, _ := mgr.GetSession("k")
 	a.Equal("session-2", sess.SessionID())
 }
 
@@ -101,17 +103,17 @@ func TestSessionManager_NewSession_ClosesPreviousSession(t *testing.T) {
 	secondBackend := &mockBackend{sessionID: "session-2"}
 	factory := &mockBackendFactory{backend: firstBackend}
 	mgr := NewSessionManager(factory, nil)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 
 	factory.backend = secondBackend
 
 	// when
-	err := mgr.NewSession("", Capabilities{})
+	err := mgr.NewSession("k", "", Capabilities{})
 
 	// then
 	r.NoError(err)
 	a.True(firstBackend.closed)
-	sess, _ := mgr.GetSession()
+	sess, _ := mgr.GetSession("k")
 	a.Equal("session-2", sess.SessionID())
 }
 
@@ -126,7 +128,7 @@ func TestSessionManager_GetOrCreateSession_CreatesIfNone(t *testing.T) {
 	mgr := NewSessionManager(factory, nil)
 
 	// when
-	sess, err := mgr.GetOrCreateSession(Capabilities{})
+	sess, err := mgr.GetOrCreateSession("k", Capabilities{})
 
 	// then
 	r.NoError(err)
@@ -144,11 +146,11 @@ func TestSessionManager_GetOrCreateSession_ReturnsExisting(t *testing.T) {
 		backend: &mockBackend{sessionID: "existing"},
 	}
 	mgr := NewSessionManager(factory, nil)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 	factory.createCalled = false
 
 	// when
-	sess, err := mgr.GetOrCreateSession(Capabilities{})
+	sess, err := mgr.GetOrCreateSession("k", Capabilities{})
 
 	// then
 	r.NoError(err)
@@ -163,7 +165,7 @@ func TestSessionManager_GetSession_ReturnsNilIfNone(t *testing.T) {
 	mgr := NewSessionManager(&mockBackendFactory{}, nil)
 
 	// when
-	sess, err := mgr.GetSession()
+	sess, err := mgr.GetSession("k")
 
 	// then
 	a.Nil(sess)
@@ -178,7 +180,7 @@ func TestSessionManager_Close_ClosesCurrentSession(t *testing.T) {
 	backend := &mockBackend{sessionID: "to-close"}
 	factory := &mockBackendFactory{backend: backend}
 	mgr := NewSessionManager(factory, nil)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 
 	// when
 	err := mgr.Close()
@@ -186,7 +188,7 @@ func TestSessionManager_Close_ClosesCurrentSession(t *testing.T) {
 	// then
 	r.NoError(err)
 	a.True(backend.closed)
-	sess, _ := mgr.GetSession()
+	sess, _ := mgr.GetSession("k")
 	a.Nil(sess)
 }
 
@@ -209,7 +211,7 @@ func TestSessionManager_NewSession_FactoryError(t *testing.T) {
 	mgr := NewSessionManager(factory, nil)
 
 	// when
-	err := mgr.NewSession("", Capabilities{})
+	err := mgr.NewSession("k", "", Capabilities{})
 
 	// then
 	a.Error(err)
@@ -237,12 +239,12 @@ func TestSessionManager_NewSession_RunsFlushBeforeClose(t *testing.T) {
 	}
 
 	mgr := NewSessionManager(factory, flushFn)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 	factory.backend = secondBackend
 
 	// when
 	// ... a new session is started
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 
 	// then
 	// ... the flush func was called against the previous backend before it was closed
@@ -266,7 +268,7 @@ func TestSessionManager_NewSession_NoFlushOnFirstSession(t *testing.T) {
 
 	// when
 	// ... NewSession is called for the very first time
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 
 	// then
 	// ... the flush func was not called
@@ -282,12 +284,12 @@ func TestSessionManager_NewSession_NilFlushIsNoop(t *testing.T) {
 	second := &mockBackend{sessionID: "second"}
 	factory := &mockBackendFactory{backend: first}
 	mgr := NewSessionManager(factory, nil)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 	factory.backend = second
 
 	// when
 	// ... NewSession is called again with no flush func
-	err := mgr.NewSession("", Capabilities{})
+	err := mgr.NewSession("k", "", Capabilities{})
 
 	// then
 	// ... no panic, behaves like the unflushed manager
@@ -307,18 +309,18 @@ func TestSessionManager_NewSession_FlushPanicDoesNotBlock(t *testing.T) {
 		panic("boom")
 	}
 	mgr := NewSessionManager(factory, flushFn)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 	factory.backend = second
 
 	// when
 	// ... NewSession is called and the flush func panics
-	err := mgr.NewSession("", Capabilities{})
+	err := mgr.NewSession("k", "", Capabilities{})
 
 	// then
 	// ... the panic is recovered and the new session is created
 	r.NoError(err)
 	a.True(first.closed)
-	sess, _ := mgr.GetSession()
+	sess, _ := mgr.GetSession("k")
 	a.Equal("second", sess.SessionID())
 }
 
@@ -331,22 +333,157 @@ func TestSessionManager_NewSession_FactoryError_PreservesCurrent(t *testing.T) {
 	existingBackend := &mockBackend{sessionID: "existing"}
 	factory := &mockBackendFactory{backend: existingBackend}
 	mgr := NewSessionManager(factory, nil)
-	r.NoError(mgr.NewSession("", Capabilities{}))
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
 	factory.err = errors.New("factory boom")
 
 	// when
 	// ... NewSession is called and the factory fails
-	err := mgr.NewSession("", Capabilities{})
+	err := mgr.NewSession("k", "", Capabilities{})
 
 	// then
 	// ... an error is returned, the old backend is still current and NOT closed
 	a.Error(err)
 	a.False(existingBackend.closed, "old backend must not be closed when factory fails")
-	sess, sessErr := mgr.GetSession()
+	sess, sessErr := mgr.GetSession("k")
 	r.NoError(sessErr)
 	a.Equal("existing", sess.SessionID())
 }
 
+func TestSessionManager_NewSession_LeavesOtherKeysAlone(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... sessions for two keys
+	other := &mockBackend{sessionID: "other"}
+	factory := &mockBackendFactory{backend: other}
+	mgr := NewSessionManager(factory, nil)
+	r.NoError(mgr.NewSession("other", "", Capabilities{}))
+	factory.backend = &mockBackend{sessionID: "k-1"}
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
+
+	// when
+	// ... only "k" is reset
+	factory.backend = &mockBackend{sessionID: "k-2"}
+	r.NoError(mgr.NewSession("k", "", Capabilities{}))
+
+	// then
+	// ... the other key still has its original, open backend
+	a.False(other.closed)
+	sess, err := mgr.GetSession("other")
+	r.NoError(err)
+	a.Equal("other", sess.SessionID())
+}
+
+func TestSessionManager_Acquire_EvictsLeastRecentlyUsedIdleSession(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a manager capped at two sessions, "busy" holding a turn in flight
+	busy := &mockBackend{sessionID: "busy"}
+	idle := &mockBackend{sessionID: "idle"}
+	factory := &mockBackendFactory{backend: busy}
+	var flushed []string
+	mgr := NewSessionManager(factory, func(_ context.Context, b Backend) {
+		flushed = append(flushed, b.SessionID())
+	})
+	mgr.maxSessions = 2
+	_, release, err := mgr.Acquire("busy", Capabilities{})
+	r.NoError(err)
+	defer release()
+	factory.backend = idle
+	_, err = mgr.GetOrCreateSession("idle", Capabilities{})
+	r.NoError(err)
+
+	// when
+	// ... a third key arrives
+	factory.backend = &mockBackend{sessionID: "new"}
+	_, err = mgr.GetOrCreateSession("new", Capabilities{})
+	r.NoError(err)
+
+	// then
+	// ... the older busy session is skipped and the idle one is flushed and closed
+	a.False(busy.closed)
+	a.True(idle.closed)
+	a.Equal([]string{"idle"}, flushed)
+	_, err = mgr.GetSession("idle")
+	a.Error(err)
+}
+
+// slowFactory blocks creating workDir "" until release is closed.
+type slowFactory struct {
+	started chan struct{}
+	release chan struct{}
+	created atomic.Int32
+}
+
+func (f *slowFactory) Create(workDir string, _ Capabilities) (Backend, error) {
+	f.created.Add(1)
+	if workDir == "" {
+		f.started <- struct{}{}
+		<-f.release
+	}
+	return &mockBackend{sessionID: "s-" + workDir}, nil
+}
+
+func TestSessionManager_Acquire_CreatesOutsideTheLock(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... one key's backend is slow to create
+	f := &slowFactory{started: make(chan struct{}, 1), release: make(chan struct{})}
+	mgr := NewSessionManager(f, nil)
+	results := make(chan Backend, 2)
+	for range 2 {
+		go func() {
+			b, err := mgr.GetOrCreateSession("slow", Capabilities{})
+			assert.NoError(t, err)
+			results <- b
+		}()
+	}
+	<-f.started
+
+	// when
+	// ... another key starts a session meanwhile
+	err := mgr.NewSession("fast", "/srv/fast", Capabilities{})
+	close(f.release)
+
+	// then
+	// ... it doesn't wait, and both waiters on the slow key share one backend
+	r.NoError(err)
+	first, second := <-results, <-results
+	a.Same(first, second)
+	a.Equal(int32(2), f.created.Load())
+}
+
+func TestSessionManager_Sessions_MostRecentFirst(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... two sessions, the second newer, and a key linked to the first
+	factory := &mockBackendFactory{backend: &mockBackend{sessionID: "session-1"}}
+	mgr := NewSessionManager(factory, nil)
+	r.NoError(mgr.NewSession("a", "", Capabilities{}))
+	time.Sleep(time.Millisecond)
+	factory.backend = &mockBackend{sessionID: "session-2"}
+	r.NoError(mgr.NewSession("b", "", Capabilities{}))
+	r.NoError(mgr.Link("c", "a"))
+
+	// when
+	infos := mgr.Sessions()
+
+	// then
+	r.Len(infos, 3)
+	a.Equal(SessionKey("b"), infos[0].Key)
+	a.Equal("session-2", infos[0].SessionID)
+	a.Equal(SessionKey("a"), infos[1].Key)
+	a.Equal(SessionKey("c"), infos[2].Key)
+	a.Equal("session-1", infos[2].SessionID)
+}
+
 type mockBackendFactory struct {
 	backend      *mockBackend
 	err          error
@@ -0,0 +1,81 @@
+package core
+
+// Verbosity controls how much tool activity a session mirrors into its
+// updates channel.
+type Verbosity string
+
+const (
+	// VerbosityQuiet only surfaces what the model chooses to send_update.
+	VerbosityQuiet Verbosity = "quiet"
+	// VerbosityTools additionally posts one line per tool call.
+	VerbosityTools Verbosity = "tools"
+	// VerbosityThinking also posts a summary of each extended thinking
+	// block.
+	VerbosityThinking Verbosity = "thinking"
+)
+
+// Settings are per-session preferences changed with slash commands. They
+// belong to the SessionKey, so they survive NewSession but are dropped
+// when the session is evicted.
+type Settings struct {
+	Verbosity Verbosity
+	// ReadOnly swaps the bot's permission checker for its read-only one.
+	ReadOnly bool
+	// Speak sends each final response as audio too.
+	Speak bool
+	// Generation tunes the model calls, set with /settings.
+	Generation Generation
+	// Env holds the variables set with /env for the session's tool
+	// calls. It is replaced rather than changed in place, since a running
+	// turn may hold the old map.
+	Env map[string]string
+}
+
+// Generation overrides a backend's generation parameters. Zero fields keep
+// the defaults.
+type Generation struct {
+	MaxTokens   int
+	Temperature *float64
+	// ThinkingBudget > 0 sets the extended thinking budget; < 0 turns
+	// thinking off.
+	ThinkingBudget int
+}
+
+// MirrorTools reports whether tool calls should be echoed as updates.
+func (s Settings) MirrorTools() bool {
+	return s.Verbosity == VerbosityTools || s.Verbosity == VerbosityThinking
+}
+
+// MirrorThinking reports whether extended thinking should be summarised as
+// updates.
+func (s Settings) MirrorThinking() bool {
+	return s.Verbosity == VerbosityThinking
+}
+
+// Settings returns the settings for key, or the zero value if it has none.
+func (m *SessionManager) Settings(key SessionKey) Settings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.sessions[key]; ok {
+		return s.settings
+	}
+	return Settings{}
+}
+
+// UpdateSettings applies fn to the settings for key, creating the session
+// with caps if it doesn't exist yet.
+func (m *SessionManager) UpdateSettings(key SessionKey, caps Capabilities, fn func(*Settings)) error {
+	_, release, err := m.Acquire(key, caps)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[key]; ok {
+		fn(&s.settings)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/skills"
+	"github.com/pkg/errors"
+)
+
+const skillUsage = "Use /skill new <name> [description]. With a description, I draft the instructions too."
+
+const draftSkillPrompt = "Draft the instructions for a new skill named %q. Its purpose: %s\n" +
+	"Reply with only the markdown body of its SKILL.md, without frontmatter, using the sections " +
+	"\"# Title\", \"## When to Use\", \"## Usage\" and \"## Steps\". The skill directory also holds " +
+	"scripts/example.sh and references/notes.md, which you may mention. Do not call any tools."
+
+func (b *Bot) cmdSkill(ctx context.Context, in Inbound, args string) (string, error) {
+	sub, rest, _ := strings.Cut(args, " ")
+	if strings.ToLower(sub) != "new" {
+		return b.tr(in, skillUsage), nil
+	}
+	if b.scaffolder == nil {
+		return b.tr(in, "Creating skills is not available."), nil
+	}
+	name, description, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	description = strings.TrimSpace(description)
+	if name == "" {
+		return b.tr(in, skillUsage), nil
+	}
+	if !skills.ValidName(name) {
+		return b.tr(in, "Invalid skill name %q: use lowercase letters, digits and single hyphens.", name), nil
+	}
+
+	var instructions string
+	if description != "" {
+		draft, err := b.draftSkill(ctx, in, name, description)
+		if err != nil {
+			slog.Warn("drafting skill failed, using template", "name", name, "error", err)
+		}
+		instructions = draft
+	}
+
+	path, err := b.scaffolder.Scaffold(name, description, instructions)
+	if err != nil {
+		return b.tr(in, "Could not create skill %s: %s", name, err), nil
+	}
+	if instructions != "" {
+		return b.tr(in, "Created skill %s at %s with drafted instructions, plus scripts/example.sh and references/notes.md. Review it before relying on it; new sessions will list it.", name, path), nil
+	}
+	return b.tr(in, "Created skill %s at %s from the template, plus scripts/example.sh and references/notes.md. Fill in its description and instructions; new sessions will list it.", name, path), nil
+}
+
+// draftSkill asks the session's model for the instructions body.
+func (b *Bot) draftSkill(ctx context.Context, in Inbound, name, description string) (string, error) {
+	t := b.tenantFor(in)
+	release := b.acquireSlot(in.SessionKey)
+	defer release()
+
+	backend, unlock, err := t.sessions.Acquire(in.SessionKey, in.Capabilities)
+	if err != nil {
+		return "", errors.Wrap(err, "getting session")
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, b.converseTimeout)
+	defer cancel()
+	draft, err := backend.Converse(ctx, Inbound{
+		SessionKey:   in.SessionKey,
+		Text:         fmt.Sprintf(draftSkillPrompt, name, description),
+		Capabilities: in.Capabilities,
+	}, in.Reply, t.permsFor(in.SessionKey, t.sessions.Settings(in.SessionKey)))
+	if err != nil {
+		return "", errors.Wrap(err, "converse")
+	}
+	draft = strings.TrimSpace(draft)
+	if draft == "" {
+		return "", errors.New("empty draft")
+	}
+	return draft + "\n", nil
+}
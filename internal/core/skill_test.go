@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubScaffolder struct {
+	name, description, instructions string
+	calls                           int
+}
+
+func (s *stubScaffolder) Scaffold(name, description, instructions string) (string, error) {
+	s.calls++
+	s.name, s.description, s.instructions = name, description, instructions
+	return "/skills/" + name + "/SKILL.md", nil
+}
+
+func TestHandleInbound_SkillNewDraftsFromDescription(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{id: "b1", converseR: "# Deploy\n\nRun make deploy."}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	sc := &stubScaffolder{}
+	bot.SetSkillScaffolder(sc)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/skill new deploy Ship the app to prod", Reply: out}))
+
+	// then
+	// ... the model drafted the body and the skill was scaffolded with it
+	r.Len(be.messages, 1)
+	a.Contains(be.messages[0], `"deploy"`)
+	a.Contains(be.messages[0], "Ship the app to prod")
+	a.Equal("deploy", sc.name)
+	a.Equal("Ship the app to prod", sc.description)
+	a.Equal("# Deploy\n\nRun make deploy.\n", sc.instructions)
+	r.Len(out.posted, 1)
+	a.Contains(out.posted[0], "Created skill deploy at /skills/deploy/SKILL.md with drafted instructions")
+}
+
+func TestHandleInbound_SkillNewWithoutDescriptionUsesTemplate(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{id: "b1"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	sc := &stubScaffolder{}
+	bot.SetSkillScaffolder(sc)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/skill new deploy", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/skill new Bad_Name", Reply: out}))
+
+	// then
+	// ... no model turn, and the bad name never reaches the scaffolder
+	a.Empty(be.messages)
+	a.Equal(1, sc.calls)
+	a.Empty(sc.instructions)
+	r.Len(out.posted, 2)
+	a.Contains(out.posted[0], "from the template")
+	a.Contains(out.posted[1], "Invalid skill name")
+}
+
+func TestHandleInbound_SkillUnavailableWithoutScaffolder(t *testing.T) {
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	require.NoError(t, bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/skill new deploy", Reply: out}))
+
+	// then
+	assert.Equal(t, []string{"Creating skills is not available."}, out.posted)
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Releases reports the running build and any newer release for /status
+// (version.Checker).
+type Releases interface {
+	Current() string
+	Newer() (latest string, ok bool)
+}
+
+// SetReleases lets /status name the build and point out a newer release.
+// Without one it reports the build as unknown.
+func (b *Bot) SetReleases(r Releases) {
+	b.releases = r
+}
+
+// cmdStatus reports the build, uptime, how many sessions are live and
+// whether the channel is paused.
+func (b *Bot) cmdStatus(_ context.Context, in Inbound, _ string) (string, error) {
+	build := b.tr(in, "unknown")
+	if b.releases != nil {
+		build = b.releases.Current()
+	}
+	up := time.Since(b.started).Truncate(time.Second)
+	reply := b.tr(in, "Switchboard %s, up %s, %d live sessions.", build, up, len(b.tenantFor(in).sessions.Sessions()))
+	if paused := b.pauseStatus(in); paused != "" {
+		reply += "\n" + paused
+	}
+	if b.releases == nil {
+		return reply, nil
+	}
+	if latest, ok := b.releases.Newer(); ok {
+		reply += "\n" + b.tr(in, "Release %s is available.", latest)
+	}
+	return reply, nil
+}
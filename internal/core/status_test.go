@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReleases struct{ latest string }
+
+func (s stubReleases) Current() string       { return "v1.2.3 (commit abc1234)" }
+func (s stubReleases) Newer() (string, bool) { return s.latest, s.latest != "" }
+
+func TestStatus(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{id: "b1"} }}, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/status", Reply: out}))
+	bot.SetReleases(stubReleases{latest: "v1.4.0"})
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", Text: "/status", Reply: out}))
+
+	// then
+	r.Len(out.posted, 2)
+	a.Regexp(`^Switchboard unknown, up \d+s, 0 live sessions\.$`, out.posted[0])
+	a.Regexp(`^Switchboard v1\.2\.3 \(commit abc1234\), up \d+s, 0 live sessions\.\nRelease v1\.4\.0 is available\.$`, out.posted[1])
+}
@@ -0,0 +1,70 @@
+package core
+
+import "sync"
+
+// Tenant is a team sharing the deployment with its own servers, API key,
+// allowed directories and workspaces. Its chats get sessions from its own
+// SessionManager, so they never reach another tenant's backends.
+type Tenant struct {
+	Name string
+	// GuildIDs are the servers whose chats belong to the tenant, e.g.
+	// "discord:123".
+	GuildIDs []string
+	// Sessions should come from a BackendFactory that bills the tenant's
+	// API key and records usage under Name.
+	Sessions *SessionManager
+	Perms    PermissionChecker
+	// ReadOnlyPerms enables /readonly; without it the command refuses.
+	ReadOnlyPerms PermissionChecker
+	Workspaces    []Workspace
+}
+
+// tenant is what the bot keeps per Tenant. Bot.base is the unnamed one
+// built from NewBot's arguments.
+type tenant struct {
+	name          string
+	sessions      *SessionManager
+	perms         PermissionChecker
+	readOnlyPerms PermissionChecker
+	wsMu          sync.Mutex
+	workspaces    []Workspace // protected by wsMu
+}
+
+// AddTenant routes chats in t's servers to t's sessions, permissions and
+// workspaces instead of the bot's own. Call before the first inbound is
+// handled.
+func (b *Bot) AddTenant(t Tenant) {
+	tt := &tenant{
+		name:          t.Name,
+		sessions:      t.Sessions,
+		perms:         t.Perms,
+		readOnlyPerms: t.ReadOnlyPerms,
+		workspaces:    t.Workspaces,
+	}
+	for _, id := range t.GuildIDs {
+		b.tenants[id] = tt
+	}
+}
+
+// tenantFor returns the tenant owning in's server, or the base one.
+func (b *Bot) tenantFor(in Inbound) *tenant {
+	if t, ok := b.tenants[in.GuildID]; ok && in.GuildID != "" {
+		return t
+	}
+	return b.base
+}
+
+// permsFor picks the permission checker for a session's settings, scoped
+// to its scratch directory if it has one.
+func (t *tenant) permsFor(key SessionKey, s Settings) PermissionChecker {
+	pc := t.perms
+	if s.ReadOnly && t.readOnlyPerms != nil {
+		pc = t.readOnlyPerms
+	}
+	if dir := t.sessions.ScratchDir(key); dir != "" {
+		if scoper, ok := pc.(DirScoper); ok {
+			pc = scoper.WithDirs(dir)
+		}
+	}
+	return pc
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_TenantsKeepTheirOwnSessions(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a base bot and a tenant owning one server
+	baseBackend := &permsBackend{}
+	baseMgr := NewSessionManager(&stubFactory{next: func() Backend { return baseBackend }}, nil)
+	bot := NewBot(baseMgr, namedPerms("base"))
+	bot.SetWorkspaces([]Workspace{{Label: "ops", Path: "/srv/ops", Default: true}})
+	teamFactory := &workDirFactory{}
+	teamMgr := NewSessionManager(teamFactory, nil)
+	bot.AddTenant(Tenant{
+		Name:       "team-a",
+		GuildIDs:   []string{"discord:g1"},
+		Sessions:   teamMgr,
+		Perms:      namedPerms("team-a"),
+		Workspaces: []Workspace{{Label: "api", Path: "/srv/team-a/api", Default: true}},
+	})
+	out := &stubResponder{}
+	team := Inbound{SessionKey: "discord:thread:1", GuildID: "discord:g1", Reply: out}
+	other := Inbound{SessionKey: "discord:thread:2", GuildID: "discord:g2", Reply: out}
+
+	// when
+	team.Text = "/new-session ops"
+	r.NoError(bot.HandleInbound(team))
+	team.Text = "/new-session"
+	r.NoError(bot.HandleInbound(team))
+	team.Text = "/readonly on"
+	r.NoError(bot.HandleInbound(team))
+	other.Text = "hello"
+	r.NoError(bot.HandleInbound(other))
+
+	// then
+	// ... the tenant only sees its own workspaces and manager, and other
+	// servers stay on the base one
+	a.Equal([]string{"/srv/team-a/api"}, teamFactory.workDirs)
+	a.Equal([]string{
+		`Unknown workspace "ops". Workspaces: api (default).`,
+		"Started a new session in api (/srv/team-a/api).",
+		"Read-only mode is not available.",
+	}, out.posted)
+	_, ok := baseMgr.Info(team.SessionKey)
+	a.False(ok)
+	_, ok = teamMgr.Info(other.SessionKey)
+	a.False(ok)
+	a.Equal([]PermissionChecker{namedPerms("base")}, baseBackend.perms)
+}
+
+func TestHandleInbound_TenantPermissions(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &permsBackend{}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return &stubBackend{} }}, nil), namedPerms("base"))
+	bot.AddTenant(Tenant{
+		Name:          "team-a",
+		GuildIDs:      []string{"discord:g1"},
+		Sessions:      NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil),
+		Perms:         namedPerms("team-a"),
+		ReadOnlyPerms: namedPerms("team-a-readonly"),
+	})
+	send := func(text string) {
+		r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", GuildID: "discord:g1", Text: text}))
+	}
+
+	// when
+	send("fix it")
+	send("/readonly on")
+	send("look around")
+
+	// then
+	a.Equal([]PermissionChecker{namedPerms("team-a"), namedPerms("team-a-readonly")}, be.perms)
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// timestampLayout is how times are shown in replies, in the reader's zone.
+const timestampLayout = "2006-01-02 15:04 MST"
+
+// timezones holds the zones users picked with /timezone.
+type timezones struct {
+	mu    sync.Mutex
+	users map[string]*time.Location
+}
+
+// location is the zone times are shown in for in's sender; UTC unless they
+// picked one.
+func (b *Bot) location(in Inbound) *time.Location {
+	b.zones.mu.Lock()
+	defer b.zones.mu.Unlock()
+	if loc, ok := b.zones.users[in.UserID]; ok && in.UserID != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// formatTime renders t for in's sender.
+func (b *Bot) formatTime(in Inbound, t time.Time) string {
+	return t.In(b.location(in)).Format(timestampLayout)
+}
+
+// cmdTimezone shows or sets the sender's time zone, as an IANA name such as
+// Europe/Berlin.
+func (b *Bot) cmdTimezone(_ context.Context, in Inbound, args string) (string, error) {
+	if args == "" {
+		return b.tr(in, "Your time zone is %s. Use /timezone <zone>, e.g. /timezone Europe/Berlin.", b.location(in)), nil
+	}
+	if in.UserID == "" {
+		return b.tr(in, "This chat cannot tell users apart, so times stay in UTC."), nil
+	}
+	loc, err := time.LoadLocation(args)
+	if err != nil || args == "Local" {
+		return b.tr(in, "Unknown time zone %q. Use an IANA name such as Europe/Berlin or America/New_York.", args), nil
+	}
+	b.zones.mu.Lock()
+	b.zones.users[in.UserID] = loc
+	b.zones.mu.Unlock()
+	return b.tr(in, "Time zone set to %s. It is now %s.", loc, b.formatTime(in, time.Now())), nil
+}
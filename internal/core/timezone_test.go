@@ -0,0 +1,61 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInbound_TimezoneFormatsShareExpiry(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... alice is in Berlin, bob never picked a zone
+	be := &transcriptBackend{entries: []TranscriptEntry{{Role: "user", Text: "hi"}}}
+	f := &stubFactory{next: func() Backend { return be }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.SetSharer(&stubSharer{})
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "hi"}))
+	out := &stubResponder{}
+	alice := Inbound{SessionKey: "k1", UserID: "u:alice", Reply: out}
+	bob := Inbound{SessionKey: "k1", UserID: "u:bob", Reply: out}
+	alice.Text = "/timezone Europe/Berlin"
+	r.NoError(bot.HandleInbound(alice))
+
+	// when
+	alice.Text = "/share"
+	r.NoError(bot.HandleInbound(alice))
+	bob.Text = "/share"
+	r.NoError(bot.HandleInbound(bob))
+
+	// then
+	r.Len(out.posted, 3)
+	a.True(strings.HasPrefix(out.posted[0], "Time zone set to Europe/Berlin. It is now "), out.posted[0])
+	a.Equal("Transcript shared until 2026-01-08 10:30 CET: https://bot.example/share/abc", out.posted[1])
+	a.Equal("Transcript shared until 2026-01-08 09:30 UTC: https://bot.example/share/abc", out.posted[2])
+}
+
+func TestHandleInbound_TimezoneRejects(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &stubFactory{next: func() Backend { return &stubBackend{id: "b"} }}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "u:1", Text: "/timezone Mars/Olympus", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/timezone Europe/Berlin", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", UserID: "u:1", Text: "/timezone", Reply: out}))
+
+	// then
+	a.Equal([]string{
+		`Unknown time zone "Mars/Olympus". Use an IANA name such as Europe/Berlin or America/New_York.`,
+		"This chat cannot tell users apart, so times stay in UTC.",
+		"Your time zone is UTC. Use /timezone <zone>, e.g. /timezone Europe/Berlin.",
+	}, out.posted)
+}
@@ -45,16 +45,54 @@ func SendUpdateTool() ToolDef {
 	}
 }
 
+// WriteArtifactTool is registered for channels that can send files.
+func WriteArtifactTool() ToolDef {
+	return ToolDef{
+		Name:        "write_artifact",
+		Description: "Deliver a named file (patch, script, config, ...) to the user as an attachment instead of pasting a long code block. Set save to also write it under the session working directory.",
+		InputSchema: objSchema(map[string]any{
+			"name":    strProp("File name including extension, e.g. fix.patch. May contain subdirectories when saving."),
+			"content": strProp("Full file content"),
+			"save": map[string]any{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Also write the file under the session working directory",
+			},
+		}, "name", "content"),
+	}
+}
+
 // FileTools returns tool definitions for file/shell operations (API mode only)
 func FileTools() []ToolDef {
 	return []ToolDef{
 		{
 			Name:        "Read",
-			Description: "Read file contents",
+			Description: "Read file contents. Images are returned for viewing; other binary files are summarised. Long text files are cut at a line boundary; use offset and limit to page through them.",
 			InputSchema: objSchema(map[string]any{
 				"file_path": strProp("Absolute path to the file"),
+				"offset":    map[string]any{"type": "integer", "minimum": 1, "description": "Line to start reading from, 1-based"},
+				"limit":     map[string]any{"type": "integer", "minimum": 1, "description": "Maximum number of lines to read"},
+				"hex": map[string]any{
+					"type":        "boolean",
+					"default":     false,
+					"description": "For binary files, include a hexdump of the first bytes",
+				},
 			}, "file_path"),
 		},
+		{
+			Name:        "LS",
+			Description: "List a directory with sizes and modification times. Entries git ignores are left out. Use instead of Bash ls.",
+			InputSchema: objSchema(map[string]any{
+				"path": strProp("Absolute path to the directory; defaults to the working directory"),
+			}),
+		},
+		{
+			Name:        "Patch",
+			Description: "Apply a unified diff (as from diff -u or git diff) to files under the working directory. Cheaper than rewriting whole files. Context lines must match exactly; if any hunk fails, no file is changed.",
+			InputSchema: objSchema(map[string]any{
+				"diff": strProp("Unified diff with ---/+++ headers; paths relative to the working directory, a/ and b/ prefixes allowed, /dev/null to create or delete"),
+			}, "diff"),
+		},
 		{
 			Name:        "Bash",
 			Description: "Execute a bash command",
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// minCachedPromptLen keeps short prompts such as "continue" or "try again"
+// out of the turn cache: their answer depends on the conversation, not on
+// the text.
+const minCachedPromptLen = 40
+
+// turnCache remembers recent answers per chat scope, so the same prompt
+// asked again within ttl (two people pasting one error) is answered from
+// memory instead of running another turn.
+type turnCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cachedTurn
+}
+
+type cachedTurn struct {
+	response string
+	at       time.Time
+	// session asked the prompt; asking it again there wants a fresh
+	// answer, since the conversation has moved on.
+	session SessionKey
+}
+
+// SetTurnCache answers a prompt repeated in the same channel within ttl
+// with the earlier answer. ttl <= 0 disables it. Call before the first
+// inbound is handled.
+func (b *Bot) SetTurnCache(ttl time.Duration) {
+	b.turns = turnCache{ttl: ttl, now: time.Now, entries: make(map[string]cachedTurn)}
+}
+
+// turnCacheKey scopes a prompt to its channel (every Discord thread of a
+// channel shares one), or to the session where the channel is unknown. ok
+// is false for prompts that shouldn't be cached.
+func turnCacheKey(in Inbound) (string, bool) {
+	if len(in.Attachments) > 0 {
+		return "", false
+	}
+	prompt := strings.ToLower(strings.Join(strings.Fields(in.Text), " "))
+	if utf8.RuneCountInString(prompt) < minCachedPromptLen {
+		return "", false
+	}
+	scope := in.ChannelID
+	if scope == "" {
+		scope = string(in.SessionKey)
+	}
+	return in.GuildID + "\x00" + scope + "\x00" + prompt, true
+}
+
+// get returns the answer cached for in and how old it is.
+func (c *turnCache) get(in Inbound) (string, time.Duration, bool) {
+	if c.ttl <= 0 {
+		return "", 0, false
+	}
+	key, ok := turnCacheKey(in)
+	if !ok {
+		return "", 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	age := c.now().Sub(e.at)
+	if !ok || age > c.ttl || e.session == in.SessionKey {
+		return "", 0, false
+	}
+	return e.response, age, true
+}
+
+// put caches response for in, dropping expired entries on the way.
+func (c *turnCache) put(in Inbound, response string) {
+	if c.ttl <= 0 || response == "" {
+		return
+	}
+	key, ok := turnCacheKey(in)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, e := range c.entries {
+		if now.Sub(e.at) > c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedTurn{response: response, at: now, session: in.SessionKey}
+}
+
+// serveCached posts the cached answer to in, if there is one, with a note
+// saying how old it is.
+func (b *Bot) serveCached(in Inbound) (bool, error) {
+	response, age, ok := b.turns.get(in)
+	if !ok || in.Reply == nil {
+		return false, nil
+	}
+	minutes := int(math.Max(1, math.Round(age.Minutes())))
+	note := b.tr(in, "(Cached from %d min ago. Rephrase the question to ask again.)", minutes)
+	return true, in.Reply.PostResponse(response + "\n\n" + note)
+}
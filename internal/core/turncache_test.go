@@ -0,0 +1,92 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pastedError = "panic: runtime error: invalid memory address or nil pointer dereference"
+
+func TestHandleInbound_TurnCacheServesRepeatInChannel(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a five minute cache and a first answer two minutes ago in another thread
+	be := &stubBackend{converseR: "check the nil map"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.SetTurnCache(5 * time.Minute)
+	now := time.Now()
+	bot.turns.now = func() time.Time { return now }
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "discord:thread:1", ChannelID: "discord:c", Text: pastedError, Reply: &stubResponder{}}))
+	now = now.Add(2 * time.Minute)
+	out := &stubResponder{}
+
+	// when
+	// ... the same prompt, with different case and spacing, arrives in a second thread
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "discord:thread:2", ChannelID: "discord:c", Text: "  PANIC: runtime error:  invalid memory address or nil pointer dereference\n", Reply: out}))
+
+	// then
+	// ... the cached answer is posted with its age and no turn runs
+	a.Len(be.messages, 1)
+	r.Len(out.posted, 1)
+	a.Contains(out.posted[0], "check the nil map")
+	a.Contains(out.posted[0], "Cached from 2 min ago")
+}
+
+func TestHandleInbound_TurnCacheMisses(t *testing.T) {
+	tests := []struct {
+		name   string
+		second Inbound
+		after  time.Duration
+	}{
+		{"expired", Inbound{SessionKey: "s2", ChannelID: "c", Text: pastedError}, 6 * time.Minute},
+		{"same session", Inbound{SessionKey: "s1", ChannelID: "c", Text: pastedError}, 0},
+		{"other channel", Inbound{SessionKey: "s2", ChannelID: "other", Text: pastedError}, 0},
+		{"attachment", Inbound{SessionKey: "s2", ChannelID: "c", Text: pastedError, Attachments: []AttachmentRef{{Path: "/tmp/log.txt"}}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := require.New(t)
+
+			// given
+			be := &stubBackend{converseR: "answer"}
+			bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+			bot.SetTurnCache(5 * time.Minute)
+			now := time.Now()
+			bot.turns.now = func() time.Time { return now }
+			r.NoError(bot.HandleInbound(Inbound{SessionKey: "s1", ChannelID: "c", Text: pastedError, Reply: &stubResponder{}}))
+			now = now.Add(tt.after)
+
+			// when
+			tt.second.Reply = &stubResponder{}
+			r.NoError(bot.HandleInbound(tt.second))
+
+			// then
+			a.Len(be.messages, 2)
+		})
+	}
+}
+
+func TestHandleInbound_TurnCacheSkipsShortPrompts(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	be := &stubBackend{converseR: "ok"}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	bot.SetTurnCache(5 * time.Minute)
+
+	// when
+	// ... "continue" is sent twice
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c", Text: "continue", Reply: &stubResponder{}}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k", ChannelID: "c", Text: "continue", Reply: &stubResponder{}}))
+
+	// then
+	// ... both run a turn
+	a.Equal([]string{"continue", "continue"}, be.messages)
+}
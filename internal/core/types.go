@@ -15,4 +15,10 @@ type ToolInput struct {
 	Message   string            `json:"message,omitempty"`
 	Query     string            `json:"query,omitempty"`
 	Name      string            `json:"name,omitempty"`
+	Content   string            `json:"content,omitempty"`
+	Save      bool              `json:"save,omitempty"`
+	Hex       bool              `json:"hex,omitempty"`
+	Offset    int               `json:"offset,omitempty"`
+	Limit     int               `json:"limit,omitempty"`
+	Diff      string            `json:"diff,omitempty"`
 }
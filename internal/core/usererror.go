@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorKind is the category a failed turn is reported to users under.
+type ErrorKind int
+
+const (
+	ErrInternal ErrorKind = iota
+	ErrUnavailable
+	ErrPermissionDenied
+	ErrTimeout
+	ErrRateLimited
+	// ErrCrashed is a panic while handling a message. The session it hit
+	// starts over on its next turn (see SessionManager.MarkSuspect).
+	ErrCrashed
+)
+
+var errorKindText = map[ErrorKind]string{
+	ErrInternal:         "Something went wrong while handling that message.",
+	ErrUnavailable:      "The model backend is unavailable right now. Try again in a few minutes.",
+	ErrPermissionDenied: "That isn't permitted.",
+	ErrTimeout:          "That took too long and was stopped.",
+	ErrRateLimited:      "The model is rate limited right now. Wait a minute and try again.",
+	ErrCrashed:          "Something went wrong while handling that message, so this conversation will start over.",
+}
+
+type kindError struct {
+	kind ErrorKind
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+func (e *kindError) Unwrap() error { return e.err }
+
+// MarkError tags err with the category users should see if it ends a
+// turn. Wrapping it further keeps the tag.
+func MarkError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// ErrorKindOf returns the category of err: its MarkError tag, else one
+// inferred from context deadlines and filesystem permission errors.
+func ErrorKindOf(err error) ErrorKind {
+	var k *kindError
+	switch {
+	case errors.As(err, &k):
+		return k.kind
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.Is(err, os.ErrPermission):
+		return ErrPermissionDenied
+	}
+	return ErrInternal
+}
+
+// UserError returns the message to show users for err and a short ID to
+// log alongside the full error, so a pasted reply can be found in the logs.
+func UserError(err error) (text, id string) {
+	return LangEnglish.UserError(err)
+}
+
+// UserError is like the package-level UserError, in l.
+func (l Lang) UserError(err error) (text, id string) {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	id = hex.EncodeToString(b[:])
+	return l.T("%s (error ID %s)", l.T(errorKindText[ErrorKindOf(err)]), id), id
+}
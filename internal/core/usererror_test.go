@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(ErrRateLimited, ErrorKindOf(errors.Wrap(MarkError(ErrRateLimited, errors.New("429")), "converse")))
+	a.Equal(ErrTimeout, ErrorKindOf(errors.Wrap(context.DeadlineExceeded, "API call failed")))
+	a.Equal(ErrPermissionDenied, ErrorKindOf(&os.PathError{Op: "open", Path: "/root", Err: os.ErrPermission}))
+	a.Equal(ErrInternal, ErrorKindOf(errors.New("boom")))
+	a.Nil(MarkError(ErrTimeout, nil))
+}
+
+func TestHandleInbound_ReportsFailureWithErrorID(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a backend failing because the API is down
+	be := &stubBackend{converseErr: MarkError(ErrUnavailable, errors.New("POST /v1/messages: 503 Service Unavailable"))}
+	bot := NewBot(NewSessionManager(&stubFactory{next: func() Backend { return be }}, nil), nil)
+	out := &stubResponder{}
+
+	// when
+	err := bot.HandleInbound(Inbound{SessionKey: "k", Text: "hi", Reply: out})
+
+	// then
+	// ... the user sees the category and an ID that also appears in the error
+	r.Error(err)
+	r.Len(out.posted, 1)
+	a.True(strings.HasPrefix(out.posted[0], "The model backend is unavailable right now."))
+	a.NotContains(out.posted[0], "503")
+	id := strings.TrimSuffix(strings.SplitAfter(out.posted[0], "(error ID ")[1], ")")
+	a.Len(id, 8)
+	a.Contains(err.Error(), "error ID "+id)
+}
@@ -48,6 +48,7 @@ func escapeXMLAttr(s string) string {
 // <attachment> tags emitted by RenderWhatsAppBatch.
 const WhatsAppMediaSystemPromptAddendum = `
 When the user message contains <attachment> tags, treat each tag as an inbound
-file the user just sent. For image MIME types, call Read on the path attribute
-to view the image. For other MIME types, consult <available_skills> for one
+file the user just sent. Images are also attached to the message itself, so
+you can see them directly; call Read on the path attribute only if an image
+is missing from the message. For other MIME types, consult <available_skills> for one
 whose description matches the mime attribute and follow its instructions.`
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Workspace is a labelled working directory /new-session can start in.
+type Workspace struct {
+	Label   string
+	Path    string
+	Default bool
+	// ReadOnly sessions started here begin in /readonly mode.
+	ReadOnly bool
+}
+
+// SetWorkspaces sets the workspaces /new-session offers. Without any it
+// starts sessions in the backend's default directory. Call before the
+// first inbound is handled.
+func (b *Bot) SetWorkspaces(ws []Workspace) {
+	b.base.wsMu.Lock()
+	b.base.workspaces = ws
+	b.base.wsMu.Unlock()
+}
+
+// SetCloner enables /clone. Without one the command reports it is
+// unavailable.
+func (b *Bot) SetCloner(c Cloner) {
+	b.cloner = c
+}
+
+// SetScratch enables /scratch, which starts sessions in fresh directories
+// under root. Each is removed when its session ends or after ttl. Call
+// before the first inbound is handled.
+func (b *Bot) SetScratch(root string, ttl time.Duration) {
+	b.scratchRoot = root
+	b.scratchTTL = ttl
+}
+
+func (t *tenant) workspace(label string) (Workspace, bool) {
+	t.wsMu.Lock()
+	defer t.wsMu.Unlock()
+	for _, w := range t.workspaces {
+		if (label == "" && w.Default) || strings.EqualFold(w.Label, label) {
+			return w, true
+		}
+	}
+	return Workspace{}, false
+}
+
+func (t *tenant) workspaceList(l Lang) string {
+	t.wsMu.Lock()
+	defer t.wsMu.Unlock()
+	names := make([]string, 0, len(t.workspaces))
+	for _, w := range t.workspaces {
+		name := w.Label
+		switch {
+		case w.Default && w.ReadOnly:
+			name = l.T("%s (default, read-only)", name)
+		case w.Default:
+			name = l.T("%s (default)", name)
+		case w.ReadOnly:
+			name = l.T("%s (read-only)", name)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// cmdNewSession replaces the chat's session with a fresh one in the named
+// workspace, or the default one. The workspace's read-only policy replaces
+// the session's /readonly setting.
+func (b *Bot) cmdNewSession(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	t.wsMu.Lock()
+	configured := len(t.workspaces) > 0
+	t.wsMu.Unlock()
+	if !configured {
+		if args != "" {
+			return b.tr(in, "No workspaces are configured. Use /new-session without a name."), nil
+		}
+		if err := t.sessions.NewSession(in.SessionKey, "", in.Capabilities); err != nil {
+			return "", err
+		}
+		b.held.take(in.SessionKey)
+		return b.tr(in, "Started a new session."), nil
+	}
+
+	w, ok := t.workspace(args)
+	if !ok {
+		return b.tr(in, "Unknown workspace %q. Workspaces: %s.", args, t.workspaceList(b.Lang(in))), nil
+	}
+	if w.ReadOnly && t.readOnlyPerms == nil {
+		return b.tr(in, "Workspace %s is read-only, but read-only mode is not available.", w.Label), nil
+	}
+	if err := t.sessions.NewWorkspaceSession(in.SessionKey, w.Path, w.ReadOnly, in.Capabilities); err != nil {
+		return "", err
+	}
+	b.held.take(in.SessionKey)
+	if w.ReadOnly {
+		return b.tr(in, "Started a new read-only session in %s (%s).", w.Label, w.Path), nil
+	}
+	return b.tr(in, "Started a new session in %s (%s).", w.Label, w.Path), nil
+}
+
+// addWorkspace registers dir under label unless the label or path is taken,
+// reporting the label /new-session will find it by.
+func (t *tenant) addWorkspace(label, dir string) (string, bool) {
+	t.wsMu.Lock()
+	defer t.wsMu.Unlock()
+	for _, w := range t.workspaces {
+		if w.Path == dir {
+			return w.Label, true
+		}
+		if strings.EqualFold(w.Label, label) {
+			return "", false
+		}
+	}
+	t.workspaces = append(t.workspaces, Workspace{Label: label, Path: dir})
+	return label, true
+}
+
+// cmdClone checks out a repository and moves the chat's session there.
+func (b *Bot) cmdClone(ctx context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	if b.cloner == nil {
+		return b.tr(in, "Cloning is not available."), nil
+	}
+	if args == "" || strings.Contains(args, " ") {
+		return b.tr(in, "Use /clone <https-git-url>."), nil
+	}
+	if t.sessions.Settings(in.SessionKey).ReadOnly {
+		return b.tr(in, "Cloning is not allowed in read-only mode."), nil
+	}
+	if in.Reply != nil {
+		in.Reply.SendTyping()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.converseTimeout)
+	defer cancel()
+	dir, fresh, err := b.cloner.Clone(ctx, args)
+	if err != nil {
+		return b.tr(in, "Could not clone %s: %s", args, err), nil
+	}
+	if err := t.sessions.NewSession(in.SessionKey, dir, in.Capabilities); err != nil {
+		return "", err
+	}
+	b.held.take(in.SessionKey)
+
+	reply := b.tr(in, "Cloned %s into %s and started a new session there.", args, dir)
+	if !fresh {
+		reply = b.tr(in, "Already cloned %s into %s; started a new session there.", args, dir)
+	}
+	if label, ok := t.addWorkspace(strings.ToLower(filepath.Base(dir)), dir); ok {
+		reply += " " + b.tr(in, "Use /new-session %s to come back to it.", label)
+	}
+	return reply, nil
+}
+
+// cmdScratch moves the chat's session to a new empty directory that only
+// this session may write to.
+func (b *Bot) cmdScratch(_ context.Context, in Inbound, args string) (string, error) {
+	t := b.tenantFor(in)
+	if b.scratchRoot == "" {
+		return b.tr(in, "Scratch sessions are not available."), nil
+	}
+	if args != "" {
+		return b.tr(in, "Use /scratch without arguments."), nil
+	}
+	if t.sessions.Settings(in.SessionKey).ReadOnly {
+		return b.tr(in, "Scratch sessions are not allowed in read-only mode."), nil
+	}
+
+	if err := os.MkdirAll(b.scratchRoot, 0o755); err != nil {
+		return "", errors.Wrap(err, "creating scratch root")
+	}
+	dir, err := os.MkdirTemp(b.scratchRoot, "scratch-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating scratch dir")
+	}
+	if err := t.sessions.NewScratchSession(in.SessionKey, dir, in.Capabilities); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	b.held.take(in.SessionKey)
+	if b.scratchTTL <= 0 {
+		return b.tr(in, "Started a scratch session in %s. It is deleted when the session ends.", dir), nil
+	}
+	time.AfterFunc(b.scratchTTL, func() { t.sessions.ExpireScratch(dir) })
+	return b.tr(in, "Started a scratch session in %s. It is deleted when the session ends or after %d minutes.", dir, int(b.scratchTTL.Minutes())), nil
+}
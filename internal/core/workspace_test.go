@@ -0,0 +1,232 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type workDirFactory struct {
+	workDirs []string
+}
+
+func (f *workDirFactory) Create(workDir string, _ Capabilities) (Backend, error) {
+	f.workDirs = append(f.workDirs, workDir)
+	return &stubBackend{id: workDir}, nil
+}
+
+func TestHandleInbound_NewSessionInWorkspace(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... two workspaces, the second read-only
+	f := &workDirFactory{}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, namedPerms("default"))
+	bot.SetReadOnlyChecker(namedPerms("readonly"))
+	bot.SetWorkspaces([]Workspace{
+		{Label: "api", Path: "/srv/api", Default: true},
+		{Label: "docs", Path: "/srv/docs", ReadOnly: true},
+	})
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session Docs", Reply: out}))
+	readOnly := mgr.Settings("k1").ReadOnly
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session", Reply: out}))
+
+	// then
+	// ... each session starts in its workspace and takes its read-only policy
+	a.Equal([]string{"/srv/docs", "/srv/api"}, f.workDirs)
+	a.True(readOnly)
+	a.False(mgr.Settings("k1").ReadOnly)
+	a.Equal([]string{
+		"Started a new read-only session in docs (/srv/docs).",
+		"Started a new session in api (/srv/api).",
+	}, out.posted)
+}
+
+func TestHandleInbound_ReadOnlyWorkspaceStaysReadOnly(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a session in a read-only workspace
+	mgr := NewSessionManager(&workDirFactory{}, nil)
+	bot := NewBot(mgr, namedPerms("default"))
+	bot.SetReadOnlyChecker(namedPerms("readonly"))
+	bot.SetWorkspaces([]Workspace{
+		{Label: "api", Path: "/srv/api", Default: true},
+		{Label: "docs", Path: "/srv/docs", ReadOnly: true},
+	})
+	out := &stubResponder{}
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session docs", Reply: out}))
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/readonly off", Reply: out}))
+
+	// then
+	// ... the workspace's policy wins until the session moves elsewhere
+	a.True(mgr.Settings("k1").ReadOnly)
+	a.Equal("This session's workspace is read-only. Use /new-session with another workspace to write.", out.posted[1])
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session api", Reply: out}))
+	a.False(mgr.ReadOnlyLocked("k1"))
+}
+
+func TestHandleInbound_NewSessionUnknownWorkspace(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &workDirFactory{}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.SetWorkspaces([]Workspace{
+		{Label: "api", Path: "/srv/api", Default: true},
+		{Label: "docs", Path: "/srv/docs", ReadOnly: true},
+	})
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session web", Reply: out}))
+
+	// then
+	a.Empty(f.workDirs)
+	a.Equal([]string{`Unknown workspace "web". Workspaces: api (default), docs (read-only).`}, out.posted)
+}
+
+type stubCloner struct {
+	dir   string
+	fresh bool
+	err   error
+	urls  []string
+}
+
+func (c *stubCloner) Clone(_ context.Context, url string) (string, bool, error) {
+	c.urls = append(c.urls, url)
+	return c.dir, c.fresh, c.err
+}
+
+func TestHandleInbound_CloneStartsSessionAndRegistersWorkspace(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &workDirFactory{}
+	bot := NewBot(NewSessionManager(f, nil), nil)
+	bot.SetWorkspaces([]Workspace{{Label: "api", Path: "/srv/api", Default: true}})
+	bot.SetCloner(&stubCloner{dir: "/clones/web", fresh: true})
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/clone https://github.com/acme/web.git", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session web", Reply: out}))
+
+	// then
+	// ... the clone became a workspace /new-session can find
+	a.Equal([]string{"/clones/web", "/clones/web"}, f.workDirs)
+	r.Len(out.posted, 2)
+	a.Equal("Cloned https://github.com/acme/web.git into /clones/web and started a new session there. Use /new-session web to come back to it.", out.posted[0])
+	a.Equal("Started a new session in web (/clones/web).", out.posted[1])
+}
+
+func TestHandleInbound_CloneRefusals(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	f := &workDirFactory{}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, nil)
+	cloner := &stubCloner{err: errors.New("host gitlab.com is not in the clone allowlist")}
+	bot.SetCloner(cloner)
+	bot.SetReadOnlyChecker(namedPerms("readonly"))
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/clone https://gitlab.com/acme/web", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/readonly on"}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/clone https://github.com/acme/web", Reply: out}))
+
+	// then
+	a.Equal([]string{"https://gitlab.com/acme/web"}, cloner.urls)
+	a.Equal([]string{
+		"Could not clone https://gitlab.com/acme/web: host gitlab.com is not in the clone allowlist",
+		"Cloning is not allowed in read-only mode.",
+	}, out.posted)
+}
+
+// scopedPerms records the directories a session was scoped to.
+type scopedPerms struct {
+	dirs []string
+}
+
+func (*scopedPerms) Check(string, ToolInput) (bool, string) { return true, "" }
+
+func (p *scopedPerms) WithDirs(dirs ...string) PermissionChecker {
+	return &scopedPerms{dirs: append(p.dirs, dirs...)}
+}
+
+func TestHandleInbound_Scratch(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	root := t.TempDir()
+	var backends []*permsBackend
+	f := &stubFactory{next: func() Backend {
+		b := &permsBackend{}
+		backends = append(backends, b)
+		return b
+	}}
+	mgr := NewSessionManager(f, nil)
+	bot := NewBot(mgr, &scopedPerms{})
+	bot.SetScratch(root, time.Hour)
+	out := &stubResponder{}
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/scratch", Reply: out}))
+	dir := mgr.ScratchDir("k1")
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "try this", Reply: out}))
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/new-session", Reply: out}))
+
+	// then
+	// ... only the scratch session may use the directory, and it goes with it
+	r.NotEmpty(dir)
+	a.Equal(root, filepath.Dir(dir))
+	a.Equal(dir, f.created[0])
+	r.Len(backends[0].perms, 1)
+	a.Equal([]string{dir}, backends[0].perms[0].(*scopedPerms).dirs)
+	a.NoDirExists(dir)
+	a.Empty(mgr.ScratchDir("k1"))
+	a.Contains(out.posted[0], "Started a scratch session in "+dir)
+}
+
+func TestHandleInbound_ScratchExpires(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	backend := &stubBackend{}
+	mgr := NewSessionManager(&stubFactory{next: func() Backend { return backend }}, nil)
+	bot := NewBot(mgr, nil)
+	bot.SetScratch(t.TempDir(), 10*time.Millisecond)
+
+	// when
+	r.NoError(bot.HandleInbound(Inbound{SessionKey: "k1", Text: "/scratch", Reply: &stubResponder{}}))
+	dir := mgr.ScratchDir("k1")
+
+	// then
+	r.NotEmpty(dir)
+	a.Eventually(func() bool {
+		_, err := os.Stat(dir)
+		return os.IsNotExist(err)
+	}, time.Second, 5*time.Millisecond)
+	a.Empty(mgr.ScratchDir("k1"))
+}
@@ -0,0 +1,93 @@
+package dashboard
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/metrics"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366
+)
+
+// SetMetrics enables the analytics endpoints. Without a store they return
+// 404.
+func (s *Server) SetMetrics(m *metrics.Store) {
+	s.mu.Lock()
+	s.metrics = m
+	s.mu.Unlock()
+}
+
+// analyticsReport answers both analytics endpoints: ?days=N selects the
+// window ending today (UTC), default 30.
+func (s *Server) analyticsReport(w http.ResponseWriter, r *http.Request) (metrics.Report, bool) {
+	if !s.isAuthenticated(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return metrics.Report{}, false
+	}
+	s.mu.Lock()
+	m := s.metrics
+	s.mu.Unlock()
+	if m == nil {
+		http.Error(w, "analytics disabled", http.StatusNotFound)
+		return metrics.Report{}, false
+	}
+	days := defaultAnalyticsDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAnalyticsDays {
+			http.Error(w, "days must be between 1 and 366", http.StatusBadRequest)
+			return metrics.Report{}, false
+		}
+		days = n
+	}
+	rep, err := m.Report(r.Context(), time.Now().UTC().AddDate(0, 0, 1-days))
+	if err != nil {
+		slog.Error("analytics report", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return metrics.Report{}, false
+	}
+	return rep, true
+}
+
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	rep, ok := s.analyticsReport(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rep)
+}
+
+// handleAnalyticsCSV exports the daily rows, one line per day with turns.
+func (s *Server) handleAnalyticsCSV(w http.ResponseWriter, r *http.Request) {
+	rep, ok := s.analyticsReport(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="switchboard-usage-`+rep.Since+`.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"day", "turns", "failed", "input_tokens", "output_tokens", "thinking_tokens", "tool_calls", "avg_latency_ms", "thumbs_up", "thumbs_down"})
+	for _, d := range rep.Days {
+		cw.Write([]string{
+			d.Day,
+			strconv.FormatInt(d.Turns, 10),
+			strconv.FormatInt(d.Failed, 10),
+			strconv.FormatInt(d.InputTokens, 10),
+			strconv.FormatInt(d.OutputTokens, 10),
+			strconv.FormatInt(d.ThinkingTokens, 10),
+			strconv.FormatInt(d.ToolCalls, 10),
+			strconv.FormatFloat(d.AvgLatencyMillis, 'f', 0, 64),
+			strconv.FormatInt(d.ThumbsUp, 10),
+			strconv.FormatInt(d.ThumbsDown, 10),
+		})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/metrics"
+
+	_ "modernc.org/sqlite"
+)
+
+func loginCookie(t *testing.T, handler http.Handler) *http.Cookie {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("password=testpass"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+func TestServer_Analytics_JSONAndCSV(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a metrics store with one turn today
+	store, err := metrics.Open(filepath.Join(t.TempDir(), "metrics.db"))
+	r.NoError(err)
+	defer store.Close()
+	store.RecordTurn(core.TurnStats{At: time.Now(), UserID: "discord:1", InputTokens: 120, OutputTokens: 30, ToolCalls: []string{"Bash"}, Latency: 1500 * time.Millisecond})
+	s := NewServer(nil, nil, nil, nil, "", "", "", "", "testpass", nil)
+	s.SetMetrics(store)
+	handler := s.Handler()
+	cookie := loginCookie(t, handler)
+
+	// when
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics?days=7", nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	// then
+	r.Equal(http.StatusOK, rec.Code)
+	var rep metrics.Report
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &rep))
+	a.Equal(int64(1), rep.Turns)
+	a.Equal([]metrics.Tool{{Name: "Bash", Calls: 1}}, rep.Tools)
+
+	// when
+	req = httptest.NewRequest(http.MethodGet, "/api/analytics.csv", nil)
+	req.AddCookie(cookie)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	// then
+	r.Equal(http.StatusOK, rec.Code)
+	a.Equal("text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	today := time.Now().UTC().Format(time.DateOnly)
+	a.Equal("day,turns,failed,input_tokens,output_tokens,thinking_tokens,tool_calls,avg_latency_ms,thumbs_up,thumbs_down\n"+today+",1,0,120,30,0,1,1500,0,0\n", rec.Body.String())
+}
+
+func TestServer_Analytics_RequiresAuthAndStore(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a server without a metrics store
+	s := NewServer(nil, nil, nil, nil, "", "", "", "", "testpass", nil)
+	handler := s.Handler()
+
+	// when
+	anon := httptest.NewRecorder()
+	handler.ServeHTTP(anon, httptest.NewRequest(http.MethodGet, "/api/analytics", nil))
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics.csv", nil)
+	req.AddCookie(loginCookie(t, handler))
+	disabled := httptest.NewRecorder()
+	handler.ServeHTTP(disabled, req)
+
+	// then
+	a.Equal(http.StatusUnauthorized, anon.Code)
+	a.Equal(http.StatusNotFound, disabled.Code)
+}
@@ -9,6 +9,29 @@ import (
 
 const sessionCookieName = "switchboard_session"
 
+// authSession is a logged-in dashboard browser.
+type authSession struct {
+	created time.Time
+	// viewer sessions logged in with the viewer password: they see chat,
+	// logs and tool activity but can't chat or change anything.
+	viewer bool
+}
+
+func (a authSession) role() string {
+	if a.viewer {
+		return "viewer"
+	}
+	return "admin"
+}
+
+// SetViewerPassword enables read-only spectator logins with pw. Empty
+// disables them.
+func (s *Server) SetViewerPassword(pw string) {
+	s.mu.Lock()
+	s.viewerPassword = pw
+	s.mu.Unlock()
+}
+
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		data, err := staticFiles.ReadFile("static/login.html")
@@ -27,12 +50,21 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if r.FormValue("password") != s.password {
+		s.mu.Lock()
+		viewerPassword := s.viewerPassword
+		s.mu.Unlock()
+
+		var viewer bool
+		switch password := r.FormValue("password"); {
+		case password == s.password:
+		case viewerPassword != "" && password == viewerPassword:
+			viewer = true
+		default:
 			http.Error(w, "invalid password", http.StatusUnauthorized)
 			return
 		}
 
-		token := s.createSession()
+		token := s.createSession(viewer)
 		http.SetCookie(w, &http.Cookie{
 			Name:     sessionCookieName,
 			Value:    token,
@@ -48,29 +80,34 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 }
 
-func (s *Server) createSession() string {
+func (s *Server) createSession(viewer bool) string {
 	b := make([]byte, 32)
 	rand.Read(b)
 	token := hex.EncodeToString(b)
 
 	s.mu.Lock()
-	s.sessions[token] = time.Now()
+	s.sessions[token] = authSession{created: time.Now(), viewer: viewer}
 	s.mu.Unlock()
 
 	return token
 }
 
 func (s *Server) isAuthenticated(r *http.Request) bool {
+	_, ok := s.authSession(r)
+	return ok
+}
+
+func (s *Server) authSession(r *http.Request) (authSession, bool) {
 	cookie, err := r.Cookie(sessionCookieName)
 	if err != nil {
-		return false
+		return authSession{}, false
 	}
 
 	s.mu.Lock()
-	_, valid := s.sessions[cookie.Value]
+	session, valid := s.sessions[cookie.Value]
 	s.mu.Unlock()
 
-	return valid
+	return session, valid
 }
 
 func (s *Server) requireAuth(next http.Handler) http.Handler {
@@ -0,0 +1,63 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Broadcaster posts an operator announcement to every chat with a live
+// session. core.Bot implements it.
+type Broadcaster interface {
+	Broadcast(text string) (sent, failed int)
+}
+
+// SetBroadcaster enables the dashboard's Broadcast panel and
+// POST /api/broadcast. Without it both report broadcasting as unavailable.
+func (s *Server) SetBroadcaster(b Broadcaster) {
+	s.mu.Lock()
+	s.broadcaster = b
+	s.mu.Unlock()
+}
+
+// broadcast sends text through the Broadcaster, or reports why it can't
+// with the matching HTTP status.
+func (s *Server) broadcast(text string) (BroadcastEvent, int) {
+	s.mu.Lock()
+	b := s.broadcaster
+	s.mu.Unlock()
+	text = strings.TrimSpace(text)
+	switch {
+	case b == nil:
+		return BroadcastEvent{Error: "broadcasting is not available"}, http.StatusServiceUnavailable
+	case text == "":
+		return BroadcastEvent{Error: "text is required"}, http.StatusBadRequest
+	}
+	slog.Info("broadcast requested from the dashboard")
+	sent, failed := b.Broadcast(text)
+	return BroadcastEvent{Sent: sent, Failed: failed}, http.StatusOK
+}
+
+// handleBroadcastAPI takes a BroadcastRequest body and answers with a
+// BroadcastEvent.
+func (s *Server) handleBroadcastAPI(w http.ResponseWriter, r *http.Request) {
+	var req BroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	e, status := s.broadcast(req.Text)
+	if e.Error != "" {
+		writeError(w, status, e.Error)
+		return
+	}
+	writeJSON(w, status, e)
+}
+
+// handleBroadcast runs off the read loop, since posting waits on every
+// platform, and answers the client that asked.
+func (s *Server) handleBroadcast(client *Client, text string) {
+	e, _ := s.broadcast(text)
+	client.Send(e)
+}
@@ -2,79 +2,109 @@ package dashboard
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/TheLazyLemur/switchboard/internal/core"
 )
 
-func (s *Server) handleMessage(client *Client, msg Message) {
-	switch msg.Type {
-	case "chat":
-		go s.handleChat(msg.Content)
+// ChatSessionKey is the session dashboard chat is routed to. The dashboard
+// channel plugin uses the same key so the server and the bot agree on which
+// backend is "the dashboard session".
+const ChatSessionKey = core.SessionKey("dashboard")
+
+// ChatCapabilities describes what the dashboard chat surface supports.
+var ChatCapabilities = core.Capabilities{Reactions: false, Updates: true}
+
+// viewerMessages are the WS requests a viewer client may send.
+var viewerMessages = map[string]bool{
+	"get_skills":    true,
+	"get_skill":     true,
+	"get_agents_md": true,
+	"list_memory":   true,
+	"get_memory":    true,
+	"get_whatsapp":  true,
+}
+
+func (s *Server) handleMessage(client *Client, req Request) {
+	defer core.Recover("dashboard "+req.Kind(), func(text string) {
+		client.Send(LogEvent{Level: "ERROR", Msg: text, Time: time.Now().Format(time.RFC3339)})
+	})
+	if client.viewer && !viewerMessages[req.Kind()] {
+		slog.Warn("dashboard viewer request refused", "type", req.Kind())
+		client.Send(LogEvent{Level: "WARN", Msg: "read-only viewer: " + req.Kind() + " not allowed", Time: time.Now().Format(time.RFC3339)})
+		return
+	}
+	switch req := req.(type) {
+	case *ChatRequest:
+		go s.handleChat(req.Content)
 
-	case "get_skills":
+	case *GetSkillsRequest:
 		s.handleGetSkills(client)
 
-	case "get_skill":
-		s.handleGetSkill(client, msg.Name)
+	case *GetSkillRequest:
+		s.handleGetSkill(client, req.Name)
+
+	case *NewSkillRequest:
+		s.handleNewSkill(client, req)
 
-	case "save_skill":
-		s.handleSaveSkill(client, msg)
+	case *SaveSkillRequest:
+		s.handleSaveSkill(client, req)
 
-	case "delete_skill_file":
-		s.handleDeleteSkillFile(client, msg.Name, msg.Path)
+	case *DeleteSkillFileRequest:
+		s.handleDeleteSkillFile(client, req.Name, req.Path)
 
-	case "get_agents_md":
+	case *GetAgentsMdRequest:
 		s.handleGetAgentsMd(client)
 
-	case "save_agents_md":
-		s.handleSaveAgentsMd(client, msg.Content)
+	case *SaveAgentsMdRequest:
+		s.handleSaveAgentsMd(client, req.Content)
 
-	case "reset_agents_md":
+	case *ResetAgentsMdRequest:
 		s.handleResetAgentsMd(client)
 
-	case "list_memory":
+	case *ListMemoryRequest:
 		s.handleListMemory(client)
 
-	case "get_memory":
-		s.handleGetMemory(client, msg.Path)
+	case *GetMemoryRequest:
+		s.handleGetMemory(client, req.Path)
 
-	case "save_memory":
-		s.handleSaveMemory(client, msg.Path, msg.Content)
+	case *SaveMemoryRequest:
+		s.handleSaveMemory(client, req.Path, req.Content)
 
-	case "delete_memory":
-		s.handleDeleteMemory(client, msg.Path)
+	case *DeleteMemoryRequest:
+		s.handleDeleteMemory(client, req.Path)
+
+	case *GetWhatsAppRequest:
+		s.handleGetWhatsApp(client)
+
+	case *RepairWhatsAppRequest:
+		go s.handleRepairWhatsApp()
+
+	case *BroadcastRequest:
+		go s.handleBroadcast(client, req.Text)
 	}
 }
 
 func (s *Server) handleChat(content string) {
-	backend, err := s.sessionMgr.GetOrCreateSession(core.Capabilities{})
+	defer core.Recover("dashboard chat", func(text string) {
+		s.hub.Broadcast(ChatEvent{Role: "assistant", Content: text})
+	})
+	backend, err := s.sessionMgr.GetOrCreateSession(ChatSessionKey, ChatCapabilities)
 	if err != nil {
-		slog.Error("get session", "error", err)
-		s.hub.Broadcast(Message{
-			Type:    "chat",
-			Role:    "assistant",
-			Content: "Error: " + err.Error(),
-		})
+		text, id := core.UserError(err)
+		slog.Error("get session", "error_id", id, "error", err)
+		s.hub.Broadcast(ChatEvent{Role: "assistant", Content: text})
 		return
 	}
 
 	s.mu.Lock()
 	if s.lastSessionID != backend.SessionID() {
 		s.lastSessionID = backend.SessionID()
-		active := true
-		s.hub.Broadcast(Message{
-			Type:      "session",
-			Active:    &active,
-			SessionID: backend.SessionID(),
-		})
+		s.hub.Broadcast(SessionEvent{Active: true, SessionID: backend.SessionID()})
 	}
 	s.mu.Unlock()
 
-	s.hub.Broadcast(Message{
-		Type:    "chat",
-		Role:    "user",
-		Content: content,
-	})
+	s.hub.Broadcast(ChatEvent{Role: "user", Content: content})
 
 	s.chatCallback(backend.SessionID(), content)
 }
@@ -33,8 +33,8 @@ func (f *fakeBackendFactory) Create(_ string, _ core.Capabilities) (core.Backend
 
 // collectSessionBroadcasts drains client.send for up to 50ms and returns all
 // decoded Messages with Type == "session".
-func collectSessionBroadcasts(client *Client) []Message {
-	var out []Message
+func collectSessionBroadcasts(client *Client) []SessionEvent {
+	var out []SessionEvent
 	deadline := time.After(50 * time.Millisecond)
 	for {
 		select {
@@ -42,8 +42,9 @@ func collectSessionBroadcasts(client *Client) []Message {
 			if !ok {
 				return out
 			}
-			var m Message
-			if err := json.Unmarshal(data, &m); err == nil && m.Type == "session" {
+			var env Envelope
+			var m SessionEvent
+			if json.Unmarshal(data, &env) == nil && env.Type == "session" && json.Unmarshal(data, &m) == nil {
 				out = append(out, m)
 			}
 		case <-deadline:
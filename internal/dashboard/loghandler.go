@@ -28,8 +28,7 @@ func (h *BroadcastHandler) Enabled(ctx context.Context, level slog.Level) bool {
 // Handle broadcasts the log record and delegates to inner handler.
 func (h *BroadcastHandler) Handle(ctx context.Context, r slog.Record) error {
 	// Broadcast to WS clients
-	h.hub.Broadcast(Message{
-		Type:  "log",
+	h.hub.Broadcast(LogEvent{
 		Level: r.Level.String(),
 		Msg:   r.Message,
 		Time:  r.Time.Format(time.RFC3339),
@@ -16,34 +16,34 @@ func (s *Server) handleListMemory(client *Client) {
 	for _, f := range files {
 		infos = append(infos, SkillFile{Path: f})
 	}
-	client.Send(Message{Type: "memory_list", Files: infos})
+	client.Send(MemoryListEvent{Files: infos})
 }
 
 func (s *Server) handleGetMemory(client *Client, path string) {
 	content, err := memory.Read(s.memoryDir, path)
 	if err != nil {
 		slog.Error("read memory", "error", err, "path", path)
-		client.Send(Message{Type: "memory_file", Path: path, Msg: err.Error()})
+		client.Send(MemoryFileEvent{Path: path, Error: err.Error()})
 		return
 	}
-	client.Send(Message{Type: "memory_file", Path: path, Content: content})
+	client.Send(MemoryFileEvent{Path: path, Content: content})
 }
 
 func (s *Server) handleSaveMemory(client *Client, path, content string) {
 	if err := memory.Write(s.memoryDir, path, content); err != nil {
 		slog.Error("write memory", "error", err, "path", path)
-		client.Send(Message{Type: "memory_file", Path: path, Msg: err.Error()})
+		client.Send(MemoryFileEvent{Path: path, Error: err.Error()})
 		return
 	}
 	slog.Info("memory saved", "path", path)
-	client.Send(Message{Type: "memory_file", Path: path, Content: content})
+	client.Send(MemoryFileEvent{Path: path, Content: content})
 	s.handleListMemory(client)
 }
 
 func (s *Server) handleDeleteMemory(client *Client, path string) {
 	if err := memory.Delete(s.memoryDir, path); err != nil {
 		slog.Error("delete memory", "error", err, "path", path)
-		client.Send(Message{Type: "memory_list", Msg: err.Error()})
+		client.Send(MemoryListEvent{Files: []SkillFile{}, Error: err.Error()})
 		return
 	}
 	slog.Info("memory deleted", "path", path)
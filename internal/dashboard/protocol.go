@@ -0,0 +1,312 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ProtocolVersion is the dashboard WS protocol version. Every server message
+// carries it as "v"; clients may send it too and are refused if it differs.
+// Bump it when a message kind changes incompatibly.
+const ProtocolVersion = 1
+
+// Every WS message is one JSON object whose "type" names its kind; the
+// kind's fields sit next to it. Event kinds flow server to client, Request
+// kinds client to server. GET /api/schema describes both.
+
+// Event is a server-to-client message.
+type Event interface {
+	Kind() string
+}
+
+// Request is a client-to-server message.
+type Request interface {
+	Kind() string
+}
+
+// Envelope is the part every message shares.
+type Envelope struct {
+	Type string `json:"type"`
+	V    int    `json:"v,omitempty"`
+}
+
+type (
+	LogEvent struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+		Time  string `json:"time"`
+	}
+	ChatEvent struct {
+		Role      string `json:"role"`
+		Content   string `json:"content"`
+		SessionID string `json:"sessionID,omitempty"`
+	}
+	TypingEvent struct {
+		Active bool `json:"active"`
+	}
+	SessionEvent struct {
+		Active    bool   `json:"active"`
+		SessionID string `json:"sessionID"`
+	}
+	// RoleEvent is sent once on connect: "admin" or "viewer".
+	RoleEvent struct {
+		Role string `json:"role"`
+	}
+	// VersionEvent is sent once on connect: the running build and, if the
+	// update check found one, a newer release.
+	VersionEvent struct {
+		Version string `json:"version"`
+		Latest  string `json:"latest,omitempty"`
+	}
+	SkillsEvent struct {
+		Skills []SkillInfo `json:"skills"`
+	}
+	SkillDetailEvent struct {
+		Name    string      `json:"name"`
+		Content string      `json:"content"`
+		Files   []SkillFile `json:"files"`
+	}
+	// SkillInvalidEvent answers a save_skill whose SKILL.md would not load;
+	// nothing was written.
+	SkillInvalidEvent struct {
+		Name   string       `json:"name"`
+		Errors []SkillError `json:"errors"`
+	}
+	AgentsMdEvent struct {
+		Content string `json:"content"`
+		Error   string `json:"error,omitempty"`
+	}
+	MemoryListEvent struct {
+		Files []SkillFile `json:"files"`
+		Error string      `json:"error,omitempty"`
+	}
+	MemoryFileEvent struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+		Error   string `json:"error,omitempty"`
+	}
+	// WhatsAppQREvent carries a pairing code, or "success"/"timeout" once
+	// pairing ends.
+	WhatsAppQREvent struct {
+		Content string `json:"content"`
+	}
+	// WhatsAppStatusEvent answers get_whatsapp and follows a re-pair.
+	// Enabled is false when the bot runs without WhatsApp.
+	WhatsAppStatusEvent struct {
+		Enabled bool           `json:"enabled"`
+		Status  WhatsAppStatus `json:"status"`
+		Error   string         `json:"error,omitempty"`
+	}
+	// BroadcastEvent answers a broadcast with how many chats got it.
+	BroadcastEvent struct {
+		Sent   int    `json:"sent"`
+		Failed int    `json:"failed"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+func (LogEvent) Kind() string            { return "log" }
+func (ChatEvent) Kind() string           { return "chat" }
+func (TypingEvent) Kind() string         { return "typing" }
+func (SessionEvent) Kind() string        { return "session" }
+func (RoleEvent) Kind() string           { return "role" }
+func (VersionEvent) Kind() string        { return "version" }
+func (SkillsEvent) Kind() string         { return "skills" }
+func (SkillDetailEvent) Kind() string    { return "skill_detail" }
+func (SkillInvalidEvent) Kind() string   { return "skill_invalid" }
+func (AgentsMdEvent) Kind() string       { return "agents_md" }
+func (MemoryListEvent) Kind() string     { return "memory_list" }
+func (MemoryFileEvent) Kind() string     { return "memory_file" }
+func (WhatsAppQREvent) Kind() string     { return "whatsapp_qr" }
+func (WhatsAppStatusEvent) Kind() string { return "whatsapp_status" }
+func (BroadcastEvent) Kind() string      { return "broadcast" }
+
+type (
+	ChatRequest struct {
+		Content string `json:"content"`
+	}
+	GetSkillsRequest struct{}
+	GetSkillRequest  struct {
+		Name string `json:"name"`
+	}
+	// NewSkillRequest scaffolds a skill directory from the template.
+	NewSkillRequest struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+	// SaveSkillRequest writes SKILL.md and any supporting files given.
+	SaveSkillRequest struct {
+		Name    string      `json:"name"`
+		Content string      `json:"content"`
+		Files   []SkillFile `json:"files,omitempty"`
+	}
+	DeleteSkillFileRequest struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	}
+	GetAgentsMdRequest  struct{}
+	SaveAgentsMdRequest struct {
+		Content string `json:"content"`
+	}
+	ResetAgentsMdRequest struct{}
+	ListMemoryRequest    struct{}
+	GetMemoryRequest     struct {
+		Path string `json:"path"`
+	}
+	SaveMemoryRequest struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	DeleteMemoryRequest struct {
+		Path string `json:"path"`
+	}
+	GetWhatsAppRequest struct{}
+	// RepairWhatsAppRequest logs the linked device out and shows a fresh
+	// pairing QR.
+	RepairWhatsAppRequest struct{}
+	// BroadcastRequest posts Text to every chat with a live session.
+	BroadcastRequest struct {
+		Text string `json:"text"`
+	}
+)
+
+func (ChatRequest) Kind() string            { return "chat" }
+func (GetSkillsRequest) Kind() string       { return "get_skills" }
+func (GetSkillRequest) Kind() string        { return "get_skill" }
+func (NewSkillRequest) Kind() string        { return "new_skill" }
+func (SaveSkillRequest) Kind() string       { return "save_skill" }
+func (DeleteSkillFileRequest) Kind() string { return "delete_skill_file" }
+func (GetAgentsMdRequest) Kind() string     { return "get_agents_md" }
+func (SaveAgentsMdRequest) Kind() string    { return "save_agents_md" }
+func (ResetAgentsMdRequest) Kind() string   { return "reset_agents_md" }
+func (ListMemoryRequest) Kind() string      { return "list_memory" }
+func (GetMemoryRequest) Kind() string       { return "get_memory" }
+func (SaveMemoryRequest) Kind() string      { return "save_memory" }
+func (DeleteMemoryRequest) Kind() string    { return "delete_memory" }
+func (GetWhatsAppRequest) Kind() string     { return "get_whatsapp" }
+func (RepairWhatsAppRequest) Kind() string  { return "repair_whatsapp" }
+func (BroadcastRequest) Kind() string       { return "broadcast" }
+
+var eventKinds = []Event{
+	LogEvent{}, ChatEvent{}, TypingEvent{}, SessionEvent{}, RoleEvent{},
+	SkillsEvent{}, SkillDetailEvent{}, SkillInvalidEvent{}, AgentsMdEvent{}, MemoryListEvent{},
+	MemoryFileEvent{}, WhatsAppQREvent{}, VersionEvent{}, WhatsAppStatusEvent{}, BroadcastEvent{},
+}
+
+var requestKinds = map[string]func() Request{}
+
+func init() {
+	for _, r := range []Request{
+		ChatRequest{}, GetSkillsRequest{}, GetSkillRequest{}, NewSkillRequest{}, SaveSkillRequest{},
+		DeleteSkillFileRequest{}, GetAgentsMdRequest{}, SaveAgentsMdRequest{},
+		ResetAgentsMdRequest{}, ListMemoryRequest{}, GetMemoryRequest{},
+		SaveMemoryRequest{}, DeleteMemoryRequest{}, GetWhatsAppRequest{}, RepairWhatsAppRequest{},
+		BroadcastRequest{},
+	} {
+		t := reflect.TypeOf(r)
+		requestKinds[r.Kind()] = func() Request {
+			return reflect.New(t).Interface().(Request)
+		}
+	}
+}
+
+// encodeEvent renders e as {"type":..., "v":..., <e's fields>}.
+func encodeEvent(e Event) ([]byte, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	head := fmt.Sprintf(`{"type":%q,"v":%d`, e.Kind(), ProtocolVersion)
+	if len(body) <= 2 {
+		return []byte(head + "}"), nil
+	}
+	return append([]byte(head+","), body[1:]...), nil
+}
+
+// decodeRequest parses one client message into its Request kind. The
+// result is a pointer to the kind's struct.
+func decodeRequest(data []byte) (Request, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, errors.Wrap(err, "decoding message")
+	}
+	if env.V != 0 && env.V != ProtocolVersion {
+		return nil, errors.Errorf("unsupported protocol version %d (server speaks %d)", env.V, ProtocolVersion)
+	}
+	newRequest, ok := requestKinds[env.Type]
+	if !ok {
+		return nil, errors.Errorf("unknown message type %q", env.Type)
+	}
+	req := newRequest()
+	if err := json.Unmarshal(data, req); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", env.Type)
+	}
+	return req, nil
+}
+
+// Schema describes the protocol as JSON Schema objects keyed by kind.
+type Schema struct {
+	Version  int                       `json:"version"`
+	Events   map[string]map[string]any `json:"events"`
+	Requests map[string]map[string]any `json:"requests"`
+}
+
+// ProtocolSchema returns the schema served at /api/schema.
+func ProtocolSchema() Schema {
+	s := Schema{
+		Version:  ProtocolVersion,
+		Events:   make(map[string]map[string]any),
+		Requests: make(map[string]map[string]any),
+	}
+	for _, e := range eventKinds {
+		s.Events[e.Kind()] = kindSchema(e.Kind(), reflect.TypeOf(e))
+	}
+	for kind, newRequest := range requestKinds {
+		s.Requests[kind] = kindSchema(kind, reflect.TypeOf(newRequest()).Elem())
+	}
+	return s
+}
+
+func kindSchema(kind string, t reflect.Type) map[string]any {
+	schema := typeSchema(t)
+	props := schema["properties"].(map[string]any)
+	props["type"] = map[string]any{"const": kind}
+	props["v"] = map[string]any{"const": ProtocolVersion}
+	schema["required"] = append([]string{"type"}, schema["required"].([]string)...)
+	return schema
+}
+
+// typeSchema maps the Go types the protocol uses to JSON Schema. Fields
+// without omitempty are required.
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		props := make(map[string]any)
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			props[name] = typeSchema(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]any{"type": "object", "properties": props, "required": required}
+	}
+	return map[string]any{}
+}
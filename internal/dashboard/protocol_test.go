@@ -0,0 +1,76 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeEvent_FlattensKindAndVersion(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// when
+	chat, err := encodeEvent(ChatEvent{Role: "user", Content: "hi"})
+	r.NoError(err)
+	empty, err := encodeEvent(SkillsEvent{})
+	r.NoError(err)
+
+	// then
+	a.JSONEq(`{"type":"chat","v":1,"role":"user","content":"hi"}`, string(chat))
+	a.JSONEq(`{"type":"skills","v":1,"skills":null}`, string(empty))
+}
+
+func TestDecodeRequest(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// when
+	save, err := decodeRequest([]byte(`{"type":"save_memory","v":1,"path":"notes.md","content":"x"}`))
+	r.NoError(err)
+	unversioned, err := decodeRequest([]byte(`{"type":"get_skills"}`))
+	r.NoError(err)
+	_, futureErr := decodeRequest([]byte(`{"type":"chat","v":2,"content":"hi"}`))
+	_, unknownErr := decodeRequest([]byte(`{"type":"permission_response"}`))
+
+	// then
+	a.Equal(&SaveMemoryRequest{Path: "notes.md", Content: "x"}, save)
+	a.IsType(&GetSkillsRequest{}, unversioned)
+	a.ErrorContains(futureErr, "unsupported protocol version 2")
+	a.ErrorContains(unknownErr, `unknown message type "permission_response"`)
+}
+
+func TestServer_Schema(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... no login: the schema is public
+	s := NewServer(nil, nil, nil, nil, "", "", "", "", "testpass", nil)
+
+	// when
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/schema", nil))
+
+	// then
+	r.Equal(http.StatusOK, rec.Code)
+	var got struct {
+		Version  int                       `json:"version"`
+		Events   map[string]map[string]any `json:"events"`
+		Requests map[string]map[string]any `json:"requests"`
+	}
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &got))
+	a.Equal(ProtocolVersion, got.Version)
+	a.Len(got.Events, len(eventKinds))
+	a.Len(got.Requests, len(requestKinds))
+
+	memoryFile := got.Events["memory_file"]
+	a.Equal(map[string]any{"const": "memory_file"}, memoryFile["properties"].(map[string]any)["type"])
+	a.ElementsMatch([]any{"type", "path", "content"}, memoryFile["required"])
+	save := got.Requests["save_skill"]["properties"].(map[string]any)
+	a.Equal("array", save["files"].(map[string]any)["type"])
+}
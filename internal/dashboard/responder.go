@@ -16,24 +16,15 @@ func NewWSResponder(hub *Hub, sessionID string) *WSResponder {
 
 // SendTyping broadcasts typing indicator.
 func (r *WSResponder) SendTyping() error {
-	active := true
-	r.hub.Broadcast(Message{
-		Type:   "typing",
-		Active: &active,
-	})
+	r.hub.Broadcast(TypingEvent{Active: true})
 	return nil
 }
 
 // PostResponse broadcasts final response.
 func (r *WSResponder) PostResponse(content string) error {
-	active := false
-	r.hub.Broadcast(Message{
-		Type:   "typing",
-		Active: &active,
-	})
+	r.hub.Broadcast(TypingEvent{Active: false})
 
-	r.hub.Broadcast(Message{
-		Type:      "chat",
+	r.hub.Broadcast(ChatEvent{
 		Role:      "assistant",
 		Content:   content,
 		SessionID: r.sessionID,
@@ -48,8 +39,7 @@ func (r *WSResponder) AddReaction(emoji string) error {
 
 // SendUpdate broadcasts an incremental update.
 func (r *WSResponder) SendUpdate(message string) error {
-	r.hub.Broadcast(Message{
-		Type:      "chat",
+	r.hub.Broadcast(ChatEvent{
 		Role:      "assistant",
 		Content:   message,
 		SessionID: r.sessionID,
@@ -0,0 +1,238 @@
+package dashboard
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// The REST endpoints mirror the WS requests for scripts and CLI tools. They
+// accept a dashboard login cookie or, once SetAPIToken is called,
+// "Authorization: Bearer <token>", which acts as an admin.
+
+// SetAPIToken enables bearer-token access to the REST API. Empty disables it.
+func (s *Server) SetAPIToken(token string) {
+	s.mu.Lock()
+	s.apiToken = token
+	s.mu.Unlock()
+}
+
+func (s *Server) apiSession(r *http.Request) (authSession, bool) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		s.mu.Lock()
+		want := s.apiToken
+		s.mu.Unlock()
+		valid := want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+		return authSession{}, valid
+	}
+	return s.authSession(r)
+}
+
+// api guards a REST handler; write handlers refuse viewers.
+func (s *Server) api(write bool, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, ok := s.apiSession(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if write && session.viewer {
+			slog.Warn("dashboard viewer request refused", "method", r.Method, "path", r.URL.Path)
+			writeError(w, http.StatusForbidden, "read-only viewer")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) routeAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/sessions", s.api(false, s.handleListSessions))
+	mux.HandleFunc("POST /api/sessions", s.api(true, s.handleNewSession))
+	mux.HandleFunc("GET /api/skills", s.api(false, s.handleListSkillsAPI))
+	mux.HandleFunc("POST /api/skills", s.api(true, s.handleNewSkillAPI))
+	mux.HandleFunc("GET /api/skills/{name}", s.api(false, s.handleGetSkillAPI))
+	mux.HandleFunc("PUT /api/skills/{name}", s.api(true, s.handlePutSkillAPI))
+	mux.HandleFunc("POST /api/skills/{name}/files", s.api(true, s.handleUploadSkillFile))
+	mux.HandleFunc("POST /api/skills/{name}/preview", s.api(false, s.handlePreviewSkillAPI))
+	mux.HandleFunc("POST /api/broadcast", s.api(true, s.handleBroadcastAPI))
+}
+
+// handleListSessions lists live sessions, optionally only those with
+// ?tag= and in ?workdir=.
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	tag, workDir := r.URL.Query().Get("tag"), r.URL.Query().Get("workdir")
+	sessions := []core.SessionInfo{}
+	for _, info := range s.sessionMgr.Sessions() {
+		if info.Matches(tag, workDir) {
+			sessions = append(sessions, info)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+}
+
+// handleNewSession replaces the dashboard chat session with a fresh one in
+// the server's working directory.
+func (s *Server) handleNewSession(w http.ResponseWriter, r *http.Request) {
+	if err := s.sessionMgr.NewSession(ChatSessionKey, s.workDir, ChatCapabilities); err != nil {
+		text, id := core.UserError(err)
+		slog.Error("new session", "error_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, text)
+		return
+	}
+	backend, err := s.sessionMgr.GetSession(ChatSessionKey)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.lastSessionID = backend.SessionID()
+	s.mu.Unlock()
+	s.hub.Broadcast(SessionEvent{Active: true, SessionID: backend.SessionID()})
+
+	writeJSON(w, http.StatusCreated, SessionEvent{Active: true, SessionID: backend.SessionID()})
+}
+
+func (s *Server) handleListSkillsAPI(w http.ResponseWriter, r *http.Request) {
+	infos, err := s.listSkills()
+	if err != nil {
+		slog.Error("list skills", "error", err)
+		writeError(w, http.StatusInternalServerError, "listing skills failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, SkillsEvent{Skills: infos})
+}
+
+// handleNewSkillAPI scaffolds a skill from a NewSkillRequest body.
+func (s *Server) handleNewSkillAPI(w http.ResponseWriter, r *http.Request) {
+	var req NewSkillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	detail, err := s.newSkill(&req)
+	if err != nil {
+		if writeInvalidSkill(w, err) {
+			return
+		}
+		slog.Error("new skill", "error", err, "name", req.Name)
+		writeError(w, http.StatusInternalServerError, "creating skill failed")
+		return
+	}
+	writeJSON(w, http.StatusCreated, detail)
+}
+
+func (s *Server) handleGetSkillAPI(w http.ResponseWriter, r *http.Request) {
+	detail, err := s.skillDetail(r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "skill not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// handlePutSkillAPI takes a SaveSkillRequest body; the name comes from the
+// path.
+func (s *Server) handlePutSkillAPI(w http.ResponseWriter, r *http.Request) {
+	var req SaveSkillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	req.Name = r.PathValue("name")
+	if err := s.saveSkill(&req); err != nil {
+		if writeInvalidSkill(w, err) {
+			return
+		}
+		slog.Error("save skill", "error", err, "name", req.Name)
+		writeError(w, http.StatusInternalServerError, "saving skill failed")
+		return
+	}
+	s.handleGetSkillAPI(w, r)
+}
+
+// handleUploadSkillFile takes a multipart form with the target "path"
+// (e.g. assets/logo.png) and the "file" itself, stored byte for byte. It
+// answers with the skill's file list.
+func (s *Server) handleUploadSkillFile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSkillFileSize+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "file exceeds 10 MiB")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "expected a multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "missing file")
+		return
+	}
+	defer file.Close()
+	if header.Size > maxSkillFileSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "file exceeds 10 MiB")
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := s.writeSkillFile(name, r.FormValue("path"), file); err != nil {
+		if errors.Is(err, errBadSkillFile) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("upload skill file", "error", err, "name", name)
+		writeError(w, http.StatusInternalServerError, "upload failed")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"files": s.skillFiles(name)})
+}
+
+// handlePreviewSkillAPI validates a SaveSkillRequest body without saving
+// it and returns the SkillPreview.
+func (s *Server) handlePreviewSkillAPI(w http.ResponseWriter, r *http.Request) {
+	var req SaveSkillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	req.Name = r.PathValue("name")
+	preview, err := s.previewSkill(&req)
+	if err != nil {
+		if writeInvalidSkill(w, err) {
+			return
+		}
+		slog.Error("preview skill", "error", err, "name", req.Name)
+		writeError(w, http.StatusInternalServerError, "preview failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
+
+// writeInvalidSkill answers 422 with the validation errors if err is one.
+func writeInvalidSkill(w http.ResponseWriter, err error) bool {
+	var invalid *invalidSkillError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": invalid.Error(), "errors": invalid.errs})
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,311 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/skills"
+)
+
+func newAPIServer(t *testing.T) (*Server, string) {
+	hub := NewHub()
+	go hub.Run()
+	skillsDir := t.TempDir()
+	mgr := core.NewSessionManager(&fakeBackendFactory{backend: &fakeBackend{sessionID: "session-1"}}, nil)
+	s := NewServer(hub, mgr, nil, skills.NewFSSkillStore(skillsDir), skillsDir, "", "", "", "testpass", nil)
+	s.SetAPIToken("secret-token")
+	return s, skillsDir
+}
+
+func apiRequest(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_API_RequiresToken(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s, _ := newAPIServer(t)
+	handler := s.Handler()
+
+	// when
+	missing := apiRequest(handler, http.MethodGet, "/api/sessions", "", "")
+	wrong := apiRequest(handler, http.MethodGet, "/api/sessions", "nope", "")
+	right := apiRequest(handler, http.MethodGet, "/api/sessions", "secret-token", "")
+
+	// then
+	a.Equal(http.StatusUnauthorized, missing.Code)
+	a.Equal(http.StatusUnauthorized, wrong.Code)
+	a.Equal(http.StatusOK, right.Code)
+}
+
+func TestServer_API_Sessions(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s, _ := newAPIServer(t)
+	handler := s.Handler()
+
+	// when
+	created := apiRequest(handler, http.MethodPost, "/api/sessions", "secret-token", "")
+	listed := apiRequest(handler, http.MethodGet, "/api/sessions", "secret-token", "")
+
+	// then
+	r.Equal(http.StatusCreated, created.Code)
+	a.Contains(created.Body.String(), `"sessionID":"session-1"`)
+	r.Equal(http.StatusOK, listed.Code)
+	var body struct {
+		Sessions []core.SessionInfo `json:"sessions"`
+	}
+	r.NoError(json.Unmarshal(listed.Body.Bytes(), &body))
+	r.Len(body.Sessions, 1)
+	a.Equal(ChatSessionKey, body.Sessions[0].Key)
+	a.Equal("session-1", body.Sessions[0].SessionID)
+}
+
+func TestServer_API_SessionsFilter(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... the dashboard session, tagged
+	s, _ := newAPIServer(t)
+	handler := s.Handler()
+	r.Equal(http.StatusCreated, apiRequest(handler, http.MethodPost, "/api/sessions", "secret-token", "").Code)
+	_, err := s.sessionMgr.Tag(ChatSessionKey, []string{"billing"}, nil)
+	r.NoError(err)
+
+	// when
+	tagged := apiRequest(handler, http.MethodGet, "/api/sessions?tag=billing", "secret-token", "")
+	other := apiRequest(handler, http.MethodGet, "/api/sessions?tag=urgent", "secret-token", "")
+
+	// then
+	a.Contains(tagged.Body.String(), `"tags":["billing"]`)
+	a.JSONEq(`{"sessions":[]}`, other.Body.String())
+}
+
+func TestServer_API_PutAndGetSkill(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s, skillsDir := newAPIServer(t)
+	handler := s.Handler()
+	content := "---\nname: deploy\ndescription: Ship it\n---\nRun make deploy."
+
+	// when
+	body, _ := json.Marshal(SaveSkillRequest{Content: content, Files: []SkillFile{{Path: "scripts/run.sh", Content: "make deploy"}}})
+	put := apiRequest(handler, http.MethodPut, "/api/skills/deploy", "secret-token", string(body))
+	get := apiRequest(handler, http.MethodGet, "/api/skills/deploy", "secret-token", "")
+	missing := apiRequest(handler, http.MethodGet, "/api/skills/nope", "secret-token", "")
+
+	// then
+	r.Equal(http.StatusOK, put.Code, put.Body.String())
+	saved, err := os.ReadFile(filepath.Join(skillsDir, "deploy", "SKILL.md"))
+	r.NoError(err)
+	a.Equal(content, string(saved))
+	r.Equal(http.StatusOK, get.Code)
+	var detail SkillDetailEvent
+	r.NoError(json.Unmarshal(get.Body.Bytes(), &detail))
+	a.Equal("deploy", detail.Name)
+	a.Contains(detail.Content, "Run make deploy.")
+	r.Len(detail.Files, 1)
+	a.Equal("scripts/run.sh", detail.Files[0].Path)
+	a.Equal(http.StatusNotFound, missing.Code)
+}
+
+func TestServer_API_RejectsBadSkillAndViewerWrites(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s, _ := newAPIServer(t)
+	s.SetViewerPassword("watchonly")
+	handler := s.Handler()
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("password=watchonly"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	viewerCookie := rec.Result().Cookies()[0]
+
+	// when
+	empty := apiRequest(handler, http.MethodPut, "/api/skills/deploy", "secret-token", `{"content":""}`)
+	viewerReq := httptest.NewRequest(http.MethodPost, "/api/sessions", nil)
+	viewerReq.AddCookie(viewerCookie)
+	viewerRec := httptest.NewRecorder()
+	handler.ServeHTTP(viewerRec, viewerReq)
+	viewerListReq := httptest.NewRequest(http.MethodGet, "/api/skills", nil)
+	viewerListReq.AddCookie(viewerCookie)
+	viewerListRec := httptest.NewRecorder()
+	handler.ServeHTTP(viewerListRec, viewerListReq)
+
+	// then
+	a.Equal(http.StatusUnprocessableEntity, empty.Code)
+	a.Equal(http.StatusForbidden, viewerRec.Code)
+	a.Equal(http.StatusOK, viewerListRec.Code)
+}
+
+func TestServer_API_PreviewSkill(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a skill with a script already on disk
+	s, skillsDir := newAPIServer(t)
+	handler := s.Handler()
+	r.NoError(os.MkdirAll(filepath.Join(skillsDir, "deploy", "scripts"), 0755))
+	r.NoError(os.WriteFile(filepath.Join(skillsDir, "deploy", "scripts", "run.sh"), []byte("make"), 0644))
+	body, _ := json.Marshal(SaveSkillRequest{
+		Content: "---\nname: deploy\ndescription: Ship it\n---\nRun make deploy.",
+		Files:   []SkillFile{{Path: "references/notes.md", Content: "notes"}},
+	})
+
+	// when
+	rec := apiRequest(handler, http.MethodPost, "/api/skills/deploy/preview", "secret-token", string(body))
+	bad := apiRequest(handler, http.MethodPost, "/api/skills/deploy/preview", "secret-token", `{"content":"---\nname: other\ndescription: x\n---\n"}`)
+
+	// then
+	r.Equal(http.StatusOK, rec.Code, rec.Body.String())
+	var preview SkillPreview
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &preview))
+	a.Equal("deploy", preview.Name)
+	a.Equal("Ship it", preview.Description)
+	a.Equal("Run make deploy.", preview.Instructions)
+	r.Len(preview.Files, 2)
+	a.Equal("scripts/run.sh", preview.Files[0].Path)
+	a.Equal("references/notes.md", preview.Files[1].Path)
+	r.Equal(http.StatusUnprocessableEntity, bad.Code)
+	a.Contains(bad.Body.String(), `"field":"name"`)
+	_, err := os.Stat(filepath.Join(skillsDir, "deploy", "SKILL.md"))
+	a.True(os.IsNotExist(err), "preview must not save")
+}
+
+func uploadRequest(t *testing.T, handler http.Handler, skill, path string, data []byte) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("path", path))
+	fw, err := mw.CreateFormFile("file", filepath.Base(path))
+	require.NoError(t, err)
+	fw.Write(data)
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/api/skills/"+skill+"/files", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_API_UploadSkillFile(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s, skillsDir := newAPIServer(t)
+	handler := s.Handler()
+	png := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe, 0x0a}
+
+	// when
+	rec := uploadRequest(t, handler, "deploy", "assets/logo.png", png)
+
+	// then
+	// ... the bytes land unchanged and the file list comes back
+	r.Equal(http.StatusCreated, rec.Code, rec.Body.String())
+	saved, err := os.ReadFile(filepath.Join(skillsDir, "deploy", "assets", "logo.png"))
+	r.NoError(err)
+	a.Equal(png, saved)
+	a.Contains(rec.Body.String(), `"path":"assets/logo.png"`)
+}
+
+func TestServer_API_UploadSkillFile_Rejects(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s, skillsDir := newAPIServer(t)
+	handler := s.Handler()
+
+	// when
+	escape := uploadRequest(t, handler, "deploy", "../../etc/passwd", []byte("x"))
+	topLevel := uploadRequest(t, handler, "deploy", "SKILL.md", []byte("x"))
+	tooBig := uploadRequest(t, handler, "deploy", "assets/big.bin", make([]byte, maxSkillFileSize+1))
+
+	// then
+	a.Equal(http.StatusBadRequest, escape.Code)
+	a.Equal(http.StatusBadRequest, topLevel.Code)
+	a.Equal(http.StatusRequestEntityTooLarge, tooBig.Code)
+	_, err := os.Stat(filepath.Join(skillsDir, "deploy"))
+	a.True(os.IsNotExist(err))
+}
+
+func TestServer_API_NewSkill(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s, _ := newAPIServer(t)
+	handler := s.Handler()
+
+	// when
+	created := apiRequest(handler, http.MethodPost, "/api/skills", "secret-token", `{"name":"deploy","description":"Ship it"}`)
+	again := apiRequest(handler, http.MethodPost, "/api/skills", "secret-token", `{"name":"deploy"}`)
+
+	// then
+	// ... the scaffold comes back for the editor; a second create is refused
+	r.Equal(http.StatusCreated, created.Code, created.Body.String())
+	var detail SkillDetailEvent
+	r.NoError(json.Unmarshal(created.Body.Bytes(), &detail))
+	a.Equal("deploy", detail.Name)
+	a.Contains(detail.Content, "description: Ship it")
+	r.Len(detail.Files, 2)
+	a.Equal(http.StatusUnprocessableEntity, again.Code)
+	a.Contains(again.Body.String(), "already exists")
+}
+
+type stubBroadcaster struct{ texts []string }
+
+func (b *stubBroadcaster) Broadcast(text string) (int, int) {
+	b.texts = append(b.texts, text)
+	return 3, 1
+}
+
+func TestServer_API_Broadcast(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s, _ := newAPIServer(t)
+	handler := s.Handler()
+	unavailable := apiRequest(handler, http.MethodPost, "/api/broadcast", "secret-token", `{"text":"down at noon"}`)
+	b := &stubBroadcaster{}
+	s.SetBroadcaster(b)
+
+	// when
+	sent := apiRequest(handler, http.MethodPost, "/api/broadcast", "secret-token", `{"text":" down at noon "}`)
+	empty := apiRequest(handler, http.MethodPost, "/api/broadcast", "secret-token", `{"text":"  "}`)
+
+	// then
+	a.Equal(http.StatusServiceUnavailable, unavailable.Code)
+	r.Equal(http.StatusOK, sent.Code, sent.Body.String())
+	var e BroadcastEvent
+	r.NoError(json.Unmarshal(sent.Body.Bytes(), &e))
+	a.Equal(BroadcastEvent{Sent: 3, Failed: 1}, e)
+	a.Equal(http.StatusBadRequest, empty.Code)
+	a.Equal([]string{"down at noon"}, b.texts)
+}
@@ -2,15 +2,17 @@ package dashboard
 
 import (
 	"embed"
+	"encoding/json"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"sync"
-	"time"
 
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/metrics"
 	"github.com/TheLazyLemur/switchboard/internal/skills"
+	"github.com/TheLazyLemur/switchboard/internal/version"
 	"github.com/gorilla/websocket"
 )
 
@@ -29,11 +31,17 @@ type Server struct {
 	agentsDefaultPath string
 	memoryDir         string
 	password          string
+	viewerPassword    string // protected by mu
+	apiToken          string // protected by mu
 	chatCallback      func(sessionID, text string)
+	metrics           *metrics.Store  // protected by mu
+	releases          core.Releases   // protected by mu
+	whatsApp          WhatsAppPairing // protected by mu
+	broadcaster       Broadcaster     // protected by mu
 
 	mu            sync.Mutex
-	sessions      map[string]time.Time // valid session tokens
-	lastSessionID string               // protected by mu
+	sessions      map[string]authSession // valid session tokens
+	lastSessionID string                 // protected by mu
 }
 
 // NewServer creates a dashboard server. chatCallback is required; it is invoked
@@ -51,7 +59,7 @@ func NewServer(hub *Hub, sessionMgr *core.SessionManager, permChecker core.Permi
 		memoryDir:         memoryDir,
 		password:          password,
 		chatCallback:      chatCallback,
-		sessions:          make(map[string]time.Time),
+		sessions:          make(map[string]authSession),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
@@ -76,6 +84,25 @@ func (s *Server) SetChatCallback(cb func(sessionID, text string)) {
 	s.mu.Unlock()
 }
 
+// SetReleases names the build in the footer and flags a newer release.
+// Without it the footer shows the build alone.
+func (s *Server) SetReleases(r core.Releases) {
+	s.mu.Lock()
+	s.releases = r
+	s.mu.Unlock()
+}
+
+func (s *Server) versionEvent() VersionEvent {
+	s.mu.Lock()
+	r := s.releases
+	s.mu.Unlock()
+	if r == nil {
+		return VersionEvent{Version: version.Get().String()}
+	}
+	latest, _ := r.Newer()
+	return VersionEvent{Version: r.Current(), Latest: latest}
+}
+
 // Handler returns the HTTP handler for the dashboard.
 func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
@@ -124,6 +151,17 @@ func (s *Server) Handler() http.Handler {
 		w.Write(data)
 	})
 
+	// The schema is static and holds nothing private, so frontends can be
+	// built against it without logging in.
+	mux.HandleFunc("/api/schema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ProtocolSchema())
+	})
+
+	mux.HandleFunc("/api/analytics", s.handleAnalytics)
+	mux.HandleFunc("/api/analytics.csv", s.handleAnalyticsCSV)
+	s.routeAPI(mux)
+
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		if !s.isAuthenticated(r) {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
@@ -142,13 +180,17 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	session, _ := s.authSession(r)
 	client := &Client{
-		hub:  s.hub,
-		conn: conn,
-		send: make(chan []byte, 256),
+		hub:    s.hub,
+		conn:   conn,
+		send:   make(chan []byte, 256),
+		viewer: session.viewer,
 	}
 
 	s.hub.register <- client
+	client.Send(RoleEvent{Role: session.role()})
+	client.Send(s.versionEvent())
 
 	go client.writePump()
 	go client.readPump(s.handleMessage)
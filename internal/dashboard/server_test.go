@@ -1,8 +1,11 @@
 package dashboard
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -133,3 +136,103 @@ func TestServer_CheckOrigin_RejectsEmptyOrigin(t *testing.T) {
 
 	assert.False(t, s.upgrader.CheckOrigin(req))
 }
+
+func TestServer_Login_ViewerPassword(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	s := NewServer(nil, nil, nil, nil, "", "", "", "", "testpass", nil)
+	s.SetViewerPassword("watchonly")
+	handler := s.Handler()
+
+	// when
+	login := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("password="+password))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	viewerRec, adminRec := login("watchonly"), login("testpass")
+
+	// then
+	// ... both log in, only the viewer password gives a viewer session
+	r.Equal(http.StatusOK, viewerRec.Code)
+	r.Equal(http.StatusOK, adminRec.Code)
+	viewerReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	viewerReq.AddCookie(viewerRec.Result().Cookies()[0])
+	adminReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	adminReq.AddCookie(adminRec.Result().Cookies()[0])
+	viewer, ok := s.authSession(viewerReq)
+	a.True(ok)
+	a.Equal("viewer", viewer.role())
+	admin, ok := s.authSession(adminReq)
+	a.True(ok)
+	a.Equal("admin", admin.role())
+}
+
+func TestServer_Login_ViewerPasswordUnsetRejected(t *testing.T) {
+	s := NewServer(nil, nil, nil, nil, "", "", "", "", "testpass", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("password="))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestHandleMessage_ViewerCannotEdit(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a viewer client and an existing AGENTS.md
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("original"), 0o644))
+	s := NewServer(nil, nil, nil, nil, "", dir, "", "", "testpass", nil)
+	client := &Client{send: make(chan []byte, 4), viewer: true}
+
+	// when
+	s.handleMessage(client, &SaveAgentsMdRequest{Content: "defaced"})
+	s.handleMessage(client, &GetAgentsMdRequest{})
+
+	// then
+	// ... the save is refused with a warning and reads still work
+	data, err := os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	r.NoError(err)
+	a.Equal("original", string(data))
+	r.Len(client.send, 2)
+	var refused LogEvent
+	var read AgentsMdEvent
+	r.NoError(json.Unmarshal(<-client.send, &refused))
+	r.NoError(json.Unmarshal(<-client.send, &read))
+	a.Contains(refused.Msg, "read-only viewer")
+	a.Equal("original", read.Content)
+}
+
+func TestHandleMessage_SaveSkillRejectsInvalidFrontmatter(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	s := NewServer(nil, nil, nil, nil, dir, "", "", "", "testpass", nil)
+	client := &Client{send: make(chan []byte, 4)}
+
+	// when
+	s.handleMessage(client, &SaveSkillRequest{Name: "deploy", Content: "---\nname: deploy\n---\nno description"})
+
+	// then
+	// ... nothing is written and the client gets the structured error
+	_, err := os.Stat(filepath.Join(dir, "deploy"))
+	a.True(os.IsNotExist(err))
+	r.Len(client.send, 1)
+	var ev SkillInvalidEvent
+	r.NoError(json.Unmarshal(<-client.send, &ev))
+	a.Equal("deploy", ev.Name)
+	r.Len(ev.Errors, 1)
+	a.Equal("description", ev.Errors[0].Field)
+	a.Equal("missing required field: description", ev.Errors[0].Message)
+}
@@ -0,0 +1,132 @@
+package dashboard
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// maxShares bounds how many live pages are held in memory; the oldest is
+// dropped to make room.
+const maxShares = 200
+
+var _ core.Sharer = (*ShareStore)(nil)
+
+type sharePage struct {
+	html    []byte
+	created time.Time
+	expires time.Time
+}
+
+// ShareStore renders /share transcripts to static HTML and serves them at
+// /share/<token>. The token is 128 random bits, so the link is the only
+// credential. Pages live in memory and do not survive a restart.
+type ShareStore struct {
+	mu      sync.Mutex
+	pages   map[string]sharePage
+	baseURL string
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewShareStore returns a store whose links start with baseURL, the public
+// address of the dashboard server, and expire after ttl.
+func NewShareStore(baseURL string, ttl time.Duration) *ShareStore {
+	return &ShareStore{
+		pages:   make(map[string]sharePage),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+func (s *ShareStore) Share(entries []core.TranscriptEntry) (string, time.Time, error) {
+	var tok [16]byte
+	if _, err := rand.Read(tok[:]); err != nil {
+		return "", time.Time{}, errors.Wrap(err, "generating share token")
+	}
+	token := hex.EncodeToString(tok[:])
+
+	now := s.now()
+	var buf bytes.Buffer
+	if err := shareTemplate.Execute(&buf, map[string]any{"Created": now.UTC().Format("2006-01-02 15:04 UTC"), "Entries": entries}); err != nil {
+		return "", time.Time{}, errors.Wrap(err, "rendering transcript")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked(now)
+	page := sharePage{html: buf.Bytes(), created: now, expires: now.Add(s.ttl)}
+	s.pages[token] = page
+	return s.baseURL + "/share/" + token, page.expires, nil
+}
+
+// pruneLocked drops expired pages and, if still full, the oldest one.
+func (s *ShareStore) pruneLocked(now time.Time) {
+	var oldest string
+	for tok, p := range s.pages {
+		if !now.Before(p.expires) {
+			delete(s.pages, tok)
+			continue
+		}
+		if oldest == "" || p.created.Before(s.pages[oldest].created) {
+			oldest = tok
+		}
+	}
+	if len(s.pages) >= maxShares {
+		delete(s.pages, oldest)
+	}
+}
+
+func (s *ShareStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/share/")
+	s.mu.Lock()
+	page, ok := s.pages[token]
+	if ok && !s.now().Before(page.expires) {
+		delete(s.pages, token)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Write(page.html)
+}
+
+var shareTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Switchboard transcript</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.entry { margin: 1rem 0; padding: .75rem 1rem; border-radius: 6px; }
+.user { background: #eef3ff; }
+.assistant { background: #f6f6f6; }
+.tool { background: #fff8e6; font-size: .85rem; }
+.role { font-weight: 600; font-size: .75rem; text-transform: uppercase; color: #666; }
+pre { white-space: pre-wrap; word-wrap: break-word; margin: .25rem 0 0; font-family: ui-monospace, monospace; }
+</style>
+</head>
+<body>
+<h1>Switchboard transcript</h1>
+<p>Shared {{.Created}}</p>
+{{range .Entries}}<div class="entry {{.Role}}"><div class="role">{{.Role}}</div><pre>{{.Text}}</pre></div>
+{{end}}</body>
+</html>
+`))
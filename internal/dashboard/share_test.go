@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareStore_ServesEscapedPageUntilExpiry(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a store with a one-hour TTL and a controllable clock
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewShareStore("https://bot.example/", time.Hour)
+	s.now = func() time.Time { return now }
+
+	// when
+	// ... a transcript containing markup is shared
+	url, expires, err := s.Share([]core.TranscriptEntry{{Role: "user", Text: "<script>alert(1)</script>"}})
+	r.NoError(err)
+	path := strings.TrimPrefix(url, "https://bot.example")
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	live := get()
+	now = now.Add(time.Hour)
+	expired := get()
+
+	// then
+	// ... the link has a 32-hex-char token, the page escapes the text, and it expires
+	a.Regexp(`^https://bot\.example/share/[0-9a-f]{32}$`, url)
+	a.Equal(now, expires)
+	a.Equal(http.StatusOK, live.Code)
+	a.Contains(live.Body.String(), "&lt;script&gt;alert(1)&lt;/script&gt;")
+	a.NotContains(live.Body.String(), "<script>")
+	a.Equal("noindex", live.Header().Get("X-Robots-Tag"))
+	a.Equal(http.StatusNotFound, expired.Code)
+}
+
+func TestShareStore_UnknownToken(t *testing.T) {
+	// given
+	s := NewShareStore("http://localhost:5005", time.Hour)
+	rec := httptest.NewRecorder()
+
+	// when
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/share/deadbeef", nil))
+
+	// then
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
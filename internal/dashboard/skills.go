@@ -1,44 +1,60 @@
 package dashboard
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/TheLazyLemur/switchboard/internal/core"
 	"github.com/TheLazyLemur/switchboard/internal/skills"
+	"github.com/pkg/errors"
 )
 
 func (s *Server) handleGetSkills(client *Client) {
-	skillList, err := s.skillStore.List()
+	infos, err := s.listSkills()
 	if err != nil {
 		slog.Error("list skills", "error", err)
 		return
 	}
+	client.Send(SkillsEvent{Skills: infos})
+}
+
+func (s *Server) listSkills() ([]SkillInfo, error) {
+	skillList, err := s.skillStore.List()
+	if err != nil {
+		return nil, err
+	}
 
-	var infos []SkillInfo
+	infos := []SkillInfo{}
 	for _, sk := range skillList {
 		infos = append(infos, SkillInfo{
 			Name:        sk.Name,
 			Description: sk.Description,
 		})
 	}
-
-	client.Send(Message{
-		Type:   "skills",
-		Skills: infos,
-	})
+	return infos, nil
 }
 
 func (s *Server) handleGetSkill(client *Client, name string) {
-	skill, err := s.skillStore.Load(name)
+	detail, err := s.skillDetail(name)
 	if err != nil {
 		slog.Error("load skill", "error", err, "name", name)
 		return
 	}
+	client.Send(detail)
+}
+
+func (s *Server) skillDetail(name string) (SkillDetailEvent, error) {
+	skill, err := s.skillStore.Load(name)
+	if err != nil {
+		return SkillDetailEvent{}, err
+	}
 
 	skillDir := filepath.Join(s.skillsDir, name)
-	var files []SkillFile
+	files := []SkillFile{}
 
 	for _, subdir := range []string{"scripts", "references", "assets"} {
 		dir := filepath.Join(skillDir, subdir)
@@ -61,40 +77,153 @@ func (s *Server) handleGetSkill(client *Client, name string) {
 		}
 	}
 
-	content := formatSkillContent(skill)
-
-	client.Send(Message{
-		Type:    "skill_detail",
+	return SkillDetailEvent{
 		Name:    name,
-		Content: content,
-		Files:   files,
-	})
+		Content: formatSkillContent(skill),
+		Files:   s.skillFiles(name),
+	}, nil
+}
+
+func (s *Server) skillFiles(name string) []SkillFile {
+	skillDir := filepath.Join(s.skillsDir, name)
+	files := []SkillFile{}
+
+	for _, subdir := range []string{"scripts", "references", "assets"} {
+		dir := filepath.Join(skillDir, subdir)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			files = append(files, SkillFile{
+				Path: filepath.Join(subdir, e.Name()),
+				Size: info.Size(),
+			})
+		}
+	}
+	return files
+}
+
+// previewSkill renders req as the bot would load it once saved: the
+// parsed SKILL.md plus the files already on disk and those being uploaded.
+func (s *Server) previewSkill(req *SaveSkillRequest) (SkillPreview, error) {
+	skill, err := s.validateSkill(req.Name, req.Content)
+	if err != nil {
+		return SkillPreview{}, err
+	}
+	files := s.skillFiles(req.Name)
+	seen := make(map[string]bool)
+	for _, f := range files {
+		seen[f.Path] = true
+	}
+	for _, f := range req.Files {
+		if validateRelativePath(f.Path) != nil || seen[f.Path] {
+			continue
+		}
+		seen[f.Path] = true
+		files = append(files, SkillFile{Path: f.Path, Size: int64(len(f.Content))})
+	}
+	return SkillPreview{
+		Name:         skill.Name,
+		Description:  skill.Description,
+		Instructions: skill.Instructions,
+		Files:        files,
+	}, nil
 }
 
 func formatSkillContent(skill *skills.Skill) string {
 	return "---\nname: " + skill.Name + "\ndescription: " + skill.Description + "\n---\n" + skill.Instructions
 }
 
-func (s *Server) handleSaveSkill(client *Client, msg Message) {
-	if msg.Name == "" || msg.Content == "" {
+func (s *Server) handleNewSkill(client *Client, req *NewSkillRequest) {
+	detail, err := s.newSkill(req)
+	if err != nil {
+		var invalid *invalidSkillError
+		if errors.As(err, &invalid) {
+			client.Send(SkillInvalidEvent{Name: req.Name, Errors: invalid.errs})
+			return
+		}
+		slog.Error("new skill", "error", err, "name", req.Name)
 		return
 	}
+	s.handleGetSkills(client)
+	client.Send(detail)
+}
 
-	if strings.Contains(msg.Name, "..") || strings.Contains(msg.Name, "/") {
-		slog.Error("invalid skill name", "name", msg.Name)
+// newSkill scaffolds req's skill if the store supports it and returns the
+// result for the editor.
+func (s *Server) newSkill(req *NewSkillRequest) (SkillDetailEvent, error) {
+	scaffolder, ok := s.skillStore.(core.SkillScaffolder)
+	if !ok {
+		return SkillDetailEvent{}, errors.New("skill store cannot create skills")
+	}
+	if _, err := scaffolder.Scaffold(req.Name, req.Description, ""); err != nil {
+		return SkillDetailEvent{}, &invalidSkillError{[]SkillError{{Field: "name", Message: err.Error()}}}
+	}
+	slog.Info("skill created", "name", req.Name)
+	return s.skillDetail(req.Name)
+}
+
+func (s *Server) handleSaveSkill(client *Client, msg *SaveSkillRequest) {
+	if err := s.saveSkill(msg); err != nil {
+		var invalid *invalidSkillError
+		if errors.As(err, &invalid) {
+			client.Send(SkillInvalidEvent{Name: msg.Name, Errors: invalid.errs})
+			return
+		}
+		slog.Error("save skill", "error", err, "name", msg.Name)
 		return
 	}
+	s.handleGetSkills(client)
+}
+
+// invalidSkillError is saveSkill's error for content that would not load.
+type invalidSkillError struct {
+	errs []SkillError
+}
+
+func (e *invalidSkillError) Error() string { return "invalid skill: " + e.errs[0].Message }
+
+// validateSkill parses content the way the skill store will, and checks its
+// name matches the directory it is saved under.
+func (s *Server) validateSkill(name, content string) (*skills.Skill, error) {
+	if name == "" || strings.Contains(name, "..") || strings.Contains(name, "/") {
+		return nil, &invalidSkillError{[]SkillError{{Field: "name", Message: fmt.Sprintf("invalid skill name %q", name)}}}
+	}
+	skill, err := skills.ParseSkill(content, filepath.Join(s.skillsDir, name, "SKILL.md"))
+	var verr *skills.ValidationError
+	if errors.As(err, &verr) {
+		return nil, &invalidSkillError{[]SkillError{{Field: verr.Field, Message: verr.Message}}}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if skill.Name != name {
+		return nil, &invalidSkillError{[]SkillError{{Field: "name", Message: fmt.Sprintf("name %q must match the skill directory %q", skill.Name, name)}}}
+	}
+	return skill, nil
+}
+
+func (s *Server) saveSkill(msg *SaveSkillRequest) error {
+	if _, err := s.validateSkill(msg.Name, msg.Content); err != nil {
+		return err
+	}
 
 	skillDir := filepath.Join(s.skillsDir, msg.Name)
 	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		slog.Error("create skill dir", "error", err)
-		return
+		return errors.Wrap(err, "creating skill dir")
 	}
 
 	skillPath := filepath.Join(skillDir, "SKILL.md")
 	if err := os.WriteFile(skillPath, []byte(msg.Content), 0644); err != nil {
-		slog.Error("write skill", "error", err)
-		return
+		return errors.Wrap(err, "writing skill")
 	}
 
 	for _, f := range msg.Files {
@@ -111,8 +240,7 @@ func (s *Server) handleSaveSkill(client *Client, msg Message) {
 	}
 
 	slog.Info("skill saved", "name", msg.Name)
-
-	s.handleGetSkills(client)
+	return nil
 }
 
 func (s *Server) handleDeleteSkillFile(client *Client, name, path string) {
@@ -144,6 +272,46 @@ func (s *Server) handleDeleteSkillFile(client *Client, name, path string) {
 	s.handleGetSkill(client, name)
 }
 
+// maxSkillFileSize caps one uploaded supporting file.
+const maxSkillFileSize = 10 << 20
+
+// skillFileDirs are the subdirectories a skill's supporting files live in.
+var skillFileDirs = map[string]bool{"scripts": true, "references": true, "assets": true}
+
+// writeSkillFile streams src to path inside skill name's directory,
+// creating it if the skill hasn't been saved yet.
+func (s *Server) writeSkillFile(name, path string, src io.Reader) error {
+	if name == "" || strings.Contains(name, "..") || strings.Contains(name, "/") {
+		return errors.Wrapf(errBadSkillFile, "invalid skill name %q", name)
+	}
+	dir, _, _ := strings.Cut(filepath.ToSlash(path), "/")
+	if validateRelativePath(path) != nil || !skillFileDirs[dir] || filepath.Base(path) == dir {
+		return errors.Wrapf(errBadSkillFile, "path %q must be a file under scripts/, references/ or assets/", path)
+	}
+
+	filePath := filepath.Join(s.skillsDir, name, path)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return errors.Wrap(err, "creating skill dir")
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrap(err, "creating skill file")
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		os.Remove(filePath)
+		return errors.Wrap(err, "writing skill file")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "writing skill file")
+	}
+	slog.Info("skill file uploaded", "name", name, "path", path)
+	return nil
+}
+
+// errBadSkillFile marks an upload refused for its name or path.
+var errBadSkillFile = errors.New("bad skill file")
+
 func validateRelativePath(p string) error {
 	if filepath.IsAbs(p) {
 		return os.ErrInvalid
@@ -7,6 +7,7 @@ let ws = null;
 let reconnectTimer = null;
 let pendingPermission = null;
 let currentSkill = null;
+let savingSkill = false;
 let skillFiles = [];
 
 // DOM elements
@@ -15,6 +16,8 @@ const chatInput = document.getElementById('chatInput');
 const sendBtn = document.getElementById('sendBtn');
 const sessionInfo = document.getElementById('sessionInfo');
 const sessionID = document.getElementById('sessionID');
+const versionInfo = document.getElementById('versionInfo');
+const versionLatest = document.getElementById('versionLatest');
 const typingIndicator = document.getElementById('typingIndicator');
 const logsContainer = document.getElementById('logsContainer');
 const clearLogsBtn = document.getElementById('clearLogsBtn');
@@ -57,6 +60,31 @@ const closeMemoryBtn = document.getElementById('closeMemoryBtn');
 const cancelMemoryBtn = document.getElementById('cancelMemoryBtn');
 const saveMemoryBtn = document.getElementById('saveMemoryBtn');
 
+// Analytics modal
+const openAnalyticsBtn = document.getElementById('openAnalyticsBtn');
+const analyticsModal = document.getElementById('analyticsModal');
+const analyticsBody = document.getElementById('analyticsBody');
+const analyticsDays = document.getElementById('analyticsDays');
+const analyticsCsvLink = document.getElementById('analyticsCsvLink');
+const closeAnalyticsBtn = document.getElementById('closeAnalyticsBtn');
+
+// WhatsApp modal
+const openWhatsAppBtn = document.getElementById('openWhatsAppBtn');
+const whatsAppModal = document.getElementById('whatsAppModal');
+const whatsAppBody = document.getElementById('whatsAppBody');
+const closeWhatsAppBtn = document.getElementById('closeWhatsAppBtn');
+const refreshWhatsAppBtn = document.getElementById('refreshWhatsAppBtn');
+const repairWhatsAppBtn = document.getElementById('repairWhatsAppBtn');
+
+// Broadcast modal
+const openBroadcastBtn = document.getElementById('openBroadcastBtn');
+const broadcastModal = document.getElementById('broadcastModal');
+const broadcastText = document.getElementById('broadcastText');
+const broadcastResult = document.getElementById('broadcastResult');
+const closeBroadcastBtn = document.getElementById('closeBroadcastBtn');
+const cancelBroadcastBtn = document.getElementById('cancelBroadcastBtn');
+const sendBroadcastBtn = document.getElementById('sendBroadcastBtn');
+
 let currentMemoryPath = null;
 let memoryFilesCache = [];
 
@@ -70,6 +98,7 @@ const skillFilesTab = document.getElementById('skillFilesTab');
 const skillFilesList = document.getElementById('skillFilesList');
 const fileUploadInput = document.getElementById('fileUploadInput');
 const saveSkillBtn = document.getElementById('saveSkillBtn');
+const skillErrors = document.getElementById('skillErrors');
 const cancelSkillBtn = document.getElementById('cancelSkillBtn');
 const skillTabs = document.querySelectorAll('.skill-tab');
 
@@ -99,6 +128,7 @@ function connect() {
     for (const data of messages) {
       try {
         const msg = JSON.parse(data);
+        if (msg.v !== PROTOCOL_VERSION) console.warn(`protocol v${msg.v}, expected v${PROTOCOL_VERSION}`);
         handleMessage(msg);
       } catch (e) {
         console.error('Parse error', e);
@@ -115,15 +145,28 @@ function scheduleReconnect() {
   }, 2000);
 }
 
+// PROTOCOL_VERSION must match dashboard.ProtocolVersion; see /api/schema.
+const PROTOCOL_VERSION = 1;
+
 function send(msg) {
   if (ws && ws.readyState === WebSocket.OPEN) {
-    ws.send(JSON.stringify(msg));
+    ws.send(JSON.stringify({ ...msg, v: PROTOCOL_VERSION }));
   }
 }
 
 // Message handlers
 function handleMessage(msg) {
   switch (msg.type) {
+    case 'role':
+      setViewer(msg.role === 'viewer');
+      break;
+
+    case 'version':
+      versionInfo.textContent = msg.version;
+      versionLatest.textContent = msg.latest ? `· ${msg.latest} available` : '';
+      versionLatest.classList.toggle('hidden', !msg.latest);
+      break;
+
     case 'log':
       addLog(msg.level, msg.msg, msg.time);
       break;
@@ -146,25 +189,51 @@ function handleMessage(msg) {
 
     case 'skills':
       renderSkillsList(msg.skills || []);
+      // A save is confirmed by the refreshed list.
+      if (savingSkill) hideSkillModal();
       break;
 
+    case 'skill_invalid': {
+      savingSkill = false;
+      const text = (msg.errors || []).map(e => `${e.field}: ${e.message}`).join('; ');
+      if (!currentSkill) {
+        addLog('ERROR', `skill ${msg.name}: ${text}`);
+        break;
+      }
+      skillErrors.textContent = text;
+      switchSkillTab('content');
+      break;
+    }
+
     case 'skill_detail':
       showSkillEditor(msg.name, msg.content, msg.files || []);
       break;
 
     case 'whatsapp_qr':
       handleWhatsAppQR(msg.content);
+      // A finished pairing changes what the WhatsApp panel shows.
+      if (msg.content === 'success' && !whatsAppModal.classList.contains('hidden')) {
+        send({ type: 'get_whatsapp' });
+      }
+      break;
+
+    case 'whatsapp_status':
+      renderWhatsApp(msg);
+      break;
+
+    case 'broadcast':
+      renderBroadcast(msg);
       break;
 
     case 'agents_md':
       agentsMdContent.value = msg.content || '';
-      if (msg.msg) addLog('ERROR', 'AGENTS.md: ' + msg.msg);
+      if (msg.error) addLog('ERROR', 'AGENTS.md: ' + msg.error);
       break;
 
     case 'memory_list':
       memoryFilesCache = (msg.files || []).map(f => f.path);
       renderMemoryFiles();
-      if (msg.msg) addLog('ERROR', 'memory: ' + msg.msg);
+      if (msg.error) addLog('ERROR', 'memory: ' + msg.error);
       break;
 
     case 'memory_file':
@@ -172,11 +241,19 @@ function handleMessage(msg) {
       memoryCurrentPath.textContent = msg.path;
       memoryContent.value = msg.content || '';
       deleteMemoryFileBtn.classList.remove('hidden');
-      if (msg.msg) addLog('ERROR', 'memory: ' + msg.msg);
+      if (msg.error) addLog('ERROR', 'memory: ' + msg.error);
       break;
   }
 }
 
+// Viewers can watch but not chat or edit; the server enforces it too.
+function setViewer(viewer) {
+  document.body.classList.toggle('viewer', viewer);
+  for (const ta of [skillContent, agentsMdContent, memoryContent]) {
+    ta.readOnly = viewer;
+  }
+}
+
 // Chat
 function addChatMessage(role, content) {
   const div = document.createElement('div');
@@ -305,18 +382,10 @@ function newSkill() {
     return;
   }
 
-  // Show editor with template
-  const template = `---
-name: ${name}
-description: Description here
----
-
-# ${name}
-
-Instructions for the skill...
-`;
-
-  showSkillEditor(name, template, []);
+  // The server scaffolds SKILL.md plus example scripts/ and references/
+  // files, then opens the result in the editor.
+  const description = prompt('Description (optional):') || '';
+  send({ type: 'new_skill', name, description });
 }
 
 function showSkillEditor(name, content, files) {
@@ -325,6 +394,7 @@ function showSkillEditor(name, content, files) {
 
   skillModalTitle.textContent = `Edit: ${name}`;
   skillContent.value = content;
+  skillErrors.textContent = '';
 
   renderSkillFiles(files);
   switchSkillTab('content');
@@ -342,7 +412,7 @@ function renderSkillFiles(files) {
         <div class="text-sm text-zinc-100">${escapeHtml(f.path)}</div>
         <div class="text-xs text-zinc-500">${formatBytes(f.size)}</div>
       </div>
-      <button class="delete-file-btn text-zinc-500 hover:text-red-400 transition-colors" data-path="${escapeHtml(f.path)}">
+      <button class="delete-file-btn edit-only text-zinc-500 hover:text-red-400 transition-colors" data-path="${escapeHtml(f.path)}">
         <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
           <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M19 7l-.867 12.142A2 2 0 0116.138 21H7.862a2 2 0 01-1.995-1.858L5 7m5 4v6m4-6v6m1-10V4a1 1 0 00-1-1h-4a1 1 0 00-1 1v3M4 7h16"/>
         </svg>
@@ -367,6 +437,7 @@ function hideSkillModal() {
   skillModal.classList.add('hidden');
   currentSkill = null;
   skillFiles = [];
+  savingSkill = false;
 }
 
 function saveSkill() {
@@ -377,7 +448,8 @@ function saveSkill() {
     content: skillContent.value,
     files: skillFiles
   });
-  hideSkillModal();
+  savingSkill = true;
+  skillErrors.textContent = '';
 }
 
 function deleteSkillFile(path) {
@@ -386,35 +458,32 @@ function deleteSkillFile(path) {
   send({ type: 'delete_skill_file', name: currentSkill, path });
 }
 
-function handleFileUpload(files) {
+// Uploads go over HTTP as multipart so binary assets survive intact.
+async function handleFileUpload(files) {
+  if (!currentSkill) return;
   for (const file of files) {
-    const reader = new FileReader();
-    reader.onload = (e) => {
-      // Determine subdir based on extension
-      let subdir = 'assets';
-      if (file.name.endsWith('.sh') || file.name.endsWith('.py') || file.name.endsWith('.js')) {
-        subdir = 'scripts';
-      } else if (file.name.endsWith('.md') || file.name.endsWith('.txt') || file.name.endsWith('.json')) {
-        subdir = 'references';
-      }
+    // Determine subdir based on extension
+    let subdir = 'assets';
+    if (file.name.endsWith('.sh') || file.name.endsWith('.py') || file.name.endsWith('.js')) {
+      subdir = 'scripts';
+    } else if (file.name.endsWith('.md') || file.name.endsWith('.txt') || file.name.endsWith('.json')) {
+      subdir = 'references';
+    }
 
-      skillFiles.push({
-        path: `${subdir}/${file.name}`,
-        content: e.target.result
-      });
-
-      // Add to UI
-      const div = document.createElement('div');
-      div.className = 'flex items-center justify-between bg-zinc-700 rounded px-3 py-2';
-      div.innerHTML = `
-        <div>
-          <div class="text-sm text-zinc-100">${subdir}/${escapeHtml(file.name)}</div>
-          <div class="text-xs text-emerald-400">New</div>
-        </div>
-      `;
-      skillFilesList.appendChild(div);
-    };
-    reader.readAsText(file);
+    const form = new FormData();
+    form.append('path', `${subdir}/${file.name}`);
+    form.append('file', file);
+    try {
+      const res = await fetch(`/api/skills/${encodeURIComponent(currentSkill)}/files`, { method: 'POST', body: form });
+      const body = await res.json();
+      if (!res.ok) {
+        skillErrors.textContent = `${file.name}: ${body.error}`;
+        continue;
+      }
+      renderSkillFiles(body.files || []);
+    } catch (err) {
+      skillErrors.textContent = `${file.name}: upload failed`;
+    }
   }
 }
 
@@ -488,6 +557,157 @@ function deleteMemory() {
   deleteMemoryFileBtn.classList.add('hidden');
 }
 
+// Analytics
+function openAnalytics() {
+  analyticsModal.classList.remove('hidden');
+  loadAnalytics();
+}
+
+function hideAnalytics() {
+  analyticsModal.classList.add('hidden');
+}
+
+function loadAnalytics() {
+  const days = analyticsDays.value;
+  analyticsCsvLink.href = `/api/analytics.csv?days=${days}`;
+  analyticsBody.textContent = 'Loading...';
+  fetch(`/api/analytics?days=${days}`).then(r => {
+    if (r.status === 404) throw new Error('Usage tracking is disabled.');
+    if (!r.ok) throw new Error(`Failed to load usage (${r.status}).`);
+    return r.json();
+  }).then(renderAnalytics).catch((e) => { analyticsBody.textContent = e.message; });
+}
+
+function renderAnalytics(rep) {
+  const n = (v) => Number(v).toLocaleString();
+  const ms = (v) => v >= 1000 ? (v / 1000).toFixed(1) + ' s' : Math.round(v) + ' ms';
+  const table = (headers, rows) => `
+    <table class="w-full text-left">
+      <thead><tr class="text-xs text-zinc-500">${headers.map(h => `<th class="py-1 pr-4 font-normal">${h}</th>`).join('')}</tr></thead>
+      <tbody>${rows.map(r => `<tr class="border-t border-zinc-800">${r.map(c => `<td class="py-1 pr-4">${c}</td>`).join('')}</tr>`).join('')}</tbody>
+    </table>`;
+  const stat = (label, value) => `
+    <div class="bg-zinc-950 border border-zinc-800 rounded p-3">
+      <div class="text-xs text-zinc-500">${label}</div>
+      <div class="text-lg">${value}</div>
+    </div>`;
+
+  analyticsBody.innerHTML = `
+    <div class="grid grid-cols-7 gap-3">
+      ${stat('Turns', n(rep.turns))}
+      ${stat('Input tokens', n(rep.input_tokens))}
+      ${stat('Output tokens', n(rep.output_tokens))}
+      ${stat('Thinking tokens', n(rep.thinking_tokens))}
+      ${stat('Error rate', (rep.error_rate * 100).toFixed(1) + '%')}
+      ${stat('Avg latency', ms(rep.avg_latency_ms))}
+      ${stat('Satisfaction', rep.thumbs_up + rep.thumbs_down ? `${(rep.satisfaction * 100).toFixed(0)}% <span class="text-xs text-zinc-500">of ${n(rep.thumbs_up + rep.thumbs_down)}</span>` : '-')}
+    </div>
+    <div>
+      <h4 class="text-xs font-semibold text-zinc-400 mb-2">DAILY</h4>
+      ${table(['Day', 'Turns', 'Failed', 'Input tokens', 'Output tokens', 'Thinking tokens', 'Tool calls', 'Avg latency', '👍', '👎'],
+        rep.days.map(d => [d.day, n(d.turns), n(d.failed), n(d.input_tokens), n(d.output_tokens), n(d.thinking_tokens), n(d.tool_calls), ms(d.avg_latency_ms), n(d.thumbs_up), n(d.thumbs_down)]))}
+    </div>
+    <div class="grid grid-cols-2 gap-6">
+      <div>
+        <h4 class="text-xs font-semibold text-zinc-400 mb-2">TOOLS</h4>
+        ${table(['Tool', 'Calls'], rep.tools.map(t => [escapeHtml(t.name), n(t.calls)]))}
+      </div>
+      <div>
+        <h4 class="text-xs font-semibold text-zinc-400 mb-2">TOP USERS</h4>
+        ${table(['User', 'Turns', 'Tokens'], rep.top_users.map(u => [escapeHtml(u.user_id), n(u.turns), n(u.input_tokens + u.output_tokens)]))}
+      </div>
+    </div>
+    ${rep.tenants.some(t => t.tenant) ? `
+    <div>
+      <h4 class="text-xs font-semibold text-zinc-400 mb-2">TENANTS</h4>
+      ${table(['Tenant', 'Turns', 'Input tokens', 'Output tokens'],
+        rep.tenants.map(t => [t.tenant ? escapeHtml(t.tenant) : '(base)', n(t.turns), n(t.input_tokens), n(t.output_tokens)]))}
+    </div>` : ''}`;
+}
+
+// WhatsApp
+function openWhatsApp() {
+  whatsAppBody.textContent = 'Loading...';
+  whatsAppModal.classList.remove('hidden');
+  send({ type: 'get_whatsapp' });
+}
+
+function hideWhatsApp() {
+  whatsAppModal.classList.add('hidden');
+}
+
+function repairWhatsApp() {
+  if (!confirm('Log the bot out of WhatsApp and pair it again? It stops answering WhatsApp until the new QR code is scanned.')) return;
+  whatsAppBody.textContent = 'Logging out...';
+  send({ type: 'repair_whatsapp' });
+}
+
+// Broadcast
+function openBroadcast() {
+  broadcastText.value = '';
+  broadcastResult.textContent = '';
+  sendBroadcastBtn.disabled = false;
+  broadcastModal.classList.remove('hidden');
+  broadcastText.focus();
+}
+
+function hideBroadcast() {
+  broadcastModal.classList.add('hidden');
+}
+
+function sendBroadcast() {
+  const text = broadcastText.value.trim();
+  if (!text) return;
+  if (!confirm('Post this announcement to every active chat?')) return;
+  sendBroadcastBtn.disabled = true;
+  broadcastResult.textContent = 'Sending...';
+  send({ type: 'broadcast', text });
+}
+
+function renderBroadcast(msg) {
+  sendBroadcastBtn.disabled = false;
+  if (msg.error) {
+    broadcastResult.textContent = `Not sent: ${msg.error}`;
+    return;
+  }
+  broadcastResult.textContent = msg.failed
+    ? `Sent to ${msg.sent} chats; ${msg.failed} failed (see logs).`
+    : `Sent to ${msg.sent} chats.`;
+}
+
+function renderWhatsApp(msg) {
+  repairWhatsAppBtn.disabled = !msg.enabled;
+  if (!msg.enabled) {
+    whatsAppBody.textContent = 'WhatsApp is not enabled.';
+    return;
+  }
+  const st = msg.status || {};
+  const row = (label, value) => `
+    <div class="flex justify-between gap-4">
+      <span class="text-zinc-500">${label}</span>
+      <span class="text-zinc-100 break-all text-right">${value}</span>
+    </div>`;
+  const devices = (st.devices || []).map(d => `
+    <li class="flex justify-between gap-4">
+      <span class="break-all">${escapeHtml(d.jid)}</span>
+      <span class="text-xs text-zinc-500">${[d.primary ? 'phone' : '', d.this ? 'this bot' : ''].filter(Boolean).join(', ')}</span>
+    </li>`).join('');
+  whatsAppBody.innerHTML = `
+    ${msg.error ? `<div class="text-red-400">${escapeHtml(msg.error)}</div>` : ''}
+    <div class="space-y-1">
+      ${row('Status', st.paired ? (st.connected ? 'Connected' : 'Paired, disconnected') : 'Not paired')}
+      ${st.jid ? row('Account', escapeHtml(st.jid)) : ''}
+      ${st.pushName ? row('Name', escapeHtml(st.pushName)) : ''}
+      ${st.platform ? row('Platform', escapeHtml(st.platform)) : ''}
+    </div>
+    ${devices ? `
+    <div>
+      <h4 class="text-xs font-semibold text-zinc-400 mb-2">LINKED DEVICES</h4>
+      <ul class="space-y-1">${devices}</ul>
+    </div>` : ''}
+    ${st.paired ? '' : '<div class="text-zinc-500">Scan the QR code shown above the chat to pair.</div>'}`;
+}
+
 // WhatsApp QR
 function handleWhatsAppQR(content) {
   if (content === 'success') {
@@ -584,6 +804,20 @@ saveMemoryBtn.onclick = saveMemory;
 newMemoryFileBtn.onclick = newMemoryFile;
 deleteMemoryFileBtn.onclick = deleteMemory;
 
+openAnalyticsBtn.onclick = openAnalytics;
+closeAnalyticsBtn.onclick = hideAnalytics;
+analyticsDays.onchange = loadAnalytics;
+
+openWhatsAppBtn.onclick = openWhatsApp;
+closeWhatsAppBtn.onclick = hideWhatsApp;
+refreshWhatsAppBtn.onclick = () => send({ type: 'get_whatsapp' });
+repairWhatsAppBtn.onclick = repairWhatsApp;
+
+openBroadcastBtn.onclick = openBroadcast;
+closeBroadcastBtn.onclick = hideBroadcast;
+cancelBroadcastBtn.onclick = hideBroadcast;
+sendBroadcastBtn.onclick = sendBroadcast;
+
 // Close modals on backdrop click
 permissionModal.onclick = (e) => {
   if (e.target === permissionModal) hidePermissionModal();
@@ -597,6 +831,15 @@ agentsMdModal.onclick = (e) => {
 memoryModal.onclick = (e) => {
   if (e.target === memoryModal) hideMemory();
 };
+analyticsModal.onclick = (e) => {
+  if (e.target === analyticsModal) hideAnalytics();
+};
+whatsAppModal.onclick = (e) => {
+  if (e.target === whatsAppModal) hideWhatsApp();
+};
+broadcastModal.onclick = (e) => {
+  if (e.target === broadcastModal) hideBroadcast();
+};
 
 // Start
 connect();
@@ -1,11 +1,11 @@
 package dashboard
 
 import (
-	"encoding/json"
 	"log/slog"
 	"sync"
 	"time"
 
+	"github.com/TheLazyLemur/switchboard/internal/faults"
 	"github.com/gorilla/websocket"
 )
 
@@ -16,30 +16,6 @@ const (
 	maxMessageSize = 512 * 1024
 )
 
-// Message represents a WS message.
-type Message struct {
-	Type    string `json:"type"`
-	Level   string `json:"level,omitempty"`
-	Msg     string `json:"msg,omitempty"`
-	Time    string `json:"time,omitempty"`
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
-	Active  *bool  `json:"active,omitempty"`
-	ID      string `json:"id,omitempty"`
-
-	// Session info
-	SessionID string `json:"sessionID,omitempty"`
-
-	// Skills
-	Skills []SkillInfo `json:"skills,omitempty"`
-	Name   string      `json:"name,omitempty"`
-	Files  []SkillFile `json:"files,omitempty"`
-	Path   string      `json:"path,omitempty"`
-
-	// New session
-	WorkDir string `json:"workDir,omitempty"`
-}
-
 // SkillInfo for skill list.
 type SkillInfo struct {
 	Name        string `json:"name"`
@@ -53,11 +29,28 @@ type SkillFile struct {
 	Content string `json:"content,omitempty"`
 }
 
+// SkillError is one problem that stops a SKILL.md from loading. Field is
+// "name", "description" or "frontmatter".
+type SkillError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SkillPreview is a skill as the bot will load it.
+type SkillPreview struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Instructions string      `json:"instructions"`
+	Files        []SkillFile `json:"files"`
+}
+
 // Client represents a connected WS client.
 type Client struct {
 	hub  *Hub
 	conn *websocket.Conn
 	send chan []byte
+	// viewer clients may only receive broadcasts and read.
+	viewer bool
 }
 
 // Hub manages WS clients and broadcasts.
@@ -68,6 +61,9 @@ type Hub struct {
 	unregister chan *Client
 	mu         sync.RWMutex
 	sticky     []byte // last sticky message, replayed to new clients
+	// dropRate is the share of writes after which a client is cut off,
+	// injected by FAULT_INJECTION so reconnects get exercised.
+	dropRate float64
 }
 
 // NewHub creates a new Hub.
@@ -80,6 +76,12 @@ func NewHub() *Hub {
 	}
 }
 
+// SetDropRate disconnects clients after that share of writes, to test
+// that they reconnect. Call it before Run.
+func (h *Hub) SetDropRate(rate float64) {
+	h.dropRate = rate
+}
+
 // Run starts the hub's event loop.
 func (h *Hub) Run() {
 	for {
@@ -125,9 +127,9 @@ func (h *Hub) Run() {
 	}
 }
 
-// Broadcast sends a message to all clients.
-func (h *Hub) Broadcast(msg Message) {
-	data, err := json.Marshal(msg)
+// Broadcast sends an event to all clients.
+func (h *Hub) Broadcast(e Event) {
+	data, err := encodeEvent(e)
 	if err != nil {
 		slog.Error("marshal broadcast", "error", err)
 		return
@@ -135,9 +137,9 @@ func (h *Hub) Broadcast(msg Message) {
 	h.broadcast <- data
 }
 
-// BroadcastSticky caches the message and broadcasts it. Late-joining clients receive the cached copy.
-func (h *Hub) BroadcastSticky(msg Message) {
-	data, err := json.Marshal(msg)
+// BroadcastSticky caches the event and broadcasts it. Late-joining clients receive the cached copy.
+func (h *Hub) BroadcastSticky(e Event) {
+	data, err := encodeEvent(e)
 	if err != nil {
 		slog.Error("marshal broadcast", "error", err)
 		return
@@ -163,7 +165,7 @@ func (h *Hub) Sticky() []byte {
 }
 
 // readPump pumps messages from WS to hub.
-func (c *Client) readPump(handler func(*Client, Message)) {
+func (c *Client) readPump(handler func(*Client, Request)) {
 	defer func() {
 		c.hub.unregister <- c
 		c.conn.Close()
@@ -185,13 +187,13 @@ func (c *Client) readPump(handler func(*Client, Message)) {
 			break
 		}
 
-		var msg Message
-		if err := json.Unmarshal(data, &msg); err != nil {
+		req, err := decodeRequest(data)
+		if err != nil {
 			slog.Error("ws unmarshal", "error", err)
 			continue
 		}
 
-		handler(c, msg)
+		handler(c, req)
 	}
 }
 
@@ -228,6 +230,10 @@ func (c *Client) writePump() {
 			if err := w.Close(); err != nil {
 				return
 			}
+			if faults.Hit(c.hub.dropRate) {
+				slog.Warn("dropping dashboard client (injected fault)")
+				return
+			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -238,9 +244,9 @@ func (c *Client) writePump() {
 	}
 }
 
-// Send sends a message to this client.
-func (c *Client) Send(msg Message) {
-	data, err := json.Marshal(msg)
+// Send sends an event to this client.
+func (c *Client) Send(e Event) {
+	data, err := encodeEvent(e)
 	if err != nil {
 		return
 	}
@@ -28,7 +28,7 @@ func TestHub_Broadcast_EvictsSlowClient(t *testing.T) {
 	// broadcast should evict the slow client, not deadlock
 	done := make(chan struct{})
 	go func() {
-		hub.Broadcast(Message{Type: "test"})
+		hub.Broadcast(TypingEvent{})
 		close(done)
 	}()
 
@@ -55,7 +55,7 @@ func TestHub_BroadcastSticky_CachesMessage(t *testing.T) {
 
 	a.Nil(hub.Sticky())
 
-	hub.BroadcastSticky(Message{Type: "whatsapp_qr", Content: "qr-code-data"})
+	hub.BroadcastSticky(WhatsAppQREvent{Content: "qr-code-data"})
 	time.Sleep(10 * time.Millisecond)
 
 	a.NotNil(hub.Sticky())
@@ -68,7 +68,7 @@ func TestHub_ClearSticky(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	hub.BroadcastSticky(Message{Type: "whatsapp_qr", Content: "qr-code-data"})
+	hub.BroadcastSticky(WhatsAppQREvent{Content: "qr-code-data"})
 	time.Sleep(10 * time.Millisecond)
 	a.NotNil(hub.Sticky())
 
@@ -96,7 +96,7 @@ func TestHub_Broadcast_ConcurrentDoesNotDeadlock(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			hub.Broadcast(Message{Type: "test"})
+			hub.Broadcast(TypingEvent{})
 		}()
 	}
 
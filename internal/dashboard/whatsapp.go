@@ -0,0 +1,94 @@
+package dashboard
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+// WhatsAppPairing is the WhatsApp connection the dashboard's WhatsApp panel
+// shows and re-pairs. cmd/switchboard implements it over whatsmeow.
+type WhatsAppPairing interface {
+	Status(ctx context.Context) (WhatsAppStatus, error)
+	// Repair logs the linked device out and starts a fresh pairing, whose
+	// codes are broadcast as sticky WhatsAppQREvents.
+	Repair(ctx context.Context) error
+}
+
+// WhatsAppStatus describes the WhatsApp connection.
+type WhatsAppStatus struct {
+	Connected bool   `json:"connected"`
+	Paired    bool   `json:"paired"`
+	JID       string `json:"jid,omitempty"`
+	PushName  string `json:"pushName,omitempty"`
+	Platform  string `json:"platform,omitempty"`
+	// Devices are every device linked to the account, phone included.
+	Devices []WhatsAppDevice `json:"devices,omitempty"`
+}
+
+// WhatsAppDevice is one device linked to the paired account.
+type WhatsAppDevice struct {
+	JID string `json:"jid"`
+	// Primary is the phone; This is the bot's own device.
+	Primary bool `json:"primary,omitempty"`
+	This    bool `json:"this,omitempty"`
+}
+
+// whatsAppTimeout bounds a status lookup or logout.
+const whatsAppTimeout = 30 * time.Second
+
+// SetWhatsApp enables the WhatsApp panel. Without it the panel reports
+// WhatsApp as not configured.
+func (s *Server) SetWhatsApp(p WhatsAppPairing) {
+	s.mu.Lock()
+	s.whatsApp = p
+	s.mu.Unlock()
+}
+
+func (s *Server) whatsAppStatus() WhatsAppStatusEvent {
+	s.mu.Lock()
+	p := s.whatsApp
+	s.mu.Unlock()
+	if p == nil {
+		return WhatsAppStatusEvent{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), whatsAppTimeout)
+	defer cancel()
+	st, err := p.Status(ctx)
+	e := WhatsAppStatusEvent{Enabled: true, Status: st}
+	if err != nil {
+		slog.Warn("whatsapp status", "error", err)
+		e.Error = err.Error()
+	}
+	return e
+}
+
+func (s *Server) handleGetWhatsApp(client *Client) {
+	client.Send(s.whatsAppStatus())
+}
+
+// handleRepairWhatsApp runs off the read loop, since logging out waits on
+// WhatsApp, and tells every client the outcome.
+func (s *Server) handleRepairWhatsApp() {
+	defer core.Recover("dashboard whatsapp re-pair", func(text string) {
+		s.hub.Broadcast(WhatsAppStatusEvent{Enabled: true, Error: text})
+	})
+	s.mu.Lock()
+	p := s.whatsApp
+	s.mu.Unlock()
+	if p == nil {
+		return
+	}
+	slog.Info("whatsapp re-pair requested from the dashboard")
+	ctx, cancel := context.WithTimeout(context.Background(), whatsAppTimeout)
+	err := p.Repair(ctx)
+	cancel()
+	e := s.whatsAppStatus()
+	if err != nil {
+		slog.Error("whatsapp re-pair", "error", err)
+		e.Error = err.Error()
+	}
+	s.hub.Broadcast(e)
+}
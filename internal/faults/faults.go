@@ -0,0 +1,111 @@
+// Package faults injects failures on purpose, so the retry and reconnect
+// paths can be seen working before production relies on them. It is set
+// with FAULT_INJECTION and meant for development and staging only.
+package faults
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/pkg/errors"
+)
+
+// Config says which faults to inject. The zero value injects none.
+type Config struct {
+	// API529Rate is the share of Messages API calls answered with a
+	// synthetic 529 instead of being sent.
+	API529Rate float64
+	// DiscordSendDelay is added before every Discord message and file
+	// send.
+	DiscordSendDelay time.Duration
+	// Discord429Rate is the share of Discord sends that fail with a
+	// synthetic rate limit before reaching Discord.
+	Discord429Rate float64
+	// WSDropRate is the share of dashboard WebSocket writes after which
+	// the client is disconnected.
+	WSDropRate float64
+}
+
+// Parse reads a comma-separated list of faults such as
+// "api_529=0.2,discord_send_delay=2s,discord_429=0.1,ws_drop=0.05".
+// Rates are between 0 and 1.
+func Parse(s string) (Config, error) {
+	var c Config
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return Config{}, errors.Errorf("fault %q must be name=value", item)
+		}
+		var err error
+		switch key {
+		case "api_529":
+			c.API529Rate, err = parseRate(value)
+		case "discord_send_delay":
+			c.DiscordSendDelay, err = time.ParseDuration(value)
+			if err == nil && c.DiscordSendDelay < 0 {
+				err = errors.New("must not be negative")
+			}
+		case "discord_429":
+			c.Discord429Rate, err = parseRate(value)
+		case "ws_drop":
+			c.WSDropRate, err = parseRate(value)
+		default:
+			return Config{}, errors.Errorf("unknown fault %q (api_529, discord_send_delay, discord_429, ws_drop)", key)
+		}
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "fault %s", key)
+		}
+	}
+	return c, nil
+}
+
+func parseRate(s string) (float64, error) {
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, errors.Errorf("rate %q must be between 0 and 1", s)
+	}
+	return rate, nil
+}
+
+// Enabled reports whether any fault is set.
+func (c Config) Enabled() bool {
+	return c != Config{}
+}
+
+// Hit reports whether a fault that happens at rate fires this time.
+func Hit(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+// overloaded is the body the API sends with a 529.
+const overloaded = `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded (injected fault)"}}`
+
+// APIMiddleware answers a share of Messages API calls with a 529, as an
+// overloaded API does, without sending them.
+func APIMiddleware(rate float64) option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		if !Hit(rate) {
+			return next(req)
+		}
+		return &http.Response{
+			Status:     "529 Overloaded",
+			StatusCode: 529,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(overloaded))),
+			Request:    req,
+		}, nil
+	}
+}
@@ -0,0 +1,82 @@
+package faults
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// when
+	c, err := Parse("api_529=0.2, discord_send_delay=1500ms,discord_429=1,ws_drop=0.05")
+
+	// then
+	r.NoError(err)
+	a.Equal(Config{API529Rate: 0.2, DiscordSendDelay: 1500 * time.Millisecond, Discord429Rate: 1, WSDropRate: 0.05}, c)
+	a.True(c.Enabled())
+	empty, err := Parse("")
+	r.NoError(err)
+	a.False(empty.Enabled())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"api_529", "must be name=value"},
+		{"cli_kill=0.1", `unknown fault "cli_kill"`},
+		{"api_529=2", "between 0 and 1"},
+		{"ws_drop=often", "between 0 and 1"},
+		{"discord_send_delay=2", "discord_send_delay"},
+		{"discord_send_delay=-1s", "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestAPIMiddleware(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+	client := func(rate float64) anthropic.Client {
+		return anthropic.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL), option.WithMaxRetries(0), option.WithMiddleware(APIMiddleware(rate)))
+	}
+	params := anthropic.MessageNewParams{Model: "m", MaxTokens: 1, Messages: []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("hi"))}}
+
+	// when
+	injecting, passing := client(1), client(0)
+	_, injected := injecting.Messages.New(context.Background(), params)
+	_, passed := passing.Messages.New(context.Background(), params)
+
+	// then
+	// ... a hit never reaches the server and looks like an overloaded API
+	var apiErr *anthropic.Error
+	if a.ErrorAs(injected, &apiErr) {
+		a.Equal(529, apiErr.StatusCode)
+	}
+	if a.ErrorAs(passed, &apiErr) {
+		a.Equal(http.StatusTeapot, apiErr.StatusCode)
+	}
+	a.Equal(1, calls)
+}
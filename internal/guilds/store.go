@@ -0,0 +1,85 @@
+// Package guilds keeps per-server settings changed with /config in SQLite.
+package guilds
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+var _ core.GuildStore = (*Store)(nil)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS guild_settings (
+	guild_id         TEXT PRIMARY KEY,
+	allowed_channels TEXT NOT NULL,
+	passive          INTEGER NOT NULL,
+	workspace        TEXT NOT NULL,
+	language         TEXT NOT NULL
+);
+`
+
+// Store keeps one row of settings per guild.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or creates the settings database at path. The sqlite driver
+// must be registered by the caller.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening guild settings database")
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "creating guild settings schema")
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// GuildSettings returns guildID's settings, which are empty until first
+// saved.
+func (s *Store) GuildSettings(guildID string) (core.GuildSettings, error) {
+	var channels, workspace, lang string
+	var passive bool
+	err := s.db.QueryRow(
+		`SELECT allowed_channels, passive, workspace, language FROM guild_settings WHERE guild_id = ?`,
+		guildID,
+	).Scan(&channels, &passive, &workspace, &lang)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.GuildSettings{}, nil
+	}
+	if err != nil {
+		return core.GuildSettings{}, errors.Wrapf(err, "reading settings for %s", guildID)
+	}
+	return core.GuildSettings{
+		AllowedChannels: strings.Fields(channels),
+		Passive:         passive,
+		Workspace:       workspace,
+		Language:        core.Lang(lang),
+	}, nil
+}
+
+// SetGuildSettings replaces guildID's settings.
+func (s *Store) SetGuildSettings(guildID string, g core.GuildSettings) error {
+	_, err := s.db.Exec(
+		`INSERT INTO guild_settings (guild_id, allowed_channels, passive, workspace, language)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (guild_id) DO UPDATE SET
+			allowed_channels = excluded.allowed_channels,
+			passive = excluded.passive,
+			workspace = excluded.workspace,
+			language = excluded.language`,
+		guildID, strings.Join(g.AllowedChannels, " "), g.Passive, g.Workspace, string(g.Language),
+	)
+	return errors.Wrapf(err, "saving settings for %s", guildID)
+}
@@ -0,0 +1,43 @@
+package guilds
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a fresh database
+	path := filepath.Join(t.TempDir(), "guilds.db")
+	s, err := Open(path)
+	r.NoError(err)
+
+	// when
+	// ... a guild's settings are saved, then replaced, and the database reopened
+	r.NoError(s.SetGuildSettings("discord:1", core.GuildSettings{AllowedChannels: []string{"10"}, Passive: true}))
+	want := core.GuildSettings{AllowedChannels: []string{"10", "11"}, Workspace: "api", Language: core.LangGerman}
+	r.NoError(s.SetGuildSettings("discord:1", want))
+	r.NoError(s.Close())
+	s, err = Open(path)
+	r.NoError(err)
+	defer s.Close()
+
+	// then
+	// ... the latest settings come back and other guilds have none
+	got, err := s.GuildSettings("discord:1")
+	r.NoError(err)
+	a.Equal(want, got)
+	other, err := s.GuildSettings("discord:2")
+	r.NoError(err)
+	a.Zero(other)
+}
@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+)
+
+// builtins is the whole API a script sees besides the Starlark language.
+var builtins = starlark.StringDict{
+	"allow":  starlark.NewBuiltin("allow", allow),
+	"deny":   starlark.NewBuiltin("deny", deny),
+	"modify": starlark.NewBuiltin("modify", modify),
+	"route":  starlark.NewBuiltin("route", route),
+}
+
+// decision is the value allow(), deny(), modify() and route() return.
+type decision struct {
+	core.HookDecision
+}
+
+var _ starlark.Value = (*decision)(nil)
+
+func (d *decision) String() string {
+	switch d.Verdict {
+	case core.HookAllow:
+		return "allow()"
+	case core.HookDeny:
+		return fmt.Sprintf("deny(%q)", d.Reason)
+	case core.HookModify:
+		return fmt.Sprintf("modify(%q)", d.Text)
+	case core.HookRoute:
+		return fmt.Sprintf("route(%q)", string(d.Route))
+	}
+	return "pass"
+}
+
+func (d *decision) Type() string          { return "decision" }
+func (d *decision) Freeze()               {}
+func (d *decision) Truth() starlark.Bool  { return starlark.True }
+func (d *decision) Hash() (uint32, error) { return 0, errors.New("unhashable type: decision") }
+
+func allow(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	return &decision{core.HookDecision{Verdict: core.HookAllow}}, nil
+}
+
+func deny(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var reason string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "reason?", &reason); err != nil {
+		return nil, err
+	}
+	return &decision{core.HookDecision{Verdict: core.HookDeny, Reason: reason}}, nil
+}
+
+func modify(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var text string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "text", &text); err != nil {
+		return nil, err
+	}
+	return &decision{core.HookDecision{Verdict: core.HookModify, Text: text}}, nil
+}
+
+func route(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "session_key", &key); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, errors.Errorf("%s: session_key is empty", b.Name())
+	}
+	return &decision{core.HookDecision{Verdict: core.HookRoute, Route: core.SessionKey(key)}}, nil
+}
@@ -0,0 +1,214 @@
+// Package hooks runs operator-supplied Starlark functions at fixed points:
+// on_message before a message is dispatched, pre_tool_call before a tool
+// runs and post_response before a reply is posted.
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// maxSteps bounds one hook call so a runaway loop can't stall a turn.
+const maxSteps = 1_000_000
+
+var _ core.Hooks = (*Hooks)(nil)
+
+// Hooks holds the functions a script defined. Missing functions pass
+// everything through.
+type Hooks struct {
+	path         string
+	onMessage    starlark.Callable
+	preToolCall  starlark.Callable
+	postResponse starlark.Callable
+}
+
+// Load executes the script at path and picks up its hook functions.
+func Load(path string) (*Hooks, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading hooks file")
+	}
+	thread := newThread(path)
+	globals, err := starlark.ExecFile(thread, path, src, builtins)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading hooks file")
+	}
+	globals.Freeze()
+
+	h := &Hooks{path: path}
+	for name, dst := range map[string]*starlark.Callable{
+		"on_message":    &h.onMessage,
+		"pre_tool_call": &h.preToolCall,
+		"post_response": &h.postResponse,
+	} {
+		v, ok := globals[name]
+		if !ok {
+			continue
+		}
+		fn, ok := v.(starlark.Callable)
+		if !ok {
+			return nil, errors.Errorf("hooks file: %s is a %s, not a function", name, v.Type())
+		}
+		*dst = fn
+	}
+	return h, nil
+}
+
+func newThread(path string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: "hooks",
+		Print: func(_ *starlark.Thread, msg string) {
+			slog.Info("hook print", "file", path, "msg", msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	return thread
+}
+
+// call runs fn with one argument. Script errors are logged and count as
+// no decision, so a broken hook never blocks the bot.
+func (h *Hooks) call(name string, fn starlark.Callable, arg starlark.Value) core.HookDecision {
+	if fn == nil {
+		return core.HookDecision{}
+	}
+	v, err := starlark.Call(newThread(h.path), fn, starlark.Tuple{arg}, nil)
+	if err != nil {
+		slog.Error("hook failed", "hook", name, "error", err)
+		return core.HookDecision{}
+	}
+	switch d := v.(type) {
+	case starlark.NoneType:
+		return core.HookDecision{}
+	case *decision:
+		return d.HookDecision
+	}
+	slog.Error("hook returned a non-decision", "hook", name, "type", v.Type())
+	return core.HookDecision{}
+}
+
+func messageValue(in core.Inbound) starlark.Value {
+	return starlarkstruct.FromStringDict(starlark.String("message"), starlark.StringDict{
+		"text":        starlark.String(in.Text),
+		"session_key": starlark.String(in.SessionKey),
+		"user_id":     starlark.String(in.UserID),
+		"channel_id":  starlark.String(in.ChannelID),
+		"attachments": starlark.MakeInt(len(in.Attachments)),
+	})
+}
+
+// OnMessage calls on_message(msg). It may allow(), deny(reason), modify
+// the text or route to another session.
+func (h *Hooks) OnMessage(in core.Inbound) core.HookDecision {
+	return h.call("on_message", h.onMessage, messageValue(in))
+}
+
+// PostResponse calls post_response(msg) where msg.text is the reply and
+// the other fields describe the inbound it answers.
+func (h *Hooks) PostResponse(in core.Inbound, response string) core.HookDecision {
+	in.Text = response
+	return h.call("post_response", h.postResponse, messageValue(in))
+}
+
+// PreToolCall calls pre_tool_call(tool) with tool.name and tool.input.
+func (h *Hooks) PreToolCall(toolName string, input core.ToolInput) core.HookDecision {
+	if h.preToolCall == nil {
+		return core.HookDecision{}
+	}
+	fields, err := toStarlark(input)
+	if err != nil {
+		slog.Error("hook input", "hook", "pre_tool_call", "error", err)
+		return core.HookDecision{}
+	}
+	return h.call("pre_tool_call", h.preToolCall, starlarkstruct.FromStringDict(starlark.String("tool"), starlark.StringDict{
+		"name":  starlark.String(toolName),
+		"input": fields,
+	}))
+}
+
+// Checker wraps next with pre_tool_call: deny() refuses the call and, when
+// canAllow is set, allow() approves it without asking next. Read-only
+// sessions pass canAllow=false so a hook can't grant writes.
+func (h *Hooks) Checker(next core.PermissionChecker, canAllow bool) core.PermissionChecker {
+	return &checker{hooks: h, next: next, canAllow: canAllow}
+}
+
+type checker struct {
+	hooks    *Hooks
+	next     core.PermissionChecker
+	canAllow bool
+}
+
+// WithDirs scopes the wrapped checker, if it supports it.
+func (c *checker) WithDirs(dirs ...string) core.PermissionChecker {
+	scoper, ok := c.next.(core.DirScoper)
+	if !ok {
+		return c
+	}
+	return &checker{hooks: c.hooks, next: scoper.WithDirs(dirs...), canAllow: c.canAllow}
+}
+
+func (c *checker) Check(toolName string, input core.ToolInput) (bool, string) {
+	d := c.hooks.PreToolCall(toolName, input)
+	switch {
+	case d.Verdict == core.HookDeny:
+		reason := d.Reason
+		if reason == "" {
+			reason = "blocked by hook"
+		}
+		return false, reason
+	case d.Verdict == core.HookAllow && c.canAllow:
+		return true, ""
+	}
+	return c.next.Check(toolName, input)
+}
+
+// toStarlark converts v via its JSON form to frozen Starlark values.
+func toStarlark(v any) (starlark.Value, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	out := fromJSON(generic)
+	out.Freeze()
+	return out, nil
+}
+
+func fromJSON(v any) starlark.Value {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None
+	case bool:
+		return starlark.Bool(v)
+	case float64:
+		if v == float64(int64(v)) {
+			return starlark.MakeInt64(int64(v))
+		}
+		return starlark.Float(v)
+	case string:
+		return starlark.String(v)
+	case []any:
+		items := make([]starlark.Value, len(v))
+		for i, item := range v {
+			items[i] = fromJSON(item)
+		}
+		return starlark.NewList(items)
+	case map[string]any:
+		d := starlark.NewDict(len(v))
+		for k, item := range v {
+			_ = d.SetKey(starlark.String(k), fromJSON(item))
+		}
+		return d
+	}
+	return starlark.String(fmt.Sprint(v))
+}
@@ -0,0 +1,133 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const script = `
+def on_message(msg):
+    if msg.user_id == "discord:banned":
+        return deny("you are blocked")
+    if msg.text.startswith("!ops "):
+        return route("discord:ops")
+    if "password" in msg.text:
+        return modify(msg.text.replace("password", "[redacted]"))
+
+def pre_tool_call(tool):
+    if tool.name == "Bash" and "rm -rf" in tool.input.get("command", ""):
+        return deny("no recursive deletes")
+    if tool.name == "Read":
+        return allow()
+
+def post_response(msg):
+    if msg.attachments > 0:
+        return deny()
+`
+
+func load(t *testing.T, src string) (*Hooks, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hooks.star")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	return Load(path)
+}
+
+// recordingChecker approves nothing and counts the calls it receives.
+type recordingChecker struct {
+	calls int
+}
+
+func (c *recordingChecker) Check(string, core.ToolInput) (bool, string) {
+	c.calls++
+	return false, "asked next"
+}
+
+func TestOnMessage(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	h, err := load(t, script)
+	require.NoError(t, err)
+
+	// when / then
+	a.Equal(core.HookDecision{Verdict: core.HookDeny, Reason: "you are blocked"},
+		h.OnMessage(core.Inbound{UserID: "discord:banned", Text: "hi"}))
+	a.Equal(core.HookDecision{Verdict: core.HookRoute, Route: "discord:ops"},
+		h.OnMessage(core.Inbound{Text: "!ops restart"}))
+	a.Equal(core.HookDecision{Verdict: core.HookModify, Text: "my [redacted] is"},
+		h.OnMessage(core.Inbound{Text: "my password is"}))
+	a.Equal(core.HookDecision{}, h.OnMessage(core.Inbound{Text: "hello"}))
+}
+
+func TestPostResponse(t *testing.T) {
+	// given
+	h, err := load(t, script)
+	require.NoError(t, err)
+
+	// when
+	d := h.PostResponse(core.Inbound{Attachments: []core.AttachmentRef{{}}}, "reply")
+
+	// then
+	assert.Equal(t, core.HookDeny, d.Verdict)
+}
+
+func TestChecker(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	h, err := load(t, script)
+	require.NoError(t, err)
+	next := &recordingChecker{}
+	pc := h.Checker(next, true)
+	readOnly := h.Checker(next, false)
+
+	// when
+	denied, reason := pc.Check("Bash", core.ToolInput{Command: "rm -rf /"})
+	allowed, _ := pc.Check("Read", core.ToolInput{FilePath: "/etc/hosts"})
+	readOnlyAllowed, _ := readOnly.Check("Read", core.ToolInput{FilePath: "/etc/hosts"})
+	deferred, _ := pc.Check("Bash", core.ToolInput{Command: "ls"})
+
+	// then
+	// ... deny always wins, allow only where the checker may grant, the rest falls through
+	a.False(denied)
+	a.Equal("no recursive deletes", reason)
+	a.True(allowed)
+	a.False(readOnlyAllowed)
+	a.False(deferred)
+	a.Equal(2, next.calls)
+}
+
+func TestHookErrorsPassThrough(t *testing.T) {
+	// given
+	// ... a hook that loops forever and one that returns a string
+	h, err := load(t, `
+def on_message(msg):
+    for i in range(100000000):
+        pass
+
+def post_response(msg):
+    return "oops"
+`)
+	require.NoError(t, err)
+
+	// when / then
+	assert.Equal(t, core.HookDecision{}, h.OnMessage(core.Inbound{Text: "hi"}))
+	assert.Equal(t, core.HookDecision{}, h.PostResponse(core.Inbound{}, "reply"))
+}
+
+func TestLoad_Errors(t *testing.T) {
+	// when
+	_, syntaxErr := load(t, "def on_message(msg:\n")
+	_, typeErr := load(t, "on_message = 1\n")
+	_, missingErr := Load(filepath.Join(t.TempDir(), "missing.star"))
+
+	// then
+	assert.ErrorContains(t, syntaxErr, "loading hooks file")
+	assert.ErrorContains(t, typeErr, "on_message is a int")
+	assert.ErrorContains(t, missingErr, "reading hooks file")
+}
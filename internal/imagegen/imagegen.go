@@ -0,0 +1,143 @@
+// Package imagegen generates images for the generate_image tool.
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com"
+	DefaultOpenAIModel   = "gpt-image-1"
+	DefaultSize          = "1024x1024"
+)
+
+// Generator turns a prompt into a PNG. size is "WIDTHxHEIGHT".
+type Generator interface {
+	Generate(ctx context.Context, prompt, size string) ([]byte, error)
+}
+
+var httpClient = &http.Client{Timeout: 3 * time.Minute}
+
+// OpenAI calls an OpenAI-compatible /v1/images/generations endpoint.
+type OpenAI struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+}
+
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAI{BaseURL: strings.TrimRight(baseURL, "/"), APIKey: apiKey, Model: model}
+}
+
+func (o *OpenAI) Generate(ctx context.Context, prompt, size string) ([]byte, error) {
+	req := map[string]any{"model": o.Model, "prompt": prompt, "size": size, "n": 1}
+	// dall-e models return URLs unless asked; gpt-image-1 always returns b64.
+	if strings.HasPrefix(o.Model, "dall-e") {
+		req["response_format"] = "b64_json"
+	}
+	var resp struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+		} `json:"data"`
+	}
+	if err := postJSON(ctx, o.BaseURL+"/v1/images/generations", o.APIKey, req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("image endpoint returned no images")
+	}
+	return decodeImage(resp.Data[0].B64JSON)
+}
+
+// SDWebUI calls a Stable Diffusion web UI (AUTOMATIC1111 or compatible)
+// /sdapi/v1/txt2img endpoint.
+type SDWebUI struct {
+	BaseURL string
+}
+
+func NewSDWebUI(baseURL string) *SDWebUI {
+	return &SDWebUI{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (s *SDWebUI) Generate(ctx context.Context, prompt, size string) ([]byte, error) {
+	w, h, err := ParseSize(size)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Images []string `json:"images"`
+	}
+	req := map[string]any{"prompt": prompt, "width": w, "height": h}
+	if err := postJSON(ctx, s.BaseURL+"/sdapi/v1/txt2img", "", req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Images) == 0 {
+		return nil, errors.New("image endpoint returned no images")
+	}
+	return decodeImage(resp.Images[0])
+}
+
+// ParseSize splits "WIDTHxHEIGHT".
+func ParseSize(size string) (int, int, error) {
+	ws, hs, ok := strings.Cut(size, "x")
+	w, errW := strconv.Atoi(ws)
+	h, errH := strconv.Atoi(hs)
+	if !ok || errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, errors.Errorf("size %q must be WIDTHxHEIGHT", size)
+	}
+	return w, h, nil
+}
+
+func postJSON(ctx context.Context, url, apiKey string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "encoding image request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "creating image request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "requesting image")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading image response")
+	}
+	if resp.StatusCode >= 400 {
+		msg := string(respBody)
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return errors.Errorf("image endpoint returned %d: %s", resp.StatusCode, msg)
+	}
+	return errors.Wrap(json.Unmarshal(respBody, out), "decoding image response")
+}
+
+func decodeImage(b64 string) ([]byte, error) {
+	img, err := base64.StdEncoding.DecodeString(b64)
+	return img, errors.Wrap(err, "decoding image")
+}
@@ -0,0 +1,71 @@
+package imagegen
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAI_Generate(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		a.Equal("/v1/images/generations", req.URL.Path)
+		a.Equal("Bearer sk-img", req.Header.Get("Authorization"))
+		_ = json.NewDecoder(req.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]string{{"b64_json": base64.StdEncoding.EncodeToString([]byte("png"))}}})
+	}))
+	defer srv.Close()
+
+	// when
+	img, err := NewOpenAI(srv.URL, "sk-img", "").Generate(context.Background(), "a cat", "1024x1024")
+
+	// then
+	r.NoError(err)
+	a.Equal("png", string(img))
+	a.Equal(DefaultOpenAIModel, got["model"])
+	a.Equal("a cat", got["prompt"])
+}
+
+func TestSDWebUI_Generate(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		a.Equal("/sdapi/v1/txt2img", req.URL.Path)
+		_ = json.NewDecoder(req.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]any{"images": []string{base64.StdEncoding.EncodeToString([]byte("png"))}})
+	}))
+	defer srv.Close()
+
+	// when
+	img, err := NewSDWebUI(srv.URL).Generate(context.Background(), "a cat", "768x512")
+
+	// then
+	r.NoError(err)
+	a.Equal("png", string(img))
+	a.Equal(float64(768), got["width"])
+	a.Equal(float64(512), got["height"])
+}
+
+func TestGenerate_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "billing limit", http.StatusPaymentRequired)
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAI(srv.URL, "", "").Generate(context.Background(), "a cat", "1024x1024")
+
+	assert.ErrorContains(t, err, "402")
+}
@@ -0,0 +1,237 @@
+// Package mcp serves the bot's channels to other local agents as a Model
+// Context Protocol server over streamable HTTP, so a desktop client or a
+// script can message a chat through the bot's allowlist and outbound
+// filters.
+package mcp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// protocolVersion is the MCP revision the server speaks when the client
+// asks for one it doesn't know.
+const protocolVersion = "2025-06-18"
+
+// maxRequestBytes caps one JSON-RPC request body.
+const maxRequestBytes = 1 << 20
+
+// Poster is the slice of core.Bot the server sends through.
+type Poster interface {
+	Post(channel, chatID, text string) error
+	React(channel, chatID, messageID, emoji string) error
+}
+
+// Server answers MCP requests on one endpoint. Only requests bearing the
+// token are served, and only the allowed chats can be reached.
+type Server struct {
+	poster Poster
+	token  string
+	// chats are "channel:chatID" keys, e.g. "discord:123".
+	chats []string
+}
+
+// NewServer returns a Server posting through p. allowedChats are
+// "channel:chatID" entries such as "discord:123" or
+// "whatsapp:271234@s.whatsapp.net".
+func NewServer(p Poster, token string, allowedChats []string) *Server {
+	return &Server{poster: p, token: token, chats: allowedChats}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes.
+const (
+	codeParse          = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// The server never pushes messages, so there is no SSE stream to GET.
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeJSON(w, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParse, "parse error: " + err.Error()}})
+		return
+	}
+	if req.ID == nil {
+		// Notifications such as notifications/initialized need no answer.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	resp := response{JSONRPC: "2.0", ID: req.ID}
+	resp.Result, resp.Error = s.handle(req)
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("mcp response", "error", err)
+	}
+}
+
+func (s *Server) handle(req request) (any, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{codeInvalidRequest, `jsonrpc must be "2.0"`}
+	}
+	switch req.Method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, "invalid params: " + err.Error()}
+		}
+		version := protocolVersion
+		if p.ProtocolVersion == "2025-03-26" {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "switchboard", "version": "1"},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools()}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, "invalid params: " + err.Error()}
+		}
+		text, isError, ok := s.call(p.Name, p.Arguments)
+		if !ok {
+			return nil, &rpcError{codeInvalidParams, "unknown tool: " + p.Name}
+		}
+		return map[string]any{
+			"content": []map[string]any{{"type": "text", "text": text}},
+			"isError": isError,
+		}, nil
+	default:
+		return nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+	}
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+func (s *Server) tools() []tool {
+	chats := "Allowed chats: " + strings.Join(s.chats, ", ") + "."
+	str := func(desc string) map[string]any { return map[string]any{"type": "string", "description": desc} }
+	return []tool{
+		{
+			Name:        "send_message",
+			Description: "Send a message to a chat the bot is in. " + chats,
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"chat": str(`Chat as "channel:chatID", e.g. "discord:123"`),
+					"text": str("Message text"),
+				},
+				"required": []string{"chat", "text"},
+			},
+		},
+		{
+			Name:        "add_reaction",
+			Description: "React to a message with an emoji (Discord only). " + chats,
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"chat":       str(`Chat as "channel:chatID", e.g. "discord:123"`),
+					"message_id": str("ID of the message to react to"),
+					"emoji":      str("Unicode emoji"),
+				},
+				"required": []string{"chat", "message_id", "emoji"},
+			},
+		},
+	}
+}
+
+// call runs a tool; ok is false when there is no tool called name.
+func (s *Server) call(name string, raw json.RawMessage) (text string, isError, ok bool) {
+	var args struct {
+		Chat      string `json:"chat"`
+		Text      string `json:"text"`
+		MessageID string `json:"message_id"`
+		Emoji     string `json:"emoji"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "invalid arguments: " + err.Error(), true, true
+		}
+	}
+	switch name {
+	case "send_message", "add_reaction":
+	default:
+		return "", false, false
+	}
+
+	channel, chatID, found := strings.Cut(args.Chat, ":")
+	if !found || chatID == "" {
+		return fmt.Sprintf(`chat %q must be "channel:chatID"`, args.Chat), true, true
+	}
+	if !slices.Contains(s.chats, args.Chat) {
+		return fmt.Sprintf("chat %s is not allowed; allowed chats: %s", args.Chat, strings.Join(s.chats, ", ")), true, true
+	}
+
+	var err error
+	if name == "send_message" {
+		if args.Text == "" {
+			return "missing text argument", true, true
+		}
+		err = s.poster.Post(channel, chatID, args.Text)
+	} else {
+		if args.MessageID == "" || args.Emoji == "" {
+			return "missing message_id or emoji argument", true, true
+		}
+		err = s.poster.React(channel, chatID, args.MessageID, args.Emoji)
+	}
+	if err != nil {
+		slog.Warn("mcp tool call", "tool", name, "chat", args.Chat, "error", err)
+		return "error: " + err.Error(), true, true
+	}
+	slog.Info("mcp tool call", "tool", name, "chat", args.Chat)
+	return "ok", false, true
+}
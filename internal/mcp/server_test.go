@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPoster struct {
+	posts []string
+}
+
+func (p *stubPoster) Post(channel, chatID, text string) error {
+	p.posts = append(p.posts, channel+":"+chatID+" "+text)
+	return nil
+}
+
+func (p *stubPoster) React(channel, chatID, messageID, emoji string) error {
+	p.posts = append(p.posts, channel+":"+chatID+"/"+messageID+" "+emoji)
+	return nil
+}
+
+func call(t *testing.T, s *Server, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_SendMessage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	p := &stubPoster{}
+	s := NewServer(p, "secret", []string{"discord:123"})
+
+	// when
+	rec := call(t, s, "secret", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"send_message","arguments":{"chat":"discord:123","text":"build is green"}}}`)
+
+	// then
+	r.Equal(http.StatusOK, rec.Code)
+	var resp struct {
+		ID     int `json:"id"`
+		Result struct {
+			Content []struct{ Text string } `json:"content"`
+			IsError bool                    `json:"isError"`
+		} `json:"result"`
+	}
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	a.Equal(1, resp.ID)
+	a.False(resp.Result.IsError)
+	a.Equal("ok", resp.Result.Content[0].Text)
+	a.Equal([]string{"discord:123 build is green"}, p.posts)
+}
+
+func TestServer_RefusesChatsOutsideAllowlist(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	p := &stubPoster{}
+	s := NewServer(p, "secret", []string{"discord:123"})
+
+	// when
+	rec := call(t, s, "secret", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"add_reaction","arguments":{"chat":"discord:999","message_id":"m1","emoji":"👍"}}}`)
+
+	// then
+	a.Contains(rec.Body.String(), `"isError":true`)
+	a.Contains(rec.Body.String(), "chat discord:999 is not allowed")
+	a.Empty(p.posts)
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	s := NewServer(&stubPoster{}, "secret", []string{"discord:123"})
+
+	rec := call(t, s, "wrong", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestServer_InitializeAndList(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s := NewServer(&stubPoster{}, "secret", []string{"discord:123"})
+
+	// when
+	initRec := call(t, s, "secret", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	notifyRec := call(t, s, "secret", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	listRec := call(t, s, "secret", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	unknownRec := call(t, s, "secret", `{"jsonrpc":"2.0","id":3,"method":"resources/list"}`)
+
+	// then
+	a.Contains(initRec.Body.String(), `"protocolVersion":"2025-06-18"`)
+	a.Contains(initRec.Body.String(), `"tools":{}`)
+	a.Equal(http.StatusAccepted, notifyRec.Code)
+	a.Contains(listRec.Body.String(), `"name":"send_message"`)
+	a.Contains(listRec.Body.String(), `Allowed chats: discord:123.`)
+	a.Contains(unknownRec.Body.String(), `"code":-32601`)
+}
+
+func TestServer_InitializeRejectsInvalidParams(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s := NewServer(&stubPoster{}, "secret", []string{"discord:123"})
+
+	// when
+	rec := call(t, s, "secret", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":2025}}`)
+
+	// then
+	a.Contains(rec.Body.String(), `"code":-32602`)
+	a.NotContains(rec.Body.String(), `"serverInfo"`)
+}
@@ -0,0 +1,105 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxVisionImageBytes is the API's per-image limit.
+	MaxVisionImageBytes = 5 * 1024 * 1024
+	// MaxVisionImageEdge is the longest edge the model uses without
+	// resizing internally; larger images only cost latency.
+	MaxVisionImageEdge = 1568
+)
+
+// visionMIMEs are the image types the model accepts.
+var visionMIMEs = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// IsVisionImage reports whether mime can be sent to the model as an image.
+func IsVisionImage(mime string) bool {
+	return visionMIMEs[mime]
+}
+
+// PrepareVisionImage reads the image at path and returns bytes the model
+// accepts. Images within the size and edge limits are passed through;
+// larger JPEG, PNG and GIF images are downscaled and re-encoded as JPEG.
+func PrepareVisionImage(path, mime string) ([]byte, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "reading image")
+	}
+	if !IsVisionImage(mime) {
+		return nil, "", errors.Errorf("unsupported image type %s", mime)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	fits := len(data) <= MaxVisionImageBytes
+	if err == nil {
+		fits = fits && cfg.Width <= MaxVisionImageEdge && cfg.Height <= MaxVisionImageEdge
+	}
+	if fits {
+		return data, mime, nil
+	}
+	if mime == "image/webp" {
+		return nil, "", errors.New("webp image too large to send")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "decoding image")
+	}
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, downscale(img, MaxVisionImageEdge), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", errors.Wrap(err, "encoding image")
+	}
+	if out.Len() > MaxVisionImageBytes {
+		return nil, "", errors.New("image still too large after downscaling")
+	}
+	return out.Bytes(), "image/jpeg", nil
+}
+
+// downscale box-filters img so its longest edge is at most maxEdge. Images
+// already within the limit are returned unchanged.
+func downscale(img image.Image, maxEdge int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxEdge && h <= maxEdge {
+		return img
+	}
+	nw, nh := maxEdge, h*maxEdge/w
+	if h > w {
+		nw, nh = w*maxEdge/h, maxEdge
+	}
+	nw, nh = max(nw, 1), max(nh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0, y1 := b.Min.Y+y*h/nh, b.Min.Y+max((y+1)*h/nh, y*h/nh+1)
+		for x := 0; x < nw; x++ {
+			x0, x1 := b.Min.X+x*w/nw, b.Min.X+max((x+1)*w/nw, x*w/nw+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareVisionImage_PassesSmallImageThrough(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a small PNG
+	path := filepath.Join(t.TempDir(), "small.png")
+	var buf bytes.Buffer
+	r.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 10, 10))))
+	r.NoError(os.WriteFile(path, buf.Bytes(), 0o644))
+
+	// when
+	data, mime, err := PrepareVisionImage(path, "image/png")
+
+	// then
+	r.NoError(err)
+	a.Equal("image/png", mime)
+	a.Equal(buf.Bytes(), data)
+}
+
+func TestPrepareVisionImage_DownscalesLargeImage(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a wide PNG over the edge limit
+	img := image.NewRGBA(image.Rect(0, 0, 3000, 1000))
+	for y := 0; y < 1000; y++ {
+		for x := 0; x < 3000; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	path := filepath.Join(t.TempDir(), "wide.png")
+	var buf bytes.Buffer
+	r.NoError(png.Encode(&buf, img))
+	r.NoError(os.WriteFile(path, buf.Bytes(), 0o644))
+
+	// when
+	data, mime, err := PrepareVisionImage(path, "image/png")
+
+	// then
+	// ... it comes back as a JPEG with the long edge at the limit
+	r.NoError(err)
+	a.Equal("image/jpeg", mime)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	r.NoError(err)
+	a.Equal(MaxVisionImageEdge, cfg.Width)
+	a.Equal(MaxVisionImageEdge/3, cfg.Height)
+}
+
+func TestPrepareVisionImage_RejectsNonImage(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("%PDF"), 0o644))
+
+	// when
+	_, _, err := PrepareVisionImage(path, "application/pdf")
+
+	// then
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package media
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// MaxInlineTextBytes caps how much of a text attachment is pasted into the
+// prompt. The model can still Read the saved file for the rest.
+const MaxInlineTextBytes = 64 * 1024
+
+// inlineTextExts are attachment extensions inlined regardless of the MIME
+// type the platform reported (Discord often says application/octet-stream).
+var inlineTextExts = map[string]bool{
+	".txt": true, ".log": true, ".patch": true, ".diff": true,
+	".go": true, ".md": true, ".json": true, ".yaml": true, ".yml": true,
+	".toml": true, ".csv": true, ".sh": true, ".py": true, ".js": true,
+	".ts": true, ".sql": true,
+}
+
+// IsInlineText reports whether an attachment should have its contents
+// included in the prompt.
+func IsInlineText(name, mimeType string) bool {
+	if inlineTextExts[strings.ToLower(filepath.Ext(name))] {
+		return true
+	}
+	return strings.HasPrefix(mimeType, "text/")
+}
+
+// ReadInlineText reads up to MaxInlineTextBytes of path. truncated reports
+// whether the file was longer. Content that isn't valid UTF-8 or contains
+// NUL bytes is rejected so binaries misnamed .txt don't reach the prompt.
+func ReadInlineText(path string) (text string, truncated bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, errors.Wrap(err, "opening attachment")
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, MaxInlineTextBytes+1))
+	if err != nil {
+		return "", false, errors.Wrap(err, "reading attachment")
+	}
+	if len(data) > MaxInlineTextBytes {
+		data = data[:MaxInlineTextBytes]
+		data = trimPartialRune(data[:MaxInlineTextBytes])
+		truncated = true
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return "", false, errors.New("attachment is not text")
+	}
+	return string(data), truncated, nil
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence left at the end of data
+// by truncation.
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
@@ -0,0 +1,52 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsInlineText(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(IsInlineText("build.LOG", "application/octet-stream"))
+	a.True(IsInlineText("fix.patch", ""))
+	a.True(IsInlineText("notes", "text/plain"))
+	a.False(IsInlineText("photo.png", "image/png"))
+	a.False(IsInlineText("report.pdf", "application/pdf"))
+}
+
+func TestReadInlineText_TruncatesOnRuneBoundary(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a file just over the cap whose cut point lands inside a 3-byte rune
+	path := filepath.Join(t.TempDir(), "big.txt")
+	content := strings.Repeat("a", MaxInlineTextBytes-1) + "€tail"
+	r.NoError(os.WriteFile(path, []byte(content), 0o644))
+
+	// when
+	text, truncated, err := ReadInlineText(path)
+
+	// then
+	r.NoError(err)
+	a.True(truncated)
+	a.Equal(strings.Repeat("a", MaxInlineTextBytes-1), text)
+}
+
+func TestReadInlineText_RejectsBinary(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "fake.txt")
+	require.NoError(t, os.WriteFile(path, []byte{'P', 'K', 0, 3}, 0o644))
+
+	// when
+	_, _, err := ReadInlineText(path)
+
+	// then
+	assert.Error(t, err)
+}
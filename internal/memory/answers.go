@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// MaxAnswersPerChannel bounds a channel's answers index; pinning past it
+// drops the oldest answer.
+const MaxAnswersPerChannel = 50
+
+// AddAnswer appends a to channelID's answers index.
+func (n *Notes) AddAnswer(channelID string, a core.Answer) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	answers, err := n.readAnswers(channelID)
+	if err != nil {
+		return err
+	}
+	answers = append(answers, a)
+	if len(answers) > MaxAnswersPerChannel {
+		answers = answers[len(answers)-MaxAnswersPerChannel:]
+	}
+	path := filepath.Join(n.dir, answersScope(channelID))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "creating answers dir")
+	}
+	return errors.Wrap(os.WriteFile(path, []byte(renderAnswers(answers)), 0o644), "writing answers")
+}
+
+// Answers returns channelID's pinned answers, oldest first.
+func (n *Notes) Answers(channelID string) ([]core.Answer, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.readAnswers(channelID)
+}
+
+func (n *Notes) readAnswers(channelID string) ([]core.Answer, error) {
+	data, err := os.ReadFile(filepath.Join(n.dir, answersScope(channelID)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading answers")
+	}
+	return parseAnswers(string(data)), nil
+}
+
+// renderAnswers writes each answer as a "## <RFC 3339 time>" heading, the
+// question as a quote line, and the answer text.
+func renderAnswers(answers []core.Answer) string {
+	var sb strings.Builder
+	for _, a := range answers {
+		sb.WriteString("## " + a.At.UTC().Format(time.RFC3339) + "\n")
+		if a.Question != "" {
+			sb.WriteString("> " + a.Question + "\n")
+		}
+		sb.WriteString("\n" + strings.TrimSpace(a.Text) + "\n\n")
+	}
+	return sb.String()
+}
+
+// parseAnswers reads renderAnswers' format. A "## " line only starts an
+// answer when the rest is a timestamp, so headings inside answers survive.
+func parseAnswers(data string) []core.Answer {
+	var (
+		answers []core.Answer
+		cur     *core.Answer
+		body    []string
+		// heading is set on the line right after a heading, the only place
+		// a question quote can be.
+		heading bool
+	)
+	flush := func() {
+		if cur != nil {
+			cur.Text = strings.TrimSpace(strings.Join(body, "\n"))
+			answers = append(answers, *cur)
+		}
+	}
+	for _, line := range strings.Split(data, "\n") {
+		if at, err := time.Parse(time.RFC3339, strings.TrimPrefix(line, "## ")); err == nil && strings.HasPrefix(line, "## ") {
+			flush()
+			cur, body, heading = &core.Answer{At: at}, nil, true
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if q, ok := strings.CutPrefix(line, "> "); ok && heading {
+			cur.Question = q
+			heading = false
+			continue
+		}
+		heading = false
+		body = append(body, line)
+	}
+	flush()
+	return answers
+}
+
+func answersScope(channelID string) string { return "answers/" + scopeFile(channelID) }
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes_AnswersRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... answers with a heading, a quote and no question
+	n := NewNotes(t.TempDir())
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	first := core.Answer{At: at, Question: "how do I rotate the key?", Text: "## Steps\n\n1. Run rotate.\n2. Restart."}
+	second := core.Answer{At: at.Add(time.Hour), Text: "> quoted first line\nand more"}
+
+	// when
+	r.NoError(n.AddAnswer("discord:1", first))
+	r.NoError(n.AddAnswer("discord:1", second))
+	got, err := n.Answers("discord:1")
+
+	// then
+	r.NoError(err)
+	a.Equal([]core.Answer{first, second}, got)
+	other, err := n.Answers("discord:2")
+	r.NoError(err)
+	a.Empty(other)
+}
+
+func TestNotes_AddAnswerDropsOldest(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	n := NewNotes(t.TempDir())
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	// when
+	// ... one answer more than the cap is pinned
+	for i := 0; i <= MaxAnswersPerChannel; i++ {
+		r.NoError(n.AddAnswer("c", core.Answer{At: start.Add(time.Duration(i) * time.Minute), Text: "answer"}))
+	}
+
+	// then
+	got, err := n.Answers("c")
+	r.NoError(err)
+	r.Len(got, MaxAnswersPerChannel)
+	a.Equal(start.Add(time.Minute), got[0].At)
+}
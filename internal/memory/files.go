@@ -1,5 +1,6 @@
-// Package memory provides file-level helpers for the dashboard memory editor.
-// All operations are constrained to a single memoryDir; absolute paths and
+// Package memory provides file-level helpers for the dashboard memory editor
+// and the key-value notes behind the remember and recall tools. All file
+// operations are constrained to a single memoryDir; absolute paths and
 // parent-traversal segments are rejected.
 package memory
 
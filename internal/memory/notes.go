@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	MaxNoteKeyLen    = 64
+	MaxNoteValueLen  = 500
+	MaxNotesPerScope = 100
+	// maxBlockBytes keeps the system prompt memory block compact; notes past
+	// it are left out but stay recallable.
+	maxBlockBytes = 4096
+)
+
+// Note is one remembered key-value pair.
+type Note struct {
+	Key   string
+	Value string
+}
+
+// Notes is a key-value store of per-user and per-channel notes kept as
+// Markdown bullet lists under <memoryDir>/notes, so the dashboard memory
+// editor can show and edit them.
+type Notes struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewNotes(memoryDir string) *Notes {
+	return &Notes{dir: filepath.Join(memoryDir, "notes")}
+}
+
+// UserScope and ChannelScope name the note file for an identity, e.g.
+// "discord:123".
+func UserScope(id string) string    { return "users/" + scopeFile(id) }
+func ChannelScope(id string) string { return "channels/" + scopeFile(id) }
+
+var unsafeScopeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func scopeFile(id string) string {
+	name := unsafeScopeChars.ReplaceAllString(id, "_")
+	if strings.HasPrefix(name, ".") {
+		name = "_" + name
+	}
+	return name + ".md"
+}
+
+// List returns the notes in scope in file order. A missing file is an
+// empty scope.
+func (n *Notes) List(scope string) ([]Note, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.read(scope)
+}
+
+// Set stores value under key in scope, replacing any previous value. An
+// empty value forgets the key.
+func (n *Notes) Set(scope, key, value string) error {
+	key = strings.TrimSpace(key)
+	value = strings.Join(strings.Fields(value), " ")
+	if key == "" || strings.ContainsAny(key, ":\n") {
+		return errors.New("key must be non-empty and cannot contain ':' or newlines")
+	}
+	if len(key) > MaxNoteKeyLen {
+		return errors.Errorf("key longer than %d bytes", MaxNoteKeyLen)
+	}
+	if len(value) > MaxNoteValueLen {
+		return errors.Errorf("value longer than %d bytes", MaxNoteValueLen)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	notes, err := n.read(scope)
+	if err != nil {
+		return err
+	}
+	i := 0
+	for ; i < len(notes) && notes[i].Key != key; i++ {
+	}
+	switch {
+	case value == "" && i == len(notes):
+		return nil
+	case value == "":
+		notes = append(notes[:i], notes[i+1:]...)
+	case i < len(notes):
+		notes[i].Value = value
+	case len(notes) >= MaxNotesPerScope:
+		return errors.Errorf("at most %d notes per scope; forget one first", MaxNotesPerScope)
+	default:
+		notes = append(notes, Note{Key: key, Value: value})
+	}
+	return n.write(scope, notes)
+}
+
+// Block renders the user's and channel's notes as a compact system prompt
+// section, or "" when there are none. Empty IDs are skipped.
+func (n *Notes) Block(userID, channelID string) string {
+	var b strings.Builder
+	section := func(title, scope string) {
+		notes, err := n.List(scope)
+		if err != nil || len(notes) == 0 {
+			return
+		}
+		b.WriteString(title + "\n")
+		for _, note := range notes {
+			line := "- " + note.Key + ": " + note.Value + "\n"
+			if b.Len()+len(line) > maxBlockBytes {
+				return
+			}
+			b.WriteString(line)
+		}
+	}
+	if userID != "" {
+		section("About this user:", UserScope(userID))
+	}
+	if channelID != "" {
+		section("About this channel:", ChannelScope(channelID))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "<memory>\nNotes saved with the remember tool in earlier sessions. Use recall to see them all and remember to update them.\n" +
+		b.String() + "</memory>"
+}
+
+func (n *Notes) read(scope string) ([]Note, error) {
+	f, err := os.Open(filepath.Join(n.dir, scope))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening notes")
+	}
+	defer f.Close()
+
+	var notes []Note
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, ok := strings.CutPrefix(sc.Text(), "- ")
+		if !ok {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		notes = append(notes, Note{Key: key, Value: value})
+	}
+	return notes, errors.Wrap(sc.Err(), "reading notes")
+}
+
+func (n *Notes) write(scope string, notes []Note) error {
+	path := filepath.Join(n.dir, scope)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "creating notes dir")
+	}
+	var b strings.Builder
+	for _, note := range notes {
+		b.WriteString("- " + note.Key + ": " + note.Value + "\n")
+	}
+	return errors.Wrap(os.WriteFile(path, []byte(b.String()), 0o644), "writing notes")
+}
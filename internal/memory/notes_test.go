@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotes_SetReplaceAndForget(t *testing.T) {
+	// given
+	// ... a notes store with one note
+	dir := t.TempDir()
+	n := NewNotes(dir)
+	scope := UserScope("discord:42")
+	if err := n.Set(scope, "language", "Go"); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	// ... the note is replaced, another added and the first forgotten
+	if err := n.Set(scope, "language", "Go,  terse\nanswers"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Set(scope, "editor", "vim"); err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := n.List(scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Set(scope, "language", ""); err != nil {
+		t.Fatal(err)
+	}
+	got, err := n.List(scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// then
+	// ... values are single-line and the file is Markdown under notes/users
+	if len(replaced) != 2 || replaced[0] != (Note{Key: "language", Value: "Go, terse answers"}) {
+		t.Fatalf("unexpected notes after replace: %v", replaced)
+	}
+	if len(got) != 1 || got[0] != (Note{Key: "editor", Value: "vim"}) {
+		t.Fatalf("unexpected notes after forget: %v", got)
+	}
+	body, err := os.ReadFile(filepath.Join(dir, "notes", "users", "discord_42.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "- editor: vim\n" {
+		t.Fatalf("unexpected file: %q", body)
+	}
+}
+
+func TestNotes_SetRejectsBadKeys(t *testing.T) {
+	// given
+	n := NewNotes(t.TempDir())
+
+	// when / then
+	for _, key := range []string{"", "a:b", strings.Repeat("k", MaxNoteKeyLen+1)} {
+		if err := n.Set(ChannelScope("x"), key, "v"); err == nil {
+			t.Fatalf("expected error for key %q", key)
+		}
+	}
+}
+
+func TestNotes_Block(t *testing.T) {
+	// given
+	// ... a user note and a channel note
+	n := NewNotes(t.TempDir())
+	if err := n.Set(UserScope("u"), "name", "Dan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Set(ChannelScope("c"), "tests", "go test ./..."); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	block := n.Block("u", "c")
+	empty := n.Block("other", "")
+
+	// then
+	// ... both sections render and an unknown identity renders nothing
+	if !strings.HasPrefix(block, "<memory>\n") || !strings.HasSuffix(block, "</memory>") {
+		t.Fatalf("unexpected block: %q", block)
+	}
+	if !strings.Contains(block, "About this user:\n- name: Dan\n") || !strings.Contains(block, "About this channel:\n- tests: go test ./...\n") {
+		t.Fatalf("missing notes: %q", block)
+	}
+	if empty != "" {
+		t.Fatalf("expected empty block, got %q", empty)
+	}
+}
+
+func TestNotes_PinContext(t *testing.T) {
+	// given
+	// ... a pin for one channel
+	n := NewNotes(t.TempDir())
+	if err := n.PinContext("discord:7", "  we use Go 1.22, tabs, table-driven tests \n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	got, err := n.PinnedContext("discord:7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := n.PinnedBlock("discord:7")
+	other := n.PinnedBlock("discord:8")
+	tooLong := n.PinContext("discord:7", strings.Repeat("x", MaxPinnedContextLen+1))
+	if err := n.PinContext("discord:7", ""); err != nil {
+		t.Fatal(err)
+	}
+	cleared, _ := n.PinnedContext("discord:7")
+
+	// then
+	if got != "we use Go 1.22, tabs, table-driven tests" {
+		t.Fatalf("pinned = %q", got)
+	}
+	if !strings.Contains(block, "<channel_instructions>") || !strings.Contains(block, got) {
+		t.Fatalf("block = %q", block)
+	}
+	if other != "" || cleared != "" {
+		t.Fatalf("other = %q, cleared = %q", other, cleared)
+	}
+	if tooLong == nil {
+		t.Fatal("oversized pin accepted")
+	}
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxPinnedContextLen bounds a channel's pinned context, which goes into
+// every new session's system prompt there.
+const MaxPinnedContextLen = 2000
+
+// PinnedContext returns the standing instructions pinned for channelID,
+// or "" when there are none.
+func (n *Notes) PinnedContext(channelID string) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	data, err := os.ReadFile(filepath.Join(n.dir, pinScope(channelID)))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return strings.TrimSpace(string(data)), errors.Wrap(err, "reading pinned context")
+}
+
+// PinContext replaces channelID's pinned context; "" removes it.
+func (n *Notes) PinContext(channelID, text string) error {
+	text = strings.TrimSpace(text)
+	if len(text) > MaxPinnedContextLen {
+		return errors.Errorf("pinned context longer than %d bytes", MaxPinnedContextLen)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	path := filepath.Join(n.dir, pinScope(channelID))
+	if text == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "removing pinned context")
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "creating pins dir")
+	}
+	return errors.Wrap(os.WriteFile(path, []byte(text+"\n"), 0o644), "writing pinned context")
+}
+
+// PinnedBlock renders channelID's pinned context as a system prompt
+// section, or "" when there is none.
+func (n *Notes) PinnedBlock(channelID string) string {
+	if channelID == "" {
+		return ""
+	}
+	text, err := n.PinnedContext(channelID)
+	if err != nil || text == "" {
+		return ""
+	}
+	return "<channel_instructions>\nStanding instructions pinned for this channel with /pin-context. Follow them unless the user says otherwise.\n" +
+		text + "\n</channel_instructions>"
+}
+
+func pinScope(channelID string) string { return "pins/" + scopeFile(channelID) }
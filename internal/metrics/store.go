@@ -0,0 +1,309 @@
+// Package metrics keeps per-turn usage (tokens, tool calls, latency,
+// failures) and users' ratings of answers in SQLite for the dashboard
+// analytics view.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+var (
+	_ core.UsageRecorder    = (*Store)(nil)
+	_ core.FeedbackRecorder = (*Store)(nil)
+)
+
+// topUsersLimit bounds Report.TopUsers.
+const topUsersLimit = 10
+
+const schema = `
+CREATE TABLE IF NOT EXISTS turns (
+	id              INTEGER PRIMARY KEY,
+	at              INTEGER NOT NULL,
+	day             TEXT NOT NULL,
+	user_id         TEXT NOT NULL,
+	channel_id      TEXT NOT NULL,
+	model           TEXT NOT NULL,
+	input_tokens    INTEGER NOT NULL,
+	output_tokens   INTEGER NOT NULL,
+	latency_ms      INTEGER NOT NULL,
+	failed          INTEGER NOT NULL,
+	thinking_tokens INTEGER NOT NULL DEFAULT 0,
+	tenant          TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS turns_day ON turns (day);
+CREATE TABLE IF NOT EXISTS tool_calls (
+	turn_id INTEGER NOT NULL,
+	day     TEXT NOT NULL,
+	name    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tool_calls_day ON tool_calls (day);
+CREATE TABLE IF NOT EXISTS feedback (
+	turn_id     TEXT NOT NULL,
+	user_id     TEXT NOT NULL,
+	at          INTEGER NOT NULL,
+	day         TEXT NOT NULL,
+	tenant      TEXT NOT NULL,
+	session_key TEXT NOT NULL,
+	channel_id  TEXT NOT NULL,
+	rating      INTEGER NOT NULL,
+	PRIMARY KEY (turn_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS feedback_day ON feedback (day);
+`
+
+// Store records turns and aggregates them by UTC day.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or creates the metrics database at path. The sqlite driver
+// must be registered by the caller.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening metrics database")
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "creating metrics schema")
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// migrate adds the columns newer versions record to databases created
+// before them.
+func migrate(db *sql.DB) error {
+	for _, col := range []struct{ name, def string }{
+		{"thinking_tokens", "INTEGER NOT NULL DEFAULT 0"},
+		{"tenant", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		var n int
+		err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('turns') WHERE name = ?`, col.name).Scan(&n)
+		if err != nil {
+			return errors.Wrap(err, "migrating metrics schema")
+		}
+		if n > 0 {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE turns ADD COLUMN ` + col.name + ` ` + col.def); err != nil {
+			return errors.Wrap(err, "migrating metrics schema")
+		}
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// RecordTurn implements core.UsageRecorder. Failures are logged, not
+// returned: losing a data point must not break a conversation.
+func (s *Store) RecordTurn(t core.TurnStats) {
+	if err := s.insert(context.Background(), t); err != nil {
+		slog.Warn("recording usage", "error", err)
+	}
+}
+
+func (s *Store) insert(ctx context.Context, t core.TurnStats) error {
+	day := t.At.UTC().Format(time.DateOnly)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "writing metrics")
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `INSERT INTO turns (at, day, tenant, user_id, channel_id, model, input_tokens, output_tokens, thinking_tokens, latency_ms, failed) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.At.Unix(), day, t.Tenant, t.UserID, t.ChannelID, t.Model, t.InputTokens, t.OutputTokens, t.ThinkingTokens, t.Latency.Milliseconds(), t.Failed)
+	if err != nil {
+		return errors.Wrap(err, "writing metrics")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "writing metrics")
+	}
+	for _, name := range t.ToolCalls {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tool_calls (turn_id, day, name) VALUES (?, ?, ?)`, id, day, name); err != nil {
+			return errors.Wrap(err, "writing metrics")
+		}
+	}
+	return errors.Wrap(tx.Commit(), "writing metrics")
+}
+
+// RecordFeedback implements core.FeedbackRecorder. Failures are logged,
+// like RecordTurn's.
+func (s *Store) RecordFeedback(f core.Feedback) {
+	_, err := s.db.Exec(`
+		INSERT INTO feedback (turn_id, user_id, at, day, tenant, session_key, channel_id, rating) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (turn_id, user_id) DO UPDATE SET at = excluded.at, day = excluded.day, rating = excluded.rating`,
+		f.TurnID, f.UserID, f.At.Unix(), f.At.UTC().Format(time.DateOnly), f.Tenant, string(f.SessionKey), f.ChannelID, f.Rating)
+	if err != nil {
+		slog.Warn("recording feedback", "error", errors.Wrap(err, "writing feedback"))
+	}
+}
+
+// Day aggregates one UTC day. Days without turns are omitted.
+type Day struct {
+	Day              string  `json:"day"`
+	Turns            int64   `json:"turns"`
+	Failed           int64   `json:"failed"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	ThinkingTokens   int64   `json:"thinking_tokens"`
+	ToolCalls        int64   `json:"tool_calls"`
+	AvgLatencyMillis float64 `json:"avg_latency_ms"`
+	ThumbsUp         int64   `json:"thumbs_up"`
+	ThumbsDown       int64   `json:"thumbs_down"`
+}
+
+// Tool counts calls to one tool.
+type Tool struct {
+	Name  string `json:"name"`
+	Calls int64  `json:"calls"`
+}
+
+// User totals one user's turns and tokens.
+type User struct {
+	UserID       string `json:"user_id"`
+	Turns        int64  `json:"turns"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// Tenant totals one tenant's turns and tokens. The empty tenant is every
+// chat outside multi-tenant servers.
+type Tenant struct {
+	Tenant       string `json:"tenant"`
+	Turns        int64  `json:"turns"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// Report summarises the turns since a given day. Satisfaction is the share
+// of ratings that are 👍, 0 without ratings.
+type Report struct {
+	Since            string   `json:"since"`
+	Turns            int64    `json:"turns"`
+	Failed           int64    `json:"failed"`
+	ErrorRate        float64  `json:"error_rate"`
+	InputTokens      int64    `json:"input_tokens"`
+	OutputTokens     int64    `json:"output_tokens"`
+	ThinkingTokens   int64    `json:"thinking_tokens"`
+	AvgLatencyMillis float64  `json:"avg_latency_ms"`
+	ThumbsUp         int64    `json:"thumbs_up"`
+	ThumbsDown       int64    `json:"thumbs_down"`
+	Satisfaction     float64  `json:"satisfaction"`
+	Days             []Day    `json:"days"`
+	Tools            []Tool   `json:"tools"`
+	TopUsers         []User   `json:"top_users"`
+	Tenants          []Tenant `json:"tenants"`
+}
+
+// Report aggregates every turn on or after since's UTC day.
+func (s *Store) Report(ctx context.Context, since time.Time) (Report, error) {
+	rep := Report{Since: since.UTC().Format(time.DateOnly), Days: []Day{}, Tools: []Tool{}, TopUsers: []User{}, Tenants: []Tenant{}}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.day, COUNT(*), SUM(t.failed), SUM(t.input_tokens), SUM(t.output_tokens), SUM(t.thinking_tokens), AVG(t.latency_ms),
+			(SELECT COUNT(*) FROM tool_calls c WHERE c.day = t.day),
+			(SELECT COUNT(*) FROM feedback f WHERE f.day = t.day AND f.rating > 0),
+			(SELECT COUNT(*) FROM feedback f WHERE f.day = t.day AND f.rating < 0)
+		FROM turns t WHERE t.day >= ? GROUP BY t.day ORDER BY t.day`, rep.Since)
+	if err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+	var latencySum float64
+	for rows.Next() {
+		var d Day
+		if err := rows.Scan(&d.Day, &d.Turns, &d.Failed, &d.InputTokens, &d.OutputTokens, &d.ThinkingTokens, &d.AvgLatencyMillis, &d.ToolCalls, &d.ThumbsUp, &d.ThumbsDown); err != nil {
+			rows.Close()
+			return rep, errors.Wrap(err, "reading metrics")
+		}
+		rep.Days = append(rep.Days, d)
+		rep.Turns += d.Turns
+		rep.Failed += d.Failed
+		rep.InputTokens += d.InputTokens
+		rep.OutputTokens += d.OutputTokens
+		rep.ThinkingTokens += d.ThinkingTokens
+		latencySum += d.AvgLatencyMillis * float64(d.Turns)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+	if rep.Turns > 0 {
+		rep.ErrorRate = float64(rep.Failed) / float64(rep.Turns)
+		rep.AvgLatencyMillis = latencySum / float64(rep.Turns)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(rating > 0), 0), COALESCE(SUM(rating < 0), 0) FROM feedback WHERE day >= ?`, rep.Since).Scan(&rep.ThumbsUp, &rep.ThumbsDown)
+	if err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+	if n := rep.ThumbsUp + rep.ThumbsDown; n > 0 {
+		rep.Satisfaction = float64(rep.ThumbsUp) / float64(n)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT name, COUNT(*) AS n FROM tool_calls WHERE day >= ? GROUP BY name ORDER BY n DESC, name`, rep.Since)
+	if err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+	for rows.Next() {
+		var t Tool
+		if err := rows.Scan(&t.Name, &t.Calls); err != nil {
+			rows.Close()
+			return rep, errors.Wrap(err, "reading metrics")
+		}
+		rep.Tools = append(rep.Tools, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT user_id, COUNT(*), SUM(input_tokens), SUM(output_tokens) FROM turns
+		WHERE day >= ? AND user_id != '' GROUP BY user_id
+		ORDER BY SUM(input_tokens) + SUM(output_tokens) DESC, user_id LIMIT ?`, rep.Since, topUsersLimit)
+	if err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.UserID, &u.Turns, &u.InputTokens, &u.OutputTokens); err != nil {
+			rows.Close()
+			return rep, errors.Wrap(err, "reading metrics")
+		}
+		rep.TopUsers = append(rep.TopUsers, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT tenant, COUNT(*), SUM(input_tokens), SUM(output_tokens) FROM turns
+		WHERE day >= ? GROUP BY tenant ORDER BY tenant`, rep.Since)
+	if err != nil {
+		return rep, errors.Wrap(err, "reading metrics")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t Tenant
+		if err := rows.Scan(&t.Tenant, &t.Turns, &t.InputTokens, &t.OutputTokens); err != nil {
+			return rep, errors.Wrap(err, "reading metrics")
+		}
+		rep.Tenants = append(rep.Tenants, t)
+	}
+	return rep, errors.Wrap(rows.Err(), "reading metrics")
+}
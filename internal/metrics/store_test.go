@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestStore_ReportAggregatesByDay(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... turns on two days and one before the window
+	s, err := Open(filepath.Join(t.TempDir(), "metrics.db"))
+	r.NoError(err)
+	defer s.Close()
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	s.RecordTurn(core.TurnStats{At: day1.Add(-48 * time.Hour), UserID: "discord:old", InputTokens: 999})
+	s.RecordTurn(core.TurnStats{At: day1, UserID: "discord:1", InputTokens: 100, OutputTokens: 10, ToolCalls: []string{"Bash", "Read", "Bash"}, Latency: time.Second})
+	s.RecordTurn(core.TurnStats{At: day1.Add(time.Hour), Tenant: "team-a", UserID: "whatsapp:2", InputTokens: 500, OutputTokens: 50, ThinkingTokens: 30, Latency: 3 * time.Second, Failed: true})
+	s.RecordTurn(core.TurnStats{At: day2, UserID: "discord:1", InputTokens: 20, OutputTokens: 2, ToolCalls: []string{"Read"}, Latency: 2 * time.Second})
+
+	// when
+	rep, err := s.Report(context.Background(), day1)
+
+	// then
+	r.NoError(err)
+	a.Equal(int64(3), rep.Turns)
+	a.Equal(int64(1), rep.Failed)
+	a.InDelta(1.0/3, rep.ErrorRate, 1e-9)
+	a.Equal(int64(620), rep.InputTokens)
+	a.Equal(int64(30), rep.ThinkingTokens)
+	a.InDelta(2000, rep.AvgLatencyMillis, 1e-9)
+	r.Len(rep.Days, 2)
+	a.Equal(Day{Day: "2026-03-01", Turns: 2, Failed: 1, InputTokens: 600, OutputTokens: 60, ThinkingTokens: 30, ToolCalls: 3, AvgLatencyMillis: 2000}, rep.Days[0])
+	a.Equal(Day{Day: "2026-03-02", Turns: 1, InputTokens: 20, OutputTokens: 2, ToolCalls: 1, AvgLatencyMillis: 2000}, rep.Days[1])
+	a.Equal([]Tool{{Name: "Bash", Calls: 2}, {Name: "Read", Calls: 2}}, rep.Tools)
+	a.Equal([]User{
+		{UserID: "whatsapp:2", Turns: 1, InputTokens: 500, OutputTokens: 50},
+		{UserID: "discord:1", Turns: 2, InputTokens: 120, OutputTokens: 12},
+	}, rep.TopUsers)
+	a.Equal([]Tenant{
+		{Turns: 2, InputTokens: 120, OutputTokens: 12},
+		{Tenant: "team-a", Turns: 1, InputTokens: 500, OutputTokens: 50},
+	}, rep.Tenants)
+}
+
+func TestStore_ReportEmpty(t *testing.T) {
+	// given
+	s, err := Open(filepath.Join(t.TempDir(), "metrics.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	// when
+	rep, err := s.Report(context.Background(), time.Now())
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, rep.Turns)
+	assert.Zero(t, rep.ErrorRate)
+	assert.Empty(t, rep.Days)
+}
+
+func TestStore_ReportCountsFeedback(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a turn rated by two users, one of whom changes their mind
+	s, err := Open(filepath.Join(t.TempDir(), "metrics.db"))
+	r.NoError(err)
+	defer s.Close()
+	day := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s.RecordTurn(core.TurnStats{At: day, UserID: "discord:1"})
+	s.RecordFeedback(core.Feedback{At: day, TurnID: "t1", UserID: "discord:1", Rating: 1})
+	s.RecordFeedback(core.Feedback{At: day, TurnID: "t1", UserID: "discord:2", Rating: 1})
+	s.RecordFeedback(core.Feedback{At: day.Add(time.Minute), TurnID: "t1", UserID: "discord:2", Rating: -1})
+	s.RecordFeedback(core.Feedback{At: day, TurnID: "t2", UserID: "discord:1", Rating: 1})
+
+	// when
+	rep, err := s.Report(context.Background(), day)
+
+	// then
+	r.NoError(err)
+	a.Equal(int64(2), rep.ThumbsUp)
+	a.Equal(int64(1), rep.ThumbsDown)
+	a.InDelta(2.0/3, rep.Satisfaction, 1e-9)
+	r.Len(rep.Days, 1)
+	a.Equal(int64(2), rep.Days[0].ThumbsUp)
+	a.Equal(int64(1), rep.Days[0].ThumbsDown)
+}
+
+func TestOpen_AddsNewColumnsToOldDatabases(t *testing.T) {
+	r := require.New(t)
+
+	// given
+	// ... a database created before thinking tokens and tenants were recorded
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	db, err := sql.Open("sqlite", path)
+	r.NoError(err)
+	_, err = db.Exec(`CREATE TABLE turns (id INTEGER PRIMARY KEY, at INTEGER NOT NULL, day TEXT NOT NULL, user_id TEXT NOT NULL,
+		channel_id TEXT NOT NULL, model TEXT NOT NULL, input_tokens INTEGER NOT NULL, output_tokens INTEGER NOT NULL,
+		latency_ms INTEGER NOT NULL, failed INTEGER NOT NULL)`)
+	r.NoError(err)
+	r.NoError(db.Close())
+
+	// when
+	s, err := Open(path)
+	r.NoError(err)
+	defer s.Close()
+	s.RecordTurn(core.TurnStats{At: time.Now(), Tenant: "team-a", ThinkingTokens: 7})
+
+	// then
+	rep, err := s.Report(context.Background(), time.Now())
+	r.NoError(err)
+	assert.Equal(t, int64(7), rep.ThinkingTokens)
+	assert.Equal(t, []Tenant{{Tenant: "team-a", Turns: 1}}, rep.Tenants)
+}
@@ -161,3 +161,20 @@ func TestAutoApprove_AllPathFields(t *testing.T) {
 	// then
 	a.True(allow)
 }
+
+func TestAutoApprove_WithDirsScopesCopy(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	checker := NewAutoApprovePermissionChecker([]string{"/home/user/projects"})
+	input := core.ToolInput{FilePath: "/tmp/scratch-1/main.go"}
+
+	// when
+	scoped := checker.WithDirs("/tmp/scratch-1")
+	scopedAllow, _ := scoped.Check("Write", input)
+	baseAllow, _ := checker.Check("Write", input)
+
+	// then
+	a.True(scopedAllow)
+	a.False(baseAllow)
+}
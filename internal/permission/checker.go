@@ -3,19 +3,38 @@ package permission
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/TheLazyLemur/switchboard/internal/core"
 )
 
-var _ core.PermissionChecker = (*Checker)(nil)
+var (
+	_ core.PermissionChecker = (*Checker)(nil)
+	_ core.DirScoper         = (*Checker)(nil)
+)
 
 var readOnlyTools = map[string]bool{
 	"Read":      true,
+	"LS":        true,
 	"Glob":      true,
 	"Grep":      true,
 	"WebFetch":  true,
 	"WebSearch": true,
+	// Conversation and skill tools touch nothing on disk.
+	"send_update":         true,
+	"react_emoji":         true,
+	"Skill":               true,
+	"LoadSkillSupporting": true,
+	// sql_query only runs read-only statements in a read-only transaction.
+	"sql_query":     true,
+	"kube_get":      true,
+	"kube_describe": true,
+	"kube_logs":     true,
+	"browse":        true,
+	"repo_map":      true,
+	"code_search":   true,
+	"recall":        true,
 }
 
 // Checker enforces path containment against allowedDirs and, when
@@ -41,8 +60,15 @@ func cleanDirs(dirs []string) []string {
 	return cleaned
 }
 
+// WithDirs returns a copy of c that also allows dirs.
+func (c *Checker) WithDirs(dirs ...string) core.PermissionChecker {
+	scoped := *c
+	scoped.allowedDirs = append(slices.Clip(c.allowedDirs), cleanDirs(dirs)...)
+	return &scoped
+}
+
 func (c *Checker) Check(toolName string, input core.ToolInput) (bool, string) {
-	if c.readOnly && !readOnlyTools[toolName] {
+	if c.readOnly && !readOnlyAllowed(toolName, input) {
 		return false, fmt.Sprintf("read-only mode: %s not allowed", toolName)
 	}
 	for _, path := range extractPaths(input) {
@@ -53,6 +79,18 @@ func (c *Checker) Check(toolName string, input core.ToolInput) (bool, string) {
 	return true, ""
 }
 
+// readOnlyAllowed reports whether a call is safe in read-only mode. Fetch
+// and write_artifact are allowed only in their non-mutating forms.
+func readOnlyAllowed(toolName string, input core.ToolInput) bool {
+	switch toolName {
+	case "Fetch":
+		return input.Method == "" || strings.EqualFold(input.Method, "GET")
+	case "write_artifact":
+		return !input.Save
+	}
+	return readOnlyTools[toolName]
+}
+
 func (c *Checker) isAllowed(path string) bool {
 	cleanPath := filepath.Clean(path)
 	for _, allowed := range c.allowedDirs {
@@ -53,3 +53,27 @@ func TestReadOnlyPermissionChecker_EnforcesAllowedDirs(t *testing.T) {
 	a.False(allow)
 	a.Contains(reason, "outside allowed")
 }
+
+func TestReadOnlyPermissionChecker_AllowsNonMutatingForms(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	checker := NewReadOnlyPermissionChecker([]string{"/allowed"})
+
+	// then - GET fetches, unsaved artifacts and chat tools are allowed
+	allow, _ := checker.Check("Fetch", core.ToolInput{URL: "https://example.com"})
+	a.True(allow)
+	allow, _ = checker.Check("write_artifact", core.ToolInput{Name: "a.patch"})
+	a.True(allow)
+	allow, _ = checker.Check("send_update", core.ToolInput{Message: "looking"})
+	a.True(allow)
+	allow, _ = checker.Check("sql_query", core.ToolInput{Query: "SELECT 1"})
+	a.True(allow)
+
+	// then - their mutating forms are not
+	allow, reason := checker.Check("Fetch", core.ToolInput{URL: "https://example.com", Method: "POST"})
+	a.False(allow)
+	a.Contains(reason, "read-only")
+	allow, _ = checker.Check("write_artifact", core.ToolInput{Name: "a.patch", Save: true})
+	a.False(allow)
+}
@@ -0,0 +1,102 @@
+// Package persona loads the personas /persona switches between from a
+// directory of Markdown files.
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// frontmatter is the optional YAML block at the top of a persona file.
+type frontmatter struct {
+	Description string   `yaml:"description"`
+	Model       string   `yaml:"model"`
+	Temperature *float64 `yaml:"temperature"`
+}
+
+// Store reads personas from <dir>/<name>.md. The file body is the system
+// prompt; optional frontmatter sets description, model and temperature.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+var _ core.PersonaStore = (*Store)(nil)
+
+// Personas lists every valid persona, sorted by name. Invalid files are
+// skipped.
+func (s *Store) Personas() ([]core.Persona, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading personas dir")
+	}
+	var personas []core.Persona
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".md")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if p, err := s.Persona(name); err == nil {
+			personas = append(personas, p)
+		}
+	}
+	sort.Slice(personas, func(i, j int) bool { return personas[i].Name < personas[j].Name })
+	return personas, nil
+}
+
+// Persona loads one persona by name.
+func (s *Store) Persona(name string) (core.Persona, error) {
+	if !nameRegex.MatchString(name) {
+		return core.Persona{}, errors.Errorf("invalid persona name %q", name)
+	}
+	content, err := os.ReadFile(filepath.Join(s.dir, name+".md"))
+	if err != nil {
+		return core.Persona{}, errors.Wrapf(err, "reading persona %s", name)
+	}
+	return Parse(name, string(content))
+}
+
+// Parse reads a persona file's content.
+func Parse(name, content string) (core.Persona, error) {
+	var fm frontmatter
+	body := content
+	if strings.HasPrefix(content, "---") {
+		parts := strings.SplitN(content, "---", 3)
+		if len(parts) < 3 {
+			return core.Persona{}, errors.New("invalid frontmatter: missing closing ---")
+		}
+		if err := yaml.Unmarshal([]byte(parts[1]), &fm); err != nil {
+			return core.Persona{}, errors.Wrap(err, "invalid frontmatter")
+		}
+		body = parts[2]
+	}
+	if t := fm.Temperature; t != nil && (*t < 0 || *t > 1) {
+		return core.Persona{}, errors.Errorf("temperature %g is outside 0-1", *t)
+	}
+	prompt := strings.TrimSpace(body)
+	if prompt == "" {
+		return core.Persona{}, errors.New("persona has no prompt")
+	}
+	return core.Persona{
+		Name:        name,
+		Description: fm.Description,
+		Prompt:      prompt,
+		Model:       fm.Model,
+		Temperature: fm.Temperature,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Personas(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... one persona with frontmatter, one plain, and files to skip
+	dir := t.TempDir()
+	write := func(name, content string) {
+		r.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("reviewer.md", "---\ndescription: Terse code review\nmodel: claude-haiku-4-5\ntemperature: 0.2\n---\nReview code tersely. Never react with emoji.\n")
+	write("pirate.md", "Talk like a pirate.")
+	write("empty.md", "---\nmodel: x\n---\n")
+	write("hot.md", "---\ntemperature: 3\n---\nToo hot.")
+	write("notes.txt", "ignored")
+	store := NewStore(dir)
+
+	// when
+	all, err := store.Personas()
+	r.NoError(err)
+	reviewer, err := store.Persona("reviewer")
+	r.NoError(err)
+	_, missingErr := store.Persona("nope")
+	_, badNameErr := store.Persona("../reviewer")
+
+	// then
+	r.Len(all, 2)
+	a.Equal("pirate", all[0].Name)
+	a.Equal("Talk like a pirate.", all[0].Prompt)
+	a.Nil(all[0].Temperature)
+	a.Equal("Terse code review", reviewer.Description)
+	a.Equal("claude-haiku-4-5", reviewer.Model)
+	r.NotNil(reviewer.Temperature)
+	a.InDelta(0.2, *reviewer.Temperature, 1e-9)
+	a.Equal("Review code tersely. Never react with emoji.", reviewer.Prompt)
+	a.Error(missingErr)
+	a.Error(badNameErr)
+}
+
+func TestStore_MissingDir(t *testing.T) {
+	all, err := NewStore(filepath.Join(t.TempDir(), "none")).Personas()
+
+	assert.NoError(t, err)
+	assert.Empty(t, all)
+}
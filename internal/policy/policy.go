@@ -0,0 +1,187 @@
+// Package policy applies operator-configured content rules to outbound
+// responses: masking regex matches and withholding responses that echo the
+// contents of sensitive files.
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// MaskPlaceholder replaces every span matched by a mask rule.
+const MaskPlaceholder = "[MASKED]"
+
+// BlockedNotice replaces a response that tripped a block rule.
+const BlockedNotice = "⚠️ Response withheld: it contained content covered by the outbound content policy."
+
+// maxSensitiveFileBytes caps how much of each sensitive file is fingerprinted.
+const maxSensitiveFileBytes = 64 * 1024
+
+// minFingerprintLen skips short lines/values ("}", "FOO=1") that would make
+// unrelated responses look like leaks.
+const minFingerprintLen = 12
+
+// File is the YAML shape of CONTENT_POLICY_FILE.
+type File struct {
+	// Mask lists regexes whose matches are replaced with MaskPlaceholder.
+	Mask []string `yaml:"mask"`
+	// Block lists regexes that cause the whole response to be withheld.
+	Block []string `yaml:"block"`
+	// SensitivePaths lists file globs (~ expanded) whose contents must never
+	// be echoed. Matched files are re-read on every check so new or rotated
+	// files are covered without a restart.
+	SensitivePaths []string `yaml:"sensitive_paths"`
+}
+
+// Policy is a compiled File.
+type Policy struct {
+	mask           []*regexp.Regexp
+	block          []*regexp.Regexp
+	sensitivePaths []string
+}
+
+// DefaultFile blocks private keys and the contents of ~/.ssh and any .env
+// file at the root of an allowed dir.
+func DefaultFile(allowedDirs []string) File {
+	f := File{
+		Block:          []string{`-----BEGIN [A-Z ]*PRIVATE KEY-----`},
+		SensitivePaths: []string{"~/.ssh/*"},
+	}
+	for _, d := range allowedDirs {
+		f.SensitivePaths = append(f.SensitivePaths, filepath.Join(d, ".env"), filepath.Join(d, ".env.*"))
+	}
+	return f
+}
+
+// Load reads and compiles a policy file.
+func Load(path string) (*Policy, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading content policy")
+	}
+	var f File
+	if err := yaml.Unmarshal(body, &f); err != nil {
+		return nil, errors.Wrap(err, "parsing content policy")
+	}
+	return Compile(f)
+}
+
+// Compile validates every regex in f.
+func Compile(f File) (*Policy, error) {
+	p := &Policy{}
+	for _, expr := range f.Mask {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "mask pattern %q", expr)
+		}
+		p.mask = append(p.mask, re)
+	}
+	for _, expr := range f.Block {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "block pattern %q", expr)
+		}
+		p.block = append(p.block, re)
+	}
+	for _, sp := range f.SensitivePaths {
+		p.sensitivePaths = append(p.sensitivePaths, expandHome(sp))
+	}
+	return p, nil
+}
+
+// Filter applies the policy to s. Block rules and sensitive-file matches
+// replace the whole text with BlockedNotice; otherwise mask rules are
+// applied. Satisfies core.TextFilter.
+func (p *Policy) Filter(s string) string {
+	if p == nil || s == "" {
+		return s
+	}
+	for _, re := range p.block {
+		if re.MatchString(s) {
+			slog.Warn("outbound response blocked by content policy", "pattern", re.String())
+			return BlockedNotice
+		}
+	}
+	if path, ok := p.leaksSensitiveFile(s); ok {
+		slog.Warn("outbound response blocked by content policy", "sensitive_file", path)
+		return BlockedNotice
+	}
+	for _, re := range p.mask {
+		s = re.ReplaceAllString(s, MaskPlaceholder)
+	}
+	return s
+}
+
+func (p *Policy) leaksSensitiveFile(s string) (string, bool) {
+	for _, pattern := range p.sensitivePaths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			for _, fp := range fingerprints(path) {
+				if strings.Contains(s, fp) {
+					return path, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// fingerprints returns the distinctive lines of path (and, for KEY=VALUE
+// lines, the value on its own) that identify the file's contents.
+func fingerprints(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(f, maxSensitiveFileBytes))
+	if err != nil {
+		return nil
+	}
+
+	var out []string
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	sc.Buffer(make([]byte, 0, 4096), maxSensitiveFileBytes)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, value, ok := strings.Cut(line, "="); ok {
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			if len(value) >= minFingerprintLen {
+				out = append(out, value)
+			}
+		}
+		if len(line) >= minFingerprintLen {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
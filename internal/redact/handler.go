@@ -0,0 +1,68 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler wraps a slog.Handler and redacts the message and string-ish
+// attribute values of every record before delegating.
+type Handler struct {
+	inner slog.Handler
+	r     *Redactor
+}
+
+// NewHandler returns a Handler that scrubs records with r before passing
+// them to inner.
+func NewHandler(inner slog.Handler, r *Redactor) *Handler {
+	return &Handler{inner: inner, r: r}
+}
+
+// Enabled reports whether the inner handler handles records at level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle redacts the record and delegates to the inner handler.
+func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.Redact(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+// WithAttrs returns a new handler with the given attributes, redacted.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbed[i] = h.redactAttr(a)
+	}
+	return &Handler{inner: h.inner.WithAttrs(scrubbed), r: h.r}
+}
+
+// WithGroup returns a new handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{inner: h.inner.WithGroup(name), r: h.r}
+}
+
+func (h *Handler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.r.Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		scrubbed := make([]any, len(group))
+		for i, g := range group {
+			scrubbed[i] = h.redactAttr(g)
+		}
+		return slog.Group(a.Key, scrubbed...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.r.Redact(err.Error()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,32 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_RedactsMessageAndAttrs(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil), New([]string{"topsecret"})))
+
+	// when
+	logger.With("preset", "topsecret").Info("leaking topsecret",
+		"str", "value topsecret",
+		"error", errors.New("auth failed for topsecret"),
+		slog.Group("g", "nested", "topsecret"),
+		"count", 3,
+	)
+
+	// then
+	out := buf.String()
+	a.NotContains(out, "topsecret")
+	a.Contains(out, "[REDACTED]")
+	a.Contains(out, "count=3")
+}
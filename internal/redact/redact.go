@@ -0,0 +1,88 @@
+// Package redact scrubs secrets from text before it leaves the process:
+// log lines, dashboard broadcasts and chat messages.
+package redact
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces every redacted span.
+const Placeholder = "[REDACTED]"
+
+// minSecretLen guards against redacting trivially short configured values
+// (e.g. a one-character password) that would shred unrelated text.
+const minSecretLen = 6
+
+// DefaultPatterns match secret-shaped tokens regardless of configuration.
+var DefaultPatterns = []*regexp.Regexp{
+	// Anthropic / OpenAI style keys.
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{16,}`),
+	// Discord bot tokens: base64 user id . timestamp . hmac.
+	regexp.MustCompile(`\b[MNO][A-Za-z0-9_\-]{23,27}\.[A-Za-z0-9_\-]{6}\.[A-Za-z0-9_\-]{27,}`),
+	// Resend API keys.
+	regexp.MustCompile(`\bre_[A-Za-z0-9]{8,}_[A-Za-z0-9]{16,}`),
+	// Authorization headers echoed back by curl -v and friends.
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._\-~+/]{16,}=*`),
+}
+
+// Redactor replaces known secret values and secret-shaped tokens with
+// Placeholder. A nil *Redactor is a no-op.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  []string
+	patterns []*regexp.Regexp
+}
+
+// New builds a Redactor for the given literal secrets plus DefaultPatterns.
+// Empty and very short secrets are ignored.
+func New(secrets []string) *Redactor {
+	var kept []string
+	for _, s := range secrets {
+		if len(s) >= minSecretLen {
+			kept = append(kept, s)
+		}
+	}
+	sortLongestFirst(kept)
+	return &Redactor{secrets: kept, patterns: DefaultPatterns}
+}
+
+// Add redacts secret from now on too, e.g. a value a user set for their
+// session. Short values are ignored, as in New.
+func (r *Redactor) Add(secret string) {
+	if r == nil || len(secret) < minSecretLen {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.secrets, secret) {
+		return
+	}
+	r.secrets = append(r.secrets, secret)
+	sortLongestFirst(r.secrets)
+}
+
+// sortLongestFirst orders secrets so one that contains another is replaced
+// whole.
+func sortLongestFirst(secrets []string) {
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// Redact returns s with every secret occurrence replaced.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	r.mu.RUnlock()
+	for _, p := range r.patterns {
+		s = p.ReplaceAllString(s, Placeholder)
+	}
+	return s
+}
@@ -0,0 +1,155 @@
+// Package replay feeds a session recording made with API_RECORD_DIR back
+// through a bot whose Messages API answers with the recorded responses, so
+// a reported bug can be reproduced without the reporter's setup.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	"github.com/TheLazyLemur/switchboard/internal/api"
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// Read parses a recording, one api.RecordEntry per line.
+func Read(r io.Reader) ([]api.RecordEntry, error) {
+	var entries []api.RecordEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	for n := 1; sc.Scan(); n++ {
+		var e api.RecordEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, errors.Wrapf(err, "recording line %d", n)
+		}
+		entries = append(entries, e)
+	}
+	return entries, errors.Wrap(sc.Err(), "reading recording")
+}
+
+// Run sends each recorded message to a fresh bot in a temporary work dir
+// and writes the messages and everything the bot posts in reply to w. The
+// bot's API calls get the recorded responses in order. Tool calls are
+// refused, never run, so a recording can't touch this machine; the model's
+// next response is recorded anyway, so the turn still plays out as it did.
+func Run(ctx context.Context, entries []api.RecordEntry, w io.Writer) error {
+	srv := &responses{}
+	for _, e := range entries {
+		if e.Kind == "response" {
+			srv.queue = append(srv.queue, e)
+		}
+	}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	dir, err := os.MkdirTemp("", "switchboard-replay-")
+	if err != nil {
+		return errors.Wrap(err, "creating replay dir")
+	}
+	defer os.RemoveAll(dir)
+
+	mgr := core.NewSessionManager(&api.BackendFactory{
+		APIKey:         "replay",
+		BaseURL:        server.URL,
+		DefaultWorkDir: dir,
+		AllowedDirs:    []string{dir},
+	}, nil)
+	defer mgr.Close()
+	bot := core.NewBot(mgr, refuseAll{})
+
+	out := printer{w}
+	for _, e := range entries {
+		if e.Kind != "inbound" {
+			continue
+		}
+		fmt.Fprintf(w, "> %s\n", e.Text)
+		err := bot.HandleInboundContext(ctx, core.Inbound{
+			SessionKey: "replay",
+			UserID:     "replay",
+			ChannelID:  "replay",
+			Text:       e.Text,
+			Reply:      out,
+		})
+		if err != nil {
+			fmt.Fprintf(w, "! %v\n", err)
+		}
+	}
+	if left := srv.left(); left > 0 {
+		fmt.Fprintf(w, "! %d recorded responses were not used\n", left)
+	}
+	return nil
+}
+
+// responses serves recorded responses in order. Once they run out it
+// answers 400, which the backend doesn't retry.
+type responses struct {
+	mu    sync.Mutex
+	queue []api.RecordEntry
+}
+
+type apiError struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *responses) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	if len(s.queue) == 0 {
+		s.mu.Unlock()
+		body := apiError{Type: "error"}
+		body.Error.Type = "invalid_request_error"
+		body.Error.Message = "the recording has no more responses"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(body)
+		return
+	}
+	e := s.queue[0]
+	s.queue = s.queue[1:]
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	_, _ = io.WriteString(w, e.Body)
+}
+
+func (s *responses) left() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+type refuseAll struct{}
+
+func (refuseAll) Check(string, core.ToolInput) (bool, string) {
+	return false, "tools don't run during replay"
+}
+
+// printer is the replayed session's Outbound.
+type printer struct{ w io.Writer }
+
+func (p printer) SendTyping() error { return nil }
+
+func (p printer) PostResponse(content string) error {
+	_, err := fmt.Fprintf(p.w, "< %s\n", content)
+	return err
+}
+
+func (p printer) AddReaction(emoji string) error {
+	_, err := fmt.Fprintf(p.w, "< reaction %s\n", emoji)
+	return err
+}
+
+func (p printer) SendUpdate(message string) error {
+	_, err := fmt.Fprintf(p.w, "< update: %s\n", message)
+	return err
+}
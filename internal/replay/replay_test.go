@@ -0,0 +1,78 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/api"
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/permission"
+	"github.com/TheLazyLemur/switchboard/internal/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a recorded session in which the model read a file
+	work := t.TempDir()
+	target := filepath.Join(work, "notes.txt")
+	r.NoError(os.WriteFile(target, []byte("ship friday"), 0o644))
+	llm := testkit.NewAnthropic(t,
+		testkit.Reply{Tools: []testkit.ToolCall{{Name: "Read", Input: core.ToolInput{FilePath: target}}}},
+		testkit.Reply{Text: "It says ship friday."},
+		testkit.Reply{Text: "You're welcome."},
+	)
+	recordDir := t.TempDir()
+	factory := &api.BackendFactory{APIKey: "test", BaseURL: llm.URL, DefaultWorkDir: work, AllowedDirs: []string{work}, RecordDir: recordDir}
+	bot := core.NewBot(core.NewSessionManager(factory, nil), permission.NewAutoApprovePermissionChecker([]string{work}))
+	for _, text := range []string{"what do my notes say?", "thanks"} {
+		r.NoError(bot.HandleInbound(core.Inbound{SessionKey: "k", Text: text, Reply: printer{&bytes.Buffer{}}}))
+	}
+	files, err := filepath.Glob(filepath.Join(recordDir, "*.jsonl"))
+	r.NoError(err)
+	r.Len(files, 1)
+	f, err := os.Open(files[0])
+	r.NoError(err)
+	defer f.Close()
+	entries, err := Read(f)
+	r.NoError(err)
+
+	// when
+	var out bytes.Buffer
+	r.NoError(Run(context.Background(), entries, &out))
+
+	// then
+	// ... the same conversation plays out from the recorded responses
+	a.Len(entries, 5)
+	a.Equal("> what do my notes say?\n"+
+		"< It says ship friday.\n"+
+		"> thanks\n"+
+		"< You're welcome.\n", out.String())
+}
+
+func TestRun_ReportsDivergence(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a recording with a response nothing asks for
+	entries := []api.RecordEntry{
+		{Kind: "inbound", Text: "hi"},
+		{Kind: "response", Status: 200, Body: `{"id":"msg_1","type":"message","role":"assistant","model":"m","content":[{"type":"text","text":"hello"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`},
+		{Kind: "response", Status: 200, Body: `{}`},
+	}
+
+	// when
+	var out bytes.Buffer
+	r.NoError(Run(context.Background(), entries, &out))
+
+	// then
+	a.Equal("> hi\n< hello\n! 1 recorded responses were not used\n", out.String())
+}
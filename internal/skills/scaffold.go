@@ -0,0 +1,81 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// placeholderDescription is used when a skill is scaffolded without one, so
+// the new SKILL.md still parses.
+const placeholderDescription = "TODO: say what this skill does and when to use it."
+
+// Scaffold creates a new skill directory with a SKILL.md, an example script
+// in scripts/ and a note in references/. Empty instructions get a template
+// body that points at the example files. It refuses to overwrite an
+// existing skill and returns the SKILL.md path.
+func (s *FSSkillStore) Scaffold(name, description, instructions string) (string, error) {
+	if !nameRegex.MatchString(name) {
+		return "", errors.Errorf("invalid name: must be lowercase alphanumeric with single hyphens, got %q", name)
+	}
+	if description == "" {
+		description = placeholderDescription
+	}
+	if instructions == "" {
+		instructions = templateInstructions(name)
+	}
+
+	dir := filepath.Join(s.baseDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return "", errors.Errorf("skill already exists: %s", name)
+	}
+
+	fm, err := yaml.Marshal(frontmatter{Name: name, Description: description})
+	if err != nil {
+		return "", errors.Wrap(err, "encoding frontmatter")
+	}
+	skillPath := filepath.Join(dir, "SKILL.md")
+	files := []struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}{
+		{skillPath, "---\n" + string(fm) + "---\n\n" + strings.TrimLeft(instructions, "\n"), 0644},
+		{filepath.Join(dir, "scripts", "example.sh"), "#!/bin/sh\n# Example helper for the " + name + " skill.\necho \"hello from " + name + ": $*\"\n", 0755},
+		{filepath.Join(dir, "references", "notes.md"), "# " + name + " notes\n\nBackground the skill can load on demand with LoadSkillSupporting.\n", 0644},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return "", errors.Wrap(err, "creating skill dir")
+		}
+		if err := os.WriteFile(f.path, []byte(f.content), f.mode); err != nil {
+			return "", errors.Wrapf(err, "writing %s", filepath.Base(f.path))
+		}
+	}
+	return skillPath, nil
+}
+
+func templateInstructions(name string) string {
+	return "# " + name + `
+
+Describe what this skill is for.
+
+## When to Use
+
+List the requests or situations that should trigger this skill.
+
+## Usage
+
+` + "```bash\nbash scripts/example.sh <args>\n```" + `
+
+Load ` + "`references/notes.md`" + ` with LoadSkillSupporting for background.
+
+## Steps
+
+1. First step
+2. Second step
+`
+}
@@ -27,8 +27,26 @@ type frontmatter struct {
 	Description string `yaml:"description"`
 }
 
+// ValidationError is a SKILL.md problem the author can fix. Field is the
+// frontmatter key at fault, or "frontmatter" when the block itself is bad.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+func invalid(field, format string, args ...any) error {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
 var nameRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
 
+// ValidName reports whether name is a valid skill name.
+func ValidName(name string) bool {
+	return nameRegex.MatchString(name)
+}
+
 // ParseSkill parses a SKILL.md content string into a Skill.
 func ParseSkill(content, path string) (*Skill, error) {
 	fm, body, err := parseFrontmatter(content)
@@ -70,17 +88,17 @@ func ParseMetadata(content, path string) (*SkillMetadata, error) {
 
 func parseFrontmatter(content string) (*frontmatter, string, error) {
 	if !strings.HasPrefix(content, "---") {
-		return nil, "", fmt.Errorf("missing frontmatter: file must start with ---")
+		return nil, "", invalid("frontmatter", "missing frontmatter: file must start with ---")
 	}
 
 	parts := strings.SplitN(content, "---", 3)
 	if len(parts) < 3 {
-		return nil, "", fmt.Errorf("invalid frontmatter: missing closing ---")
+		return nil, "", invalid("frontmatter", "invalid frontmatter: missing closing ---")
 	}
 
 	var fm frontmatter
 	if err := yaml.Unmarshal([]byte(parts[1]), &fm); err != nil {
-		return nil, "", fmt.Errorf("invalid yaml: %w", err)
+		return nil, "", invalid("frontmatter", "invalid yaml: %v", err)
 	}
 
 	body := strings.TrimPrefix(parts[2], "\n")
@@ -89,13 +107,13 @@ func parseFrontmatter(content string) (*frontmatter, string, error) {
 
 func validateFrontmatter(fm *frontmatter) error {
 	if fm.Name == "" {
-		return fmt.Errorf("missing required field: name")
+		return invalid("name", "missing required field: name")
 	}
 	if fm.Description == "" {
-		return fmt.Errorf("missing required field: description")
+		return invalid("description", "missing required field: description")
 	}
 	if !nameRegex.MatchString(fm.Name) {
-		return fmt.Errorf("invalid name: must be lowercase alphanumeric with single hyphens, got %q", fm.Name)
+		return invalid("name", "invalid name: must be lowercase alphanumeric with single hyphens, got %q", fm.Name)
 	}
 	return nil
 }
@@ -145,6 +145,21 @@ Instructions.
 
 		require.Error(t, err)
 	})
+
+	t.Run("validation errors name the field", func(t *testing.T) {
+		for content, field := range map[string]string{
+			"no frontmatter":                         "frontmatter",
+			"---\nname: [\n---\n":                    "frontmatter",
+			"---\ndescription: Missing name.\n---\n": "name",
+			"---\nname: no-desc\n---\n":              "description",
+		} {
+			_, err := ParseSkill(content, "/path/SKILL.md")
+
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr, content)
+			assert.Equal(t, field, verr.Field, content)
+		}
+	})
 }
 
 func TestParseMetadata(t *testing.T) {
@@ -181,3 +181,47 @@ func createSupportingFile(t *testing.T, baseDir, skillName, relPath, content str
 	require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
 	require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
 }
+
+func TestFSSkillStore_Scaffold(t *testing.T) {
+	t.Run("creates a loadable skill with example files", func(t *testing.T) {
+		dir := t.TempDir()
+		store := NewFSSkillStore(dir)
+
+		path, err := store.Scaffold("deploy-app", "Deploy the app: staging or prod.", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "deploy-app", "SKILL.md"), path)
+		skill, err := store.Load("deploy-app")
+		require.NoError(t, err)
+		assert.Equal(t, "Deploy the app: staging or prod.", skill.Description)
+		assert.Contains(t, skill.Instructions, "scripts/example.sh")
+		script, err := store.LoadSupporting("deploy-app", "scripts/example.sh")
+		require.NoError(t, err)
+		assert.Contains(t, string(script), "#!/bin/sh")
+		_, err = store.LoadSupporting("deploy-app", "references/notes.md")
+		assert.NoError(t, err)
+	})
+
+	t.Run("uses given instructions and a placeholder description", func(t *testing.T) {
+		store := NewFSSkillStore(t.TempDir())
+
+		_, err := store.Scaffold("notes", "", "# Notes\n\nTake notes.\n")
+
+		require.NoError(t, err)
+		skill, err := store.Load("notes")
+		require.NoError(t, err)
+		assert.Equal(t, placeholderDescription, skill.Description)
+		assert.Equal(t, "\n# Notes\n\nTake notes.\n", skill.Instructions)
+	})
+
+	t.Run("refuses bad names and existing skills", func(t *testing.T) {
+		dir := t.TempDir()
+		createTestSkill(t, dir, "taken", "Already here.")
+		store := NewFSSkillStore(dir)
+
+		_, err := store.Scaffold("Bad_Name", "", "")
+		assert.Error(t, err)
+		_, err = store.Scaffold("taken", "", "")
+		assert.ErrorContains(t, err, "already exists")
+	})
+}
@@ -0,0 +1,99 @@
+// Package stt transcribes speech for Discord voice mode.
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultBaseURL = "https://api.openai.com"
+	DefaultModel   = "whisper-1"
+)
+
+// OpenAI transcribes through an OpenAI-compatible /v1/audio/transcriptions
+// endpoint, which local servers (e.g. faster-whisper-server, whisper.cpp)
+// also expose.
+type OpenAI struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Client  *http.Client
+}
+
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	return &OpenAI{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+		Client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Transcribe returns the text spoken in audio. name's extension tells the
+// endpoint the format, e.g. "utterance.ogg".
+func (o *OpenAI) Transcribe(ctx context.Context, audio []byte, name string) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("model", o.Model); err != nil {
+		return "", errors.Wrap(err, "encoding transcription request")
+	}
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding transcription request")
+	}
+	if _, err := fw.Write(audio); err != nil {
+		return "", errors.Wrap(err, "encoding transcription request")
+	}
+	if err := mw.Close(); err != nil {
+		return "", errors.Wrap(err, "encoding transcription request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+"/v1/audio/transcriptions", &body)
+	if err != nil {
+		return "", errors.Wrap(err, "creating transcription request")
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "requesting transcription")
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "reading transcription")
+	}
+	if resp.StatusCode >= 400 {
+		msg := string(data)
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return "", errors.Errorf("transcription endpoint returned %d: %s", resp.StatusCode, msg)
+	}
+	var out struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", errors.Wrap(err, "decoding transcription")
+	}
+	return strings.TrimSpace(out.Text), nil
+}
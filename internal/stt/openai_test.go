@@ -0,0 +1,56 @@
+package stt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAI_Transcribe(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a transcription endpoint that records the upload
+	var model, fileName, audio, auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, auth = req.URL.Path, req.Header.Get("Authorization")
+		model = req.FormValue("model")
+		f, h, err := req.FormFile("file")
+		if err == nil {
+			data, _ := io.ReadAll(f)
+			fileName, audio = h.Filename, string(data)
+		}
+		_, _ = w.Write([]byte(`{"text":" Switchboard, run the tests. "}`))
+	}))
+	defer srv.Close()
+	o := NewOpenAI(srv.URL+"/", "sk-stt", "")
+
+	// when
+	text, err := o.Transcribe(context.Background(), []byte("OggS"), "utterance.ogg")
+
+	// then
+	r.NoError(err)
+	a.Equal("Switchboard, run the tests.", text)
+	a.Equal("/v1/audio/transcriptions", path)
+	a.Equal("Bearer sk-stt", auth)
+	a.Equal(DefaultModel, model)
+	a.Equal("utterance.ogg", fileName)
+	a.Equal("OggS", audio)
+}
+
+func TestOpenAI_TranscribeErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAI(srv.URL, "", "").Transcribe(context.Background(), nil, "a.ogg")
+
+	assert.ErrorContains(t, err, "transcription endpoint returned 400: unsupported format")
+}
@@ -0,0 +1,210 @@
+// Package testkit provides in-memory stand-ins for the services the bot
+// talks to, so end-to-end tests can drive a message from a Discord mention
+// through the agent loop and back without a network.
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+// Reply is one scripted Messages API response. With Tools set the model
+// asks for those tool calls and the loop continues; otherwise Text ends the
+// turn.
+type Reply struct {
+	Text  string
+	Tools []ToolCall
+}
+
+// ToolCall is a tool_use block in a scripted reply.
+type ToolCall struct {
+	Name  string
+	Input core.ToolInput
+}
+
+// Request is a recorded Messages API request, trimmed to what tests look
+// at.
+type Request struct {
+	Model    string    `json:"model"`
+	System   []Block   `json:"system"`
+	Messages []Message `json:"messages"`
+}
+
+// Message is one turn of a recorded request.
+type Message struct {
+	Role    string  `json:"role"`
+	Content []Block `json:"content"`
+}
+
+// Block is a content block of a recorded message. Content holds a
+// tool_result's content, a string or a list of text blocks.
+type Block struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	Name      string          `json:"name"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// ResultText returns a tool_result block's text.
+func (b Block) ResultText() string {
+	var s string
+	if json.Unmarshal(b.Content, &s) == nil {
+		return s
+	}
+	var blocks []Block
+	_ = json.Unmarshal(b.Content, &blocks)
+	var parts []string
+	for _, c := range blocks {
+		parts = append(parts, c.Text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ToolResults returns the tool_result blocks of the request's last
+// message.
+func (r Request) ToolResults() []Block {
+	if len(r.Messages) == 0 {
+		return nil
+	}
+	var results []Block
+	for _, b := range r.Messages[len(r.Messages)-1].Content {
+		if b.Type == "tool_result" {
+			results = append(results, b)
+		}
+	}
+	return results
+}
+
+// Anthropic is a fake Messages API. Each request to /v1/messages gets the
+// next scripted reply; a request past the end of the script fails with a
+// 400, which the backend doesn't retry, so the test sees it at once.
+type Anthropic struct {
+	URL string
+
+	mu       sync.Mutex
+	replies  []Reply
+	requests []Request
+}
+
+// NewAnthropic starts a fake API answering with replies in order. It is
+// closed when the test ends.
+func NewAnthropic(t testing.TB, replies ...Reply) *Anthropic {
+	t.Helper()
+	f := &Anthropic{replies: replies}
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+	f.URL = server.URL
+	return f
+}
+
+// Script appends replies to the ones still unsent.
+func (f *Anthropic) Script(replies ...Reply) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies = append(f.replies, replies...)
+}
+
+// Requests returns the requests received so far.
+func (f *Anthropic) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+func (f *Anthropic) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/messages" {
+		writeAPIError(w, http.StatusNotFound, "not_found_error", r.Method+" "+r.URL.Path+" is not faked")
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	n := len(f.requests)
+	if len(f.replies) == 0 {
+		f.mu.Unlock()
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("request %d has no scripted reply", n))
+		return
+	}
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	f.mu.Unlock()
+
+	msg := apiMessage{
+		ID:         fmt.Sprintf("msg_%d", n),
+		Type:       "message",
+		Role:       "assistant",
+		Content:    []apiBlock{},
+		Model:      req.Model,
+		StopReason: "end_turn",
+		Usage:      apiUsage{InputTokens: 1, OutputTokens: 1},
+	}
+	if reply.Text != "" {
+		msg.Content = append(msg.Content, apiBlock{Type: "text", Text: reply.Text})
+	}
+	for i, call := range reply.Tools {
+		msg.StopReason = "tool_use"
+		msg.Content = append(msg.Content, apiBlock{
+			Type:  "tool_use",
+			ID:    fmt.Sprintf("toolu_%d_%d", n, i),
+			Name:  call.Name,
+			Input: &call.Input,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(msg)
+}
+
+// apiMessage is the wire form of a Messages API response.
+type apiMessage struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	Role       string     `json:"role"`
+	Content    []apiBlock `json:"content"`
+	Model      string     `json:"model"`
+	StopReason string     `json:"stop_reason"`
+	Usage      apiUsage   `json:"usage"`
+}
+
+type apiBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input *core.ToolInput `json:"input,omitempty"`
+}
+
+type apiUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type apiError struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func writeAPIError(w http.ResponseWriter, status int, kind, msg string) {
+	body := apiError{Type: "error"}
+	body.Error.Type = kind
+	body.Error.Message = msg
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
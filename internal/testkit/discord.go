@@ -0,0 +1,209 @@
+package testkit
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+)
+
+// Discord is an in-memory Discord gateway and REST API for one bot. Pass
+// it to discord.New in place of a wrapped discordgo session: Send then
+// delivers a message as if a user typed it, and everything the bot posts
+// is recorded per channel.
+//
+// Handlers run on the caller's goroutine, so once Send returns the turn it
+// started has finished, apart from updates still waiting to be batched.
+type Discord struct {
+	BotID   string
+	GuildID string
+
+	mu        sync.Mutex
+	nextID    int
+	handlers  []interface{}
+	threads   []string
+	parents   map[string]string // thread ID -> parent channel ID
+	contents  map[string]string // message ID -> content
+	posts     map[string][]string
+	files     map[string][]string
+	reactions map[string][]string
+}
+
+// NewDiscord returns a fake for a bot with botID in server guildID.
+func NewDiscord(botID, guildID string) *Discord {
+	return &Discord{
+		BotID:     botID,
+		GuildID:   guildID,
+		parents:   make(map[string]string),
+		contents:  make(map[string]string),
+		posts:     make(map[string][]string),
+		files:     make(map[string][]string),
+		reactions: make(map[string][]string),
+	}
+}
+
+// Send delivers content from authorID in channelID and returns the new
+// message's ID.
+func (d *Discord) Send(channelID, authorID, content string) string {
+	d.mu.Lock()
+	id := d.newID("msg")
+	d.contents[id] = content
+	d.mu.Unlock()
+	d.create(id, channelID, authorID, content)
+	return id
+}
+
+// Redeliver sends messageID's MESSAGE_CREATE again, as the gateway can
+// after a reconnect.
+func (d *Discord) Redeliver(channelID, messageID, authorID string) {
+	d.mu.Lock()
+	content := d.contents[messageID]
+	d.mu.Unlock()
+	d.create(messageID, channelID, authorID, content)
+}
+
+func (d *Discord) create(id, channelID, authorID, content string) {
+	d.mu.Lock()
+	handlers := append([]interface{}(nil), d.handlers...)
+	d.mu.Unlock()
+
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        id,
+		ChannelID: channelID,
+		GuildID:   d.GuildID,
+		Content:   content,
+		Author:    &discordgo.User{ID: authorID},
+	}}
+	for _, h := range handlers {
+		if h, ok := h.(func(*discordgo.Session, *discordgo.MessageCreate)); ok {
+			h(nil, m)
+		}
+	}
+}
+
+// Mention sends "<@bot> text" and returns the message ID.
+func (d *Discord) Mention(channelID, authorID, text string) string {
+	return d.Send(channelID, authorID, "<@"+d.BotID+"> "+text)
+}
+
+// Edit changes a sent message's content, as a user editing it would.
+func (d *Discord) Edit(channelID, messageID, authorID, content string) {
+	d.mu.Lock()
+	d.contents[messageID] = content
+	handlers := append([]interface{}(nil), d.handlers...)
+	d.mu.Unlock()
+
+	m := &discordgo.MessageUpdate{Message: &discordgo.Message{
+		ID:        messageID,
+		ChannelID: channelID,
+		GuildID:   d.GuildID,
+		Content:   content,
+		Author:    &discordgo.User{ID: authorID},
+	}}
+	for _, h := range handlers {
+		if h, ok := h.(func(*discordgo.Session, *discordgo.MessageUpdate)); ok {
+			h(nil, m)
+		}
+	}
+}
+
+// Threads returns the threads the bot opened under channelID, oldest
+// first.
+func (d *Discord) Threads(channelID string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var threads []string
+	for _, id := range d.threads {
+		if d.parents[id] == channelID {
+			threads = append(threads, id)
+		}
+	}
+	return threads
+}
+
+// Posts returns the messages the bot sent to channelID.
+func (d *Discord) Posts(channelID string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.posts[channelID]...)
+}
+
+// Files returns the names of files the bot attached in channelID.
+func (d *Discord) Files(channelID string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.files[channelID]...)
+}
+
+// Reactions returns the emoji the bot added to messageID.
+func (d *Discord) Reactions(messageID string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.reactions[messageID]...)
+}
+
+// newID must be called with d.mu held.
+func (d *Discord) newID(kind string) string {
+	d.nextID++
+	return kind + "-" + strconv.Itoa(d.nextID)
+}
+
+func (d *Discord) AddHandler(handler interface{}) func() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, handler)
+	return func() {}
+}
+
+func (d *Discord) ChannelState(channelID string) (*discordgo.Channel, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if parent, ok := d.parents[channelID]; ok {
+		return &discordgo.Channel{ID: channelID, GuildID: d.GuildID, ParentID: parent, Type: discordgo.ChannelTypeGuildPublicThread}, nil
+	}
+	return &discordgo.Channel{ID: channelID, GuildID: d.GuildID, Type: discordgo.ChannelTypeGuildText}, nil
+}
+
+func (d *Discord) ChannelMessageSend(channelID, content string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.contents[d.newID("msg")] = content
+	d.posts[channelID] = append(d.posts[channelID], content)
+	return nil
+}
+
+func (d *Discord) ChannelTyping(string) error { return nil }
+
+func (d *Discord) MessageReactionAdd(_, messageID, emoji string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reactions[messageID] = append(d.reactions[messageID], emoji)
+	return nil
+}
+
+func (d *Discord) ChannelFileSend(channelID, name string, _ []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.files[channelID] = append(d.files[channelID], name)
+	return nil
+}
+
+func (d *Discord) ChannelMessage(_, messageID string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	content, ok := d.contents[messageID]
+	if !ok {
+		return "", errors.Errorf("unknown message %s", messageID)
+	}
+	return content, nil
+}
+
+func (d *Discord) MessageThreadStartComplex(channelID, _, _ string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := d.newID("thread")
+	d.threads = append(d.threads, id)
+	d.parents[id] = channelID
+	return id, nil
+}
@@ -0,0 +1,175 @@
+package testkit_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/api"
+	"github.com/TheLazyLemur/switchboard/internal/channels/discord"
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/permission"
+	"github.com/TheLazyLemur/switchboard/internal/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBot wires a bot the way cmd/switchboard does, against the fake
+// Discord and Messages API, with tool access confined to dir.
+func startBot(t *testing.T, dc *testkit.Discord, llm *testkit.Anthropic, dir string) {
+	t.Helper()
+	factory := &api.BackendFactory{
+		APIKey:         "test",
+		BaseURL:        llm.URL,
+		Model:          "test-model",
+		DefaultWorkDir: dir,
+		AllowedDirs:    []string{dir},
+	}
+	bot := core.NewBot(core.NewSessionManager(factory, nil), permission.NewAutoApprovePermissionChecker([]string{dir}))
+	plugin := discord.New(discord.Config{BotID: dc.BotID, AllowedUsers: []string{"user-1"}}, dc)
+	require.NoError(t, plugin.Start(context.Background(), func(in core.Inbound) {
+		if err := bot.HandleInbound(in); err != nil {
+			t.Logf("handling inbound: %v", err)
+		}
+	}))
+	bot.AddChannel(plugin.ID(), plugin)
+}
+
+func TestE2E_MentionRunsToolAndRepliesInThread(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.4.2\n"), 0o644))
+	dc := testkit.NewDiscord("bot-1", "guild-1")
+	llm := testkit.NewAnthropic(t,
+		testkit.Reply{Text: "Checking.", Tools: []testkit.ToolCall{{Name: "Read", Input: core.ToolInput{FilePath: filepath.Join(dir, "VERSION")}}}},
+		testkit.Reply{Text: "The version is 1.4.2."},
+	)
+	startBot(t, dc, llm, dir)
+
+	// when
+	dc.Mention("general", "user-1", "what version are we on?")
+
+	// then
+	// ... the bot opened a thread, read the file and answered there with
+	// the text of both replies
+	threads := dc.Threads("general")
+	r.Len(threads, 1)
+	a.Equal([]string{"Checking.\nThe version is 1.4.2."}, dc.Posts(threads[0]))
+	reqs := llm.Requests()
+	r.Len(reqs, 2)
+	a.Equal("what version are we on?", reqs[0].Messages[0].Content[0].Text)
+	results := reqs[1].ToolResults()
+	r.Len(results, 1)
+	a.False(results[0].IsError)
+	a.Contains(results[0].ResultText(), "1.4.2")
+}
+
+func TestE2E_DeniedToolCallIsReportedToTheModel(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a secret outside the directory tools may touch
+	dir := t.TempDir()
+	secret := filepath.Join(t.TempDir(), "secret.txt")
+	r.NoError(os.WriteFile(secret, []byte("hunter2"), 0o600))
+	dc := testkit.NewDiscord("bot-1", "guild-1")
+	llm := testkit.NewAnthropic(t,
+		testkit.Reply{Tools: []testkit.ToolCall{{Name: "Read", Input: core.ToolInput{FilePath: secret}}}},
+		testkit.Reply{Text: "I can't read that file."},
+	)
+	startBot(t, dc, llm, dir)
+
+	// when
+	dc.Mention("general", "user-1", "cat "+secret)
+
+	// then
+	threads := dc.Threads("general")
+	r.Len(threads, 1)
+	a.Equal([]string{"I can't read that file."}, dc.Posts(threads[0]))
+	results := llm.Requests()[1].ToolResults()
+	r.Len(results, 1)
+	a.True(results[0].IsError)
+	a.Contains(results[0].ResultText(), "outside allowed directories")
+	a.NotContains(results[0].ResultText(), "hunter2")
+}
+
+func TestE2E_ThreadReplyContinuesTheSession(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dc := testkit.NewDiscord("bot-1", "guild-1")
+	llm := testkit.NewAnthropic(t, testkit.Reply{Text: "Hi!"}, testkit.Reply{Text: "You said hello."})
+	startBot(t, dc, llm, t.TempDir())
+	dc.Mention("general", "user-1", "hello")
+	threads := dc.Threads("general")
+	r.Len(threads, 1)
+
+	// when
+	dc.Mention(threads[0], "user-1", "what did I say?")
+
+	// then
+	// ... no second thread, and the model saw the whole conversation
+	a.Len(dc.Threads("general"), 1)
+	a.Equal([]string{"Hi!", "You said hello."}, dc.Posts(threads[0]))
+	reqs := llm.Requests()
+	r.Len(reqs, 2)
+	r.Len(reqs[1].Messages, 3)
+	a.Equal("hello", reqs[1].Messages[0].Content[0].Text)
+	a.Equal("what did I say?", reqs[1].Messages[2].Content[0].Text)
+}
+
+func TestE2E_RedeliveredMessageRunsOneTurn(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dc := testkit.NewDiscord("bot-1", "guild-1")
+	llm := testkit.NewAnthropic(t, testkit.Reply{Text: "Hi!"})
+	startBot(t, dc, llm, t.TempDir())
+	id := dc.Mention("general", "user-1", "hello")
+
+	// when
+	// ... the gateway reconnects and sends the same message again
+	dc.Redeliver("general", id, "user-1")
+
+	// then
+	threads := dc.Threads("general")
+	r.Len(threads, 1)
+	a.Equal([]string{"Hi!"}, dc.Posts(threads[0]))
+	a.Len(llm.Requests(), 1)
+}
+
+func TestE2E_PanicInHandlerIsReportedAndBotKeepsRunning(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a delivery that panics on its first message
+	dc := testkit.NewDiscord("bot-1", "guild-1")
+	plugin := discord.New(discord.Config{BotID: dc.BotID, AllowedUsers: []string{"user-1"}}, dc)
+	var delivered []string
+	r.NoError(plugin.Start(context.Background(), func(in core.Inbound) {
+		delivered = append(delivered, in.Text)
+		if len(delivered) == 1 {
+			panic("boom")
+		}
+	}))
+
+	// when
+	dc.Mention("general", "user-1", "first")
+	dc.Mention("general", "user-1", "second")
+
+	// then
+	// ... the panicking message was answered where it was sent, and the
+	// next one was still handled
+	posts := dc.Posts("general")
+	r.Len(posts, 1)
+	a.Contains(posts[0], "Something went wrong while handling that message.")
+	a.Equal([]string{"first", "second"}, delivered)
+}
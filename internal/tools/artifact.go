@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// maxArtifactBytes keeps artifacts under Discord's default upload limit.
+const maxArtifactBytes = 8 * 1024 * 1024
+
+func executeWriteArtifact(input core.ToolInput, deps Deps) (string, bool) {
+	if input.Name == "" {
+		return "missing name argument", true
+	}
+	if len(input.Content) > maxArtifactBytes {
+		return fmt.Sprintf("artifact too large: %d bytes (max %d)", len(input.Content), maxArtifactBytes), true
+	}
+	rel, err := artifactPath(input.Name)
+	if err != nil {
+		return err.Error(), true
+	}
+
+	var notes []string
+	if input.Save {
+		if deps.WorkDir == "" {
+			return "no working directory to save into", true
+		}
+		dest, err := savePath(deps, rel)
+		if err != nil {
+			return "error saving artifact: " + err.Error(), true
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return "error creating directory: " + err.Error(), true
+		}
+		if err := os.WriteFile(dest, []byte(input.Content), 0o644); err != nil {
+			return "error saving artifact: " + err.Error(), true
+		}
+		notes = append(notes, "saved to "+dest)
+	}
+
+	fs, ok := deps.Outbound.(core.FileSender)
+	if !ok {
+		return "this channel cannot receive files", true
+	}
+	if err := fs.SendFile(filepath.Base(rel), []byte(input.Content)); err != nil {
+		return "error sending artifact: " + err.Error(), true
+	}
+	notes = append([]string{"artifact sent"}, notes...)
+	return strings.Join(notes, "; "), false
+}
+
+// artifactPath validates a model-supplied artifact name as a relative path
+// that stays inside the working directory.
+func artifactPath(name string) (string, error) {
+	clean := filepath.Clean(name)
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid artifact name: %s", name)
+	}
+	return clean, nil
+}
+
+// savePath resolves where a saved artifact lands: rel under the work dir,
+// with the deepest part that already exists resolved through containedPath
+// so a symlink planted in the work dir can't redirect the write.
+func savePath(deps Deps, rel string) (string, error) {
+	dest := filepath.Join(deps.WorkDir, rel)
+	existing := dest
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	real, err := deps.containedPath(existing)
+	if err != nil {
+		return "", err
+	}
+	rest, err := filepath.Rel(existing, dest)
+	if err != nil {
+		return "", errors.Wrap(err, "resolving artifact path")
+	}
+	return filepath.Join(real, rest), nil
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fileResponder struct {
+	mockResponder
+	names    []string
+	contents []string
+}
+
+func (f *fileResponder) SendFile(name string, content []byte) error {
+	f.names = append(f.names, name)
+	f.contents = append(f.contents, string(content))
+	return nil
+}
+
+func TestExecute_WriteArtifact_SendsFile(t *testing.T) {
+	a := assert.New(t)
+	out := &fileResponder{}
+
+	result, isErr := Execute(context.Background(), "write_artifact", core.ToolInput{Name: "fix.patch", Content: "diff --git"}, Deps{Outbound: out})
+
+	a.False(isErr)
+	a.Equal("artifact sent", result)
+	a.Equal([]string{"fix.patch"}, out.names)
+	a.Equal([]string{"diff --git"}, out.contents)
+}
+
+func TestExecute_WriteArtifact_SaveWritesUnderWorkDir(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	out := &fileResponder{}
+
+	// when
+	result, isErr := Execute(context.Background(), "write_artifact", core.ToolInput{Name: "scripts/run.sh", Content: "echo hi", Save: true}, Deps{Outbound: out, WorkDir: dir})
+
+	// then
+	// ... the file lands under workDir and is uploaded under its base name
+	a.False(isErr, result)
+	data, err := os.ReadFile(filepath.Join(dir, "scripts", "run.sh"))
+	r.NoError(err)
+	a.Equal("echo hi", string(data))
+	a.Equal([]string{"run.sh"}, out.names)
+	a.Contains(result, "saved to")
+}
+
+func TestExecute_WriteArtifact_SaveRejectsEscapingSymlink(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a link in the work dir pointing outside the allowed directories
+	dir := t.TempDir()
+	outside := t.TempDir()
+	r.NoError(os.Symlink(outside, filepath.Join(dir, "out")))
+	deps := Deps{Outbound: &fileResponder{}, WorkDir: dir, AllowedDirs: []string{dir}}
+
+	// when
+	result, isErr := Execute(context.Background(), "write_artifact", core.ToolInput{Name: "out/run.sh", Content: "echo hi", Save: true}, deps)
+
+	// then
+	a.True(isErr)
+	a.Contains(result, "outside allowed directories")
+	a.NoFileExists(filepath.Join(outside, "run.sh"))
+}
+
+func TestExecute_WriteArtifact_RejectsEscapingNames(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+
+	for _, name := range []string{"../evil.sh", "/etc/cron.d/x", ".."} {
+		result, isErr := Execute(context.Background(), "write_artifact", core.ToolInput{Name: name, Content: "x", Save: true}, Deps{Outbound: &fileResponder{}, WorkDir: dir})
+		a.True(isErr, name)
+		a.Contains(result, "invalid artifact name", name)
+	}
+}
+
+func TestExecute_WriteArtifact_ChannelWithoutFiles(t *testing.T) {
+	a := assert.New(t)
+
+	result, isErr := Execute(context.Background(), "write_artifact", core.ToolInput{Name: "a.txt", Content: "x"}, Deps{Outbound: &mockResponder{}})
+
+	a.True(isErr)
+	a.Equal("this channel cannot receive files", result)
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// binarySniffLen is how much of a file isBinary looks at.
+	binarySniffLen = 8000
+	// maxHexBytes caps the hexdump Read returns for a binary file.
+	maxHexBytes = 512
+)
+
+// magicTypes name binary formats http.DetectContentType reports as
+// application/octet-stream.
+var magicTypes = []struct {
+	magic string
+	name  string
+}{
+	{"\x7fELF", "ELF executable"},
+	{"MZ", "Windows executable"},
+	{"\xcf\xfa\xed\xfe", "Mach-O executable"},
+	{"\xca\xfe\xba\xbe", "Mach-O universal binary or Java class"},
+	{"SQLite format 3\x00", "SQLite database"},
+	{"\x00asm", "WebAssembly module"},
+	{"\xfd7zXZ\x00", "xz archive"},
+	{"(\xb5/\xfd", "zstd archive"},
+	{"7z\xbc\xaf\x27\x1c", "7-Zip archive"},
+}
+
+// isBinary reports whether content looks like something other than UTF-8
+// text: a NUL byte or an invalid sequence in its first few KiB.
+func isBinary(content []byte) bool {
+	head := content
+	if len(head) > binarySniffLen {
+		head = head[:binarySniffLen]
+		// Don't count a rune cut in half by the sniff window.
+		for i := 1; i < utf8.UTFMax && i <= len(head); i++ {
+			if utf8.RuneStart(head[len(head)-i]) {
+				if !utf8.FullRune(head[len(head)-i:]) {
+					head = head[:len(head)-i]
+				}
+				break
+			}
+		}
+	}
+	return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(head)
+}
+
+// fileType names content's format from its magic bytes.
+func fileType(content []byte) string {
+	for _, m := range magicTypes {
+		if bytes.HasPrefix(content, []byte(m.magic)) {
+			return m.name
+		}
+	}
+	return strings.TrimSuffix(http.DetectContentType(content), "; charset=utf-8")
+}
+
+// describeBinary summarises a binary file for the model instead of
+// pasting its bytes, with a hexdump of the start when withHex is set.
+func describeBinary(content []byte, withHex bool) string {
+	summary := fmt.Sprintf("binary file, %d bytes, type %s", len(content), fileType(content))
+	if !withHex {
+		return summary + "\nRead it again with hex: true to see the first bytes as a hexdump."
+	}
+	head := content[:min(len(content), maxHexBytes)]
+	return summary + fmt.Sprintf("\nfirst %d bytes:\n", len(head)) + hex.Dump(head)
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBinary(t *testing.T) {
+	a := assert.New(t)
+
+	a.False(isBinary([]byte("plain text\n")))
+	a.False(isBinary([]byte("héllo wörld")))
+	a.False(isBinary([]byte(strings.Repeat("a", binarySniffLen-1) + "é")))
+	a.True(isBinary([]byte("ab\x00cd")))
+	a.True(isBinary([]byte{0xff, 0xfe, 0x41}))
+}
+
+func TestExecuteRead_BinarySummary(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an ELF-looking file
+	path := filepath.Join(t.TempDir(), "tool")
+	content := append([]byte("\x7fELF\x02\x01\x01\x00"), make([]byte, 1000)...)
+	r.NoError(os.WriteFile(path, content, 0o644))
+
+	// when
+	plain, plainErr := executeRead(core.ToolInput{FilePath: path}, Deps{})
+	dump, dumpErr := executeRead(core.ToolInput{FilePath: path, Hex: true}, Deps{})
+
+	// then
+	a.False(plainErr)
+	a.Equal("binary file, 1008 bytes, type ELF executable\nRead it again with hex: true to see the first bytes as a hexdump.", plain)
+	a.False(dumpErr)
+	a.Contains(dump, "first 512 bytes:\n00000000  7f 45 4c 46 02 01 01 00")
+	a.NotContains(dump, "00000200")
+}
+
+func TestExecuteRead_ImageThatCannotBePrepared(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a WebP over the vision size limit, which can't be downscaled
+	path := filepath.Join(t.TempDir(), "big.webp")
+	r.NoError(os.WriteFile(path, make([]byte, 6*1024*1024), 0o644))
+
+	// when
+	out, isErr := executeRead(core.ToolInput{FilePath: path}, Deps{})
+
+	// then
+	a.False(isErr)
+	a.False(strings.HasPrefix(out, ImageSentinel))
+	a.Contains(out, "binary file, 6291456 bytes")
+	a.Contains(out, "image not attached: webp image too large to send")
+}
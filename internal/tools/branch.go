@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// gitIdentity authors session branch commits and merges, so they are easy
+// to tell apart from the user's own.
+var gitIdentity = []string{"-c", "user.name=Switchboard", "-c", "user.email=switchboard@localhost"}
+
+// SessionBranchPrefix names session branches: <prefix><session ID>.
+const SessionBranchPrefix = "switchboard/session-"
+
+// SessionBranch is a git branch a session commits its edits to, created
+// from Base when the session started.
+type SessionBranch struct {
+	Name string
+	Base string
+}
+
+// StartBranch creates and checks out branch name in the repository holding
+// deps.WorkDir. It returns nil without an error when WorkDir is not in a
+// git work tree, HEAD is detached or unborn, or the work tree has
+// uncommitted changes, which would otherwise end up on the branch. It
+// refuses when another session's branch is checked out, since both
+// sessions would then commit to whichever branch HEAD last pointed at.
+func StartBranch(ctx context.Context, deps Deps, name string) (*SessionBranch, error) {
+	if deps.WorkDir == "" {
+		return nil, nil
+	}
+	base, err := gitOutput(ctx, deps, nil, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		return nil, nil
+	}
+	if strings.HasPrefix(base, SessionBranchPrefix) {
+		return nil, errors.Errorf("session branch %s is already checked out", base)
+	}
+	if _, err := gitOutput(ctx, deps, nil, "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return nil, nil
+	}
+	status, err := gitOutput(ctx, deps, nil, "status", "--porcelain")
+	if err != nil || status != "" {
+		return nil, nil
+	}
+	if _, err := gitOutput(ctx, deps, nil, "checkout", "-q", "-b", name); err != nil {
+		return nil, errors.Wrapf(err, "creating branch %s", name)
+	}
+	return &SessionBranch{Name: name, Base: base}, nil
+}
+
+// Commit commits every change in the work tree to the branch. It does
+// nothing when there is nothing to commit, and fails when the branch is no
+// longer checked out rather than committing elsewhere.
+func (s *SessionBranch) Commit(ctx context.Context, deps Deps, message string) error {
+	if current, _ := gitOutput(ctx, deps, nil, "symbolic-ref", "--short", "-q", "HEAD"); current != s.Name {
+		return errors.Errorf("%s is no longer checked out (HEAD is %q)", s.Name, current)
+	}
+	if _, err := gitOutput(ctx, deps, nil, "add", "-A"); err != nil {
+		return errors.Wrap(err, "staging changes")
+	}
+	if _, err := gitOutput(ctx, deps, nil, "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	args := append(slices.Clip(gitIdentity), "commit", "-q", "--no-verify", "-m", message)
+	if _, err := gitOutput(ctx, deps, nil, args...); err != nil {
+		return errors.Wrap(err, "committing changes")
+	}
+	return nil
+}
+
+// Apply commits anything pending, merges the branch into Base and deletes
+// it. On a conflict the merge is aborted and the branch stays checked out.
+func (s *SessionBranch) Apply(ctx context.Context, deps Deps) error {
+	if err := s.Commit(ctx, deps, "Pending session changes"); err != nil {
+		return err
+	}
+	if _, err := gitOutput(ctx, deps, nil, "checkout", "-q", s.Base); err != nil {
+		return errors.Wrapf(err, "checking out %s", s.Base)
+	}
+	args := append(slices.Clip(gitIdentity), "merge", "-q", "--no-edit", s.Name)
+	if _, err := gitOutput(ctx, deps, nil, args...); err != nil {
+		gitOutput(ctx, deps, nil, "merge", "--abort")
+		gitOutput(ctx, deps, nil, "checkout", "-q", s.Name)
+		return errors.Errorf("merging %s into %s failed; resolve it by hand", s.Name, s.Base)
+	}
+	if _, err := gitOutput(ctx, deps, nil, "branch", "-q", "-d", s.Name); err != nil {
+		return errors.Wrapf(err, "deleting %s", s.Name)
+	}
+	return nil
+}
+
+// Discard throws away the branch and every change made on it, including
+// uncommitted ones, and checks out Base again.
+func (s *SessionBranch) Discard(ctx context.Context, deps Deps) error {
+	if err := s.Commit(ctx, deps, "Discarded session changes"); err != nil {
+		return err
+	}
+	if _, err := gitOutput(ctx, deps, nil, "checkout", "-q", s.Base); err != nil {
+		return errors.Wrapf(err, "checking out %s", s.Base)
+	}
+	if _, err := gitOutput(ctx, deps, nil, "branch", "-q", "-D", s.Name); err != nil {
+		return errors.Wrapf(err, "deleting %s", s.Name)
+	}
+	return nil
+}
+
+// Leave commits anything pending and checks out Base again, keeping the
+// branch so its changes can still be merged by hand. It is for sessions
+// that end without /apply or /discard.
+func (s *SessionBranch) Leave(ctx context.Context, deps Deps) error {
+	if err := s.Commit(ctx, deps, "Pending session changes"); err != nil {
+		return err
+	}
+	if _, err := gitOutput(ctx, deps, nil, "checkout", "-q", s.Base); err != nil {
+		return errors.Wrapf(err, "checking out %s", s.Base)
+	}
+	return nil
+}
+
+// CommitMessage turns a user message into a one-line commit subject.
+func CommitMessage(text string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if r := []rune(subject); len(r) > 72 {
+		subject = string(r[:69]) + "..."
+	}
+	if subject == "" {
+		return "Session changes"
+	}
+	return subject
+}
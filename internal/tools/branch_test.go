@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitRepo makes a repository on main with one commit.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("hi\n"), 0o644))
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := gitOutput(context.Background(), Deps{WorkDir: dir}, nil, args...)
+	require.NoError(t, err)
+	return out
+}
+
+func TestSessionBranch_Apply(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := gitRepo(t)
+	deps := Deps{WorkDir: dir}
+	ctx := context.Background()
+	branch, err := StartBranch(ctx, deps, "switchboard/session-1")
+	r.NoError(err)
+	r.NotNil(branch)
+
+	// when
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	r.NoError(branch.Commit(ctx, deps, CommitMessage("add a main package\nplease")))
+	r.NoError(os.WriteFile(filepath.Join(dir, "README"), []byte("hello\n"), 0o644))
+	err = branch.Apply(ctx, deps)
+
+	// then
+	// ... both edits land on main and the branch is gone
+	r.NoError(err)
+	a.Equal("main", git(t, dir, "symbolic-ref", "--short", "HEAD"))
+	log := git(t, dir, "log", "--format=%s|%an")
+	a.Contains(log, "add a main package|Switchboard")
+	a.Contains(log, "Pending session changes|Switchboard")
+	a.Empty(git(t, dir, "branch", "--list", "switchboard/*"))
+	a.Empty(git(t, dir, "status", "--porcelain"))
+}
+
+func TestSessionBranch_Discard(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := gitRepo(t)
+	deps := Deps{WorkDir: dir}
+	ctx := context.Background()
+	branch, err := StartBranch(ctx, deps, "switchboard/session-1")
+	r.NoError(err)
+	r.NoError(os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("x\n"), 0o644))
+
+	// when
+	err = branch.Discard(ctx, deps)
+
+	// then
+	r.NoError(err)
+	a.Equal("main", git(t, dir, "symbolic-ref", "--short", "HEAD"))
+	a.NoFileExists(filepath.Join(dir, "scratch.txt"))
+	a.Empty(git(t, dir, "branch", "--list", "switchboard/*"))
+}
+
+func TestStartBranch_Skips(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a repo with uncommitted work and a directory outside git
+	dirty := gitRepo(t)
+	r.NoError(os.WriteFile(filepath.Join(dirty, "README"), []byte("edited\n"), 0o644))
+	plain := t.TempDir()
+
+	// when
+	dirtyBranch, dirtyErr := StartBranch(context.Background(), Deps{WorkDir: dirty}, "switchboard/session-1")
+	plainBranch, plainErr := StartBranch(context.Background(), Deps{WorkDir: plain}, "switchboard/session-1")
+
+	// then
+	a.NoError(dirtyErr)
+	a.Nil(dirtyBranch)
+	a.Equal("main", git(t, dirty, "symbolic-ref", "--short", "HEAD"))
+	a.NoError(plainErr)
+	a.Nil(plainBranch)
+}
+
+func TestSessionBranch_Leave(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := gitRepo(t)
+	deps := Deps{WorkDir: dir}
+	ctx := context.Background()
+	first, err := StartBranch(ctx, deps, "switchboard/session-1")
+	r.NoError(err)
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+
+	// when
+	// ... a second session starts while the first is on its branch
+	second, secondErr := StartBranch(ctx, deps, "switchboard/session-2")
+	leaveErr := first.Leave(ctx, deps)
+
+	// then
+	// ... it is refused, and leaving keeps the work on the first branch
+	a.EqualError(secondErr, "session branch switchboard/session-1 is already checked out")
+	a.Nil(second)
+	r.NoError(leaveErr)
+	a.Equal("main", git(t, dir, "symbolic-ref", "--short", "HEAD"))
+	a.NoFileExists(filepath.Join(dir, "main.go"))
+	a.Contains(git(t, dir, "log", "--format=%s", "switchboard/session-1"), "Pending session changes")
+	a.Error(first.Commit(ctx, deps, "late"))
+}
+
+func TestCommitMessage(t *testing.T) {
+	a := assert.New(t)
+
+	// when
+	firstLine := CommitMessage("  fix the build\nthanks")
+	empty := CommitMessage("")
+	long := CommitMessage(strings.Repeat("é", 80))
+
+	// then
+	a.Equal("fix the build", firstLine)
+	a.Equal("Session changes", empty)
+	a.Equal(strings.Repeat("é", 69)+"...", long)
+}
@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/pkg/errors"
+)
+
+const browseTimeout = 60 * time.Second
+
+type browseInput struct {
+	URL      string `json:"url"`
+	Action   string `json:"action"`
+	Selector string `json:"selector"`
+}
+
+// BrowseTool returns a headless-Chrome tool that loads a page and returns
+// its text or a screenshot. Screenshots are posted to the channel when it
+// can receive files and always returned to the model as an image.
+// Every request the page makes is limited to localhost plus allowedHosts,
+// and hosts other than localhost must also pass egress.
+func BrowseTool(allowedHosts []string, egress *Egress) core.RegisteredTool {
+	guard := browseGuard{allowedHosts: allowedHosts, egress: egress}
+	def := core.ToolDef{
+		Name:        "browse",
+		Description: "Load a web page in headless Chrome. action=text returns the visible text (optionally of a CSS selector); action=screenshot captures the page and posts it to the chat. Only localhost and allow-listed hosts can be opened.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url":      strProp("Page URL"),
+				"action":   map[string]any{"type": "string", "enum": []string{"text", "screenshot"}, "default": "text", "description": "What to return"},
+				"selector": strProp("Optional CSS selector to restrict text or screenshot to one element"),
+			},
+			"required": []string{"url"},
+		},
+	}
+
+	return core.RegisteredTool{
+		Def: def,
+		Execute: func(ctx context.Context, raw json.RawMessage, out core.Outbound) (string, bool) {
+			var input browseInput
+			if err := json.Unmarshal(raw, &input); err != nil {
+				return "invalid input: " + err.Error(), true
+			}
+			if reason := guard.check(ctx, input.URL); reason != "" {
+				return reason, true
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, browseTimeout)
+			defer cancel()
+			// The defaults turn off site-per-process, so cross-site frames
+			// load in the page's target and are intercepted with it.
+			opts := chromedp.DefaultExecAllocatorOptions[:]
+			if proxy := egress.Proxy(); proxy != "" {
+				opts = append(opts, chromedp.ProxyServer(proxy))
+			}
+			ctx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+			defer cancelAlloc()
+			ctx, cancelBrowser := chromedp.NewContext(ctx)
+			defer cancelBrowser()
+			if err := guard.intercept(ctx); err != nil {
+				return "browse error: " + err.Error(), true
+			}
+
+			switch input.Action {
+			case "", "text":
+				return browseText(ctx, input)
+			case "screenshot":
+				return browseScreenshot(ctx, input, out)
+			default:
+				return fmt.Sprintf("unknown action %q (text, screenshot)", input.Action), true
+			}
+		},
+	}
+}
+
+func browseText(ctx context.Context, input browseInput) (string, bool) {
+	selector := input.Selector
+	if selector == "" {
+		selector = "body"
+	}
+	var title, text string
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(input.URL),
+		chromedp.Title(&title),
+		chromedp.Text(selector, &text, chromedp.ByQuery),
+	)
+	if err != nil {
+		return "browse error: " + err.Error(), true
+	}
+	return truncateOutput("Title: "+title+"\n\n"+strings.TrimSpace(text), maxOutputLen), false
+}
+
+func browseScreenshot(ctx context.Context, input browseInput, out core.Outbound) (string, bool) {
+	var png []byte
+	capture := chromedp.FullScreenshot(&png, 100)
+	if input.Selector != "" {
+		capture = chromedp.Screenshot(input.Selector, &png, chromedp.ByQuery)
+	}
+	if err := chromedp.Run(ctx, chromedp.EmulateViewport(1280, 800), chromedp.Navigate(input.URL), capture); err != nil {
+		return "browse error: " + err.Error(), true
+	}
+
+	if fs, ok := out.(core.FileSender); ok {
+		if err := fs.SendFile("screenshot.png", png); err != nil && !errors.Is(err, core.ErrFilesUnsupported) {
+			return "posting screenshot: " + err.Error(), true
+		}
+	}
+	return ImageSentinel + "\timage/png\t" + base64.StdEncoding.EncodeToString(png), false
+}
+
+// browseGuard decides which requests a browsed page may make.
+type browseGuard struct {
+	allowedHosts []string
+	egress       *Egress
+}
+
+// check returns why the page may not request rawURL, or "". Localhost is
+// always allowed, since browse exists to look at dev servers; other hosts
+// must be allow-listed, pass the egress policy and not resolve to an
+// internal address.
+func (g browseGuard) check(ctx context.Context, rawURL string) string {
+	if reason := checkBrowseURL(rawURL, g.allowedHosts); reason != "" {
+		return reason
+	}
+	u, _ := url.Parse(rawURL)
+	if isLoopbackHost(u.Hostname()) {
+		return ""
+	}
+	if err := g.egress.CheckLookup(ctx, rawURL); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// intercept pauses every request the page in ctx makes, redirects,
+// scripts and subresources included, and fails the ones check refuses.
+// WebSockets can't be paused, so they are blocked outright.
+func (g browseGuard) intercept(ctx context.Context) error {
+	chromedp.ListenTarget(ctx, func(ev any) {
+		paused, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		// Listeners must not block, and answering is a round trip.
+		go func() {
+			exec := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+			var err error
+			if reason := g.check(ctx, paused.Request.URL); reason != "" {
+				slog.Info("browse blocked request", "url", paused.Request.URL, "reason", reason)
+				err = fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient).Do(exec)
+			} else {
+				err = fetch.ContinueRequest(paused.RequestID).Do(exec)
+			}
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("answering paused browse request", "url", paused.Request.URL, "error", err)
+			}
+		}()
+	})
+	return chromedp.Run(ctx,
+		network.Enable(),
+		network.SetBypassServiceWorker(true),
+		network.SetBlockedURLs([]string{"ws://*", "wss://*"}),
+		fetch.Enable(),
+	)
+}
+
+// checkBrowseURL returns why rawURL may not be opened, or "".
+func checkBrowseURL(rawURL string, allowedHosts []string) string {
+	if rawURL == "" {
+		return "missing url argument"
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "url must be http or https"
+	}
+	host := u.Hostname()
+	if isLoopbackHost(host) {
+		return ""
+	}
+	for _, h := range allowedHosts {
+		if strings.EqualFold(h, host) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("host %s is not allowed; only localhost and BROWSE_ALLOWED_HOSTS can be opened", host)
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
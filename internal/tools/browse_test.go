@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBrowseURL(t *testing.T) {
+	a := assert.New(t)
+	allowed := []string{"staging.example.com"}
+
+	a.Empty(checkBrowseURL("http://localhost:3000/login", allowed))
+	a.Empty(checkBrowseURL("http://127.0.0.1:8080", allowed))
+	a.Empty(checkBrowseURL("http://[::1]/", allowed))
+	a.Empty(checkBrowseURL("https://Staging.Example.com/app", allowed))
+
+	a.Contains(checkBrowseURL("https://example.com", allowed), "not allowed")
+	a.Contains(checkBrowseURL("file:///etc/passwd", allowed), "http or https")
+	a.Contains(checkBrowseURL("", allowed), "missing url")
+}
+
+func TestBrowseTool_RejectsDisallowedHostBeforeLaunchingChrome(t *testing.T) {
+	tool := BrowseTool(nil, nil)
+
+	result, isErr := tool.Execute(context.Background(), json.RawMessage(`{"url":"https://example.com","action":"screenshot"}`), nil)
+
+	assert.True(t, isErr)
+	assert.Contains(t, result, "not allowed")
+}
+
+func TestBrowseGuard_ChecksEveryHostAgainstEgress(t *testing.T) {
+	// given
+	// ... allow-listed hosts, one of them denied by egress and one internal
+	egress, err := NewEgress(EgressConfig{DeniedDomains: []string{"ads.example.com"}})
+	require.NoError(t, err)
+	g := browseGuard{allowedHosts: []string{"staging.example.com", "ads.example.com", "169.254.169.254", "10.0.0.5"}, egress: egress}
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"localhost", "http://localhost:3000/app.js", ""},
+		{"loopback address", "http://127.0.0.1:8080/", ""},
+		{"allow-listed host", "https://staging.example.com/", ""},
+		{"not allow-listed", "https://evil.example.net/x", "not allowed"},
+		{"egress denied", "https://ads.example.com/pixel", "EGRESS_DENIED_DOMAINS"},
+		{"cloud metadata", "http://169.254.169.254/latest/meta-data/", "internal"},
+		{"private address", "http://10.0.0.5/admin", "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got := g.check(context.Background(), tt.url)
+
+			// then
+			if tt.want == "" {
+				assert.Empty(t, got)
+			} else {
+				assert.Contains(t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+var _ core.ResultCache = (*WebCache)(nil)
+
+// WebCache keeps Fetch and WebSearch results on disk for a TTL, shared by
+// every session. Each entry is one file named by a hash of its kind and
+// key; its mtime is when it was stored. A nil *WebCache caches nothing.
+type WebCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewWebCache caches under dir, which is created if missing.
+func NewWebCache(dir string, ttl time.Duration) (*WebCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "creating web cache directory")
+	}
+	return &WebCache{dir: dir, ttl: ttl, now: time.Now}, nil
+}
+
+func (c *WebCache) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + key))
+	return filepath.Join(c.dir, kind+"-"+hex.EncodeToString(sum[:]))
+}
+
+// Get returns a stored result younger than the TTL.
+func (c *WebCache) Get(kind, key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	p := c.path(kind, key)
+	info, err := os.Stat(p)
+	if err != nil || c.now().Sub(info.ModTime()) > c.ttl {
+		return "", false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Put stores result. Failures are logged: a lost entry only costs a
+// network call.
+func (c *WebCache) Put(kind, key, result string) {
+	if c == nil {
+		return
+	}
+	p := c.path(kind, key)
+	tmp := p + ".tmp"
+	err := os.WriteFile(tmp, []byte(result), 0o600)
+	if err == nil {
+		err = os.Rename(tmp, p)
+	}
+	if err != nil {
+		slog.Warn("writing web cache", "kind", kind, "error", err)
+	}
+}
+
+// Len counts the stored entries, expired ones included.
+func (c *WebCache) Len() (int, error) {
+	entries, err := c.entries()
+	return len(entries), err
+}
+
+// Clear removes every entry and reports how many there were.
+func (c *WebCache) Clear() (int, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+	for i, p := range entries {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return i, errors.Wrap(err, "clearing web cache")
+		}
+	}
+	return len(entries), nil
+}
+
+func (c *WebCache) entries() ([]string, error) {
+	des, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading web cache")
+	}
+	var out []string
+	for _, de := range des {
+		if !de.IsDir() && !strings.HasSuffix(de.Name(), ".tmp") {
+			out = append(out, filepath.Join(c.dir, de.Name()))
+		}
+	}
+	return out, nil
+}
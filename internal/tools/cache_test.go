@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_FetchUsesWebCache(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a page that counts its hits and a cache shared by two sessions
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.Write([]byte("<title>Docs</title>"))
+	}))
+	defer srv.Close()
+	cache, err := NewWebCache(t.TempDir(), time.Hour)
+	r.NoError(err)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	first, second := &Sources{}, &Sources{}
+	egress := loopbackEgress(t, EgressConfig{})
+
+	// when
+	Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL}, Deps{WebCache: cache, Sources: first, Egress: egress})
+	result, isErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL}, Deps{WebCache: cache, Sources: second, Egress: egress})
+	Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL, Headers: map[string]string{"Authorization": "x"}}, Deps{WebCache: cache, Egress: egress})
+	now = now.Add(2 * time.Hour)
+	Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL}, Deps{WebCache: cache, Egress: egress})
+
+	// then
+	// ... the repeat is served from disk, still cited; headers and expiry bypass it
+	a.False(isErr)
+	a.Equal("<title>Docs</title>", result)
+	a.Equal("Sources:\n- Docs: "+srv.URL, second.Footer(""))
+	a.Equal(3, hits)
+}
+
+func TestExecute_WebSearchUsesWebCache(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.Write([]byte(`{"web":{"results":[{"title":"Go","url":"https://go.dev/"}]}}`))
+	}))
+	defer srv.Close()
+	prev := webSearchEndpoint
+	webSearchEndpoint = srv.URL
+	t.Cleanup(func() { webSearchEndpoint = prev })
+	cache, err := NewWebCache(t.TempDir(), time.Hour)
+	r.NoError(err)
+	deps := Deps{WebSearchAPIKey: "k", WebCache: cache}
+
+	// when
+	first, _ := Execute(context.Background(), "WebSearch", core.ToolInput{Query: "golang"}, deps)
+	second, _ := Execute(context.Background(), "WebSearch", core.ToolInput{Query: "golang"}, deps)
+	n, err := cache.Len()
+	r.NoError(err)
+	cleared, err := cache.Clear()
+	r.NoError(err)
+	Execute(context.Background(), "WebSearch", core.ToolInput{Query: "golang"}, deps)
+
+	// then
+	a.Equal(first, second)
+	a.Equal(1, n)
+	a.Equal(1, cleared)
+	a.Equal(2, hits)
+}
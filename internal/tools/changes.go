@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxChangeDiff caps the diff kept in a ChangeSummary.
+const maxChangeDiff = 1 << 20
+
+// ChangeSummary is what changed in a work tree between two snapshots.
+type ChangeSummary struct {
+	Files   int
+	Added   int
+	Removed int
+	// Diff is the unified diff, truncated to maxChangeDiff.
+	Diff string
+}
+
+// Footer renders the summary as one line for the end of a response.
+func (c ChangeSummary) Footer() string {
+	noun := "files"
+	if c.Files == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("📝 Changed: %d %s (+%d/−%d)", c.Files, noun, c.Added, c.Removed)
+}
+
+// Snapshot records the git work tree containing deps.WorkDir, untracked
+// files included, as a tree object. It stages into a copy of the index so
+// the user's staging area is left alone. It returns "" when WorkDir is not
+// in a git work tree.
+func Snapshot(ctx context.Context, deps Deps) (string, error) {
+	if deps.WorkDir == "" {
+		return "", nil
+	}
+	indexPath, err := gitOutput(ctx, deps, nil, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", nil
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(deps.WorkDir, indexPath)
+	}
+
+	tmp, err := os.CreateTemp("", "switchboard-index-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating temp index")
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	// Starting from the real index keeps git's stat cache, so unchanged
+	// files are not rehashed.
+	if index, err := os.ReadFile(indexPath); err == nil {
+		if err := os.WriteFile(tmp.Name(), index, 0o600); err != nil {
+			return "", errors.Wrap(err, "copying index")
+		}
+	} else {
+		os.Remove(tmp.Name())
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmp.Name()}
+	if _, err := gitOutput(ctx, deps, env, "add", "-A"); err != nil {
+		return "", errors.Wrap(err, "staging snapshot")
+	}
+	tree, err := gitOutput(ctx, deps, env, "write-tree")
+	if err != nil {
+		return "", errors.Wrap(err, "writing snapshot tree")
+	}
+	return tree, nil
+}
+
+// Changes compares the work tree with an earlier Snapshot.
+func Changes(ctx context.Context, deps Deps, since string) (ChangeSummary, error) {
+	now, err := Snapshot(ctx, deps)
+	if err != nil || now == since {
+		return ChangeSummary{}, err
+	}
+	numstat, err := gitOutput(ctx, deps, nil, "diff", "--numstat", since, now)
+	if err != nil {
+		return ChangeSummary{}, errors.Wrap(err, "diffing snapshots")
+	}
+	var c ChangeSummary
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		c.Files++
+		// Binary files report "-" for both counts.
+		added, _ := strconv.Atoi(fields[0])
+		removed, _ := strconv.Atoi(fields[1])
+		c.Added += added
+		c.Removed += removed
+	}
+	diff, err := gitOutput(ctx, deps, nil, "diff", "--no-color", "--no-ext-diff", since, now)
+	if err != nil {
+		return ChangeSummary{}, errors.Wrap(err, "diffing snapshots")
+	}
+	c.Diff = truncateOutput(diff, maxChangeDiff)
+	return c, nil
+}
+
+func gitOutput(ctx context.Context, deps Deps, env []string, args ...string) (string, error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, bashTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "git", args...)
+	cmd.Dir = deps.WorkDir
+	cmd.Env = append(deps.Env.Filter(os.Environ()), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s", args[0])
+	}
+	return strings.TrimSpace(string(out)), nil
+}
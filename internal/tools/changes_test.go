@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a repo with one tracked file and a staged change the turn must not touch
+	dir := t.TempDir()
+	r.NoError(exec.Command("git", "init", "-q", dir).Run())
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	add := exec.Command("git", "add", "main.go")
+	add.Dir = dir
+	r.NoError(add.Run())
+	deps := Deps{WorkDir: dir}
+	since, err := Snapshot(context.Background(), deps)
+	r.NoError(err)
+	r.NotEmpty(since)
+
+	// when
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(1)\n}\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "new.txt"), []byte("hello\n"), 0o644))
+	c, err := Changes(context.Background(), deps, since)
+
+	// then
+	r.NoError(err)
+	a.Equal(2, c.Files)
+	a.Equal(4, c.Added)
+	a.Equal(1, c.Removed)
+	a.Contains(c.Diff, "+\tprintln(1)")
+	a.Contains(c.Diff, "+++ b/new.txt")
+	a.Equal("📝 Changed: 2 files (+4/−1)", c.Footer())
+	status := exec.Command("git", "status", "--porcelain")
+	status.Dir = dir
+	out, err := status.Output()
+	r.NoError(err)
+	a.Equal("AM main.go\n?? new.txt\n", string(out), "the real index is untouched")
+}
+
+func TestSnapshot_NotARepo(t *testing.T) {
+	// when
+	tree, err := Snapshot(context.Background(), Deps{WorkDir: t.TempDir()})
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, tree)
+}
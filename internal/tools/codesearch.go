@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/codesearch"
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+const (
+	defaultCodeSearchLimit = 8
+	maxCodeSearchLimit     = 20
+)
+
+type codeSearchInput struct {
+	Path  string `json:"path"`
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+// CodeSearchTool returns code_search, which finds chunks of a directory's
+// files by meaning rather than text. The index is refreshed for changed
+// files before every search.
+func CodeSearchTool(idx *codesearch.Index) core.RegisteredTool {
+	return core.RegisteredTool{
+		Def: core.ToolDef{
+			Name:        "code_search",
+			Description: "Semantic search over a codebase: returns the file snippets most related to a natural-language query (e.g. \"where are retries handled\"). Use Grep for exact identifiers. The first search of a large directory takes a while to index.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":  strProp("Absolute path to the repository root to search"),
+					"query": strProp("What the code you are looking for does"),
+					"limit": map[string]any{"type": "integer", "default": defaultCodeSearchLimit, "description": fmt.Sprintf("Number of snippets, at most %d", maxCodeSearchLimit)},
+				},
+				"required": []string{"path", "query"},
+			},
+		},
+		Execute: func(ctx context.Context, raw json.RawMessage, _ core.Outbound) (string, bool) {
+			var input codeSearchInput
+			if err := json.Unmarshal(raw, &input); err != nil {
+				return "invalid input: " + err.Error(), true
+			}
+			if input.Path == "" || input.Query == "" {
+				return "missing path or query argument", true
+			}
+			if input.Limit <= 0 {
+				input.Limit = defaultCodeSearchLimit
+			}
+			input.Limit = min(input.Limit, maxCodeSearchLimit)
+
+			results, err := idx.Search(ctx, input.Path, input.Query, input.Limit)
+			if err != nil {
+				return "code search failed: " + err.Error(), true
+			}
+			if len(results) == 0 {
+				return "no indexed files under " + input.Path, false
+			}
+			var b strings.Builder
+			for _, r := range results {
+				fmt.Fprintf(&b, "=== %s:%d-%d (score %.2f) ===\n%s\n", r.Path, r.StartLine, r.EndLine, r.Score, r.Content)
+			}
+			return b.String(), false
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// compactReserve is room kept under max for the omission markers.
+const compactReserve = 256
+
+// errorLine matches lines worth keeping from the middle of a long output.
+var errorLine = regexp.MustCompile(`(?i)\b(error|errors|fail|failed|failure|panic|fatal|exception|traceback)\b|^\s*(--- FAIL|FAIL\b|\S+:\d+:\d*:?)`)
+
+// CompactOutput fits s into about max bytes. Rather than cutting the end
+// off, it keeps the first quarter and the last half, where commands print
+// what they are doing and how they ended, and fills the rest with error
+// lines from the middle. Each gap is marked and a last line says what was
+// left out.
+func CompactOutput(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	budget := max - compactReserve
+	if budget < 64 {
+		budget = 64
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	keep := make([]bool, len(lines))
+	used := 0
+	take := func(i int) bool {
+		if keep[i] {
+			return true
+		}
+		if used+len(lines[i]) > budget {
+			return false
+		}
+		keep[i] = true
+		used += len(lines[i])
+		return true
+	}
+	for i := 0; i < len(lines) && used < budget/4; i++ {
+		if !take(i) {
+			break
+		}
+	}
+	headUsed := used
+	for i := len(lines) - 1; i >= 0 && used-headUsed < budget/2; i-- {
+		if !take(i) {
+			break
+		}
+	}
+	var errorsKept int
+	for i, line := range lines {
+		if !keep[i] && errorLine.MatchString(line) && take(i) {
+			errorsKept++
+		}
+	}
+	if used == 0 {
+		return compactBytes(s, budget)
+	}
+
+	var b strings.Builder
+	omittedLines, omittedBytes, gap := 0, 0, 0
+	for i, line := range lines {
+		if !keep[i] {
+			gap++
+			omittedLines++
+			omittedBytes += len(line)
+			continue
+		}
+		if gap > 0 {
+			fmt.Fprintf(&b, "... [%d lines omitted] ...\n", gap)
+			gap = 0
+		}
+		b.WriteString(line)
+	}
+	if gap > 0 {
+		fmt.Fprintf(&b, "... [%d lines omitted] ...\n", gap)
+	}
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[output compacted: %d of %d lines (%d bytes) omitted; kept the start, the end and %d error lines from between]",
+		omittedLines, len(lines), omittedBytes, errorsKept)
+	return b.String()
+}
+
+// compactBytes keeps the start and end of s when it has no line short
+// enough to keep whole, cutting on rune boundaries.
+func compactBytes(s string, budget int) string {
+	head := budget / 3
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	tail := len(s) - (budget - head)
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+	return fmt.Sprintf("%s\n... [%d bytes omitted] ...\n%s", s[:head], tail-head, s[tail:])
+}
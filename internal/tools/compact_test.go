@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactOutput_KeepsErrorsAndTail(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a build log with one error buried in the middle
+	var lines []string
+	for i := range 2000 {
+		lines = append(lines, fmt.Sprintf("compiling package %04d", i))
+	}
+	lines[1000] = "pkg/foo.go:12:3: undefined: bar"
+	lines = append(lines, "exit status 2")
+	out := strings.Join(lines, "\n") + "\n"
+
+	// when
+	got := CompactOutput(out, 4000)
+
+	// then
+	// ... the start, the buried error and the end survive, with the gaps marked
+	a.LessOrEqual(len(got), 4000)
+	a.True(strings.HasPrefix(got, "compiling package 0000\n"), got)
+	a.Contains(got, "pkg/foo.go:12:3: undefined: bar")
+	a.Contains(got, "compiling package 1999\nexit status 2\n")
+	a.Contains(got, "lines omitted] ...")
+	a.Regexp(`\[output compacted: \d+ of 2001 lines \(\d+ bytes\) omitted; kept the start, the end and 1 error lines from between\]$`, got)
+}
+
+func TestCompactOutput_ShortOutputUnchanged(t *testing.T) {
+	assert.Equal(t, "ok\n", CompactOutput("ok\n", 100))
+}
+
+func TestCompactOutput_OneLongLine(t *testing.T) {
+	a := assert.New(t)
+
+	got := CompactOutput(strings.Repeat("é", 5000), 1000)
+
+	a.LessOrEqual(len(got), 1000)
+	a.Contains(got, "bytes omitted] ...")
+	a.True(strings.HasSuffix(got, "é"))
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// containedPath resolves path's symlinks and checks the result lies in
+// AllowedDirs or the session's WorkDir. The permission checker only sees
+// the path as written, so this stops a link inside an allowed directory
+// from pointing the executor outside it. With no AllowedDirs every path
+// is allowed.
+func (d Deps) containedPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrap(err, "resolving path")
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	if len(d.AllowedDirs) == 0 {
+		return real, nil
+	}
+	for _, dir := range append(d.AllowedDirs[:len(d.AllowedDirs):len(d.AllowedDirs)], d.WorkDir) {
+		if dir == "" {
+			continue
+		}
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if real == root || strings.HasPrefix(real, root+string(filepath.Separator)) {
+			return real, nil
+		}
+	}
+	return "", errors.Errorf("path %s is outside allowed directories", path)
+}
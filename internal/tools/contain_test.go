@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_ReadStaysInAllowedDirs(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... an allowed dir holding a file and a symlink to a file outside it
+	allowed, outside := t.TempDir(), t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(allowed, "ok.txt"), []byte("inside"), 0o600))
+	r.NoError(os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o600))
+	r.NoError(os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(allowed, "link.txt")))
+	deps := Deps{AllowedDirs: []string{allowed}}
+
+	// when
+	ok, okErr := Execute(context.Background(), "Read", core.ToolInput{FilePath: filepath.Join(allowed, "ok.txt")}, deps)
+	linked, linkedErr := Execute(context.Background(), "Read", core.ToolInput{FilePath: filepath.Join(allowed, "link.txt")}, deps)
+	direct, directErr := Execute(context.Background(), "Read", core.ToolInput{FilePath: filepath.Join(allowed, "..", filepath.Base(outside), "secret.txt")}, deps)
+
+	// then
+	a.False(okErr)
+	a.Equal("inside", ok)
+	a.True(linkedErr)
+	a.Contains(linked, "is outside allowed directories")
+	a.True(directErr)
+	a.Contains(direct, "is outside allowed directories")
+}
+
+func TestExecute_BashRunsInWorkDir(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	dir := t.TempDir()
+	real, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	// when
+	pwd, isErr := Execute(context.Background(), "Bash", core.ToolInput{Command: "pwd -P"}, Deps{WorkDir: dir})
+
+	// then
+	a.False(isErr)
+	a.Equal(real, strings.TrimSpace(pwd))
+}
@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxRedirects matches net/http's default limit.
+const maxRedirects = 10
+
+// EgressConfig configures NewEgress.
+type EgressConfig struct {
+	// Proxy, if set, is an http, https or socks5 URL.
+	Proxy string
+	// AllowedDomains, when set, are the only domains reachable;
+	// DeniedDomains never are. A domain covers its subdomains and denials
+	// win.
+	AllowedDomains []string
+	DeniedDomains  []string
+	// InternalNetworks are CIDRs Fetch may reach even though they are
+	// loopback, private or link-local.
+	InternalNetworks []string
+}
+
+// Egress limits which hosts Fetch and WebSearch may reach, redirects
+// included, and can send them through a proxy. Fetch also refuses
+// internal addresses, so the model can't reach cloud metadata or services
+// behind the firewall. A nil *Egress behaves like NewEgress with an empty
+// config.
+type Egress struct {
+	allow   []string
+	deny    []string
+	guard   ipGuard
+	proxied bool
+	proxy   string
+	web     *http.Client
+	fetch   *http.Client
+}
+
+var defaultEgress, _ = NewEgress(EgressConfig{})
+
+// NewEgress builds an Egress from cfg.
+func NewEgress(cfg EgressConfig) (*Egress, error) {
+	e := &Egress{allow: lowerAll(cfg.AllowedDomains), deny: lowerAll(cfg.DeniedDomains)}
+	for _, s := range cfg.InternalNetworks {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Errorf("invalid network %q", s)
+		}
+		e.guard.allowed = append(e.guard.allowed, n)
+	}
+	var proxy *url.URL
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, errors.Errorf("invalid proxy URL %q", cfg.Proxy)
+		}
+		proxy, e.proxied, e.proxy = u, true, cfg.Proxy
+	}
+
+	web := http.DefaultTransport.(*http.Transport).Clone()
+	fetch := web.Clone()
+	if proxy != nil {
+		web.Proxy = http.ProxyURL(proxy)
+		fetch.Proxy = http.ProxyURL(proxy)
+	} else {
+		fetch.Proxy = nil
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: e.guard.control}
+		fetch.DialContext = dialer.DialContext
+	}
+	e.web = &http.Client{Timeout: httpClient.Timeout, Transport: web, CheckRedirect: e.redirect(func(req *http.Request) error {
+		return e.Check(req.URL.String())
+	})}
+	e.fetch = &http.Client{Timeout: httpClient.Timeout, Transport: fetch, CheckRedirect: e.redirect(func(req *http.Request) error {
+		return e.CheckFetch(req.Context(), req.URL.String())
+	})}
+	return e, nil
+}
+
+func (e *Egress) redirect(check func(*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return check(req)
+	}
+}
+
+// Check returns why rawURL may not be reached, or nil.
+func (e *Egress) Check(rawURL string) error {
+	if e == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing url")
+	}
+	host := strings.ToLower(u.Hostname())
+	if matchDomain(host, e.deny) {
+		return errors.Errorf("host %s is blocked by EGRESS_DENIED_DOMAINS", host)
+	}
+	if len(e.allow) > 0 && !matchDomain(host, e.allow) {
+		return errors.Errorf("host %s is not in EGRESS_ALLOWED_DOMAINS", host)
+	}
+	return nil
+}
+
+// CheckFetch is Check plus, behind a proxy, a lookup of the host's
+// addresses. Without a proxy the dialer checks the address it connects
+// to instead, which also catches names that re-resolve between checks.
+// Names only the proxy can resolve are left to the proxy.
+func (e *Egress) CheckFetch(ctx context.Context, rawURL string) error {
+	if err := e.Check(rawURL); err != nil || e == nil || !e.proxied {
+		return err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing url")
+	}
+	return e.guard.lookup(ctx, u.Hostname())
+}
+
+// CheckLookup is Check plus a lookup of the host's addresses, for clients
+// that dial for themselves, such as browse's headless Chrome. A name that
+// re-resolves between the lookup and the dial gets past it.
+func (e *Egress) CheckLookup(ctx context.Context, rawURL string) error {
+	if err := e.Check(rawURL); err != nil {
+		return err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing url")
+	}
+	return e.orDefault().guard.lookup(ctx, u.Hostname())
+}
+
+// Proxy returns the proxy URL traffic goes through, or "".
+func (e *Egress) Proxy() string {
+	if e == nil {
+		return ""
+	}
+	return e.proxy
+}
+
+func (e *Egress) orDefault() *Egress {
+	if e == nil {
+		return defaultEgress
+	}
+	return e
+}
+
+// client is WebSearch's client. Without an Egress it is the plain one.
+func (e *Egress) client() *http.Client {
+	if e == nil {
+		return httpClient
+	}
+	return e.web
+}
+
+func (e *Egress) fetchClient() *http.Client {
+	return e.orDefault().fetch
+}
+
+// extraInternal are ranges Fetch refuses beyond net.IP's own predicates:
+// "this network" and carrier-grade NAT.
+var extraInternal = []*net.IPNet{
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+}
+
+// ipGuard refuses loopback, private, link-local and unspecified addresses
+// unless they are in allowed.
+type ipGuard struct {
+	allowed []*net.IPNet
+}
+
+func (g ipGuard) check(ip net.IP) error {
+	for _, n := range g.allowed {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	internal := ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+	for _, n := range extraInternal {
+		internal = internal || n.Contains(ip)
+	}
+	if internal {
+		return errors.Errorf("address %s is internal; add it to FETCH_ALLOWED_NETWORKS to allow it", ip)
+	}
+	return nil
+}
+
+// control runs after DNS resolution, on the address actually dialled.
+func (g ipGuard) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return errors.Errorf("unexpected address %s", address)
+	}
+	return g.check(ip)
+}
+
+func (g ipGuard) lookup(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		return g.check(ip)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, a := range addrs {
+		if err := g.check(a.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(ss []string) []string {
+	out := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgress_Check(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	e, err := NewEgress(EgressConfig{AllowedDomains: []string{"Example.com", "go.dev"}, DeniedDomains: []string{"secret.example.com"}})
+	r.NoError(err)
+
+	// then
+	// ... domains cover their subdomains and denials win
+	a.NoError(e.Check("https://example.com/a"))
+	a.NoError(e.Check("https://docs.example.com/a"))
+	a.NoError(e.Check("https://go.dev/"))
+	a.ErrorContains(e.Check("https://api.secret.example.com/"), "EGRESS_DENIED_DOMAINS")
+	a.ErrorContains(e.Check("https://notexample.com/"), "EGRESS_ALLOWED_DOMAINS")
+	a.NoError((*Egress)(nil).Check("https://anything.test/"))
+}
+
+func TestExecute_FetchEnforcesEgress(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... an allowed host that redirects to a denied one
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer denied.Close()
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(denied.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+	}))
+	defer allowed.Close()
+	e := loopbackEgress(t, EgressConfig{AllowedDomains: []string{"127.0.0.1"}})
+
+	// when
+	redirected, redirectErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: allowed.URL}, Deps{Egress: e})
+	direct, directErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: "http://localhost/"}, Deps{Egress: e})
+
+	// then
+	a.True(redirectErr)
+	a.Contains(redirected, "host localhost is not in EGRESS_ALLOWED_DOMAINS")
+	a.True(directErr)
+	a.Equal("host localhost is not in EGRESS_ALLOWED_DOMAINS", direct)
+}
+
+// loopbackEgress lets Fetch reach httptest servers.
+func loopbackEgress(t *testing.T, cfg EgressConfig) *Egress {
+	t.Helper()
+	cfg.InternalNetworks = append(cfg.InternalNetworks, "127.0.0.0/8")
+	e, err := NewEgress(cfg)
+	require.NoError(t, err)
+	return e
+}
+
+func TestExecute_FetchRefusesInternalAddresses(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a local service and a public page redirecting to it
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("credentials"))
+	}))
+	defer internal.Close()
+
+	// when
+	direct, directErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: internal.URL}, Deps{})
+	metadata, metadataErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: "http://169.254.169.254/latest/meta-data/"}, Deps{})
+	mapped, mappedErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: "http://[::ffff:127.0.0.1]:1/"}, Deps{})
+
+	// then
+	// ... the dialer refuses them before connecting
+	a.True(directErr)
+	a.Contains(direct, "address 127.0.0.1 is internal")
+	a.True(metadataErr)
+	a.Contains(metadata, "address 169.254.169.254 is internal")
+	a.True(mappedErr)
+	a.Contains(mapped, "is internal")
+}
+
+func TestExecute_FetchRefusesInternalAddressesBehindProxy(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... the proxy would fetch anything
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+	e, err := NewEgress(EgressConfig{Proxy: proxy.URL, InternalNetworks: []string{"10.1.0.0/16"}})
+	r.NoError(err)
+
+	// when
+	blocked, blockedErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: "http://10.0.0.1/"}, Deps{Egress: e})
+	allowed, allowedErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: "http://10.1.2.3/"}, Deps{Egress: e})
+
+	// then
+	a.True(blockedErr)
+	a.Equal("address 10.0.0.1 is internal; add it to FETCH_ALLOWED_NETWORKS to allow it", blocked)
+	a.False(allowedErr)
+	a.Equal("via proxy", allowed)
+}
+
+func TestExecute_FetchCapsResponseSize(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 3*maxOutputLen)))
+	}))
+	defer srv.Close()
+
+	// when
+	result, _ := Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL}, Deps{Egress: loopbackEgress(t, EgressConfig{})})
+
+	// then
+	assert.Equal(t, strings.Repeat("a", maxOutputLen)+"\n... (truncated)", result)
+}
+
+func TestExecute_FetchUsesEgressProxy(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a proxy that answers for every host
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+	e := loopbackEgress(t, EgressConfig{Proxy: proxy.URL})
+
+	// when
+	result, isErr := Execute(context.Background(), "Fetch", core.ToolInput{URL: "http://intranet.test/page"}, Deps{Egress: e})
+
+	// then
+	a.False(isErr)
+	a.Equal("via proxy", result)
+	a.Equal("http://intranet.test/page", proxied)
+}
+
+func TestEgress_CheckLookupRefusesInternalAddressesWithoutProxy(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	e := loopbackEgress(t, EgressConfig{AllowedDomains: []string{"example.com", "127.0.0.1", "169.254.169.254"}})
+
+	// when
+	loopback := e.CheckLookup(context.Background(), "http://127.0.0.1:8080/")
+	metadata := e.CheckLookup(context.Background(), "http://169.254.169.254/")
+	var nilEgress *Egress
+	unconfigured := nilEgress.CheckLookup(context.Background(), "http://10.0.0.1/")
+
+	// then
+	a.NoError(loopback)
+	a.ErrorContains(metadata, "internal")
+	a.ErrorContains(unconfigured, "internal")
+	a.Empty(nilEgress.Proxy())
+}
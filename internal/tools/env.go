@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// EnvPolicy filters the environment handed to processes spawned by tools
+// (currently Bash) so the model's shell doesn't inherit secrets the bot
+// itself needs. It can't stop a shell running as the bot's user from
+// reading the bot's own environment; see hideSecrets in cmd/switchboard.
+// The zero value passes the environment through unchanged.
+type EnvPolicy struct {
+	// Allow, when non-empty, restricts the child env to these names.
+	Allow []string
+	// Deny drops these names. Applied after Allow.
+	Deny []string
+}
+
+// Filter returns the subset of environ (KEY=VALUE entries, as returned by
+// os.Environ) permitted by the policy.
+func (p EnvPolicy) Filter(environ []string) []string {
+	allow := nameSet(p.Allow)
+	deny := nameSet(p.Deny)
+
+	out := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if len(allow) > 0 && !allow[name] {
+			continue
+		}
+		if deny[name] {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
+func nameSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+// sessionEnviron renders a session's /env variables as sorted KEY=VALUE
+// entries. Appended after the filtered environment, they win over
+// inherited values of the same name.
+func sessionEnviron(env map[string]string) []string {
+	out := make([]string, 0, len(env))
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		out = append(out, name+"="+env[name])
+	}
+	return out
+}
+
+// sessionEnvRef matches $NAME and ${NAME}.
+var sessionEnvRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandSessionEnv replaces references to session variables in s with
+// their values, reporting whether it replaced any. Other references are
+// left as written, so a literal "$" in a URL survives.
+func expandSessionEnv(s string, env map[string]string) (string, bool) {
+	if len(env) == 0 || !strings.Contains(s, "$") {
+		return s, false
+	}
+	var expanded bool
+	out := sessionEnvRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		v, ok := env[name]
+		if !ok {
+			return ref
+		}
+		expanded = true
+		return v
+	})
+	return out, expanded
+}
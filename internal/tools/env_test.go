@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvPolicy_ZeroValuePassesThrough(t *testing.T) {
+	env := []string{"A=1", "B=2"}
+
+	assert.Equal(t, env, EnvPolicy{}.Filter(env))
+}
+
+func TestEnvPolicy_DenyDropsNames(t *testing.T) {
+	got := EnvPolicy{Deny: []string{"TOKEN"}}.Filter([]string{"PATH=/bin", "TOKEN=secret", "TOKEN_HINT=x"})
+
+	assert.Equal(t, []string{"PATH=/bin", "TOKEN_HINT=x"}, got)
+}
+
+func TestEnvPolicy_AllowRestrictsThenDenyApplies(t *testing.T) {
+	got := EnvPolicy{
+		Allow: []string{"PATH", "HOME"},
+		Deny:  []string{"HOME"},
+	}.Filter([]string{"PATH=/bin", "HOME=/root", "SECRET=x"})
+
+	assert.Equal(t, []string{"PATH=/bin"}, got)
+}
+
+func TestSessionEnviron_SortedEntries(t *testing.T) {
+	got := sessionEnviron(map[string]string{"TEST_DATABASE_URL": "postgres://db", "API_TOKEN": "t0ken=="})
+
+	assert.Equal(t, []string{"API_TOKEN=t0ken==", "TEST_DATABASE_URL=postgres://db"}, got)
+}
+
+func TestExpandSessionEnv(t *testing.T) {
+	env := map[string]string{"HOST": "api.example.com", "TOKEN": "s3cret"}
+	tests := []struct {
+		name     string
+		in       string
+		want     string
+		expanded bool
+	}{
+		{"plain", "https://example.com/a", "https://example.com/a", false},
+		{"bare reference", "https://$HOST/v1", "https://api.example.com/v1", true},
+		{"braced reference", "Bearer ${TOKEN}", "Bearer s3cret", true},
+		{"unknown name kept", "https://$HOST/$OTHER", "https://api.example.com/$OTHER", true},
+		{"only unknown names", "price=$5&x=$NOPE", "price=$5&x=$NOPE", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expanded := expandSessionEnv(tt.in, env)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.expanded, expanded)
+		})
+	}
+}
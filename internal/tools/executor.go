@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/media"
 	"github.com/TheLazyLemur/switchboard/internal/skills"
 )
 
@@ -33,6 +34,10 @@ var httpClient = &http.Client{Timeout: 30 * time.Second}
 var bashTimeout = 2 * time.Minute
 var webSearchEndpoint = "https://api.search.brave.com/res/v1/web/search"
 
+// maxSearchBytes caps the Brave response WebSearch reads; a real one is a
+// few dozen kilobytes.
+const maxSearchBytes = 1 << 20
+
 func truncateOutput(s string, maxLen int) string {
 	if len(s) > maxLen {
 		return s[:maxLen] + "\n... (truncated)"
@@ -45,27 +50,57 @@ type Deps struct {
 	Outbound        core.Outbound
 	SkillStore      skills.SkillStore
 	WebSearchAPIKey string
+	// Env filters the environment inherited by spawned tool processes.
+	Env EnvPolicy
+	// WorkDir is the session working directory write_artifact saves into
+	// and Bash runs in.
+	WorkDir string
+	// AllowedDirs, when set, are enforced by Read on top of the permission
+	// checker, with symlinks resolved. WorkDir is always allowed, since the
+	// bot only starts sessions in directories it vetted.
+	AllowedDirs []string
+	// Registry holds the tools registered at startup, such as script tools.
+	Registry *core.ToolRegistry
+	// Hooks run before and after every tool call.
+	Hooks ToolHooks
+	// Sources, when set, collects the pages WebSearch and Fetch return.
+	Sources *Sources
+	// WebCache, when set, answers repeated Fetch and WebSearch calls.
+	WebCache *WebCache
+	// Egress, when set, limits and proxies Fetch and WebSearch traffic.
+	Egress *Egress
+	// SessionEnv holds the session's /env variables. Bash and verify
+	// commands get them on top of the filtered environment, and Fetch
+	// expands $NAME references to them in its URL and headers.
+	SessionEnv map[string]string
 }
 
 // Execute dispatches to the appropriate tool executor. Returns (result, isError).
-func Execute(name string, input core.ToolInput, deps Deps) (string, bool) {
+// Cancelling ctx stops Bash commands and HTTP requests.
+func Execute(ctx context.Context, name string, input core.ToolInput, deps Deps) (string, bool) {
 	switch name {
 	case "react_emoji":
 		return executeReactEmoji(input, deps.Outbound)
 	case "send_update":
 		return executeSendUpdate(input, deps.Outbound)
 	case "Read":
-		return executeRead(input)
+		return executeRead(input, deps)
+	case "LS":
+		return executeLS(ctx, input, deps)
 	case "Bash":
-		return executeBash(input)
+		return executeBash(ctx, input, deps)
 	case "Fetch":
-		return executeFetch(input)
+		return executeFetch(ctx, input, deps)
 	case "Skill":
 		return executeSkill(input, deps.SkillStore)
 	case "LoadSkillSupporting":
 		return executeLoadSkillSupporting(input, deps.SkillStore)
 	case "WebSearch":
-		return executeWebSearch(input, deps.WebSearchAPIKey)
+		return executeWebSearch(ctx, input, deps)
+	case "write_artifact":
+		return executeWriteArtifact(input, deps)
+	case "Patch":
+		return executePatch(input, deps)
 	default:
 		return "unknown tool: " + name, true
 	}
@@ -94,23 +129,38 @@ func executeSendUpdate(input core.ToolInput, responder core.Outbound) (string, b
 	return "update sent", false
 }
 
-func executeRead(input core.ToolInput) (string, bool) {
+func executeRead(input core.ToolInput, deps Deps) (string, bool) {
 	if input.FilePath == "" {
 		return "missing file_path argument", true
 	}
 
 	filePath := filepath.Clean(input.FilePath)
+	real, err := deps.containedPath(filePath)
+	if err != nil {
+		return "error reading file: " + err.Error(), true
+	}
 
-	content, err := os.ReadFile(filePath)
+	content, err := os.ReadFile(real)
 	if err != nil {
 		return "error reading file: " + err.Error(), true
 	}
 
 	if mime := detectImageMIME(filePath, content); mime != "" {
-		return ImageSentinel + "\t" + mime + "\t" + base64.StdEncoding.EncodeToString(content), false
+		data, mime, err := media.PrepareVisionImage(real, mime)
+		if err == nil {
+			return ImageSentinel + "\t" + mime + "\t" + base64.StdEncoding.EncodeToString(data), false
+		}
+		return describeBinary(content, input.Hex) + "\nimage not attached: " + err.Error(), false
+	}
+	if isBinary(content) {
+		return describeBinary(content, input.Hex), false
 	}
 
-	return truncateOutput(string(content), maxOutputLen), false
+	page, err := pageLines(string(content), input.Offset, input.Limit, maxOutputLen)
+	if err != nil {
+		return "error reading file: " + err.Error(), true
+	}
+	return page, false
 }
 
 // detectImageMIME returns a normalized image MIME ("image/png" etc.) when the
@@ -141,14 +191,25 @@ func detectImageMIME(path string, content []byte) string {
 	return ""
 }
 
-func executeBash(input core.ToolInput) (string, bool) {
+// executeBash runs the command in the session's WorkDir rather than the
+// bot's own directory. That is all the containment a shell command gets
+// here: it can still name any path the bot's user can reach.
+func executeBash(ctx context.Context, input core.ToolInput, deps Deps) (string, bool) {
 	if input.Command == "" {
 		return "missing command argument", true
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bashTimeout)
+	ctx, cancel := context.WithTimeout(ctx, bashTimeout)
 	defer cancel()
 	cmd := exec.CommandContext(ctx, "sh", "-c", input.Command)
+	cmd.Dir = deps.WorkDir
+	cmd.Env = append(deps.Env.Filter(os.Environ()), sessionEnviron(deps.SessionEnv)...)
+	return runProcess(cmd)
+}
+
+// runProcess runs cmd and formats its stdout, stderr and exit error the
+// way the model expects from Bash.
+func runProcess(cmd *exec.Cmd) (string, bool) {
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -173,10 +234,10 @@ func executeBash(input core.ToolInput) (string, bool) {
 		}
 		result.WriteString("exit error: ")
 		result.WriteString(err.Error())
-		return result.String(), true
+		return CompactOutput(result.String(), maxOutputLen), true
 	}
 
-	return truncateOutput(result.String(), maxOutputLen), false
+	return CompactOutput(result.String(), maxOutputLen), false
 }
 
 func executeSkill(input core.ToolInput, store skills.SkillStore) (string, bool) {
@@ -216,10 +277,18 @@ func executeLoadSkillSupporting(input core.ToolInput, store skills.SkillStore) (
 	return string(content), false
 }
 
-func executeFetch(input core.ToolInput) (string, bool) {
+func executeFetch(ctx context.Context, input core.ToolInput, deps Deps) (string, bool) {
 	if input.URL == "" {
 		return "missing url argument", true
 	}
+	// The model names session variables rather than seeing their values,
+	// so target is what goes on the wire and input.URL what gets reported.
+	target, expanded := expandSessionEnv(input.URL, deps.SessionEnv)
+	hide := func(err error) string { return strings.ReplaceAll(err.Error(), target, input.URL) }
+	if err := deps.Egress.CheckFetch(ctx, target); err != nil {
+		return hide(err), true
+	}
+	sources, cache := deps.Sources, deps.WebCache
 
 	method := input.Method
 	if method == "" {
@@ -227,67 +296,74 @@ func executeFetch(input core.ToolInput) (string, bool) {
 	}
 	method = strings.ToUpper(method)
 
+	// Headers may carry credentials, so only plain GETs are shared.
+	cacheable := method == http.MethodGet && input.Body == "" && len(input.Headers) == 0 && !expanded
+	if cacheable {
+		if result, ok := cache.Get("fetch", input.URL); ok {
+			sources.Add(Source{Title: pageTitle(result), URL: input.URL})
+			return result, false
+		}
+	}
+
 	var bodyReader io.Reader
 	if input.Body != "" {
 		bodyReader = strings.NewReader(input.Body)
 	}
 
-	req, err := http.NewRequest(method, input.URL, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, target, bodyReader)
 	if err != nil {
-		return "error creating request: " + err.Error(), true
+		return "error creating request: " + hide(err), true
 	}
 
 	for k, v := range input.Headers {
+		v, _ = expandSessionEnv(v, deps.SessionEnv)
 		req.Header.Set(k, v)
 	}
 
-	resp, err := httpClient.Do(req)
+	resp, err := deps.Egress.fetchClient().Do(req)
 	if err != nil {
-		return "error making request: " + err.Error(), true
+		return "error making request: " + hide(err), true
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	// Anything past maxOutputLen would be truncated anyway.
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxOutputLen+1))
 	if err != nil {
 		return "error reading response: " + err.Error(), true
 	}
 
-	return truncateOutput(string(respBody), maxOutputLen), resp.StatusCode >= 400
+	result := truncateOutput(string(respBody), maxOutputLen)
+	// A page fetched with session variables isn't one to cite: its link
+	// either doesn't resolve or gives their values away.
+	if resp.StatusCode < 400 && method == http.MethodGet && !expanded {
+		sources.Add(Source{Title: pageTitle(string(respBody)), URL: input.URL})
+		if cacheable {
+			cache.Put("fetch", input.URL, result)
+		}
+	}
+	return result, resp.StatusCode >= 400
 }
 
-func executeWebSearch(input core.ToolInput, apiKey string) (string, bool) {
+func executeWebSearch(ctx context.Context, input core.ToolInput, deps Deps) (string, bool) {
 	if input.Query == "" {
 		return "missing query argument", true
 	}
 
-	if apiKey == "" {
+	if deps.WebSearchAPIKey == "" {
 		return "WEB_SEARCH_API_KEY not configured", true
 	}
-
-	req, err := http.NewRequest(http.MethodGet, webSearchEndpoint, nil)
-	if err != nil {
-		return "error creating request: " + err.Error(), true
-	}
-	q := req.URL.Query()
-	q.Set("q", input.Query)
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("X-Subscription-Token", apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return "error making request: " + err.Error(), true
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "error reading response: " + err.Error(), true
+	if err := deps.Egress.Check(webSearchEndpoint); err != nil {
+		return err.Error(), true
 	}
+	sources, cache := deps.Sources, deps.WebCache
 
-	if resp.StatusCode >= 400 {
-		return "search failed: " + string(respBody), true
+	cached, hit := cache.Get("search", input.Query)
+	respBody := []byte(cached)
+	if !hit {
+		var failure string
+		if respBody, failure = braveSearch(ctx, deps.Egress.client(), input.Query, deps.WebSearchAPIKey); failure != "" {
+			return failure, true
+		}
 	}
 
 	var searchResp struct {
@@ -304,6 +380,9 @@ func executeWebSearch(input core.ToolInput, apiKey string) (string, bool) {
 	if err := json.Unmarshal(respBody, &searchResp); err != nil {
 		return "error parsing response: " + err.Error(), true
 	}
+	if !hit {
+		cache.Put("search", input.Query, string(respBody))
+	}
 
 	if len(searchResp.Web.Results) == 0 {
 		return "No results.", false
@@ -313,6 +392,9 @@ func executeWebSearch(input core.ToolInput, apiKey string) (string, bool) {
 	result.WriteString("Search results:\n\n")
 
 	for i, r := range searchResp.Web.Results {
+		if i < searchSources {
+			sources.Add(Source{Title: r.Title, URL: r.URL})
+		}
 		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, r.Title))
 		result.WriteString("   " + r.URL + "\n")
 		if r.Description != "" {
@@ -323,3 +405,37 @@ func executeWebSearch(input core.ToolInput, apiKey string) (string, bool) {
 
 	return result.String(), false
 }
+
+// braveSearch returns Brave's JSON response for query, or the tool result
+// to report instead.
+func braveSearch(ctx context.Context, client *http.Client, query, apiKey string) ([]byte, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webSearchEndpoint, nil)
+	if err != nil {
+		return nil, "error creating request: " + err.Error()
+	}
+	q := req.URL.Query()
+	q.Set("q", query)
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Set("X-Subscription-Token", apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "error making request: " + err.Error()
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxSearchBytes+1))
+	if err != nil {
+		return nil, "error reading response: " + err.Error()
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, "search failed: " + truncateOutput(string(respBody), maxOutputLen)
+	}
+	if len(respBody) > maxSearchBytes {
+		return nil, fmt.Sprintf("search response too large (max %d bytes)", maxSearchBytes)
+	}
+	return respBody, ""
+}
@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -55,7 +57,7 @@ func TestExecute_ReactEmoji(t *testing.T) {
 	a := assert.New(t)
 	r := &mockResponder{}
 
-	result, isErr := Execute("react_emoji", core.ToolInput{Emoji: "👀"}, Deps{Outbound: r})
+	result, isErr := Execute(context.Background(), "react_emoji", core.ToolInput{Emoji: "👀"}, Deps{Outbound: r})
 
 	a.Equal("reaction added", result)
 	a.False(isErr)
@@ -65,7 +67,7 @@ func TestExecute_ReactEmoji(t *testing.T) {
 func TestExecute_ReactEmoji_MissingArg(t *testing.T) {
 	a := assert.New(t)
 
-	result, isErr := Execute("react_emoji", core.ToolInput{}, Deps{Outbound: &mockResponder{}})
+	result, isErr := Execute(context.Background(), "react_emoji", core.ToolInput{}, Deps{Outbound: &mockResponder{}})
 
 	a.Equal("missing emoji argument", result)
 	a.True(isErr)
@@ -75,7 +77,7 @@ func TestExecute_SendUpdate(t *testing.T) {
 	a := assert.New(t)
 	r := &mockResponder{}
 
-	result, isErr := Execute("send_update", core.ToolInput{Message: "working on it"}, Deps{Outbound: r})
+	result, isErr := Execute(context.Background(), "send_update", core.ToolInput{Message: "working on it"}, Deps{Outbound: r})
 
 	a.Equal("update sent", result)
 	a.False(isErr)
@@ -85,7 +87,7 @@ func TestExecute_SendUpdate(t *testing.T) {
 func TestExecute_SendUpdate_MissingArg(t *testing.T) {
 	a := assert.New(t)
 
-	result, isErr := Execute("send_update", core.ToolInput{}, Deps{Outbound: &mockResponder{}})
+	result, isErr := Execute(context.Background(), "send_update", core.ToolInput{}, Deps{Outbound: &mockResponder{}})
 
 	a.Equal("missing message argument", result)
 	a.True(isErr)
@@ -95,7 +97,7 @@ func TestExecute_Skill(t *testing.T) {
 	a := assert.New(t)
 	store := &mockSkillStore{skills: map[string]string{"greet": "say hello"}}
 
-	result, isErr := Execute("Skill", core.ToolInput{Name: "greet"}, Deps{SkillStore: store})
+	result, isErr := Execute(context.Background(), "Skill", core.ToolInput{Name: "greet"}, Deps{SkillStore: store})
 
 	a.Equal("say hello", result)
 	a.False(isErr)
@@ -105,7 +107,7 @@ func TestExecute_Skill_NotFound(t *testing.T) {
 	a := assert.New(t)
 	store := &mockSkillStore{skills: map[string]string{}}
 
-	result, isErr := Execute("Skill", core.ToolInput{Name: "missing"}, Deps{SkillStore: store})
+	result, isErr := Execute(context.Background(), "Skill", core.ToolInput{Name: "missing"}, Deps{SkillStore: store})
 
 	a.Equal("skill not found: missing", result)
 	a.True(isErr)
@@ -114,7 +116,7 @@ func TestExecute_Skill_NotFound(t *testing.T) {
 func TestExecute_Skill_NilStore(t *testing.T) {
 	a := assert.New(t)
 
-	result, isErr := Execute("Skill", core.ToolInput{Name: "x"}, Deps{})
+	result, isErr := Execute(context.Background(), "Skill", core.ToolInput{Name: "x"}, Deps{})
 
 	a.Equal("skill store not configured", result)
 	a.True(isErr)
@@ -123,7 +125,7 @@ func TestExecute_Skill_NilStore(t *testing.T) {
 func TestExecute_WebSearch_MissingQuery(t *testing.T) {
 	a := assert.New(t)
 
-	result, isErr := Execute("WebSearch", core.ToolInput{}, Deps{WebSearchAPIKey: "k"})
+	result, isErr := Execute(context.Background(), "WebSearch", core.ToolInput{}, Deps{WebSearchAPIKey: "k"})
 
 	a.Equal("missing query argument", result)
 	a.True(isErr)
@@ -132,7 +134,7 @@ func TestExecute_WebSearch_MissingQuery(t *testing.T) {
 func TestExecute_WebSearch_MissingAPIKey(t *testing.T) {
 	a := assert.New(t)
 
-	result, isErr := Execute("WebSearch", core.ToolInput{Query: "go programming"}, Deps{})
+	result, isErr := Execute(context.Background(), "WebSearch", core.ToolInput{Query: "go programming"}, Deps{})
 
 	a.Equal("WEB_SEARCH_API_KEY not configured", result)
 	a.True(isErr)
@@ -170,7 +172,7 @@ func TestExecute_WebSearch_BraveSuccess(t *testing.T) {
 
 	// when
 	// ... WebSearch is executed
-	result, isErr := Execute("WebSearch", core.ToolInput{Query: "go programming"}, Deps{WebSearchAPIKey: "test-key"})
+	result, isErr := Execute(context.Background(), "WebSearch", core.ToolInput{Query: "go programming"}, Deps{WebSearchAPIKey: "test-key"})
 
 	// then
 	// ... the request hits Brave and the response is formatted as a numbered list
@@ -202,7 +204,7 @@ func TestExecute_WebSearch_BraveErrorStatus(t *testing.T) {
 
 	// when
 	// ... WebSearch is executed
-	result, isErr := Execute("WebSearch", core.ToolInput{Query: "anything"}, Deps{WebSearchAPIKey: "bad"})
+	result, isErr := Execute(context.Background(), "WebSearch", core.ToolInput{Query: "anything"}, Deps{WebSearchAPIKey: "bad"})
 
 	// then
 	// ... the error is reported with the response body
@@ -211,11 +213,36 @@ func TestExecute_WebSearch_BraveErrorStatus(t *testing.T) {
 	a.Contains(result, "invalid token")
 }
 
+func TestExecute_WebSearch_OversizedResponse(t *testing.T) {
+	// given
+	// ... a Brave endpoint streaming more than maxSearchBytes
+	a := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"web":{"results":[{"title":"`))
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 2*maxSearchBytes))
+		_, _ = w.Write([]byte(`"}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	prev := webSearchEndpoint
+	webSearchEndpoint = srv.URL + "/res/v1/web/search"
+	t.Cleanup(func() { webSearchEndpoint = prev })
+
+	// when
+	// ... WebSearch is executed
+	result, isErr := Execute(context.Background(), "WebSearch", core.ToolInput{Query: "anything"}, Deps{WebSearchAPIKey: "key"})
+
+	// then
+	// ... the response is refused instead of read whole
+	a.True(isErr)
+	a.Contains(result, "search response too large")
+}
+
 func TestExecute_LoadSkillSupporting(t *testing.T) {
 	a := assert.New(t)
 	store := &mockSkillStore{supporting: map[string][]byte{"greet/refs.md": []byte("ref content")}}
 
-	result, isErr := Execute("LoadSkillSupporting", core.ToolInput{Name: "greet", Path: "refs.md"}, Deps{SkillStore: store})
+	result, isErr := Execute(context.Background(), "LoadSkillSupporting", core.ToolInput{Name: "greet", Path: "refs.md"}, Deps{SkillStore: store})
 
 	a.Equal("ref content", result)
 	a.False(isErr)
@@ -224,7 +251,7 @@ func TestExecute_LoadSkillSupporting(t *testing.T) {
 func TestExecute_UnknownTool(t *testing.T) {
 	a := assert.New(t)
 
-	result, isErr := Execute("bogus", core.ToolInput{}, Deps{})
+	result, isErr := Execute(context.Background(), "bogus", core.ToolInput{}, Deps{})
 
 	a.Equal("unknown tool: bogus", result)
 	a.True(isErr)
@@ -264,7 +291,7 @@ func TestExecuteRead_ImageByExtension(t *testing.T) {
 		path := filepath.Join(dir, name)
 		r.NoError(os.WriteFile(path, []byte("anything"), 0o644))
 
-		out, isErr := executeRead(core.ToolInput{FilePath: path})
+		out, isErr := executeRead(core.ToolInput{FilePath: path}, Deps{})
 		a.False(isErr, name)
 		a.True(strings.HasPrefix(out, ImageSentinel+"\t"), "name=%s out=%q", name, out)
 	}
@@ -278,7 +305,7 @@ func TestExecuteRead_ImageBySniff(t *testing.T) {
 	path := filepath.Join(dir, "noext")
 	r.NoError(os.WriteFile(path, pngBytes, 0o644))
 
-	out, isErr := executeRead(core.ToolInput{FilePath: path})
+	out, isErr := executeRead(core.ToolInput{FilePath: path}, Deps{})
 	a.False(isErr)
 	a.True(strings.HasPrefix(out, ImageSentinel+"\timage/png\t"))
 }
@@ -291,14 +318,14 @@ func TestExecuteRead_TextUnchanged(t *testing.T) {
 	path := filepath.Join(dir, "hello.txt")
 	r.NoError(os.WriteFile(path, []byte("hello"), 0o644))
 
-	out, isErr := executeRead(core.ToolInput{FilePath: path})
+	out, isErr := executeRead(core.ToolInput{FilePath: path}, Deps{})
 	a.False(isErr)
 	a.Equal("hello", out)
 }
 
 func TestExecuteRead_MissingFile(t *testing.T) {
 	a := assert.New(t)
-	out, isErr := executeRead(core.ToolInput{FilePath: "/nonexistent/missing"})
+	out, isErr := executeRead(core.ToolInput{FilePath: "/nonexistent/missing"}, Deps{})
 	a.True(isErr)
 	a.Contains(out, "error reading file")
 }
@@ -312,9 +339,72 @@ func TestExecuteBash_Timeout(t *testing.T) {
 	defer func() { bashTimeout = old }()
 
 	// when
-	result, isErr := executeBash(core.ToolInput{Command: "sleep 10"})
+	result, isErr := executeBash(context.Background(), core.ToolInput{Command: "sleep 10"}, Deps{})
 
 	// then
 	a.True(isErr)
 	a.Contains(result, "signal: killed")
 }
+
+func TestExecuteBash_EnvPolicyStripsDeniedVars(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	t.Setenv("SB_TEST_SECRET", "hunter2")
+	t.Setenv("SB_TEST_VISIBLE", "ok")
+
+	// when
+	result, isErr := Execute(context.Background(), "Bash", core.ToolInput{Command: "echo \"[$SB_TEST_SECRET][$SB_TEST_VISIBLE]\""}, Deps{Env: EnvPolicy{Deny: []string{"SB_TEST_SECRET"}}})
+
+	// then
+	a.False(isErr)
+	a.Equal("[][ok]\n", result)
+}
+
+func TestExecuteBash_SessionEnvOverridesInherited(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	t.Setenv("TEST_DATABASE_URL", "postgres://global")
+	deps := Deps{SessionEnv: map[string]string{"TEST_DATABASE_URL": "postgres://session", "SB_TEST_EXTRA": "1"}}
+
+	// when
+	result, isErr := Execute(context.Background(), "Bash", core.ToolInput{Command: "echo \"[$TEST_DATABASE_URL][$SB_TEST_EXTRA]\""}, deps)
+
+	// then
+	a.False(isErr)
+	a.Equal("[postgres://session][1]\n", result)
+	a.Equal("postgres://global", os.Getenv("TEST_DATABASE_URL"))
+}
+
+func TestExecuteFetch_ExpandsSessionEnv(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a server that echoes the path and auth header, behind a cache
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s %s", req.URL.Path, req.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+	cache, err := NewWebCache(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	sources := &Sources{}
+	deps := Deps{
+		SessionEnv: map[string]string{"API_BASE": srv.URL, "API_TOKEN": "s3cret-token"},
+		Egress:     loopbackEgress(t, EgressConfig{}),
+		WebCache:   cache,
+		Sources:    sources,
+	}
+	input := core.ToolInput{URL: "${API_BASE}/items", Headers: map[string]string{"Authorization": "Bearer $API_TOKEN"}}
+
+	// when
+	result, isErr := Execute(context.Background(), "Fetch", input, deps)
+
+	// then
+	a.False(isErr)
+	a.Equal("/items Bearer s3cret-token", result)
+	// ... the values stay out of the sources and the cache
+	a.Empty(sources.list)
+	_, cached := cache.Get("fetch", srv.URL+"/items")
+	a.False(cached)
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var toolHookTimeout = 30 * time.Second
+
+// ToolHooks are operator shell commands run around every tool call with
+// a JSON description of the call on stdin and SWITCHBOARD_TOOL set to the
+// tool name. A non-zero exit from Pre vetoes the call; its output is the
+// reason. A non-zero exit from Post is reported to the model alongside
+// the tool result.
+type ToolHooks struct {
+	Pre  string
+	Post string
+}
+
+// ToolCall describes the call a hook runs for.
+type ToolCall struct {
+	Tool      string          `json:"tool"`
+	Input     json.RawMessage `json:"input"`
+	SessionID string          `json:"session_id"`
+	WorkDir   string          `json:"work_dir"`
+	Result    *string         `json:"result,omitempty"`
+	IsError   *bool           `json:"is_error,omitempty"`
+}
+
+// RunPreToolHook runs deps.Hooks.Pre for call and reports whether the call
+// may go ahead.
+func RunPreToolHook(ctx context.Context, deps Deps, call ToolCall) (bool, string) {
+	if deps.Hooks.Pre == "" {
+		return true, ""
+	}
+	out, err := runToolHook(ctx, deps, deps.Hooks.Pre, call)
+	if err == nil {
+		return true, ""
+	}
+	slog.Info("tool call vetoed by hook", "tool", call.Tool, "error", err)
+	if out == "" {
+		out = "pre-tool hook: " + err.Error()
+	}
+	return false, out
+}
+
+// RunPostToolHook runs deps.Hooks.Post after call produced result and
+// returns result, with the hook's output appended if it failed.
+func RunPostToolHook(ctx context.Context, deps Deps, call ToolCall, result string, isError bool) string {
+	if deps.Hooks.Post == "" {
+		return result
+	}
+	// Image payloads are neither shown to the hook nor extended: appending
+	// would corrupt the base64.
+	image := strings.HasPrefix(result, ImageSentinel+"\t")
+	shown := result
+	if image {
+		shown = "(image)"
+	}
+	call.Result, call.IsError = &shown, &isError
+	out, err := runToolHook(ctx, deps, deps.Hooks.Post, call)
+	if err == nil {
+		return result
+	}
+	slog.Warn("post-tool hook failed", "tool", call.Tool, "error", err, "output", out)
+	if image {
+		return result
+	}
+	if out == "" {
+		out = err.Error()
+	}
+	return result + "\n\npost-tool hook failed: " + out
+}
+
+// runToolHook runs command with sh in the session's working directory and
+// returns its trimmed combined output.
+func runToolHook(ctx context.Context, deps Deps, command string, call ToolCall) (string, error) {
+	payload, err := json.Marshal(call)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, toolHookTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = deps.WorkDir
+	cmd.Env = append(deps.Env.Filter(os.Environ()), "SWITCHBOARD_TOOL="+call.Tool)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	return truncateOutput(strings.TrimSpace(out.String()), maxOutputLen), err
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPreToolHook_VetoesOnNonZeroExit(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a hook blocking Bash calls that mention a protected path
+	deps := Deps{WorkDir: t.TempDir(), Hooks: ToolHooks{
+		Pre: `[ "$SWITCHBOARD_TOOL" = Bash ] && grep -q /etc/ && { echo "/etc is protected"; exit 1; }; exit 0`,
+	}}
+
+	// when
+	blocked, reason := RunPreToolHook(context.Background(), deps,
+		ToolCall{Tool: "Bash", Input: json.RawMessage(`{"command":"rm /etc/hosts"}`)})
+	allowed, _ := RunPreToolHook(context.Background(), deps,
+		ToolCall{Tool: "Bash", Input: json.RawMessage(`{"command":"ls"}`)})
+	otherTool, _ := RunPreToolHook(context.Background(), deps,
+		ToolCall{Tool: "Read", Input: json.RawMessage(`{"file_path":"/etc/hosts"}`)})
+
+	// then
+	a.False(blocked)
+	a.Equal("/etc is protected", reason)
+	a.True(allowed)
+	a.True(otherTool)
+}
+
+func TestRunPreToolHook_NoHookAllows(t *testing.T) {
+	ok, reason := RunPreToolHook(context.Background(), Deps{}, ToolCall{Tool: "Bash"})
+
+	assert.True(t, ok)
+	assert.Empty(t, reason)
+}
+
+func TestRunPostToolHook_ReceivesResultAndRunsInWorkDir(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a hook saving its stdin into the working directory
+	dir := t.TempDir()
+	deps := Deps{WorkDir: dir, Hooks: ToolHooks{Post: "cat > hook.json"}}
+
+	// when
+	result := RunPostToolHook(context.Background(), deps,
+		ToolCall{Tool: "Bash", Input: json.RawMessage(`{"command":"ls"}`), SessionID: "s1", WorkDir: dir}, "a.go\n", false)
+
+	// then
+	a.Equal("a.go\n", result)
+	data, err := os.ReadFile(filepath.Join(dir, "hook.json"))
+	r.NoError(err)
+	var got map[string]any
+	r.NoError(json.Unmarshal(data, &got))
+	a.Equal("Bash", got["tool"])
+	a.Equal(map[string]any{"command": "ls"}, got["input"])
+	a.Equal("s1", got["session_id"])
+	a.Equal("a.go\n", got["result"])
+	a.Equal(false, got["is_error"])
+}
+
+func TestRunPostToolHook_FailureIsAppended(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	deps := Deps{WorkDir: t.TempDir(), Hooks: ToolHooks{Post: "echo 'main.go:3: syntax error'; exit 2"}}
+	image := ImageSentinel + "\timage/png\taGk="
+
+	// when
+	result := RunPostToolHook(context.Background(), deps, ToolCall{Tool: "write_artifact"}, "saved", false)
+	imageResult := RunPostToolHook(context.Background(), deps, ToolCall{Tool: "Read"}, image, false)
+
+	// then
+	// ... the model sees the hook output, except where it would corrupt an image
+	a.Equal("saved\n\npost-tool hook failed: main.go:3: syntax error", result)
+	a.Equal(image, imageResult)
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/imagegen"
+)
+
+type generateImageInput struct {
+	Prompt string `json:"prompt"`
+	Size   string `json:"size"`
+}
+
+// imageBudget caps paid generations per UTC day across all sessions.
+type imageBudget struct {
+	mu    sync.Mutex
+	limit int
+	day   string
+	used  int
+	now   func() time.Time
+}
+
+// take reserves one generation, reporting false when the day's limit is
+// spent. A zero limit is unlimited.
+func (b *imageBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if day := b.now().UTC().Format(time.DateOnly); day != b.day {
+		b.day, b.used = day, 0
+	}
+	if b.limit > 0 && b.used >= b.limit {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// refund returns a reservation for a generation that failed.
+func (b *imageBudget) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used > 0 {
+		b.used--
+	}
+}
+
+// GenerateImageTool returns a tool that generates a PNG with gen and posts
+// it to the channel as an attachment. dailyLimit caps generations per UTC
+// day across all sessions; 0 is unlimited.
+func GenerateImageTool(gen imagegen.Generator, dailyLimit int) core.RegisteredTool {
+	budget := &imageBudget{limit: dailyLimit, now: time.Now}
+	return core.RegisteredTool{
+		Def: core.ToolDef{
+			Name:        "generate_image",
+			Description: "Generate an image (diagram, mockup, illustration) from a text prompt and post it to the chat as an attachment. Generations are limited per day, so refine the prompt before calling.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"prompt": strProp("Detailed description of the image"),
+					"size":   map[string]any{"type": "string", "default": imagegen.DefaultSize, "description": "WIDTHxHEIGHT, e.g. 1024x1024 or 1536x1024"},
+				},
+				"required": []string{"prompt"},
+			},
+		},
+		Execute: func(ctx context.Context, raw json.RawMessage, out core.Outbound) (string, bool) {
+			var input generateImageInput
+			if err := json.Unmarshal(raw, &input); err != nil {
+				return "invalid input: " + err.Error(), true
+			}
+			if input.Prompt == "" {
+				return "missing prompt argument", true
+			}
+			if input.Size == "" {
+				input.Size = imagegen.DefaultSize
+			}
+			if _, _, err := imagegen.ParseSize(input.Size); err != nil {
+				return err.Error(), true
+			}
+			fs, ok := out.(core.FileSender)
+			if !ok {
+				return "this channel cannot receive files", true
+			}
+			if !budget.take() {
+				return fmt.Sprintf("daily image limit of %d reached; try again tomorrow", dailyLimit), true
+			}
+
+			png, err := gen.Generate(ctx, input.Prompt, input.Size)
+			if err != nil {
+				budget.refund()
+				return "image generation failed: " + err.Error(), true
+			}
+			if err := fs.SendFile("image.png", png); err != nil {
+				return "error sending image: " + err.Error(), true
+			}
+			return "image sent", false
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubGenerator struct {
+	prompts []string
+	err     error
+}
+
+func (g *stubGenerator) Generate(_ context.Context, prompt, _ string) ([]byte, error) {
+	g.prompts = append(g.prompts, prompt)
+	return []byte("png"), g.err
+}
+
+func TestGenerateImageTool_PostsImage(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	gen := &stubGenerator{}
+	out := &fileResponder{}
+	tool := GenerateImageTool(gen, 0)
+
+	// when
+	result, isErr := tool.Execute(context.Background(), json.RawMessage(`{"prompt":"login page mockup"}`), out)
+
+	// then
+	a.False(isErr, result)
+	a.Equal([]string{"login page mockup"}, gen.prompts)
+	a.Equal([]string{"image.png"}, out.names)
+	a.Equal([]string{"png"}, out.contents)
+}
+
+func TestGenerateImageTool_EnforcesDailyLimit(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a limit of one image, with the first attempt failing
+	gen := &stubGenerator{err: errors.New("upstream down")}
+	tool := GenerateImageTool(gen, 1)
+	run := func() (string, bool) {
+		return tool.Execute(context.Background(), json.RawMessage(`{"prompt":"diagram"}`), &fileResponder{})
+	}
+
+	// when
+	_, failedErr := run()
+	gen.err = nil
+	_, firstErr := run()
+	result, secondErr := run()
+
+	// then
+	// ... the failed attempt is refunded; the limit stops the third call
+	a.True(failedErr)
+	a.False(firstErr)
+	a.True(secondErr)
+	a.Contains(result, "daily image limit of 1")
+	a.Len(gen.prompts, 2)
+}
+
+func TestImageBudget_ResetsEachDay(t *testing.T) {
+	a := assert.New(t)
+	now := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	b := &imageBudget{limit: 1, now: func() time.Time { return now }}
+
+	a.True(b.take())
+	a.False(b.take())
+	now = now.Add(2 * time.Hour)
+	a.True(b.take())
+}
+
+func TestGenerateImageTool_RejectsBadSize(t *testing.T) {
+	tool := GenerateImageTool(&stubGenerator{}, 0)
+
+	result, isErr := tool.Execute(context.Background(), json.RawMessage(`{"prompt":"x","size":"big"}`), &fileResponder{})
+
+	assert.True(t, isErr)
+	assert.Contains(t, result, "WIDTHxHEIGHT")
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+var kubectlPath = "kubectl"
+
+// maxKubeLogLines caps kube_logs so a chatty pod can't flood the context.
+const maxKubeLogLines = 500
+
+// KubeScopes maps each kubeconfig context the tools may use to the
+// namespaces allowed in it. A "*" namespace allows any.
+type KubeScopes map[string][]string
+
+type kubeInput struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Selector  string `json:"selector"`
+	Container string `json:"container"`
+	Previous  bool   `json:"previous"`
+	Tail      int    `json:"tail"`
+	Manifest  string `json:"manifest"`
+}
+
+// KubeReadTools returns kube_get, kube_describe and kube_logs.
+func KubeReadTools(scopes KubeScopes, env EnvPolicy) []core.RegisteredTool {
+	return []core.RegisteredTool{
+		kubeTool(scopes, env, "kube_get", "List or get Kubernetes resources (kubectl get -o wide).",
+			kubeProps("resource", "name", "selector"), []string{"resource"}, kubeGetArgs),
+		kubeTool(scopes, env, "kube_describe", "Describe a Kubernetes resource, including recent events (kubectl describe).",
+			kubeProps("resource", "name", "selector"), []string{"resource"}, kubeDescribeArgs),
+		kubeTool(scopes, env, "kube_logs", "Fetch pod logs (kubectl logs). Set previous to read the last crashed container.",
+			kubeProps("name", "container", "previous", "tail"), []string{"name"}, kubeLogsArgs),
+	}
+}
+
+// KubeWriteTools returns kube_apply and kube_delete.
+func KubeWriteTools(scopes KubeScopes, env EnvPolicy) []core.RegisteredTool {
+	return []core.RegisteredTool{
+		kubeTool(scopes, env, "kube_apply", "Apply a YAML manifest (kubectl apply -f -). Objects must belong to the given namespace.",
+			kubeProps("manifest"), []string{"manifest"}, kubeApplyArgs),
+		kubeTool(scopes, env, "kube_delete", "Delete a named Kubernetes resource (kubectl delete).",
+			kubeProps("resource", "name"), []string{"resource", "name"}, kubeDeleteArgs),
+	}
+}
+
+func kubeTool(scopes KubeScopes, env EnvPolicy, name, desc string, props map[string]any, required []string, args func(kubeInput) ([]string, string)) core.RegisteredTool {
+	contexts := make([]string, 0, len(scopes))
+	for c := range scopes {
+		contexts = append(contexts, c)
+	}
+	sort.Strings(contexts)
+	props["context"] = map[string]any{"type": "string", "enum": contexts, "description": "kubeconfig context"}
+	props["namespace"] = strProp("Namespace")
+
+	return core.RegisteredTool{
+		Def: core.ToolDef{
+			Name:        name,
+			Description: desc,
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": props,
+				"required":   append([]string{"context", "namespace"}, required...),
+			},
+		},
+		Execute: func(ctx context.Context, raw json.RawMessage, _ core.Outbound) (string, bool) {
+			var input kubeInput
+			if err := json.Unmarshal(raw, &input); err != nil {
+				return "invalid input: " + err.Error(), true
+			}
+			if reason := scopes.check(input.Context, input.Namespace); reason != "" {
+				return reason, true
+			}
+			for _, v := range []string{input.Resource, input.Name, input.Selector, input.Container} {
+				if strings.HasPrefix(v, "-") {
+					return fmt.Sprintf("invalid argument %q", v), true
+				}
+			}
+			verbArgs, missing := args(input)
+			if missing != "" {
+				return "missing " + missing + " argument", true
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, bashTimeout)
+			defer cancel()
+			cmdArgs := append([]string{"--context", input.Context, "--namespace", input.Namespace}, verbArgs...)
+			cmd := exec.CommandContext(ctx, kubectlPath, cmdArgs...)
+			cmd.Env = env.Filter(os.Environ())
+			if input.Manifest != "" {
+				cmd.Stdin = strings.NewReader(input.Manifest)
+			}
+			return runProcess(cmd)
+		},
+	}
+}
+
+// check returns why context/namespace is out of scope, or "".
+func (s KubeScopes) check(context, namespace string) string {
+	namespaces, ok := s[context]
+	if !ok {
+		return fmt.Sprintf("context %q is not configured", context)
+	}
+	if namespace == "" {
+		return "missing namespace argument"
+	}
+	for _, ns := range namespaces {
+		if ns == "*" || ns == namespace {
+			return ""
+		}
+	}
+	return fmt.Sprintf("namespace %q is not allowed in context %q", namespace, context)
+}
+
+func kubeProps(names ...string) map[string]any {
+	all := map[string]any{
+		"resource":  strProp("Resource type, e.g. pods, deployments, svc"),
+		"name":      strProp("Resource name (pod name for logs)"),
+		"selector":  strProp("Label selector, e.g. app=web"),
+		"container": strProp("Container name, for multi-container pods"),
+		"previous":  map[string]any{"type": "boolean", "description": "Logs of the previous, crashed container instance"},
+		"tail":      map[string]any{"type": "integer", "description": fmt.Sprintf("Number of recent lines (default and max %d)", maxKubeLogLines)},
+		"manifest":  strProp("YAML manifest to apply"),
+	}
+	props := make(map[string]any, len(names))
+	for _, n := range names {
+		props[n] = all[n]
+	}
+	return props
+}
+
+func strProp(desc string) map[string]any {
+	return map[string]any{"type": "string", "description": desc}
+}
+
+func kubeGetArgs(in kubeInput) ([]string, string) {
+	if in.Resource == "" {
+		return nil, "resource"
+	}
+	return append(appendTarget([]string{"get", in.Resource}, in), "-o", "wide"), ""
+}
+
+func kubeDescribeArgs(in kubeInput) ([]string, string) {
+	if in.Resource == "" {
+		return nil, "resource"
+	}
+	return appendTarget([]string{"describe", in.Resource}, in), ""
+}
+
+func kubeLogsArgs(in kubeInput) ([]string, string) {
+	if in.Name == "" {
+		return nil, "name"
+	}
+	tail := in.Tail
+	if tail <= 0 || tail > maxKubeLogLines {
+		tail = maxKubeLogLines
+	}
+	args := []string{"logs", in.Name, "--tail", strconv.Itoa(tail)}
+	if in.Container != "" {
+		args = append(args, "--container", in.Container)
+	}
+	if in.Previous {
+		args = append(args, "--previous")
+	}
+	return args, ""
+}
+
+func kubeApplyArgs(in kubeInput) ([]string, string) {
+	if in.Manifest == "" {
+		return nil, "manifest"
+	}
+	return []string{"apply", "-f", "-"}, ""
+}
+
+func kubeDeleteArgs(in kubeInput) ([]string, string) {
+	if in.Resource == "" {
+		return nil, "resource"
+	}
+	if in.Name == "" {
+		return nil, "name"
+	}
+	return []string{"delete", in.Resource, in.Name}, ""
+}
+
+func appendTarget(args []string, in kubeInput) []string {
+	if in.Name != "" {
+		args = append(args, in.Name)
+	}
+	if in.Selector != "" {
+		args = append(args, "--selector", in.Selector)
+	}
+	return args
+}
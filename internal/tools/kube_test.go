@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKubectl points kubectlPath at a script that echoes its args and stdin.
+func fakeKubectl(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubectl")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho \"$@\"\n[ -t 0 ] || cat\n"), 0o755))
+	old := kubectlPath
+	kubectlPath = path
+	t.Cleanup(func() { kubectlPath = old })
+}
+
+func findTool(tools []core.RegisteredTool, name string) core.RegisteredTool {
+	for _, t := range tools {
+		if t.Def.Name == name {
+			return t
+		}
+	}
+	return core.RegisteredTool{}
+}
+
+func TestKubeReadTools_BuildKubectlArgs(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	fakeKubectl(t)
+	tools := KubeReadTools(KubeScopes{"prod": {"web"}}, EnvPolicy{})
+
+	// when
+	get, getErr := findTool(tools, "kube_get").Execute(context.Background(), json.RawMessage(`{"context":"prod","namespace":"web","resource":"pods","selector":"app=api"}`), nil)
+	logs, logsErr := findTool(tools, "kube_logs").Execute(context.Background(), json.RawMessage(`{"context":"prod","namespace":"web","name":"api-1","previous":true}`), nil)
+
+	// then
+	a.False(getErr, get)
+	a.Equal("--context prod --namespace web get pods --selector app=api -o wide\n", get)
+	a.False(logsErr, logs)
+	a.Equal("--context prod --namespace web logs api-1 --tail 500 --previous\n", logs)
+}
+
+func TestKubeTools_EnforceScopes(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	fakeKubectl(t)
+	get := findTool(KubeReadTools(KubeScopes{"prod": {"web"}, "staging": {"*"}}, EnvPolicy{}), "kube_get")
+	run := func(input string) (string, bool) {
+		return get.Execute(context.Background(), json.RawMessage(input), nil)
+	}
+
+	// then
+	result, isErr := run(`{"context":"dev","namespace":"web","resource":"pods"}`)
+	a.True(isErr)
+	a.Contains(result, "not configured")
+	result, isErr = run(`{"context":"prod","namespace":"kube-system","resource":"pods"}`)
+	a.True(isErr)
+	a.Contains(result, "not allowed")
+	_, isErr = run(`{"context":"staging","namespace":"anything","resource":"pods"}`)
+	a.False(isErr)
+	result, isErr = run(`{"context":"prod","namespace":"web","resource":"pods","name":"--all-namespaces"}`)
+	a.True(isErr)
+	a.Contains(result, "invalid argument")
+}
+
+func TestKubeWriteTools_ApplyPipesManifest(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	fakeKubectl(t)
+	apply := findTool(KubeWriteTools(KubeScopes{"prod": {"web"}}, EnvPolicy{}), "kube_apply")
+
+	// when
+	result, isErr := apply.Execute(context.Background(), json.RawMessage(`{"context":"prod","namespace":"web","manifest":"kind: ConfigMap"}`), nil)
+
+	// then
+	a.False(isErr, result)
+	a.Equal("--context prod --namespace web apply -f -\nkind: ConfigMap", result)
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// pageLines returns the lines of text from the 1-based offset, at most
+// limit of them (0 is no limit) and at most maxLen bytes, cut at a line
+// boundary. A text that fits whole is returned as is; otherwise a footer
+// says which lines were shown and where to continue.
+func pageLines(text string, offset, limit, maxLen int) (string, error) {
+	if offset < 0 || limit < 0 {
+		return "", errors.New("offset and limit must not be negative")
+	}
+	if text == "" && offset <= 1 {
+		return "", nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	total := len(lines)
+	start := max(offset, 1)
+	if start > total {
+		return "", errors.Errorf("offset %d is past the end of the file (%d lines)", offset, total)
+	}
+	end := total
+	if limit > 0 {
+		end = min(start-1+limit, total)
+	}
+
+	var b strings.Builder
+	shown := start - 1
+	for _, line := range lines[start-1 : end] {
+		if b.Len()+len(line) > maxLen {
+			if shown == start-1 {
+				// A single line longer than maxLen: show what fits and say
+				// so, since paging by line cannot reach the rest of it.
+				b.WriteString(truncateLine(line, maxLen))
+				shown++
+			}
+			break
+		}
+		b.WriteString(line)
+		shown++
+	}
+	if start == 1 && shown == total {
+		return b.String(), nil
+	}
+
+	out := strings.TrimSuffix(b.String(), "\n")
+	if shown < total {
+		return out + fmt.Sprintf("\n... (lines %d-%d of %d; continue with offset %d)", start, shown, total, shown+1), nil
+	}
+	return out + fmt.Sprintf("\n... (lines %d-%d of %d)", start, shown, total), nil
+}
+
+// truncateLine cuts line to at most maxLen bytes on a rune boundary and
+// marks it with the line's full length.
+func truncateLine(line string, maxLen int) string {
+	line = strings.TrimSuffix(line, "\n")
+	if len(line) <= maxLen {
+		return line + "\n"
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + fmt.Sprintf(" … [line truncated, %d bytes]\n", len(line))
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageLines(t *testing.T) {
+	text := "one\ntwo\nthree\nfour\n"
+	tests := []struct {
+		name          string
+		offset, limit int
+		maxLen        int
+		want          string
+	}{
+		{"whole file", 0, 0, 100, text},
+		{"range", 2, 2, 100, "two\nthree\n... (lines 2-3 of 4; continue with offset 4)"},
+		{"to the end", 3, 0, 100, "three\nfour\n... (lines 3-4 of 4)"},
+		{"limit past the end", 4, 10, 100, "four\n... (lines 4-4 of 4)"},
+		{"cut at a line boundary", 0, 0, 10, "one\ntwo\n... (lines 1-2 of 4; continue with offset 3)"},
+		{"one long line", 0, 0, 2, "on … [line truncated, 3 bytes]\n... (lines 1-1 of 4; continue with offset 2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pageLines(text, tt.offset, tt.limit, tt.maxLen)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPageLines_LongMultiByteLine(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... a line of three-byte runes longer than maxLen
+	text := strings.Repeat("世", 10) + "\nnext\n"
+
+	// when
+	got, err := pageLines(text, 0, 0, 8)
+
+	// then
+	a.NoError(err)
+	a.True(utf8.ValidString(got))
+	a.Equal("世世 … [line truncated, 30 bytes]\n... (lines 1-1 of 2; continue with offset 2)", got)
+}
+
+func TestPageLines_BadRange(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := pageLines("a\nb\n", 3, 0, 100)
+	a.EqualError(err, "offset 3 is past the end of the file (2 lines)")
+	_, err = pageLines("a\n", 0, -1, 100)
+	a.Error(err)
+	got, err := pageLines("", 0, 0, 100)
+	a.NoError(err)
+	a.Empty(got)
+}
+
+func TestExecuteRead_PagesLongFiles(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a file longer than one Read can return
+	line := strings.Repeat("x", 99) + "\n"
+	path := filepath.Join(t.TempDir(), "big.txt")
+	r.NoError(os.WriteFile(path, []byte(strings.Repeat(line, 600)), 0o644))
+
+	// when
+	first, firstErr := executeRead(core.ToolInput{FilePath: path}, Deps{})
+	next, nextErr := executeRead(core.ToolInput{FilePath: path, Offset: 501, Limit: 50}, Deps{})
+
+	// then
+	a.False(firstErr)
+	a.True(strings.HasSuffix(first, "... (lines 1-500 of 600; continue with offset 501)"), first[len(first)-80:])
+	a.False(nextErr)
+	a.Equal(strings.Repeat(line, 50)+"... (lines 501-550 of 600; continue with offset 551)", next)
+}
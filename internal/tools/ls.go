@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+// maxListEntries caps how many entries one LS call returns.
+const maxListEntries = 500
+
+// executeLS lists one directory, defaulting to the work dir, like ls -la
+// but without the entries git ignores or .git itself.
+func executeLS(ctx context.Context, input core.ToolInput, deps Deps) (string, bool) {
+	dir := input.Path
+	if dir == "" {
+		dir = deps.WorkDir
+	}
+	if dir == "" {
+		return "missing path argument", true
+	}
+	real, err := deps.containedPath(filepath.Clean(dir))
+	if err != nil {
+		return "error listing directory: " + err.Error(), true
+	}
+	entries, err := os.ReadDir(real)
+	if err != nil {
+		return "error listing directory: " + err.Error(), true
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Name() != ".git" {
+			names = append(names, e.Name())
+		}
+	}
+	ignored := gitIgnored(ctx, deps, real, names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n", dir)
+	var shown, skipped int
+	for _, e := range entries {
+		if e.Name() == ".git" || ignored[e.Name()] {
+			skipped++
+			continue
+		}
+		if shown == maxListEntries {
+			break
+		}
+		shown++
+		info, err := e.Info()
+		if err != nil {
+			fmt.Fprintf(&b, "?  %s\n", e.Name())
+			continue
+		}
+		b.WriteString(formatEntry(real, info))
+	}
+	if rest := len(entries) - skipped - shown; rest > 0 {
+		fmt.Fprintf(&b, "... (%d more entries; list a subdirectory or use a narrower path)\n", rest)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&b, "(%d hidden: .git or ignored by git)\n", skipped)
+	}
+	return truncateOutput(strings.TrimSuffix(b.String(), "\n"), maxOutputLen), false
+}
+
+// formatEntry renders one ls -l style line: mode, size, mtime and name,
+// with a trailing slash on directories and the target of a symlink.
+func formatEntry(dir string, info os.FileInfo) string {
+	name := info.Name()
+	size := fmt.Sprint(info.Size())
+	switch {
+	case info.IsDir():
+		name += "/"
+		size = "-"
+	case info.Mode()&os.ModeSymlink != 0:
+		if target, err := os.Readlink(filepath.Join(dir, name)); err == nil {
+			name += " -> " + target
+		}
+	}
+	return fmt.Sprintf("%s %10s  %s  %s\n", info.Mode(), size, info.ModTime().Format("2006-01-02 15:04"), name)
+}
+
+// gitIgnored returns which of names in dir git ignores. Outside a work
+// tree, or when git is missing, nothing is ignored.
+func gitIgnored(ctx context.Context, deps Deps, dir string, names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "check-ignore", "--stdin", "-z")
+	cmd.Dir = dir
+	cmd.Env = deps.Env.Filter(os.Environ())
+	cmd.Stdin = strings.NewReader(strings.Join(names, "\x00") + "\x00")
+	// Exit status 1 means nothing is ignored; anything else is not a repo.
+	out, _ := cmd.Output()
+	ignored := make(map[string]bool)
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			ignored[string(name)] = true
+		}
+	}
+	return ignored
+}
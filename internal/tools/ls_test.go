@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteLS_SkipsGitIgnored(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a repository ignoring a build directory and log files
+	dir := gitRepo(t)
+	r.NoError(os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("build/\n*.log\n"), 0o644))
+	r.NoError(os.Mkdir(filepath.Join(dir, "build"), 0o755))
+	r.NoError(os.Mkdir(filepath.Join(dir, "src"), 0o755))
+	r.NoError(os.WriteFile(filepath.Join(dir, "debug.log"), []byte("x"), 0o644))
+
+	// when
+	got, isErr := Execute(context.Background(), "LS", core.ToolInput{}, Deps{WorkDir: dir})
+
+	// then
+	// ... the work dir is listed with sizes, ignored entries and .git left out
+	a.False(isErr, got)
+	a.True(strings.HasPrefix(got, dir+":\n"), got)
+	a.Contains(got, "src/")
+	a.Regexp(`-rw-r--r-- +3  \d{4}-\d\d-\d\d \d\d:\d\d  README`, got)
+	a.NotContains(got, "build")
+	a.NotContains(got, "debug.log")
+	a.NotContains(got, ".git/")
+	a.Contains(got, "(3 hidden: .git or ignored by git)")
+}
+
+func TestExecuteLS_CapsEntries(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	dir := t.TempDir()
+	for i := range maxListEntries + 5 {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%04d", i)), nil, 0o644))
+	}
+
+	// when
+	got, isErr := Execute(context.Background(), "LS", core.ToolInput{Path: dir}, Deps{})
+
+	// then
+	a.False(isErr)
+	a.Contains(got, "f0499")
+	a.NotContains(got, "f0500")
+	a.True(strings.HasSuffix(got, "... (5 more entries; list a subdirectory or use a narrower path)"), got)
+}
+
+func TestExecuteLS_OutsideAllowedDirs(t *testing.T) {
+	got, isErr := Execute(context.Background(), "LS", core.ToolInput{Path: t.TempDir()}, Deps{AllowedDirs: []string{t.TempDir()}})
+
+	assert.True(t, isErr)
+	assert.Contains(t, got, "outside allowed directories")
+}
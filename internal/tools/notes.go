@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/memory"
+)
+
+type noteInput struct {
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// NoteTools returns remember and recall, which keep key-value notes about
+// the current user or channel. The identity comes from the turn's context.
+func NoteTools(notes *memory.Notes) []core.RegisteredTool {
+	scopeProp := map[string]any{
+		"type":        "string",
+		"enum":        []string{"user", "channel"},
+		"default":     "user",
+		"description": "user for preferences of the person talking to you, channel for conventions of this chat or project",
+	}
+	return []core.RegisteredTool{
+		{
+			Def: core.ToolDef{
+				Name:        "remember",
+				Description: "Save a short note (a preference, project convention or fact) that will be shown to you in future sessions. Reusing a key replaces its value; an empty value forgets it.",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"scope": scopeProp,
+						"key":   strProp("Short name for the note, e.g. language or test-command"),
+						"value": strProp("The note itself, one line"),
+					},
+					"required": []string{"key", "value"},
+				},
+			},
+			Execute: func(ctx context.Context, raw json.RawMessage, _ core.Outbound) (string, bool) {
+				var input noteInput
+				if err := json.Unmarshal(raw, &input); err != nil {
+					return "invalid input: " + err.Error(), true
+				}
+				scope, errMsg := noteScope(ctx, input.Scope)
+				if errMsg != "" {
+					return errMsg, true
+				}
+				if err := notes.Set(scope, input.Key, input.Value); err != nil {
+					return "error saving note: " + err.Error(), true
+				}
+				if strings.TrimSpace(input.Value) == "" {
+					return "forgot " + input.Key, false
+				}
+				return "remembered " + input.Key, false
+			},
+		},
+		{
+			Def: core.ToolDef{
+				Name:        "recall",
+				Description: "List the notes saved with remember for the current user or channel.",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"scope": scopeProp,
+					},
+				},
+			},
+			Execute: func(ctx context.Context, raw json.RawMessage, _ core.Outbound) (string, bool) {
+				var input noteInput
+				if err := json.Unmarshal(raw, &input); err != nil {
+					return "invalid input: " + err.Error(), true
+				}
+				scope, errMsg := noteScope(ctx, input.Scope)
+				if errMsg != "" {
+					return errMsg, true
+				}
+				list, err := notes.List(scope)
+				if err != nil {
+					return "error reading notes: " + err.Error(), true
+				}
+				if len(list) == 0 {
+					return "no notes", false
+				}
+				var b strings.Builder
+				for _, n := range list {
+					b.WriteString("- " + n.Key + ": " + n.Value + "\n")
+				}
+				return b.String(), false
+			},
+		},
+	}
+}
+
+// noteScope resolves the tool's scope argument against the turn identity.
+func noteScope(ctx context.Context, scope string) (string, string) {
+	id := core.IdentityFromContext(ctx)
+	switch scope {
+	case "", "user":
+		if id.UserID == "" {
+			return "", "no user identity for this conversation"
+		}
+		return memory.UserScope(id.UserID), ""
+	case "channel":
+		if id.ChannelID == "" {
+			return "", "no channel identity for this conversation"
+		}
+		return memory.ChannelScope(id.ChannelID), ""
+	default:
+		return "", "scope must be user or channel"
+	}
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/TheLazyLemur/switchboard/internal/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoteTools_RememberThenRecallPerScope(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... remember and recall for a turn from discord:1 in discord:chan
+	ts := NoteTools(memory.NewNotes(t.TempDir()))
+	r.Len(ts, 2)
+	remember, recall := ts[0].Execute, ts[1].Execute
+	ctx := core.WithIdentity(context.Background(), core.Inbound{UserID: "discord:1", ChannelID: "discord:chan"})
+
+	// when
+	_, isErr := remember(ctx, json.RawMessage(`{"key":"style","value":"terse"}`), nil)
+	r.False(isErr)
+	_, isErr = remember(ctx, json.RawMessage(`{"scope":"channel","key":"tests","value":"make test"}`), nil)
+	r.False(isErr)
+	user, _ := recall(ctx, json.RawMessage(`{}`), nil)
+	channel, _ := recall(ctx, json.RawMessage(`{"scope":"channel"}`), nil)
+
+	// then
+	a.Equal("- style: terse\n", user)
+	a.Equal("- tests: make test\n", channel)
+}
+
+func TestNoteTools_RequiresIdentity(t *testing.T) {
+	// given
+	// ... a context without identity
+	ts := NoteTools(memory.NewNotes(t.TempDir()))
+
+	// when
+	result, isErr := ts[0].Execute(context.Background(), json.RawMessage(`{"key":"k","value":"v"}`), nil)
+
+	// then
+	assert.True(t, isErr)
+	assert.Contains(t, result, "no user identity")
+}
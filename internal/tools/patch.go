@@ -0,0 +1,396 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// maxPatchBytes caps the diff one Patch call accepts.
+const maxPatchBytes = 1 << 20
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// filePatch is one file's section of a unified diff. An empty oldPath
+// creates the file and an empty newPath deletes it.
+type filePatch struct {
+	oldPath, newPath string
+	hunks            []hunk
+}
+
+// hunk is one @@ block; lines keep their ' ', '-' or '+' prefix.
+type hunk struct {
+	oldStart, oldLines int
+	lines              []string
+	// oldNoEOL and newNoEOL record "\ No newline at end of file" after the
+	// hunk's last old or new line.
+	oldNoEOL, newNoEOL bool
+}
+
+// executePatch applies a unified diff to files under the work dir. Every
+// hunk must match its context exactly; if any fails nothing is written.
+func executePatch(input core.ToolInput, deps Deps) (string, bool) {
+	if input.Diff == "" {
+		return "missing diff argument", true
+	}
+	if len(input.Diff) > maxPatchBytes {
+		return fmt.Sprintf("diff too large: %d bytes (max %d)", len(input.Diff), maxPatchBytes), true
+	}
+	if deps.WorkDir == "" {
+		return "no working directory to patch", true
+	}
+	patches, err := parseUnifiedDiff(input.Diff)
+	if err != nil {
+		return "invalid diff: " + err.Error(), true
+	}
+
+	var changes []fileChange
+	for _, p := range patches {
+		c, err := preparePatch(p, deps)
+		if err != nil {
+			return "patch rejected, no files changed: " + err.Error(), true
+		}
+		changes = append(changes, c)
+	}
+	if err := commitChanges(changes); err != nil {
+		return "error applying patch: " + err.Error(), true
+	}
+
+	notes := make([]string, len(changes))
+	for i, c := range changes {
+		notes[i] = c.summary
+	}
+	return strings.Join(notes, "\n"), false
+}
+
+// parseUnifiedDiff splits diff into per-file patches, skipping git's
+// extended headers.
+func parseUnifiedDiff(diff string) ([]filePatch, error) {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	var patches []filePatch
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") {
+			continue
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			return nil, errors.Errorf("line %d: --- without +++", i+1)
+		}
+		p := filePatch{oldPath: diffPath(lines[i][4:]), newPath: diffPath(lines[i+1][4:])}
+		i += 2
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+			h, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			p.hunks = append(p.hunks, h)
+			i = next
+		}
+		i--
+		if len(p.hunks) == 0 {
+			return nil, errors.Errorf("no hunks for %s", p.newPath)
+		}
+		stripGitPrefixes(&p)
+		patches = append(patches, p)
+	}
+	if len(patches) == 0 {
+		return nil, errors.New("no file headers (--- / +++) found")
+	}
+	return patches, nil
+}
+
+// parseHunk reads the hunk whose header is lines[i] and returns the index
+// of the line after it.
+func parseHunk(lines []string, i int) (hunk, int, error) {
+	m := hunkHeader.FindStringSubmatch(lines[i])
+	if m == nil {
+		return hunk{}, 0, errors.Errorf("line %d: bad hunk header %q", i+1, lines[i])
+	}
+	h := hunk{oldStart: atoiOr(m[1], 0), oldLines: atoiOr(m[2], 1)}
+	oldLeft, newLeft := h.oldLines, atoiOr(m[4], 1)
+	i++
+	for ; i < len(lines) && (oldLeft > 0 || newLeft > 0 || strings.HasPrefix(lines[i], `\`)); i++ {
+		line := lines[i]
+		if line == "" {
+			// Some editors strip the space from empty context lines.
+			line = " "
+		}
+		switch line[0] {
+		case ' ':
+			oldLeft--
+			newLeft--
+		case '-':
+			oldLeft--
+		case '+':
+			newLeft--
+		case '\\':
+			if len(h.lines) > 0 {
+				prev := h.lines[len(h.lines)-1][0]
+				h.oldNoEOL = h.oldNoEOL || prev != '+'
+				h.newNoEOL = h.newNoEOL || prev != '-'
+			}
+			continue
+		default:
+			return hunk{}, 0, errors.Errorf("line %d: unexpected %q in hunk", i+1, line)
+		}
+		h.lines = append(h.lines, line)
+	}
+	if oldLeft != 0 || newLeft != 0 {
+		return hunk{}, 0, errors.Errorf("line %d: hunk is shorter than its header says", i)
+	}
+	return h, i, nil
+}
+
+func atoiOr(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// diffPath takes the path from a ---/+++ line, dropping a trailing
+// timestamp; /dev/null becomes "".
+func diffPath(s string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	if s == "/dev/null" {
+		return ""
+	}
+	return s
+}
+
+// stripGitPrefixes removes git's a/ and b/ prefixes.
+func stripGitPrefixes(p *filePatch) {
+	if (p.oldPath == "" || strings.HasPrefix(p.oldPath, "a/")) && (p.newPath == "" || strings.HasPrefix(p.newPath, "b/")) {
+		p.oldPath = strings.TrimPrefix(p.oldPath, "a/")
+		p.newPath = strings.TrimPrefix(p.newPath, "b/")
+	}
+}
+
+// fileChange is a patched file ready to be written or removed.
+type fileChange struct {
+	path    string
+	content string
+	mode    os.FileMode
+	remove  bool
+	// original is restored if a later write fails; exists is false for
+	// new files.
+	original []byte
+	exists   bool
+	summary  string
+}
+
+// preparePatch resolves p's target and computes its new content without
+// touching the disk.
+func preparePatch(p filePatch, deps Deps) (fileChange, error) {
+	name := p.newPath
+	if name == "" {
+		name = p.oldPath
+	}
+	if p.oldPath != "" && p.newPath != "" && p.oldPath != p.newPath {
+		return fileChange{}, errors.Errorf("renaming %s to %s is not supported", p.oldPath, p.newPath)
+	}
+	rel, err := artifactPath(name)
+	if err != nil {
+		return fileChange{}, errors.Errorf("%s is not a path inside the working directory", name)
+	}
+	path, err := savePath(deps, rel)
+	if err != nil {
+		return fileChange{}, err
+	}
+	c := fileChange{path: path, mode: 0o644}
+
+	var old string
+	if p.oldPath != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fileChange{}, errors.Wrapf(err, "reading %s", rel)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fileChange{}, errors.Wrapf(err, "reading %s", rel)
+		}
+		old, c.original, c.exists, c.mode = string(data), data, true, info.Mode().Perm()
+	} else if _, err := os.Lstat(path); err == nil {
+		return fileChange{}, errors.Errorf("%s already exists", rel)
+	}
+
+	content, added, removed, err := applyHunks(old, p.hunks)
+	if err != nil {
+		return fileChange{}, errors.Wrap(err, rel)
+	}
+	c.content = content
+	switch {
+	case p.newPath == "":
+		if content != "" {
+			return fileChange{}, errors.Errorf("%s: deletion leaves content behind", rel)
+		}
+		c.remove = true
+		c.summary = "deleted " + rel
+	case p.oldPath == "":
+		c.summary = fmt.Sprintf("created %s (+%d)", rel, added)
+	default:
+		c.summary = fmt.Sprintf("patched %s (+%d -%d)", rel, added, removed)
+	}
+	return c, nil
+}
+
+// applyHunks applies hunks in order to text. Each hunk's old lines must
+// appear at its stated line or, if the file has shifted, at the first
+// later match.
+func applyHunks(text string, hunks []hunk) (string, int, int, error) {
+	lines := strings.Split(text, "\n")
+	eol := strings.HasSuffix(text, "\n")
+	if eol || text == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var out []string
+	var added, removed int
+	next := 0
+	for n, h := range hunks {
+		var before, after []string
+		for _, l := range h.lines {
+			if l[0] != '+' {
+				before = append(before, l[1:])
+			}
+			if l[0] != '-' {
+				after = append(after, l[1:])
+			}
+			switch l[0] {
+			case '+':
+				added++
+			case '-':
+				removed++
+			}
+		}
+		want := h.oldStart - 1
+		if len(before) == 0 {
+			want = h.oldStart
+		}
+		at := findLines(lines, before, next, want)
+		if at < 0 {
+			return "", 0, 0, errors.Errorf("hunk %d (line %d) does not match the file", n+1, h.oldStart)
+		}
+		out = append(append(out, lines[next:at]...), after...)
+		next = at + len(before)
+		if next == len(lines) {
+			if h.newNoEOL {
+				eol = false
+			} else if h.oldNoEOL {
+				eol = true
+			}
+		}
+	}
+	out = append(out, lines[next:]...)
+	if len(out) == 0 {
+		return "", added, removed, nil
+	}
+	result := strings.Join(out, "\n")
+	if eol || text == "" && !hunks[len(hunks)-1].newNoEOL {
+		result += "\n"
+	}
+	return result, added, removed, nil
+}
+
+// findLines returns where want appears in lines at or after from,
+// preferring index at, or -1.
+func findLines(lines, want []string, from, at int) int {
+	matches := func(i int) bool {
+		if i < from || i+len(want) > len(lines) {
+			return false
+		}
+		for j, w := range want {
+			if lines[i+j] != w {
+				return false
+			}
+		}
+		return true
+	}
+	if matches(at) {
+		return at
+	}
+	for i := from; i+len(want) <= len(lines); i++ {
+		if matches(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// commitChanges writes every change to a temp file next to its target
+// and then renames them into place, restoring the originals if a rename
+// or removal fails partway.
+func commitChanges(changes []fileChange) error {
+	temps := make([]string, len(changes))
+	defer func() {
+		for _, t := range temps {
+			if t != "" {
+				os.Remove(t)
+			}
+		}
+	}()
+	for i, c := range changes {
+		if c.remove {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+			return errors.Wrap(err, "creating directory")
+		}
+		f, err := os.CreateTemp(filepath.Dir(c.path), ".patch-*")
+		if err != nil {
+			return errors.Wrap(err, "creating temp file")
+		}
+		temps[i] = f.Name()
+		_, err = f.WriteString(c.content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Chmod(f.Name(), c.mode)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", c.path)
+		}
+	}
+
+	for i, c := range changes {
+		var err error
+		if c.remove {
+			err = os.Remove(c.path)
+		} else {
+			err = os.Rename(temps[i], c.path)
+			temps[i] = ""
+		}
+		if err != nil {
+			if failed := restore(changes[:i]); len(failed) > 0 {
+				return errors.Wrapf(err, "updating %s (could not restore %s)", c.path, strings.Join(failed, "; "))
+			}
+			return errors.Wrapf(err, "updating %s", c.path)
+		}
+	}
+	return nil
+}
+
+// restore undoes changes already committed by commitChanges, returning a
+// note for each file it couldn't put back.
+func restore(changes []fileChange) []string {
+	var failed []string
+	for _, c := range changes {
+		var err error
+		if c.exists {
+			err = os.WriteFile(c.path, c.original, c.mode)
+		} else if err = os.Remove(c.path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", c.path, err))
+		}
+	}
+	return failed
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutePatch_AppliesGitDiff(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "old.txt"), []byte("bye\n"), 0o644))
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,3 +3,4 @@
+ func main() {
+-	println("hi")
++	println("hello")
++	println("world")
+ }
+--- /dev/null
++++ b/pkg/new.go
+@@ -0,0 +1,2 @@
++package pkg
++
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+	// when
+	got, isErr := Execute(context.Background(), "Patch", core.ToolInput{Diff: diff}, Deps{WorkDir: dir})
+
+	// then
+	a.False(isErr, got)
+	a.Equal("patched main.go (+2 -1)\ncreated pkg/new.go (+2)\ndeleted old.txt", got)
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	r.NoError(err)
+	a.Equal("package main\n\nfunc main() {\n\tprintln(\"hello\")\n\tprintln(\"world\")\n}\n", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "pkg", "new.go"))
+	r.NoError(err)
+	a.Equal("package pkg\n\n", string(data))
+	a.NoFileExists(filepath.Join(dir, "old.txt"))
+}
+
+func TestExecutePatch_ContextMismatchChangesNothing(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a diff whose first file applies and second doesn't
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\ntwo\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "b.txt"), []byte("three\n"), 0o644))
+	diff := "--- a.txt\n+++ a.txt\n@@ -1,2 +1,2 @@\n one\n-two\n+2\n--- b.txt\n+++ b.txt\n@@ -1 +1 @@\n-four\n+4\n"
+
+	// when
+	got, isErr := Execute(context.Background(), "Patch", core.ToolInput{Diff: diff}, Deps{WorkDir: dir})
+
+	// then
+	a.True(isErr)
+	a.Equal("patch rejected, no files changed: b.txt: hunk 1 (line 1) does not match the file", got)
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	r.NoError(err)
+	a.Equal("one\ntwo\n", string(data))
+}
+
+func TestExecutePatch_ShiftedHunkAndMissingNewline(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... the file gained a line above the hunk since the diff was made
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "f.txt"), []byte("new\na\nb"), 0o644))
+	diff := "--- f.txt\n+++ f.txt\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n"
+
+	// when
+	got, isErr := Execute(context.Background(), "Patch", core.ToolInput{Diff: diff}, Deps{WorkDir: dir})
+
+	// then
+	a.False(isErr, got)
+	data, err := os.ReadFile(filepath.Join(dir, "f.txt"))
+	r.NoError(err)
+	a.Equal("new\na\nc\n", string(data))
+}
+
+func TestExecutePatch_RejectsEscapingPaths(t *testing.T) {
+	got, isErr := Execute(context.Background(), "Patch", core.ToolInput{Diff: "--- /dev/null\n+++ ../x\n@@ -0,0 +1 @@\n+x\n"}, Deps{WorkDir: t.TempDir()})
+
+	assert.True(t, isErr)
+	assert.Contains(t, got, "../x is not a path inside the working directory")
+}
+
+func TestRestore_ReportsFilesItCouldNotPutBack(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	r.NoError(os.MkdirAll(filepath.Join(dir, "busy", "child"), 0o755))
+	changes := []fileChange{
+		{path: filepath.Join(dir, "kept.txt"), original: []byte("old\n"), mode: 0o644, exists: true},
+		{path: filepath.Join(dir, "gone", "f.txt"), original: []byte("old\n"), mode: 0o644, exists: true},
+		{path: filepath.Join(dir, "never-written.txt")},
+		{path: filepath.Join(dir, "busy")},
+	}
+
+	// when
+	failed := restore(changes)
+
+	// then
+	r.Len(failed, 2)
+	a.Contains(failed[0], filepath.Join(dir, "gone", "f.txt"))
+	a.Contains(failed[1], filepath.Join(dir, "busy"))
+	data, err := os.ReadFile(filepath.Join(dir, "kept.txt"))
+	r.NoError(err)
+	a.Equal("old\n", string(data))
+}
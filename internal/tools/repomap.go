@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	maxRepoMapFiles = 2000
+	maxRepoMapBytes = 48 * 1024
+	// maxSymbolFileBytes skips symbol extraction for generated or vendored
+	// blobs that happen to have a source extension.
+	maxSymbolFileBytes = 512 * 1024
+)
+
+// repoMapSkipDirs are never descended into.
+var repoMapSkipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true,
+	"target": true, "__pycache__": true, "venv": true,
+}
+
+// symbolPatterns are ctags-style line matchers for non-Go sources; the
+// first submatch is the symbol name.
+var symbolPatterns = map[string][]*regexp.Regexp{
+	".py": {regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)`), regexp.MustCompile(`^class\s+(\w+)`)},
+	".js": jsSymbols, ".jsx": jsSymbols, ".ts": jsSymbols, ".tsx": jsSymbols,
+	".rs":   {regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:fn|struct|enum|trait)\s+(\w+)`)},
+	".java": {regexp.MustCompile(`^\s*(?:public\s+|protected\s+)?(?:abstract\s+|final\s+)?(?:class|interface|enum|record)\s+(\w+)`)},
+	".rb":   {regexp.MustCompile(`^\s*(?:def|class|module)\s+([\w.]+)`)},
+}
+
+var jsSymbols = []*regexp.Regexp{
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(\w+)`),
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?(?:class|interface|type|enum)\s+(\w+)`),
+	regexp.MustCompile(`^export\s+const\s+(\w+)`),
+}
+
+type repoMapInput struct {
+	Path    string `json:"path"`
+	Symbols *bool  `json:"symbols"`
+}
+
+// RepoMapTool returns repo_map, which lists a directory tree with the
+// top-level symbols of each source file so the model can orient itself in
+// one call instead of repeated ls and grep.
+func RepoMapTool() core.RegisteredTool {
+	return core.RegisteredTool{
+		Def: core.ToolDef{
+			Name:        "repo_map",
+			Description: "Map a repository: every file under path with the top-level functions, types and methods it defines. Call once when starting work in an unfamiliar codebase.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":    strProp("Absolute path to the repository root or a subdirectory"),
+					"symbols": map[string]any{"type": "boolean", "default": true, "description": "Include symbols; false lists files only"},
+				},
+				"required": []string{"path"},
+			},
+		},
+		Execute: func(_ context.Context, raw json.RawMessage, _ core.Outbound) (string, bool) {
+			var input repoMapInput
+			if err := json.Unmarshal(raw, &input); err != nil {
+				return "invalid input: " + err.Error(), true
+			}
+			if input.Path == "" {
+				return "missing path argument", true
+			}
+			m, err := RepoMap(input.Path, input.Symbols == nil || *input.Symbols)
+			if err != nil {
+				return "error mapping repository: " + err.Error(), true
+			}
+			return m, false
+		},
+	}
+}
+
+// RepoMap renders one line per file under root, relative to it, followed
+// by the file's symbols when withSymbols is set. Hidden and dependency
+// directories are skipped and the output is capped.
+func RepoMap(root string, withSymbols bool) (string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", errors.Errorf("%s is not a directory", root)
+	}
+
+	var b strings.Builder
+	files, truncated := 0, false
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || repoMapSkipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || !d.Type().IsRegular() {
+			return nil
+		}
+		if files >= maxRepoMapFiles || b.Len() >= maxRepoMapBytes {
+			truncated = true
+			return filepath.SkipAll
+		}
+		files++
+
+		rel, _ := filepath.Rel(root, path)
+		line := filepath.ToSlash(rel)
+		if withSymbols {
+			if syms := fileSymbols(path); len(syms) > 0 {
+				line += ": " + strings.Join(syms, ", ")
+			}
+		}
+		b.WriteString(line + "\n")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if files == 0 {
+		return "no files", nil
+	}
+	if truncated {
+		fmt.Fprintf(&b, "[truncated after %d files; map a subdirectory for more]\n", files)
+	}
+	return b.String(), nil
+}
+
+func fileSymbols(path string) []string {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxSymbolFileBytes {
+		return nil
+	}
+	ext := filepath.Ext(path)
+	if ext == ".go" {
+		if strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		return goSymbols(path)
+	}
+	patterns := symbolPatterns[ext]
+	if patterns == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var syms []string
+	for _, line := range strings.Split(string(data), "\n") {
+		for _, re := range patterns {
+			if m := re.FindStringSubmatch(line); m != nil {
+				syms = append(syms, m[1])
+				break
+			}
+		}
+	}
+	return syms
+}
+
+// goSymbols lists a Go file's top-level funcs, methods as Recv.Name, types,
+// and exported vars and consts.
+func goSymbols(path string) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var syms []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = recvName(d.Recv.List[0].Type) + "." + name
+			}
+			syms = append(syms, name)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					syms = append(syms, "type "+s.Name.Name)
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							syms = append(syms, n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return syms
+}
+
+func recvName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(t.X)
+	case *ast.IndexExpr:
+		return recvName(t.X)
+	case *ast.IndexListExpr:
+		return recvName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoMap_ListsFilesWithSymbols(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a small repo with Go, Python, a test file and skipped dirs
+	root := t.TempDir()
+	write := func(rel, body string) {
+		path := filepath.Join(root, rel)
+		r.NoError(os.MkdirAll(filepath.Dir(path), 0o755))
+		r.NoError(os.WriteFile(path, []byte(body), 0o644))
+	}
+	write("pkg/store.go", "package pkg\n\nconst Max = 1\n\ntype Store struct{}\n\nfunc New() *Store { return nil }\n\nfunc (s *Store) Get() {}\n")
+	write("pkg/store_test.go", "package pkg\n\nfunc TestX() {}\n")
+	write("scripts/run.py", "import os\n\nclass Runner:\n    def go(self):\n        pass\n\ndef main():\n    pass\n")
+	write("node_modules/x/index.js", "function hidden() {}\n")
+	write(".git/HEAD", "ref")
+
+	// when
+	got, err := RepoMap(root, true)
+
+	// then
+	r.NoError(err)
+	a.Equal("pkg/store.go: Max, type Store, New, Store.Get\npkg/store_test.go\nscripts/run.py: Runner, main\n", got)
+}
+
+func TestRepoMap_RejectsFile(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "f.txt")
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0o644))
+
+	// when
+	_, err := RepoMap(path, true)
+
+	// then
+	assert.ErrorContains(t, err, "not a directory")
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// scriptManifest is the <name>.json file describing a script tool. The
+// executable sits next to it as <name>.
+type scriptManifest struct {
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// LoadScriptTools reads every <name>.json manifest in dir and returns a tool
+// that runs the executable <name> with the model's JSON input on stdin.
+// Stdout and stderr become the result; a non-zero exit marks it an error.
+func LoadScriptTools(dir string, env EnvPolicy) ([]core.RegisteredTool, error) {
+	manifests, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "listing script tools")
+	}
+
+	var out []core.RegisteredTool
+	for _, path := range manifests {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", path)
+		}
+		var m scriptManifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", path)
+		}
+		if m.InputSchema == nil {
+			m.InputSchema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+
+		exe := filepath.Join(dir, name)
+		info, err := os.Stat(exe)
+		if err != nil {
+			return nil, errors.Wrapf(err, "script tool %s", name)
+		}
+		if info.IsDir() || info.Mode()&0o111 == 0 {
+			return nil, errors.Errorf("script tool %s: %s is not executable", name, exe)
+		}
+
+		out = append(out, core.RegisteredTool{
+			Def:     core.ToolDef{Name: name, Description: m.Description, InputSchema: m.InputSchema},
+			Execute: scriptExecutor(exe, env),
+		})
+	}
+	return out, nil
+}
+
+func scriptExecutor(exe string, env EnvPolicy) core.ToolExecutor {
+	return func(ctx context.Context, input json.RawMessage, _ core.Outbound) (string, bool) {
+		ctx, cancel := context.WithTimeout(ctx, bashTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, exe)
+		cmd.Env = env.Filter(os.Environ())
+		cmd.Stdin = bytes.NewReader(input)
+		return runProcess(cmd)
+	}
+}
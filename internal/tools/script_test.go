@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScriptTool(t *testing.T, dir, name, manifest, script string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".json"), []byte(manifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755))
+}
+
+func TestLoadScriptTools_RunsScriptWithInputOnStdin(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a script tool that echoes its input
+	dir := t.TempDir()
+	writeScriptTool(t, dir, "echo_input",
+		`{"description":"Echo input","input_schema":{"type":"object","properties":{"ticket":{"type":"string"}}}}`,
+		"#!/bin/sh\ncat\n")
+
+	// when
+	loaded, err := LoadScriptTools(dir, EnvPolicy{})
+	r.NoError(err)
+	r.Len(loaded, 1)
+	result, isErr := loaded[0].Execute(context.Background(), json.RawMessage(`{"ticket":"OPS-1"}`), nil)
+
+	// then
+	a.Equal("echo_input", loaded[0].Def.Name)
+	a.Equal("Echo input", loaded[0].Def.Description)
+	a.False(isErr, result)
+	a.Equal(`{"ticket":"OPS-1"}`, result)
+}
+
+func TestLoadScriptTools_NonZeroExitIsError(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	writeScriptTool(t, dir, "fail", `{"description":"Fails"}`, "#!/bin/sh\necho boom >&2\nexit 3\n")
+
+	// when
+	loaded, err := LoadScriptTools(dir, EnvPolicy{})
+	r.NoError(err)
+	result, isErr := loaded[0].Execute(context.Background(), json.RawMessage(`{}`), nil)
+
+	// then
+	a.True(isErr)
+	a.Contains(result, "stderr: boom")
+	a.Contains(result, "exit status 3")
+}
+
+func TestLoadScriptTools_RejectsMissingOrNonExecutableScript(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... one manifest without a script, one with a non-executable script
+	missing := t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(missing, "ghost.json"), []byte(`{}`), 0o644))
+	plain := t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(plain, "plain.json"), []byte(`{}`), 0o644))
+	a.NoError(os.WriteFile(filepath.Join(plain, "plain"), []byte("echo"), 0o644))
+
+	// then
+	_, err := LoadScriptTools(missing, EnvPolicy{})
+	a.Error(err)
+	_, err = LoadScriptTools(plain, EnvPolicy{})
+	a.ErrorContains(err, "not executable")
+}
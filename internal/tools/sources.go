@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// searchSources is how many results of each WebSearch count as sources;
+	// the rest were likely skimmed past.
+	searchSources = 3
+	// maxSources caps the list appended to a response.
+	maxSources = 5
+)
+
+var htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Source is a web page WebSearch or Fetch returned.
+type Source struct {
+	Title string
+	URL   string
+}
+
+// Sources collects the pages a turn's WebSearch and Fetch calls returned,
+// so the answer can cite them without relying on the model to. A nil
+// *Sources records nothing.
+type Sources struct {
+	mu   sync.Mutex
+	list []Source
+}
+
+// Add records src unless its URL is already listed.
+func (s *Sources) Add(src Source) {
+	if s == nil || src.URL == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, have := range s.list {
+		if have.URL == src.URL {
+			return
+		}
+	}
+	if src.Title == "" {
+		src.Title = src.URL
+		if u, err := url.Parse(src.URL); err == nil && u.Host != "" {
+			src.Title = u.Host
+		}
+	}
+	s.list = append(s.list, src)
+}
+
+// Footer renders the sources resp doesn't already link to, first
+// maxSources only, or "" if there are none.
+func (s *Sources) Footer(resp string) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, src := range s.list {
+		if strings.Contains(resp, src.URL) {
+			continue
+		}
+		lines = append(lines, "- "+src.Title+": "+src.URL)
+		if len(lines) == maxSources {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "Sources:\n" + strings.Join(lines, "\n")
+}
+
+// pageTitle returns an HTML page's <title>, or "" for anything else.
+func pageTitle(body string) string {
+	m := htmlTitle.FindStringSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(m[1])), " ")
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_FetchRecordsSource(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><head><title>\n  Go &amp; You\n</title></head></html>"))
+	}))
+	defer srv.Close()
+	sources := &Sources{}
+	deps := Deps{Sources: sources, Egress: loopbackEgress(t, EgressConfig{})}
+
+	// when
+	Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL + "/page"}, deps)
+	Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL + "/page"}, deps)
+	Execute(context.Background(), "Fetch", core.ToolInput{URL: srv.URL + "/missing"}, deps)
+
+	// then
+	// ... the page is listed once with its title; failed fetches are not sources
+	a.Equal("Sources:\n- Go & You: "+srv.URL+"/page", sources.Footer("answer"))
+}
+
+func TestSources_FooterSkipsCitedAndCaps(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	s := &Sources{}
+	for _, u := range []string{"https://a.dev/1", "https://b.dev/2", "https://c.dev/3", "https://d.dev/4", "https://e.dev/5", "https://f.dev/6"} {
+		s.Add(Source{URL: u})
+	}
+
+	// when
+	footer := s.Footer("See https://a.dev/1 for details.")
+
+	// then
+	// ... cited pages are left out and untitled ones show their host
+	a.Equal("Sources:\n- b.dev: https://b.dev/2\n- c.dev: https://c.dev/3\n- d.dev: https://d.dev/4\n- e.dev: https://e.dev/5\n- f.dev: https://f.dev/6", footer)
+	a.Empty((*Sources)(nil).Footer("x"))
+}
@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+const (
+	maxSQLRows      = 200
+	maxSQLCellLen   = 200
+	sqlQueryTimeout = 30 * time.Second
+)
+
+// readOnlySQLKeywords are the statements sql_query will run. EXPLAIN is
+// left out because EXPLAIN ANALYZE executes its statement.
+var readOnlySQLKeywords = map[string]bool{
+	"select":   true,
+	"with":     true,
+	"show":     true,
+	"describe": true,
+	"desc":     true,
+	"values":   true,
+	"table":    true,
+}
+
+// mutatingSQLKeywords reject a WITH query that wraps a data-modifying
+// statement (Postgres allows INSERT/UPDATE/DELETE inside CTEs).
+var mutatingSQLKeywords = []string{"insert", "update", "delete", "merge", "truncate", "drop", "alter", "create", "grant", "revoke", "copy", "call", "lock", "into"}
+
+type sqlQueryInput struct {
+	Database string `json:"database"`
+	Query    string `json:"query"`
+}
+
+// SQLQueryTool returns a read-only query tool over dbs, keyed by the name
+// the model uses to pick one. Queries run in a read-only transaction that
+// is always rolled back.
+func SQLQueryTool(dbs map[string]*sql.DB) core.RegisteredTool {
+	names := make([]string, 0, len(dbs))
+	for name := range dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	def := core.ToolDef{
+		Name:        "sql_query",
+		Description: "Run a read-only SQL query (SELECT, WITH, SHOW, DESCRIBE) against a configured database. Results are returned as a markdown table. Mutating statements are rejected.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"database": map[string]any{"type": "string", "enum": names, "description": "Configured database name"},
+				"query":    map[string]any{"type": "string", "description": "A single read-only SQL statement"},
+			},
+			"required": []string{"database", "query"},
+		},
+	}
+
+	return core.RegisteredTool{
+		Def: def,
+		Execute: func(ctx context.Context, raw json.RawMessage, _ core.Outbound) (string, bool) {
+			var input sqlQueryInput
+			if err := json.Unmarshal(raw, &input); err != nil {
+				return "invalid input: " + err.Error(), true
+			}
+			db, ok := dbs[input.Database]
+			if !ok {
+				return fmt.Sprintf("unknown database %q (configured: %s)", input.Database, strings.Join(names, ", ")), true
+			}
+			if reason := checkReadOnlySQL(input.Query); reason != "" {
+				return reason, true
+			}
+			return runSQLQuery(ctx, db, input.Query)
+		},
+	}
+}
+
+// checkReadOnlySQL returns why query may not run, or "" if it is a single
+// read-only statement. Dialects disagree on backslashes in strings and on
+// "#", so the query must pass when read both the standard way and MySQL's:
+// otherwise a quote one dialect ignores could hide a statement another
+// runs.
+func checkReadOnlySQL(query string) string {
+	for _, mysql := range []bool{false, true} {
+		if reason := checkReadOnlyStatement(stripSQL(query, mysql)); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// checkReadOnlyStatement checks a query stripped by stripSQL.
+func checkReadOnlyStatement(q string) string {
+	q = strings.TrimSpace(q)
+	q = strings.TrimSpace(strings.TrimSuffix(q, ";"))
+	if q == "" {
+		return "missing query argument"
+	}
+	if strings.Contains(q, ";") {
+		return "only a single statement is allowed"
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(words) == 0 || !readOnlySQLKeywords[words[0]] {
+		return "only read-only statements (SELECT, WITH, SHOW, DESCRIBE) are allowed"
+	}
+	for _, w := range words {
+		for _, kw := range mutatingSQLKeywords {
+			if w == kw {
+				return fmt.Sprintf("query contains %s; only read-only statements are allowed", strings.ToUpper(kw))
+			}
+		}
+	}
+	return ""
+}
+
+// stripSQL drops comments from query and blanks its quoted strings and
+// identifiers, so "WHERE msg = 'delete failed'" is not rejected. It scans
+// left to right, so a quote inside a comment, or a comment marker inside a
+// string, can't hide what follows. With mysql, backslashes escape inside
+// strings and "#" starts a comment. MySQL's executable /*! */ comments are
+// kept as text, since MySQL runs them.
+func stripSQL(query string, mysql bool) string {
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case strings.HasPrefix(query[i:], "/*!"):
+			b.WriteString("/*!")
+			i += 3
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			b.WriteByte(' ')
+			i += 2 + end + 2
+		case strings.HasPrefix(query[i:], "--") || mysql && c == '#':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(query) {
+				if mysql && c != '`' && query[j] == '\\' {
+					j += 2
+					continue
+				}
+				if query[j] == c {
+					if j+1 < len(query) && query[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			b.WriteString("''")
+			i = j + 1
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// ReadOnlyDSN adds the driver's own read-only setting to dsn, so the
+// database refuses writes even if a statement gets past checkReadOnlySQL:
+// query_only for sqlite, which ignores read-only transactions, and
+// default_transaction_read_only for Postgres, which also covers anything
+// run after a COMMIT. MySQL refuses several statements in one query unless
+// the DSN sets multiStatements, so it is left as is.
+func ReadOnlyDSN(driver, dsn string) string {
+	switch driver {
+	case "sqlite":
+		return appendDSNParam(dsn, "_pragma=query_only(1)")
+	case "postgres":
+		if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+			return appendDSNParam(dsn, "default_transaction_read_only=on")
+		}
+		return dsn + " default_transaction_read_only=on"
+	}
+	return dsn
+}
+
+func appendDSNParam(dsn, param string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+	return dsn + "?" + param
+}
+
+func runSQLQuery(ctx context.Context, db *sql.DB, query string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, sqlQueryTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "error starting transaction: " + err.Error(), true
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// A prepared statement makes Postgres use the extended protocol, which
+	// refuses to run more than one statement.
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return "query error: " + err.Error(), true
+	}
+	defer stmt.Close()
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return "query error: " + err.Error(), true
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "query error: " + err.Error(), true
+	}
+
+	var table [][]string
+	truncated := false
+	for rows.Next() {
+		if len(table) == maxSQLRows {
+			truncated = true
+			break
+		}
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return "query error: " + err.Error(), true
+		}
+		row := make([]string, len(cols))
+		for i, v := range values {
+			row[i] = formatSQLValue(v)
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return "query error: " + err.Error(), true
+	}
+
+	result := markdownTable(cols, table)
+	if truncated {
+		result += fmt.Sprintf("\n(showing first %d rows)", maxSQLRows)
+	} else {
+		result += fmt.Sprintf("\n(%d rows)", len(table))
+	}
+	return truncateOutput(result, maxOutputLen), false
+}
+
+func formatSQLValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		s = "NULL"
+	case []byte:
+		s = string(v)
+	case time.Time:
+		s = v.Format(time.RFC3339)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if r := []rune(s); len(r) > maxSQLCellLen {
+		s = string(r[:maxSQLCellLen]) + "…"
+	}
+	return s
+}
+
+func markdownTable(cols []string, rows [][]string) string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			c = strings.ReplaceAll(c, "|", `\|`)
+			c = strings.ReplaceAll(c, "\n", " ")
+			b.WriteString(" " + c + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(cols)
+	b.WriteString("|")
+	for range cols {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, r := range rows {
+		writeRow(r)
+	}
+	return b.String()
+}
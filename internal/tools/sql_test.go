@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newSQLTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER, name TEXT, note TEXT);
+		INSERT INTO users VALUES (1, 'ada', 'a|b'), (2, 'bob', NULL);`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestSQLQueryTool_FormatsMarkdownTable(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	tool := SQLQueryTool(map[string]*sql.DB{"app": newSQLTestDB(t)})
+
+	// when
+	result, isErr := tool.Execute(context.Background(), json.RawMessage(`{"database":"app","query":"SELECT id, name, note FROM users ORDER BY id"}`), nil)
+
+	// then
+	a.False(isErr, result)
+	a.Equal("| id | name | note |\n| --- | --- | --- |\n| 1 | ada | a\\|b |\n| 2 | bob | NULL |\n\n(2 rows)", result)
+}
+
+func TestSQLQueryTool_RejectsMutatingStatements(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	db := newSQLTestDB(t)
+	tool := SQLQueryTool(map[string]*sql.DB{"app": db})
+
+	// when
+	queries := []string{
+		"DELETE FROM users",
+		"SELECT 1; DROP TABLE users",
+		"WITH gone AS (DELETE FROM users RETURNING id) SELECT * FROM gone",
+		"EXPLAIN ANALYZE DELETE FROM users",
+	}
+	for _, q := range queries {
+		input, _ := json.Marshal(sqlQueryInput{Database: "app", Query: q})
+		_, isErr := tool.Execute(context.Background(), input, nil)
+		a.True(isErr, q)
+	}
+
+	// then
+	var n int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM users").Scan(&n))
+	a.Equal(2, n)
+}
+
+func TestCheckReadOnlySQL_IgnoresKeywordsInLiterals(t *testing.T) {
+	assert.Empty(t, checkReadOnlySQL("SELECT * FROM logs WHERE msg = 'delete failed; retry'"))
+	assert.Empty(t, checkReadOnlySQL("SELECT 1 -- drop it later\n"))
+	assert.Empty(t, checkReadOnlySQL("SELECT 1 /* ; */ FROM t"))
+}
+
+func TestCheckReadOnlySQL_QuotesCannotHideStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"quote in block comment", "SELECT 1 /* ' */; COMMIT; DROP TABLE users; /* ' */"},
+		{"quote in line comment", "SELECT 1 -- '\n; DELETE FROM users; -- '"},
+		{"comment marker in string", "SELECT '--' ; DROP TABLE users"},
+		{"backslash-escaped quote", `SELECT '\'' ; DROP TABLE users; -- '`},
+		{"backslash before closing quote", `SELECT 'a\' ; DROP TABLE users; --'`},
+		{"hash comment", "SELECT 1 # '\n; DROP TABLE users; -- '"},
+		{"mysql executable comment", "SELECT 1 /*! ; DROP TABLE users */"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEmpty(t, checkReadOnlySQL(tt.query))
+		})
+	}
+}
+
+func TestSQLQueryTool_CommentTricksLeaveTableIntact(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	db := newSQLTestDB(t)
+	tool := SQLQueryTool(map[string]*sql.DB{"app": db})
+
+	// when
+	for _, q := range []string{
+		"SELECT 1 /* ' */; COMMIT; DROP TABLE users; /* ' */",
+		"SELECT 1 -- '\n; DELETE FROM users; -- '",
+	} {
+		input, _ := json.Marshal(sqlQueryInput{Database: "app", Query: q})
+		_, isErr := tool.Execute(context.Background(), input, nil)
+		a.True(isErr, q)
+	}
+
+	// then
+	var n int
+	r.NoError(db.QueryRow("SELECT count(*) FROM users").Scan(&n))
+	a.Equal(2, n)
+}
+
+func TestRunSQLQuery_ReadOnlyDSNRefusesSQLiteWrites(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a statement that skipped checkReadOnlySQL
+	path := filepath.Join(t.TempDir(), "test.db")
+	rw, err := sql.Open("sqlite", path)
+	r.NoError(err)
+	_, err = rw.Exec("CREATE TABLE users (id INTEGER); INSERT INTO users VALUES (1)")
+	r.NoError(err)
+	r.NoError(rw.Close())
+	db, err := sql.Open("sqlite", ReadOnlyDSN("sqlite", path))
+	r.NoError(err)
+	defer db.Close()
+
+	// when
+	result, isErr := runSQLQuery(context.Background(), db, "DELETE FROM users RETURNING id")
+
+	// then
+	a.True(isErr, result)
+	var n int
+	r.NoError(db.QueryRow("SELECT count(*) FROM users").Scan(&n))
+	a.Equal(1, n)
+}
+
+func TestReadOnlyDSN(t *testing.T) {
+	tests := []struct {
+		driver, dsn, want string
+	}{
+		{"sqlite", "/data/app.db", "/data/app.db?_pragma=query_only(1)"},
+		{"sqlite", "file:app.db?mode=ro", "file:app.db?mode=ro&_pragma=query_only(1)"},
+		{"postgres", "postgres://u:p@db/app?sslmode=disable", "postgres://u:p@db/app?sslmode=disable&default_transaction_read_only=on"},
+		{"postgres", "host=db dbname=app", "host=db dbname=app default_transaction_read_only=on"},
+		{"mysql", "u:p@tcp(db)/app", "u:p@tcp(db)/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver+" "+tt.dsn, func(t *testing.T) {
+			assert.Equal(t, tt.want, ReadOnlyDSN(tt.driver, tt.dsn))
+		})
+	}
+}
+
+func TestSQLQueryTool_UnknownDatabase(t *testing.T) {
+	tool := SQLQueryTool(map[string]*sql.DB{"app": newSQLTestDB(t)})
+
+	result, isErr := tool.Execute(context.Background(), json.RawMessage(`{"database":"prod","query":"SELECT 1"}`), nil)
+
+	assert.True(t, isErr)
+	assert.Contains(t, result, "configured: app")
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is read from a session's working directory when the
+// session starts.
+const ProjectConfigFile = ".switchboard.yaml"
+
+// maxVerifyOutput caps each verify command's output fed back to the model.
+const maxVerifyOutput = 8000
+
+// ProjectConfig is the per-workdir ProjectConfigFile.
+type ProjectConfig struct {
+	Verify VerifyConfig `yaml:"verify"`
+}
+
+// VerifyConfig lists commands run after a batch of tool calls edits files,
+// e.g. go build ./... and go test ./....
+type VerifyConfig struct {
+	Commands []string `yaml:"commands"`
+	// After names the tools that trigger verification besides Patch and a
+	// saving write_artifact, e.g. Bash or a script tool that edits files.
+	After []string `yaml:"after"`
+}
+
+// LoadProjectConfig reads ProjectConfigFile from workDir. A missing file
+// is an empty config.
+func LoadProjectConfig(workDir string) (ProjectConfig, error) {
+	var cfg ProjectConfig
+	if workDir == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(filepath.Join(workDir, ProjectConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, errors.Wrap(err, "reading project config")
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, errors.Wrapf(err, "parsing %s", ProjectConfigFile)
+	}
+	return cfg, nil
+}
+
+// VerifyTrusted reports whether workDir lies in one of trusted, the
+// directories whose ProjectConfigFile the operator lets run commands. A
+// cloned repository's config is otherwise a way to run code on the host.
+func VerifyTrusted(workDir string, trusted []string) bool {
+	if workDir == "" {
+		return false
+	}
+	real, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return false
+	}
+	for _, dir := range trusted {
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if real == root || strings.HasPrefix(real, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Triggered reports whether a call to name should be followed by the
+// verify commands.
+func (v VerifyConfig) Triggered(name string, input core.ToolInput) bool {
+	if len(v.Commands) == 0 {
+		return false
+	}
+	if name == "write_artifact" && input.Save || name == "Patch" {
+		return true
+	}
+	for _, after := range v.After {
+		if after == name {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyResult is one verify command's outcome.
+type VerifyResult struct {
+	Command string
+	Output  string
+	Failed  bool
+}
+
+// RunVerify runs commands in order in deps.WorkDir with Bash's environment
+// and timeout. Every command runs even after a failure, so the model sees
+// build and test results together.
+func RunVerify(ctx context.Context, deps Deps, commands []string) []VerifyResult {
+	results := make([]VerifyResult, 0, len(commands))
+	for _, command := range commands {
+		cmdCtx, cancel := context.WithTimeout(ctx, bashTimeout)
+		cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
+		cmd.Dir = deps.WorkDir
+		cmd.Env = append(deps.Env.Filter(os.Environ()), sessionEnviron(deps.SessionEnv)...)
+		out, failed := runProcess(cmd)
+		cancel()
+		results = append(results, VerifyResult{Command: command, Output: CompactOutput(out, maxVerifyOutput), Failed: failed})
+	}
+	return results
+}
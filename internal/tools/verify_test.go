@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProjectConfig(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(`
+verify:
+  commands:
+    - go build ./...
+    - go test ./...
+  after: [Bash]
+`), 0o644))
+
+	// when
+	cfg, err := LoadProjectConfig(dir)
+	missing, missingErr := LoadProjectConfig(t.TempDir())
+
+	// then
+	r.NoError(err)
+	a.Equal([]string{"go build ./...", "go test ./..."}, cfg.Verify.Commands)
+	a.True(cfg.Verify.Triggered("Bash", core.ToolInput{Command: "sed -i s/a/b/ x.go"}))
+	a.True(cfg.Verify.Triggered("write_artifact", core.ToolInput{Save: true}))
+	a.False(cfg.Verify.Triggered("write_artifact", core.ToolInput{}))
+	a.True(cfg.Verify.Triggered("Patch", core.ToolInput{Diff: "--- a\n"}))
+	a.False(cfg.Verify.Triggered("Read", core.ToolInput{}))
+	r.NoError(missingErr)
+	a.False(missing.Verify.Triggered("write_artifact", core.ToolInput{Save: true}))
+}
+
+func TestLoadProjectConfig_InvalidYAML(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte("verify: [\n"), 0o644))
+
+	// when
+	_, err := LoadProjectConfig(dir)
+
+	// then
+	assert.ErrorContains(t, err, "parsing "+ProjectConfigFile)
+}
+
+func TestRunVerify_RunsEveryCommandInWorkDir(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "marker"), []byte("here"), 0o644))
+
+	// when
+	results := RunVerify(context.Background(), Deps{WorkDir: dir}, []string{"exit 2", "cat marker"})
+
+	// then
+	// ... the failure doesn't stop the second command
+	r.Len(results, 2)
+	a.True(results[0].Failed)
+	a.Contains(results[0].Output, "exit status 2")
+	a.False(results[1].Failed)
+	a.Equal("here", results[1].Output)
+}
+
+func TestVerifyTrusted(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	trusted := t.TempDir()
+	repo := filepath.Join(trusted, "repo")
+	require.NoError(t, os.Mkdir(repo, 0o755))
+	clones := t.TempDir()
+
+	// then
+	a.True(VerifyTrusted(repo, []string{trusted}))
+	a.True(VerifyTrusted(trusted, []string{trusted}))
+	a.False(VerifyTrusted(clones, []string{trusted}))
+	a.False(VerifyTrusted(repo, nil))
+	a.False(VerifyTrusted("", []string{trusted}))
+}
@@ -0,0 +1,106 @@
+// Package tts renders responses as speech for /speak.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultBaseURL = "https://api.openai.com"
+	DefaultModel   = "tts-1"
+	DefaultVoice   = "alloy"
+)
+
+// maxInputRunes is the OpenAI speech endpoint's input limit. Longer
+// responses are cut at the last sentence that fits.
+const maxInputRunes = 4096
+
+var _ core.Speaker = (*OpenAI)(nil)
+
+// OpenAI speaks through an OpenAI-compatible /v1/audio/speech endpoint,
+// which local servers (e.g. openedai-speech, Kokoro) also expose. Audio
+// is requested as Ogg Opus, the format WhatsApp voice notes use.
+type OpenAI struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Voice   string
+	Client  *http.Client
+}
+
+func NewOpenAI(baseURL, apiKey, model, voice string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	if voice == "" {
+		voice = DefaultVoice
+	}
+	return &OpenAI{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+		Voice:   voice,
+		Client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (o *OpenAI) Speak(ctx context.Context, text string) ([]byte, string, error) {
+	body, err := json.Marshal(map[string]string{
+		"model":           o.Model,
+		"voice":           o.Voice,
+		"input":           clip(text, maxInputRunes),
+		"response_format": "opus",
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "encoding speech request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+"/v1/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "creating speech request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "requesting speech")
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "reading speech")
+	}
+	if resp.StatusCode >= 400 {
+		return nil, "", errors.Errorf("speech endpoint returned %d: %s", resp.StatusCode, clip(string(audio), 200))
+	}
+	return audio, "audio/ogg; codecs=opus", nil
+}
+
+// clip shortens s to at most n runes, preferring to end after a sentence.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	cut := string(r[:n])
+	if i := strings.LastIndexAny(cut, ".!?\n"); i > len(cut)/2 {
+		return cut[:i+1]
+	}
+	return cut
+}
@@ -0,0 +1,62 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAI_Speak(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... a speech endpoint that records the request
+	var got map[string]string
+	var auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, auth = req.URL.Path, req.Header.Get("Authorization")
+		_ = json.NewDecoder(req.Body).Decode(&got)
+		_, _ = w.Write([]byte("OggS"))
+	}))
+	defer srv.Close()
+	o := NewOpenAI(srv.URL+"/", "sk-tts", "", "nova")
+
+	// when
+	audio, mimeType, err := o.Speak(context.Background(), "Deployed.")
+
+	// then
+	r.NoError(err)
+	a.Equal("OggS", string(audio))
+	a.Equal("audio/ogg; codecs=opus", mimeType)
+	a.Equal("/v1/audio/speech", path)
+	a.Equal("Bearer sk-tts", auth)
+	a.Equal(map[string]string{"model": DefaultModel, "voice": "nova", "input": "Deployed.", "response_format": "opus"}, got)
+}
+
+func TestOpenAI_SpeakErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "bad voice", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	_, _, err := NewOpenAI(srv.URL, "", "", "").Speak(context.Background(), "hi")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+	assert.Contains(t, err.Error(), "bad voice")
+}
+
+func TestClip_PrefersSentenceBoundary(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("short", clip("short", 10))
+	a.Equal("First one. Second one.", clip("First one. Second one. Third one is long", 30))
+	a.Equal(strings.Repeat("x", 10), clip(strings.Repeat("x", 20), 10))
+}
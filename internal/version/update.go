@@ -0,0 +1,166 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultReleasesURL is the GitHub API endpoint for the latest release.
+const DefaultReleasesURL = "https://api.github.com/repos/TheLazyLemur/switchboard/releases/latest"
+
+// Level is how far ahead a release must be before Checker reports it.
+type Level int
+
+const (
+	LevelOff Level = iota
+	LevelPatch
+	LevelMinor
+	LevelMajor
+)
+
+var levels = map[string]Level{"off": LevelOff, "patch": LevelPatch, "minor": LevelMinor, "major": LevelMajor}
+
+// ParseLevel reads "off", "patch", "minor" or "major".
+func ParseLevel(s string) (Level, error) {
+	l, ok := levels[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, errors.Errorf("update check %q must be off, patch, minor or major", s)
+	}
+	return l, nil
+}
+
+// Checker polls for the latest release and remembers it when the running
+// version is behind by at least its Level. It implements core.Releases.
+type Checker struct {
+	url     string
+	level   Level
+	current string
+	client  *http.Client
+
+	mu     sync.Mutex
+	latest string
+}
+
+// NewChecker checks url, a GitHub "latest release" endpoint, against the
+// running Version.
+func NewChecker(url string, level Level) *Checker {
+	return &Checker{url: url, level: level, current: Version, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Run checks now and then every interval until ctx is done, logging a
+// warning whenever a newer release than the last one reported turns up.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	if c.level == LevelOff {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		before, _ := c.Newer()
+		if err := c.Check(ctx); err != nil {
+			slog.Debug("checking for a newer release", "error", err)
+		} else if latest, ok := c.Newer(); ok && latest != before {
+			slog.Warn("a newer switchboard release is available", "running", c.current, "latest", latest)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Check fetches the latest release once. Builds without a release version
+// (such as "dev") are never reported as behind.
+func (c *Checker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return errors.Wrap(err, "building release request")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "switchboard/"+c.current)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "fetching latest release")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("fetching latest release: %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return errors.Wrap(err, "decoding latest release")
+	}
+	if c.level == LevelOff || behind(c.current, release.TagName) < c.level {
+		return nil
+	}
+	c.mu.Lock()
+	c.latest = release.TagName
+	c.mu.Unlock()
+	return nil
+}
+
+// Current describes the running build.
+func (c *Checker) Current() string {
+	return Get().String()
+}
+
+// Newer returns the release the last check found the running version to
+// be behind.
+func (c *Checker) Newer() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest, c.latest != ""
+}
+
+// behind reports how far latest is ahead of current: LevelMajor if its
+// major version is higher, and so on, or LevelOff if it isn't ahead or
+// either isn't a version.
+func behind(current, latest string) Level {
+	cur, ok1 := parseSemver(current)
+	lat, ok2 := parseSemver(latest)
+	if !ok1 || !ok2 {
+		return LevelOff
+	}
+	for i, l := range []Level{LevelMajor, LevelMinor, LevelPatch} {
+		if lat[i] != cur[i] {
+			if lat[i] > cur[i] {
+				return l
+			}
+			return LevelOff
+		}
+	}
+	return LevelOff
+}
+
+// parseSemver reads "v1.2.3", ignoring any pre-release or build suffix.
+// A missing patch number counts as 0.
+func parseSemver(s string) ([3]int, bool) {
+	var v [3]int
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
@@ -0,0 +1,69 @@
+// Package version reports which build of switchboard is running and
+// whether a newer release is out. Release builds set Version, Commit and
+// Date with -ldflags "-X"; other builds fall back to the VCS stamp go
+// build records.
+package version
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Set at link time, e.g.
+//
+//	go build -ldflags "-X github.com/TheLazyLemur/switchboard/internal/version.Version=v1.2.3"
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes a build.
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	Date    string `json:"date,omitempty"`
+}
+
+// Get returns the running build's Info.
+func Get() Info {
+	i := Info{Version: Version, Commit: Commit, Date: Date}
+	if i.Commit != "" && i.Date != "" {
+		return i
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return i
+	}
+	for _, s := range bi.Settings {
+		switch {
+		case s.Key == "vcs.revision" && i.Commit == "":
+			i.Commit = s.Value
+		case s.Key == "vcs.time" && i.Date == "":
+			i.Date = s.Value
+		}
+	}
+	return i
+}
+
+// String renders i as "v1.2.3 (commit abc1234, built 2026-10-01T12:00:00Z)".
+func (i Info) String() string {
+	var extra []string
+	if i.Commit != "" {
+		extra = append(extra, "commit "+shortCommit(i.Commit))
+	}
+	if i.Date != "" {
+		extra = append(extra, "built "+i.Date)
+	}
+	if len(extra) == 0 {
+		return i.Version
+	}
+	return i.Version + " (" + strings.Join(extra, ", ") + ")"
+}
+
+func shortCommit(c string) string {
+	if len(c) > 7 {
+		return c[:7]
+	}
+	return c
+}
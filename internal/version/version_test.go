@@ -0,0 +1,78 @@
+package version
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfo_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("v1.2.3 (commit 0123456, built 2026-10-01)", Info{Version: "v1.2.3", Commit: "0123456789abcdef", Date: "2026-10-01"}.String())
+	a.Equal("dev", Info{Version: "dev"}.String())
+}
+
+func TestBehind(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            Level
+	}{
+		{"v1.2.3", "v2.0.0", LevelMajor},
+		{"v1.2.3", "v1.4.0", LevelMinor},
+		{"v1.2.3", "v1.2.9", LevelPatch},
+		{"v1.2.3", "v1.2.3", LevelOff},
+		{"v1.4.0", "v1.3.9", LevelOff},
+		{"v1.2.3-rc.1", "v1.3", LevelMinor},
+		{"dev", "v9.0.0", LevelOff},
+		{"v1.2.3", "nightly", LevelOff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.latest, func(t *testing.T) {
+			assert.Equal(t, tt.want, behind(tt.current, tt.latest))
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	a := assert.New(t)
+
+	l, err := ParseLevel("Minor")
+	a.NoError(err)
+	a.Equal(LevelMinor, l)
+	_, err = ParseLevel("sometimes")
+	a.ErrorContains(err, "off, patch, minor or major")
+}
+
+func TestChecker_Check(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v1.3.0"}`))
+	}))
+	defer server.Close()
+	checker := func(level Level) *Checker {
+		c := NewChecker(server.URL, level)
+		c.current = "v1.2.5"
+		return c
+	}
+
+	// when
+	minor, major := checker(LevelMinor), checker(LevelMajor)
+	r.NoError(minor.Check(context.Background()))
+	r.NoError(major.Check(context.Background()))
+
+	// then
+	// ... a minor release ahead is reported at "minor" but not at "major"
+	latest, ok := minor.Newer()
+	a.True(ok)
+	a.Equal("v1.3.0", latest)
+	_, ok = major.Newer()
+	a.False(ok)
+}
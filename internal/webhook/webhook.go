@@ -0,0 +1,202 @@
+// Package webhook posts the bot's output to an HTTP endpoint as JSON, so
+// systems without a platform plugin can follow every conversation. Each
+// body is signed with HMAC-SHA256 when a secret is configured.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body,
+// keyed with the secret.
+const SignatureHeader = "X-Switchboard-Signature"
+
+// Event kinds.
+const (
+	EventTyping     = "typing"
+	EventResponse   = "response"
+	EventUpdate     = "update"
+	EventReaction   = "reaction"
+	EventToolDenied = "tool_denied"
+)
+
+// Event is one POST body.
+type Event struct {
+	Event      string    `json:"event"`
+	SessionKey string    `json:"session_key"`
+	ChannelID  string    `json:"channel_id,omitempty"`
+	UserID     string    `json:"user_id,omitempty"`
+	Text       string    `json:"text,omitempty"`
+	Emoji      string    `json:"emoji,omitempty"`
+	Tool       string    `json:"tool,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+const (
+	// queueSize is how many events wait for delivery before new ones are
+	// dropped, so a slow endpoint never holds up a turn.
+	queueSize = 256
+	// maxAttempts covers network errors and 5xx answers; 4xx answers are
+	// not retried.
+	maxAttempts = 3
+)
+
+var _ core.Mirror = (*Sender)(nil)
+
+// Sender delivers events to one URL in the order they were sent. It
+// implements core.Mirror.
+type Sender struct {
+	url        string
+	secret     string
+	client     *http.Client
+	queue      chan Event
+	retryDelay time.Duration
+	now        func() time.Time
+}
+
+// New returns a Sender for url. An empty secret leaves bodies unsigned.
+// Nothing is delivered until Run.
+func New(url, secret string) *Sender {
+	return &Sender{
+		url:        url,
+		secret:     secret,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		queue:      make(chan Event, queueSize),
+		retryDelay: time.Second,
+		now:        time.Now,
+	}
+}
+
+// Outbound returns the Responder for in's replies.
+func (s *Sender) Outbound(in core.Inbound) core.Outbound {
+	return &Responder{sender: s, key: string(in.SessionKey), channelID: in.ChannelID, userID: in.UserID}
+}
+
+// Run delivers queued events until ctx is done.
+func (s *Sender) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.queue:
+			if err := s.deliver(ctx, e); err != nil {
+				slog.Warn("webhook delivery failed", "event", e.Event, "key", e.SessionKey, "error", err)
+			}
+		}
+	}
+}
+
+func (s *Sender) enqueue(e Event) {
+	e.Time = s.now().UTC()
+	select {
+	case s.queue <- e:
+	default:
+		slog.Warn("webhook queue full, dropping event", "event", e.Event, "key", e.SessionKey)
+	}
+}
+
+func (s *Sender) deliver(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "encoding event")
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, body)
+		if err == nil || !retry || attempt == maxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * s.retryDelay):
+		}
+	}
+}
+
+// post sends body once and reports whether a failure is worth retrying.
+func (s *Sender) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "building request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "posting event")
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500, errors.Errorf("posting event: %s", resp.Status)
+	}
+	return false, nil
+}
+
+// Sign returns the SignatureHeader value for body. Receivers recompute it
+// and compare with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Responder implements core.Outbound and core.DenialReporter for one
+// inbound by queueing events on its Sender. It never fails.
+type Responder struct {
+	sender    *Sender
+	key       string
+	channelID string
+	userID    string
+}
+
+func (r *Responder) event(kind string) Event {
+	return Event{Event: kind, SessionKey: r.key, ChannelID: r.channelID, UserID: r.userID}
+}
+
+func (r *Responder) SendTyping() error {
+	r.sender.enqueue(r.event(EventTyping))
+	return nil
+}
+
+func (r *Responder) PostResponse(content string) error {
+	e := r.event(EventResponse)
+	e.Text = content
+	r.sender.enqueue(e)
+	return nil
+}
+
+func (r *Responder) AddReaction(emoji string) error {
+	e := r.event(EventReaction)
+	e.Emoji = emoji
+	r.sender.enqueue(e)
+	return nil
+}
+
+func (r *Responder) SendUpdate(message string) error {
+	e := r.event(EventUpdate)
+	e.Text = message
+	r.sender.enqueue(e)
+	return nil
+}
+
+// ToolDenied reports a tool call the permission checker refused.
+func (r *Responder) ToolDenied(toolName, reason string) {
+	e := r.event(EventToolDenied)
+	e.Tool = toolName
+	e.Reason = reason
+	r.sender.enqueue(e)
+}
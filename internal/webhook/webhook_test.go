@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the requests posted to it, answering the first
+// failures of them with a 503.
+type receiver struct {
+	mu         sync.Mutex
+	failures   int
+	attempts   int
+	bodies     [][]byte
+	signatures []string
+	got        chan struct{}
+}
+
+func newReceiver(failures int) (*receiver, *httptest.Server) {
+	rc := &receiver{failures: failures, got: make(chan struct{}, 16)}
+	return rc, httptest.NewServer(rc)
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.attempts++
+	if rc.failures > 0 {
+		rc.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rc.bodies = append(rc.bodies, body)
+	rc.signatures = append(rc.signatures, r.Header.Get(SignatureHeader))
+	rc.got <- struct{}{}
+}
+
+func (rc *receiver) wait(t *testing.T, n int) []Event {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rc.got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of %d events", i, n)
+		}
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	events := make([]Event, len(rc.bodies))
+	for i, b := range rc.bodies {
+		require.NoError(t, json.Unmarshal(b, &events[i]))
+	}
+	return events
+}
+
+func TestSender_PostsSignedEventsInOrder(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	rc, srv := newReceiver(0)
+	defer srv.Close()
+	s := New(srv.URL, "whsec")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	out := s.Outbound(core.Inbound{SessionKey: "discord:1", ChannelID: "discord:1", UserID: "discord:9"})
+
+	// when
+	r.NoError(out.SendUpdate("reading files"))
+	out.(core.DenialReporter).ToolDenied("Bash", "outside allowed dirs")
+	r.NoError(out.PostResponse("done"))
+	events := rc.wait(t, 3)
+
+	// then
+	a.Equal(EventUpdate, events[0].Event)
+	a.Equal("reading files", events[0].Text)
+	a.Equal("discord:9", events[0].UserID)
+	a.Equal(EventToolDenied, events[1].Event)
+	a.Equal("Bash", events[1].Tool)
+	a.Equal("outside allowed dirs", events[1].Reason)
+	a.Equal(EventResponse, events[2].Event)
+	a.Equal("discord:1", events[2].SessionKey)
+	a.False(events[2].Time.IsZero())
+	// ... each signature is the HMAC of its own body
+	for i, body := range rc.bodies {
+		a.Equal(Sign("whsec", body), rc.signatures[i])
+	}
+}
+
+func TestSender_RetriesServerErrors(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	// ... an endpoint that is down for two requests
+	rc, srv := newReceiver(2)
+	defer srv.Close()
+	s := New(srv.URL, "")
+	s.retryDelay = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// when
+	_ = s.Outbound(core.Inbound{SessionKey: "k"}).PostResponse("hi")
+	events := rc.wait(t, 1)
+
+	// then
+	// ... the third attempt lands, unsigned without a secret
+	a.Equal("hi", events[0].Text)
+	a.Equal(3, rc.attempts)
+	a.Empty(rc.signatures[0])
+}
+
+func TestSign(t *testing.T) {
+	a := assert.New(t)
+
+	// HMAC-SHA256 of "{}" keyed with "secret"
+	a.Equal("sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13", Sign("secret", []byte("{}")))
+}
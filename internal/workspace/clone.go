@@ -0,0 +1,172 @@
+// Package workspace bootstraps working directories for sessions.
+package workspace
+
+import (
+	"context"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// cloneTimeout bounds one git clone.
+const cloneTimeout = 5 * time.Minute
+
+// sizePollInterval is how often a running clone's size is checked.
+const sizePollInterval = 500 * time.Millisecond
+
+var repoName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Cloner shallow-clones https repositories from allowed hosts into Root,
+// one directory per repository at Root/<host>/<owner>/<repo>.
+type Cloner struct {
+	Root     string
+	Hosts    []string
+	MaxBytes int64
+	// Env is the environment git runs with, already filtered of secrets.
+	Env []string
+}
+
+// Clone clones rawURL into Root/<host>/<owner>/<repo>. If that directory
+// already holds a clone of the same repository it is returned untouched
+// with fresh false, so /clone doubles as "switch to".
+func (c *Cloner) Clone(ctx context.Context, rawURL string) (string, bool, error) {
+	name, err := c.check(rawURL)
+	if err != nil {
+		return "", false, err
+	}
+	dest := filepath.Join(c.Root, name)
+	if _, err := os.Stat(dest); err == nil {
+		if err := checkOrigin(ctx, dest, rawURL); err != nil {
+			return "", false, err
+		}
+		return dest, false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", false, errors.Wrap(err, "creating clone dir")
+	}
+
+	tmp, err := os.MkdirTemp(c.Root, ".clone-")
+	if err != nil {
+		return "", false, errors.Wrap(err, "creating clone dir")
+	}
+	defer os.RemoveAll(tmp)
+
+	ctx, cancel := context.WithTimeout(ctx, cloneTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--single-branch", "--", rawURL, tmp)
+	env := c.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	// Never block on a credential prompt: private repos just fail.
+	cmd.Env = append(slices.Clip(env), "GIT_TERMINAL_PROMPT=0")
+
+	tooBig := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(sizePollInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				if c.MaxBytes > 0 && dirSize(tmp) > c.MaxBytes {
+					close(tooBig)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	out, err := cmd.CombinedOutput()
+	close(done)
+
+	select {
+	case <-tooBig:
+		return "", false, errors.Errorf("repository is larger than %d MB", c.MaxBytes>>20)
+	default:
+	}
+	if err != nil {
+		return "", false, errors.Errorf("git clone failed: %s", strings.TrimSpace(string(out)))
+	}
+	if c.MaxBytes > 0 && dirSize(tmp) > c.MaxBytes {
+		return "", false, errors.Errorf("repository is larger than %d MB", c.MaxBytes>>20)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return "", false, errors.Wrap(err, "moving clone into place")
+	}
+	return dest, true, nil
+}
+
+// checkOrigin makes sure the clone in dir came from rawURL, so a
+// repository is never mistaken for another one with the same path.
+func checkOrigin(ctx context.Context, dir, rawURL string) error {
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return errors.Errorf("%s exists but is not a clone", dir)
+	}
+	if origin := strings.TrimSpace(string(out)); normalizeRepoURL(origin) != normalizeRepoURL(rawURL) {
+		return errors.Errorf("%s already holds a clone of %s", dir, origin)
+	}
+	return nil
+}
+
+// normalizeRepoURL drops what doesn't change which repository a URL names.
+func normalizeRepoURL(rawURL string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(rawURL, "/"), ".git"))
+}
+
+// check validates rawURL and returns the path under Root to clone into,
+// <host>/<owner>/<repo>.
+func (c *Cloner) check(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", errors.New("only https:// git URLs can be cloned")
+	}
+	if u.User != nil {
+		return "", errors.New("URLs with credentials are not allowed")
+	}
+	if !c.hostAllowed(u.Hostname()) {
+		return "", errors.Errorf("host %s is not in the clone allowlist", u.Hostname())
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	parts[len(parts)-1] = strings.TrimSuffix(parts[len(parts)-1], ".git")
+	for _, part := range parts {
+		if !repoName.MatchString(part) {
+			return "", errors.Errorf("can't tell the repository name from %q", rawURL)
+		}
+	}
+	return filepath.Join(append([]string{strings.ToLower(u.Hostname())}, parts...)...), nil
+}
+
+func (c *Cloner) hostAllowed(host string) bool {
+	for _, h := range c.Hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,99 @@
+// Package console is a minimal platform plugin and the reference for
+// writing others: it reads prompts from stdin and prints replies to
+// stdout. Link it with `go build -tags console ./cmd/switchboard`.
+package console
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/TheLazyLemur/switchboard/pkg/platform"
+)
+
+func init() {
+	platform.Register(New(os.Stdin, os.Stdout))
+}
+
+// Plugin delivers each line of its input as one message from a single
+// user.
+type Plugin struct {
+	in     io.Reader
+	out    io.Writer
+	mu     sync.Mutex
+	user   string
+	cancel context.CancelFunc
+}
+
+func New(in io.Reader, out io.Writer) *Plugin {
+	return &Plugin{in: in, out: out}
+}
+
+func (p *Plugin) ID() string { return "console" }
+
+func (p *Plugin) Capabilities() platform.Capabilities {
+	return platform.Capabilities{Updates: true}
+}
+
+// Init reads PLUGIN_CONSOLE_USER, the name the operator is identified by
+// (default "operator").
+func (p *Plugin) Init(settings map[string]string) error {
+	p.user = settings["USER"]
+	if p.user == "" {
+		p.user = "operator"
+	}
+	return nil
+}
+
+func (p *Plugin) Start(ctx context.Context, deliver func(platform.Inbound)) error {
+	ctx, p.cancel = context.WithCancel(ctx)
+	lines := bufio.NewScanner(p.in)
+	go func() {
+		for lines.Scan() {
+			if ctx.Err() != nil {
+				return
+			}
+			text := strings.TrimSpace(lines.Text())
+			if text == "" {
+				continue
+			}
+			deliver(platform.Inbound{
+				SessionKey:   "console",
+				Text:         text,
+				UserID:       "console:" + p.user,
+				ChannelID:    "console",
+				Reply:        &outbound{p: p},
+				Capabilities: p.Capabilities(),
+			})
+		}
+	}()
+	return nil
+}
+
+// Stop stops reading further lines; a line already being handled finishes.
+func (p *Plugin) Stop() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+func (p *Plugin) print(format string, args ...any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.out, format, args...)
+	return err
+}
+
+type outbound struct {
+	p *Plugin
+}
+
+func (o *outbound) SendTyping() error                 { return nil }
+func (o *outbound) PostResponse(content string) error { return o.p.print("%s\n\n", content) }
+func (o *outbound) AddReaction(emoji string) error    { return o.p.print("[%s]\n", emoji) }
+func (o *outbound) SendUpdate(message string) error   { return o.p.print("… %s\n", message) }
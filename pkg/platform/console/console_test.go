@@ -0,0 +1,45 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TheLazyLemur/switchboard/pkg/platform"
+)
+
+func TestPlugin_DeliversLinesAndPrintsReplies(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// given
+	// ... two prompts separated by a blank line
+	var out bytes.Buffer
+	p := New(strings.NewReader("hello\n\n  second  \n"), &out)
+	r.NoError(p.Init(map[string]string{"USER": "ana"}))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	var got []platform.Inbound
+
+	// when
+	r.NoError(p.Start(context.Background(), func(in platform.Inbound) {
+		got = append(got, in)
+		_ = in.Reply.SendUpdate("working")
+		_ = in.Reply.PostResponse("reply to " + in.Text)
+		wg.Done()
+	}))
+	wg.Wait()
+
+	// then
+	r.Len(got, 2)
+	a.Equal(platform.SessionKey("console"), got[0].SessionKey)
+	a.Equal("console:ana", got[0].UserID)
+	a.Equal("second", got[1].Text)
+	a.Equal("… working\nreply to hello\n\n… working\nreply to second\n\n", out.String())
+	a.NoError(p.Stop())
+}
@@ -0,0 +1,84 @@
+// Package platform lets chat platforms be added to switchboard without
+// forking it. A platform package registers a Plugin from its init func;
+// a build-tagged file in cmd/switchboard blank-imports it, and every
+// registered plugin is initialised and started at startup.
+//
+// The types here are aliases of switchboard's internal ones so that code
+// outside this module can implement them.
+package platform
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TheLazyLemur/switchboard/internal/core"
+)
+
+type (
+	Inbound       = core.Inbound
+	Outbound      = core.Outbound
+	Capabilities  = core.Capabilities
+	AttachmentRef = core.AttachmentRef
+	SessionKey    = core.SessionKey
+)
+
+// Plugin is a chat platform. Start receives deliver, which hands one
+// inbound message to the bot and blocks until its turn is done; call it
+// from a goroutine per conversation. SessionKeys, UserIDs and ChannelIDs
+// must be prefixed with the plugin's ID (e.g. "matrix:!room:example.org").
+type Plugin interface {
+	core.ChannelPlugin
+	// Init is called once before Start with the plugin's settings: every
+	// PLUGIN_<ID>_<NAME> environment variable, keyed by NAME.
+	Init(settings map[string]string) error
+}
+
+var (
+	mu      sync.Mutex
+	plugins = map[string]Plugin{}
+)
+
+// Register makes p available to switchboard. It panics if p is nil or its
+// ID is already registered.
+func Register(p Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	if p == nil {
+		panic("platform: Register plugin is nil")
+	}
+	if _, dup := plugins[p.ID()]; dup {
+		panic("platform: Register called twice for plugin " + p.ID())
+	}
+	plugins[p.ID()] = p
+}
+
+// Plugins returns the registered plugins sorted by ID.
+func Plugins() []Plugin {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out
+}
+
+// Settings collects the PLUGIN_<ID>_ variables for plugin id from environ
+// (os.Environ when nil). Dashes in id become underscores.
+func Settings(id string, environ []string) map[string]string {
+	if environ == nil {
+		environ = os.Environ()
+	}
+	prefix := "PLUGIN_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+	settings := map[string]string{}
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			settings[name] = v
+		}
+	}
+	return settings
+}
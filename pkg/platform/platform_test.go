@@ -0,0 +1,64 @@
+package platform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubPlugin struct {
+	id string
+}
+
+func (s stubPlugin) ID() string                                 { return s.id }
+func (s stubPlugin) Capabilities() Capabilities                 { return Capabilities{} }
+func (s stubPlugin) Start(context.Context, func(Inbound)) error { return nil }
+func (s stubPlugin) Stop() error                                { return nil }
+func (s stubPlugin) Init(map[string]string) error               { return nil }
+
+func TestRegister_ListsSortedAndRejectsDuplicates(t *testing.T) {
+	a := assert.New(t)
+
+	// given
+	Register(stubPlugin{id: "test-zulip"})
+	Register(stubPlugin{id: "test-matrix"})
+
+	// when
+	var ids []string
+	for _, p := range Plugins() {
+		ids = append(ids, p.ID())
+	}
+
+	// then
+	a.Subset(ids, []string{"test-matrix", "test-zulip"})
+	a.Less(indexOf(ids, "test-matrix"), indexOf(ids, "test-zulip"))
+	a.Panics(func() { Register(stubPlugin{id: "test-matrix"}) })
+	a.Panics(func() { Register(nil) })
+}
+
+func indexOf(ids []string, id string) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSettings_StripsPluginPrefix(t *testing.T) {
+	// given
+	environ := []string{
+		"PLUGIN_MY_CHAT_TOKEN=secret",
+		"PLUGIN_MY_CHAT_ROOMS=a,b=c",
+		"PLUGIN_OTHER_TOKEN=nope",
+		"PLUGIN_MY_CHAT_=empty-name",
+		"HOME=/root",
+	}
+
+	// when
+	got := Settings("my-chat", environ)
+
+	// then
+	assert.Equal(t, map[string]string{"TOKEN": "secret", "ROOMS": "a,b=c"}, got)
+}